@@ -202,6 +202,7 @@ type Vout struct {
 	Version             uint16       `json:"version"`
 	ScriptPubKeyDecoded ScriptPubKey `json:"scriptPubKey"`
 	Spend               *TxInputID   `json:"spend,omitempty"` // unused?
+	Label               string       `json:"label,omitempty"`
 }
 
 // TxInputID specifies a transaction input as hash:vin_index.
@@ -369,6 +370,7 @@ type MultichainTxOut struct {
 	Value               float64                `json:"value"`
 	N                   uint32                 `json:"n"`
 	ScriptPubKeyDecoded MultichainScriptPubKey `json:"scriptPubKey"`
+	Label               string                 `json:"label,omitempty"`
 }
 
 type MultichainScriptPubKey struct {
@@ -434,11 +436,13 @@ type MultichainTxRaw struct {
 // Address models the address string with the transactions as AddressTxShort
 type Address struct {
 	Address      string            `json:"address"`
+	Label        string            `json:"label,omitempty"`
 	Transactions []*AddressTxShort `json:"address_transactions"`
 }
 
 type MultichainAddress struct {
 	Address      string             `json:"address"`
+	Label        string             `json:"label,omitempty"`
 	Transactions []*MultichainTxRaw `json:"address_transactions"`
 }
 
@@ -562,6 +566,7 @@ type AddressTotals struct {
 	NumUnspent   int64   `json:"num_utxos"`
 	CoinsSpent   float64 `json:"dcr_spent"`
 	CoinsUnspent float64 `json:"dcr_unspent"`
+	Label        string  `json:"label,omitempty"`
 }
 
 type MultichainAddressTotals struct {
@@ -573,6 +578,7 @@ type MultichainAddressTotals struct {
 	CoinsSpent    float64 `json:"coin_spent"`
 	CoinsUnspent  float64 `json:"coin_unspent"`
 	TotalReceived float64 `json:"total_received"`
+	Label         string  `json:"label,omitempty"`
 }
 
 // BlockDataWithTxType adds an array of TxRawWithTxType to
@@ -0,0 +1,164 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+// Package cluster groups the addresses of the UTXO chains into clusters that
+// are deemed to be controlled by one entity, using the common-input-ownership
+// and change heuristics of Link. The clusters are computed from the database
+// block by block, up to the best block of each chain, and are extended as new
+// blocks are stored. Clusters only grow: they are not unwound by reorgs.
+package cluster
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	btcwire "github.com/btcsuite/btcd/wire"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrdata/v8/blockdata"
+	"github.com/decred/dcrdata/v8/blockdata/blockdatabtc"
+	"github.com/decred/dcrdata/v8/blockdata/blockdataltc"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/mutilchain"
+	ltcwire "github.com/ltcsuite/ltcd/wire"
+)
+
+const (
+	// pollInterval is the interval of the catch up of a chain without new
+	// block notifications.
+	pollInterval = 5 * time.Minute
+	// progressInterval is the number of blocks between progress logs.
+	progressInterval = 10000
+	// retryDelay is the delay before a failed block is clustered again.
+	retryDelay = time.Minute
+)
+
+// Store is the source of the block transactions and the persistent storage of
+// the clusters, implemented by dcrpg.ChainDB.
+type Store interface {
+	// ClusterProgress returns the last clustered height of the chain, or -1.
+	ClusterProgress(chain string) (int64, error)
+	// ClusterTipHeight returns the best block height stored for the chain.
+	ClusterTipHeight(chain string) (int64, error)
+	ClusterBlockTxs(chain string, height int64) ([]*dbtypes.ClusterTx, error)
+	// StoreClusterLinks merges the clusters of the linked addresses and
+	// records the height as clustered, atomically.
+	StoreClusterLinks(chain string, height int64, links []*dbtypes.ClusterLink) error
+}
+
+// Clusterer maintains the address clusters of the configured chains.
+type Clusterer struct {
+	store Store
+	wake  map[string]chan struct{}
+}
+
+// New creates a Clusterer of the chains, which are among dcr, btc and ltc.
+func New(store Store, chains ...string) *Clusterer {
+	c := &Clusterer{
+		store: store,
+		wake:  make(map[string]chan struct{}, len(chains)),
+	}
+	for _, chain := range chains {
+		c.wake[chain] = make(chan struct{}, 1)
+	}
+	return c
+}
+
+// Run clusters the stored blocks of each chain and then the new ones, until
+// the context is canceled.
+func (c *Clusterer) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for chain, wake := range c.wake {
+		wg.Add(1)
+		go func(chain string, wake chan struct{}) {
+			defer wg.Done()
+			ticker := time.NewTicker(pollInterval)
+			defer ticker.Stop()
+			for {
+				if err := c.catchUp(ctx, chain); err != nil {
+					log.Errorf("Failed to cluster %s addresses: %v", chain, err)
+					select {
+					case <-ctx.Done():
+						return
+					case <-time.After(retryDelay):
+						continue
+					}
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				case <-wake:
+				}
+			}
+		}(chain, wake)
+	}
+	wg.Wait()
+}
+
+// catchUp clusters the blocks of a chain after the last clustered height, up
+// to the best stored block.
+func (c *Clusterer) catchUp(ctx context.Context, chain string) error {
+	last, err := c.store.ClusterProgress(chain)
+	if err != nil {
+		return err
+	}
+	tip, err := c.store.ClusterTipHeight(chain)
+	if err != nil {
+		return err
+	}
+	if tip-last > progressInterval {
+		log.Infof("Clustering %s addresses from height %d to %d.", chain, last+1, tip)
+	}
+	for height := last + 1; height <= tip; height++ {
+		if ctx.Err() != nil {
+			return nil
+		}
+		txs, err := c.store.ClusterBlockTxs(chain, height)
+		if err != nil {
+			return err
+		}
+		var links []*dbtypes.ClusterLink
+		for _, tx := range txs {
+			if link := Link(chain, tx); link != nil {
+				links = append(links, link)
+			}
+		}
+		if err = c.store.StoreClusterLinks(chain, height, links); err != nil {
+			return err
+		}
+		if height%progressInterval == 0 && height < tip {
+			log.Infof("Clustered %s addresses to height %d of %d.", chain, height, tip)
+		}
+	}
+	return nil
+}
+
+// notify wakes the clustering of a chain without blocking.
+func (c *Clusterer) notify(chain string) {
+	select {
+	case c.wake[chain] <- struct{}{}:
+	default:
+	}
+}
+
+// Store wakes the Decred clustering for a new block. It satisfies
+// blockdata.BlockDataSaver, and must follow the database in the savers.
+func (c *Clusterer) Store(*blockdata.BlockData, *wire.MsgBlock) error {
+	c.notify(mutilchain.TYPEDCR)
+	return nil
+}
+
+// BTCStore wakes the Bitcoin clustering for a new block. It satisfies
+// blockdatabtc.BlockDataSaver.
+func (c *Clusterer) BTCStore(*blockdatabtc.BlockData, *btcwire.MsgBlock) error {
+	c.notify(mutilchain.TYPEBTC)
+	return nil
+}
+
+// LTCStore wakes the Litecoin clustering for a new block. It satisfies
+// blockdataltc.BlockDataSaver.
+func (c *Clusterer) LTCStore(*blockdataltc.BlockData, *ltcwire.MsgBlock) error {
+	c.notify(mutilchain.TYPELTC)
+	return nil
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package cluster
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/mutilchain"
+)
+
+func out(addr string, value int64, fresh bool) *dbtypes.ClusterOutput {
+	return &dbtypes.ClusterOutput{Address: addr, Value: value, Fresh: fresh}
+}
+
+func TestIsCoinJoin(t *testing.T) {
+	tests := []struct {
+		name   string
+		chain  string
+		numIns int
+		outs   []int64
+		want   bool
+	}{
+		{"whirlpool", mutilchain.TYPEBTC, 5, []int64{1e6, 1e6, 1e6, 1e6, 1e6}, true},
+		{"equal outputs", mutilchain.TYPEBTC, 3, []int64{1e8, 1e8, 1e8, 12345}, true},
+		{"litecoin equal outputs", mutilchain.TYPELTC, 3, []int64{1e8, 1e8, 1e8, 12345}, true},
+		{"litecoin whirlpool structure", mutilchain.TYPELTC, 2, []int64{1e6, 1e6}, false},
+		{"batch payout of one input", mutilchain.TYPEBTC, 1, []int64{1e8, 1e8, 1e8}, false},
+		{"payment with change", mutilchain.TYPEBTC, 2, []int64{1e8, 5432}, false},
+		{"decred equal outputs", mutilchain.TYPEDCR, 3, []int64{1e8, 1e8, 1e8}, false},
+	}
+	for _, test := range tests {
+		if got := IsCoinJoin(test.chain, test.numIns, test.outs); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestLink(t *testing.T) {
+	tests := []struct {
+		name   string
+		tx     *dbtypes.ClusterTx
+		addrs  []string
+		change string
+	}{{
+		name: "fresh change",
+		tx: &dbtypes.ClusterTx{Inputs: []string{"a", "b", "a"},
+			Outputs: []*dbtypes.ClusterOutput{out("x", 123456, false), out("y", 654321, true)}},
+		addrs:  []string{"a", "b", "y"},
+		change: "y",
+	}, {
+		name: "non-round change",
+		tx: &dbtypes.ClusterTx{Inputs: []string{"a"},
+			Outputs: []*dbtypes.ClusterOutput{out("x", 5e7, true), out("y", 4321987, true)}},
+		addrs:  []string{"a", "y"},
+		change: "y",
+	}, {
+		name: "ambiguous change",
+		tx: &dbtypes.ClusterTx{Inputs: []string{"a", "b"},
+			Outputs: []*dbtypes.ClusterOutput{out("x", 1234, true), out("y", 4321, true)}},
+		addrs: []string{"a", "b"},
+	}, {
+		name: "change to an input address",
+		tx: &dbtypes.ClusterTx{Inputs: []string{"a", "b"},
+			Outputs: []*dbtypes.ClusterOutput{out("x", 1e8, true), out("a", 4321, false)}},
+		addrs: []string{"a", "b"},
+	}, {
+		name: "three outputs",
+		tx: &dbtypes.ClusterTx{Inputs: []string{"a"},
+			Outputs: []*dbtypes.ClusterOutput{out("x", 1e8, false), out("y", 2e8, false), out("z", 4321, true)}},
+	}, {
+		name: "mix",
+		tx: &dbtypes.ClusterTx{Inputs: []string{"a", "b"}, Mix: true,
+			Outputs: []*dbtypes.ClusterOutput{out("x", 1e8, true), out("y", 4321, true)}},
+	}, {
+		name: "coinjoin",
+		tx: &dbtypes.ClusterTx{Inputs: []string{"a", "b", "c"},
+			Outputs: []*dbtypes.ClusterOutput{out("x", 1e8, true), out("y", 1e8, true), out("z", 1e8, true)}},
+	}, {
+		name: "coinbase",
+		tx:   &dbtypes.ClusterTx{Outputs: []*dbtypes.ClusterOutput{out("x", 1e8, true)}},
+	}}
+	for _, test := range tests {
+		link := Link(mutilchain.TYPEBTC, test.tx)
+		if test.addrs == nil {
+			if link != nil {
+				t.Errorf("%s: unexpected link %+v", test.name, link)
+			}
+			continue
+		}
+		if link == nil {
+			t.Errorf("%s: no link", test.name)
+			continue
+		}
+		if !reflect.DeepEqual(link.Addresses, test.addrs) || link.Change != test.change {
+			t.Errorf("%s: got %v with change %q, want %v with change %q", test.name,
+				link.Addresses, link.Change, test.addrs, test.change)
+		}
+	}
+}
+
+type testStore struct {
+	progress int64
+	tip      int64
+	blocks   map[int64][]*dbtypes.ClusterTx
+	links    map[int64][]*dbtypes.ClusterLink
+}
+
+func (s *testStore) ClusterProgress(string) (int64, error) { return s.progress, nil }
+
+func (s *testStore) ClusterTipHeight(string) (int64, error) { return s.tip, nil }
+
+func (s *testStore) ClusterBlockTxs(_ string, height int64) ([]*dbtypes.ClusterTx, error) {
+	return s.blocks[height], nil
+}
+
+func (s *testStore) StoreClusterLinks(_ string, height int64, links []*dbtypes.ClusterLink) error {
+	s.links[height] = links
+	s.progress = height
+	return nil
+}
+
+func TestCatchUp(t *testing.T) {
+	store := &testStore{
+		progress: 1,
+		tip:      4,
+		blocks: map[int64][]*dbtypes.ClusterTx{
+			1: {{Inputs: []string{"old", "older"}}},
+			3: {{TxHash: "t", Inputs: []string{"a", "b"}}, {Inputs: []string{"c"}}},
+		},
+		links: make(map[int64][]*dbtypes.ClusterLink),
+	}
+	c := New(store, "dcr")
+	if err := c.catchUp(context.Background(), "dcr"); err != nil {
+		t.Fatal(err)
+	}
+	if store.progress != 4 || len(store.links) != 3 {
+		t.Fatalf("clustered to %d with %d blocks", store.progress, len(store.links))
+	}
+	if links := store.links[3]; len(links) != 1 || links[0].TxHash != "t" {
+		t.Errorf("unexpected links %+v", links)
+	}
+}
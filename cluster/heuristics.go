@@ -0,0 +1,119 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package cluster
+
+import (
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/mutilchain"
+	"github.com/decred/dcrdata/v8/txhelpers"
+)
+
+// roundAtoms is the granularity of a round payment amount, 0.001 coin.
+const roundAtoms = 100000
+
+// IsCoinJoin reports whether a transaction of a chain with numIns inputs and
+// the output values outs is a CoinJoin, as classified when its block is
+// stored: by txhelpers.DetectCoinJoin for Bitcoin and by
+// txhelpers.DetectGenericCoinJoin for Litecoin. The Decred mixes are told by
+// txhelpers.IsMixTx instead. The inputs of a CoinJoin belong to different
+// entities.
+func IsCoinJoin(chain string, numIns int, outs []int64) bool {
+	switch chain {
+	case mutilchain.TYPEBTC:
+		return txhelpers.DetectCoinJoin(numIns, outs) != nil
+	case mutilchain.TYPELTC:
+		return txhelpers.DetectGenericCoinJoin(numIns, outs) != nil
+	}
+	return false
+}
+
+// outputValues returns the output values of a transaction.
+func outputValues(tx *dbtypes.ClusterTx) []int64 {
+	outs := make([]int64, 0, len(tx.Outputs))
+	for _, out := range tx.Outputs {
+		outs = append(outs, out.Value)
+	}
+	return outs
+}
+
+// Link applies the clustering heuristics to a transaction of a chain. By the
+// common-input-ownership heuristic, the input addresses of a transaction are
+// controlled by one entity. The change heuristic adds the change address:
+// of exactly two output addresses that are not inputs, the only fresh one,
+// or, when both are fresh, the only one with an amount that is not round.
+// Mixes and CoinJoins are not linked. Link returns nil for a transaction
+// that links fewer than two addresses.
+func Link(chain string, tx *dbtypes.ClusterTx) *dbtypes.ClusterLink {
+	if tx.Mix || IsCoinJoin(chain, len(tx.Inputs), outputValues(tx)) {
+		return nil
+	}
+	addrs := distinct(tx.Inputs)
+	if len(addrs) == 0 {
+		return nil
+	}
+	change := changeAddress(tx)
+	if change != "" {
+		addrs = append(addrs, change)
+	}
+	if len(addrs) < 2 {
+		return nil
+	}
+	return &dbtypes.ClusterLink{
+		TxHash:    tx.TxHash,
+		Addresses: addrs,
+		Change:    change,
+	}
+}
+
+// changeAddress returns the change address of a transaction with two output
+// addresses, or an empty string if it cannot be told from the payment.
+func changeAddress(tx *dbtypes.ClusterTx) string {
+	inputs := make(map[string]bool, len(tx.Inputs))
+	for _, addr := range tx.Inputs {
+		inputs[addr] = true
+	}
+	var outs []*dbtypes.ClusterOutput
+	for _, out := range tx.Outputs {
+		if inputs[out.Address] {
+			// The change goes back to an input address.
+			return ""
+		}
+		if len(outs) == 1 && outs[0].Address == out.Address {
+			return ""
+		}
+		outs = append(outs, out)
+	}
+	if len(outs) != 2 {
+		return ""
+	}
+	a, b := outs[0], outs[1]
+	switch {
+	case a.Fresh && !b.Fresh:
+		return a.Address
+	case b.Fresh && !a.Fresh:
+		return b.Address
+	case a.Fresh && b.Fresh:
+		aRound, bRound := a.Value%roundAtoms == 0, b.Value%roundAtoms == 0
+		if aRound && !bRound {
+			return b.Address
+		}
+		if bRound && !aRound {
+			return a.Address
+		}
+	}
+	return ""
+}
+
+// distinct returns the addresses without duplicates, in order.
+func distinct(addrs []string) []string {
+	seen := make(map[string]bool, len(addrs))
+	out := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		if addr != "" && !seen[addr] {
+			seen[addr] = true
+			out = append(out, addr)
+		}
+	}
+	return out
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/decred/dcrdata/v8/netparams"
 	"github.com/decred/dcrdata/v8/netparams/btcnetparams"
 	"github.com/decred/dcrdata/v8/netparams/ltcnetparams"
+	"github.com/decred/dcrdata/v8/xmr/xmrutil"
 	"github.com/decred/slog"
 	flags "github.com/jessevdk/go-flags"
 	ltccfg "github.com/ltcsuite/ltcd/chaincfg"
@@ -46,6 +47,7 @@ var ltcActiveNet = &ltcnetparams.MainNetParams
 var ltcActiveChain = &ltccfg.MainNetParams
 var btcActiveNet = &btcnetparams.MainNetParams
 var btcActiveChain = &btccfg.MainNetParams
+var xmrActiveNet = xmrutil.NetMainnet
 
 var (
 	defaultHomeDir              = dcrutil.AppDataDir("dcrdata", false)
@@ -76,6 +78,9 @@ var (
 	defaultBTCTestnetPort   = "18332"
 	defaultBTCSimnetPort    = "18335"
 	defaultBTCIndentJSON    = "   "
+	//Monerod: 18081, 28081, 38081
+	defaultXMRTestnetServer  = "http://127.0.0.1:28081/json_rpc"
+	defaultXMRStagenetServer = "http://127.0.0.1:38081/json_rpc"
 
 	defaultCacheControlMaxAge  = 86400
 	defaultInsightReqRateLimit = 20.0
@@ -148,6 +153,10 @@ type config struct {
 	MaxCSVAddrs         int      `long:"max-api-addrs" description:"Maximum allowed comma-separated addresses for endpoints that accept multiple addresses." env:"DCRDATA_MAX_CSV_ADDRS"`
 	CompressAPI         bool     `long:"compress-api" description:"Use compression for a number of endpoints with commonly large responses." env:"DCRDATA_COMPRESS_API"`
 	ServerHeader        string   `long:"server-http-header" description:"Set the HTTP response header Server key value. Valid values are \"off\", \"version\", or a custom string." env:"DCRDATA_SERVER_HEADER"`
+	EnableWatchlist     bool     `long:"watchlist" description:"Enable the watch-list API, which delivers webhooks for the activity of registered addresses to the callback URLs given by API clients." env:"DCRDATA_ENABLE_WATCHLIST"`
+	WatchlistAllowLocal bool     `long:"watchlist-allow-local" description:"Allow watch-list callback URLs on private, loopback and link-local addresses, e.g. for a webhook receiver on this host. Any API client can then make this host send requests to the local network." env:"DCRDATA_WATCHLIST_ALLOW_LOCAL"`
+	EnableClustering    bool     `long:"clustering" description:"Enable the address clustering of the UTXO chains, shown on the address and entity pages. The stored blocks are clustered in the background on the first start." env:"DCRDATA_ENABLE_CLUSTERING"`
+	LabelsAdminKey      string   `long:"labels-admin-key" description:"Key authorizing the management of the address labels with the labels API, in the X-Admin-Key header. The labels API is read-only if it is not set." env:"DCRDATA_LABELS_ADMIN_KEY"`
 
 	// Mempool
 	MempoolMinInterval int `long:"mp-min-interval" description:"The minimum time in seconds between mempool reports, regardless of number of new tickets seen." env:"DCRDATA_MEMPOOL_MIN_INTERVAL"`
@@ -195,13 +204,16 @@ type config struct {
 	// LTC RPC client options (litecoind HTTP mode)
 	LtcdUser string `long:"ltcduser" description:"Daemon RPC user name" env:"DCRDATA_LTCD_USER"`
 	LtcdPass string `long:"ltcdpass" description:"Daemon RPC password" env:"DCRDATA_LTCD_PASS"`
-	LtcdServ string `long:"ltcdserv" description:"Hostname/IP and port of litecoind RPC server to connect to (default localhost:9332, testnet: localhost:19332)" env:"DCRDATA_LTCD_URL"`
+	LtcdServ string `long:"ltcdserv" description:"Hostname/IP and port of litecoind RPC server to connect to (default localhost:9332, testnet: localhost:19332, regtest: localhost:19443)" env:"DCRDATA_LTCD_URL"`
+	LtcNet   string `long:"ltcnet" description:"Litecoin network {mainnet, testnet, regtest, simnet} (default follows the Decred network)" env:"DCRDATA_LTC_NET"`
 
 	// BTC RPC client options (bitcoind HTTP mode)
 	BtcdUser string `long:"btcduser" description:"Daemon RPC user name" env:"DCRDATA_BTCD_USER"`
 	BtcdPass string `long:"btcdpass" description:"Daemon RPC password" env:"DCRDATA_BTCD_PASS"`
-	BtcdServ string `long:"btcdserv" description:"Hostname/IP and port of bitcoind RPC server to connect to (default localhost:8332, testnet: localhost:18332)" env:"DCRDATA_BTCD_URL"`
-	XmrServ  string `long:"xmrserv" description:"Endpoint of monerod RPC server to connect to (default localhost:18081/json_rpc)" env:"DCRDATA_MONEROD_URL"`
+	BtcdServ string `long:"btcdserv" description:"Hostname/IP and port of bitcoind RPC server to connect to (default localhost:8332, testnet: localhost:18332, signet: localhost:38332, regtest: localhost:18443)" env:"DCRDATA_BTCD_URL"`
+	BtcNet   string `long:"btcnet" description:"Bitcoin network {mainnet, testnet, signet, regtest, simnet} (default follows the Decred network)" env:"DCRDATA_BTC_NET"`
+	XmrServ  string `long:"xmrserv" description:"Endpoint of monerod RPC server to connect to (default localhost:18081/json_rpc, testnet: localhost:28081/json_rpc, stagenet: localhost:38081/json_rpc)" env:"DCRDATA_MONEROD_URL"`
+	XmrNet   string `long:"xmrnet" description:"Monero network {mainnet, testnet, stagenet, regtest} (default follows the Decred network)" env:"DCRDATA_XMR_NET"`
 	// ExchangeBot settings
 	EnableExchangeBot bool   `long:"exchange-monitor" description:"Enable the exchange monitor" env:"DCRDATA_MONITOR_EXCHANGES"`
 	DisabledExchanges string `long:"disable-exchange" description:"Exchanges to disable. See /exchanges/exchanges.go for available exchanges. Use a comma to separate multiple exchanges" env:"DCRDATA_DISABLE_EXCHANGES"`
@@ -220,6 +232,8 @@ type config struct {
 
 	// xmr temp api server
 	XmrTempServ string `long:"xmrtempserv" description:"Intermediate api server supports decode output and prove tx for Monero" env:"XMR_TEMP_SERV"`
+	// Monero view key watches on pubsub
+	XmrViewKeyWatch bool `long:"xmrviewkeywatch" description:"Allow pubsub clients to watch Monero addresses with their private view keys. New transactions are scanned on the server." env:"XMR_VIEWKEY_WATCH"`
 }
 
 var (
@@ -317,6 +331,47 @@ func cleanAndExpandPath(path string) string {
 	return filepath.Join(homeDir, path)
 }
 
+// btcNetParams are the parameters of the Bitcoin network chosen with --btcnet.
+func btcNetParams(name string) (*btcnetparams.Params, error) {
+	switch strings.ToLower(name) {
+	case "mainnet":
+		return &btcnetparams.MainNetParams, nil
+	case "testnet", "testnet3":
+		return &btcnetparams.TestNet3Params, nil
+	case "signet":
+		return &btcnetparams.SigNetParams, nil
+	case "regtest":
+		return &btcnetparams.RegressionNetParams, nil
+	case "simnet":
+		return &btcnetparams.SimNetParams, nil
+	}
+	return nil, fmt.Errorf("unknown Bitcoin network %q", name)
+}
+
+// ltcNetParams are the parameters of the Litecoin network chosen with --ltcnet.
+func ltcNetParams(name string) (*ltcnetparams.Params, error) {
+	switch strings.ToLower(name) {
+	case "mainnet":
+		return &ltcnetparams.MainNetParams, nil
+	case "testnet", "testnet4":
+		return &ltcnetparams.TestNet3Params, nil
+	case "regtest":
+		return &ltcnetparams.RegressionNetParams, nil
+	case "simnet":
+		return &ltcnetparams.SimNetParams, nil
+	}
+	return nil, fmt.Errorf("unknown Litecoin network %q", name)
+}
+
+// xmrNet is the Monero network chosen with --xmrnet.
+func xmrNet(name string) (string, error) {
+	switch net := strings.ToLower(name); net {
+	case xmrutil.NetMainnet, xmrutil.NetTestnet, xmrutil.NetStagenet, xmrutil.NetRegtest:
+		return net, nil
+	}
+	return "", fmt.Errorf("unknown Monero network %q", name)
+}
+
 // normalizeNetworkAddress checks for a valid local network address format and
 // adds default host and port if not present. Invalidates addresses that include
 // a protocol identifier.
@@ -610,6 +665,44 @@ func loadConfig() (*config, error) {
 		return loadConfigError(err)
 	}
 
+	// The Bitcoin, Litecoin and Monero networks follow the Decred network
+	// unless they are chosen with --btcnet, --ltcnet or --xmrnet, e.g. to run
+	// the explorer against local regtest nodes.
+	if cfg.BtcNet != "" {
+		if btcActiveNet, err = btcNetParams(cfg.BtcNet); err != nil {
+			return loadConfigError(err)
+		}
+		btcActiveChain = btcActiveNet.Params
+		btcDefaultPort = btcActiveNet.JSONRPCClientPort
+	}
+	if cfg.LtcNet != "" {
+		if ltcActiveNet, err = ltcNetParams(cfg.LtcNet); err != nil {
+			return loadConfigError(err)
+		}
+		ltcActiveChain = ltcActiveNet.Params
+		ltcDefaultPort = ltcActiveNet.JSONRPCClientPort
+	}
+	switch {
+	case cfg.XmrNet != "":
+		if xmrActiveNet, err = xmrNet(cfg.XmrNet); err != nil {
+			return loadConfigError(err)
+		}
+	case cfg.TestNet:
+		xmrActiveNet = xmrutil.NetTestnet
+	case cfg.SimNet:
+		xmrActiveNet = xmrutil.NetRegtest
+	default:
+		xmrActiveNet = xmrutil.NetMainnet
+	}
+	if cfg.XmrServ == defaultXMRMainnetServer {
+		switch xmrActiveNet {
+		case xmrutil.NetTestnet:
+			cfg.XmrServ = defaultXMRTestnetServer
+		case xmrutil.NetStagenet:
+			cfg.XmrServ = defaultXMRStagenetServer
+		}
+	}
+
 	// Append the network type to the data directory so it is "namespaced" per
 	// network.  In addition to the block database, there are other pieces of
 	// data that are saved to disk such as address manager state. All data is
@@ -0,0 +1,87 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/decred/dcrdata/db/dcrpg/v8"
+	"github.com/decred/dcrdata/exchanges/v3"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+)
+
+// exchangeHistory stores the market history of the ExchangeBot in the
+// PostgreSQL database. It satisfies exchanges.HistoryStore.
+type exchangeHistory struct {
+	db *dcrpg.ChainDB
+}
+
+func (h *exchangeHistory) StoreCandlesticks(chain, token, bin string, sticks exchanges.Candlesticks) error {
+	candles := make([]*dbtypes.ExchangeCandle, 0, len(sticks))
+	for _, stick := range sticks {
+		candles = append(candles, &dbtypes.ExchangeCandle{
+			Start:  stick.Start,
+			Open:   stick.Open,
+			High:   stick.High,
+			Low:    stick.Low,
+			Close:  stick.Close,
+			Volume: stick.Volume,
+		})
+	}
+	return h.db.StoreExchangeCandles(chain, token, bin, candles)
+}
+
+func (h *exchangeHistory) StoreDepth(chain, token string, depth *exchanges.DepthData) error {
+	depthJSON, err := json.Marshal(depth)
+	if err != nil {
+		return err
+	}
+	return h.db.StoreExchangeDepth(chain, token, time.Unix(depth.Time, 0), depthJSON)
+}
+
+func (h *exchangeHistory) StorePrice(chain, token string, state *exchanges.BaseState) error {
+	stamp := time.Now()
+	if state.Stamp > 0 {
+		stamp = time.Unix(state.Stamp, 0)
+	}
+	return h.db.StoreExchangePrice(chain, token, &dbtypes.ExchangePriceTick{
+		Stamp:      stamp,
+		Price:      state.Price,
+		BaseVolume: state.BaseVolume,
+		Volume:     state.Volume,
+		Change:     state.Change,
+	})
+}
+
+func (h *exchangeHistory) Candlesticks(chain, token, bin string, from, to time.Time) (exchanges.Candlesticks, error) {
+	candles, err := h.db.ExchangeCandles(chain, token, bin, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return candlesticks(candles), nil
+}
+
+func (h *exchangeHistory) LastCandlesticks(chain, token, bin string, n int) (exchanges.Candlesticks, error) {
+	candles, err := h.db.LastExchangeCandles(chain, token, bin, n)
+	if err != nil {
+		return nil, err
+	}
+	return candlesticks(candles), nil
+}
+
+func candlesticks(candles []*dbtypes.ExchangeCandle) exchanges.Candlesticks {
+	sticks := make(exchanges.Candlesticks, 0, len(candles))
+	for _, c := range candles {
+		sticks = append(sticks, exchanges.Candlestick{
+			High:   c.High,
+			Low:    c.Low,
+			Open:   c.Open,
+			Close:  c.Close,
+			Volume: c.Volume,
+			Start:  c.Start,
+		})
+	}
+	return sticks
+}
@@ -313,6 +313,7 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 	mux.Route("/exchanges", func(r chi.Router) {
 		r.Get("/", app.getExchanges)
 		r.Get("/codes", app.getCurrencyCodes)
+		r.Get("/quality", app.getExchangeQuality)
 	})
 
 	mux.Route("/broadcast", func(r chi.Router) {
@@ -323,6 +324,7 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 		r.Get("/decode-output", app.MoneroDecodeOutputs)
 		r.Get("/prove-tx", app.MoneroProveTx)
 		r.Get("/transactions", app.getMoneroTransactions)
+		r.Get("/fingerprints", app.getMoneroFingerprints)
 		r.Route("/block", func(rd chi.Router) {
 			rd.Route("/hash/{blockhash}", func(re chi.Router) {
 				re.Use(m.BlockHashPathCtx)
@@ -331,12 +333,14 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 			rd.Route("/{idx}", func(re chi.Router) {
 				re.Use(m.BlockIndexPathCtx)
 				re.Get("/", app.getMoneroBlockSummary)
+				re.Get("/privacy", app.getMoneroBlockPrivacy)
 			})
 		})
 		r.Route("/tx", func(rd chi.Router) {
 			rd.Route("/{txid}", func(re chi.Router) {
 				re.Use(m.TransactionHashCtx)
 				re.Get("/", app.getMoneroTransactionDetail)
+				re.Get("/privacy", app.getMoneroTxPrivacy)
 			})
 		})
 		r.Get("/mempool", app.getMoneroMempool)
@@ -349,8 +353,49 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 		})
 	})
 
+	// Watch-list management. Each watch is authorized by the secret returned
+	// on creation, in the X-Watch-Secret header.
+	mux.Route("/watchlist", func(r chi.Router) {
+		r.Use(m.Tollbooth(addrLimiter))
+		r.Post("/", app.createWatch)
+		r.Route("/{watchid}", func(rd chi.Router) {
+			rd.Use(app.WatchCtx)
+			rd.Get("/", app.getWatch)
+			rd.Delete("/", app.deleteWatch)
+			rd.Get("/deliveries", app.getWatchDeliveries)
+		})
+	})
+
+	// Extended public key wallet view. The key is in the POST body.
+	mux.With(m.Tollbooth(addrLimiter)).Post("/xpub", app.getHDWallet)
+
+	// Address labels. The labels are managed with the admin key in the
+	// X-Admin-Key header.
+	mux.Route("/labels", func(r chi.Router) {
+		r.Get("/search", app.searchLabels)
+		r.Route("/{chaintype}", func(rd chi.Router) {
+			rd.Use(m.ChainTypeCtx)
+			rd.Get("/", app.getLabels)
+			rd.With(app.LabelsAdminCtx, m.SimpleAddressCtx).Put("/{address}", app.putLabel)
+			rd.With(app.LabelsAdminCtx, m.SimpleAddressCtx).Delete("/{address}", app.deleteLabel)
+		})
+	})
+
+	mux.Route("/cluster/{chaintype}", func(r chi.Router) {
+		r.Use(m.ChainTypeCtx, m.Tollbooth(addrLimiter))
+		r.With(m.SimpleAddressCtx).Get("/address/{address}", app.getAddressCluster)
+		r.Get("/{clusterid}", app.getCluster)
+	})
+
 	mux.Route("/{chaintype}", func(r chi.Router) {
 		r.Use(m.ChainTypeCtx)
+		r.Get("/decodetx", app.decodeMultichainRawTx)
+		r.Post("/decodetx", app.decodeMultichainRawTx)
+		r.Post("/broadcast", app.broadcastMultichainTx)
+		r.Get("/mempool/projected", app.getMultichainProjectedMempool)
+		r.Get("/pools/share", app.getMultichainPoolShare)
+		r.With(m.Tollbooth(addrLimiter)).Get("/trace", app.getTxTrace)
+		r.Get("/richlist", app.getRichList)
 		r.Route("/tx", func(rt chi.Router) {
 			rt.Route("/{txid}", func(rd chi.Router) {
 				rd.Use(m.TransactionHashCtx)
@@ -6,6 +6,7 @@ package api
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/binary"
 	"encoding/csv"
 	"encoding/hex"
@@ -15,6 +16,7 @@ import (
 	"html"
 	"io"
 	"math"
+	"net"
 	"net/http"
 	"reflect"
 	"slices"
@@ -40,10 +42,16 @@ import (
 	apitypes "github.com/decred/dcrdata/v8/api/types"
 	"github.com/decred/dcrdata/v8/db/cache"
 	"github.com/decred/dcrdata/v8/db/dbtypes"
+	exptypes "github.com/decred/dcrdata/v8/explorer/types"
+	"github.com/decred/dcrdata/v8/hdwallet"
 	"github.com/decred/dcrdata/v8/mutilchain"
 	"github.com/decred/dcrdata/v8/mutilchain/externalapi"
+	"github.com/decred/dcrdata/v8/mutilchain/labels"
 	"github.com/decred/dcrdata/v8/txhelpers"
+	"github.com/decred/dcrdata/v8/txtrace"
 	"github.com/decred/dcrdata/v8/utils"
+	"github.com/decred/dcrdata/v8/watchlist"
+	"github.com/decred/dcrdata/v8/xmr/xmrfingerprint"
 	"github.com/go-chi/chi/v5"
 	ltcClient "github.com/ltcsuite/ltcd/rpcclient"
 	agents "github.com/monperrus/crawler-user-agents"
@@ -74,6 +82,18 @@ type DataSource interface {
 	MutilchainDBAddressTransactionDetails(addr, chainType string, count, skip int64) (*apitypes.MultichainAddress, error)
 	AddressTotals(address string) (*apitypes.AddressTotals, error)
 	MultichainAddressTotals(chainType, address string) (*apitypes.MultichainAddressTotals, error)
+	HDWallet(chainType, key string, gapLimit int) (*dbtypes.HDWallet, error)
+	AddressCluster(chain, address string) (*dbtypes.AddressCluster, error)
+	Cluster(chain string, id int64, limit, offset int) (*dbtypes.AddressCluster, error)
+	RichList(chain string, limit, offset int) (*dbtypes.RichList, error)
+	AddressLabel(chain, address string) *labels.Label
+	AddressLabels(chain string, addrs []string) map[string]string
+	Labels(chain string) []*labels.Label
+	SearchLabels(query string, limit int) []*labels.Match
+	PutAddressLabel(chain string, label *labels.Label) error
+	DeleteAddressLabel(chain, address string) error
+	CoSpentAddresses(chain, address string, limit int) ([]*dbtypes.CoSpentAddress, error)
+	TraceTx(chain, txHash string) (*dbtypes.TraceTx, error)
 	VotesInBlock(hash string) (int16, error)
 	TxHistoryData(address string, addrChart dbtypes.HistoryChart,
 		chartGroupings dbtypes.TimeBasedGrouping) (*dbtypes.ChartsData, error)
@@ -147,6 +167,8 @@ type DataSource interface {
 	GetAllProposalTokens() []string
 	GetProposalByOwner(name string) (proposalMetaList []map[string]string, err error)
 	SendRawTransaction(txhex string) (string, error)
+	MutilchainDecodeRawTransaction(rawTx, chainType string) (*txhelpers.DecodedMultichainTx, error)
+	MutilchainSendRawTransaction(rawTx, chainType string) (string, error)
 	GetCurrencyPriceMapByPeriod(from time.Time, to time.Time, isSync bool) map[string]float64
 	GetTreasuryTimeRange() (int64, int64, error)
 	GetLegacyTimeRange() (int64, int64, error)
@@ -167,7 +189,25 @@ type DataSource interface {
 	GetMoneroMempoolDetail() (any, error)
 	GetMoneroNetworkInfo() (any, error)
 	GetMoneroRawTransaction(txhash string) (any, error)
+	XMRTxFingerprint(txHash string) (*xmrfingerprint.Fingerprint, error)
+	XMRBlockFingerprints(height int64) (*xmrfingerprint.BlockSummary, error)
+	XMRTxFingerprints(filter *xmrfingerprint.Filter) ([]*xmrfingerprint.Fingerprint, error)
 	MutilchainAPIAddressTransactionDetails(addr, chainType string, count, skip int64) (*externalapi.APIAddressInfo, error)
+	MutilchainPoolShareChart(chainType, bin string) (*dbtypes.PoolShareChart, error)
+}
+
+// MempoolProjector provides the blocks projected from a BTC or LTC mempool.
+type MempoolProjector interface {
+	ProjectedMempool() *exptypes.MutilchainProjectedMempool
+}
+
+// Watchlist registers the address watches and serves their webhook delivery
+// logs.
+type Watchlist interface {
+	Add(w *dbtypes.Watch) (*dbtypes.Watch, error)
+	Authorize(id int64, secret string) (*dbtypes.Watch, error)
+	Remove(id int64) error
+	Deliveries(id int64, limit int) ([]*dbtypes.WatchDelivery, error)
 }
 
 // dcrdata application context used by all route handlers
@@ -189,6 +229,16 @@ type appContext struct {
 	ChainDisabledMap map[string]bool
 	CoinCaps         []string
 	CoinCapDataList  []*dbtypes.MarketCapData
+
+	projectorsMtx sync.RWMutex
+	projectors    map[string]MempoolProjector
+
+	watchlistMtx sync.RWMutex
+	watchlist    Watchlist
+
+	// labelsAdminKey authorizes the management of the address labels. The
+	// label management endpoints are disabled if it is empty.
+	labelsAdminKey string
 }
 
 // AppContextConfig is the configuration for the appContext and the only
@@ -209,6 +259,7 @@ type AppContextConfig struct {
 	AppVer            string
 	ChainDisabledMap  map[string]bool
 	CoinCaps          []string
+	LabelsAdminKey    string
 }
 
 type simulationRow struct {
@@ -248,6 +299,7 @@ func NewContext(cfg *AppContextConfig) *appContext {
 		charts:           cfg.Charts,
 		ChainDisabledMap: cfg.ChainDisabledMap,
 		CoinCaps:         cfg.CoinCaps,
+		labelsAdminKey:   cfg.LabelsAdminKey,
 	}
 }
 
@@ -752,6 +804,9 @@ func (c *appContext) getTransaction(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	for i := range tx.Vout {
+		tx.Vout[i].Label = c.outputLabel(mutilchain.TYPEDCR, tx.Vout[i].ScriptPubKeyDecoded.Addresses)
+	}
 
 	writeJSON(w, tx, m.GetIndentCtx(r))
 }
@@ -818,6 +873,9 @@ func (c *appContext) getMultichainDecodedTx(w http.ResponseWriter, r *http.Reque
 		http.Error(w, http.StatusText(422), 422)
 		return
 	}
+	for i := range tx.Vout {
+		tx.Vout[i].Label = c.outputLabel(chainType, tx.Vout[i].ScriptPubKeyDecoded.Addresses)
+	}
 	writeJSON(w, *tx, m.GetIndentCtx(r))
 }
 
@@ -3290,6 +3348,7 @@ func (c *appContext) addressTotals(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, http.StatusText(422), 422)
 		return
 	}
+	totals.Label = c.addressLabel(mutilchain.TYPEDCR, address)
 
 	writeJSON(w, totals, m.GetIndentCtx(r))
 }
@@ -3322,6 +3381,7 @@ func (c *appContext) multichainAddressTotals(w http.ResponseWriter, r *http.Requ
 		http.Error(w, http.StatusText(422), 422)
 		return
 	}
+	totals.Label = c.addressLabel(chainType, address)
 
 	writeJSON(w, totals, m.GetIndentCtx(r))
 }
@@ -3847,6 +3907,101 @@ func (c *appContext) getMoneroTransactionDetail(w http.ResponseWriter, r *http.R
 	writeJSON(w, txDetail, m.GetIndentCtx(r))
 }
 
+func (c *appContext) getMoneroTxPrivacy(w http.ResponseWriter, r *http.Request) {
+	txhash, err := m.GetTxhashStrCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	fp, err := c.DataSource.XMRTxFingerprint(txhash)
+	if err != nil {
+		apiLog.Errorf("Unable to fingerprint XMR tx %s: %v", txhash, err)
+		http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
+		return
+	}
+
+	writeJSON(w, fp, m.GetIndentCtx(r))
+}
+
+func (c *appContext) getMoneroBlockPrivacy(w http.ResponseWriter, r *http.Request) {
+	idx, err := m.GetMultichainBlockHeightCtx(r)
+	if err != nil || idx < 0 {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	summary, err := c.DataSource.XMRBlockFingerprints(int64(idx))
+	if err != nil {
+		apiLog.Errorf("Unable to fingerprint XMR block %d: %v", idx, err)
+		http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
+		return
+	}
+
+	writeJSON(w, summary, m.GetIndentCtx(r))
+}
+
+const (
+	defaultXMRFingerprintsLimit = 100
+	maxXMRFingerprintsLimit     = 1000
+)
+
+// getMoneroFingerprints lists the stored XMR tx fingerprints with all the
+// flags of the repeatable flag parameter, in the optional from/to height
+// range.
+func (c *appContext) getMoneroFingerprints(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &xmrfingerprint.Filter{
+		FromHeight: 0,
+		ToHeight:   math.MaxInt64,
+		Limit:      defaultXMRFingerprintsLimit,
+	}
+	for _, flagParam := range query["flag"] {
+		for _, flag := range strings.Split(flagParam, ",") {
+			flag = strings.TrimSpace(flag)
+			if flag == "" {
+				continue
+			}
+			if !xmrfingerprint.IsFlag(flag) {
+				http.Error(w, fmt.Sprintf("unknown flag %q", flag), http.StatusBadRequest)
+				return
+			}
+			filter.Flags = append(filter.Flags, flag)
+		}
+	}
+	parseInt := func(name string, dst *int64) bool {
+		param := query.Get(name)
+		if param == "" {
+			return true
+		}
+		val, err := strconv.ParseInt(param, 10, 64)
+		if err != nil || val < 0 {
+			http.Error(w, fmt.Sprintf("invalid %s %q", name, param), http.StatusBadRequest)
+			return false
+		}
+		*dst = val
+		return true
+	}
+	limit, offset := int64(filter.Limit), int64(0)
+	if !parseInt("from", &filter.FromHeight) || !parseInt("to", &filter.ToHeight) ||
+		!parseInt("limit", &limit) || !parseInt("offset", &offset) {
+		return
+	}
+	if limit == 0 {
+		limit = defaultXMRFingerprintsLimit
+	} else if limit > maxXMRFingerprintsLimit {
+		limit = maxXMRFingerprintsLimit
+	}
+	filter.Limit, filter.Offset = int(limit), int(offset)
+
+	fps, err := c.DataSource.XMRTxFingerprints(filter)
+	if err != nil {
+		apiLog.Errorf("Unable to select XMR tx fingerprints: %v", err)
+		http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
+		return
+	}
+
+	writeJSON(w, fps, m.GetIndentCtx(r))
+}
+
 func (c *appContext) getAvgBlockTime(w http.ResponseWriter, r *http.Request) {
 	chartType := "duration-btw-blocks"
 	avgBlockTime, _ := c.charts.GetAverageBlockTime(chartType)
@@ -3940,7 +4095,17 @@ func (c *appContext) getMutilchainCandlestickChart(w http.ResponseWriter, r *htt
 		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
-	chart, err := c.xcBot.MutilchainQuickSticks(token, bin, chainType)
+	from, to, ranged, err := candlestickRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var chart []byte
+	if ranged {
+		chart, err = c.xcBot.HistorySticks(chainType, token, bin, from, to)
+	} else {
+		chart, err = c.xcBot.MutilchainQuickSticks(token, bin, chainType)
+	}
 	if err != nil {
 		apiLog.Infof("QuickSticks error: %v", err)
 		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
@@ -3949,6 +4114,29 @@ func (c *appContext) getMutilchainCandlestickChart(w http.ResponseWriter, r *htt
 	writeJSONBytes(w, chart)
 }
 
+// candlestickRange parses the optional from and to UNIX times of a
+// candlestick chart request. A chart with either of them is served from the
+// exchange history.
+func candlestickRange(r *http.Request) (from, to time.Time, ranged bool, err error) {
+	query := r.URL.Query()
+	for _, p := range []struct {
+		name string
+		t    *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		param := query.Get(p.name)
+		if param == "" {
+			continue
+		}
+		stamp, err := strconv.ParseInt(param, 10, 64)
+		if err != nil || stamp < 0 {
+			return from, to, false, fmt.Errorf("invalid %s %q", p.name, param)
+		}
+		*p.t = time.Unix(stamp, 0)
+		ranged = true
+	}
+	return from, to, ranged, nil
+}
+
 // route: /market/{token}/candlestick/{bin}
 func (c *appContext) getCandlestickChart(w http.ResponseWriter, r *http.Request) {
 	if c.xcBot == nil {
@@ -3962,7 +4150,17 @@ func (c *appContext) getCandlestickChart(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	chart, err := c.xcBot.QuickSticks(token, bin)
+	from, to, ranged, err := candlestickRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var chart []byte
+	if ranged {
+		chart, err = c.xcBot.HistorySticks(mutilchain.TYPEDCR, token, bin, from, to)
+	} else {
+		chart, err = c.xcBot.QuickSticks(token, bin)
+	}
 	if err != nil {
 		apiLog.Infof("QuickSticks error: %v", err)
 		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
@@ -4101,6 +4299,7 @@ func (c *appContext) getAddressTransactions(w http.ResponseWriter, r *http.Reque
 		http.Error(w, http.StatusText(422), 422)
 		return
 	}
+	txs.Label = c.addressLabel(mutilchain.TYPEDCR, address)
 	writeJSON(w, txs, m.GetIndentCtx(r))
 }
 
@@ -4143,6 +4342,7 @@ func (c *appContext) getMultichainDBAddressTransactions(w http.ResponseWriter, r
 		http.Error(w, http.StatusText(422), 422)
 		return
 	}
+	addrInfo.Label = c.addressLabel(chainType, address)
 	writeJSON(w, *addrInfo, m.GetIndentCtx(r))
 }
 
@@ -4182,9 +4382,584 @@ func (c *appContext) getMutilchainAddressTransactions(w http.ResponseWriter, r *
 		http.Error(w, http.StatusText(422), 422)
 		return
 	}
+	txs.Label = c.addressLabel(chainType, address)
 	writeJSON(w, txs, m.GetIndentCtx(r))
 }
 
+// multichainRawTxParam gets the raw transaction or PSBT for the BTC and LTC
+// decodetx and broadcast endpoints, either from the ?hex= query parameter of a
+// GET request or from the body of a POST request. Broadcasts are POST only, so
+// that links and prefetches cannot send transactions.
+func multichainRawTxParam(w http.ResponseWriter, r *http.Request) (string, error) {
+	if r.Method != http.MethodPost {
+		return r.URL.Query().Get("hex"), nil
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+func (c *appContext) decodeMultichainRawTx(w http.ResponseWriter, r *http.Request) {
+	chainType := m.GetChainTypeCtx(r)
+	if chainType != mutilchain.TYPEBTC && chainType != mutilchain.TYPELTC {
+		http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
+		return
+	}
+	rawTx, err := multichainRawTxParam(w, r)
+	if err != nil || strings.TrimSpace(rawTx) == "" {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	tx, err := c.DataSource.MutilchainDecodeRawTransaction(rawTx, chainType)
+	if err != nil {
+		apiLog.Debugf("Unable to decode %s transaction: %v", chainType, err)
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	writeJSON(w, tx, m.GetIndentCtx(r))
+}
+
+// UseMempoolProjector sets the mempool projector for the BTC or LTC chain.
+func (c *appContext) UseMempoolProjector(chainType string, p MempoolProjector) {
+	c.projectorsMtx.Lock()
+	defer c.projectorsMtx.Unlock()
+	if c.projectors == nil {
+		c.projectors = make(map[string]MempoolProjector)
+	}
+	c.projectors[chainType] = p
+}
+
+func (c *appContext) getMultichainProjectedMempool(w http.ResponseWriter, r *http.Request) {
+	chainType := m.GetChainTypeCtx(r)
+	c.projectorsMtx.RLock()
+	projector := c.projectors[chainType]
+	c.projectorsMtx.RUnlock()
+	if projector == nil {
+		http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
+		return
+	}
+	projected := projector.ProjectedMempool()
+	if projected == nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, projected, m.GetIndentCtx(r))
+}
+
+// UseWatchlist enables the watch-list endpoints.
+func (c *appContext) UseWatchlist(wl Watchlist) {
+	c.watchlistMtx.Lock()
+	defer c.watchlistMtx.Unlock()
+	c.watchlist = wl
+}
+
+func (c *appContext) getWatchlist() Watchlist {
+	c.watchlistMtx.RLock()
+	defer c.watchlistMtx.RUnlock()
+	return c.watchlist
+}
+
+// watchRequest is the body of a watch-list registration.
+type watchRequest struct {
+	Chain         string `json:"chain"`
+	Address       string `json:"address"`
+	ViewKey       string `json:"view_key"`
+	CallbackURL   string `json:"callback_url"`
+	Confirmations int    `json:"confirmations"`
+}
+
+// createWatch registers a watch and responds with it and its secret, which
+// signs the deliveries and authorizes the other watch-list endpoints.
+func (c *appContext) createWatch(w http.ResponseWriter, r *http.Request) {
+	wl := c.getWatchlist()
+	if wl == nil {
+		http.Error(w, "watch-list disabled", http.StatusNotFound)
+		return
+	}
+	var req watchRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	// The RealIP middleware sets RemoteAddr behind a trusted proxy.
+	client, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		client = r.RemoteAddr
+	}
+	watch, err := wl.Add(&dbtypes.Watch{
+		Chain:         req.Chain,
+		Address:       req.Address,
+		ViewKey:       req.ViewKey,
+		CallbackURL:   req.CallbackURL,
+		Client:        client,
+		Confirmations: req.Confirmations,
+	})
+	if errors.Is(err, watchlist.ErrInvalidWatch) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("Failed to add watch: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSONWithStatus(w, struct {
+		*dbtypes.Watch
+		Secret string `json:"secret"`
+	}{watch, watch.Secret}, http.StatusCreated, m.GetIndentCtx(r))
+}
+
+// WatchCtx authorizes the watch of the {watchid} path parameter with the
+// X-Watch-Secret header, and embeds it into the request context.
+func (c *appContext) WatchCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wl := c.getWatchlist()
+		if wl == nil {
+			http.Error(w, "watch-list disabled", http.StatusNotFound)
+			return
+		}
+		id, err := strconv.ParseInt(chi.URLParam(r, "watchid"), 10, 64)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		// An unknown watch is reported as unauthorized too, so watch IDs cannot
+		// be enumerated.
+		watch, err := wl.Authorize(id, r.Header.Get("X-Watch-Secret"))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		ctx := context.WithValue(r.Context(), m.CtxWatch, watch)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (c *appContext) getWatch(w http.ResponseWriter, r *http.Request) {
+	watch, _ := r.Context().Value(m.CtxWatch).(*dbtypes.Watch)
+	writeJSON(w, watch, m.GetIndentCtx(r))
+}
+
+func (c *appContext) deleteWatch(w http.ResponseWriter, r *http.Request) {
+	watch, _ := r.Context().Value(m.CtxWatch).(*dbtypes.Watch)
+	if err := c.getWatchlist().Remove(watch.ID); err != nil {
+		apiLog.Errorf("Failed to remove watch %d: %v", watch.ID, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getWatchDeliveries serves the delivery log of a watch, the latest first, up
+// to ?limit= deliveries (default 100, max 1000).
+func (c *appContext) getWatchDeliveries(w http.ResponseWriter, r *http.Request) {
+	watch, _ := r.Context().Value(m.CtxWatch).(*dbtypes.Watch)
+	limit := 100
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 || n > 1000 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	deliveries, err := c.getWatchlist().Deliveries(watch.ID, limit)
+	if err != nil {
+		apiLog.Errorf("Failed to retrieve the deliveries of watch %d: %v", watch.ID, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, deliveries, m.GetIndentCtx(r))
+}
+
+// xpubRequest is the body of an extended public key wallet request. Keys are
+// posted rather than put in the URL so they stay out of access logs.
+type xpubRequest struct {
+	Chain    string `json:"chain"`
+	Key      string `json:"key"`
+	GapLimit int    `json:"gap_limit"`
+}
+
+// getHDWallet responds with the used addresses, balance, UTXOs and latest
+// transactions of an extended public key or output descriptor.
+func (c *appContext) getHDWallet(w http.ResponseWriter, r *http.Request) {
+	var req xpubRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<14)).Decode(&req); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	if req.Chain == "" {
+		req.Chain = mutilchain.TYPEDCR
+	}
+	if req.GapLimit == 0 {
+		req.GapLimit = hdwallet.DefaultGapLimit
+	}
+	if req.GapLimit < 1 || req.GapLimit > hdwallet.MaxGapLimit {
+		http.Error(w, fmt.Sprintf("gap_limit must be between 1 and %d", hdwallet.MaxGapLimit),
+			http.StatusBadRequest)
+		return
+	}
+	wallet, err := c.DataSource.HDWallet(req.Chain, req.Key, req.GapLimit)
+	if errors.Is(err, hdwallet.ErrInvalidKey) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, hdwallet.ErrScanLimit) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("HDWallet: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("HDWallet: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, wallet, m.GetIndentCtx(r))
+}
+
+// coSpentLimit is the number of co-spent addresses of an address cluster
+// response.
+const coSpentLimit = 50
+
+// addressClusterResponse is the cluster of an address, with the addresses
+// most often spent together with it.
+type addressClusterResponse struct {
+	Address string                    `json:"address"`
+	Cluster *dbtypes.AddressCluster   `json:"cluster"`
+	CoSpent []*dbtypes.CoSpentAddress `json:"co_spent"`
+}
+
+// clusterChain reports whether the chain is clustered.
+func clusterChain(chainType string) bool {
+	return chainType == mutilchain.TYPEDCR || chainType == mutilchain.TYPEBTC ||
+		chainType == mutilchain.TYPELTC
+}
+
+// getAddressCluster serves the cluster of an address and the addresses spent
+// together with it.
+func (c *appContext) getAddressCluster(w http.ResponseWriter, r *http.Request) {
+	chainType, address := m.GetMultichainAddressCtx(r)
+	if !clusterChain(chainType) || address == "" {
+		http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
+		return
+	}
+	cluster, err := c.DataSource.AddressCluster(chainType, address)
+	if err == nil {
+		resp := &addressClusterResponse{Address: address, Cluster: cluster}
+		resp.CoSpent, err = c.DataSource.CoSpentAddresses(chainType, address, coSpentLimit)
+		if err == nil {
+			writeJSON(w, resp, m.GetIndentCtx(r))
+			return
+		}
+	}
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("AddressCluster: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	apiLog.Errorf("AddressCluster(%s, %s): %v", chainType, address, err)
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+// getCluster serves a cluster with a page of its addresses, by ?limit=
+// (default 100, max 1000) and ?offset=.
+func (c *appContext) getCluster(w http.ResponseWriter, r *http.Request) {
+	chainType := m.GetChainTypeCtx(r)
+	id, err := strconv.ParseInt(chi.URLParam(r, "clusterid"), 10, 64)
+	if !clusterChain(chainType) || err != nil || id < 1 {
+		http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
+		return
+	}
+	limit, offset := 100, 0
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 || n > 1000 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	if o := r.URL.Query().Get("offset"); o != "" {
+		n, err := strconv.Atoi(o)
+		if err != nil || n < 0 {
+			http.Error(w, "invalid offset", http.StatusBadRequest)
+			return
+		}
+		offset = n
+	}
+	cluster, err := c.DataSource.Cluster(chainType, id, limit, offset)
+	if errors.Is(err, dbtypes.ErrNoResult) {
+		http.Error(w, "cluster not found", http.StatusNotFound)
+		return
+	}
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("Cluster: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("Cluster(%s, %d): %v", chainType, id, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, cluster, m.GetIndentCtx(r))
+}
+
+// getRichList serves a page of the addresses of a chain with the largest
+// balances, and the last wealth snapshot of the chain.
+func (c *appContext) getRichList(w http.ResponseWriter, r *http.Request) {
+	chainType := m.GetChainTypeCtx(r)
+	if chainType != mutilchain.TYPEDCR && chainType != mutilchain.TYPEBTC &&
+		chainType != mutilchain.TYPELTC {
+		http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
+		return
+	}
+	limit, offset := 100, 0
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 || n > 1000 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	if o := r.URL.Query().Get("offset"); o != "" {
+		n, err := strconv.Atoi(o)
+		if err != nil || n < 0 {
+			http.Error(w, "invalid offset", http.StatusBadRequest)
+			return
+		}
+		offset = n
+	}
+	richList, err := c.DataSource.RichList(chainType, limit, offset)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("RichList: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("RichList(%s): %v", chainType, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, richList, m.GetIndentCtx(r))
+}
+
+// addressLabel returns the label name of an address of a chain, or an empty
+// string.
+func (c *appContext) addressLabel(chain, address string) string {
+	if label := c.DataSource.AddressLabel(chain, address); label != nil {
+		return label.Name
+	}
+	return ""
+}
+
+// outputLabel returns the label name of the first labeled address of a
+// transaction output, or an empty string.
+func (c *appContext) outputLabel(chain string, addrs []string) string {
+	if len(addrs) == 0 {
+		return ""
+	}
+	names := c.DataSource.AddressLabels(chain, addrs)
+	for _, addr := range addrs {
+		if name := names[addr]; name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// getLabels serves the address labels of a chain by name.
+func (c *appContext) getLabels(w http.ResponseWriter, r *http.Request) {
+	chainType := m.GetChainTypeCtx(r)
+	if !labels.SupportedChain(chainType) {
+		http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
+		return
+	}
+	writeJSON(w, c.DataSource.Labels(chainType), m.GetIndentCtx(r))
+}
+
+// searchLabels serves the address labels of all the chains whose name
+// contains the ?q= query, or whose address is the query, up to ?limit= labels
+// (default 100, max 1000).
+func (c *appContext) searchLabels(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "missing query", http.StatusBadRequest)
+		return
+	}
+	limit := 100
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 || n > 1000 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	matches := c.DataSource.SearchLabels(query, limit)
+	if matches == nil {
+		matches = []*labels.Match{}
+	}
+	writeJSON(w, matches, m.GetIndentCtx(r))
+}
+
+// LabelsAdminCtx authorizes the management of the address labels with the
+// X-Admin-Key header. The management endpoints are not found if no admin key
+// is configured.
+func (c *appContext) LabelsAdminCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c.labelsAdminKey == "" {
+			http.NotFound(w, r)
+			return
+		}
+		key := r.Header.Get("X-Admin-Key")
+		if subtle.ConstantTimeCompare([]byte(key), []byte(c.labelsAdminKey)) != 1 {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// labelRequest is the body of an address label update.
+type labelRequest struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Link     string `json:"link"`
+}
+
+// putLabel sets the label of an address of a chain, overriding the label of
+// the labels file, if any.
+func (c *appContext) putLabel(w http.ResponseWriter, r *http.Request) {
+	chainType, address := m.GetMultichainAddressCtx(r)
+	if !labels.SupportedChain(chainType) {
+		http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
+		return
+	}
+	var req labelRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<12)).Decode(&req); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	label := &labels.Label{
+		Address:  address,
+		Name:     strings.TrimSpace(req.Name),
+		Category: req.Category,
+		Link:     req.Link,
+	}
+	if err := label.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := c.DataSource.PutAddressLabel(chainType, label); err != nil {
+		apiLog.Errorf("PutAddressLabel(%s, %s): %v", chainType, address, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, label, m.GetIndentCtx(r))
+}
+
+// deleteLabel removes the label set for an address of a chain. The label of
+// the labels file, if any, applies again.
+func (c *appContext) deleteLabel(w http.ResponseWriter, r *http.Request) {
+	chainType, address := m.GetMultichainAddressCtx(r)
+	err := c.DataSource.DeleteAddressLabel(chainType, address)
+	if errors.Is(err, dbtypes.ErrNoResult) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("DeleteAddressLabel(%s, %s): %v", chainType, address, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getTxTrace serves the flow of funds from a transaction output, following
+// its spends forward or its funding backward. See txtrace.ParseRequest for the
+// query parameters.
+func (c *appContext) getTxTrace(w http.ResponseWriter, r *http.Request) {
+	chainType := m.GetChainTypeCtx(r)
+	req, err := txtrace.ParseRequest(chainType, r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	graph, err := txtrace.Trace(c.DataSource, req)
+	if errors.Is(err, txtrace.ErrInvalidTrace) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, txtrace.ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("Trace: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("Trace(%s, %s:%d): %v", chainType, req.TxHash, req.Vout, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, graph, m.GetIndentCtx(r))
+}
+
+// getMultichainPoolShare serves the share of the blocks mined by each pool of
+// a BTC or LTC chain, binned by day (default) or week.
+func (c *appContext) getMultichainPoolShare(w http.ResponseWriter, r *http.Request) {
+	chainType := m.GetChainTypeCtx(r)
+	if chainType != mutilchain.TYPEBTC && chainType != mutilchain.TYPELTC {
+		http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
+		return
+	}
+	bin := r.URL.Query().Get("bin")
+	if bin == "" {
+		bin = "day"
+	}
+	if _, ok := dbtypes.PoolShareBins[bin]; !ok {
+		http.Error(w, "invalid bin", http.StatusBadRequest)
+		return
+	}
+	chart, err := c.DataSource.MutilchainPoolShareChart(chainType, bin)
+	if err != nil {
+		apiLog.Errorf("MutilchainPoolShareChart(%s, %s): %v", chainType, bin, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, chart, m.GetIndentCtx(r))
+}
+
+func (c *appContext) broadcastMultichainTx(w http.ResponseWriter, r *http.Request) {
+	chainType := m.GetChainTypeCtx(r)
+	if chainType != mutilchain.TYPEBTC && chainType != mutilchain.TYPELTC {
+		http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
+		return
+	}
+	rawTx, err := multichainRawTxParam(w, r)
+	if err != nil || strings.TrimSpace(rawTx) == "" {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	txid, err := c.DataSource.MutilchainSendRawTransaction(rawTx, chainType)
+	if err != nil {
+		apiLog.Errorf("Broadcast %s transaction failed. Error: %v", chainType, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, txid, m.GetIndentCtx(r))
+}
+
 // getAddressTransactionsRaw handles the various /address/{addr}/.../raw API
 // endpoints.
 func (c *appContext) getAddressesTxs(w http.ResponseWriter, r *http.Request) {
@@ -4404,6 +5179,17 @@ func (c *appContext) getCurrencyCodes(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, codes, m.GetIndentCtx(r))
 }
 
+// getExchangeQuality returns the data quality of the exchanges of each market:
+// their deviation from the VWAP, staleness, suspicious volume and whether they
+// are excluded from the index, and the spread across the aggregated order book.
+func (c *appContext) getExchangeQuality(w http.ResponseWriter, r *http.Request) {
+	if c.xcBot == nil {
+		http.Error(w, "Exchange monitoring disabled.", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, c.xcBot.Quality(), m.GetIndentCtx(r))
+}
+
 // getAgendasData returns high level agendas details that includes Name,
 // Description, Vote Version, VotingDone height, Activated, HardForked,
 // StartTime and ExpireTime.
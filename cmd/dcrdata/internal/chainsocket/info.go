@@ -75,6 +75,10 @@ const (
 	DifficultyAdjustmentKey = "da"
 	LitecoinSocketURL       = "wss://litecoinspace.org/api/v1/ws"
 	BitcoinSocketURL        = "wss://mempool.space/api/v1/ws"
+
+	// daCrossCheckTolerance is the difference, in percentage points, between
+	// the local and external estimated difficulty change that is logged.
+	daCrossCheckTolerance = 1.0
 )
 
 func NewMutilchainInfoSocket(explorer *explorer.ExplorerUI, chainType string) (*MutilchainInfoSocket, error) {
@@ -305,6 +309,10 @@ func (sk *MutilchainInfoSocket) processWsMessage(raw []byte) {
 	sk.wsUpdated()
 }
 
+// HandlerDiffifultyAdjustmentData cross-checks the difficulty adjustment
+// reported by the external websocket against the one computed locally from our
+// own node. The external data is only used when there is no local estimate,
+// and divergences beyond daCrossCheckTolerance are logged.
 func (sk *MutilchainInfoSocket) HandlerDiffifultyAdjustmentData(raw []byte) {
 	var response MempoolInfoData
 	parseErr := json.Unmarshal(raw, &response)
@@ -324,12 +332,26 @@ func (sk *MutilchainInfoSocket) HandlerDiffifultyAdjustmentData(raw []byte) {
 	if err != nil {
 		return
 	}
-	homeInfo.DifficultyChange = ConvertAnyToFloat(daMap["difficultyChange"])
-	homeInfo.PreviousRetarget = ConvertAnyToFloat(daMap["previousRetarget"])
-	homeInfo.RemainingBlocks = ConvertAnyToInt(daMap["remainingBlocks"])
-	homeInfo.TimeRemaining = ConvertAnyToInt(daMap["remainingTime"])
-	homeInfo.BlockTimeAvg = ConvertAnyToInt(daMap["timeAvg"])
-	sk.UpdateMutilchainHomeInfo(homeInfo)
+	difficultyChange := ConvertAnyToFloat(daMap["difficultyChange"])
+	remainingBlocks := ConvertAnyToInt(daMap["remainingBlocks"])
+	if homeInfo.RemainingBlocks == 0 && homeInfo.RetargetProgress == 0 {
+		// No local estimate yet.
+		homeInfo.DifficultyChange = difficultyChange
+		homeInfo.PreviousRetarget = ConvertAnyToFloat(daMap["previousRetarget"])
+		homeInfo.RemainingBlocks = remainingBlocks
+		homeInfo.TimeRemaining = ConvertAnyToInt(daMap["remainingTime"])
+		homeInfo.BlockTimeAvg = ConvertAnyToInt(daMap["timeAvg"])
+		sk.UpdateMutilchainHomeInfo(homeInfo)
+		return
+	}
+	// The remaining blocks may differ by one when the external source is a
+	// block ahead or behind.
+	if math.Abs(difficultyChange-homeInfo.DifficultyChange) > daCrossCheckTolerance ||
+		math.Abs(float64(remainingBlocks-homeInfo.RemainingBlocks)) > 1 {
+		log.Printf("%s difficulty adjustment differs from %s: local change %.2f%% with %d blocks remaining, "+
+			"external change %.2f%% with %d blocks remaining", sk.ChainType, sk.apiUrl,
+			homeInfo.DifficultyChange, homeInfo.RemainingBlocks, difficultyChange, remainingBlocks)
+	}
 }
 
 func (sk *MutilchainInfoSocket) HandlerSimpleBlockData(raw []byte) {
@@ -41,6 +41,7 @@ import (
 	"github.com/decred/dcrdata/v8/mempool"
 	"github.com/decred/dcrdata/v8/mutilchain"
 	"github.com/decred/dcrdata/v8/mutilchain/externalapi"
+	"github.com/decred/dcrdata/v8/mutilchain/labels"
 	pstypes "github.com/decred/dcrdata/v8/pubsub/types"
 	"github.com/decred/dcrdata/v8/txhelpers"
 	"github.com/decred/dcrdata/v8/utils"
@@ -76,6 +77,10 @@ const (
 
 	MaxTreasuryRows int64 = 200
 
+	// maxMempoolEventsDisplayed is the number of recent replacements and
+	// evictions shown on the BTC/LTC mempool pages.
+	maxMempoolEventsDisplayed = 25
+
 	testnetNetName = "Testnet"
 )
 
@@ -146,6 +151,8 @@ type explorerDataSource interface {
 	GetTip() (*types.WebBasicBlock, error)
 	DecodeRawTransaction(txhex string) (*chainjson.TxRawResult, error)
 	SendRawTransaction(txhex string) (string, error)
+	MutilchainDecodeRawTransaction(rawTx, chainType string) (*txhelpers.DecodedMultichainTx, error)
+	MutilchainSendRawTransaction(rawTx, chainType string) (string, error)
 	GetTransactionByHash(txid string) (*wire.MsgTx, error)
 	GetLTCTransactionByHash(txid string) (*ltcutil.Tx, error)
 	GetBTCTransactionByHash(txid string) (*btcutil.Tx, error)
@@ -167,8 +174,10 @@ type explorerDataSource interface {
 	MutilchainGetTotalVoutsCount(chainType string) int64
 	MutilchainGetTotalAddressesCount(chainType string) int64
 	MutilchainGetBlockchainInfo(chainType string) (*mutilchain.BlockchainInfo, error)
+	MutilchainDifficultyAdjustment(chainType string, height, blockTime int64, difficulty float64) (*mutilchain.DifficultyAdjustment, error)
 	MutilchainValidBlockhash(hash string, chainType string) bool
 	MutilchainValidTxhash(hash string, chainType string) bool
+	XMRKeyImageTx(keyImage string) (string, int64, error)
 	MutilchainBestBlockTime(chainType string) int64
 	GetDecredBlockchainSize() int64
 	GetDecredTotalTransactions() int64
@@ -189,6 +198,16 @@ type explorerDataSource interface {
 	GetMutilchainVoutIndexsOfContract(contractTx, chainType string) ([]int, error)
 	GetMutilchainVinIndexsOfRedeem(spendTx, chainType string) ([]int, error)
 	GetLast5PoolDataList() ([]*dbtypes.PoolDataItem, error)
+	HDWallet(chainType, key string, gapLimit int) (*dbtypes.HDWallet, error)
+	AddressClusterSize(chain, address string) (id, size int64, err error)
+	Cluster(chain string, id int64, limit, offset int) (*dbtypes.AddressCluster, error)
+	RichList(chain string, limit, offset int) (*dbtypes.RichList, error)
+	AddressLabel(chain, address string) *labels.Label
+	AddressLabels(chain string, addrs []string) map[string]string
+	SearchLabels(query string, limit int) []*labels.Match
+	CoSpentAddresses(chain, address string, limit int) ([]*dbtypes.CoSpentAddress, error)
+	TraceTx(chain, txHash string) (*dbtypes.TraceTx, error)
+	MixingSummary(height int64) (*dbtypes.MixingSummary, error)
 	GetExplorerBlockBasic(height int) *types.BlockBasic
 	GetAvgBlockFormattedSize() (string, error)
 	GetBwDashData() (int64, int64, int64)
@@ -229,6 +248,16 @@ type agendaBackend interface {
 	UpdateAgendas() error
 }
 
+// MempoolTracker provides the replacements (RBF), evictions, CPFP packages and
+// projected blocks tracked by a BTC or LTC mempool monitor.
+type MempoolTracker interface {
+	Replacements() []types.MempoolReplacement
+	Evictions() []types.MempoolEviction
+	ReplacedBy(txid string) *types.MempoolReplacement
+	TxPackage(txid string) *types.MempoolTxPackage
+	ProjectedMempool() *types.MutilchainProjectedMempool
+}
+
 // ChartDataSource provides data from the charts cache.
 type ChartDataSource interface {
 	AnonymitySet() uint64
@@ -362,6 +391,7 @@ type ExplorerUI struct {
 	ChainParams      *chaincfg.Params
 	BtcChainParams   *btcchaincfg.Params
 	LtcChainParams   *ltcchaincfg.Params
+	XmrNet           string
 	ChainDisabledMap map[string]bool
 	Version          string
 	NetName          string
@@ -377,6 +407,7 @@ type ExplorerUI struct {
 	invs                *types.MempoolInfo
 	LtcMempoolInfo      *types.MutilchainMempoolInfo
 	BtcMempoolInfo      *types.MutilchainMempoolInfo
+	mempoolTrackers     map[string]MempoolTracker
 	premine             int64
 	CoinCaps            []string
 	CoinCapDataList     []*dbtypes.MarketCapData
@@ -451,6 +482,7 @@ type ExplorerConfig struct {
 	ChainDisabledMap map[string]bool
 	CoinCaps         []string
 	MainHost         string
+	XmrNet           string
 }
 
 // New returns an initialized instance of explorerUI
@@ -498,6 +530,7 @@ func New(cfg *ExplorerConfig) *ExplorerUI {
 	exp.ChainParams = params
 	exp.BtcChainParams = btcParams
 	exp.LtcChainParams = ltcParams
+	exp.XmrNet = cfg.XmrNet
 	exp.NetName = netName(exp.ChainParams)
 	exp.MeanVotingBlocks = txhelpers.CalcMeanVotingBlocks(params)
 	exp.premine = params.BlockOneSubsidy()
@@ -568,7 +601,7 @@ func New(cfg *ExplorerConfig) *ExplorerUI {
 		"chain_address", "chain_mempool", "chain_charts", "chain_market",
 		"chain_addresstable", "supply", "marketlist", "chain_parameters",
 		"whatsnew", "chain_visualblocks", "bwdash", "atomicswaps", "atomicswaps_table",
-		"about", "xmr_mempool"}
+		"about", "xmr_mempool", "chain_rawtx", "chain_pools", "search", "xpub", "cluster", "txgraph", "mixing", "richlist"}
 
 	for _, name := range tmpls {
 		if err := exp.templates.addTemplate(name); err != nil {
@@ -660,6 +693,24 @@ func (exp *ExplorerUI) MutilchainMempoolInfo(chainType string) *types.Mutilchain
 	}
 }
 
+// UseMempoolTracker sets the mempool tracker for the BTC or LTC chain.
+func (exp *ExplorerUI) UseMempoolTracker(chainType string, tracker MempoolTracker) {
+	exp.invsMtx.Lock()
+	defer exp.invsMtx.Unlock()
+	if exp.mempoolTrackers == nil {
+		exp.mempoolTrackers = make(map[string]MempoolTracker)
+	}
+	exp.mempoolTrackers[chainType] = tracker
+}
+
+// MempoolTracker returns the mempool tracker for the chain, or nil if there is
+// none.
+func (exp *ExplorerUI) MempoolTracker(chainType string) MempoolTracker {
+	exp.invsMtx.RLock()
+	defer exp.invsMtx.RUnlock()
+	return exp.mempoolTrackers[chainType]
+}
+
 // MempoolID safely fetches the current mempool inventory ID.
 func (exp *ExplorerUI) MempoolID() uint64 {
 	exp.invsMtx.RLock()
@@ -1054,6 +1105,19 @@ func (exp *ExplorerUI) BTCStore(blockData *blockdatabtc.BlockData, msgBlock *btc
 		blockchainInfo, chainErr = exp.dataSource.MutilchainGetBlockchainInfo(mutilchain.TYPEBTC)
 	}()
 
+	// Compute the progress of the difficulty retarget epoch
+	var diffAdjustment *mutilchain.DifficultyAdjustment
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var err error
+		diffAdjustment, err = exp.dataSource.MutilchainDifficultyAdjustment(mutilchain.TYPEBTC,
+			int64(blockData.Header.Height), blockData.Header.Time, blockData.Header.Difficulty)
+		if err != nil {
+			log.Warnf("BTC: Compute difficulty adjustment failed. %v", err)
+		}
+	}()
+
 	wg.Wait()
 
 	if newBlockData == nil {
@@ -1123,6 +1187,9 @@ func (exp *ExplorerUI) BTCStore(blockData *blockdatabtc.BlockData, msgBlock *btc
 	p.HomeInfo.SubsidyInterval = int64(exp.BtcChainParams.SubsidyReductionInterval)
 	p.HomeInfo.Volume24hFloat = volume24h
 	p.HomeInfo.Volume24h = volume24hInt
+	if diffAdjustment != nil {
+		p.HomeInfo.SetDifficultyAdjustment(diffAdjustment)
+	}
 	if hasSwapData {
 		p.HomeInfo.SwapsTotalContract = swapsTotalContract
 		p.HomeInfo.SwapsTotalAmount = swapsTotalAmount
@@ -1372,6 +1439,19 @@ func (exp *ExplorerUI) LTCStore(blockData *blockdataltc.BlockData, msgBlock *ltc
 		blockchainInfo, chainErr = exp.dataSource.MutilchainGetBlockchainInfo(mutilchain.TYPELTC)
 	}()
 
+	// Compute the progress of the difficulty retarget epoch
+	var diffAdjustment *mutilchain.DifficultyAdjustment
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var err error
+		diffAdjustment, err = exp.dataSource.MutilchainDifficultyAdjustment(mutilchain.TYPELTC,
+			int64(blockData.Header.Height), blockData.Header.Time, blockData.Header.Difficulty)
+		if err != nil {
+			log.Warnf("LTC: Compute difficulty adjustment failed. %v", err)
+		}
+	}()
+
 	wg.Wait()
 
 	if newBlockData == nil {
@@ -1441,6 +1521,9 @@ func (exp *ExplorerUI) LTCStore(blockData *blockdataltc.BlockData, msgBlock *ltc
 	p.HomeInfo.SubsidyInterval = int64(exp.LtcChainParams.SubsidyReductionInterval)
 	p.HomeInfo.Volume24hFloat = volume24h
 	p.HomeInfo.Volume24h = volume24hInt
+	if diffAdjustment != nil {
+		p.HomeInfo.SetDifficultyAdjustment(diffAdjustment)
+	}
 	if hasSwapData {
 		p.HomeInfo.SwapsTotalContract = swapsTotalContract
 		p.HomeInfo.SwapsTotalAmount = swapsTotalAmount
@@ -1498,8 +1581,13 @@ func (exp *ExplorerUI) LTCStore(blockData *blockdataltc.BlockData, msgBlock *ltc
 	return nil
 }
 
+// XMRMempoolSaver receives each poll of the Monero mempool.
+type XMRMempoolSaver interface {
+	StoreXMRMempool(mp *xmrutil.Mempool)
+}
+
 // Generate xmr updates for mempool data
-func (exp *ExplorerUI) UpdateXMRMempoolData(xmrClient *xmrclient.XMRClient, stop <-chan struct{}) error {
+func (exp *ExplorerUI) UpdateXMRMempoolData(xmrClient *xmrclient.XMRClient, stop <-chan struct{}, savers ...XMRMempoolSaver) error {
 	xmrMempoolUpdateInterval := 15 * time.Second
 	ticker := time.NewTicker(xmrMempoolUpdateInterval)
 	defer ticker.Stop()
@@ -1574,6 +1662,10 @@ func (exp *ExplorerUI) UpdateXMRMempoolData(xmrClient *xmrclient.XMRClient, stop
 			exp.XmrPageData.MempoolData = &mp
 			exp.XmrPageData.Unlock()
 
+			for _, saver := range savers {
+				saver.StoreXMRMempool(&mp)
+			}
+
 			// send to websocket
 			go func() {
 				select {
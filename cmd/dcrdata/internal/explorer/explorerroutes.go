@@ -36,9 +36,12 @@ import (
 	pitypes "github.com/decred/dcrdata/gov/v6/politeia/types"
 	"github.com/decred/dcrdata/v8/db/dbtypes"
 	"github.com/decred/dcrdata/v8/explorer/types"
+	"github.com/decred/dcrdata/v8/hdwallet"
 	"github.com/decred/dcrdata/v8/mutilchain"
 	"github.com/decred/dcrdata/v8/mutilchain/externalapi"
+	"github.com/decred/dcrdata/v8/mutilchain/labels"
 	"github.com/decred/dcrdata/v8/txhelpers"
+	"github.com/decred/dcrdata/v8/txtrace"
 	"github.com/decred/dcrdata/v8/utils"
 	ticketvotev1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
 	humanize "github.com/dustin/go-humanize"
@@ -1482,6 +1485,17 @@ func (exp *ExplorerUI) MutilchainTxPage(w http.ResponseWriter, r *http.Request)
 		exp.StatusPage(w, defaultErrorCode, "XMR: Get transaction by ID failed", "", ExpStatusError)
 		return
 	} else if tx == nil {
+		// A transaction dropped from the mempool by a replacement (RBF) is
+		// unknown to the node and the DB.
+		if tracker := exp.MempoolTracker(chainType); tracker != nil {
+			if rep := tracker.ReplacedBy(hash); rep != nil {
+				exp.StatusPage(w, "transaction replaced",
+					fmt.Sprintf("Transaction %s was replaced by %s (fee +%s).", hash, rep.ReplacedBy,
+						humanize.Commaf(exp.GetCointAmountByTypeChain(rep.FeeDelta, chainType))),
+					rep.ReplacedBy, ExpStatusNotFound)
+				return
+			}
+		}
 		log.Warnf("No transaction information for %v. Trying tables in case this is an orphaned txn.", hash)
 		// Search for occurrences of the transaction in the database.
 		dbTxs, err := exp.dataSource.MutilchainTransaction(hash, chainType)
@@ -1689,6 +1703,8 @@ func (exp *ExplorerUI) MutilchainTxPage(w http.ResponseWriter, r *http.Request)
 		SwapFirstSource *dbtypes.AtomicSwapForTokenData
 		TargetToken     string
 		IsRefund        bool
+		MempoolPackage  *types.MempoolTxPackage
+		Labels          map[string]string
 		Conversions     struct {
 			Total *exchanges.Conversion
 			Fees  *exchanges.Conversion
@@ -1700,6 +1716,13 @@ func (exp *ExplorerUI) MutilchainTxPage(w http.ResponseWriter, r *http.Request)
 		SwapsFound:      swapsInfo.Found,
 		SwapFirstSource: swapFirstSource,
 		IsRefund:        isRefund,
+		Labels:          exp.txLabels(chainType, tx),
+	}
+	// Unconfirmed BTC/LTC transactions have RBF and CPFP package info.
+	if tx.Confirmations == 0 {
+		if tracker := exp.MempoolTracker(chainType); tracker != nil {
+			pageData.MempoolPackage = tracker.TxPackage(hash)
+		}
 	}
 	// Get a fiat-converted value for the total and the fees.
 	if exp.xcBot != nil {
@@ -1987,19 +2010,51 @@ func (exp *ExplorerUI) MutilchainMempool(w http.ResponseWriter, r *http.Request)
 
 	// Prevent modifications to the shared inventory struct (e.g. in the
 	// MempoolMonitor) while marshaling the inventory.
-	mempoolInfo.RLock()
 	template := "chain_mempool"
 	if chainType == mutilchain.TYPEXMR {
 		template = "xmr_mempool"
 	}
+	// Recent replacements and evictions, and the projected blocks, from the
+	// BTC/LTC mempool monitor.
+	var replacements []types.MempoolReplacement
+	var evictions []types.MempoolEviction
+	var projected *types.MutilchainProjectedMempool
+	var histogramMaxVSize int64
+	if tracker := exp.MempoolTracker(chainType); tracker != nil {
+		replacements = tracker.Replacements()
+		evictions = tracker.Evictions()
+		projected = tracker.ProjectedMempool()
+		if projected != nil {
+			for _, bin := range projected.Histogram {
+				if bin.VSize > histogramMaxVSize {
+					histogramMaxVSize = bin.VSize
+				}
+			}
+		}
+		if len(replacements) > maxMempoolEventsDisplayed {
+			replacements = replacements[:maxMempoolEventsDisplayed]
+		}
+		if len(evictions) > maxMempoolEventsDisplayed {
+			evictions = evictions[:maxMempoolEventsDisplayed]
+		}
+	}
+	mempoolInfo.RLock()
 	str, err := exp.templates.exec(template, struct {
 		*CommonPageData
-		Mempool   *types.MutilchainMempoolInfo
-		ChainType string
+		Mempool           *types.MutilchainMempoolInfo
+		ChainType         string
+		Replacements      []types.MempoolReplacement
+		Evictions         []types.MempoolEviction
+		Projected         *types.MutilchainProjectedMempool
+		HistogramMaxVSize int64
 	}{
-		CommonPageData: exp.commonData(r),
-		Mempool:        mempoolInfo,
-		ChainType:      chainType,
+		CommonPageData:    exp.commonData(r),
+		Mempool:           mempoolInfo,
+		ChainType:         chainType,
+		Replacements:      replacements,
+		Evictions:         evictions,
+		Projected:         projected,
+		HistogramMaxVSize: histogramMaxVSize,
 	})
 	mempoolInfo.RUnlock()
 
@@ -2548,6 +2603,7 @@ func (exp *ExplorerUI) TxPage(w http.ResponseWriter, r *http.Request) {
 		SwapFirstSource      *dbtypes.AtomicSwapForTokenData
 		TargetToken          string
 		IsRefund             bool
+		Labels               map[string]string
 		Conversions          struct {
 			Total *exchanges.Conversion
 			Fees  *exchanges.Conversion
@@ -2564,6 +2620,7 @@ func (exp *ExplorerUI) TxPage(w http.ResponseWriter, r *http.Request) {
 		SwapsFound:           swapsInfo.Found,
 		TargetToken:          targetToken,
 		IsRefund:             isRefund,
+		Labels:               exp.txLabels(mutilchain.TYPEDCR, tx),
 	}
 
 	// Get a fiat-converted value for the total and the fees.
@@ -2828,6 +2885,8 @@ func (exp *ExplorerUI) AddressPage(w http.ResponseWriter, r *http.Request) {
 		CRLFDownload bool
 		FiatBalance  *exchanges.Conversion
 		Pages        []pageNumber
+		Cluster      *addressClusterData
+		Label        *labels.Label
 	}
 
 	// Grab the URL query parameters
@@ -2921,6 +2980,10 @@ func (exp *ExplorerUI) AddressPage(w http.ResponseWriter, r *http.Request) {
 		FiatBalance:    conversion,
 		Pages:          calcPages(int(addrData.TxnCount), int(limitN), int(offsetAddrOuts), linkTemplate),
 	}
+	if !isZeroAddress {
+		pageData.Cluster = exp.addressCluster(mutilchain.TYPEDCR, address)
+		pageData.Label = exp.dataSource.AddressLabel(mutilchain.TYPEDCR, address)
+	}
 	str, err := exp.templates.exec("address", pageData)
 	if err != nil {
 		log.Errorf("Template execute failure: %v", err)
@@ -2953,6 +3016,8 @@ func (exp *ExplorerUI) MutilchainAddressPage(w http.ResponseWriter, r *http.Requ
 		Pages     []pageNumber
 		ChainType string
 		Maintain  bool
+		Cluster   *addressClusterData
+		Label     *labels.Label
 	}
 
 	// Grab the URL query parameters
@@ -3004,6 +3069,8 @@ func (exp *ExplorerUI) MutilchainAddressPage(w http.ResponseWriter, r *http.Requ
 		ChainType:      chainType,
 		Pages:          calcPages(int(addrData.TxnCount), int(limitN), int(offsetAddrOuts), linkTemplate),
 		Maintain:       false,
+		Cluster:        exp.addressCluster(chainType, address),
+		Label:          exp.dataSource.AddressLabel(chainType, address),
 	}
 	str, err := exp.templates.exec("chain_address", pageData)
 	if err != nil {
@@ -3541,6 +3608,56 @@ func (exp *ExplorerUI) DecodeTxPage(w http.ResponseWriter, r *http.Request) {
 	io.WriteString(w, str)
 }
 
+// MutilchainDecodeTxPage handles the "decode/broadcast transaction" page for
+// BTC and LTC. Both raw transactions and PSBTs are accepted.
+func (exp *ExplorerUI) MutilchainDecodeTxPage(w http.ResponseWriter, r *http.Request) {
+	chainType := chi.URLParam(r, "chaintype")
+	if chainType != mutilchain.TYPEBTC && chainType != mutilchain.TYPELTC {
+		exp.StatusPage(w, defaultErrorCode, "Transaction decoding is not supported for this chain", "", ExpStatusNotSupported)
+		return
+	}
+	str, err := exp.templates.exec("chain_rawtx", struct {
+		*CommonPageData
+		ChainType string
+	}{
+		CommonPageData: exp.commonData(r),
+		ChainType:      chainType,
+	})
+	if err != nil {
+		log.Errorf("Template execute failure: %v", err)
+		exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html")
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, str)
+}
+
+// MutilchainPoolsPage is the page handler for the "/{chaintype}/pools" path.
+// It shows the share of the blocks mined by each BTC or LTC pool.
+func (exp *ExplorerUI) MutilchainPoolsPage(w http.ResponseWriter, r *http.Request) {
+	chainType := chi.URLParam(r, "chaintype")
+	if chainType != mutilchain.TYPEBTC && chainType != mutilchain.TYPELTC {
+		exp.StatusPage(w, defaultErrorCode, "Mining pool attribution is not supported for this chain", "", ExpStatusNotSupported)
+		return
+	}
+	str, err := exp.templates.exec("chain_pools", struct {
+		*CommonPageData
+		ChainType string
+	}{
+		CommonPageData: exp.commonData(r),
+		ChainType:      chainType,
+	})
+	if err != nil {
+		log.Errorf("Template execute failure: %v", err)
+		exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html")
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, str)
+}
+
 // Charts handles the charts displays showing the various charts plotted.
 func (exp *ExplorerUI) Charts(w http.ResponseWriter, r *http.Request) {
 	exp.pageData.RLock()
@@ -3721,9 +3838,11 @@ func (exp *ExplorerUI) GetDecredParamsData() *types.ChainParamData {
 	return res
 }
 
-// Search implements a primitive search algorithm by checking if the value in
-// question is a block index, block hash, address hash or transaction hash and
-// redirects to the appropriate page or displays an error.
+// Search checks whether the value in question is a block height, block hash,
+// transaction hash, address, Monero key image, proposal token, agenda ID or
+// address label on any of the enabled chains, probing the chains in parallel.
+// It redirects to the matching page, shows a disambiguation page when several
+// pages match, and suggests corrections for addresses with a typo.
 func (exp *ExplorerUI) Search(w http.ResponseWriter, r *http.Request) {
 	// The ?search= query.
 	searchStr := r.URL.Query().Get("search")
@@ -3737,194 +3856,59 @@ func (exp *ExplorerUI) Search(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Attempt to get a block hash by calling GetBlockHash of WiredDB or
-	// BlockHash of ChainDB to see if the URL query value is a block index. Then
-	// redirect to the block page if it is.
-	idx, err := strconv.ParseInt(searchStr, 10, 0)
-	if err == nil {
-		blockHashMap := make(map[string]string)
-		//Get Decred hash
-		decredHash, err := exp.dataSource.GetBlockHash(idx)
-		if err == nil {
-			blockHashMap[mutilchain.TYPEDCR] = decredHash
-		}
-		//get mutilchain hash
-		for _, mutilchain := range dbtypes.MutilchainList {
-			if exp.ChainDisabledMap[mutilchain] {
-				continue
-			}
-			mHash, mErr := exp.dataSource.GetDaemonMutilchainBlockHash(idx, mutilchain)
-			if mErr == nil {
-				blockHashMap[mutilchain] = mHash
-			}
-		}
-		if len(blockHashMap) == 1 {
-			//redirect to block page
-			_, dcrExist := blockHashMap[mutilchain.TYPEDCR]
-			if dcrExist {
-				http.Redirect(w, r, "/block/"+searchStr, http.StatusPermanentRedirect)
-				return
-			}
-			for chain := range blockHashMap {
-				http.Redirect(w, r, "/"+chain+"/block/"+searchStr, http.StatusPermanentRedirect)
-				return
-			}
-		}
-
-		if len(blockHashMap) > 1 {
-			resultDisp := "<div>"
-			for chain, hash := range blockHashMap {
-				redirectURL := ""
-				if chain == mutilchain.TYPEDCR {
-					redirectURL = "/block/" + searchStr
-				} else {
-					redirectURL = "/" + chain + "/block/" + searchStr
-				}
-				resultDisp += "<p class=\"mt-3\"><img src=\"/images/" + chain + "-icon.png\" width=\"25\" height=\"25\" /><span class=\"ms-2 fw-600\">" + strings.ToUpper(chain) + ":</span> <a href=\"" + redirectURL + "\" data-turbolinks=\"false\">" + hash + "</a> </p>"
-			}
-			resultDisp += "</div>"
-			exp.StatusPage(w, "Blocks Search Result", "Search results for blocks: "+searchStr, resultDisp, ExpStatusMutilchain)
-			return
-		}
-
-		exp.StatusPage(w, "search failed", "Block "+searchStr+
-			" has not yet been mined", searchStr, ExpStatusNotFound)
-		return
-	}
-
-	//hanlder for dcr address search
-	_, err = stdaddr.DecodeAddress(searchStr, exp.ChainParams)
-	if err == nil {
-		http.Redirect(w, r, "/address/"+searchStr, http.StatusPermanentRedirect)
-		return
-	}
-	if !exp.ChainDisabledMap[mutilchain.TYPELTC] {
-		//hanlder for ltc address search
-		_, err = ltcutil.DecodeAddress(searchStr, exp.LtcChainParams)
-		if err == nil {
-			http.Redirect(w, r, "/"+mutilchain.TYPELTC+"/address/"+searchStr, http.StatusPermanentRedirect)
-			return
-		}
-	}
-	if !exp.ChainDisabledMap[mutilchain.TYPEBTC] {
-		//hanlder for btc address search
-		_, err = btcutil.DecodeAddress(searchStr, exp.BtcChainParams)
-		if err == nil {
-			http.Redirect(w, r, "/"+mutilchain.TYPEBTC+"/address/"+searchStr, http.StatusPermanentRedirect)
-			return
-		}
-	}
-
-	//check mutilchain block hash
-	for _, mutilchain := range dbtypes.MutilchainList {
-		if exp.ChainDisabledMap[mutilchain] {
-			continue
-		}
-		hashValid := exp.dataSource.MutilchainValidBlockhash(searchStr, mutilchain)
-		if hashValid {
-			http.Redirect(w, r, "/"+mutilchain+"/block/"+searchStr, http.StatusPermanentRedirect)
-			return
-		}
-	}
-	//check mutilchain tx hash
-	for _, mutilchain := range dbtypes.MutilchainList {
-		if exp.ChainDisabledMap[mutilchain] {
-			continue
-		}
-		txHashValid := exp.dataSource.MutilchainValidTxhash(searchStr, mutilchain)
-		if txHashValid {
-			http.Redirect(w, r, "/"+mutilchain+"/tx/"+searchStr, http.StatusPermanentRedirect)
+	var results []searchResult
+	if idx, err := strconv.ParseInt(searchStr, 10, 0); err == nil {
+		results = exp.searchHeight(idx)
+		if len(results) == 0 {
+			exp.StatusPage(w, "search failed", "Block "+searchStr+
+				" has not yet been mined", searchStr, ExpStatusNotFound)
 			return
 		}
-	}
-
-	// Split searchStr to the first part corresponding to a transaction hash and
-	// to the second part corresponding to a transaction output index.
-	searchStrSplit := strings.Split(searchStr, ":")
-	searchStrRewritten := searchStrSplit[0]
-	var utxoLike bool
-	switch {
-	case len(searchStrSplit) > 2:
-		exp.StatusPage(w, "search failed", "Transaction outpoint does not have a valid format: "+searchStr,
-			"", ExpStatusNotFound)
-		return
-	case len(searchStrSplit) > 1:
-		if _, err := strconv.ParseUint(searchStrSplit[1], 10, 32); err == nil {
-			searchStrRewritten = searchStrRewritten + "/out/" + searchStrSplit[1]
-			utxoLike = true
-		} else {
-			exp.StatusPage(w, "search failed", "Transaction output index is not a valid non-negative integer: "+searchStrSplit[1],
+	} else if results = exp.searchAddress(searchStr); len(results) == 0 {
+		// Split searchStr to the first part corresponding to a transaction
+		// hash and to the second part corresponding to a transaction output
+		// index.
+		searchStrSplit := strings.Split(searchStr, ":")
+		var outIndex string
+		switch {
+		case len(searchStrSplit) > 2:
+			exp.StatusPage(w, "search failed", "Transaction outpoint does not have a valid format: "+searchStr,
 				"", ExpStatusNotFound)
 			return
+		case len(searchStrSplit) > 1:
+			if _, err := strconv.ParseUint(searchStrSplit[1], 10, 32); err != nil {
+				exp.StatusPage(w, "search failed", "Transaction output index is not a valid non-negative integer: "+searchStrSplit[1],
+					"", ExpStatusNotFound)
+				return
+			}
+			outIndex = searchStrSplit[1]
 		}
-	}
-
-	tryProp := func() bool {
-		// Try proposal token.
-		proposalInfo, err := exp.proposals.ProposalByToken(searchStr)
-		if err == nil {
-			http.Redirect(w, r, "/proposal/"+proposalInfo.Token, http.StatusPermanentRedirect)
-			return true
-		}
-		return false
-	}
-
-	// If it is not a valid hash, try proposals and give up.
-	if _, err = chainhash.NewHashFromStr(searchStrSplit[0]); err != nil {
-		if tryProp() {
-			return
-		}
-		exp.StatusPage(w, "search failed",
-			"Search string is not a valid block, tx hash, address, or proposal: "+searchStr,
-			"", ExpStatusNotFound)
-		return
-	}
-
-	// A valid hash could be block, txid, or prop. First try blocks, then tx via
-	// getrawtransaction, then props, then tx via DB query.
 
-	if !utxoLike {
-		// Attempt to get a block index by calling GetBlockHeight to see if the
-		// value is a block hash and then redirect to the block page if it is.
-		_, err = exp.dataSource.GetBlockHeight(searchStrSplit[0])
-		if err == nil {
-			http.Redirect(w, r, "/block/"+searchStrSplit[0], http.StatusPermanentRedirect)
-			return
+		if _, err = chainhash.NewHashFromStr(searchStrSplit[0]); err == nil {
+			results = exp.searchHash(searchStrSplit[0], outIndex)
+		} else if outIndex == "" {
+			results = exp.searchWord(searchStr)
 		}
 	}
 
-	// It's unlikely to be a tx id with many leading/trailing zeros.
-	trimmedZeros := 2*chainhash.HashSize - len(strings.Trim(searchStrSplit[0], "0"))
-
-	// Call GetExplorerTx to see if the value is a transaction hash and then
-	// redirect to the tx page if it is.
-	if trimmedZeros < 10 {
-		tx := exp.dataSource.GetExplorerTx(searchStrSplit[0])
-		if tx != nil {
-			http.Redirect(w, r, "/tx/"+searchStrRewritten, http.StatusPermanentRedirect)
+	switch len(results) {
+	case 0:
+		if suggestions := exp.addressSuggestions(searchStr); len(suggestions) > 0 {
+			exp.searchPage(w, r, searchStr, nil, suggestions)
 			return
 		}
-	}
-
-	// Before checking the DB for txns in orphaned blocks, try props.
-	if tryProp() {
-		return
-	}
-
-	// Also check the DB as it may have transactions from orphaned blocks.
-	if trimmedZeros < 10 {
-		dbTxs, err := exp.dataSource.Transaction(searchStrSplit[0])
-		if err != nil && !errors.Is(err, dbtypes.ErrNoResult) {
-			log.Errorf("Searching for transaction failed: %v", err)
-		}
-		if dbTxs != nil {
-			http.Redirect(w, r, "/tx/"+searchStrRewritten, http.StatusPermanentRedirect)
+		message := "The search did not find any matching address, block, transaction, key image, proposal token, agenda or address label: " + searchStr
+		exp.StatusPage(w, "search failed", message, "", ExpStatusNotFound)
+	case 1:
+		if results[0].URL == "" {
+			exp.searchPage(w, r, searchStr, results, nil)
 			return
 		}
+		http.Redirect(w, r, results[0].URL, http.StatusPermanentRedirect)
+	default:
+		log.Debugf("Search for %s matched %d pages", searchStr, len(results))
+		exp.searchPage(w, r, searchStr, results, nil)
 	}
-
-	message := "The search did not find any matching address, block, transaction or proposal token: " + searchStr
-	exp.StatusPage(w, "search failed", message, "", ExpStatusNotFound)
 }
 
 // StatusPage provides a page for displaying status messages and exception
@@ -4604,17 +4588,23 @@ func (exp *ExplorerUI) MarketPage(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	exp.pageData.RUnlock()
+	var quality *exchanges.MarketQuality
+	if xcBot != nil {
+		quality = xcBot.MarketQuality(exchanges.TYPEDCR)
+	}
 	str, err := exp.templates.exec("market", struct {
 		*CommonPageData
 		DepthMarkets    []string
 		StickMarkets    map[string]string
 		XcState         *exchanges.ExchangeBotState
 		Conversions     *homeConversions
+		Quality         *exchanges.MarketQuality
 		CoinValueSupply float64
 	}{
 		CommonPageData:  exp.commonData(r),
 		XcState:         xcState,
 		Conversions:     conversions,
+		Quality:         quality,
 		CoinValueSupply: coinValueSupply,
 	})
 
@@ -4679,10 +4669,12 @@ func (exp *ExplorerUI) MutilchainMarketPage(w http.ResponseWriter, r *http.Reque
 		volume = xcState.Volume
 		exp.pageData.RUnlock()
 	}
+	var quality *exchanges.MarketQuality
 	if xcBot != nil {
 		conversions = &MutilchainHomeConversions{
 			CoinSupply: xcBot.MutilchainConversion(coinValueSupply, chainType),
 		}
+		quality = xcBot.MarketQuality(chainType)
 	}
 
 	str, err := exp.templates.exec("chain_market", struct {
@@ -4691,6 +4683,7 @@ func (exp *ExplorerUI) MutilchainMarketPage(w http.ResponseWriter, r *http.Reque
 		StickMarkets    map[string]string
 		XcState         exchanges.ExchangeBotStateContent
 		Conversions     *MutilchainHomeConversions
+		Quality         *exchanges.MarketQuality
 		ChainType       string
 		CoinValueSupply float64
 		Volume24h       float64
@@ -4699,6 +4692,7 @@ func (exp *ExplorerUI) MutilchainMarketPage(w http.ResponseWriter, r *http.Reque
 		XcState:         xcState,
 		ChainType:       chainType,
 		Conversions:     conversions,
+		Quality:         quality,
 		CoinValueSupply: coinValueSupply,
 		Volume24h:       volume,
 	})
@@ -5260,6 +5254,568 @@ func (exp *ExplorerUI) VerifyMessageHandler(w http.ResponseWriter, r *http.Reque
 	displayPage("", true)
 }
 
+// hdWalletResult is the outcome of an extended public key lookup. The key is
+// not echoed back.
+type hdWalletResult struct {
+	Chain    string
+	GapLimit int
+	Wallet   *dbtypes.HDWallet
+	Error    string
+}
+
+// HDWalletPage is the page handler for the "GET /xpub" path.
+func (exp *ExplorerUI) HDWalletPage(w http.ResponseWriter, r *http.Request) {
+	exp.renderHDWallet(w, r, &hdWalletResult{
+		Chain:    mutilchain.TYPEDCR,
+		GapLimit: hdwallet.DefaultGapLimit,
+	})
+}
+
+// HDWalletHandler is the handler for the "POST /xpub" path. It scans the
+// addresses of the posted extended public key or output descriptor.
+func (exp *ExplorerUI) HDWalletHandler(w http.ResponseWriter, r *http.Request) {
+	result := &hdWalletResult{
+		Chain:    r.PostFormValue("chain"),
+		GapLimit: hdwallet.DefaultGapLimit,
+	}
+	if gap := r.PostFormValue("gap"); gap != "" {
+		gapLimit, err := strconv.Atoi(gap)
+		if err != nil || gapLimit < 1 || gapLimit > hdwallet.MaxGapLimit {
+			result.Error = fmt.Sprintf("The gap limit must be between 1 and %d", hdwallet.MaxGapLimit)
+			exp.renderHDWallet(w, r, result)
+			return
+		}
+		result.GapLimit = gapLimit
+	}
+	wallet, err := exp.dataSource.HDWallet(result.Chain, r.PostFormValue("key"), result.GapLimit)
+	switch {
+	case errors.Is(err, hdwallet.ErrInvalidKey), errors.Is(err, hdwallet.ErrScanLimit):
+		result.Error = err.Error()
+	case err != nil:
+		log.Errorf("HDWallet: %v", err)
+		result.Error = "The wallet could not be retrieved"
+	default:
+		result.Wallet = wallet
+	}
+	exp.renderHDWallet(w, r, result)
+}
+
+func (exp *ExplorerUI) renderHDWallet(w http.ResponseWriter, r *http.Request, result *hdWalletResult) {
+	str, err := exp.templates.exec("xpub", struct {
+		*CommonPageData
+		HDWalletResult *hdWalletResult
+		MaxGapLimit    int
+	}{
+		CommonPageData: exp.commonData(r),
+		HDWalletResult: result,
+		MaxGapLimit:    hdwallet.MaxGapLimit,
+	})
+	if err != nil {
+		log.Errorf("Template execute failure: %v", err)
+		exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	// The results derive from the posted key.
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, str)
+}
+
+// coSpentLimit is the number of co-spent addresses shown on an address page.
+const coSpentLimit = 20
+
+// clusterPageSize is the number of addresses of a cluster page.
+const clusterPageSize = 100
+
+// addressClusterData is the cluster of an address page, with the addresses
+// most often spent together with it.
+type addressClusterData struct {
+	ChainPath string
+	Address   string
+	ID        int64
+	Size      int64
+	CoSpent   []*dbtypes.CoSpentAddress
+}
+
+// clusterChainPath returns the explorer path prefix of a clustered chain, or
+// an empty string for a chain that is not clustered.
+func clusterChainPath(chainType string) string {
+	switch chainType {
+	case mutilchain.TYPEDCR:
+		return "decred"
+	case mutilchain.TYPEBTC, mutilchain.TYPELTC:
+		return chainType
+	}
+	return ""
+}
+
+// addressCluster retrieves the cluster of an address and its co-spent
+// addresses for the address page. It is nil for an address that is not
+// clustered. Errors are logged, as the page is complete without it.
+func (exp *ExplorerUI) addressCluster(chainType, address string) *addressClusterData {
+	chainPath := clusterChainPath(chainType)
+	if chainPath == "" {
+		return nil
+	}
+	id, size, err := exp.dataSource.AddressClusterSize(chainType, address)
+	if err != nil {
+		log.Errorf("AddressClusterSize(%s, %s): %v", chainType, address, err)
+		return nil
+	}
+	if id == 0 {
+		return nil
+	}
+	coSpent, err := exp.dataSource.CoSpentAddresses(chainType, address, coSpentLimit)
+	if err != nil {
+		log.Errorf("CoSpentAddresses(%s, %s): %v", chainType, address, err)
+	}
+	return &addressClusterData{
+		ChainPath: chainPath,
+		Address:   address,
+		ID:        id,
+		Size:      size,
+		CoSpent:   coSpent,
+	}
+}
+
+// txLabels retrieves the label names of the labeled input and output addresses
+// of a transaction for the tx page.
+func (exp *ExplorerUI) txLabels(chainType string, tx *types.TxInfo) map[string]string {
+	var addrs []string
+	for i := range tx.Vin {
+		addrs = append(addrs, tx.Vin[i].Addresses...)
+	}
+	for i := range tx.MutilchainVin {
+		addrs = append(addrs, tx.MutilchainVin[i].Addresses...)
+	}
+	for i := range tx.Vout {
+		addrs = append(addrs, tx.Vout[i].Addresses...)
+	}
+	if len(addrs) == 0 {
+		return nil
+	}
+	return exp.dataSource.AddressLabels(chainType, addrs)
+}
+
+// ClusterPage is the page handler for the "/decred/cluster/{clusterid}" and
+// "/{chaintype}/cluster/{clusterid}" paths.
+func (exp *ExplorerUI) ClusterPage(w http.ResponseWriter, r *http.Request) {
+	if exp.IsCrawlerUserAgentAdvance(r.UserAgent(), externalapi.GetIP(r)) {
+		return
+	}
+	chainType := chi.URLParam(r, "chaintype")
+	if chainType == "" {
+		chainType = mutilchain.TYPEDCR
+	}
+	chainPath := clusterChainPath(chainType)
+	id, err := strconv.ParseInt(chi.URLParam(r, "clusterid"), 10, 64)
+	if chainPath == "" || err != nil || id < 1 {
+		exp.StatusPage(w, defaultErrorCode, "Invalid cluster.", "", ExpStatusNotFound)
+		return
+	}
+	offset, err := strconv.Atoi(r.URL.Query().Get("start"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	cluster, err := exp.dataSource.Cluster(chainType, id, clusterPageSize, offset)
+	if exp.timeoutErrorPage(w, err, "Cluster") {
+		return
+	}
+	if errors.Is(err, dbtypes.ErrNoResult) {
+		exp.StatusPage(w, defaultErrorCode, "The cluster could not be found.", "", ExpStatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Errorf("Cluster(%s, %d): %v", chainType, id, err)
+		exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
+		return
+	}
+
+	linkTemplate := fmt.Sprintf("/%s/cluster/%d?start=%%d", chainPath, id)
+	str, err := exp.templates.exec("cluster", struct {
+		*CommonPageData
+		Cluster   *dbtypes.AddressCluster
+		ChainType string
+		ChainPath string
+		Unit      string
+		Pages     []pageNumber
+	}{
+		CommonPageData: exp.commonData(r),
+		Cluster:        cluster,
+		ChainType:      chainType,
+		ChainPath:      chainPath,
+		Unit:           strings.ToUpper(chainType),
+		Pages:          calcPages(int(cluster.Size), clusterPageSize, offset, linkTemplate),
+	})
+	if err != nil {
+		log.Errorf("Template execute failure: %v", err)
+		exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Turbolinks-Location", r.URL.RequestURI())
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, str)
+}
+
+// mixingPeriod is the Decred mixing statistics of a period for the mixing
+// page, with their averages and shares.
+type mixingPeriod struct {
+	Label string
+	*dbtypes.MixingSummary
+	AvgParticipants  float64
+	AvgSpendDays     float64
+	RemixedPercent   float64
+	MixedTicketShare float64
+}
+
+func newMixingPeriod(label string, summary *dbtypes.MixingSummary) *mixingPeriod {
+	period := &mixingPeriod{
+		Label:         label,
+		MixingSummary: summary,
+	}
+	if summary.Mixes > 0 {
+		period.AvgParticipants = float64(summary.Participants) / float64(summary.Mixes)
+	}
+	if summary.Spent > 0 {
+		period.AvgSpendDays = float64(summary.SpendSecs) / float64(summary.Spent) / 86400
+	}
+	if summary.Mixed > 0 {
+		period.RemixedPercent = 100 * float64(summary.Remixed) / float64(summary.Mixed)
+	}
+	if summary.Tickets > 0 {
+		period.MixedTicketShare = 100 * float64(summary.MixedTickets) / float64(summary.Tickets)
+	}
+	return period
+}
+
+// MixingPage is the page handler for the "/decred/mixing" path.
+func (exp *ExplorerUI) MixingPage(w http.ResponseWriter, r *http.Request) {
+	if exp.IsCrawlerUserAgentAdvance(r.UserAgent(), externalapi.GetIP(r)) {
+		return
+	}
+	height := exp.Height()
+	blocksPerDay := int64(24 * time.Hour / exp.ChainParams.TargetTimePerBlock)
+	periods := []struct {
+		label  string
+		height int64
+	}{
+		{"Last 24 hours", height - blocksPerDay},
+		{"Last 30 days", height - 30*blocksPerDay},
+		{"All time", -1},
+	}
+	mixingPeriods := make([]*mixingPeriod, 0, len(periods))
+	for _, p := range periods {
+		summary, err := exp.dataSource.MixingSummary(p.height)
+		if exp.timeoutErrorPage(w, err, "MixingSummary") {
+			return
+		}
+		if err != nil {
+			log.Errorf("MixingSummary(%d): %v", p.height, err)
+			exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
+			return
+		}
+		mixingPeriods = append(mixingPeriods, newMixingPeriod(p.label, summary))
+	}
+
+	str, err := exp.templates.exec("mixing", struct {
+		*CommonPageData
+		Periods []*mixingPeriod
+		AllTime *mixingPeriod
+	}{
+		CommonPageData: exp.commonData(r),
+		Periods:        mixingPeriods,
+		AllTime:        mixingPeriods[len(mixingPeriods)-1],
+	})
+	if err != nil {
+		log.Errorf("Template execute failure: %v", err)
+		exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Turbolinks-Location", r.URL.RequestURI())
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, str)
+}
+
+// richListPageSize is the number of addresses of a rich list page, and
+// richListMaxRank the deepest rank the rich list pages reach.
+const (
+	richListPageSize = 100
+	richListMaxRank  = 10000
+)
+
+// wealthBucketRow is a balance bucket of the last wealth snapshot for the rich
+// list page, with its shares of the addresses and of the total balance.
+type wealthBucketRow struct {
+	*dbtypes.WealthBucket
+	Max          int64 // 0 for the last bucket
+	AddressShare float64
+	ValueShare   float64
+}
+
+// RichListPage is the page handler for the "/decred/richlist" and
+// "/{chaintype}/richlist" paths.
+func (exp *ExplorerUI) RichListPage(w http.ResponseWriter, r *http.Request) {
+	if exp.IsCrawlerUserAgentAdvance(r.UserAgent(), externalapi.GetIP(r)) {
+		return
+	}
+	chainType := chi.URLParam(r, "chaintype")
+	if chainType == "" {
+		chainType = mutilchain.TYPEDCR
+	}
+	chainPath := clusterChainPath(chainType)
+	if chainPath == "" {
+		exp.StatusPage(w, defaultErrorCode, "There is no rich list for this chain.", "", ExpStatusNotFound)
+		return
+	}
+	offset, err := strconv.Atoi(r.URL.Query().Get("start"))
+	if err != nil || offset < 0 || offset >= richListMaxRank {
+		offset = 0
+	}
+
+	richList, err := exp.dataSource.RichList(chainType, richListPageSize, offset)
+	if exp.timeoutErrorPage(w, err, "RichList") {
+		return
+	}
+	if err != nil {
+		log.Errorf("RichList(%s): %v", chainType, err)
+		exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
+		return
+	}
+
+	var buckets []*wealthBucketRow
+	if snapshot := richList.Snapshot; snapshot != nil {
+		buckets = make([]*wealthBucketRow, 0, len(snapshot.Buckets))
+		for i, bucket := range snapshot.Buckets {
+			row := &wealthBucketRow{WealthBucket: bucket}
+			if i+1 < len(snapshot.Buckets) {
+				row.Max = snapshot.Buckets[i+1].Min
+			}
+			if snapshot.Addresses > 0 {
+				row.AddressShare = 100 * float64(bucket.Addresses) / float64(snapshot.Addresses)
+			}
+			if snapshot.Total > 0 {
+				row.ValueShare = 100 * float64(bucket.Value) / float64(snapshot.Total)
+			}
+			buckets = append(buckets, row)
+		}
+	}
+
+	ranked := int(richList.Addresses)
+	if ranked > richListMaxRank {
+		ranked = richListMaxRank
+	}
+	linkTemplate := fmt.Sprintf("/%s/richlist?start=%%d", chainPath)
+	str, err := exp.templates.exec("richlist", struct {
+		*CommonPageData
+		RichList  *dbtypes.RichList
+		Buckets   []*wealthBucketRow
+		ChainType string
+		ChainPath string
+		Unit      string
+		Pages     []pageNumber
+	}{
+		CommonPageData: exp.commonData(r),
+		RichList:       richList,
+		Buckets:        buckets,
+		ChainType:      chainType,
+		ChainPath:      chainPath,
+		Unit:           strings.ToUpper(chainType),
+		Pages:          calcPages(ranked, richListPageSize, offset, linkTemplate),
+	})
+	if err != nil {
+		log.Errorf("Template execute failure: %v", err)
+		exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Turbolinks-Location", r.URL.RequestURI())
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, str)
+}
+
+// Sizes of the transaction graph drawing, in pixels.
+const (
+	txGraphColumn = 240
+	txGraphRow    = 64
+	txGraphBoxW   = 170
+	txGraphBoxH   = 40
+	txGraphMargin = 20
+)
+
+// txGraphBox is a transaction of a drawn transaction graph.
+type txGraphBox struct {
+	*dbtypes.TxGraphNode
+	X, Y int
+}
+
+// txGraphLine is the flow between two transactions of a drawn transaction
+// graph, with the total value of the outputs linking them, labeled at its
+// middle.
+type txGraphLine struct {
+	X1, Y1, X2, Y2 int
+	LabelX, LabelY int
+	Value          int64
+}
+
+// txGraphLayout is the drawing of a transaction graph, with the transactions
+// in a column by hop, and the flow of funds from left to right.
+type txGraphLayout struct {
+	Width, Height int
+	Boxes         []*txGraphBox
+	Lines         []*txGraphLine
+}
+
+func layoutTxGraph(graph *dbtypes.TxGraph) *txGraphLayout {
+	var maxHop int
+	for _, node := range graph.Nodes {
+		if node.Hop > maxHop {
+			maxHop = node.Hop
+		}
+	}
+	layout := new(txGraphLayout)
+	boxes := make(map[string]*txGraphBox, len(graph.Nodes))
+	rows := make([]int, maxHop+1)
+	for _, node := range graph.Nodes {
+		col := node.Hop
+		if graph.Direction == txtrace.Backward {
+			col = maxHop - node.Hop
+		}
+		box := &txGraphBox{
+			TxGraphNode: node,
+			X:           txGraphMargin + col*txGraphColumn,
+			Y:           txGraphMargin + rows[node.Hop]*txGraphRow,
+		}
+		rows[node.Hop]++
+		boxes[node.TxHash] = box
+		layout.Boxes = append(layout.Boxes, box)
+	}
+	var maxRows int
+	for _, n := range rows {
+		if n > maxRows {
+			maxRows = n
+		}
+	}
+	layout.Width = 2*txGraphMargin + maxHop*txGraphColumn + txGraphBoxW
+	layout.Height = 2*txGraphMargin + (maxRows-1)*txGraphRow + txGraphBoxH
+
+	// Unspent outputs are only listed.
+	lines := make(map[[2]string]*txGraphLine)
+	for _, edge := range graph.Edges {
+		from, to := boxes[edge.From], boxes[edge.To]
+		if from == nil || to == nil {
+			continue
+		}
+		key := [2]string{edge.From, edge.To}
+		if line := lines[key]; line != nil {
+			line.Value += edge.Value
+			continue
+		}
+		line := &txGraphLine{
+			X1:    from.X + txGraphBoxW,
+			Y1:    from.Y + txGraphBoxH/2,
+			X2:    to.X,
+			Y2:    to.Y + txGraphBoxH/2,
+			Value: edge.Value,
+		}
+		line.LabelX = (line.X1 + line.X2) / 2
+		line.LabelY = (line.Y1+line.Y2)/2 - 4
+		lines[key] = line
+		layout.Lines = append(layout.Lines, line)
+	}
+	return layout
+}
+
+// TxGraphPage traces the flow of funds from a transaction output of a chain,
+// with the query parameters of txtrace.ParseRequest, and draws the graph.
+// Without a txid, only the trace form is shown.
+func (exp *ExplorerUI) TxGraphPage(w http.ResponseWriter, r *http.Request) {
+	if exp.IsCrawlerUserAgentAdvance(r.UserAgent(), externalapi.GetIP(r)) {
+		return
+	}
+	chainType := chi.URLParam(r, "chaintype")
+	if chainType == "" {
+		chainType = mutilchain.TYPEDCR
+	}
+	if !txtrace.Chain(chainType) {
+		exp.StatusPage(w, defaultErrorCode, "Transactions of this chain cannot be traced.", "", ExpStatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	req := &txtrace.Request{Chain: chainType, Direction: txtrace.Forward, Hops: txtrace.DefaultHops}
+	var graph *dbtypes.TxGraph
+	var layout *txGraphLayout
+	var traceErr string
+	if query.Get("txid") != "" {
+		var err error
+		req, err = txtrace.ParseRequest(chainType, query)
+		if err == nil {
+			graph, err = txtrace.Trace(exp.dataSource, req)
+			if exp.timeoutErrorPage(w, err, "Trace") {
+				return
+			}
+		}
+		switch {
+		case errors.Is(err, txtrace.ErrInvalidTrace), errors.Is(err, txtrace.ErrNotFound):
+			traceErr = err.Error()
+			if req == nil {
+				req = &txtrace.Request{Chain: chainType, TxHash: query.Get("txid"),
+					Direction: txtrace.Forward, Hops: txtrace.DefaultHops}
+			}
+		case err != nil:
+			log.Errorf("Trace(%s, %s:%d): %v", chainType, req.TxHash, req.Vout, err)
+			exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
+			return
+		default:
+			layout = layoutTxGraph(graph)
+		}
+	}
+
+	str, err := exp.templates.exec("txgraph", struct {
+		*CommonPageData
+		Request   *txtrace.Request
+		Graph     *dbtypes.TxGraph
+		Layout    *txGraphLayout
+		Error     string
+		ChainType string
+		ChainPath string
+		Unit      string
+		MaxHops   int
+		BoxWidth  int
+		BoxHeight int
+	}{
+		CommonPageData: exp.commonData(r),
+		Request:        req,
+		Graph:          graph,
+		Layout:         layout,
+		Error:          traceErr,
+		ChainType:      chainType,
+		ChainPath:      clusterChainPath(chainType),
+		Unit:           strings.ToUpper(chainType),
+		MaxHops:        txtrace.MaxHops,
+		BoxWidth:       txGraphBoxW,
+		BoxHeight:      txGraphBoxH,
+	})
+	if err != nil {
+		log.Errorf("Template execute failure: %v", err)
+		exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Turbolinks-Location", r.URL.RequestURI())
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, str)
+}
+
 func (exp *ExplorerUI) IsCrawlerUserAgent(userAgent, ip string) bool {
 	if strings.Contains(userAgent, "facebookexternalhit") {
 		return true
@@ -0,0 +1,471 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package explorer
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/txscript/v4/stdaddr"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/mutilchain"
+	"github.com/decred/dcrdata/v8/xmr/xmrutil"
+	"github.com/ltcsuite/ltcd/ltcutil"
+)
+
+// Kinds of search results.
+const (
+	searchKindBlock    = "Block"
+	searchKindTx       = "Transaction"
+	searchKindAddress  = "Address"
+	searchKindKeyImage = "Key Image"
+	searchKindProposal = "Proposal"
+	searchKindAgenda   = "Agenda"
+	searchKindLabel    = "Label"
+)
+
+const (
+	// maxSearchSuggestions is the maximum number of corrections suggested for
+	// an address that fails its checksum.
+	maxSearchSuggestions = 5
+
+	// Addresses shorter or longer than these are not checked for typos.
+	minSuggestAddressLength = 25
+	maxSuggestAddressLength = 110
+
+	// maxSearchLabels is the maximum number of address labels matching a
+	// search term.
+	maxSearchLabels = 20
+
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	bech32Charset  = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+// searchKindOrder is the display order of the kinds of search results.
+var searchKindOrder = map[string]int{
+	searchKindBlock:    0,
+	searchKindTx:       1,
+	searchKindAddress:  2,
+	searchKindKeyImage: 3,
+	searchKindProposal: 4,
+	searchKindAgenda:   5,
+	searchKindLabel:    6,
+}
+
+// searchResult is a page matching a search term. Results without a URL have
+// no page, such as Monero addresses.
+type searchResult struct {
+	ChainType string
+	Kind      string
+	Value     string
+	URL       string
+	Note      string
+}
+
+// searchChainOrder returns the sort order of a chain in search results.
+func searchChainOrder(chainType string) int {
+	if chainType == mutilchain.TYPEDCR {
+		return 0
+	}
+	for i, chain := range dbtypes.MutilchainList {
+		if chain == chainType {
+			return i + 1
+		}
+	}
+	return len(dbtypes.MutilchainList) + 1
+}
+
+func sortSearchResults(results []searchResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		ci, cj := searchChainOrder(results[i].ChainType), searchChainOrder(results[j].ChainType)
+		if ci != cj {
+			return ci < cj
+		}
+		return searchKindOrder[results[i].Kind] < searchKindOrder[results[j].Kind]
+	})
+}
+
+// searchChains returns the chains to search, Decred first.
+func (exp *ExplorerUI) searchChains() []string {
+	chains := []string{mutilchain.TYPEDCR}
+	for _, chain := range dbtypes.MutilchainList {
+		if !exp.ChainDisabledMap[chain] {
+			chains = append(chains, chain)
+		}
+	}
+	return chains
+}
+
+// searchProbes runs the probes in parallel and collects their results.
+func searchProbes(probes []func() []searchResult) []searchResult {
+	var mtx sync.Mutex
+	var wg sync.WaitGroup
+	var results []searchResult
+	for _, probe := range probes {
+		wg.Add(1)
+		go func(probe func() []searchResult) {
+			defer wg.Done()
+			res := probe()
+			if len(res) == 0 {
+				return
+			}
+			mtx.Lock()
+			results = append(results, res...)
+			mtx.Unlock()
+		}(probe)
+	}
+	wg.Wait()
+	sortSearchResults(results)
+	return results
+}
+
+// searchHeight finds the block at the height on each chain.
+func (exp *ExplorerUI) searchHeight(height int64) []searchResult {
+	heightStr := strconv.FormatInt(height, 10)
+	var probes []func() []searchResult
+	for _, chain := range exp.searchChains() {
+		chain := chain
+		probes = append(probes, func() []searchResult {
+			var hash string
+			var err error
+			if chain == mutilchain.TYPEDCR {
+				hash, err = exp.dataSource.GetBlockHash(height)
+			} else {
+				hash, err = exp.dataSource.GetDaemonMutilchainBlockHash(height, chain)
+			}
+			if err != nil || hash == "" {
+				return nil
+			}
+			return []searchResult{{
+				ChainType: chain,
+				Kind:      searchKindBlock,
+				Value:     hash,
+				URL:       searchBlockURL(chain, heightStr),
+				Note:      "Height " + heightStr,
+			}}
+		})
+	}
+	return searchProbes(probes)
+}
+
+func searchBlockURL(chainType, block string) string {
+	if chainType == mutilchain.TYPEDCR {
+		return "/decred/block/" + block
+	}
+	return "/" + chainType + "/block/" + block
+}
+
+func searchTxURL(chainType, txid string) string {
+	if chainType == mutilchain.TYPEDCR {
+		return "/decred/tx/" + txid
+	}
+	return "/" + chainType + "/tx/" + txid
+}
+
+func searchAddressURL(chainType, addr string) string {
+	if chainType == mutilchain.TYPEDCR {
+		return "/decred/address/" + addr
+	}
+	return "/" + chainType + "/address/" + addr
+}
+
+// searchHash finds the blocks, transactions and Monero key images with the
+// hash on each chain, and the proposal with the token. outIndex is the output
+// index of an outpoint search, or empty.
+func (exp *ExplorerUI) searchHash(hash, outIndex string) []searchResult {
+	// It's unlikely to be a tx id with many leading/trailing zeros.
+	trimmedZeros := 2*chainhash.HashSize - len(strings.Trim(hash, "0"))
+	likelyTx := trimmedZeros < 10
+
+	probes := []func() []searchResult{
+		func() []searchResult {
+			if outIndex != "" {
+				return nil
+			}
+			if _, err := exp.dataSource.GetBlockHeight(hash); err != nil {
+				return nil
+			}
+			return []searchResult{{mutilchain.TYPEDCR, searchKindBlock, hash, searchBlockURL(mutilchain.TYPEDCR, hash), ""}}
+		},
+		func() []searchResult {
+			if !likelyTx {
+				return nil
+			}
+			found := exp.dataSource.GetExplorerTx(hash) != nil
+			if !found {
+				// Also check the DB as it may have transactions from orphaned
+				// blocks.
+				dbTxs, err := exp.dataSource.Transaction(hash)
+				if err != nil && !errors.Is(err, dbtypes.ErrNoResult) {
+					log.Errorf("Searching for transaction failed: %v", err)
+				}
+				found = len(dbTxs) > 0
+			}
+			if !found {
+				return nil
+			}
+			url := searchTxURL(mutilchain.TYPEDCR, hash)
+			if outIndex != "" {
+				url += "/out/" + outIndex
+			}
+			return []searchResult{{mutilchain.TYPEDCR, searchKindTx, hash, url, ""}}
+		},
+		func() []searchResult {
+			if outIndex != "" || exp.proposals == nil {
+				return nil
+			}
+			return exp.searchProposal(hash)
+		},
+	}
+	for _, chain := range exp.searchChains() {
+		if chain == mutilchain.TYPEDCR {
+			continue
+		}
+		chain := chain
+		probes = append(probes, func() []searchResult {
+			if outIndex != "" || !exp.dataSource.MutilchainValidBlockhash(hash, chain) {
+				return nil
+			}
+			return []searchResult{{chain, searchKindBlock, hash, searchBlockURL(chain, hash), ""}}
+		}, func() []searchResult {
+			if !likelyTx || !exp.dataSource.MutilchainValidTxhash(hash, chain) {
+				return nil
+			}
+			return []searchResult{{chain, searchKindTx, hash, searchTxURL(chain, hash), ""}}
+		})
+		if chain == mutilchain.TYPEXMR && outIndex == "" {
+			probes = append(probes, func() []searchResult {
+				txid, height, err := exp.dataSource.XMRKeyImageTx(hash)
+				if err != nil {
+					if !errors.Is(err, dbtypes.ErrNoResult) {
+						log.Errorf("Searching for key image failed: %v", err)
+					}
+					return nil
+				}
+				return []searchResult{{chain, searchKindKeyImage, hash, searchTxURL(chain, txid),
+					"Spent in transaction " + txid + " at height " + strconv.FormatInt(height, 10)}}
+			})
+		}
+	}
+	return searchProbes(probes)
+}
+
+func (exp *ExplorerUI) searchProposal(token string) []searchResult {
+	proposal, err := exp.proposals.ProposalByToken(token)
+	if err != nil {
+		return nil
+	}
+	return []searchResult{{mutilchain.TYPEDCR, searchKindProposal, proposal.Token,
+		"/decred/proposal/" + proposal.Token, proposal.Name}}
+}
+
+// searchLabels finds the labeled addresses of the enabled chains whose label
+// contains the term.
+func (exp *ExplorerUI) searchLabels(term string) []searchResult {
+	enabled := make(map[string]bool)
+	for _, chain := range exp.searchChains() {
+		enabled[chain] = true
+	}
+	var results []searchResult
+	for _, match := range exp.dataSource.SearchLabels(term, maxSearchLabels) {
+		if !enabled[match.Chain] {
+			continue
+		}
+		results = append(results, searchResult{match.Chain, searchKindLabel, match.Address,
+			searchAddressURL(match.Chain, match.Address), match.Name})
+	}
+	return results
+}
+
+// searchWord finds the proposal token, the agenda ID or the address labels.
+func (exp *ExplorerUI) searchWord(word string) []searchResult {
+	probes := []func() []searchResult{
+		func() []searchResult {
+			if exp.proposals == nil {
+				return nil
+			}
+			return exp.searchProposal(word)
+		},
+		func() []searchResult {
+			if exp.agendasSource == nil {
+				return nil
+			}
+			agenda, err := exp.agendasSource.AgendaInfo(word)
+			if err != nil {
+				return nil
+			}
+			return []searchResult{{mutilchain.TYPEDCR, searchKindAgenda, agenda.ID,
+				"/decred/agenda/" + agenda.ID, agenda.Description}}
+		},
+		func() []searchResult {
+			if exp.dataSource == nil {
+				return nil
+			}
+			return exp.searchLabels(word)
+		},
+	}
+	return searchProbes(probes)
+}
+
+// decodeAddress returns the search result of the address if it is valid for
+// the chain. The error of an invalid address is returned.
+func (exp *ExplorerUI) decodeAddress(addr, chainType string) (*searchResult, error) {
+	res := &searchResult{
+		ChainType: chainType,
+		Kind:      searchKindAddress,
+		Value:     addr,
+		URL:       searchAddressURL(chainType, addr),
+	}
+	switch chainType {
+	case mutilchain.TYPEDCR:
+		if _, err := stdaddr.DecodeAddress(addr, exp.ChainParams); err != nil {
+			return nil, err
+		}
+	case mutilchain.TYPEBTC:
+		a, err := btcutil.DecodeAddress(addr, exp.BtcChainParams)
+		if err != nil {
+			return nil, err
+		}
+		if !a.IsForNet(exp.BtcChainParams) {
+			return nil, errors.New("wrong network")
+		}
+	case mutilchain.TYPELTC:
+		a, err := ltcutil.DecodeAddress(addr, exp.LtcChainParams)
+		if err != nil {
+			return nil, err
+		}
+		if !a.IsForNet(exp.LtcChainParams) {
+			return nil, errors.New("wrong network")
+		}
+	case mutilchain.TYPEXMR:
+		a, err := xmrutil.DecodeAddress(addr)
+		if err != nil {
+			return nil, err
+		}
+		if a.Network != xmrutil.AddressNetwork(exp.XmrNet) {
+			return nil, errors.New("wrong network")
+		}
+		// Monero addresses do not appear on the blockchain.
+		res.URL = ""
+		res.Note = "Valid " + a.Network + " " + a.Type + " address. Monero addresses and " +
+			"their transactions are not visible on the blockchain."
+	default:
+		return nil, errors.New("unknown chain")
+	}
+	return res, nil
+}
+
+// searchAddress detects the chain of an address by its encoding.
+func (exp *ExplorerUI) searchAddress(addr string) []searchResult {
+	var results []searchResult
+	for _, chain := range exp.searchChains() {
+		if res, err := exp.decodeAddress(addr, chain); err == nil {
+			results = append(results, *res)
+		}
+	}
+	return results
+}
+
+// looksLikeAddress reports whether the term may be an address with a typo.
+func looksLikeAddress(term string) bool {
+	if len(term) < minSuggestAddressLength || len(term) > maxSuggestAddressLength {
+		return false
+	}
+	for _, c := range term {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// addressTypoCandidates returns the strings that differ from the term by one
+// character or by swapping two adjacent characters. Bech32 addresses are
+// lowercased and only their data part is changed.
+func addressTypoCandidates(term string) []string {
+	alphabet, start := base58Alphabet, 0
+	if sep := strings.LastIndexByte(strings.ToLower(term), '1'); sep > 0 {
+		hrp := strings.ToLower(term[:sep])
+		switch hrp {
+		case "bc", "tb", "bcrt", "ltc", "tltc", "rltc":
+			term = strings.ToLower(term)
+			alphabet, start = bech32Charset, sep+1
+		}
+	}
+	b := []byte(term)
+	var candidates []string
+	for i := start; i < len(b); i++ {
+		orig := b[i]
+		for j := 0; j < len(alphabet); j++ {
+			if alphabet[j] == orig {
+				continue
+			}
+			b[i] = alphabet[j]
+			candidates = append(candidates, string(b))
+		}
+		b[i] = orig
+	}
+	for i := start; i < len(b)-1; i++ {
+		if b[i] == b[i+1] {
+			continue
+		}
+		b[i], b[i+1] = b[i+1], b[i]
+		candidates = append(candidates, string(b))
+		b[i], b[i+1] = b[i+1], b[i]
+	}
+	return candidates
+}
+
+// addressSuggestions returns the valid addresses of the enabled chains that
+// are a single typo away from the term.
+func (exp *ExplorerUI) addressSuggestions(term string) []searchResult {
+	if !looksLikeAddress(term) {
+		return nil
+	}
+	var suggestions []searchResult
+	seen := make(map[string]bool)
+	for _, candidate := range addressTypoCandidates(term) {
+		if seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+		suggestions = append(suggestions, exp.searchAddress(candidate)...)
+		if len(suggestions) >= maxSearchSuggestions {
+			break
+		}
+	}
+	sortSearchResults(suggestions)
+	return suggestions
+}
+
+// searchPage shows the results of a search matching several pages, or the
+// suggested corrections of a search without results.
+func (exp *ExplorerUI) searchPage(w http.ResponseWriter, r *http.Request, term string, results, suggestions []searchResult) {
+	str, err := exp.templates.exec("search", struct {
+		*CommonPageData
+		SearchTerm  string
+		Results     []searchResult
+		Suggestions []searchResult
+	}{
+		CommonPageData: exp.commonData(r),
+		SearchTerm:     term,
+		Results:        results,
+		Suggestions:    suggestions,
+	})
+	if err != nil {
+		log.Errorf("Template execute failure: %v", err)
+		exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html")
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, str)
+}
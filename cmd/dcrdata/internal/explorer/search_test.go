@@ -0,0 +1,338 @@
+package explorer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	btcchaincfg "github.com/btcsuite/btcd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/txscript/v4/stdaddr"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/explorer/types"
+	"github.com/decred/dcrdata/v8/mutilchain"
+	"github.com/decred/dcrdata/v8/xmr/xmrutil"
+	ltcchaincfg "github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/ltcutil"
+)
+
+// searchSource is an explorerDataSource with the blocks and transactions of
+// each chain. Only the methods used by the search are implemented.
+type searchSource struct {
+	explorerDataSource
+	blocks map[string]map[string]int64 // chain -> hash -> height
+	txs    map[string]map[string]bool  // chain -> txid
+}
+
+func (s *searchSource) GetBlockHeight(hash string) (int64, error) {
+	if height, ok := s.blocks[mutilchain.TYPEDCR][hash]; ok {
+		return height, nil
+	}
+	return 0, dbtypes.ErrNoResult
+}
+
+func (s *searchSource) blockHash(chain string, height int64) (string, error) {
+	for hash, h := range s.blocks[chain] {
+		if h == height {
+			return hash, nil
+		}
+	}
+	return "", dbtypes.ErrNoResult
+}
+
+func (s *searchSource) GetBlockHash(height int64) (string, error) {
+	return s.blockHash(mutilchain.TYPEDCR, height)
+}
+
+func (s *searchSource) GetDaemonMutilchainBlockHash(height int64, chain string) (string, error) {
+	return s.blockHash(chain, height)
+}
+
+func (s *searchSource) GetExplorerTx(txid string) *types.TxInfo {
+	if !s.txs[mutilchain.TYPEDCR][txid] {
+		return nil
+	}
+	return new(types.TxInfo)
+}
+
+func (s *searchSource) Transaction(string) ([]*dbtypes.Tx, error) {
+	return nil, dbtypes.ErrNoResult
+}
+
+func (s *searchSource) MutilchainValidBlockhash(hash, chain string) bool {
+	_, ok := s.blocks[chain][hash]
+	return ok
+}
+
+func (s *searchSource) MutilchainValidTxhash(hash, chain string) bool {
+	return s.txs[chain][hash]
+}
+
+func (s *searchSource) XMRKeyImageTx(string) (string, int64, error) {
+	return "", 0, dbtypes.ErrNoResult
+}
+
+func TestSearchHash(t *testing.T) {
+	shared := "3f" + strings.Repeat("a1", 31)
+	dcrOnly := "4e" + strings.Repeat("b2", 31)
+	zeros := strings.Repeat("0", 16) + strings.Repeat("c3", 24)
+	src := &searchSource{
+		blocks: map[string]map[string]int64{
+			mutilchain.TYPEDCR: {zeros: 100},
+			mutilchain.TYPEBTC: {shared: 100},
+			mutilchain.TYPELTC: {zeros: 200},
+		},
+		txs: map[string]map[string]bool{
+			mutilchain.TYPEDCR: {shared: true, dcrOnly: true},
+			mutilchain.TYPELTC: {shared: true},
+			mutilchain.TYPEXMR: {shared: true, zeros: true},
+		},
+	}
+	exp := &ExplorerUI{
+		dataSource:       src,
+		ChainDisabledMap: map[string]bool{},
+	}
+
+	type result struct{ chain, kind, url string }
+	tests := []struct {
+		name     string
+		hash     string
+		outIndex string
+		disabled string
+		want     []result
+	}{
+		{"several chains", shared, "", "", []result{
+			{mutilchain.TYPEDCR, searchKindTx, "/decred/tx/" + shared},
+			{mutilchain.TYPEBTC, searchKindBlock, "/btc/block/" + shared},
+			{mutilchain.TYPELTC, searchKindTx, "/ltc/tx/" + shared},
+			{mutilchain.TYPEXMR, searchKindTx, "/xmr/tx/" + shared},
+		}},
+		{"one chain", dcrOnly, "", "", []result{
+			{mutilchain.TYPEDCR, searchKindTx, "/decred/tx/" + dcrOnly},
+		}},
+		{"block hash", zeros, "", "", []result{
+			{mutilchain.TYPEDCR, searchKindBlock, "/decred/block/" + zeros},
+			{mutilchain.TYPELTC, searchKindBlock, "/ltc/block/" + zeros},
+		}},
+		{"outpoint", shared, "1", "", []result{
+			{mutilchain.TYPEDCR, searchKindTx, "/decred/tx/" + shared + "/out/1"},
+			{mutilchain.TYPELTC, searchKindTx, "/ltc/tx/" + shared},
+			{mutilchain.TYPEXMR, searchKindTx, "/xmr/tx/" + shared},
+		}},
+		{"disabled chain", shared, "", mutilchain.TYPELTC, []result{
+			{mutilchain.TYPEDCR, searchKindTx, "/decred/tx/" + shared},
+			{mutilchain.TYPEBTC, searchKindBlock, "/btc/block/" + shared},
+			{mutilchain.TYPEXMR, searchKindTx, "/xmr/tx/" + shared},
+		}},
+		{"not found", strings.Repeat("d4", 32), "", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exp.ChainDisabledMap = map[string]bool{tt.disabled: true}
+			results := exp.searchHash(tt.hash, tt.outIndex)
+			if len(results) != len(tt.want) {
+				t.Fatalf("got %d results %v, want %d", len(results), results, len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := result{results[i].ChainType, results[i].Kind, results[i].URL}
+				if got != want {
+					t.Errorf("result %d is %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchHeight(t *testing.T) {
+	src := &searchSource{
+		blocks: map[string]map[string]int64{
+			mutilchain.TYPEDCR: {"dcr100": 100, "dcr200": 200},
+			mutilchain.TYPEBTC: {"btc100": 100},
+			mutilchain.TYPEXMR: {"xmr100": 100, "xmr300": 300},
+		},
+	}
+	exp := &ExplorerUI{
+		dataSource:       src,
+		ChainDisabledMap: map[string]bool{},
+	}
+	tests := []struct {
+		height int64
+		want   []string
+	}{
+		{100, []string{"/decred/block/100", "/btc/block/100", "/xmr/block/100"}},
+		{200, []string{"/decred/block/200"}},
+		{300, []string{"/xmr/block/300"}},
+		{400, nil},
+	}
+	for _, tt := range tests {
+		results := exp.searchHeight(tt.height)
+		if len(results) != len(tt.want) {
+			t.Errorf("height %d: got results %v, want %v", tt.height, results, tt.want)
+			continue
+		}
+		for i, url := range tt.want {
+			if results[i].URL != url || results[i].Kind != searchKindBlock {
+				t.Errorf("height %d: result %d is %v, want a block at %s", tt.height, i, results[i], url)
+			}
+		}
+	}
+}
+
+func TestAddressTypoCandidates(t *testing.T) {
+	tests := []struct {
+		name     string
+		term     string
+		want     []string
+		notWant  []string
+		wantSize int
+	}{
+		{
+			name:     "base58",
+			term:     "1abc",
+			want:     []string{"2abc", "1zbc", "1abd", "a1bc", "1bac", "1acb"},
+			notWant:  []string{"1abc", "0abc", "1Oabc"},
+			wantSize: 4*(len(base58Alphabet)-1) + 3,
+		},
+		{
+			name:     "repeated characters",
+			term:     "1aab",
+			want:     []string{"1aba", "a1ab"},
+			wantSize: 4*(len(base58Alphabet)-1) + 2,
+		},
+		{
+			name:     "bech32",
+			term:     "BC1QAZ",
+			want:     []string{"bc1paz", "bc1qa7", "bc1aqz", "bc1qza"},
+			notWant:  []string{"bc1qaz", "xc1qaz", "bcq1az", "bc1qbz"},
+			wantSize: 3*(len(bech32Charset)-1) + 2,
+		},
+		{
+			name:    "ltc bech32",
+			term:    "ltc1qaz",
+			want:    []string{"ltc1paz"},
+			notWant: []string{"ltc1qai", "Ltc1qaz"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates := addressTypoCandidates(tt.term)
+			set := make(map[string]bool, len(candidates))
+			for _, c := range candidates {
+				set[c] = true
+			}
+			for _, c := range tt.want {
+				if !set[c] {
+					t.Errorf("missing candidate %s", c)
+				}
+			}
+			for _, c := range tt.notWant {
+				if set[c] {
+					t.Errorf("unexpected candidate %s", c)
+				}
+			}
+			if tt.wantSize > 0 && len(candidates) != tt.wantSize {
+				t.Errorf("got %d candidates, want %d", len(candidates), tt.wantSize)
+			}
+		})
+	}
+}
+
+// typo replaces the character at i.
+func typo(addr string, i int, c byte) string {
+	b := []byte(addr)
+	if b[i] == c {
+		c = 'z'
+	}
+	b[i] = c
+	return string(b)
+}
+
+func TestSearchAddress(t *testing.T) {
+	exp := &ExplorerUI{
+		ChainParams:      chaincfg.MainNetParams(),
+		BtcChainParams:   &btcchaincfg.MainNetParams,
+		LtcChainParams:   &ltcchaincfg.MainNetParams,
+		XmrNet:           xmrutil.NetMainnet,
+		ChainDisabledMap: map[string]bool{},
+	}
+	hash160 := bytes.Repeat([]byte{0x5a}, 20)
+
+	dcrAddr, err := stdaddr.NewAddressPubKeyHashEcdsaSecp256k1V0(hash160, exp.ChainParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	btcAddr, err := btcutil.NewAddressPubKeyHash(hash160, exp.BtcChainParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	btcSegwit, err := btcutil.NewAddressWitnessPubKeyHash(hash160, exp.BtcChainParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ltcAddr, err := ltcutil.NewAddressPubKeyHash(hash160, exp.LtcChainParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xmrAddr := (&xmrutil.Address{
+		Network:        xmrutil.NetMainnet,
+		Type:           xmrutil.AddrStandard,
+		PublicSpendKey: bytes.Repeat([]byte{1}, 32),
+		PublicViewKey:  bytes.Repeat([]byte{2}, 32),
+	}).String()
+
+	tests := []struct {
+		chain, addr string
+	}{
+		{mutilchain.TYPEDCR, dcrAddr.String()},
+		{mutilchain.TYPEBTC, btcAddr.EncodeAddress()},
+		{mutilchain.TYPEBTC, btcSegwit.EncodeAddress()},
+		{mutilchain.TYPELTC, ltcAddr.EncodeAddress()},
+		{mutilchain.TYPEXMR, xmrAddr},
+	}
+	for _, tt := range tests {
+		results := exp.searchAddress(tt.addr)
+		if len(results) != 1 || results[0].ChainType != tt.chain {
+			t.Errorf("%s: got results %v, want a %s address", tt.addr, results, tt.chain)
+			continue
+		}
+
+		// A single substituted or transposed character is corrected.
+		for _, bad := range []string{
+			typo(tt.addr, len(tt.addr)-5, 'x'),
+			tt.addr[:len(tt.addr)-3] + tt.addr[len(tt.addr)-2:len(tt.addr)-1] +
+				tt.addr[len(tt.addr)-3:len(tt.addr)-2] + tt.addr[len(tt.addr)-1:],
+		} {
+			if bad == tt.addr || len(exp.searchAddress(bad)) > 0 {
+				continue
+			}
+			var found bool
+			for _, s := range exp.addressSuggestions(bad) {
+				found = found || (s.ChainType == tt.chain && s.Value == tt.addr)
+			}
+			if !found {
+				t.Errorf("%s: no suggestion for %s", tt.addr, bad)
+			}
+		}
+	}
+
+	// Regtest monerod uses mainnet addresses, but stagenet does not.
+	exp.XmrNet = xmrutil.NetRegtest
+	if results := exp.searchAddress(xmrAddr); len(results) != 1 {
+		t.Errorf("mainnet address not found on regtest: %v", results)
+	}
+	exp.XmrNet = xmrutil.NetStagenet
+	if results := exp.searchAddress(xmrAddr); len(results) != 0 {
+		t.Errorf("mainnet address found on stagenet: %v", results)
+	}
+
+	// Disabled chains are not searched.
+	exp.ChainDisabledMap[mutilchain.TYPEBTC] = true
+	if results := exp.searchAddress(btcAddr.EncodeAddress()); len(results) != 0 {
+		t.Errorf("unexpected results for disabled chain: %v", results)
+	}
+
+	if looksLikeAddress("0000000000000000000000000000000000000000000000000000000000000000:1") {
+		t.Errorf("outpoint looks like an address")
+	}
+}
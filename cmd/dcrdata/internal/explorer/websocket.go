@@ -55,6 +55,14 @@ type WebSocketMessage struct {
 	Message string `json:"message"`
 }
 
+// MultichainRawTxMessage is the payload of the decodemultichaintx and
+// sendmultichaintx websocket events. RawTx may be a hex encoded transaction or
+// a hex or base64 encoded PSBT.
+type MultichainRawTxMessage struct {
+	ChainType string `json:"chain"`
+	RawTx     string `json:"rawtx"`
+}
+
 // WebsocketHub and its event loop manage all websocket client connections.
 // WebsocketHub is responsible for closing all connections registered with it.
 // If the event loop is running, calling (*WebsocketHub).Stop() will handle it.
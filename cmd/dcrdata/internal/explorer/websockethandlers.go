@@ -117,6 +117,36 @@ func (exp *ExplorerUI) RootWebsocket(w http.ResponseWriter, r *http.Request) {
 						webData.Message = fmt.Sprintf("Transaction sent: %s", txid)
 					}
 
+				case "decodemultichaintx", "sendmultichaintx":
+					var req MultichainRawTxMessage
+					if err := json.Unmarshal([]byte(msg.Message), &req); err != nil {
+						webData.Message = "Error: invalid request"
+						break
+					}
+					log.Debugf("Received %s signal for %s tx: %.40s...", msg.EventId, req.ChainType, req.RawTx)
+					if msg.EventId == "sendmultichaintx" {
+						txid, err := exp.dataSource.MutilchainSendRawTransaction(req.RawTx, req.ChainType)
+						if err != nil {
+							webData.Message = fmt.Sprintf("Error: %v", err)
+						} else {
+							webData.Message = fmt.Sprintf("Transaction sent: %s", txid)
+						}
+						break
+					}
+					tx, err := exp.dataSource.MutilchainDecodeRawTransaction(req.RawTx, req.ChainType)
+					if err != nil {
+						log.Debugf("Could not decode raw %s tx: %v", req.ChainType, err)
+						webData.Message = fmt.Sprintf("Error: %v", err)
+						break
+					}
+					message, err := json.MarshalIndent(tx, "", "    ")
+					if err != nil {
+						log.Warn("Invalid JSON message: ", err)
+						webData.Message = errMsgJSONEncode
+						break
+					}
+					webData.Message = string(message)
+
 				case "getmempooltxs":
 					// MempoolInfo. Used on mempool and home page.
 					inv := exp.MempoolInventory()
@@ -62,6 +62,7 @@ const (
 	ctxIndent
 	ctxChainType
 	ctxTSpendHash
+	CtxWatch
 )
 
 type DataSource interface {
@@ -445,6 +446,17 @@ func GetMultichainBlockHeightCtx(r *http.Request) (int, error) {
 	return blockHeight, nil
 }
 
+// GetChainTypeCtx retrieves the ctxChainType data from the request context. If
+// not set, the return value is an empty string.
+func GetChainTypeCtx(r *http.Request) string {
+	chainType, ok := r.Context().Value(ctxChainType).(string)
+	if !ok {
+		apiLog.Trace("chain type not set")
+		return ""
+	}
+	return chainType
+}
+
 // GetMultichainAddressCtx retrieves the CtxAddress and ctxChainType data from the request context.
 // If not set, the return value is an empty strings.
 func GetMultichainAddressCtx(r *http.Request) (string, string) {
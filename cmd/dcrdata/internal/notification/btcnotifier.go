@@ -29,7 +29,10 @@ type BTCNotifier struct {
 	block           [][]BtcBlockHandler
 	pollInterval    time.Duration
 	lastKnownHeight int64
-	previous        struct {
+	// mempoolSeen is the set of mempool transactions already sent to the tx
+	// handlers.
+	mempoolSeen map[string]struct{}
+	previous    struct {
 		hash   chainhash.Hash
 		height uint32
 	}
@@ -67,6 +70,9 @@ func (notifier *BTCNotifier) Listen(ctx context.Context) *ContextualError {
 
 	go notifier.superQueue(ctx)
 	go notifier.pollBlocks(ctx)
+	if len(notifier.tx) > 0 {
+		go notifier.pollMempool(ctx)
+	}
 	return nil
 }
 
@@ -126,6 +132,58 @@ func (notifier *BTCNotifier) checkForNewBlocks() {
 	}
 }
 
+// pollMempool polls the node's mempool periodically and queues the
+// transactions that were not seen before. The node is polled since the tx
+// notifications require a websocket connection.
+func (notifier *BTCNotifier) pollMempool(ctx context.Context) {
+	ticker := time.NewTicker(notifier.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Infof("BTC: Mempool polling stopped")
+			return
+		case <-ticker.C:
+			notifier.checkForNewTxs()
+		}
+	}
+}
+
+// checkForNewTxs queues the mempool transactions that were not in the
+// mempool on the previous poll. The first poll only records the mempool.
+func (notifier *BTCNotifier) checkForNewTxs() {
+	hashes, err := notifier.client.GetRawMempool()
+	if err != nil {
+		log.Errorf("BTC: Failed to get raw mempool: %v", err)
+		return
+	}
+
+	first := notifier.mempoolSeen == nil
+	seen := make(map[string]struct{}, len(hashes))
+	for _, hash := range hashes {
+		txid := hash.String()
+		seen[txid] = struct{}{}
+		if first {
+			continue
+		}
+		if _, found := notifier.mempoolSeen[txid]; found {
+			continue
+		}
+		tx, err := notifier.client.GetRawTransactionVerbose(hash)
+		if err != nil {
+			// Mined or replaced since GetRawMempool.
+			log.Debugf("BTC: Failed to get mempool transaction %v: %v", hash, err)
+			delete(seen, txid)
+			continue
+		}
+		if tx.Time == 0 {
+			tx.Time = time.Now().Unix()
+		}
+		notifier.anyQ <- tx
+	}
+	notifier.mempoolSeen = seen
+}
+
 // superQueue processes notifications from the queue.
 func (notifier *BTCNotifier) superQueue(ctx context.Context) {
 out:
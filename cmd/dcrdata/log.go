@@ -31,11 +31,13 @@ import (
 	"github.com/decred/dcrdata/v8/blockdata/blockdatabtc"
 	"github.com/decred/dcrdata/v8/blockdata/blockdataltc"
 	"github.com/decred/dcrdata/v8/blockdata/blockdataxmr"
+	"github.com/decred/dcrdata/v8/cluster"
 	"github.com/decred/dcrdata/v8/mempool"
 	"github.com/decred/dcrdata/v8/mutilchain/externalapi"
 	"github.com/decred/dcrdata/v8/pubsub"
 	"github.com/decred/dcrdata/v8/rpcutils"
 	"github.com/decred/dcrdata/v8/stakedb"
+	"github.com/decred/dcrdata/v8/watchlist"
 )
 
 var (
@@ -62,6 +64,8 @@ var (
 	btcBlockdataLog slog.Logger
 	ltcBlockdataLog slog.Logger
 	xmrBlockdataLog slog.Logger
+	watchlistLog    slog.Logger
+	clusterLog      slog.Logger
 	// filled after init so setLogLevels works
 	subsystemLoggers map[string]slog.Logger
 )
@@ -246,11 +250,13 @@ func initLogRotators(logPath, debugLogPath string, maxRolls int) {
 	btcBlockdataLog = backendLog.Logger("BTCBLKD")
 	ltcBlockdataLog = backendLog.Logger("LTCBLKD")
 	xmrBlockdataLog = backendLog.Logger("XMRBLKD")
+	watchlistLog = backendLog.Logger("WTCH")
+	clusterLog = backendLog.Logger("CLST")
 	all := []slog.Logger{
 		notifyLog, postgresqlLog, stakedbLog, BlockdataLog, clientLog,
 		mempoolLog, expLog, apiLog, log, iapiLog, pubsubLog,
 		xcBotLog, agendasLog, proposalsLog, externalLog, btcBlockdataLog,
-		ltcBlockdataLog, xmrBlockdataLog,
+		ltcBlockdataLog, xmrBlockdataLog, watchlistLog, clusterLog,
 	}
 	for _, lg := range all {
 		lg.SetLevel(slog.LevelDebug)
@@ -276,6 +282,8 @@ func initLogRotators(logPath, debugLogPath string, maxRolls int) {
 	blockdatabtc.UseLogger(btcBlockdataLog)
 	blockdataltc.UseLogger(ltcBlockdataLog)
 	blockdataxmr.UseLogger(xmrBlockdataLog)
+	watchlist.UseLogger(watchlistLog)
+	cluster.UseLogger(clusterLog)
 
 	// Save map to use setLogLevels laters
 	subsystemLoggers = map[string]slog.Logger{
@@ -297,6 +305,8 @@ func initLogRotators(logPath, debugLogPath string, maxRolls int) {
 		"BTCBLKD": btcBlockdataLog,
 		"LTCBLKD": ltcBlockdataLog,
 		"XMRBLKD": xmrBlockdataLog,
+		"WTCH":    watchlistLog,
+		"CLST":    clusterLog,
 	}
 }
 
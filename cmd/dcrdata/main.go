@@ -37,9 +37,11 @@ import (
 	"github.com/decred/dcrdata/v8/blockdata/blockdatabtc"
 	"github.com/decred/dcrdata/v8/blockdata/blockdataltc"
 	"github.com/decred/dcrdata/v8/blockdata/blockdataxmr"
+	"github.com/decred/dcrdata/v8/cluster"
 	"github.com/decred/dcrdata/v8/db/cache"
 	"github.com/decred/dcrdata/v8/db/dbtypes"
 	"github.com/decred/dcrdata/v8/mempool"
+	"github.com/decred/dcrdata/v8/mempool/mempoolbtc"
 	"github.com/decred/dcrdata/v8/mempool/mempoolltc"
 	"github.com/decred/dcrdata/v8/mutilchain"
 	"github.com/decred/dcrdata/v8/mutilchain/btcrpcutils"
@@ -50,6 +52,7 @@ import (
 	"github.com/decred/dcrdata/v8/rpcutils"
 	"github.com/decred/dcrdata/v8/semver"
 	"github.com/decred/dcrdata/v8/stakedb"
+	"github.com/decred/dcrdata/v8/watchlist"
 	"github.com/decred/dcrdata/v8/xmr/xmrclient"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -586,6 +589,75 @@ func _main(ctx context.Context) error {
 	// WaitGroup for monitoring goroutines
 	var wg sync.WaitGroup
 
+	// Watch-list of address activity, delivered to the registered webhooks.
+	var wl *watchlist.Watchlist
+	if cfg.EnableWatchlist {
+		wlCfg := &watchlist.Config{
+			Store:                 chainDB,
+			AllowPrivateCallbacks: cfg.WatchlistAllowLocal,
+		}
+		if !dcrDisabled {
+			wlCfg.DCRParams = activeChain
+		}
+		if !btcDisabled {
+			wlCfg.BTCParams = btcActiveChain
+		}
+		if !ltcDisabled {
+			wlCfg.LTCParams = ltcActiveChain
+		}
+		if !xmrDisabled {
+			wlCfg.XMRTxsJSON = func(txHashes []string) ([]string, error) {
+				txs, err := xmrClient.GetTransactions(txHashes, true)
+				if err != nil {
+					return nil, err
+				}
+				return txs.TxsAsJSON, nil
+			}
+		}
+		wl, err = watchlist.New(wlCfg)
+		if err != nil {
+			return fmt.Errorf("failed to load the watch-list: %w", err)
+		}
+		blockDataSavers = append(blockDataSavers, wl)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wl.Run(ctx)
+		}()
+	}
+
+	// Known-entity address labels, from the embedded labels file and the
+	// labels managed with the admin key.
+	if err = chainDB.CheckAndCreateLabelsTable(); err != nil {
+		return fmt.Errorf("check and create labels table failed: %v", err)
+	}
+	if err = chainDB.LoadAddressLabels(); err != nil {
+		return fmt.Errorf("failed to load the address labels: %w", err)
+	}
+
+	// Address clustering of the UTXO chains, extended after the database
+	// stores each block.
+	var clusterer *cluster.Clusterer
+	if cfg.EnableClustering {
+		var chains []string
+		if !dcrDisabled {
+			chains = append(chains, mutilchain.TYPEDCR)
+		}
+		if !btcDisabled {
+			chains = append(chains, mutilchain.TYPEBTC)
+		}
+		if !ltcDisabled {
+			chains = append(chains, mutilchain.TYPELTC)
+		}
+		clusterer = cluster.New(chainDB, chains...)
+		blockDataSavers = append(blockDataSavers, clusterer)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			clusterer.Run(ctx)
+		}()
+	}
+
 	// ExchangeBot
 	var xcBot *exchanges.ExchangeBot
 	if cfg.EnableExchangeBot && activeChain.Name != "mainnet" {
@@ -604,6 +676,9 @@ func _main(ctx context.Context) error {
 		if cfg.DisabledExchanges != "" {
 			botCfg.Disabled = strings.Split(cfg.DisabledExchanges, ",")
 		}
+		if !chainDB.ChainDBDisabled {
+			botCfg.History = &exchangeHistory{chainDB}
+		}
 		xcBot, err = exchanges.NewExchangeBot(&botCfg)
 		if err != nil {
 			log.Errorf("Could not create exchange monitor. Exchange info will be disabled: %v", err)
@@ -702,6 +777,7 @@ func _main(ctx context.Context) error {
 		ChainDisabledMap: chainDisabledMap,
 		CoinCaps:         coinCaps,
 		MainHost:         cfg.MainHost,
+		XmrNet:           xmrActiveNet,
 	})
 	// TODO: allow views config
 	if explore == nil {
@@ -858,7 +934,11 @@ func _main(ctx context.Context) error {
 		Charts:            charts,
 		ChainDisabledMap:  chainDisabledMap,
 		CoinCaps:          coinCaps,
+		LabelsAdminKey:    cfg.LabelsAdminKey,
 	})
+	if wl != nil {
+		app.UseWatchlist(wl)
+	}
 	getMarketCapData := func() {
 		//get coin cap data from extenal api
 		coinCapData := externalapi.GetCoinigyCapData(coinCaps)
@@ -904,7 +984,8 @@ func _main(ctx context.Context) error {
 		log.Debugf("Using Server HTTP response header %q", cfg.ServerHeader)
 		webMux.Use(mw.Server(cfg.ServerHeader))
 	}
-	// Request per sec limit for "POST /verify-message" endpoint.
+	// Request per sec limit for the "POST /verify-message" and "POST /xpub"
+	// endpoints, and the transaction graph pages.
 	reqPerSecLimit := 5.0
 	// Create a rate limiter struct.
 	limiter := mw.NewLimiter(reqPerSecLimit)
@@ -1029,6 +1110,10 @@ func _main(ctx context.Context) error {
 			rd.With(explorer.TransactionHashCtx, explorer.TransactionIoIndexCtx).Get("/tx/{txid}/{inout}/{inoutid}", explore.TxPage)
 			rd.With(explorer.AddressPathCtx, LimitAddressMiddleware(rlCfg)).Get("/address/{address}", explore.AddressPage)
 			rd.With(explorer.AddressPathCtx).Get("/addresstable/{address}", explore.AddressTable)
+			rd.Get("/cluster/{clusterid}", explore.ClusterPage)
+			rd.With(mw.Tollbooth(limiter)).Get("/txgraph", explore.TxGraphPage)
+			rd.Get("/mixing", explore.MixingPage)
+			rd.Get("/richlist", explore.RichListPage)
 			rd.Get("/treasury", explore.TreasuryPage)
 			rd.Get("/treasurytable", explore.TreasuryTable)
 			rd.Get("/atomicswaps-table", explore.AtomicSwapsTable)
@@ -1159,10 +1244,17 @@ func _main(ctx context.Context) error {
 			rd.Get("/supply", explore.SupplyPage)
 			rd.Get("/visualblocks", explore.MultichainVisualBlocks)
 			rd.Get("/parameters", explore.MutilchainParametersPage)
+			rd.Get("/decodetx", explore.MutilchainDecodeTxPage)
+			rd.Get("/pools", explore.MutilchainPoolsPage)
 			rd.With(explorer.AddressPathCtx).Get("/address/{address}", explore.MutilchainAddressPage)
 			rd.With(explorer.AddressPathCtx).Get("/addresstable/{address}", explore.MutilchainAddressTable)
+			rd.Get("/cluster/{clusterid}", explore.ClusterPage)
+			rd.With(mw.Tollbooth(limiter)).Get("/txgraph", explore.TxGraphPage)
+			rd.Get("/richlist", explore.RichListPage)
 		})
 		r.With(mw.Tollbooth(limiter)).Post("/verify-message", explore.VerifyMessageHandler)
+		r.Get("/xpub", explore.HDWalletPage)
+		r.With(mw.Tollbooth(limiter)).Post("/xpub", explore.HDWalletHandler)
 	})
 
 	// Configure a page for the bare "/insight" path. This mounts the static
@@ -1440,6 +1532,26 @@ func _main(ctx context.Context) error {
 			return err
 		}
 		log.Infof("Finish checking and syncing coin age tables...")
+
+		// The mixing statistics are synced in the background. Blocks stored
+		// later sync from the last stored statistics, after this sync.
+		err = chainDB.CheckAndCreateMixingTables()
+		if err != nil {
+			return fmt.Errorf("check and create mixing tables failed: %v", err)
+		}
+		go func() {
+			if err := chainDB.SyncMixingData(); err != nil {
+				log.Errorf("dcrpg.SyncMixingData failed: %v", err)
+			}
+		}()
+
+		// The address balances of the rich list and the wealth distribution
+		// are brought up to date in the background as well.
+		err = chainDB.CheckAndCreateWealthTables()
+		if err != nil {
+			return fmt.Errorf("check and create wealth tables failed: %v", err)
+		}
+		go chainDB.SyncWealth(mutilchain.TYPEDCR)
 	}
 	log.Debugf("Start sync btc/ltc tx count")
 	go chainDB.SyncMultichainMetaInfo(btcDisabled, ltcDisabled)
@@ -1599,6 +1711,9 @@ func _main(ctx context.Context) error {
 	notifier.RegisterReorgHandlerGroup(bdChainMonitor.ReorgHandler, chainDBChainMonitor.ReorgHandler)
 	notifier.RegisterReorgHandlerGroup(charts.ReorgHandler) // snip charts data
 	notifier.RegisterTxHandlerGroup(mpm.TxHandler, insightSocketServer.SendNewTx)
+	if wl != nil {
+		notifier.RegisterTxHandlerGroup(wl.TxHandler)
+	}
 
 	// After this final node sync check, the monitors will handle new blocks.
 	// TODO: make this not racy at all by having notifiers register first, but
@@ -1796,8 +1911,13 @@ func _main(ctx context.Context) error {
 		//start - handler notifier for ltc
 		xmrBlockDataSavers := []blockdataxmr.BlockDataSaver{}
 		xmrBlockDataSavers = append(xmrBlockDataSavers, chainDB)
+		psHub.XmrClient = xmrClient
+		psHub.XMRViewKeyWatch = cfg.XmrViewKeyWatch
 		xmrBlockDataSavers = append(xmrBlockDataSavers, psHub)
 		xmrBlockDataSavers = append(xmrBlockDataSavers, explore)
+		if wl != nil {
+			xmrBlockDataSavers = append(xmrBlockDataSavers, wl)
+		}
 		// Add charts saver method after explorer and database stores. This may run
 		// asynchronously.
 		xmrBlockDataSavers = append(xmrBlockDataSavers, blockdataxmr.BlockTrigger{
@@ -1816,13 +1936,18 @@ func _main(ctx context.Context) error {
 		if cerr != nil {
 			return fmt.Errorf("XMR RPC client error: %v", cerr)
 		}
-		go explore.UpdateXMRMempoolData(xmrClient, make(chan struct{}))
+		xmrMempoolSavers := []explorer.XMRMempoolSaver{psHub}
+		if wl != nil {
+			xmrMempoolSavers = append(xmrMempoolSavers, wl)
+		}
+		go explore.UpdateXMRMempoolData(xmrClient, make(chan struct{}), xmrMempoolSavers...)
 	}
 
 	// handler syncing for XMR blockchain on background
 	if !xmrDisabled && xmrClient != nil && chainDB.XmrSyncFlag {
 		go chainDB.SyncBulkXMRBlockSummaryData()
 		go chainDB.SyncXMRWholeChain(xmrNewPGIndexes)
+		go chainDB.SyncXMRRingAnalysis()
 	}
 
 	//start - init rpcclient for all blockchain
@@ -1833,6 +1958,7 @@ func _main(ctx context.Context) error {
 			return fmt.Errorf("Check and create table for blockchain %s errors: %w", mutilchain.TYPELTC, checkErr)
 		}
 		// Initialize LTC mempool data via mempool collector
+		var ltcMempoolMonitor *mempoolltc.MempoolMonitor
 		if !chainDB.ChainDBDisabled {
 			ltcMempoolSavers := []mempoolltc.MempoolDataSaver{chainDB.LTCMPC}
 			ltcMempoolSavers = append(ltcMempoolSavers, explore)
@@ -1842,13 +1968,18 @@ func _main(ctx context.Context) error {
 				return fmt.Errorf("Failed to create LTC mempool data collector")
 			}
 
-			mpm, err := mempoolltc.NewMempoolMonitor(ctx, ltcMpoolCollector, ltcMempoolSavers,
-				ltcActiveChain, true)
+			// Replacements, evictions and CPFP packages are sent to the pubsub
+			// hub's clients.
+			ltcMempoolSigOuts := []chan<- pstypes.HubMessage{signalToPSHub}
+			ltcMempoolMonitor, err = mempoolltc.NewMempoolMonitor(ctx, ltcMpoolCollector, ltcMempoolSavers,
+				ltcActiveChain, ltcMempoolSigOuts, true)
 			if err != nil {
 				requestShutdown()
 				return fmt.Errorf("NewMempoolMonitor: %v", err)
 			}
-			chainDB.UseLTCMempoolChecker(mpm)
+			chainDB.UseLTCMempoolChecker(ltcMempoolMonitor)
+			explore.UseMempoolTracker(mutilchain.TYPELTC, ltcMempoolMonitor)
+			app.UseMempoolProjector(mutilchain.TYPELTC, ltcMempoolMonitor)
 		}
 
 		//Start - LTC Sync handler
@@ -1965,10 +2096,32 @@ func _main(ctx context.Context) error {
 		ltcBlockDataSavers = append(ltcBlockDataSavers, chainDB)
 		ltcBlockDataSavers = append(ltcBlockDataSavers, psHub)
 		ltcBlockDataSavers = append(ltcBlockDataSavers, explore)
+		if wl != nil {
+			ltcBlockDataSavers = append(ltcBlockDataSavers, wl)
+		}
+		if clusterer != nil {
+			ltcBlockDataSavers = append(ltcBlockDataSavers, clusterer)
+		}
+		// Add charts saver method after explorer and database stores. This may run
+		// asynchronously. Without a synced database, the charts data comes from
+		// external APIs and is only refreshed periodically.
+		if ltcCharts.UseSyncDB {
+			ltcBlockDataSavers = append(ltcBlockDataSavers, blockdataltc.BlockTrigger{
+				Async: true,
+				Saver: ltcCharts.TriggerUpdate,
+			})
+		}
 		ltcBdChainMonitor := blockdataltc.NewChainMonitor(ctx, ltcCollector, ltcBlockDataSavers,
 			ltcReorgBlockDataSavers)
 
 		ltcNotifier.RegisterBlockHandlerGroup(ltcBdChainMonitor.ConnectBlock)
+		if ltcMempoolMonitor != nil {
+			ltcNotifier.RegisterBlockHandlerGroup(ltcMempoolMonitor.BlockHandler)
+			ltcNotifier.RegisterTxHandlerGroup(ltcMempoolMonitor.TxHandler)
+		}
+		if wl != nil {
+			ltcNotifier.RegisterTxHandlerGroup(wl.LTCTxHandler)
+		}
 		cerr := ltcNotifier.Listen(ctx)
 		if cerr != nil {
 			return fmt.Errorf("LTC RPC client error: %v (%v)", cerr.Error(), cerr.Cause())
@@ -1983,6 +2136,28 @@ func _main(ctx context.Context) error {
 		if checkErr != nil {
 			return fmt.Errorf("Check and create table for blockchain %s errors: %w", mutilchain.TYPEBTC, checkErr)
 		}
+		// Initialize BTC mempool data via mempool collector
+		var btcMempoolMonitor *mempoolbtc.MempoolMonitor
+		if !chainDB.ChainDBDisabled {
+			btcMempoolSavers := []mempoolbtc.MempoolDataSaver{chainDB.BTCMPC}
+			btcMempoolSavers = append(btcMempoolSavers, explore)
+			btcMpoolCollector := mempoolbtc.NewDataCollector(btcdClient, btcActiveChain)
+			if btcMpoolCollector == nil {
+				requestShutdown()
+				return fmt.Errorf("Failed to create BTC mempool data collector")
+			}
+
+			btcMempoolSigOuts := []chan<- pstypes.HubMessage{signalToPSHub}
+			btcMempoolMonitor, err = mempoolbtc.NewMempoolMonitor(ctx, btcMpoolCollector, btcMempoolSavers,
+				btcActiveChain, btcMempoolSigOuts, true)
+			if err != nil {
+				requestShutdown()
+				return fmt.Errorf("NewMempoolMonitor: %v", err)
+			}
+			chainDB.UseBTCMempoolChecker(btcMempoolMonitor)
+			explore.UseMempoolTracker(mutilchain.TYPEBTC, btcMempoolMonitor)
+			app.UseMempoolProjector(mutilchain.TYPEBTC, btcMempoolMonitor)
+		}
 
 		//Start - BTC Sync handler
 		btcHeightFromDB, err := chainDB.MutilchainHeightDB(mutilchain.TYPEBTC)
@@ -2098,11 +2273,33 @@ func _main(ctx context.Context) error {
 		btcBlockDataSavers = append(btcBlockDataSavers, chainDB)
 		btcBlockDataSavers = append(btcBlockDataSavers, psHub)
 		btcBlockDataSavers = append(btcBlockDataSavers, explore)
+		if wl != nil {
+			btcBlockDataSavers = append(btcBlockDataSavers, wl)
+		}
+		if clusterer != nil {
+			btcBlockDataSavers = append(btcBlockDataSavers, clusterer)
+		}
+		// Add charts saver method after explorer and database stores. This may run
+		// asynchronously. Without a synced database, the charts data comes from
+		// external APIs and is only refreshed periodically.
+		if btcCharts.UseSyncDB {
+			btcBlockDataSavers = append(btcBlockDataSavers, blockdatabtc.BlockTrigger{
+				Async: true,
+				Saver: btcCharts.TriggerUpdate,
+			})
+		}
 		btcReorgBlockDataSavers := []blockdatabtc.BlockDataSaver{explore}
 		btcBdChainMonitor := blockdatabtc.NewChainMonitor(ctx, btcCollector, btcBlockDataSavers,
 			btcReorgBlockDataSavers)
 
 		btcNotifier.RegisterBlockHandlerGroup(btcBdChainMonitor.ConnectBlock)
+		if btcMempoolMonitor != nil {
+			btcNotifier.RegisterBlockHandlerGroup(btcMempoolMonitor.BlockHandler)
+			btcNotifier.RegisterTxHandlerGroup(btcMempoolMonitor.TxHandler)
+		}
+		if wl != nil {
+			btcNotifier.RegisterTxHandlerGroup(wl.BTCTxHandler)
+		}
 		cerr := btcNotifier.Listen(ctx)
 		if cerr != nil {
 			return fmt.Errorf("BTC RPC client error: %v (%v)", cerr.Error(), cerr.Cause())
@@ -2224,6 +2421,14 @@ func _main(ctx context.Context) error {
 			ltcUpdateAllAddresses, ltcNewPGIndexes = false, false
 		}
 		chainDB.MutilchainEnableDuplicateCheckOnInsert(true, mutilchain.TYPELTC)
+		// Attribute the synced blocks to mining pools in the background.
+		go chainDB.SyncMutilchainBlockPools(mutilchain.TYPELTC)
+		// Bring the coin age tables up to date in the background.
+		go chainDB.SyncMutilchainCoinAge(mutilchain.TYPELTC)
+		// Bring the new address tables up to date in the background.
+		go chainDB.SyncMutilchainNewAddresses(mutilchain.TYPELTC)
+		// Bring the address balances up to date in the background.
+		go chainDB.SyncWealth(mutilchain.TYPELTC)
 		//Finished - LTC Sync handler
 	}
 
@@ -2274,6 +2479,14 @@ func _main(ctx context.Context) error {
 			btcUpdateAllAddresses, btcNewPGIndexes = false, false
 		}
 		chainDB.MutilchainEnableDuplicateCheckOnInsert(true, mutilchain.TYPEBTC)
+		// Attribute the synced blocks to mining pools in the background.
+		go chainDB.SyncMutilchainBlockPools(mutilchain.TYPEBTC)
+		// Bring the coin age tables up to date in the background.
+		go chainDB.SyncMutilchainCoinAge(mutilchain.TYPEBTC)
+		// Bring the new address tables up to date in the background.
+		go chainDB.SyncMutilchainNewAddresses(mutilchain.TYPEBTC)
+		// Bring the address balances up to date in the background.
+		go chainDB.SyncWealth(mutilchain.TYPEBTC)
 		//Finished - BTC Sync handler
 	}
 	if !btcDisabled && btcdClient != nil && chainDB.SyncChainDBFlag {
@@ -20,9 +20,10 @@ const atomsToDCR = 1e-8
 const picoToXmr = 1e-12
 const windowScales = ['ticket-price', 'missed-votes']
 const hybridScales = ['privacy-participation']
-const lineScales = ['ticket-price', 'privacy-participation', 'decoy-bands']
+const lineScales = ['ticket-price', 'privacy-participation', 'decoy-bands', 'coin-age-bands', 'ring-member-ages',
+  'balance-addresses', 'balance-value', 'wealth-concentration']
 const modeScales = ['ticket-price']
-const binDisabled = ['decoy-bands']
+const binDisabled = ['decoy-bands', 'mined-blocks', 'balance-addresses', 'balance-value', 'wealth-concentration']
 const decoyBandsLabels = ['none', 'No Tx', 'Decoys 0-3', 'Decoys 4-7', 'Decoys 8-11', 'Decoys 12-14', 'Decoys > 15', 'Mixin']
 const decoyBandsColors = [
   '#e9baa6',
@@ -34,6 +35,43 @@ const decoyBandsColors = [
   '#990099',
   '#4c4c4cff'
 ]
+const coinAgeBandsKeys = ['greaterThan7Year', 'fiveYearTo7Year', 'threeYearTo5Year', 'twoYearTo3Year', 'yearTo2Year',
+  'halfYearToYear', 'monthToHalfYear', 'weekToMonth', 'dayToWeek', 'less1Day']
+const coinAgeBandsLabels = ['>7Y', '5-7Y', '3-5Y', '2-3Y', '1-2Y', '6M-1Y', '1-6M', '1W-1M', '1D-1W', '<1D']
+const coinAgeBandsColors = [
+  '#152b83',
+  '#dc3912',
+  '#ff9900',
+  '#109618',
+  '#990099',
+  '#0099c6',
+  '#dd4477',
+  '#66aa00',
+  '#b82e2e',
+  '#576812ff'
+]
+const wealthBucketsColors = [
+  '#2970ff',
+  '#2dd8a3',
+  '#ff9900',
+  '#dc3912',
+  '#990099',
+  '#0099c6',
+  '#dd4477',
+  '#66aa00',
+  '#b82e2e',
+  '#152b83'
+]
+const ringAgeBandsLabels = ['<2h', '2h-1d', '1d-1w', '1w-1m', '1m-6m', '6m-1y', '>1y']
+const ringAgeBandsColors = [
+  '#dc3912',
+  '#ff9900',
+  '#109618',
+  '#0099c6',
+  '#990099',
+  '#152b83',
+  '#4c4c4cff'
+]
 let globalChainType = ''
 // index 0 represents y1 and 1 represents y2 axes.
 const yValueRanges = { 'ticket-price': [1] }
@@ -60,10 +98,25 @@ const yAxisLabelWidth = {
     'fee-rate': 50,
     'avg-tx-size': 40,
     fees: 50,
-    'decoy-bands': 40
+    'decoy-bands': 40,
+    'avg-age-days': 50,
+    'coin-days-destroyed': 50,
+    'coin-age-bands': 40,
+    'mean-coin-age': 50,
+    'total-coin-days': 50,
+    'realized-cap': 50,
+    'mixed-volume': 50,
+    'anonymity-set': 45,
+    'effective-ring-size': 40,
+    'ring-deductions': 40,
+    'ring-member-ages': 40,
+    'balance-addresses': 50,
+    'balance-value': 50,
+    'wealth-concentration': 40
   },
   y2: {
-    'decoy-bands': 50
+    'decoy-bands': 50,
+    'wealth-concentration': 40
   }
 }
 
@@ -217,7 +270,9 @@ function zipHvY (heights, ys, yMult, offset) {
 function zip2D (data, ys, yMult, offset) {
   yMult = yMult || 1
   if (data.axis === 'height') {
-    if (data.bin === 'block') return zipIvY(ys, yMult)
+    // Block-binned data is indexed by height, unless heights are provided as
+    // for the mempool snapshots.
+    if (data.bin === 'block' && !data.h) return zipIvY(ys, yMult)
     return zipHvY(data.h, ys, yMult, offset)
   }
   return zipTvY(data.t, ys, yMult)
@@ -284,6 +339,52 @@ function decoyBandsFunc (data) {
   }
 }
 
+// coinAgeBandsFunc converts the unspent value by age band of each point into
+// the share of the total unspent value, oldest band first.
+function coinAgeBandsFunc (data) {
+  let xs
+  if (data.axis === 'height') {
+    // Offset by one as zip2D does.
+    xs = (data.h || data.ageBands.map((_, i) => i)).map(h => h + 1)
+  } else {
+    xs = data.t.map(t => new Date(t * 1000))
+  }
+  return data.ageBands.map((bands, i) => {
+    const total = coinAgeBandsKeys.reduce((sum, key) => sum + bands[key], 0)
+    return [xs[i], ...coinAgeBandsKeys.map(key => total > 0 ? bands[key] / total * 100 : 0)]
+  })
+}
+
+// zip2DMulti zips several series on the x values of zip2D.
+function zip2DMulti (data, ...series) {
+  return zip2D(data, series[0]).map((point, i) => [point[0], ...series.map(ys => ys[i])])
+}
+
+// wealthBucketsFunc zips the addresses or value of each balance bucket of each
+// point, scaled by yMult.
+function wealthBucketsFunc (data, yMult) {
+  return zip2D(data, data.buckets.map(b => b[0])).map((point, i) => [point[0], ...data.buckets[i].map(v => v * yMult)])
+}
+
+// wealthBucketLabels labels the balance buckets from their minimum balances.
+function wealthBucketLabels (bounds, yMult, unit) {
+  return bounds.map((min, i) => {
+    const from = humanize.formatNumber(min * yMult, 3, true)
+    if (i === bounds.length - 1) return `${from}+ ${unit}`
+    return `${from}-${humanize.formatNumber(bounds[i + 1] * yMult, 3, true)} ${unit}`
+  })
+}
+
+// ringAgeBandsFunc converts the ring member counts by age band of each point
+// into the share of the members, youngest band first.
+function ringAgeBandsFunc (data) {
+  const shares = data.ringAges.map(bands => {
+    const total = bands.reduce((sum, n) => sum + n, 0)
+    return bands.map(n => total > 0 ? n / total * 100 : 0)
+  })
+  return zip2D(data, shares.map(bands => bands[0])).map((point, i) => [point[0], ...shares[i]])
+}
+
 function zipDecoyBandsTvY (times, ys, zs, yMult, zMult) {
   yMult = yMult || 1
   zMult = zMult || 1
@@ -1082,6 +1183,170 @@ export default class extends Controller {
           })
         }
         break
+      case 'avg-age-days':
+        d = zip2D(data, data.avgAge)
+        assign(gOptions, mapDygraphOptions(d, [xlabel, 'Average Age Days'], false,
+          'Average Age (days)', true, false))
+        break
+      case 'coin-days-destroyed':
+        d = zip2D(data, data.cdd)
+        assign(gOptions, mapDygraphOptions(d, [xlabel, 'Coin Days Destroyed'], false,
+          'Coin Days Destroyed', true, false))
+        break
+      case 'mean-coin-age':
+        d = zip2D(data, data.meanCoinAge)
+        assign(gOptions, mapDygraphOptions(d, [xlabel, 'Mean Coin Age'], false,
+          'Mean Coin Age (days)', true, false))
+        break
+      case 'total-coin-days':
+        d = zip2D(data, data.totalCoinDays)
+        assign(gOptions, mapDygraphOptions(d, [xlabel, 'Total Coin Days'], false,
+          'Total Coin Days', true, false))
+        break
+      case 'realized-cap':
+        d = zip2D(data, data.realizedCap)
+        assign(gOptions, mapDygraphOptions(d, [xlabel, 'Realized Cap'], false,
+          'Realized Cap (USD)', true, false))
+        yFormatter = customYFormatter(y => '$' + intComma(Math.round(y)))
+        break
+      case 'mixed-volume':
+        d = zip2D(data, data.mixed, unitToCoin(this.chainType))
+        assign(gOptions, mapDygraphOptions(d, [xlabel, 'Mixed Volume'], false,
+          'Mixed Volume (' + globalChainType.toUpperCase() + ')', true, false))
+        yFormatter = customYFormatter(y => {
+          if (y == null || isNaN(y)) return '–'
+          return y.toFixed(8) + ' ' + globalChainType.toUpperCase()
+        })
+        break
+      case 'anonymity-set':
+        d = zip2D(data, data.anonymitySet)
+        assign(gOptions, mapDygraphOptions(d, [xlabel, 'Anonymity Set'], false,
+          'CoinJoin Participants', true, false))
+        break
+      case 'effective-ring-size':
+        d = zip2DMulti(data, data.ringSize, data.effRingSize)
+        assign(gOptions, mapDygraphOptions(d, [xlabel, 'Ring Size', 'Effective Ring Size'], false,
+          'Members / Input', true, false))
+        yFormatter = customYFormatter(y => humanize.formatNumber(y, 2, true))
+        break
+      case 'ring-deductions':
+        d = zip2DMulti(data, data.deduced, data.eliminated)
+        assign(gOptions, mapDygraphOptions(d, [xlabel, 'Inputs Deduced', 'Members Eliminated'], false,
+          'Share (%)', true, false))
+        yFormatter = customYFormatter(y => humanize.formatNumber(y, 3, true) + ' %')
+        break
+      case 'ring-member-ages': {
+        d = ringAgeBandsFunc(data)
+        const expected = data.expected || []
+        labels.push(xlabel)
+        labels.push(...ringAgeBandsLabels)
+        ringAgeBandsColors.forEach((item) => {
+          stackVisibility.push(true)
+        })
+        gOptions = {
+          labels: labels,
+          file: d,
+          logscale: false,
+          colors: ringAgeBandsColors,
+          ylabel: 'Ring Members (%)',
+          y2label: null,
+          valueRange: [0, 100],
+          fillGraph: true,
+          stackedGraph: true,
+          visibility: stackVisibility,
+          legend: 'always',
+          includeZero: true,
+          axes: {}
+        }
+        yFormatter = (div, data, i) => {
+          if (!data.series || data.series.length === 0) return
+          data.series.forEach((serie, idx) => {
+            const exp = expected[idx] !== undefined ? ` (decoy model ${humanize.formatNumber(expected[idx], 1, true)} %)` : ''
+            addLegendEntryFmt(div, serie, y => (y > 0 ? humanize.formatNumber(y, 2, true) : '0') + ' %' + exp)
+          })
+        }
+        break
+      }
+      case 'balance-addresses':
+      case 'balance-value': {
+        const isValue = chartName === 'balance-value'
+        const unit = globalChainType.toUpperCase()
+        const bandLabels = wealthBucketLabels(data.bounds, unitToCoin(this.chainType), unit)
+        d = wealthBucketsFunc(data, isValue ? unitToCoin(this.chainType) : 1)
+        labels.push(xlabel)
+        labels.push(...bandLabels)
+        bandLabels.forEach(() => {
+          stackVisibility.push(true)
+        })
+        gOptions = {
+          labels: labels,
+          file: d,
+          logscale: false,
+          colors: wealthBucketsColors.slice(0, bandLabels.length),
+          ylabel: isValue ? `Value (${unit})` : 'Addresses',
+          y2label: null,
+          fillGraph: true,
+          stackedGraph: true,
+          visibility: stackVisibility,
+          legend: 'always',
+          includeZero: true,
+          axes: {}
+        }
+        yFormatter = (div, data, i) => {
+          if (!data.series || data.series.length === 0) return
+          data.series.forEach((serie) => {
+            addLegendEntryFmt(div, serie, isValue
+              ? y => (y > 0 ? humanize.formatNumber(y, 2, true) : '0') + ' ' + unit
+              : y => intComma(y) || '0')
+          })
+        }
+        break
+      }
+      case 'wealth-concentration':
+        d = zip2DMulti(data, data.gini, data.nakamoto)
+        assign(gOptions, mapDygraphOptions(d, [xlabel, 'Gini Coefficient', 'Nakamoto Coefficient'], false,
+          'Gini Coefficient', true, false))
+        gOptions.y2label = 'Nakamoto Coefficient'
+        gOptions.series = { 'Nakamoto Coefficient': { axis: 'y2' } }
+        gOptions.axes.y2 = {
+          axisLabelFormatter: (y) => Math.round(y),
+          axisLabelWidth: isMobile() ? yAxisLabelWidth.y2['wealth-concentration'] : yAxisLabelWidth.y2['wealth-concentration'] + 15
+        }
+        yFormatter = (div, data, i) => {
+          if (!data.series || data.series.length === 0) return
+          addLegendEntryFmt(div, data.series[0], y => humanize.formatNumber(y, 4, true))
+          addLegendEntryFmt(div, data.series[1], y => intComma(y) || '0')
+        }
+        break
+      case 'coin-age-bands':
+        d = coinAgeBandsFunc(data)
+        labels.push(xlabel)
+        labels.push(...coinAgeBandsLabels)
+        coinAgeBandsColors.forEach((item) => {
+          stackVisibility.push(true)
+        })
+        gOptions = {
+          labels: labels,
+          file: d,
+          logscale: false,
+          colors: coinAgeBandsColors,
+          ylabel: 'HODL Age Bands (%)',
+          y2label: null,
+          valueRange: [0, 100],
+          fillGraph: true,
+          stackedGraph: true,
+          visibility: stackVisibility,
+          legend: 'always',
+          includeZero: true,
+          axes: {}
+        }
+        yFormatter = (div, data, i) => {
+          if (!data.series || data.series.length === 0) return
+          data.series.forEach((serie) => {
+            addLegendEntryFmt(div, serie, y => (y > 0 ? humanize.formatNumber(y, 2, true) : '0') + ' %')
+          })
+        }
+        break
     }
     gOptions.axes.y = {
       axisLabelWidth: isMobile() ? yAxisLabelWidth.y1[chartName] : yAxisLabelWidth.y1[chartName] + 5
@@ -1118,7 +1383,7 @@ export default class extends Controller {
       this.settings.bin = 'day'
       this.setActiveOptionBtn(this.settings.bin, this.binSizeTargets)
       this.hideMultiTargets(this.binSelectorTargets)
-    } else if (this.chainType === 'xmr') {
+    } else {
       this.showMultiTargets(this.binSelectorTargets)
     }
 
@@ -1130,45 +1395,38 @@ export default class extends Controller {
         this.hideMultiTargets(this.binSelectorTargets)
         this.settings.bin = 'window'
       } else if (!isBinDisabled(selection)) {
-        if (this.chainType === 'xmr') {
         // this.binSelectorTarget.classList.remove('d-hide')
-          this.showMultiTargets(this.binSelectorTargets)
-          this.settings.bin = this.selectedBin()
-          const _this = this
-          // handler for option window only
-          this.binSizeTargets.forEach(el => {
-            if (_this.exitCond(el)) {
-              return
-            }
-            if (el.dataset.option !== 'window') return
-            if (usesHybridUnits(selection)) {
-              el.classList.remove('d-hide')
-            } else {
-              el.classList.add('d-hide')
-              if (this.settings.bin === 'window') {
-                this.settings.bin = 'day'
-                this.setActiveOptionBtn(this.settings.bin, this.binSizeTargets)
-              }
-            }
-          })
-          // if bin is blocks, hide option 'All' in zoom
-          if (this.settings.bin === 'block') {
-            // if zoom is all, change to year
-            const selectedZoom = this.selectedZoom()
-            if (!selectedZoom || selectedZoom === '' || selectedZoom === 'all') {
-              this.settings.zoom = ''
-              this.setActiveOptionBtn('year', this.zoomOptionTargets)
-            }
-            // hide 'All' option
-            this.hideOptionBtn('all', this.zoomOptionTargets)
+        this.showMultiTargets(this.binSelectorTargets)
+        this.settings.bin = this.selectedBin()
+        const _this = this
+        // handler for option window only
+        this.binSizeTargets.forEach(el => {
+          if (_this.exitCond(el)) {
+            return
+          }
+          if (el.dataset.option !== 'window') return
+          if (usesHybridUnits(selection)) {
+            el.classList.remove('d-hide')
           } else {
-            // else, show 'All' option in zoom
-            this.showOptionBtn('all', this.zoomOptionTargets)
+            el.classList.add('d-hide')
+            if (this.settings.bin === 'window') {
+              this.settings.bin = 'day'
+              this.setActiveOptionBtn(this.settings.bin, this.binSizeTargets)
+            }
+          }
+        })
+        // if bin is blocks, hide option 'All' in zoom
+        if (this.settings.bin === 'block') {
+          // if zoom is all, change to year
+          const selectedZoom = this.selectedZoom()
+          if (!selectedZoom || selectedZoom === '' || selectedZoom === 'all') {
+            this.settings.zoom = ''
+            this.setActiveOptionBtn('year', this.zoomOptionTargets)
           }
+          // hide 'All' option
+          this.hideOptionBtn('all', this.zoomOptionTargets)
         } else {
-          this.settings.bin = 'day'
-          this.setActiveOptionBtn(this.settings.bin, this.binSizeTargets)
-          this.hideMultiTargets(this.binSelectorTargets)
+          // else, show 'All' option in zoom
           this.showOptionBtn('all', this.zoomOptionTargets)
         }
       }
@@ -1239,6 +1497,34 @@ export default class extends Controller {
         return 'Average Transaction Size — mean transaction size'
       case 'decoy-bands':
         return 'Monero Decoys Bands — distribution of transactions by ring size (decoy / mixin bands), shown as percent of total (100% stacked).'
+      case 'effective-ring-size':
+        return 'Average ring size and effective ring size per input — members referenced before their creation or deduced spent elsewhere cannot be the real spend.'
+      case 'ring-deductions':
+        return 'Share of inputs whose real spend is deduced (zero-mixin or chain reaction), and share of ring members eliminated as decoys.'
+      case 'ring-member-ages':
+        return 'Age of ring members at the time of spending, as a share of all members (100% stacked), against the wallet decoy selection model shown in the legend.'
+      case 'avg-age-days':
+        return `Average age in days of the ${this.getChainName()} coins spent in each block or day.`
+      case 'coin-days-destroyed':
+        return `Coin days destroyed: the ${this.getChainName()} value spent times the days it was held unspent.`
+      case 'coin-age-bands':
+        return `HODL Age Bands — share of the unspent ${this.getChainName()} supply by the time since it last moved (100% stacked).`
+      case 'mean-coin-age':
+        return `Mean age in days of all unspent ${this.getChainName()} coins, weighted by value.`
+      case 'total-coin-days':
+        return `Total coin days accumulated by all unspent ${this.getChainName()} coins.`
+      case 'realized-cap':
+        return `Realized Cap — every unspent ${this.getChainName()} output valued at the daily close price when it was created.`
+      case 'mixed-volume':
+        return `Value of the equal-amount ${this.getChainName()} CoinJoin outputs (Wasabi, WabiSabi, Whirlpool, JoinMarket and generic) over time.`
+      case 'anonymity-set':
+        return `Number of equal-amount ${this.getChainName()} CoinJoin outputs over time — the participants a mixed coin hides among.`
+      case 'balance-addresses':
+        return `Number of ${this.getChainName()} addresses in each balance range, from the daily snapshots of the address balances.`
+      case 'balance-value':
+        return `Value held by the ${this.getChainName()} addresses in each balance range, from the daily snapshots of the address balances.`
+      case 'wealth-concentration':
+        return `Gini coefficient of the ${this.getChainName()} address balances, and the number of addresses holding more than half of the total balance.`
       default:
         return ''
     }
@@ -1282,6 +1568,34 @@ export default class extends Controller {
         return 'Average Tx Size'
       case 'decoy-bands':
         return 'Monero Decoys Bands'
+      case 'effective-ring-size':
+        return 'Effective Ring Size'
+      case 'ring-deductions':
+        return 'Ring Deductions'
+      case 'ring-member-ages':
+        return 'Ring Member Ages'
+      case 'avg-age-days':
+        return 'Average Age Days'
+      case 'coin-days-destroyed':
+        return 'Coin Days Destroyed'
+      case 'coin-age-bands':
+        return 'HODL Age Bands'
+      case 'mean-coin-age':
+        return 'Mean Coin Age'
+      case 'total-coin-days':
+        return 'Total Coin Days'
+      case 'realized-cap':
+        return 'Realized Cap'
+      case 'mixed-volume':
+        return 'Mixed Volume'
+      case 'anonymity-set':
+        return 'Anonymity Set'
+      case 'balance-addresses':
+        return 'Addresses by Balance'
+      case 'balance-value':
+        return 'Value by Balance'
+      case 'wealth-concentration':
+        return 'Wealth Concentration'
       default:
         return ''
     }
@@ -45,6 +45,19 @@ function setMenuDropdownPos (e) {
   }
 }
 
+// diffFormat formats a mining difficulty like the diffFormat template function.
+function diffFormat (d) {
+  const units = [[1e12, 'T'], [1e9, 'G'], [1e6, 'M'], [1e4, 'K']]
+  for (const [scale, unit] of units) {
+    if (d >= scale) return (d / scale).toFixed(2) + unit
+  }
+  return Math.round(d).toString()
+}
+
+// daTolerance is the difference, in percentage points, between the local and
+// external estimated difficulty change that is logged.
+const daTolerance = 1
+
 export default class extends Controller {
   static get targets () {
     return ['blockHeight', 'blockTotal', 'blockSize', 'blockTime',
@@ -56,7 +69,8 @@ export default class extends Controller {
       'nextRewardConverted', 'minedBlock', 'numTx24h', 'sent24h', 'fees24h', 'numVout24h',
       'feeAvg24h', 'blockReward', 'nextBlockReward', 'exchangeRateBottom', 'reward24h',
       'totalInputs', 'totalRingMembers', 'memInputCount', 'reward24hExchange',
-      'fees24hExchange', 'avgTxFee24h', 'blockTxCount']
+      'fees24hExchange', 'avgTxFee24h', 'blockTxCount', 'nextDifficulty', 'retargetBar',
+      'retargetProgress', 'vbytesPerSecond']
   }
 
   async connect () {
@@ -167,6 +181,9 @@ export default class extends Controller {
         globalEventBus.on('MEMPOOL_XMR_RECEIVED', this.processXmrMempool)
         break
     }
+    if (this.hasRetargetBarTarget && Number(this.retargetBarTarget.dataset.remainingBlocks) > 0) {
+      this.localDA = { difficultyChange: Number(this.retargetBarTarget.dataset.difficultyChange) }
+    }
     this.ws = null
     if (this.chainType !== 'xmr') {
       this.wsHostName = this.chainType === 'ltc' ? 'litecoinspace.org' : 'mempool.space'
@@ -282,6 +299,9 @@ export default class extends Controller {
     if (!extra) {
       return
     }
+    if (this.chainType !== 'xmr') {
+      this.setDifficultyAdjustment(extra)
+    }
     this.totalTransactionsTarget.textContent = humanize.commaWithDecimal(extra.total_transactions, 0)
     this.coinSupplyTarget.textContent = humanize.commaWithDecimal(extra.coin_value_supply, 2)
     if (this.exchangeRate > 0) {
@@ -314,6 +334,28 @@ export default class extends Controller {
     }
   }
 
+  // setDifficultyAdjustment displays the locally computed progress of the
+  // difficulty retarget epoch.
+  setDifficultyAdjustment (info) {
+    if (!info.remainingBlocks && !info.retargetProgress) return
+    this.localDA = info
+    const diffChange = info.difficultyChange
+    this.diffChangeTarget.innerHTML = humanize.decimalParts(diffChange, false, 2, 0)
+    this.diffChangeTarget.classList.toggle('c-green-2', diffChange > 0)
+    this.diffChangeTarget.classList.toggle('c-red', diffChange <= 0)
+    this.prevRetargetTarget.innerHTML = humanize.threeSigFigs(info.previousRetarget)
+    if (info.nextDifficulty > 0) {
+      this.nextDifficultyTarget.textContent = diffFormat(info.nextDifficulty)
+    }
+    this.remainingBlocksTarget.innerHTML = humanize.decimalParts(info.remainingBlocks, true, 0)
+    this.timeRemaningTarget.setAttribute('data-duration', info.timeRemaining)
+    this.blockTimeAvgTarget.setAttribute('data-duration', info.blockTimeAvg)
+    const progress = Number(info.retargetProgress || 0).toFixed(2)
+    this.retargetBarTarget.style.width = `${progress}%`
+    this.retargetBarTarget.setAttribute('aria-valuenow', progress)
+    this.retargetProgressTarget.textContent = progress
+  }
+
   _processXmrMempool (mempoolData) {
     if (this.hasTxCountTarget) {
       const mempool = mempoolData.xmr_mempool
@@ -398,22 +440,23 @@ export default class extends Controller {
         _this.totalSentExchangeTarget.innerHTML = humanize.threeSigFigs(convertedSent)
       }
       if (res.da) {
-        const diffChange = res.da.difficultyChange
-        const prevRetarget = res.da.previousRetarget
-        const remainingBlocks = res.da.remainingBlocks
-        const timeRemaining = res.da.remainingTime
-        _this.diffChangeTarget.innerHTML = humanize.decimalParts(diffChange, false, 2, 0)
-        if (diffChange > 0) {
-          _this.diffChangeTarget.classList.remove('c-red')
-          _this.diffChangeTarget.classList.add('c-green-2')
-        } else {
-          _this.diffChangeTarget.classList.add('c-red')
-          _this.diffChangeTarget.classList.remove('c-green-2')
+        // The difficulty adjustment is computed locally. The external data is
+        // only a cross-check, or a fallback before the first local estimate.
+        const local = _this.localDA
+        if (!local) {
+          _this.setDifficultyAdjustment({
+            difficultyChange: res.da.difficultyChange,
+            previousRetarget: res.da.previousRetarget,
+            remainingBlocks: res.da.remainingBlocks,
+            timeRemaining: res.da.remainingTime,
+            blockTimeAvg: res.da.timeAvg,
+            retargetProgress: res.da.progressPercent
+          })
+          _this.localDA = null
+        } else if (Math.abs(local.difficultyChange - res.da.difficultyChange) > daTolerance) {
+          console.warn(`${_this.chainType} difficulty adjustment differs from ${_this.wsHostName}: ` +
+            `local ${local.difficultyChange.toFixed(2)}%, external ${res.da.difficultyChange.toFixed(2)}%`)
         }
-        _this.prevRetargetTarget.innerHTML = humanize.threeSigFigs(prevRetarget)
-        _this.remainingBlocksTarget.innerHTML = humanize.decimalParts(remainingBlocks, true, 0)
-        _this.timeRemaningTarget.setAttribute('data-duration', timeRemaining)
-        // _this.blockTimeAvgTarget.setAttribute('data-duration', timeAvg)
       }
     })
   }
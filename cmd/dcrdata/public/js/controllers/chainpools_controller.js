@@ -0,0 +1,114 @@
+import { Controller } from '@hotwired/stimulus'
+import { getDefault } from '../helpers/module_helper'
+import humanize from '../helpers/humanize_helper'
+import { requestJSON } from '../helpers/http'
+
+// maxPools is the number of pools plotted. The remaining pools are plotted
+// together as "Other".
+const maxPools = 10
+
+const colors = ['#2970ff', '#2ed6a1', '#e2b027', '#f36e6e', '#8c6cf2',
+  '#41bfe0', '#0c644e', '#f2994a', '#c7417b', '#6e7f99', '#b8b8b8']
+
+function poolsLegendFormatter (data) {
+  if (data.x == null) return ''
+  let html = '<span style="color:#2d2d2d;">' + humanize.date(data.x, false, true) + '</span>'
+  data.series.forEach((series) => {
+    if (!series.y) return
+    html += `<br>${series.dashHTML}<span style="color: ${series.color};">${series.labelHTML}: ${series.y.toFixed(2)}%</span>`
+  })
+  return html
+}
+
+function poolShareData (d) {
+  const pools = d.pools.slice(0, maxPools)
+  const other = d.pools.slice(maxPools)
+  const labels = ['Date', ...pools.map((p) => p.name)]
+  if (other.length) labels.push('Other')
+  const data = d.time.map((t, i) => {
+    const row = [new Date(t * 1000), ...pools.map((p) => p.share[i] * 100)]
+    if (other.length) row.push(other.reduce((sum, p) => sum + p.share[i] * 100, 0))
+    return row
+  })
+  return { labels, data }
+}
+
+export default class extends Controller {
+  static get targets () {
+    return ['chart', 'bin', 'poolsTable', 'noData']
+  }
+
+  async connect () {
+    this.chainType = this.data.get('chainType')
+    this.bin = 'day'
+    this.Dygraph = await getDefault(
+      import(/* webpackChunkName: "dygraphs" */ '../vendor/dygraphs.min.js')
+    )
+    this.fetchChart()
+  }
+
+  disconnect () {
+    if (this.chart) this.chart.destroy()
+  }
+
+  setBin (e) {
+    const bin = e.target.dataset.option
+    if (!bin || bin === this.bin) return
+    this.bin = bin
+    this.binTargets.forEach((el) => {
+      el.classList.toggle('active', el.dataset.option === bin)
+    })
+    this.fetchChart()
+  }
+
+  async fetchChart () {
+    this.element.classList.add('loading')
+    let d
+    try {
+      d = await requestJSON(`/api/${this.chainType}/pools/share?bin=${this.bin}`)
+    } catch (err) {
+      console.error(err)
+    }
+    this.element.classList.remove('loading')
+    const empty = !d || !d.time || d.time.length === 0
+    this.noDataTarget.classList.toggle('d-none', !empty)
+    this.chartTarget.classList.toggle('d-none', empty)
+    if (empty) {
+      this.poolsTableTarget.innerHTML = ''
+      return
+    }
+    this.drawChart(d)
+    this.drawTable(d)
+  }
+
+  drawChart (d) {
+    const { labels, data } = poolShareData(d)
+    if (this.chart) this.chart.destroy()
+    this.chart = new this.Dygraph(this.chartTarget, data, {
+      labels: labels,
+      colors: colors,
+      ylabel: 'Share of Blocks (%)',
+      valueRange: [0, 100],
+      stackedGraph: true,
+      fillGraph: true,
+      showRangeSelector: true,
+      legend: 'follow',
+      legendFormatter: poolsLegendFormatter,
+      labelsSeparateLines: true,
+      labelsUTC: true
+    })
+  }
+
+  drawTable (d) {
+    const last = d.time.length - 1
+    this.poolsTableTarget.innerHTML = d.pools
+      .filter((p) => p.blocks[last] > 0)
+      .sort((a, b) => b.blocks[last] - a.blocks[last])
+      .map((p) => {
+        const name = p.link ? `<a href="${p.link}" target="_blank" rel="noopener noreferrer">${p.name}</a>` : p.name
+        return `<tr><td class="text-start">${name}</td>` +
+          `<td class="text-end">${p.blocks[last]}</td>` +
+          `<td class="text-end">${(p.share[last] * 100).toFixed(2)}%</td></tr>`
+      }).join('')
+  }
+}
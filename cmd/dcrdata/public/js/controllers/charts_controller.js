@@ -20,9 +20,9 @@ const atomsToDCR = 1e-8
 const windowScales = ['ticket-price', 'pow-difficulty', 'missed-votes']
 const rangeUse = ['hashrate', 'pow-difficulty']
 const hybridScales = ['privacy-participation']
-const lineScales = ['ticket-price', 'privacy-participation', 'avg-age-days', 'coin-days-destroyed', 'coin-age-bands', 'mean-coin-age', 'total-coin-days']
+const lineScales = ['ticket-price', 'privacy-participation', 'avg-age-days', 'coin-days-destroyed', 'coin-age-bands', 'mean-coin-age', 'total-coin-days', 'mix-denom-volume', 'remix-rounds', 'balance-addresses', 'balance-value', 'wealth-concentration']
 const modeScales = ['ticket-price']
-const binDisabled = ['coin-age-bands']
+const binDisabled = ['coin-age-bands', 'balance-addresses', 'balance-value', 'wealth-concentration']
 const multiYAxisChart = ['ticket-price', 'coin-supply', 'privacy-participation', 'avg-age-days', 'coin-days-destroyed', 'coin-age-bands', 'mean-coin-age', 'total-coin-days']
 const coinAgeCharts = ['avg-age-days', 'coin-days-destroyed', 'coin-age-bands', 'mean-coin-age', 'total-coin-days']
 const coinAgeBandsLabels = ['none', '>7Y', '5-7Y', '3-5Y', '2-3Y', '1-2Y', '6M-1Y', '1-6M', '1W-1M', '1D-1W', '<1D', 'Decred Price']
@@ -40,6 +40,19 @@ const coinAgeBandsColors = [
   '#576812ff',
   '#4c4c4cff'
 ]
+const mixBandsColors = [
+  '#2970ff',
+  '#2dd8a3',
+  '#ff9900',
+  '#dc3912',
+  '#990099',
+  '#0099c6',
+  '#dd4477',
+  '#66aa00',
+  '#b82e2e',
+  '#152b83'
+]
+const remixRoundsLabels = ['Round 1', 'Round 2', 'Round 3', 'Round 4+']
 // index 0 represents y1 and 1 represents y2 axes.
 const yValueRanges = { 'ticket-price': [1] }
 const chainworkUnits = ['exahash', 'zettahash', 'yottahash']
@@ -70,7 +83,15 @@ const yAxisLabelWidth = {
     'coin-days-destroyed': 50,
     'coin-age-bands': 40,
     'mean-coin-age': 50,
-    'total-coin-days': 50
+    'total-coin-days': 50,
+    'mix-denom-volume': 50,
+    'mix-participants': 40,
+    'mix-spend-time': 40,
+    'remix-rounds': 50,
+    'mixed-tickets': 40,
+    'balance-addresses': 50,
+    'balance-value': 50,
+    'wealth-concentration': 40
   },
   y2: {
     'ticket-price': 45,
@@ -78,7 +99,11 @@ const yAxisLabelWidth = {
     'coin-days-destroyed': 40,
     'coin-age-bands': 40,
     'mean-coin-age': 40,
-    'total-coin-days': 40
+    'total-coin-days': 40,
+    'mix-participants': 40,
+    'mix-spend-time': 40,
+    'mixed-tickets': 40,
+    'wealth-concentration': 40
   }
 }
 
@@ -587,6 +612,31 @@ function zip2D (data, ys, yMult, offset) {
   return zipTvY(data.t, ys, yMult)
 }
 
+// zip2DMulti zips several series on the x values of zip2D.
+function zip2DMulti (data, ...series) {
+  return zip2D(data, series[0]).map((point, i) => [point[0], ...series.map(ys => ys[i])])
+}
+
+// mixBandsFunc zips the mixed value of each band of each point, in DCR.
+function mixBandsFunc (data, bands) {
+  return zip2D(data, bands.map(b => b[0])).map((point, i) => [point[0], ...bands[i].map(v => v * atomsToDCR)])
+}
+
+// wealthBucketsFunc zips the addresses or value of each balance bucket of each
+// point, scaled by yMult.
+function wealthBucketsFunc (data, yMult) {
+  return zip2D(data, data.buckets.map(b => b[0])).map((point, i) => [point[0], ...data.buckets[i].map(v => v * yMult)])
+}
+
+// wealthBucketLabels labels the balance buckets from their minimum balances.
+function wealthBucketLabels (bounds, unit) {
+  return bounds.map((min, i) => {
+    const from = humanize.formatNumber(min * atomsToDCR, 3, true)
+    if (i === bounds.length - 1) return `${from}+ ${unit}`
+    return `${from}-${humanize.formatNumber(bounds[i + 1] * atomsToDCR, 3, true)} ${unit}`
+  })
+}
+
 function anonymitySetFunc (data) {
   let d
   let start = -1
@@ -1355,6 +1405,140 @@ export default class extends Controller {
         }
         break
 
+      case 'mix-denom-volume':
+      case 'remix-rounds': {
+        const bands = chartName === 'mix-denom-volume' ? data.mixed : data.rounds
+        const bandLabels = chartName === 'mix-denom-volume'
+          ? data.denoms.map(denom => (denom * atomsToDCR).toFixed(8) + ' DCR')
+          : remixRoundsLabels
+        d = mixBandsFunc(data, bands)
+        labels.push(xlabel)
+        labels.push(...bandLabels)
+        bandLabels.forEach(() => {
+          stackVisibility.push(true)
+        })
+        gOptions = {
+          labels: labels,
+          file: d,
+          logscale: false,
+          colors: mixBandsColors.slice(0, bandLabels.length),
+          ylabel: 'Mixed Volume (DCR)',
+          y2label: null,
+          fillGraph: true,
+          stackedGraph: true,
+          visibility: stackVisibility,
+          legend: 'always',
+          includeZero: true,
+          axes: {}
+        }
+        yFormatter = (div, data, i) => {
+          if (!data.series || data.series.length === 0) return
+          data.series.forEach((serie) => {
+            addLegendEntryFmt(div, serie, y => (y > 0 ? humanize.formatNumber(y, 2, true) : '0') + ' DCR')
+          })
+        }
+        break
+      }
+
+      case 'balance-addresses':
+      case 'balance-value': {
+        const isValue = chartName === 'balance-value'
+        const bandLabels = wealthBucketLabels(data.bounds, 'DCR')
+        d = wealthBucketsFunc(data, isValue ? atomsToDCR : 1)
+        labels.push(xlabel)
+        labels.push(...bandLabels)
+        bandLabels.forEach(() => {
+          stackVisibility.push(true)
+        })
+        gOptions = {
+          labels: labels,
+          file: d,
+          logscale: false,
+          colors: mixBandsColors.slice(0, bandLabels.length),
+          ylabel: isValue ? 'Value (DCR)' : 'Addresses',
+          y2label: null,
+          fillGraph: true,
+          stackedGraph: true,
+          visibility: stackVisibility,
+          legend: 'always',
+          includeZero: true,
+          axes: {}
+        }
+        yFormatter = (div, data, i) => {
+          if (!data.series || data.series.length === 0) return
+          data.series.forEach((serie) => {
+            addLegendEntryFmt(div, serie, isValue
+              ? y => (y > 0 ? humanize.formatNumber(y, 2, true) : '0') + ' DCR'
+              : y => intComma(y) || '0')
+          })
+        }
+        break
+      }
+
+      case 'wealth-concentration':
+        d = zip2DMulti(data, data.gini, data.nakamoto)
+        assign(gOptions, mapDygraphOptions(d, [xlabel, 'Gini Coefficient', 'Nakamoto Coefficient'], false,
+          'Gini Coefficient', true, false))
+        gOptions.y2label = 'Nakamoto Coefficient'
+        gOptions.series = { 'Nakamoto Coefficient': { axis: 'y2' } }
+        gOptions.axes.y2 = {
+          axisLabelFormatter: (y) => Math.round(y),
+          axisLabelWidth: isMobile() ? yAxisLabelWidth.y2['wealth-concentration'] : yAxisLabelWidth.y2['wealth-concentration'] + 15
+        }
+        yFormatter = (div, data, i) => {
+          if (!data.series || data.series.length === 0) return
+          addLegendEntryFmt(div, data.series[0], y => humanize.formatNumber(y, 4, true))
+          addLegendEntryFmt(div, data.series[1], y => intComma(y) || '0')
+        }
+        break
+
+      case 'mix-participants':
+        d = zip2DMulti(data, data.participants, data.count)
+        assign(gOptions, mapDygraphOptions(d, [xlabel, 'Participants per Mix', 'Mixes'], false,
+          'Participants per Mix', true, false))
+        gOptions.y2label = 'Mixes'
+        gOptions.series = { Mixes: { axis: 'y2' } }
+        gOptions.axes.y2 = {
+          axisLabelFormatter: (y) => Math.round(y),
+          axisLabelWidth: isMobile() ? yAxisLabelWidth.y2['mix-participants'] : yAxisLabelWidth.y2['mix-participants'] + 15
+        }
+        yFormatter = customYFormatter(y => humanize.formatNumber(y, 2, true))
+        break
+
+      case 'mix-spend-time':
+        d = zip2DMulti(data, data.days, data.count)
+        assign(gOptions, mapDygraphOptions(d, [xlabel, 'Time to Spend', 'Outputs Spent'], false,
+          'Time to Spend (days)', true, false))
+        gOptions.y2label = 'Outputs Spent'
+        gOptions.series = { 'Outputs Spent': { axis: 'y2' } }
+        gOptions.axes.y2 = {
+          axisLabelFormatter: (y) => Math.round(y),
+          axisLabelWidth: isMobile() ? yAxisLabelWidth.y2['mix-spend-time'] : yAxisLabelWidth.y2['mix-spend-time'] + 15
+        }
+        yFormatter = (div, data, i) => {
+          if (!data.series || data.series.length === 0) return
+          addLegendEntryFmt(div, data.series[0], y => humanize.formatNumber(y, 2, true) + ' days')
+          addLegendEntryFmt(div, data.series[1], y => intComma(y) || '0')
+        }
+        break
+
+      case 'mixed-tickets':
+        d = zip2DMulti(data, data.share, data.count)
+        assign(gOptions, mapDygraphOptions(d, [xlabel, 'Mixed Share', 'Mixed Tickets'], false,
+          'Mixed Share (%)', true, false))
+        gOptions.y2label = 'Mixed Tickets'
+        gOptions.series = { 'Mixed Tickets': { axis: 'y2' } }
+        gOptions.axes.y2 = {
+          axisLabelFormatter: (y) => Math.round(y),
+          axisLabelWidth: isMobile() ? yAxisLabelWidth.y2['mixed-tickets'] : yAxisLabelWidth.y2['mixed-tickets'] + 15
+        }
+        yFormatter = (div, data, i) => {
+          if (!data.series || data.series.length === 0) return
+          addLegendEntryFmt(div, data.series[0], y => humanize.formatNumber(y, 2, true) + ' %')
+          addLegendEntryFmt(div, data.series[1], y => intComma(y) || '0')
+        }
+        break
+
       case 'hashrate': // Total chainwork over time
         d = isHeightBlock ? zipHvY(data.h, data.rate, 1e-3, data.offset) : zip2D(data, data.rate, 1e-3, data.offset)
         assign(gOptions, mapDygraphOptions(d, [xlabel, 'Network Hashrate'],
@@ -1567,6 +1751,22 @@ export default class extends Controller {
         return 'Shows the average age of all Decred coins in circulation, indicating how long coins have been held without moving.'
       case 'total-coin-days':
         return 'Represents the cumulative total of all coin-days in the Decred network, measuring how long coins have remained unmoved.'
+      case 'mix-denom-volume':
+        return 'Shows the value mixed in each of the Decred mix denominations over time.'
+      case 'mix-participants':
+        return 'Shows the number of mixes and their average number of participants, estimated from the change outputs of each mix.'
+      case 'mix-spend-time':
+        return 'Shows the average time between a mix and the spending of its mixed outputs, and the number of mixed outputs spent.'
+      case 'remix-rounds':
+        return 'Shows the mixed value by re-mix round. A mix that spends the outputs of other mixes is one round after the highest round it spends.'
+      case 'mixed-tickets':
+        return 'Shows the share of the tickets bought from mixed split transactions, and their number.'
+      case 'balance-addresses':
+        return 'Shows the number of Decred addresses in each balance range, from the daily snapshots of the address balances.'
+      case 'balance-value':
+        return 'Shows the value held by the Decred addresses in each balance range, from the daily snapshots of the address balances.'
+      case 'wealth-concentration':
+        return 'Shows the Gini coefficient of the Decred address balances, and the number of addresses holding more than half of the total balance.'
       default:
         return ''
     }
@@ -1614,6 +1814,22 @@ export default class extends Controller {
         return 'Mean Coin Age'
       case 'total-coin-days':
         return 'Total Coin Days'
+      case 'mix-denom-volume':
+        return 'Mixed Volume by Denomination'
+      case 'mix-participants':
+        return 'Mix Participants'
+      case 'mix-spend-time':
+        return 'Mixed Output Time to Spend'
+      case 'remix-rounds':
+        return 'Re-mix Rounds'
+      case 'mixed-tickets':
+        return 'Mixed Tickets'
+      case 'balance-addresses':
+        return 'Addresses by Balance'
+      case 'balance-value':
+        return 'Value by Balance'
+      case 'wealth-concentration':
+        return 'Wealth Concentration'
       default:
         return ''
     }
@@ -14,12 +14,18 @@ export default class extends Controller {
   }
 
   connect () {
-    ws.registerEvtHandler('decodetxResp', (evt) => {
+    // BTC and LTC pages set a chain type and use the multichain events, which
+    // also accept PSBTs.
+    this.chainType = this.data.get('chainType')
+    const prefix = this.chainType ? 'multichaintx' : 'tx'
+    this.decodeEvt = 'decode' + prefix + 'Resp'
+    this.sendEvt = 'send' + prefix + 'Resp'
+    ws.registerEvtHandler(this.decodeEvt, (evt) => {
       this.decodeHeaderTarget.textContent = 'Decoded tx'
       fadeIn(this.decodedTransactionTarget)
       this.decodedTransactionTarget.textContent = evt
     })
-    ws.registerEvtHandler('sendtxResp', (evt) => {
+    ws.registerEvtHandler(this.sendEvt, (evt) => {
       this.decodeHeaderTarget.textContent = 'Sent tx'
       fadeIn(this.decodedTransactionTarget)
       this.decodedTransactionTarget.textContent = evt
@@ -27,8 +33,8 @@ export default class extends Controller {
   }
 
   disconnect () {
-    ws.deregisterEvtHandlers('decodetxResp')
-    ws.deregisterEvtHandlers('sendtxResp')
+    ws.deregisterEvtHandlers(this.decodeEvt)
+    ws.deregisterEvtHandlers(this.sendEvt)
   }
 
   send (e) {
@@ -36,7 +42,11 @@ export default class extends Controller {
       return
     }
     if (this.rawTransactionTarget.value !== '') {
-      ws.send(e.target.dataset.eventId, this.rawTransactionTarget.value)
+      let msg = this.rawTransactionTarget.value
+      if (this.chainType) {
+        msg = JSON.stringify({ chain: this.chainType, rawtx: msg })
+      }
+      ws.send(e.target.dataset.eventId, msg)
       this.rawTransactionTarget.textContent = ''
       this.decodedTransactionTarget.textContent = ''
     }
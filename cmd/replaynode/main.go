@@ -0,0 +1,103 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+// replaynode serves a recorded chain to dcrdata in place of dcrd, bitcoind,
+// litecoind or monerod, or records one by proxying a live node. See the
+// testutil/replaynode package for the format of the replay directory.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/decred/dcrdata/v8/testutil/replaynode"
+	"github.com/decred/slog"
+)
+
+var dir = flag.String("dir", "", "replay directory")
+var listen = flag.String("listen", "127.0.0.1:19109", "RPC listen address")
+var user = flag.String("user", "", "RPC username required of clients")
+var pass = flag.String("pass", "", "RPC password required of clients")
+var tlsCert = flag.String("tlscert", "", "TLS certificate to serve RPC over TLS")
+var tlsKey = flag.String("tlskey", "", "TLS key to serve RPC over TLS")
+var interval = flag.Duration("interval", -1, "time between blocks, overriding the manifest (0 to advance only with POST /replay/advance)")
+var record = flag.String("record", "", "URL of a live node to proxy and record to -dir instead of replaying")
+var chain = flag.String("chain", "dcr", "chain of the recorded node {dcr, btc, ltc, xmr}")
+var upstreamUser = flag.String("upstreamuser", "", "RPC username of the recorded node")
+var upstreamPass = flag.String("upstreampass", "", "RPC password of the recorded node")
+var upstreamCert = flag.String("upstreamcert", "", "TLS certificate of the recorded node")
+var debugLevel = flag.String("debuglevel", "info", "logging level {trace, debug, info, warn, error, critical}")
+
+func mainCore() error {
+	flag.Parse()
+	if *dir == "" {
+		return errors.New("-dir is required")
+	}
+	logger := slog.NewBackend(os.Stdout).Logger("RPLY")
+	level, ok := slog.LevelFromString(*debugLevel)
+	if !ok {
+		return fmt.Errorf("invalid -debuglevel %q", *debugLevel)
+	}
+	logger.SetLevel(level)
+	replaynode.UseLogger(logger)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	var handler http.Handler
+	if *record != "" {
+		rec, err := replaynode.NewRecorder(*dir, *chain, *record, *upstreamUser, *upstreamPass, *upstreamCert)
+		if err != nil {
+			return err
+		}
+		defer rec.Close()
+		handler = rec.Handler(*user, *pass)
+		logger.Infof("Recording %s calls to %s in %s", *chain, *record, *dir)
+	} else {
+		node, err := replaynode.Load(*dir)
+		if err != nil {
+			return err
+		}
+		if *interval >= 0 {
+			node.SetInterval(*interval)
+		}
+		tip, hash := node.Tip()
+		logger.Infof("Replaying %s from %d / %s", node.Chain(), tip, hash)
+		go node.Run(ctx)
+		handler = node.Handler(*user, *pass)
+	}
+
+	srv := &http.Server{
+		Addr:              *listen,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		srv.Shutdown(context.Background())
+	}()
+	logger.Infof("Listening on %s", *listen)
+	var err error
+	if *tlsCert != "" {
+		err = srv.ListenAndServeTLS(*tlsCert, *tlsKey)
+	} else {
+		err = srv.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+func main() {
+	if err := mainCore(); err != nil {
+		fmt.Fprintf(os.Stderr, "replaynode: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -22,6 +22,7 @@ var user = flag.String("user", "bitcoin", "node RPC username")
 var pass = flag.String("pass", "bananas", "node RPC password")
 var start = flag.Uint64("start", 590_000, "Start block height")
 var end = flag.Uint64("end", 1<<32, "End block height")
+var network = flag.String("net", "mainnet", "Network of the node: mainnet, testnet3, signet or regtest")
 
 func mainCore() int {
 	//defer profile.Start(profile.CPUProfile).Stop()
@@ -31,6 +32,21 @@ func mainCore() int {
 	}()
 	flag.Parse()
 
+	var params *chaincfg.Params
+	switch *network {
+	case "mainnet":
+		params = &chaincfg.MainNetParams
+	case "testnet3":
+		params = &chaincfg.TestNet3Params
+	case "signet":
+		params = &chaincfg.SigNetParams
+	case "regtest":
+		params = &chaincfg.RegressionNetParams
+	default:
+		fmt.Fprintf(os.Stderr, "ERROR: unknown network %q\n", *network)
+		return 1
+	}
+
 	csvfile, err := os.Create("swapscan.csv")
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error creating utxos file: %v\n", err)
@@ -76,8 +92,6 @@ func mainCore() int {
 		height = int32(*end)
 	}
 
-	params := &chaincfg.MainNetParams
-
 	err = csvwriter.Write([]string{"height", "type", "spend_tx", "spend_vin", "BTC",
 		"contract_tx", "contract_vout", "secret"})
 	if err != nil {
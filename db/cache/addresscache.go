@@ -603,6 +603,17 @@ func (d *AddressCacheItem) UTXOs() ([]*dbtypes.AddressTxnOutput, *BlockID) {
 	return d.utxos, d.blockID()
 }
 
+// MutilchainUTXOs is a thread-safe accessor for the
+// []*dbtypes.MutilchainAddressTxnOutput.
+func (d *MutilchainAddressCacheItem) MutilchainUTXOs() ([]*dbtypes.MutilchainAddressTxnOutput, *MutilchainBlockID) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	if d.utxos == nil {
+		return nil, nil
+	}
+	return d.utxos, d.mutilchainBlockID()
+}
+
 // HistoryChart is a thread-safe accessor for the TxHistory.
 func (d *AddressCacheItem) HistoryChart(addrChart dbtypes.HistoryChart, chartGrouping dbtypes.TimeBasedGrouping) (*dbtypes.ChartsData, *BlockID) {
 	d.mtx.RLock()
@@ -1116,6 +1127,20 @@ func (ac *AddressCache) UTXOs(addr string) ([]*dbtypes.AddressTxnOutput, *BlockI
 	return aci.UTXOs()
 }
 
+// MutilchainUTXOs attempts to retrieve an []*MutilchainAddressTxnOutput for
+// the given BTC or LTC address. The MutilchainBlockID for the block at which
+// the cached data is valid is also returned. In the event of a cache miss, the
+// slice and the *MutilchainBlockID will be nil.
+func (ac *AddressCache) MutilchainUTXOs(addr string, chainType string) ([]*dbtypes.MutilchainAddressTxnOutput, *MutilchainBlockID) {
+	aci := ac.MutilchainAddressCacheItem(addr, chainType)
+	if aci == nil {
+		ac.cacheMetrics.utxoMiss()
+		return nil, nil
+	}
+	ac.cacheMetrics.utxoHit()
+	return aci.MutilchainUTXOs()
+}
+
 // HistoryChart attempts to retrieve ChartsData for the given address, chart
 // type, and grouping interval. The BlockID for the block at which the cached
 // data is valid is also returned. In the event of a cache miss, both returned
@@ -1776,6 +1801,43 @@ func (ac *AddressCache) StoreUTXOs(addr string, utxos []*dbtypes.AddressTxnOutpu
 	return true
 }
 
+// StoreMutilchainUTXOs stores the *MutilchainAddressTxnOutput slice for the
+// given BTC or LTC address in cache. The current best block data is required
+// to determine cache freshness.
+func (ac *AddressCache) StoreMutilchainUTXOs(addr string, utxos []*dbtypes.MutilchainAddressTxnOutput, block *MutilchainBlockID, chainType string) bool {
+	if block == nil || ac.GetMutilchainCap(chainType) < 1 || ac.GetMutilchainAddrCap(chainType) < 1 {
+		return false
+	}
+
+	// Only allow storing maxUTXOsPerAddr.
+	if len(utxos) > ac.maxUTXOsPerAddr {
+		return false
+	}
+
+	ac.mtx.Lock()
+	defer ac.mtx.Unlock()
+	aci := ac.GetMutilchainAddresCacheItemMap(chainType)[addr]
+
+	if utxos == nil {
+		utxos = []*dbtypes.MutilchainAddressTxnOutput{}
+	}
+
+	// Don't evict existing balance/rows cache on account of block mismatch.
+	if aci == nil {
+		return ac.addMutilchainCacheItem(addr, &MutilchainAddressCacheItem{
+			utxos:  utxos,
+			height: block.Height,
+			hash:   block.Hash,
+		}, chainType)
+	}
+
+	// cache is current, so just set the utxos.
+	aci.mtx.Lock()
+	aci.utxos = utxos
+	aci.mtx.Unlock()
+	return true
+}
+
 // ClearUTXOs clears any stored UTXOs for the given address in cache.
 func (ac *AddressCache) ClearUTXOs(addr string) {
 	ac.mtx.Lock()
@@ -48,11 +48,25 @@ const (
 	CoinAgeBands      = "coin-age-bands"
 	MeanCoinAge       = "mean-coin-age"
 	TotalCoinDays     = "total-coin-days"
+	RealizedCap       = "realized-cap"
 	TotalRingSize     = "total-ring-size"
 	AvgRingSize       = "avg-ring-size"
 	FeeRate           = "fee-rate"
 	AvgTxSize         = "avg-tx-size"
 	DecoyBands        = "decoy-bands"
+	EffectiveRingSize = "effective-ring-size"
+	RingDeductions    = "ring-deductions"
+	RingMemberAges    = "ring-member-ages"
+	MixedVolume       = "mixed-volume"
+	MixAnonymitySet   = "anonymity-set"
+	MixDenomVolume    = "mix-denom-volume"
+	MixParticipants   = "mix-participants"
+	MixSpendTime      = "mix-spend-time"
+	RemixRounds       = "remix-rounds"
+	MixedTickets      = "mixed-tickets"
+	BalanceAddresses  = "balance-addresses"
+	BalanceValue      = "balance-value"
+	Concentration     = "wealth-concentration"
 
 	// Some chartResponse keys
 	heightKey        = "h"
@@ -79,9 +93,25 @@ const (
 	ageBandKey       = "ageBands"
 	meanCoinAgeKey   = "meanCoinAge"
 	totalCoinDaysKey = "totalCoinDays"
+	realizedCapKey   = "realizedCap"
 	marketPriceKey   = "marketPrice"
 	ringSizeKey      = "ringSize"
 	xmrDecoyKey      = "decoy"
+	effRingSizeKey   = "effRingSize"
+	deducedKey       = "deduced"
+	eliminatedKey    = "eliminated"
+	ringAgesKey      = "ringAges"
+	expectedKey      = "expected"
+	mixedKey         = "mixed"
+	denomsKey        = "denoms"
+	participantsKey  = "participants"
+	daysKey          = "days"
+	roundsKey        = "rounds"
+	shareKey         = "share"
+	bucketsKey       = "buckets"
+	boundsKey        = "bounds"
+	giniKey          = "gini"
+	nakamotoKey      = "nakamoto"
 )
 
 // binLevel specifies the granularity of data.
@@ -320,6 +350,116 @@ func (data MoneroDecoyBands) Avg(s, e int) *dbtypes.MoneroDecoyData {
 	return &avgBand
 }
 
+// ChartRingAgeBands is a slice of the Monero ring member counts in each
+// xmrring age band. It satisfies the lengther interface.
+type ChartRingAgeBands [][]int64
+
+func newChartRingAgeBands(size int) ChartRingAgeBands {
+	return make([][]int64, 0, size)
+}
+
+// Length returns the length of data. Satisfies the lengther interface.
+func (data ChartRingAgeBands) Length() int {
+	return len(data)
+}
+
+// Truncate makes a subset of the underlying dataset. It satisfies the lengther
+// interface.
+func (data ChartRingAgeBands) Truncate(l int) lengther {
+	return data[:l]
+}
+
+// If the data is longer than max, return a subset of length max.
+func (data ChartRingAgeBands) snip(max int) ChartRingAgeBands {
+	if len(data) < max {
+		max = len(data)
+	}
+	return data[:max]
+}
+
+// Sum is the accumulation of a segment of the dataset.
+func (data ChartRingAgeBands) Sum(s, e int) []int64 {
+	var sum []int64
+	for _, bands := range data[s:max(s, e)] {
+		if sum == nil {
+			sum = make([]int64, len(bands))
+		}
+		for i := 0; i < len(bands) && i < len(sum); i++ {
+			sum[i] += bands[i]
+		}
+	}
+	return sum
+}
+
+// ChartWealthBuckets is a slice of the number of addresses or the value in
+// each balance bucket of a wealth snapshot. It satisfies the lengther
+// interface.
+type ChartWealthBuckets [][]int64
+
+func newChartWealthBuckets(size int) ChartWealthBuckets {
+	return make([][]int64, 0, size)
+}
+
+// Length returns the length of data. Satisfies the lengther interface.
+func (data ChartWealthBuckets) Length() int {
+	return len(data)
+}
+
+// Truncate makes a subset of the underlying dataset. It satisfies the lengther
+// interface.
+func (data ChartWealthBuckets) Truncate(l int) lengther {
+	return data[:l]
+}
+
+// If the data is longer than max, return a subset of length max.
+func (data ChartWealthBuckets) snip(max int) ChartWealthBuckets {
+	if len(data) < max {
+		max = len(data)
+	}
+	return data[:max]
+}
+
+// ChartMixBands is a slice of the Decred mixed value of each mix denomination
+// or re-mix round, by block or day. It satisfies the lengther interface.
+type ChartMixBands [][]int64
+
+func newChartMixBands(size int) ChartMixBands {
+	return make([][]int64, 0, size)
+}
+
+// Length returns the length of data. Satisfies the lengther interface.
+func (data ChartMixBands) Length() int {
+	return len(data)
+}
+
+// Truncate makes a subset of the underlying dataset. It satisfies the lengther
+// interface.
+func (data ChartMixBands) Truncate(l int) lengther {
+	return data[:l]
+}
+
+// If the data is longer than max, return a subset of length max.
+func (data ChartMixBands) snip(max int) ChartMixBands {
+	if len(data) < max {
+		max = len(data)
+	}
+	return data[:max]
+}
+
+// Sum is the accumulation of a segment of the dataset.
+func (data ChartMixBands) Sum(s, e int) []int64 {
+	var sum []int64
+	for _, bands := range data[s:max(s, e)] {
+		if sum == nil {
+			sum = make([]int64, len(bands))
+		}
+		for i := 0; i < len(bands) && i < len(sum); i++ {
+			sum[i] += bands[i]
+		}
+	}
+	return sum
+}
+
 // A constructor for a sized ChartFloats.
 func newChartFloats(size int) ChartFloats {
 	return make([]float64, 0, size)
@@ -397,6 +537,9 @@ type ZoomSet struct {
 	APIAddressCount   ChartUints
 	APIMempoolTxNum   ChartUints
 	APIMempoolSize    ChartUints
+	NewAddresses      ChartUints
+	MempoolTxCount    ChartUints
+	MempoolSize       ChartUints
 	NewAtoms          ChartUints
 	Chainwork         ChartUints
 	Fees              ChartUints
@@ -412,10 +555,37 @@ type ZoomSet struct {
 	MoneroDecoyBands  MoneroDecoyBands
 	MeanCoinAge       ChartFloats
 	TotalCoinDays     ChartFloats // Sum Coin Age
+	RealizedCap       ChartFloats
 	TotalRingSize     ChartUints
 	AverageRingSize   ChartUints
 	FeeRate           ChartUints
 	AverageTxSize     ChartUints
+	// Monero ring analysis totals. The sums are kept so that averages and
+	// shares can be taken over any bin.
+	RingInputs           ChartUints
+	RingSizeSum          ChartUints
+	EffectiveRingSizeSum ChartUints
+	DeducedInputs        ChartUints
+	RingMemberAges       ChartRingAgeBands
+	// Decred mixing totals. Participants are summed over the mixes and the
+	// spend seconds over the spent mix outputs, so that averages can be taken
+	// over any bin. The denomination bands follow txhelpers.MixDenoms and the
+	// round bands are rounds 1, 2, 3 and 4 or more.
+	Mixes           ChartUints
+	MixParticipants ChartUints
+	MixSpent        ChartUints
+	MixSpendSecs    ChartUints
+	TicketsBought   ChartUints
+	MixedTickets    ChartUints
+	MixDenoms       ChartMixBands
+	MixRounds       ChartMixBands
+	// Wealth distribution snapshots of the address balances. The buckets
+	// follow dbtypes.WealthBucketMins.
+	WealthAddresses ChartUints
+	Gini            ChartFloats
+	Nakamoto        ChartUints
+	BucketAddresses ChartWealthBuckets
+	BucketValue     ChartWealthBuckets
 }
 
 // Snip truncates the zoomSet to a provided length.
@@ -429,9 +599,15 @@ func (set *ZoomSet) Snip(length int) {
 	set.PoolValue = set.PoolValue.snip(length)
 	set.BlockSize = set.BlockSize.snip(length)
 	set.TxCount = set.TxCount.snip(length)
+	set.TxPerBlock = set.TxPerBlock.snip(length)
+	set.NewAddresses = set.NewAddresses.snip(length)
+	set.MempoolTxCount = set.MempoolTxCount.snip(length)
+	set.MempoolSize = set.MempoolSize.snip(length)
 	set.NewAtoms = set.NewAtoms.snip(length)
 	set.Chainwork = set.Chainwork.snip(length)
 	set.Difficulty = set.Difficulty.snip(length)
+	set.Hashrate = set.Hashrate.snip(length)
+	set.Reward = set.Reward.snip(length)
 	set.Fees = set.Fees.snip(length)
 	set.TotalMixed = set.TotalMixed.snip(length)
 	set.AnonymitySet = set.AnonymitySet.snip(length)
@@ -440,7 +616,26 @@ func (set *ZoomSet) Snip(length int) {
 	set.CoinAgeBands = set.CoinAgeBands.snip(length)
 	set.MeanCoinAge = set.MeanCoinAge.snip(length)
 	set.TotalCoinDays = set.TotalCoinDays.snip(length)
+	set.RealizedCap = set.RealizedCap.snip(length)
 	set.MarketPrice = set.MarketPrice.snip(length)
+	set.RingInputs = set.RingInputs.snip(length)
+	set.RingSizeSum = set.RingSizeSum.snip(length)
+	set.EffectiveRingSizeSum = set.EffectiveRingSizeSum.snip(length)
+	set.DeducedInputs = set.DeducedInputs.snip(length)
+	set.RingMemberAges = set.RingMemberAges.snip(length)
+	set.Mixes = set.Mixes.snip(length)
+	set.MixParticipants = set.MixParticipants.snip(length)
+	set.MixSpent = set.MixSpent.snip(length)
+	set.MixSpendSecs = set.MixSpendSecs.snip(length)
+	set.TicketsBought = set.TicketsBought.snip(length)
+	set.MixedTickets = set.MixedTickets.snip(length)
+	set.MixDenoms = set.MixDenoms.snip(length)
+	set.MixRounds = set.MixRounds.snip(length)
+	set.WealthAddresses = set.WealthAddresses.snip(length)
+	set.Gini = set.Gini.snip(length)
+	set.Nakamoto = set.Nakamoto.snip(length)
+	set.BucketAddresses = set.BucketAddresses.snip(length)
+	set.BucketValue = set.BucketValue.snip(length)
 }
 
 // Constructor for a sized zoomSet for blocks, which has has no Height slice
@@ -548,6 +743,7 @@ type ChartGobject struct {
 	AverageTxSize     ChartUints
 	MoneroDecoyBands  MoneroDecoyBands
 	TxPerBlock        ChartUints
+	NewAddresses      ChartUints
 }
 
 // The chart data is cached with the current cacheID of the zoomSet or windowSet.
@@ -597,10 +793,15 @@ type ChartData struct {
 	Blocks       *ZoomSet
 	Windows      *windowSet
 	Days         *ZoomSet
-	cacheMtx     sync.RWMutex
-	cache        map[string]*cachedChart
-	updateMtx    sync.Mutex
-	updaters     []ChartUpdater
+	// Mixing is the Decred mixing data by block, which is computed after the
+	// blocks are stored and may lag the Blocks data.
+	Mixing *ZoomSet
+	// Wealth is the daily wealth distribution of the address balances.
+	Wealth    *ZoomSet
+	cacheMtx  sync.RWMutex
+	cache     map[string]*cachedChart
+	updateMtx sync.Mutex
+	updaters  []ChartUpdater
 }
 
 // ValidateLengths checks that the length of all arguments is equal.
@@ -784,6 +985,9 @@ func (charts *ChartData) ReorgHandler(reorg *txhelpers.ReorgData) error {
 	windowsLen--
 	log.Debugf("ChartData.ReorgHandler snipping windows to height to %d", windowsLen)
 	charts.Windows.Snip(windowsLen)
+	if charts.Mixing != nil {
+		charts.Mixing.Snip(newHeight)
+	}
 	charts.mtx.Unlock()
 	return nil
 }
@@ -1005,6 +1209,24 @@ func (charts *ChartData) CoinAgeTip() int32 {
 	return int32(len(charts.Blocks.AvgCoinAge)) - 1
 }
 
+// MixingTip is the height of the last block in the Mixing data, or -1 if there
+// is none.
+func (charts *ChartData) MixingTip() int64 {
+	charts.mtx.RLock()
+	defer charts.mtx.RUnlock()
+	if charts.Mixing == nil || len(charts.Mixing.Height) == 0 {
+		return -1
+	}
+	return int64(charts.Mixing.Height[len(charts.Mixing.Height)-1])
+}
+
+// WealthTip is the height of the last wealth snapshot, or -1 if there is none.
+func (charts *ChartData) WealthTip() int64 {
+	charts.mtx.RLock()
+	defer charts.mtx.RUnlock()
+	return wealthTip(charts.Wealth)
+}
+
 // CoinAgeBandsTip is the height of the CoinAgeBands data
 func (charts *ChartData) CoinAgeBandsTip() int32 {
 	charts.mtx.RLock()
@@ -1158,6 +1380,8 @@ func NewChartData(ctx context.Context, height uint32, chainParams *chaincfg.Para
 		Blocks:       newBlockSet(size),
 		Windows:      newWindowSet(windows),
 		Days:         newDaySet(days),
+		Mixing:       newMixingSet(),
+		Wealth:       newWealthSet(),
 		cache:        make(map[string]*cachedChart),
 		updaters:     make([]ChartUpdater, 0),
 	}
@@ -1267,6 +1491,14 @@ var chartMakers = map[string]ChartMaker{
 	CoinAgeBands:      coinAgeBands,
 	MeanCoinAge:       meanCoinAge,
 	TotalCoinDays:     totalCoinDays,
+	MixDenomVolume:    mixDenomVolumeChart,
+	MixParticipants:   mixParticipantsChart,
+	MixSpendTime:      mixSpendTimeChart,
+	RemixRounds:       remixRoundsChart,
+	MixedTickets:      mixedTicketsChart,
+	BalanceAddresses:  balanceAddressesChart,
+	BalanceValue:      balanceValueChart,
+	Concentration:     wealthConcentrationChart,
 }
 
 var customMakers = map[string]CustomUintsMaker{
@@ -2148,6 +2380,213 @@ func feesChart(charts *ChartData, bin binLevel, axis axisType, _ string) ([]byte
 	return nil, InvalidBinErr
 }
 
+// newMixingSet is the constructor for the zoomSet of the Decred mixing data.
+func newMixingSet() *ZoomSet {
+	return &ZoomSet{
+		Height:          newChartUints(0),
+		Time:            newChartUints(0),
+		Mixes:           newChartUints(0),
+		MixParticipants: newChartUints(0),
+		MixSpent:        newChartUints(0),
+		MixSpendSecs:    newChartUints(0),
+		TicketsBought:   newChartUints(0),
+		MixedTickets:    newChartUints(0),
+		MixDenoms:       newChartMixBands(0),
+		MixRounds:       newChartMixBands(0),
+	}
+}
+
+// dailyMixing bins the mixing data of each complete day by summing the totals
+// of its blocks.
+func dailyMixing(mixing *ZoomSet) *ZoomSet {
+	days := newMixingSet()
+	if len(mixing.Time) == 0 {
+		return days
+	}
+	end := midnight(mixing.Time[len(mixing.Time)-1])
+	for start := 0; start < len(mixing.Time); {
+		day := midnight(mixing.Time[start])
+		if day >= end {
+			break
+		}
+		stop := start + 1
+		for stop < len(mixing.Time) && midnight(mixing.Time[stop]) == day {
+			stop++
+		}
+		days.Time = append(days.Time, day)
+		days.Height = append(days.Height, mixing.Height[stop-1])
+		days.Mixes = append(days.Mixes, mixing.Mixes.Sum(start, stop))
+		days.MixParticipants = append(days.MixParticipants, mixing.MixParticipants.Sum(start, stop))
+		days.MixSpent = append(days.MixSpent, mixing.MixSpent.Sum(start, stop))
+		days.MixSpendSecs = append(days.MixSpendSecs, mixing.MixSpendSecs.Sum(start, stop))
+		days.TicketsBought = append(days.TicketsBought, mixing.TicketsBought.Sum(start, stop))
+		days.MixedTickets = append(days.MixedTickets, mixing.MixedTickets.Sum(start, stop))
+		days.MixDenoms = append(days.MixDenoms, mixing.MixDenoms.Sum(start, stop))
+		days.MixRounds = append(days.MixRounds, mixing.MixRounds.Sum(start, stop))
+		start = stop
+	}
+	return days
+}
+
+// encodeMixing encodes a mixing data set. The block bin is the Mixing data and
+// the day bin is binned by dailyMixing.
+func encodeMixing(charts *ChartData, bin binLevel, axis axisType, seed chartResponse,
+	data func(*ZoomSet) lengtherMap) ([]byte, error) {
+	var set *ZoomSet
+	switch bin {
+	case BlockBin:
+		set = charts.Mixing
+	case DayBin:
+		set = dailyMixing(charts.Mixing)
+	default:
+		return nil, InvalidBinErr
+	}
+	sets := data(set)
+	switch axis {
+	case HeightAxis:
+		sets[heightKey] = set.Height
+	default:
+		sets[timeKey] = set.Time
+	}
+	return encode(sets, seed)
+}
+
+// mixDenomVolumeChart is the mixed value of each mix denomination. The
+// denominations are sent with the data.
+func mixDenomVolumeChart(charts *ChartData, bin binLevel, axis axisType, _ string) ([]byte, error) {
+	seed := binAxisSeed(bin, axis)
+	seed[denomsKey] = txhelpers.MixDenoms()
+	return encodeMixing(charts, bin, axis, seed, func(set *ZoomSet) lengtherMap {
+		return lengtherMap{
+			mixedKey: set.MixDenoms,
+		}
+	})
+}
+
+// mixParticipantsChart is the number of mixes and their average number of
+// participants.
+func mixParticipantsChart(charts *ChartData, bin binLevel, axis axisType, _ string) ([]byte, error) {
+	return encodeMixing(charts, bin, axis, binAxisSeed(bin, axis), func(set *ZoomSet) lengtherMap {
+		return lengtherMap{
+			countKey:        set.Mixes,
+			participantsKey: ratios(set.MixParticipants, set.Mixes, 1),
+		}
+	})
+}
+
+// mixSpendTimeChart is the number of mix outputs spent and the average days
+// from their mix to the spend.
+func mixSpendTimeChart(charts *ChartData, bin binLevel, axis axisType, _ string) ([]byte, error) {
+	return encodeMixing(charts, bin, axis, binAxisSeed(bin, axis), func(set *ZoomSet) lengtherMap {
+		return lengtherMap{
+			countKey: set.MixSpent,
+			daysKey:  ratios(set.MixSpendSecs, set.MixSpent, 1.0/86400),
+		}
+	})
+}
+
+// remixRoundsChart is the mixed value of each re-mix round.
+func remixRoundsChart(charts *ChartData, bin binLevel, axis axisType, _ string) ([]byte, error) {
+	return encodeMixing(charts, bin, axis, binAxisSeed(bin, axis), func(set *ZoomSet) lengtherMap {
+		return lengtherMap{
+			roundsKey: set.MixRounds,
+		}
+	})
+}
+
+// mixedTicketsChart is the number of tickets bought from mixed split
+// transactions and their percentage of the tickets bought.
+func mixedTicketsChart(charts *ChartData, bin binLevel, axis axisType, _ string) ([]byte, error) {
+	return encodeMixing(charts, bin, axis, binAxisSeed(bin, axis), func(set *ZoomSet) lengtherMap {
+		return lengtherMap{
+			countKey: set.MixedTickets,
+			shareKey: ratios(set.MixedTickets, set.TicketsBought, 100),
+		}
+	})
+}
+
+// newWealthSet is the constructor for the zoomSet of the wealth distribution
+// snapshots.
+func newWealthSet() *ZoomSet {
+	return &ZoomSet{
+		Height:          newChartUints(0),
+		Time:            newChartUints(0),
+		WealthAddresses: newChartUints(0),
+		Gini:            newChartFloats(0),
+		Nakamoto:        newChartUints(0),
+		BucketAddresses: newChartWealthBuckets(0),
+		BucketValue:     newChartWealthBuckets(0),
+	}
+}
+
+// wealthTip is the height of the last snapshot of a wealth zoomSet, or -1 if
+// there is none.
+func wealthTip(wealth *ZoomSet) int64 {
+	if wealth == nil || len(wealth.Height) == 0 {
+		return -1
+	}
+	return int64(wealth.Height[len(wealth.Height)-1])
+}
+
+// encodeWealth encodes a wealth data set. The snapshots are taken at the last
+// block of each day, so the block and day bins are the same.
+func encodeWealth(wealth *ZoomSet, bin binLevel, axis axisType, seed chartResponse,
+	data func(*ZoomSet) lengtherMap) ([]byte, error) {
+	if bin != BlockBin && bin != DayBin {
+		return nil, InvalidBinErr
+	}
+	if wealth == nil {
+		wealth = newWealthSet()
+	}
+	sets := data(wealth)
+	switch axis {
+	case HeightAxis:
+		sets[heightKey] = wealth.Height
+	default:
+		sets[timeKey] = wealth.Time
+	}
+	return encode(sets, seed)
+}
+
+// wealthBucketsSeed is the seed of the balance bucket charts, with the lowest
+// balance of each bucket.
+func wealthBucketsSeed(bin binLevel, axis axisType) chartResponse {
+	seed := binAxisSeed(bin, axis)
+	seed[boundsKey] = dbtypes.WealthBucketMins
+	return seed
+}
+
+// balanceAddressesChart is the number of addresses in each balance bucket.
+func balanceAddressesChart(charts *ChartData, bin binLevel, axis axisType, _ string) ([]byte, error) {
+	return encodeWealth(charts.Wealth, bin, axis, wealthBucketsSeed(bin, axis), func(set *ZoomSet) lengtherMap {
+		return lengtherMap{
+			bucketsKey: set.BucketAddresses,
+		}
+	})
+}
+
+// balanceValueChart is the value held in each balance bucket.
+func balanceValueChart(charts *ChartData, bin binLevel, axis axisType, _ string) ([]byte, error) {
+	return encodeWealth(charts.Wealth, bin, axis, wealthBucketsSeed(bin, axis), func(set *ZoomSet) lengtherMap {
+		return lengtherMap{
+			bucketsKey: set.BucketValue,
+		}
+	})
+}
+
+// wealthConcentrationChart is the Gini coefficient of the address balances
+// and their Nakamoto coefficient, the fewest addresses holding more than half
+// of the value.
+func wealthConcentrationChart(charts *ChartData, bin binLevel, axis axisType, _ string) ([]byte, error) {
+	return encodeWealth(charts.Wealth, bin, axis, binAxisSeed(bin, axis), func(set *ZoomSet) lengtherMap {
+		return lengtherMap{
+			countKey:    set.WealthAddresses,
+			giniKey:     set.Gini,
+			nakamotoKey: set.Nakamoto,
+		}
+	})
+}
+
 func anonymitySetChart(charts *ChartData, bin binLevel, axis axisType, _ string) ([]byte, error) {
 	seed := binAxisSeed(bin, axis)
 	switch bin {
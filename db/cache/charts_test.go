@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -342,3 +343,69 @@ func TestChartReorg(t *testing.T) {
 	resetCharts()
 	testReorg(2, 2, 1, 1, 2)
 }
+
+// TestMixingCharts checks the averages and shares of the Decred mixing charts,
+// which are taken from the summed totals of each bin.
+func TestMixingCharts(t *testing.T) {
+	const start = 19675 * aDay // a midnight
+	charts := &ChartData{
+		ctx:    context.Background(),
+		Blocks: newBlockSet(0),
+		Days:   newDaySet(0),
+		Mixing: newMixingSet(),
+		cache:  make(map[string]*cachedChart),
+	}
+	mixing := charts.Mixing
+	for i := uint64(0); i < 3; i++ {
+		mixing.Height = append(mixing.Height, 100+i)
+		// Two blocks on the first day and one on the second.
+		mixing.Time = append(mixing.Time, start+i*16*3600)
+		mixing.Mixes = append(mixing.Mixes, i)
+		mixing.MixParticipants = append(mixing.MixParticipants, 4*i)
+		mixing.MixSpent = append(mixing.MixSpent, 2*i)
+		mixing.MixSpendSecs = append(mixing.MixSpendSecs, 2*i*aDay)
+		mixing.TicketsBought = append(mixing.TicketsBought, 4)
+		mixing.MixedTickets = append(mixing.MixedTickets, i)
+		mixing.MixDenoms = append(mixing.MixDenoms, []int64{int64(i), 0, 0, 0, 0, 0, 0, 0, 0, 1})
+		mixing.MixRounds = append(mixing.MixRounds, []int64{1, int64(i), 0, 0})
+	}
+
+	tests := []struct {
+		chartID string
+		bin     binLevel
+		want    string
+	}{
+		{MixParticipants, BlockBin, `{"axis":"height","bin":"block","count":[0,1,2],"h":[100,101,102],"participants":[0,4,4]}`},
+		{MixSpendTime, DayBin, `{"axis":"height","bin":"day","count":[2],"days":[1],"h":[101]}`},
+		{MixedTickets, DayBin, `{"axis":"height","bin":"day","count":[1],"h":[101],"share":[12.5]}`},
+		{RemixRounds, DayBin, `{"axis":"height","bin":"day","h":[101],"rounds":[[2,1,0,0]]}`},
+	}
+	for _, tt := range tests {
+		data, err := charts.Chart(tt.chartID, string(tt.bin), string(HeightAxis), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("%s %s: expected %s, found %s", tt.chartID, tt.bin, tt.want, data)
+		}
+	}
+
+	data, err := charts.Chart(MixDenomVolume, string(DayBin), string(TimeAxis), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp struct {
+		Mixed  [][]int64 `json:"mixed"`
+		Denoms []int64   `json:"denoms"`
+		T      []uint64  `json:"t"`
+	}
+	if err = json.Unmarshal(data, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.T) != 1 || resp.T[0] != start || fmt.Sprint(resp.Mixed) != "[[1 0 0 0 0 0 0 0 0 2]]" {
+		t.Errorf("unexpected day-binned denomination volume %s", data)
+	}
+	if len(resp.Denoms) != 10 || resp.Denoms[0] != 1<<18 {
+		t.Errorf("unexpected denominations %v", resp.Denoms)
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/decred/dcrdata/v8/mutilchain"
 	"github.com/decred/dcrdata/v8/txhelpers"
 	"github.com/decred/dcrdata/v8/xmr/xmrclient"
+	"github.com/decred/dcrdata/v8/xmr/xmrring"
 )
 
 type ChartMutilchainUpdater struct {
@@ -35,6 +36,10 @@ type MutilchainChartData struct {
 	LastBlockHeight     int64
 	Blocks              *ZoomSet
 	Days                *ZoomSet
+	Mempool             *ZoomSet
+	CoinAge             *ZoomSet
+	RingAnalysis        *ZoomSet
+	Wealth              *ZoomSet
 	APIBlockSize        *ZoomSet
 	APIBlockchainSize   *ZoomSet
 	APITxNumPerBlockAvg *ZoomSet
@@ -76,7 +81,8 @@ func (charts *MutilchainChartData) Lengthen() error {
 	} else {
 		shortest, err = ValidateLengths(blocks.Height, blocks.Time,
 			blocks.BlockSize, blocks.TxCount, blocks.Fees, blocks.Difficulty,
-			blocks.Hashrate, blocks.Reward)
+			blocks.Hashrate, blocks.Reward, blocks.NewAddresses, blocks.TotalMixed,
+			blocks.AnonymitySet)
 	}
 	if err != nil {
 		log.Warnf("%s: MultiChartData.Lengthen: multichain block data length mismatch detected. "+
@@ -146,6 +152,11 @@ func (charts *MutilchainChartData) Lengthen() error {
 				days.AverageTxSize = append(days.AverageTxSize, blocks.AverageTxSize.Avg(interval[0], interval[1]))
 				days.MoneroDecoyBands = append(days.MoneroDecoyBands, blocks.MoneroDecoyBands.Avg(interval[0], interval[1]))
 				days.TxPerBlock = append(days.TxPerBlock, blocks.TxPerBlock.Avg(interval[0], interval[1]))
+			} else {
+				days.TxPerBlock = append(days.TxPerBlock, blocks.TxCount.Avg(interval[0], interval[1]))
+				days.NewAddresses = append(days.NewAddresses, blocks.NewAddresses.Sum(interval[0], interval[1]))
+				days.TotalMixed = append(days.TotalMixed, blocks.TotalMixed.Sum(interval[0], interval[1]))
+				days.AnonymitySet = append(days.AnonymitySet, blocks.AnonymitySet.Sum(interval[0], interval[1]))
 			}
 			days.TxCount = append(days.TxCount, blocks.TxCount.Sum(interval[0], interval[1]))
 			days.Reward = append(days.Reward, blocks.Reward.Sum(interval[0], interval[1]))
@@ -164,7 +175,9 @@ func (charts *MutilchainChartData) Lengthen() error {
 			days.AverageRingSize, days.FeeRate, days.AverageTxSize, days.MoneroDecoyBands)
 	} else {
 		daysLen, err = ValidateLengths(days.Height, days.Time,
-			days.BlockSize, days.TxCount, days.Reward, days.Fees, days.Difficulty, days.Hashrate)
+			days.BlockSize, days.TxCount, days.TxPerBlock, days.Reward, days.Fees,
+			days.Difficulty, days.Hashrate, days.NewAddresses, days.TotalMixed,
+			days.AnonymitySet)
 	}
 
 	if err != nil {
@@ -269,6 +282,10 @@ func (charts *MutilchainChartData) readCacheFile(filePath string) error {
 		charts.Blocks.AverageTxSize = gobject.AverageTxSize
 		charts.Blocks.MoneroDecoyBands = gobject.MoneroDecoyBands
 		charts.Blocks.TxPerBlock = gobject.TxPerBlock
+	} else {
+		charts.Blocks.NewAddresses = gobject.NewAddresses
+		charts.Blocks.TotalMixed = gobject.TotalMixed
+		charts.Blocks.AnonymitySet = gobject.AnonymitySet
 	}
 
 	charts.mtx.Unlock()
@@ -313,9 +330,14 @@ func (charts *MutilchainChartData) Dump(dumpPath string) {
 	}
 }
 
-// TriggerUpdate triggers (*ChartData).Update.
+// TriggerUpdate triggers (*ChartData).Update. Without a synced database, the
+// charts data comes from external APIs and is not binned.
 func (charts *MutilchainChartData) TriggerUpdate(_ string, _ uint32) error {
-	if err := charts.MultichainUpdate(); err != nil {
+	update := charts.MultichainUpdate
+	if charts.UseSyncDB {
+		update = charts.Update
+	}
+	if err := update(); err != nil {
 		// Only log errors from ChartsData.Update. TODO: make this more severe.
 		log.Errorf("%s: (*ChartData).Update failed: %v", charts.ChainType, err)
 	}
@@ -367,6 +389,9 @@ func (charts *MutilchainChartData) gobject() *ChartGobject {
 		AverageTxSize:    charts.Blocks.AverageTxSize,
 		MoneroDecoyBands: charts.Blocks.MoneroDecoyBands,
 		TxPerBlock:       charts.Blocks.TxPerBlock,
+		NewAddresses:     charts.Blocks.NewAddresses,
+		TotalMixed:       charts.Blocks.TotalMixed,
+		AnonymitySet:     charts.Blocks.AnonymitySet,
 	}
 }
 
@@ -431,6 +456,64 @@ func (charts *MutilchainChartData) NewAtomsTip() int32 {
 	return int32(len(charts.Blocks.NewAtoms)) - 1
 }
 
+// NewAddressesTip is the height of the NewAddresses data.
+func (charts *MutilchainChartData) NewAddressesTip() int32 {
+	charts.mtx.RLock()
+	defer charts.mtx.RUnlock()
+	return int32(len(charts.Blocks.NewAddresses)) - 1
+}
+
+// MempoolTip is the time of the last mempool snapshot, or zero if there is
+// none.
+func (charts *MutilchainChartData) MempoolTip() uint64 {
+	charts.mtx.RLock()
+	defer charts.mtx.RUnlock()
+	if charts.Mempool == nil || len(charts.Mempool.Time) == 0 {
+		return 0
+	}
+	return charts.Mempool.Time[len(charts.Mempool.Time)-1]
+}
+
+// CoinAgeTip is the height of the last block in the CoinAge data, or -1 if
+// there is none.
+func (charts *MutilchainChartData) CoinAgeTip() int64 {
+	charts.mtx.RLock()
+	defer charts.mtx.RUnlock()
+	if charts.CoinAge == nil || len(charts.CoinAge.Height) == 0 {
+		return -1
+	}
+	return int64(charts.CoinAge.Height[len(charts.CoinAge.Height)-1])
+}
+
+// CoinAgeBandsTip is the height of the last block with age bands in the
+// CoinAge data, or -1 if there is none.
+func (charts *MutilchainChartData) CoinAgeBandsTip() int64 {
+	charts.mtx.RLock()
+	defer charts.mtx.RUnlock()
+	if charts.CoinAge == nil || len(charts.CoinAge.CoinAgeBands) == 0 {
+		return -1
+	}
+	return int64(charts.CoinAge.Height[len(charts.CoinAge.CoinAgeBands)-1])
+}
+
+// RingAnalysisTip is the height of the last block in the RingAnalysis data, or
+// -1 if there is none.
+func (charts *MutilchainChartData) RingAnalysisTip() int64 {
+	charts.mtx.RLock()
+	defer charts.mtx.RUnlock()
+	if charts.RingAnalysis == nil || len(charts.RingAnalysis.Height) == 0 {
+		return -1
+	}
+	return int64(charts.RingAnalysis.Height[len(charts.RingAnalysis.Height)-1])
+}
+
+// WealthTip is the height of the last wealth snapshot, or -1 if there is none.
+func (charts *MutilchainChartData) WealthTip() int64 {
+	charts.mtx.RLock()
+	defer charts.mtx.RUnlock()
+	return wealthTip(charts.Wealth)
+}
+
 // PoolSizeTip is the height of the PoolSize data.
 func (charts *MutilchainChartData) PoolSizeTip() int32 {
 	charts.mtx.RLock()
@@ -543,9 +626,12 @@ func NewLTCChartData(ctx context.Context, height uint32, chainParams *ltcchaincf
 		ctx:             ctx,
 		Blocks:          newBlockSet(size),
 		Days:            newDaySet(days),
+		Mempool:         newMempoolSet(),
+		CoinAge:         newCoinAgeSet(),
+		Wealth:          newWealthSet(),
 		cache:           make(map[string]*cachedChart),
 		updaters:        make([]ChartMutilchainUpdater, 0),
-		TimePerBlocks:   float64(chainParams.TargetTimePerBlock),
+		TimePerBlocks:   chainParams.TargetTimePerBlock.Seconds(),
 		ChainType:       mutilchain.TYPELTC,
 		LastBlockHeight: lastBlockHeight,
 		UseSyncDB:       !disabledDBSync,
@@ -561,9 +647,12 @@ func NewBTCChartData(ctx context.Context, height uint32, chainParams *btcchaincf
 		ctx:             ctx,
 		Blocks:          newBlockSet(size),
 		Days:            newDaySet(days),
+		Mempool:         newMempoolSet(),
+		CoinAge:         newCoinAgeSet(),
+		Wealth:          newWealthSet(),
 		cache:           make(map[string]*cachedChart),
 		updaters:        make([]ChartMutilchainUpdater, 0),
-		TimePerBlocks:   float64(chainParams.TargetTimePerBlock),
+		TimePerBlocks:   chainParams.TargetTimePerBlock.Seconds(),
 		ChainType:       mutilchain.TYPEBTC,
 		LastBlockHeight: lastBlockHeight,
 		UseSyncDB:       !disabledDBSync,
@@ -583,6 +672,7 @@ func NewXMRChartData(ctx context.Context, xmrClient *xmrclient.XMRClient, height
 		ctx:             ctx,
 		Blocks:          newBlockSet(size),
 		Days:            newDaySet(days),
+		RingAnalysis:    newRingAnalysisSet(),
 		cache:           make(map[string]*cachedChart),
 		updaters:        make([]ChartMutilchainUpdater, 0),
 		TimePerBlocks:   float64(120),
@@ -634,36 +724,50 @@ func (charts *MutilchainChartData) cacheChart(chartID string, bin binLevel, axis
 type MutilchainChartMaker func(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error)
 
 var mutilchainChartMaker = map[string]MutilchainChartMaker{
-	BlockSize:      MutilchainBlockSizeChart,
-	BlockChainSize: MutilchainBlockchainSizeChart,
-	CoinSupply:     MutilchainCoinSupplyChart,
-	DurationBTW:    MutilchainDurationBTWChart,
-	HashRate:       MutilchainHashRateChart,
-	POWDifficulty:  MutilchainDifficultyChart,
-	TxCount:        MutilchainTxCountChart,
-	Fees:           MutilchainFeesChart,
-	TxNumPerBlock:  MutilchainTxNumPerBlock,
-	MinedBlocks:    MutilchainMinedBlocks,
-	MempoolTxCount: MutilchainMempoolTxCount,
-	MempoolSize:    MutilchainMempoolSize,
-	AddressNumber:  MutilchainAddressNumber,
+	BlockSize:         MutilchainBlockSizeChart,
+	BlockChainSize:    MutilchainBlockchainSizeChart,
+	CoinSupply:        MutilchainCoinSupplyChart,
+	DurationBTW:       MutilchainDurationBTWChart,
+	HashRate:          MutilchainHashRateChart,
+	POWDifficulty:     MutilchainDifficultyChart,
+	TxCount:           MutilchainTxCountChart,
+	Fees:              MutilchainFeesChart,
+	TxNumPerBlock:     MutilchainTxNumPerBlock,
+	MinedBlocks:       MutilchainMinedBlocks,
+	MempoolTxCount:    MutilchainMempoolTxCount,
+	MempoolSize:       MutilchainMempoolSize,
+	AddressNumber:     MutilchainAddressNumber,
+	AvgAgeDays:        MutilchainAvgAgeDays,
+	CoinDaysDestroyed: MutilchainCoinDaysDestroyed,
+	CoinAgeBands:      MutilchainCoinAgeBands,
+	MeanCoinAge:       MutilchainMeanCoinAge,
+	TotalCoinDays:     MutilchainTotalCoinDays,
+	RealizedCap:       MutilchainRealizedCap,
+	MixedVolume:       MutilchainMixedVolume,
+	MixAnonymitySet:   MutilchainAnonymitySet,
+	BalanceAddresses:  MutilchainBalanceAddresses,
+	BalanceValue:      MutilchainBalanceValue,
+	Concentration:     MutilchainWealthConcentration,
 }
 
 var xmrChartMaker = map[string]MutilchainChartMaker{
-	BlockSize:      xmrBlockSizeChart,
-	BlockChainSize: xmrBlockchainSizeChart,
-	CoinSupply:     xmrCoinSupplyChart,
-	DurationBTW:    xmrDurationBTWChart,
-	HashRate:       xmrHashrateChart,
-	POWDifficulty:  xmrDifficultyChart,
-	TxCount:        xmrTxCountChart,
-	Fees:           xmrFeesChart,
-	TxNumPerBlock:  xmrTxsPerBlockChart,
-	TotalRingSize:  xmrRingSizeSum,
-	AvgRingSize:    xmrRingSizeAvg,
-	FeeRate:        xmrFeeRate,
-	AvgTxSize:      xmrAvgTxSize,
-	DecoyBands:     xmrDecoyBands,
+	BlockSize:         xmrBlockSizeChart,
+	BlockChainSize:    xmrBlockchainSizeChart,
+	CoinSupply:        xmrCoinSupplyChart,
+	DurationBTW:       xmrDurationBTWChart,
+	HashRate:          xmrHashrateChart,
+	POWDifficulty:     xmrDifficultyChart,
+	TxCount:           xmrTxCountChart,
+	Fees:              xmrFeesChart,
+	TxNumPerBlock:     xmrTxsPerBlockChart,
+	TotalRingSize:     xmrRingSizeSum,
+	AvgRingSize:       xmrRingSizeAvg,
+	FeeRate:           xmrFeeRate,
+	AvgTxSize:         xmrAvgTxSize,
+	DecoyBands:        xmrDecoyBands,
+	EffectiveRingSize: xmrEffectiveRingSize,
+	RingDeductions:    xmrRingDeductions,
+	RingMemberAges:    xmrRingMemberAges,
 }
 
 // Chart will return a JSON-encoded chartResponse of the provided chart,
@@ -1157,7 +1261,314 @@ func xmrDurationBTWChart(charts *MutilchainChartData, bin binLevel, axis axisTyp
 	return nil, InvalidBinErr
 }
 
+// newMempoolSet is the constructor for the zoomSet of mempool snapshots.
+func newMempoolSet() *ZoomSet {
+	return &ZoomSet{
+		Height:         newChartUints(0),
+		Time:           newChartUints(0),
+		MempoolTxCount: newChartUints(0),
+		MempoolSize:    newChartUints(0),
+	}
+}
+
+// dailyMempool averages the mempool snapshots of each complete day. The height
+// of a day is the best block height at its last snapshot.
+func dailyMempool(mempool *ZoomSet) *ZoomSet {
+	days := newMempoolSet()
+	if len(mempool.Time) == 0 {
+		return days
+	}
+	end := midnight(mempool.Time[len(mempool.Time)-1])
+	for start := 0; start < len(mempool.Time); {
+		day := midnight(mempool.Time[start])
+		if day >= end {
+			break
+		}
+		stop := start + 1
+		for stop < len(mempool.Time) && midnight(mempool.Time[stop]) == day {
+			stop++
+		}
+		days.Time = append(days.Time, day)
+		days.Height = append(days.Height, mempool.Height[stop-1])
+		days.MempoolTxCount = append(days.MempoolTxCount, mempool.MempoolTxCount.Avg(start, stop))
+		days.MempoolSize = append(days.MempoolSize, mempool.MempoolSize.Avg(start, stop))
+		start = stop
+	}
+	return days
+}
+
+// newCoinAgeSet is the constructor for the zoomSet of the coin age data, which
+// is kept a few blocks behind the Blocks data.
+func newCoinAgeSet() *ZoomSet {
+	return &ZoomSet{
+		Height:            newChartUints(0),
+		Time:              newChartUints(0),
+		CoinDaysDestroyed: newChartFloats(0),
+		AvgCoinAge:        newChartFloats(0),
+		CoinAgeBands:      newChartAgeCoinBands(0),
+		MeanCoinAge:       newChartFloats(0),
+		TotalCoinDays:     newChartFloats(0),
+		RealizedCap:       newChartFloats(0),
+	}
+}
+
+// dailyCoinAge bins the coin age data of each complete day. The coin days
+// destroyed are summed and the average age of the spent coins averaged, while
+// the age bands, mean coin age, total coin days and realized cap are those of
+// the last block of the day.
+func dailyCoinAge(coinAge *ZoomSet) *ZoomSet {
+	days := newCoinAgeSet()
+	if len(coinAge.Time) == 0 {
+		return days
+	}
+	end := midnight(coinAge.Time[len(coinAge.Time)-1])
+	for start := 0; start < len(coinAge.Time); {
+		day := midnight(coinAge.Time[start])
+		if day >= end {
+			break
+		}
+		stop := start + 1
+		for stop < len(coinAge.Time) && midnight(coinAge.Time[stop]) == day {
+			stop++
+		}
+		last := stop - 1
+		days.Time = append(days.Time, day)
+		days.Height = append(days.Height, coinAge.Height[last])
+		days.CoinDaysDestroyed = append(days.CoinDaysDestroyed, coinAge.CoinDaysDestroyed.Sum(start, stop))
+		days.AvgCoinAge = append(days.AvgCoinAge, coinAge.AvgCoinAge.Avg(start, stop))
+		days.MeanCoinAge = append(days.MeanCoinAge, coinAge.MeanCoinAge[last])
+		days.TotalCoinDays = append(days.TotalCoinDays, coinAge.TotalCoinDays[last])
+		days.RealizedCap = append(days.RealizedCap, coinAge.RealizedCap[last])
+		if last < len(coinAge.CoinAgeBands) {
+			days.CoinAgeBands = append(days.CoinAgeBands, coinAge.CoinAgeBands[last])
+		}
+		start = stop
+	}
+	return days
+}
+
+// encodeCoinAge encodes a coin age data set. The block bin is the CoinAge
+// data and the day bin is binned by dailyCoinAge. Without a synced database,
+// there is no coin age data.
+func encodeCoinAge(charts *MutilchainChartData, bin binLevel, axis axisType, key string,
+	data func(*ZoomSet) lengther) ([]byte, error) {
+	if charts.UseAPI {
+		return encodeAPI(nil, key, data)
+	}
+	seed := binAxisSeed(bin, axis)
+	var set *ZoomSet
+	switch bin {
+	case BlockBin:
+		set = charts.CoinAge
+	case DayBin:
+		set = dailyCoinAge(charts.CoinAge)
+	default:
+		return nil, InvalidBinErr
+	}
+	switch axis {
+	case HeightAxis:
+		return encode(lengtherMap{
+			heightKey: set.Height,
+			key:       data(set),
+		}, seed)
+	default:
+		return encode(lengtherMap{
+			timeKey: set.Time,
+			key:     data(set),
+		}, seed)
+	}
+}
+
+// encodeCoinJoin encodes a CoinJoin data set of the Blocks and Days zoomSets.
+// The data is only stored by a synced database.
+func encodeCoinJoin(charts *MutilchainChartData, bin binLevel, axis axisType, key string,
+	data func(*ZoomSet) lengther) ([]byte, error) {
+	if charts.UseAPI {
+		return encodeAPI(nil, key, data)
+	}
+	seed := binAxisSeed(bin, axis)
+	switch bin {
+	case BlockBin:
+		switch axis {
+		case HeightAxis:
+			return encode(lengtherMap{
+				key: data(charts.Blocks),
+			}, seed)
+		default:
+			return encode(lengtherMap{
+				timeKey: charts.Blocks.Time,
+				key:     data(charts.Blocks),
+			}, seed)
+		}
+	case DayBin:
+		switch axis {
+		case HeightAxis:
+			return encode(lengtherMap{
+				heightKey: charts.Days.Height,
+				key:       data(charts.Days),
+			}, seed)
+		default:
+			return encode(lengtherMap{
+				timeKey: charts.Days.Time,
+				key:     data(charts.Days),
+			}, seed)
+		}
+	}
+	return nil, InvalidBinErr
+}
+
+// newRingAnalysisSet is the constructor for the zoomSet of the Monero ring
+// analysis data, which is kept xmrring.SpendableAge blocks behind the Blocks
+// data.
+func newRingAnalysisSet() *ZoomSet {
+	return &ZoomSet{
+		Height:               newChartUints(0),
+		Time:                 newChartUints(0),
+		RingInputs:           newChartUints(0),
+		RingSizeSum:          newChartUints(0),
+		EffectiveRingSizeSum: newChartUints(0),
+		DeducedInputs:        newChartUints(0),
+		RingMemberAges:       newChartRingAgeBands(0),
+	}
+}
+
+// dailyRingAnalysis bins the ring analysis data of each complete day by
+// summing the totals of its blocks.
+func dailyRingAnalysis(rings *ZoomSet) *ZoomSet {
+	days := newRingAnalysisSet()
+	if len(rings.Time) == 0 {
+		return days
+	}
+	end := midnight(rings.Time[len(rings.Time)-1])
+	for start := 0; start < len(rings.Time); {
+		day := midnight(rings.Time[start])
+		if day >= end {
+			break
+		}
+		stop := start + 1
+		for stop < len(rings.Time) && midnight(rings.Time[stop]) == day {
+			stop++
+		}
+		days.Time = append(days.Time, day)
+		days.Height = append(days.Height, rings.Height[stop-1])
+		days.RingInputs = append(days.RingInputs, rings.RingInputs.Sum(start, stop))
+		days.RingSizeSum = append(days.RingSizeSum, rings.RingSizeSum.Sum(start, stop))
+		days.EffectiveRingSizeSum = append(days.EffectiveRingSizeSum, rings.EffectiveRingSizeSum.Sum(start, stop))
+		days.DeducedInputs = append(days.DeducedInputs, rings.DeducedInputs.Sum(start, stop))
+		days.RingMemberAges = append(days.RingMemberAges, rings.RingMemberAges.Sum(start, stop))
+		start = stop
+	}
+	return days
+}
+
+// ratios divides each numerator by its denominator, scaled. A zero denominator
+// gives zero.
+func ratios(num, den ChartUints, scale float64) ChartFloats {
+	r := make(ChartFloats, 0, len(num))
+	for i := 0; i < len(num) && i < len(den); i++ {
+		if den[i] == 0 {
+			r = append(r, 0)
+			continue
+		}
+		r = append(r, scale*float64(num[i])/float64(den[i]))
+	}
+	return r
+}
+
+// encodeRingAnalysis encodes a ring analysis data set. The block bin is the
+// RingAnalysis data and the day bin is binned by dailyRingAnalysis.
+func encodeRingAnalysis(charts *MutilchainChartData, bin binLevel, axis axisType, seed chartResponse,
+	data func(*ZoomSet) lengtherMap) ([]byte, error) {
+	var set *ZoomSet
+	switch bin {
+	case BlockBin:
+		set = charts.RingAnalysis
+	case DayBin:
+		set = dailyRingAnalysis(charts.RingAnalysis)
+	default:
+		return nil, InvalidBinErr
+	}
+	sets := data(set)
+	switch axis {
+	case HeightAxis:
+		sets[heightKey] = set.Height
+	default:
+		sets[timeKey] = set.Time
+	}
+	return encode(sets, seed)
+}
+
+// xmrEffectiveRingSize is the average ring size and the average effective ring
+// size, after the members that cannot be the real spend are eliminated.
+func xmrEffectiveRingSize(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	return encodeRingAnalysis(charts, bin, axis, binAxisSeed(bin, axis), func(set *ZoomSet) lengtherMap {
+		return lengtherMap{
+			ringSizeKey:    ratios(set.RingSizeSum, set.RingInputs, 1),
+			effRingSizeKey: ratios(set.EffectiveRingSizeSum, set.RingInputs, 1),
+		}
+	})
+}
+
+// xmrRingDeductions is the percentage of the inputs with a deduced real spend
+// and the percentage of the ring members eliminated.
+func xmrRingDeductions(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	return encodeRingAnalysis(charts, bin, axis, binAxisSeed(bin, axis), func(set *ZoomSet) lengtherMap {
+		eliminated := make(ChartUints, 0, len(set.RingSizeSum))
+		for i := 0; i < len(set.RingSizeSum) && i < len(set.EffectiveRingSizeSum); i++ {
+			eliminated = append(eliminated, set.RingSizeSum[i]-set.EffectiveRingSizeSum[i])
+		}
+		return lengtherMap{
+			deducedKey:    ratios(set.DeducedInputs, set.RingInputs, 100),
+			eliminatedKey: ratios(eliminated, set.RingSizeSum, 100),
+		}
+	})
+}
+
+// xmrRingMemberAges is the number of ring members in each age band, with the
+// expected share of each band under the wallet's decoy selection.
+func xmrRingMemberAges(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	seed := binAxisSeed(bin, axis)
+	seed[expectedKey] = xmrring.ExpectedAgeBands()
+	return encodeRingAnalysis(charts, bin, axis, seed, func(set *ZoomSet) lengtherMap {
+		return lengtherMap{
+			ringAgesKey: set.RingMemberAges,
+		}
+	})
+}
+
+// minedBlocks is the number of blocks mined on each day, given the height of
+// the last block of each day.
+func minedBlocks(heights ChartUints) ChartUints {
+	counts := make(ChartUints, 0, len(heights))
+	var next uint64
+	for _, h := range heights {
+		counts = append(counts, h+1-next)
+		next = h + 1
+	}
+	return counts
+}
+
+// encodeAPI encodes a data set from the external APIs, which are used in place
+// of the database when it is not synced. The API data is day-binned on a time
+// axis whatever the requested bin and axis.
+func encodeAPI(set *ZoomSet, key string, data func(*ZoomSet) lengther) ([]byte, error) {
+	seed := binAxisSeed(DayBin, TimeAxis)
+	if set == nil {
+		return encode(lengtherMap{
+			timeKey: newChartUints(0),
+			key:     newChartUints(0),
+		}, seed)
+	}
+	return encode(lengtherMap{
+		timeKey: set.Time,
+		key:     data(set),
+	}, seed)
+}
+
 func MutilchainBlockSizeChart(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	if charts.UseAPI {
+		return encodeAPI(charts.APIBlockSize, sizeKey, func(s *ZoomSet) lengther { return s.BlockSize })
+	}
 	seed := binAxisSeed(bin, axis)
 	switch bin {
 	case BlockBin:
@@ -1180,18 +1591,6 @@ func MutilchainBlockSizeChart(charts *MutilchainChartData, bin binLevel, axis ax
 				sizeKey:   charts.Days.BlockSize,
 			}, seed)
 		default:
-			if charts.UseAPI {
-				timeArray := newChartUints(0)
-				blockSizeArray := newChartUints(0)
-				if charts.APIBlockSize != nil {
-					timeArray = charts.APIBlockSize.Time
-					blockSizeArray = charts.APIBlockSize.BlockSize
-				}
-				return encode(lengtherMap{
-					timeKey: timeArray,
-					sizeKey: blockSizeArray,
-				}, seed)
-			}
 			return encode(lengtherMap{
 				timeKey: charts.Days.Time,
 				sizeKey: charts.Days.BlockSize,
@@ -1202,6 +1601,9 @@ func MutilchainBlockSizeChart(charts *MutilchainChartData, bin binLevel, axis ax
 }
 
 func MutilchainBlockchainSizeChart(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	if charts.UseAPI {
+		return encodeAPI(charts.APIBlockchainSize, sizeKey, func(s *ZoomSet) lengther { return s.APIBlockchainSize })
+	}
 	seed := binAxisSeed(bin, axis)
 	switch bin {
 	case BlockBin:
@@ -1224,18 +1626,6 @@ func MutilchainBlockchainSizeChart(charts *MutilchainChartData, bin binLevel, ax
 				sizeKey:   accumulate(charts.Days.BlockSize),
 			}, seed)
 		default:
-			if charts.UseAPI {
-				timeArray := newChartUints(0)
-				blockchainSizeArray := newChartUints(0)
-				if charts.APIBlockchainSize != nil {
-					timeArray = charts.APIBlockchainSize.Time
-					blockchainSizeArray = charts.APIBlockchainSize.APIBlockchainSize
-				}
-				return encode(lengtherMap{
-					timeKey: timeArray,
-					sizeKey: blockchainSizeArray,
-				}, seed)
-			}
 			return encode(lengtherMap{
 				timeKey: charts.Days.Time,
 				sizeKey: accumulate(charts.Days.BlockSize),
@@ -1277,19 +1667,23 @@ func MutilchainChainWorkChart(charts *MutilchainChartData, bin binLevel, axis ax
 	return nil, InvalidBinErr
 }
 
+// MutilchainCoinSupplyChart is the cumulative block subsidy, in coins.
 func MutilchainCoinSupplyChart(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	if charts.UseAPI {
+		return encodeAPI(charts.APICoinSupply, supplyKey, func(s *ZoomSet) lengther { return s.NewAtoms })
+	}
 	seed := binAxisSeed(bin, axis)
 	switch bin {
 	case BlockBin:
 		switch axis {
 		case HeightAxis:
 			return encode(lengtherMap{
-				supplyKey: accumulate(charts.Blocks.NewAtoms),
+				supplyKey: accumulateFloat(charts.Blocks.Reward),
 			}, seed)
 		default:
 			return encode(lengtherMap{
 				timeKey:   charts.Blocks.Time,
-				supplyKey: accumulate(charts.Blocks.NewAtoms),
+				supplyKey: accumulateFloat(charts.Blocks.Reward),
 			}, seed)
 		}
 	case DayBin:
@@ -1297,25 +1691,12 @@ func MutilchainCoinSupplyChart(charts *MutilchainChartData, bin binLevel, axis a
 		case HeightAxis:
 			return encode(lengtherMap{
 				heightKey: charts.Days.Height,
-				supplyKey: accumulate(charts.Days.NewAtoms),
+				supplyKey: accumulateFloat(charts.Days.Reward),
 			}, seed)
 		default:
-			if charts.UseAPI {
-				timeArray := newChartUints(0)
-				coinSupplyArray := newChartUints(0)
-				if charts.APICoinSupply != nil {
-					timeArray = charts.APICoinSupply.Time
-					coinSupplyArray = charts.APICoinSupply.NewAtoms
-				}
-				return encode(lengtherMap{
-					timeKey:   timeArray,
-					supplyKey: coinSupplyArray,
-				}, seed)
-			}
 			return encode(lengtherMap{
 				timeKey:   charts.Days.Time,
-				supplyKey: accumulate(charts.Days.NewAtoms),
-				heightKey: charts.Days.Height,
+				supplyKey: accumulateFloat(charts.Days.Reward),
 			}, seed)
 		}
 	}
@@ -1361,106 +1742,82 @@ func MutilchainDurationBTWChart(charts *MutilchainChartData, bin binLevel, axis
 	return nil, InvalidBinErr
 }
 
-// func MutilchainHashRateChart(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
-// 	seed := binAxisSeed(bin, axis)
-// 	switch bin {
-// 	case BlockBin:
-// 		if len(charts.Blocks.Time) < 2 {
-// 			return nil, fmt.Errorf("Not enough blocks to calculate hashrate")
-// 		}
-// 		seed[offsetKey] = HashrateAvgLength
-// 		times, rates := hashrate(charts.Blocks.Time, charts.Blocks.Chainwork)
-// 		switch axis {
-// 		case HeightAxis:
-// 			return encode(lengtherMap{
-// 				rateKey: rates,
-// 			}, seed)
-// 		default:
-// 			return encode(lengtherMap{
-// 				timeKey: times,
-// 				rateKey: rates,
-// 			}, seed)
-// 		}
-// 	case DayBin:
-// 		if len(charts.Days.Time) < 2 {
-// 			return nil, fmt.Errorf("Not enough days to calculate hashrate")
-// 		}
-// 		seed[offsetKey] = 1
-// 		times, rates := dailyHashrate(charts.Days.Time, charts.Days.Chainwork)
-// 		switch axis {
-// 		case HeightAxis:
-// 			return encode(lengtherMap{
-// 				heightKey: charts.Days.Height[1:],
-// 				rateKey:   rates,
-// 			}, seed)
-// 		default:
-// 			return encode(lengtherMap{
-// 				timeKey: times,
-// 				rateKey: rates,
-// 			}, seed)
-// 		}
-// 	}
-// 	return nil, InvalidBinErr
-// }
-
 func MutilchainDifficultyChart(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
-	// Pow Difficulty only has window level bin, so all others are ignored.
+	if charts.UseAPI {
+		return encodeAPI(charts.APIDifficulty, diffKey, func(s *ZoomSet) lengther { return s.Difficulty })
+	}
 	seed := binAxisSeed(bin, axis)
-	switch axis {
-	case HeightAxis:
-		return encode(lengtherMap{
-			diffKey: charts.Blocks.Difficulty,
-		}, seed)
-	default:
-		if charts.UseAPI {
-			timeArray := newChartUints(0)
-			difficultyArray := newChartFloats(0)
-			if charts.APIDifficulty != nil {
-				timeArray = charts.APIDifficulty.Time
-				difficultyArray = charts.APIDifficulty.Difficulty
-			}
+	switch bin {
+	case BlockBin:
+		switch axis {
+		case HeightAxis:
 			return encode(lengtherMap{
-				diffKey: difficultyArray,
-				timeKey: timeArray,
+				diffKey: charts.Blocks.Difficulty,
+			}, seed)
+		default:
+			return encode(lengtherMap{
+				timeKey: charts.Blocks.Time,
+				diffKey: charts.Blocks.Difficulty,
+			}, seed)
+		}
+	case DayBin:
+		switch axis {
+		case HeightAxis:
+			return encode(lengtherMap{
+				heightKey: charts.Days.Height,
+				diffKey:   charts.Days.Difficulty,
+			}, seed)
+		default:
+			return encode(lengtherMap{
+				timeKey: charts.Days.Time,
+				diffKey: charts.Days.Difficulty,
 			}, seed)
 		}
-		return encode(lengtherMap{
-			diffKey: charts.Blocks.Difficulty,
-			timeKey: charts.Blocks.Time,
-		}, seed)
 	}
+	return nil, InvalidBinErr
 }
 
+// MutilchainHashRateChart is the network hashrate implied by the difficulty,
+// in hashes per second.
 func MutilchainHashRateChart(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
-	// Pow Difficulty only has window level bin, so all others are ignored.
+	if charts.UseAPI {
+		return encodeAPI(charts.APIHashrate, rateKey, func(s *ZoomSet) lengther { return s.Hashrate })
+	}
 	seed := binAxisSeed(bin, axis)
-	switch axis {
-	case HeightAxis:
-		return encode(lengtherMap{
-			heightKey: charts.Days.Height[1:],
-			rateKey:   charts.Blocks.Hashrate,
-		}, seed)
-	default:
-		if charts.UseAPI {
-			timeArray := newChartUints(0)
-			hashrateArray := newChartFloats(0)
-			if charts.APIHashrate != nil {
-				timeArray = charts.APIHashrate.Time
-				hashrateArray = charts.APIHashrate.Hashrate
-			}
+	switch bin {
+	case BlockBin:
+		switch axis {
+		case HeightAxis:
 			return encode(lengtherMap{
-				timeKey: timeArray,
-				rateKey: hashrateArray,
+				rateKey: charts.Blocks.Hashrate,
+			}, seed)
+		default:
+			return encode(lengtherMap{
+				timeKey: charts.Blocks.Time,
+				rateKey: charts.Blocks.Hashrate,
+			}, seed)
+		}
+	case DayBin:
+		switch axis {
+		case HeightAxis:
+			return encode(lengtherMap{
+				heightKey: charts.Days.Height,
+				rateKey:   charts.Days.Hashrate,
+			}, seed)
+		default:
+			return encode(lengtherMap{
+				timeKey: charts.Days.Time,
+				rateKey: charts.Days.Hashrate,
 			}, seed)
 		}
-		return encode(lengtherMap{
-			timeKey: charts.Blocks.Time,
-			rateKey: charts.Blocks.Hashrate,
-		}, seed)
 	}
+	return nil, InvalidBinErr
 }
 
 func MutilchainTxCountChart(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	if charts.UseAPI {
+		return encodeAPI(charts.APITxTotal, countKey, func(s *ZoomSet) lengther { return s.TxCount })
+	}
 	seed := binAxisSeed(bin, axis)
 	switch bin {
 	case BlockBin:
@@ -1483,18 +1840,6 @@ func MutilchainTxCountChart(charts *MutilchainChartData, bin binLevel, axis axis
 				countKey:  charts.Days.TxCount,
 			}, seed)
 		default:
-			if charts.UseAPI {
-				timeArray := newChartUints(0)
-				txcountArray := newChartUints(0)
-				if charts.APITxTotal != nil {
-					timeArray = charts.APITxTotal.Time
-					txcountArray = charts.APITxTotal.TxCount
-				}
-				return encode(lengtherMap{
-					timeKey:  timeArray,
-					countKey: txcountArray,
-				}, seed)
-			}
 			return encode(lengtherMap{
 				timeKey:  charts.Days.Time,
 				countKey: charts.Days.TxCount,
@@ -1504,47 +1849,168 @@ func MutilchainTxCountChart(charts *MutilchainChartData, bin binLevel, axis axis
 	return nil, InvalidBinErr
 }
 
+// MutilchainTxNumPerBlock is the average number of transactions per block.
 func MutilchainTxNumPerBlock(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	if charts.UseAPI {
+		return encodeAPI(charts.APITxNumPerBlockAvg, countKey, func(s *ZoomSet) lengther { return s.APITxAverage })
+	}
 	seed := binAxisSeed(bin, axis)
-	return encode(lengtherMap{
-		timeKey:  charts.APITxNumPerBlockAvg.Time,
-		countKey: charts.APITxNumPerBlockAvg.APITxAverage,
-	}, seed)
+	switch bin {
+	case BlockBin:
+		switch axis {
+		case HeightAxis:
+			return encode(lengtherMap{
+				countKey: charts.Blocks.TxCount,
+			}, seed)
+		default:
+			return encode(lengtherMap{
+				timeKey:  charts.Blocks.Time,
+				countKey: charts.Blocks.TxCount,
+			}, seed)
+		}
+	case DayBin:
+		switch axis {
+		case HeightAxis:
+			return encode(lengtherMap{
+				heightKey: charts.Days.Height,
+				countKey:  charts.Days.TxPerBlock,
+			}, seed)
+		default:
+			return encode(lengtherMap{
+				timeKey:  charts.Days.Time,
+				countKey: charts.Days.TxPerBlock,
+			}, seed)
+		}
+	}
+	return nil, InvalidBinErr
 }
 
+// MutilchainMinedBlocks is the number of blocks mined per day. It is only
+// day-binned.
 func MutilchainMinedBlocks(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	if charts.UseAPI {
+		return encodeAPI(charts.APINewMinedBlocks, countKey, func(s *ZoomSet) lengther { return s.APIMinedBlocks })
+	}
+	if bin != DayBin {
+		return nil, InvalidBinErr
+	}
 	seed := binAxisSeed(bin, axis)
-	return encode(lengtherMap{
-		timeKey:  charts.APINewMinedBlocks.Time,
-		countKey: charts.APINewMinedBlocks.APIMinedBlocks,
-	}, seed)
+	switch axis {
+	case HeightAxis:
+		return encode(lengtherMap{
+			heightKey: charts.Days.Height,
+			countKey:  minedBlocks(charts.Days.Height),
+		}, seed)
+	default:
+		return encode(lengtherMap{
+			timeKey:  charts.Days.Time,
+			countKey: minedBlocks(charts.Days.Height),
+		}, seed)
+	}
 }
 
+// MutilchainMempoolTxCount is the number of transactions in mempool. The block
+// bin is the raw mempool snapshots, and the height of a snapshot is the best
+// block height at the time.
 func MutilchainMempoolTxCount(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	if charts.UseAPI {
+		return encodeAPI(charts.APIMempoolTxCount, countKey, func(s *ZoomSet) lengther { return s.APIMempoolTxNum })
+	}
 	seed := binAxisSeed(bin, axis)
-	return encode(lengtherMap{
-		timeKey:  charts.APIMempoolTxCount.Time,
-		countKey: charts.APIMempoolTxCount.APIMempoolTxNum,
-	}, seed)
+	var set *ZoomSet
+	switch bin {
+	case BlockBin:
+		set = charts.Mempool
+	case DayBin:
+		set = dailyMempool(charts.Mempool)
+	default:
+		return nil, InvalidBinErr
+	}
+	switch axis {
+	case HeightAxis:
+		return encode(lengtherMap{
+			heightKey: set.Height,
+			countKey:  set.MempoolTxCount,
+		}, seed)
+	default:
+		return encode(lengtherMap{
+			timeKey:  set.Time,
+			countKey: set.MempoolTxCount,
+		}, seed)
+	}
 }
 
+// MutilchainMempoolSize is the size of mempool in bytes. It is binned like
+// MutilchainMempoolTxCount.
 func MutilchainMempoolSize(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	if charts.UseAPI {
+		return encodeAPI(charts.APIMempoolSize, sizeKey, func(s *ZoomSet) lengther { return s.APIMempoolSize })
+	}
 	seed := binAxisSeed(bin, axis)
-	return encode(lengtherMap{
-		timeKey: charts.APIMempoolSize.Time,
-		sizeKey: charts.APIMempoolSize.APIMempoolSize,
-	}, seed)
+	var set *ZoomSet
+	switch bin {
+	case BlockBin:
+		set = charts.Mempool
+	case DayBin:
+		set = dailyMempool(charts.Mempool)
+	default:
+		return nil, InvalidBinErr
+	}
+	switch axis {
+	case HeightAxis:
+		return encode(lengtherMap{
+			heightKey: set.Height,
+			sizeKey:   set.MempoolSize,
+		}, seed)
+	default:
+		return encode(lengtherMap{
+			timeKey: set.Time,
+			sizeKey: set.MempoolSize,
+		}, seed)
+	}
 }
 
+// MutilchainAddressNumber is the cumulative number of distinct addresses that
+// have been funded.
 func MutilchainAddressNumber(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	if charts.UseAPI {
+		return encodeAPI(charts.APIAddressCount, countKey, func(s *ZoomSet) lengther { return s.APIAddressCount })
+	}
 	seed := binAxisSeed(bin, axis)
-	return encode(lengtherMap{
-		timeKey:  charts.APIAddressCount.Time,
-		countKey: charts.APIAddressCount.APIAddressCount,
-	}, seed)
+	switch bin {
+	case BlockBin:
+		switch axis {
+		case HeightAxis:
+			return encode(lengtherMap{
+				countKey: accumulate(charts.Blocks.NewAddresses),
+			}, seed)
+		default:
+			return encode(lengtherMap{
+				timeKey:  charts.Blocks.Time,
+				countKey: accumulate(charts.Blocks.NewAddresses),
+			}, seed)
+		}
+	case DayBin:
+		switch axis {
+		case HeightAxis:
+			return encode(lengtherMap{
+				heightKey: charts.Days.Height,
+				countKey:  accumulate(charts.Days.NewAddresses),
+			}, seed)
+		default:
+			return encode(lengtherMap{
+				timeKey:  charts.Days.Time,
+				countKey: accumulate(charts.Days.NewAddresses),
+			}, seed)
+		}
+	}
+	return nil, InvalidBinErr
 }
 
 func MutilchainFeesChart(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	if charts.UseAPI {
+		return encodeAPI(charts.APITxFeeAvg, feesKey, func(s *ZoomSet) lengther { return s.Fees })
+	}
 	seed := binAxisSeed(bin, axis)
 	switch bin {
 	case BlockBin:
@@ -1567,18 +2033,6 @@ func MutilchainFeesChart(charts *MutilchainChartData, bin binLevel, axis axisTyp
 				feesKey:   charts.Days.Fees,
 			}, seed)
 		default:
-			if charts.UseAPI {
-				timeArray := newChartUints(0)
-				feesArray := newChartUints(0)
-				if charts.APITxFeeAvg != nil {
-					timeArray = charts.APITxFeeAvg.Time
-					feesArray = charts.APITxFeeAvg.Fees
-				}
-				return encode(lengtherMap{
-					timeKey: timeArray,
-					feesKey: feesArray,
-				}, seed)
-			}
 			return encode(lengtherMap{
 				timeKey: charts.Days.Time,
 				feesKey: charts.Days.Fees,
@@ -1587,3 +2041,77 @@ func MutilchainFeesChart(charts *MutilchainChartData, bin binLevel, axis axisTyp
 	}
 	return nil, InvalidBinErr
 }
+
+// MutilchainAvgAgeDays is the average age in days of the coins spent in each
+// block.
+func MutilchainAvgAgeDays(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	return encodeCoinAge(charts, bin, axis, avgCoinAgeKey, func(s *ZoomSet) lengther { return s.AvgCoinAge })
+}
+
+// MutilchainCoinDaysDestroyed is the coin days destroyed in each block.
+func MutilchainCoinDaysDestroyed(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	return encodeCoinAge(charts, bin, axis, cddKey, func(s *ZoomSet) lengther { return s.CoinDaysDestroyed })
+}
+
+// MutilchainCoinAgeBands is the unspent value by age band, the HODL waves.
+func MutilchainCoinAgeBands(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	return encodeCoinAge(charts, bin, axis, ageBandKey, func(s *ZoomSet) lengther { return s.CoinAgeBands })
+}
+
+// MutilchainMeanCoinAge is the mean age in days of the unspent coins.
+func MutilchainMeanCoinAge(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	return encodeCoinAge(charts, bin, axis, meanCoinAgeKey, func(s *ZoomSet) lengther { return s.MeanCoinAge })
+}
+
+// MutilchainTotalCoinDays is the total coin days of the unspent coins.
+func MutilchainTotalCoinDays(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	return encodeCoinAge(charts, bin, axis, totalCoinDaysKey, func(s *ZoomSet) lengther { return s.TotalCoinDays })
+}
+
+// MutilchainMixedVolume is the value of the equal outputs of the CoinJoins.
+func MutilchainMixedVolume(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	return encodeCoinJoin(charts, bin, axis, mixedKey, func(s *ZoomSet) lengther { return s.TotalMixed })
+}
+
+// MutilchainAnonymitySet is the number of equal outputs of the CoinJoins, the
+// participants the mixed coins hide among.
+func MutilchainAnonymitySet(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	return encodeCoinJoin(charts, bin, axis, anonymitySetKey, func(s *ZoomSet) lengther { return s.AnonymitySet })
+}
+
+// MutilchainRealizedCap is the USD value of the unspent coins at the price of
+// the day they were created.
+func MutilchainRealizedCap(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	return encodeCoinAge(charts, bin, axis, realizedCapKey, func(s *ZoomSet) lengther { return s.RealizedCap })
+}
+
+// MutilchainBalanceAddresses is the number of addresses in each balance
+// bucket.
+func MutilchainBalanceAddresses(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	return encodeWealth(charts.Wealth, bin, axis, wealthBucketsSeed(bin, axis), func(set *ZoomSet) lengtherMap {
+		return lengtherMap{
+			bucketsKey: set.BucketAddresses,
+		}
+	})
+}
+
+// MutilchainBalanceValue is the value held in each balance bucket.
+func MutilchainBalanceValue(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	return encodeWealth(charts.Wealth, bin, axis, wealthBucketsSeed(bin, axis), func(set *ZoomSet) lengtherMap {
+		return lengtherMap{
+			bucketsKey: set.BucketValue,
+		}
+	})
+}
+
+// MutilchainWealthConcentration is the Gini and Nakamoto coefficients of the
+// address balances.
+func MutilchainWealthConcentration(charts *MutilchainChartData, bin binLevel, axis axisType) ([]byte, error) {
+	return encodeWealth(charts.Wealth, bin, axis, binAxisSeed(bin, axis), func(set *ZoomSet) lengtherMap {
+		return lengtherMap{
+			countKey:    set.WealthAddresses,
+			giniKey:     set.Gini,
+			nakamotoKey: set.Nakamoto,
+		}
+	})
+}
@@ -0,0 +1,229 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	btcchaincfg "github.com/btcsuite/btcd/chaincfg"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/mutilchain"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+// mutilchainTestCharts is a BTC MutilchainChartData with three and a half days
+// of blocks, one every six hours, mempool snapshots every eight hours, and the
+// coin age data of all but the last two blocks, and the wealth snapshots of the
+// first two days.
+func mutilchainTestCharts(t *testing.T) *MutilchainChartData {
+	const start = 19675 * aDay // a midnight
+	charts := NewBTCChartData(context.Background(), 0, &btcchaincfg.MainNetParams, 0, false)
+	blocks := charts.Blocks
+	for i := uint64(0); i < 14; i++ {
+		blocks.Height = append(blocks.Height, i)
+		blocks.Time = append(blocks.Time, start+600+i*6*3600+i%3*60)
+		blocks.BlockSize = append(blocks.BlockSize, 1000+i*100)
+		blocks.TxCount = append(blocks.TxCount, 1+i%4)
+		blocks.Fees = append(blocks.Fees, i*1500)
+		difficulty := float64(1 + i/8)
+		blocks.Difficulty = append(blocks.Difficulty, difficulty)
+		blocks.Hashrate = append(blocks.Hashrate, difficulty*4294967296/charts.TimePerBlocks)
+		blocks.Reward = append(blocks.Reward, 50)
+		blocks.NewAddresses = append(blocks.NewAddresses, i%3)
+		// A 5-participant, 0.01 BTC Whirlpool CoinJoin in every third block.
+		var mixed, participants uint64
+		if i%3 == 0 {
+			mixed, participants = 5e6, 5
+		}
+		blocks.TotalMixed = append(blocks.TotalMixed, mixed)
+		blocks.AnonymitySet = append(blocks.AnonymitySet, participants)
+	}
+	mempool := charts.Mempool
+	for i := uint64(0); i < 11; i++ {
+		mempool.Time = append(mempool.Time, start+i*8*3600+1800)
+		mempool.Height = append(mempool.Height, i*4/3)
+		mempool.MempoolTxCount = append(mempool.MempoolTxCount, 10+i*i)
+		mempool.MempoolSize = append(mempool.MempoolSize, 2500+i*300)
+	}
+	coinAge := charts.CoinAge
+	for i := uint64(0); i < 12; i++ {
+		coinAge.Height = append(coinAge.Height, i)
+		coinAge.Time = append(coinAge.Time, blocks.Time[i])
+		coinAge.CoinDaysDestroyed = append(coinAge.CoinDaysDestroyed, float64(i%4*25))
+		coinAge.AvgCoinAge = append(coinAge.AvgCoinAge, float64(i%4)/2)
+		coinAge.MeanCoinAge = append(coinAge.MeanCoinAge, float64(i)/8)
+		coinAge.TotalCoinDays = append(coinAge.TotalCoinDays, float64(i*i*25))
+		coinAge.RealizedCap = append(coinAge.RealizedCap, float64(50*(i+1)*100))
+		// The bands of the last block are not fetched yet.
+		if i < 11 {
+			coinAge.CoinAgeBands = append(coinAge.CoinAgeBands, &dbtypes.AgeBandData{
+				Less1Day:  float64(min(i, 4) * 50),
+				DayToWeek: float64(max(int(i)-4, 0) * 50),
+			})
+		}
+	}
+	wealth := charts.Wealth
+	for i := uint64(0); i < 2; i++ {
+		last := i*4 + 3 // the last block of the day
+		wealth.Height = append(wealth.Height, last)
+		wealth.Time = append(wealth.Time, blocks.Time[last])
+		wealth.WealthAddresses = append(wealth.WealthAddresses, 10+i*5)
+		wealth.Gini = append(wealth.Gini, 0.5+float64(i)/10)
+		wealth.Nakamoto = append(wealth.Nakamoto, 3-i)
+		wealth.BucketAddresses = append(wealth.BucketAddresses, []int64{4, 3, 2, 1 + int64(i)*5, 0, 0, 0, 0, 0, 0})
+		wealth.BucketValue = append(wealth.BucketValue, []int64{2e5, 2e6, 2e7, 5e7 + int64(i)*5e8, 0, 0, 0, 0, 0, 0})
+	}
+	if err := charts.Lengthen(); err != nil {
+		t.Fatalf("Lengthen: %v", err)
+	}
+	return charts
+}
+
+// TestMutilchainCharts compares the BTC/LTC charts for every bin and axis with
+// the golden files in testdata/mutilchain. Run with -update to regenerate them.
+func TestMutilchainCharts(t *testing.T) {
+	charts := mutilchainTestCharts(t)
+	if n := len(charts.Days.Time); n != 3 {
+		t.Fatalf("expected 3 days, found %d", n)
+	}
+
+	chartIDs := make([]string, 0, len(mutilchainChartMaker))
+	for chartID := range mutilchainChartMaker {
+		chartIDs = append(chartIDs, chartID)
+	}
+	sort.Strings(chartIDs)
+
+	for _, chartID := range chartIDs {
+		t.Run(chartID, func(t *testing.T) {
+			var out bytes.Buffer
+			for _, bin := range []binLevel{BlockBin, DayBin} {
+				for _, axis := range []axisType{TimeAxis, HeightAxis} {
+					fmt.Fprintf(&out, "bin=%s axis=%s\n", bin, axis)
+					data, err := charts.Chart(chartID, string(bin), string(axis))
+					if err != nil {
+						fmt.Fprintf(&out, "error: %v\n", err)
+						continue
+					}
+					out.Write(data)
+					out.WriteByte('\n')
+				}
+			}
+
+			golden := filepath.Join("testdata", "mutilchain", chartID+".golden")
+			if *updateGolden {
+				if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(golden, out.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("missing golden file, run with -update: %v", err)
+			}
+			if !bytes.Equal(out.Bytes(), want) {
+				t.Errorf("%s charts differ from %s:\n%s", chartID, golden, out.String())
+			}
+		})
+	}
+}
+
+// TestMutilchainChartsAPI checks that the external API data is used when the
+// database is not synced.
+func TestMutilchainChartsAPI(t *testing.T) {
+	charts := mutilchainTestCharts(t)
+	charts.UseAPI = true
+	charts.APIDifficulty = &ZoomSet{
+		Time:       ChartUints{1, 2},
+		Difficulty: ChartFloats{3, 4},
+	}
+	data, err := charts.Chart(POWDifficulty, string(BlockBin), string(HeightAxis))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"axis":"time","bin":"day","diff":[3,4],"t":[1,2]}`
+	if string(data) != want {
+		t.Fatalf("expected %s, found %s", want, data)
+	}
+	// A missing API data set encodes as empty series.
+	data, err = charts.Chart(MempoolSize, string(DayBin), string(TimeAxis))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `{"axis":"time","bin":"day","size":[],"t":[]}`
+	if string(data) != want {
+		t.Fatalf("expected %s, found %s", want, data)
+	}
+}
+
+// TestXMRRingAnalysisCharts checks the averages and shares of the Monero ring
+// analysis charts, which are taken from the summed totals of each bin.
+func TestXMRRingAnalysisCharts(t *testing.T) {
+	const start = 19675 * aDay // a midnight
+	charts := &MutilchainChartData{
+		ctx:          context.Background(),
+		Blocks:       newBlockSet(0),
+		Days:         newDaySet(0),
+		RingAnalysis: newRingAnalysisSet(),
+		cache:        make(map[string]*cachedChart),
+		ChainType:    mutilchain.TYPEXMR,
+	}
+	rings := charts.RingAnalysis
+	for i := uint64(0); i < 3; i++ {
+		rings.Height = append(rings.Height, 100+i)
+		// Two blocks on the first day and one on the second.
+		rings.Time = append(rings.Time, start+i*16*3600)
+		rings.RingInputs = append(rings.RingInputs, 2*i)
+		rings.RingSizeSum = append(rings.RingSizeSum, 32*i)
+		rings.EffectiveRingSizeSum = append(rings.EffectiveRingSizeSum, 30*i)
+		rings.DeducedInputs = append(rings.DeducedInputs, i/2)
+		rings.RingMemberAges = append(rings.RingMemberAges, []int64{int64(i), 2, 0, 0, 0, 0, 1})
+	}
+
+	tests := []struct {
+		chartID string
+		bin     binLevel
+		want    string
+	}{
+		{EffectiveRingSize, BlockBin, `{"axis":"height","bin":"block","effRingSize":[0,15,15],"h":[100,101,102],"ringSize":[0,16,16]}`},
+		{RingDeductions, BlockBin, `{"axis":"height","bin":"block","deduced":[0,0,25],"eliminated":[0,6.25,6.25],"h":[100,101,102]}`},
+		{RingDeductions, DayBin, `{"axis":"height","bin":"day","deduced":[0],"eliminated":[6.25],"h":[101]}`},
+	}
+	for _, tt := range tests {
+		data, err := charts.Chart(tt.chartID, string(tt.bin), string(HeightAxis))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("%s %s: expected %s, found %s", tt.chartID, tt.bin, tt.want, data)
+		}
+	}
+
+	data, err := charts.Chart(RingMemberAges, string(DayBin), string(TimeAxis))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp struct {
+		RingAges [][]int64 `json:"ringAges"`
+		Expected []float64 `json:"expected"`
+		T        []uint64  `json:"t"`
+	}
+	if err = json.Unmarshal(data, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.T) != 1 || resp.T[0] != start || fmt.Sprint(resp.RingAges) != "[[1 4 0 0 0 0 2]]" {
+		t.Errorf("unexpected day-binned ring ages %s", data)
+	}
+	if len(resp.Expected) != 7 {
+		t.Errorf("expected 7 decoy model shares, found %d", len(resp.Expected))
+	}
+}
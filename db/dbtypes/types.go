@@ -2381,6 +2381,325 @@ type DailyMarket struct {
 
 type MexcMonthlyPriceResponse [][]interface{}
 
+// ExchangeCandle is a stored candlestick of an exchange market.
+type ExchangeCandle struct {
+	Start  time.Time
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume float64
+}
+
+// ExchangePriceTick is a stored price update of an exchange market.
+type ExchangePriceTick struct {
+	Stamp      time.Time
+	Price      float64
+	BaseVolume float64
+	Volume     float64
+	Change     float64
+}
+
+// Watch is a watch-list entry: an address, or a Monero address with its
+// private view key, whose incoming funds are posted to a callback URL. Client
+// is the IP address of the API client that registered it.
+type Watch struct {
+	ID            int64     `json:"id"`
+	Chain         string    `json:"chain"`
+	Address       string    `json:"address"`
+	ViewKey       string    `json:"-"`
+	CallbackURL   string    `json:"callback_url"`
+	Client        string    `json:"-"`
+	Secret        string    `json:"-"`
+	Confirmations int       `json:"confirmations"`
+	Created       time.Time `json:"created"`
+}
+
+// WatchDelivery is a webhook delivery of an event of a Watch.
+type WatchDelivery struct {
+	ID            int64      `json:"id"`
+	WatchID       int64      `json:"watch_id"`
+	Event         string     `json:"event"`
+	TxID          string     `json:"txid"`
+	Height        int64      `json:"height"`
+	BlockHash     string     `json:"block_hash,omitempty"`
+	Amount        int64      `json:"amount"`
+	Confirmations int        `json:"confirmations"`
+	Created       time.Time  `json:"created"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttempt   time.Time  `json:"next_attempt"`
+	ResponseCode  int        `json:"response_code,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	Delivered     *time.Time `json:"delivered,omitempty"`
+}
+
+// HDWallet is the aggregated view of the used addresses derived from an
+// extended public key or output descriptor. Amounts are in atoms. The key is
+// not part of it.
+type HDWallet struct {
+	Chain         string            `json:"chain"`
+	ScriptType    string            `json:"script_type"`
+	GapLimit      int               `json:"gap_limit"`
+	Branches      []*HDWalletBranch `json:"branches"`
+	NumUsed       int               `json:"num_used"`
+	TotalReceived int64             `json:"total_received"`
+	TotalSent     int64             `json:"total_sent"`
+	Balance       int64             `json:"balance"`
+	UTXOs         []*HDWalletUTXO   `json:"utxos"`
+	// Txs are the latest transactions of the addresses, up to TxsLimit.
+	Txs      []*HDWalletTx `json:"txs"`
+	TxsLimit int           `json:"txs_limit"`
+}
+
+// HDWalletBranch is a scanned receive or change branch of an HDWallet.
+type HDWalletBranch struct {
+	Name        string             `json:"name"`
+	Path        string             `json:"path"`
+	NextIndex   uint32             `json:"next_index"`
+	NextAddress string             `json:"next_address"`
+	Addresses   []*HDWalletAddress `json:"addresses"`
+}
+
+// HDWalletAddress is a used address of an HDWalletBranch.
+type HDWalletAddress struct {
+	Path     string `json:"path"`
+	Address  string `json:"address"`
+	Received int64  `json:"received"`
+	Balance  int64  `json:"balance"`
+}
+
+// HDWalletUTXO is an unspent output of an HDWallet address.
+type HDWalletUTXO struct {
+	Path      string `json:"path"`
+	Address   string `json:"address"`
+	TxHash    string `json:"txid"`
+	Vout      uint32 `json:"vout"`
+	Height    int64  `json:"height"`
+	BlockTime int64  `json:"block_time"`
+	Value     int64  `json:"value"`
+}
+
+// HDWalletTx is a transaction funding or spending HDWallet addresses, with
+// the amounts they received and sent in it.
+type HDWalletTx struct {
+	TxHash    string `json:"txid"`
+	BlockTime int64  `json:"block_time"`
+	Received  int64  `json:"received"`
+	Sent      int64  `json:"sent"`
+}
+
+// ClusterTx is a transaction of a block as seen by the address clustering,
+// with the addresses of its inputs and outputs.
+type ClusterTx struct {
+	TxHash  string
+	Inputs  []string
+	Outputs []*ClusterOutput
+	// Mix is set for a Decred mix, as detected by txhelpers.IsMixTx.
+	Mix bool
+}
+
+// ClusterOutput is an output of a ClusterTx. Fresh is set when its address
+// was not funded before the block of the transaction.
+type ClusterOutput struct {
+	Address string
+	Value   int64
+	Fresh   bool
+}
+
+// ClusterLink is the evidence of a transaction that addresses are controlled
+// by one entity: its input addresses and its change address, if one was
+// detected.
+type ClusterLink struct {
+	TxHash    string
+	Addresses []string
+	Change    string
+}
+
+// AddressCluster is a group of addresses deemed to be controlled by one
+// entity. An address that is not clustered is reported alone with ID 0.
+// Balance is in atoms, and the activity times are UNIX seconds.
+type AddressCluster struct {
+	Chain         string   `json:"chain"`
+	ID            int64    `json:"id"`
+	Size          int64    `json:"size"`
+	Balance       int64    `json:"balance"`
+	FirstActivity int64    `json:"first_activity"`
+	LastActivity  int64    `json:"last_activity"`
+	Addresses     []string `json:"addresses,omitempty"`
+}
+
+// CoSpentAddress is an address spent together with another in TxCount
+// clustered transactions.
+type CoSpentAddress struct {
+	Address string `json:"address"`
+	TxCount int64  `json:"tx_count"`
+}
+
+// TraceTx is a transaction as seen by the transaction flow tracing, with its
+// spent outpoints and its outputs. Coinbase inputs are omitted.
+type TraceTx struct {
+	TxHash      string
+	BlockHeight int64
+	BlockTime   int64
+	// Mix is set for a Decred mix, as detected by txhelpers.IsMixTx.
+	Mix bool
+	// Swap is set for an atomic swap contract, redemption or refund.
+	Swap    bool
+	Inputs  []*TraceInput
+	Outputs []*TraceOutput
+}
+
+// TraceInput is a spent outpoint of a TraceTx. ClusterID is the address
+// cluster of its address, or 0.
+type TraceInput struct {
+	PrevTxHash string
+	PrevVout   uint32
+	Address    string
+	Value      int64
+	ClusterID  int64
+}
+
+// TraceOutput is an output of a TraceTx, with the transaction spending it, if
+// any. ClusterID is the address cluster of its address, or 0.
+type TraceOutput struct {
+	Vout        uint32
+	Address     string
+	Value       int64
+	SpendTxHash string
+	ClusterID   int64
+}
+
+// TxGraph is the flow of funds from a transaction output, forward through
+// the transactions spending it or backward through the transactions funding
+// it, up to Hops transactions away. Amounts are in atoms.
+type TxGraph struct {
+	Chain     string         `json:"chain"`
+	TxHash    string         `json:"txid"`
+	Vout      uint32         `json:"vout"`
+	Direction string         `json:"direction"`
+	Hops      int            `json:"hops"`
+	MinValue  int64          `json:"min_value"`
+	Nodes     []*TxGraphNode `json:"nodes"`
+	Edges     []*TxGraphEdge `json:"edges"`
+	// Pruned is the number of edges below MinValue that were not followed.
+	Pruned int `json:"pruned"`
+	// Truncated is set when the node limit was reached.
+	Truncated bool `json:"truncated"`
+}
+
+// TxGraphNode is a transaction of a TxGraph, Hop transactions away from the
+// traced one. Stop is the reason a node was not followed further: mix,
+// swap, cluster (the funds reached another address cluster), coinbase or
+// hops.
+type TxGraphNode struct {
+	TxHash      string `json:"txid"`
+	Hop         int    `json:"hop"`
+	BlockHeight int64  `json:"block_height"`
+	BlockTime   int64  `json:"block_time"`
+	Stop        string `json:"stop,omitempty"`
+}
+
+// TxGraphEdge is an output of the From transaction spent by the To
+// transaction. To is empty for an unspent output.
+type TxGraphEdge struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Vout    uint32 `json:"vout"`
+	Address string `json:"address"`
+	Value   int64  `json:"value"`
+}
+
+// MixingStats are the Decred mixing statistics of a range of blocks. Values
+// are in atoms. Participants is estimated from the change outputs of the
+// mixes, one per peer. SpendSecs is the sum of the seconds from the mixes of
+// the Spent mix outputs to their spends, and Remixed is the value of the mixed
+// outputs spent by other mixes. MixedTickets is the number of Tickets funded
+// by mixed split transactions.
+type MixingStats struct {
+	Mixes        int64 `json:"mixes"`
+	Participants int64 `json:"participants"`
+	Mixed        int64 `json:"mixed"`
+	Remixed      int64 `json:"remixed"`
+	Spent        int64 `json:"spent"`
+	SpendSecs    int64 `json:"spend_secs"`
+	Tickets      int64 `json:"tickets"`
+	MixedTickets int64 `json:"mixed_tickets"`
+}
+
+// MixingDenom is the number of mixes and the mixed value of a mix
+// denomination, in atoms.
+type MixingDenom struct {
+	Denom int64 `json:"denom"`
+	Mixes int64 `json:"mixes"`
+	Mixed int64 `json:"mixed"`
+}
+
+// MixRound is the number of mixes and the mixed value of a re-mix round.
+// Round 1 mixes spend no outputs of other mixes, and round 4 includes the
+// later rounds.
+type MixRound struct {
+	Round int64 `json:"round"`
+	Mixes int64 `json:"mixes"`
+	Mixed int64 `json:"mixed"`
+}
+
+// MixingSummary is the mixing statistics of the blocks above a height, with
+// their breakdown by denomination and by re-mix round.
+type MixingSummary struct {
+	MixingStats
+	Denoms []*MixingDenom `json:"denoms"`
+	Rounds []*MixRound    `json:"rounds"`
+}
+
+// WealthBucketMins are the lowest balances, in atoms, of the balance buckets
+// of the wealth distribution. The buckets grow tenfold from 0.001 coins, and
+// the first one holds the smaller balances.
+var WealthBucketMins = []int64{0, 1e5, 1e6, 1e7, 1e8, 1e9, 1e10, 1e11, 1e12, 1e13}
+
+// WealthBucket is the number of addresses and their value in a balance
+// bucket.
+type WealthBucket struct {
+	Min       int64 `json:"min"`
+	Addresses int64 `json:"addresses"`
+	Value     int64 `json:"value"`
+}
+
+// WealthSnapshot is the wealth distribution of the address balances of a
+// chain after the last block of a day. Nakamoto is the fewest addresses
+// holding more than half of the value.
+type WealthSnapshot struct {
+	Height    int64           `json:"height"`
+	Time      int64           `json:"time"`
+	Addresses int64           `json:"addresses"`
+	Total     int64           `json:"total"`
+	Gini      float64         `json:"gini"`
+	Nakamoto  int64           `json:"nakamoto"`
+	Buckets   []*WealthBucket `json:"buckets"`
+}
+
+// RichListEntry is an address of a rich list. Share is the percentage of the
+// value of all addresses.
+type RichListEntry struct {
+	Rank      int64   `json:"rank"`
+	Address   string  `json:"address"`
+	Balance   int64   `json:"balance"`
+	Share     float64 `json:"share"`
+	Label     string  `json:"label,omitempty"`
+	ClusterID int64   `json:"cluster_id,omitempty"`
+}
+
+// RichList is a page of the addresses of a chain with the largest balances,
+// at the last block included in the balances, with the last wealth snapshot.
+type RichList struct {
+	Chain     string           `json:"chain"`
+	Height    int64            `json:"height"`
+	Addresses int64            `json:"addresses"`
+	Total     int64            `json:"total"`
+	Entries   []*RichListEntry `json:"entries"`
+	Snapshot  *WealthSnapshot  `json:"snapshot,omitempty"`
+}
+
 // TreasuryBalance is the current balance, spent amount, and tx count for the
 // treasury.
 type TreasuryBalance struct {
@@ -2719,6 +3038,53 @@ type MultichainPoolDataItem struct {
 	Miner         string  `json:"miner"`
 }
 
+// BlockPool is the mining pool attribution of a BTC or LTC block. Reward is
+// the total value of the coinbase outputs in atoms.
+type BlockPool struct {
+	Height          int64    `json:"height"`
+	Hash            string   `json:"hash"`
+	Time            int64    `json:"time"`
+	PoolSlug        string   `json:"pool_slug"`
+	PoolName        string   `json:"pool_name"`
+	CoinbaseScript  []byte   `json:"-"`
+	PayoutAddresses []string `json:"payout_addresses"`
+	Reward          int64    `json:"reward"`
+	NumTx           int      `json:"numtx"`
+	DefsVersion     int      `json:"defs_version"`
+}
+
+// PoolBlockCount is the number of blocks mined by a pool, and how many of them
+// had no transactions other than the coinbase.
+type PoolBlockCount struct {
+	PoolSlug    string
+	Blocks      int64
+	EmptyBlocks int64
+}
+
+// PoolShareBins are the bin sizes, in seconds, of a PoolShareChart.
+var PoolShareBins = map[string]int64{
+	"day":  86400,
+	"week": 7 * 86400,
+}
+
+// PoolShareSeries is the share of the blocks mined by a pool in each bin of a
+// PoolShareChart, which approximates the pool's share of the hashrate.
+type PoolShareSeries struct {
+	Slug   string    `json:"slug"`
+	Name   string    `json:"name"`
+	Link   string    `json:"link,omitempty"`
+	Blocks []int64   `json:"blocks"`
+	Share  []float64 `json:"share"`
+}
+
+// PoolShareChart is the hashrate share of the mining pools over time.
+type PoolShareChart struct {
+	ChainType string             `json:"chain"`
+	Bin       string             `json:"bin"`
+	Time      []int64            `json:"time"`
+	Pools     []*PoolShareSeries `json:"pools"`
+}
+
 type MarketCapData struct {
 	Symbol        string  `json:"symbol"`
 	SymbolDisplay string  `json:"symbolDisplay"`
@@ -11,6 +11,7 @@ import (
 
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrdata/v8/mutilchain"
 	"github.com/decred/dcrdata/v8/txhelpers"
 )
 
@@ -123,6 +124,8 @@ func (p *ChainMonitor) switchToSideChain(reorgData *txhelpers.ReorgData) (int32,
 		// handler for sync coin_age_bands table
 		log.Infof("Start syncing coin age bands/mean coin age data in the background. Height (From Sidechain): %d.", msgBlock.Header.Height)
 		go p.db.SyncCoinAgeDataAllSet(int64(msgBlock.Header.Height))
+		go p.db.SyncMixingDataOnHeight(int64(msgBlock.Header.Height))
+		go p.db.SyncWealth(mutilchain.TYPEDCR)
 		currentHeight++
 	}
 
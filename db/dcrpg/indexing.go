@@ -602,6 +602,16 @@ func (pgb *ChainDB) DeindexMutilchainWholeTable(chainType string) error {
 		if err != nil {
 			return err
 		}
+
+		// monero_tx_fingerprints
+		err = HandlerDeindexFunc(pgb.db, mutilchainquery.DeindexMoneroTxFingerprintsOnBlockHeight)
+		if err != nil {
+			return err
+		}
+		err = HandlerDeindexFunc(pgb.db, mutilchainquery.DeindexMoneroTxFingerprintsOnFlags)
+		if err != nil {
+			return err
+		}
 	} else {
 		err = HandlerDeindexFunc(pgb.db, mutilchainquery.DeindexAddressTableOnAddrVoutRowIdStmt(chainType))
 		if err != nil {
@@ -889,6 +899,14 @@ func (pgb *ChainDB) IndexMutilchainWholeTable(chainType string) error {
 		if err = HandlerMultichainIndexFunc(pgb.db, "monero_rct_data on tx_hash", mutilchainquery.IndexMoneroRctDataOnTxHash); err != nil {
 			return err
 		}
+
+		// monero_tx_fingerprints
+		if err = HandlerMultichainIndexFunc(pgb.db, "monero_tx_fingerprints on block_height", mutilchainquery.IndexMoneroTxFingerprintsOnBlockHeight); err != nil {
+			return err
+		}
+		if err = HandlerMultichainIndexFunc(pgb.db, "monero_tx_fingerprints on flags", mutilchainquery.IndexMoneroTxFingerprintsOnFlags); err != nil {
+			return err
+		}
 	} else {
 		if err = HandlerMultichainIndexFunc(pgb.db, fmt.Sprintf("%saddress on address/vout_row_id", chainType), mutilchainquery.IndexAddressTableOnAddrVoutRowIdStmt(chainType)); err != nil {
 			return err
@@ -308,6 +308,23 @@ ORDER BY count, is_funding;`
 		JOIN vouts ON addresses.tx_vin_vout_row_id = vouts.id
 		WHERE addresses.address=$1 AND addresses.is_funding AND addresses.matching_tx_hash = '' AND valid_mainchain
 		ORDER BY addresses.block_time DESC;`
+
+	// SelectUsedAddresses selects which of the addresses in $1 have received
+	// funds.
+	SelectUsedAddresses = `SELECT DISTINCT address FROM addresses WHERE address = ANY($1);`
+
+	// SelectAddressesTxs selects the latest $2 mainchain transactions funding
+	// or spending any of the addresses in $1, with the amounts received and
+	// sent by the addresses in each.
+	SelectAddressesTxs = `SELECT tx_hash, block_time,
+			SUM(CASE WHEN is_funding THEN value ELSE 0 END),
+			SUM(CASE WHEN is_funding THEN 0 ELSE value END)
+		FROM addresses
+		WHERE address = ANY($1) AND valid_mainchain
+		GROUP BY tx_hash, block_time
+		ORDER BY block_time DESC
+		LIMIT $2;`
+
 	// Since tx_vin_vout_row_id is the vouts table primary key (id) when
 	// is_funding=true, there is no need to join vouts on tx_hash and tx_index.
 
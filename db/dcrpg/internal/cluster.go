@@ -0,0 +1,119 @@
+package internal
+
+// These queries relate to the "address_clusters", "cluster_txs" and
+// "cluster_progress" tables of the address clustering, and to the Decred
+// "transactions" and "addresses" tables it is computed from. The clustering
+// tables are shared by all UTXO chains, with a chain column.
+const (
+	// Cluster IDs come from one sequence for all chains. When clusters merge,
+	// the smallest ID is kept.
+	CreateAddressClustersTable = `CREATE TABLE IF NOT EXISTS address_clusters (
+		chain TEXT NOT NULL,
+		address TEXT NOT NULL,
+		cluster_id INT8 NOT NULL,
+		PRIMARY KEY (chain, address)
+	);
+	CREATE SEQUENCE IF NOT EXISTS address_cluster_ids;
+	CREATE INDEX IF NOT EXISTS idx_address_clusters_cluster_id
+		ON address_clusters (chain, cluster_id);`
+
+	// The transactions that linked addresses, with the change output found
+	// by the change heuristic, if any.
+	CreateClusterTxsTable = `CREATE TABLE IF NOT EXISTS cluster_txs (
+		chain TEXT NOT NULL,
+		tx_hash TEXT NOT NULL,
+		block_height INT8 NOT NULL,
+		change_address TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (chain, tx_hash)
+	);`
+
+	// The last block height clustered on each chain.
+	CreateClusterProgressTable = `CREATE TABLE IF NOT EXISTS cluster_progress (
+		chain TEXT PRIMARY KEY,
+		height INT8 NOT NULL
+	);`
+
+	SelectClusterProgress = `SELECT height FROM cluster_progress WHERE chain = $1;`
+
+	UpsertClusterProgress = `INSERT INTO cluster_progress (chain, height) VALUES ($1, $2)
+		ON CONFLICT (chain) DO UPDATE SET height = EXCLUDED.height;`
+
+	SelectNextClusterID = `SELECT nextval('address_cluster_ids');`
+
+	// SelectAddressesClusterIDs selects the distinct clusters of the addresses
+	// in $2.
+	SelectAddressesClusterIDs = `SELECT DISTINCT cluster_id FROM address_clusters
+		WHERE chain = $1 AND address = ANY($2);`
+
+	// MergeClusters moves the addresses of the clusters in $3 to cluster $2.
+	MergeClusters = `UPDATE address_clusters SET cluster_id = $2
+		WHERE chain = $1 AND cluster_id = ANY($3);`
+
+	InsertAddressesCluster = `INSERT INTO address_clusters (chain, address, cluster_id)
+		SELECT $1, addr, $3 FROM unnest($2::TEXT[]) AS addr
+		ON CONFLICT (chain, address) DO NOTHING;`
+
+	InsertClusterTx = `INSERT INTO cluster_txs (chain, tx_hash, block_height, change_address)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (chain, tx_hash) DO NOTHING;`
+
+	SelectAddressClusterID = `SELECT cluster_id FROM address_clusters
+		WHERE chain = $1 AND address = $2;`
+
+	// SelectAddressesClusters selects the clusters of the addresses in $2.
+	SelectAddressesClusters = `SELECT address, cluster_id FROM address_clusters
+		WHERE chain = $1 AND address = ANY($2);`
+
+	SelectClusterSize = `SELECT COUNT(*) FROM address_clusters
+		WHERE chain = $1 AND cluster_id = $2;`
+
+	SelectClusterAddresses = `SELECT address FROM address_clusters
+		WHERE chain = $1 AND cluster_id = $2
+		ORDER BY address
+		LIMIT $3 OFFSET $4;`
+
+	// SelectClusterBlockTxs selects the valid regular transactions of the
+	// main chain block at height $1, with their mix count. Stake transactions
+	// spend tickets and votes of a single owner by construction and are not
+	// clustered.
+	SelectClusterBlockTxs = `SELECT tx_hash, block_time, mix_count FROM transactions
+		WHERE block_height = $1 AND is_mainchain AND is_valid AND tree = 0
+		ORDER BY block_index;`
+
+	// SelectClusterTxsInputs selects the input addresses of the transactions
+	// in $1. The non-funding rows of a transaction are its spends.
+	SelectClusterTxsInputs = `SELECT tx_hash, address FROM addresses
+		WHERE tx_hash = ANY($1) AND NOT is_funding AND valid_mainchain;`
+
+	SelectClusterTxsOutputs = `SELECT tx_hash, address, value FROM addresses
+		WHERE tx_hash = ANY($1) AND is_funding AND valid_mainchain;`
+
+	// SelectFundedAddressesBefore selects which of the addresses in $1 were
+	// funded before the block time $2.
+	SelectFundedAddressesBefore = `SELECT DISTINCT address FROM addresses
+		WHERE address = ANY($1) AND is_funding AND valid_mainchain AND block_time < $2;`
+
+	// SelectClusterStats selects the balance and the first and last activity
+	// of cluster $1, or of the address $2 alone when it is not clustered.
+	SelectClusterStats = `SELECT
+			COALESCE(SUM(value) FILTER (WHERE is_funding AND matching_tx_hash = ''), 0),
+			MIN(block_time), MAX(block_time)
+		FROM addresses
+		WHERE address IN (
+				SELECT address FROM address_clusters WHERE chain = 'dcr' AND cluster_id = $1
+				UNION SELECT $2::TEXT)
+			AND valid_mainchain;`
+
+	// SelectCoSpentAddresses selects the addresses spent together with $1 in
+	// the clustered transactions, with the number of such transactions, most
+	// frequent first.
+	SelectCoSpentAddresses = `SELECT other.address, COUNT(DISTINCT other.tx_hash) AS txs
+		FROM addresses AS spend
+		JOIN cluster_txs ON cluster_txs.chain = 'dcr' AND cluster_txs.tx_hash = spend.tx_hash
+		JOIN addresses AS other ON other.tx_hash = spend.tx_hash
+			AND NOT other.is_funding AND other.valid_mainchain AND other.address != $1
+		WHERE spend.address = $1 AND NOT spend.is_funding AND spend.valid_mainchain
+		GROUP BY other.address
+		ORDER BY txs DESC, other.address
+		LIMIT $2;`
+)
@@ -0,0 +1,66 @@
+package internal
+
+// These queries relate to the "exchange_candlesticks",
+// "exchange_depth_snapshots" and "exchange_price_ticks" tables, the market
+// history of the ExchangeBot.
+const (
+	CreateExchangeCandlesticksTable = `CREATE TABLE IF NOT EXISTS exchange_candlesticks (
+		chain TEXT NOT NULL,
+		token TEXT NOT NULL,
+		bin TEXT NOT NULL,
+		start TIMESTAMPTZ NOT NULL,
+		open FLOAT8,
+		high FLOAT8,
+		low FLOAT8,
+		close FLOAT8,
+		volume FLOAT8,
+		PRIMARY KEY (chain, token, bin, start)
+	);`
+
+	UpsertExchangeCandlestick = `INSERT INTO exchange_candlesticks (chain, token, bin, start, open, high, low, close, volume)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (chain, token, bin, start) DO UPDATE
+		SET open = $5, high = $6, low = $7, close = $8, volume = $9;`
+
+	SelectExchangeCandlesticks = `SELECT start, open, high, low, close, volume
+		FROM exchange_candlesticks
+		WHERE chain = $1 AND token = $2 AND bin = $3 AND start >= $4 AND start <= $5
+		ORDER BY start;`
+
+	SelectLastExchangeCandlesticks = `SELECT start, open, high, low, close, volume
+		FROM (SELECT start, open, high, low, close, volume
+			FROM exchange_candlesticks
+			WHERE chain = $1 AND token = $2 AND bin = $3
+			ORDER BY start DESC LIMIT $4) AS last_sticks
+		ORDER BY start;`
+
+	CreateExchangeDepthSnapshotsTable = `CREATE TABLE IF NOT EXISTS exchange_depth_snapshots (
+		chain TEXT NOT NULL,
+		token TEXT NOT NULL,
+		time TIMESTAMPTZ NOT NULL,
+		depth JSONB NOT NULL,
+		PRIMARY KEY (chain, token, time)
+	);`
+
+	InsertExchangeDepthSnapshot = `INSERT INTO exchange_depth_snapshots (chain, token, time, depth)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (chain, token, time) DO NOTHING;`
+
+	CreateExchangePriceTicksTable = `CREATE TABLE IF NOT EXISTS exchange_price_ticks (
+		chain TEXT NOT NULL,
+		token TEXT NOT NULL,
+		time TIMESTAMPTZ NOT NULL,
+		price FLOAT8,
+		base_volume FLOAT8,
+		volume FLOAT8,
+		change FLOAT8,
+		PRIMARY KEY (chain, token, time)
+	);`
+
+	InsertExchangePriceTick = `INSERT INTO exchange_price_ticks (chain, token, time, price, base_volume, volume, change)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (chain, token, time) DO NOTHING;`
+
+	DeleteOldExchangePriceTicks = `DELETE FROM exchange_price_ticks
+		WHERE chain = $1 AND token = $2 AND time < $3;`
+)
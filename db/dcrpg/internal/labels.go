@@ -0,0 +1,26 @@
+package internal
+
+// These queries relate to the "address_labels" table of the labels added by
+// the operators of a dcrdata instance. The labels shipped in labels.json are
+// not stored. The table is shared by all chains, with a chain column. Times
+// are UNIX seconds.
+const (
+	CreateAddressLabelsTable = `CREATE TABLE IF NOT EXISTS address_labels (
+		chain TEXT NOT NULL,
+		address TEXT NOT NULL,
+		name TEXT NOT NULL,
+		category TEXT NOT NULL,
+		link TEXT NOT NULL DEFAULT '',
+		updated INT8 NOT NULL,
+		PRIMARY KEY (chain, address)
+	);`
+
+	SelectAddressLabels = `SELECT chain, address, name, category, link FROM address_labels;`
+
+	UpsertAddressLabel = `INSERT INTO address_labels (chain, address, name, category, link, updated)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (chain, address) DO UPDATE SET name = EXCLUDED.name,
+			category = EXCLUDED.category, link = EXCLUDED.link, updated = EXCLUDED.updated;`
+
+	DeleteAddressLabel = `DELETE FROM address_labels WHERE chain = $1 AND address = $2;`
+)
@@ -0,0 +1,171 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/decred/dcrd/blockchain/stake/v5"
+)
+
+// These queries relate to the "mixing_stats", "mixing_denoms" and
+// "mix_rounds" tables of the mixing analytics, and to the "transactions",
+// "vins" and "vouts" tables they are computed from. A mix is a transaction
+// with mix_denom one of the mix denominations, passed as an INT8 array. Mixed
+// split transactions also have mix_count and mix_denom set, but their
+// mix_denom is a ticket price plus fee.
+const (
+	// CreateMixingStatsTable creates a table of mixing statistics with a row
+	// for every main chain block. participants is the sum over the mixes of
+	// their estimated number of peers. spent and spend_secs are the number of
+	// mix outputs spent in the block and the sum of the seconds from their
+	// mix to the spend. remixed is the value of the mixed outputs spent by
+	// mixes.
+	CreateMixingStatsTable = `CREATE TABLE IF NOT EXISTS mixing_stats (
+		height INT8 PRIMARY KEY,
+		time TIMESTAMPTZ NOT NULL,
+		mixes INT4 NOT NULL,
+		participants INT4 NOT NULL,
+		mixed INT8 NOT NULL,
+		remixed INT8 NOT NULL,
+		spent INT4 NOT NULL,
+		spend_secs INT8 NOT NULL,
+		tickets INT4 NOT NULL,
+		mixed_tickets INT4 NOT NULL
+	);`
+
+	// CreateMixingDenomsTable creates a table of the number of mixes and the
+	// mixed value of each denomination mixed in a block.
+	CreateMixingDenomsTable = `CREATE TABLE IF NOT EXISTS mixing_denoms (
+		height INT8 NOT NULL,
+		denom INT8 NOT NULL,
+		mixes INT4 NOT NULL,
+		mixed INT8 NOT NULL,
+		PRIMARY KEY (height, denom)
+	);`
+
+	// CreateMixRoundsTable creates a table of the re-mix round of each mix. A
+	// mix that spends no outputs of other mixes is round 1, otherwise its
+	// round follows the highest round of the mixes it spends.
+	CreateMixRoundsTable = `CREATE TABLE IF NOT EXISTS mix_rounds (
+		tx_hash TEXT PRIMARY KEY,
+		height INT8 NOT NULL,
+		round INT4 NOT NULL,
+		mixed INT8 NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_mix_rounds_height ON mix_rounds (height);`
+
+	SelectMixingStatsMaxHeight = `SELECT COALESCE(MAX(height), -1) FROM mixing_stats;`
+
+	DeleteMixingStatsFrom  = `DELETE FROM mixing_stats WHERE height >= $1;`
+	DeleteMixingDenomsFrom = `DELETE FROM mixing_denoms WHERE height >= $1;`
+	DeleteMixRoundsFrom    = `DELETE FROM mix_rounds WHERE height >= $1;`
+
+	InsertMixingStatsRow = `INSERT INTO mixing_stats (height, time, mixes,
+		participants, mixed, remixed, spent, spend_secs, tickets, mixed_tickets)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);`
+
+	InsertMixingDenomsRow = `INSERT INTO mixing_denoms (height, denom, mixes, mixed)
+		VALUES ($1, $2, $3, $4);`
+
+	UpsertMixRoundRow = `INSERT INTO mix_rounds (tx_hash, height, round, mixed)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tx_hash) DO UPDATE
+		SET height = EXCLUDED.height, round = EXCLUDED.round, mixed = EXCLUDED.mixed;`
+
+	// SelectMixingBlocks selects the main chain blocks from height $1 to $2.
+	SelectMixingBlocks = `SELECT height, time FROM blocks
+		WHERE is_mainchain AND height BETWEEN $1 AND $2
+		ORDER BY height;`
+
+	// SelectMixesInRange selects the main chain mixes from height $1 to $2,
+	// in block order.
+	SelectMixesInRange = `SELECT tx_hash, block_height, mix_denom, mix_count, num_vout
+		FROM transactions
+		WHERE is_mainchain AND block_height BETWEEN $1 AND $2
+			AND mix_count > 0 AND mix_denom = ANY($3)
+		ORDER BY block_height, block_index;`
+
+	// SelectMixesMixedInputs selects the funding transactions of the mixed
+	// outputs spent by the mixes in $1.
+	SelectMixesMixedInputs = `SELECT DISTINCT vins.tx_hash, vins.prev_tx_hash
+		FROM vins
+		JOIN vouts ON vouts.tx_hash = vins.prev_tx_hash
+			AND vouts.tx_index = vins.prev_tx_index
+			AND vouts.tx_tree = vins.prev_tx_tree
+		WHERE vins.tx_hash = ANY($1) AND vins.is_mainchain AND vouts.mixed;`
+
+	SelectMixRounds = `SELECT tx_hash, round FROM mix_rounds WHERE tx_hash = ANY($1);`
+
+	// SelectMixedSpendsInRange selects, by main chain block from height $1 to
+	// $2, the number of mix outputs spent, the sum of the seconds from their
+	// mix to the spend, and the value of those spent by mixes.
+	SelectMixedSpendsInRange = `SELECT spend_tx.block_height, COUNT(*),
+			COALESCE(SUM(EXTRACT(EPOCH FROM (spend_tx.block_time - fund_tx.block_time))), 0)::INT8,
+			COALESCE(SUM(vouts.value) FILTER (WHERE spend_tx.mix_count > 0
+				AND spend_tx.mix_denom = ANY($3)), 0)::INT8
+		FROM transactions AS spend_tx
+		JOIN vins ON vins.tx_hash = spend_tx.tx_hash
+			AND vins.tx_tree = spend_tx.tree AND vins.is_mainchain
+		JOIN vouts ON vouts.tx_hash = vins.prev_tx_hash
+			AND vouts.tx_index = vins.prev_tx_index
+			AND vouts.tx_tree = vins.prev_tx_tree
+		JOIN transactions AS fund_tx ON fund_tx.tx_hash = vouts.tx_hash
+			AND fund_tx.is_mainchain
+		WHERE spend_tx.is_mainchain AND spend_tx.block_height BETWEEN $1 AND $2
+			AND vouts.mixed AND fund_tx.mix_count > 0 AND fund_tx.mix_denom = ANY($3)
+		GROUP BY spend_tx.block_height;`
+
+	// SelectMixingChartRows selects the mixing statistics above height $1,
+	// with the mixed value of each denomination and of each round, with
+	// rounds from 4 combined.
+	SelectMixingChartRows = `SELECT s.height, s.time, s.mixes, s.participants,
+			s.spent, s.spend_secs, s.tickets, s.mixed_tickets,
+			COALESCE(d.denoms, '{}'), COALESCE(d.mixed, '{}'),
+			COALESCE(r.rounds, '{}'), COALESCE(r.mixed, '{}')
+		FROM mixing_stats AS s
+		LEFT JOIN (
+			SELECT height, array_agg(denom) AS denoms, array_agg(mixed) AS mixed
+			FROM mixing_denoms WHERE height > $1
+			GROUP BY height
+		) AS d ON d.height = s.height
+		LEFT JOIN (
+			SELECT height, array_agg(round) AS rounds, array_agg(mixed) AS mixed
+			FROM (
+				SELECT height, LEAST(round, 4) AS round, SUM(mixed)::INT8 AS mixed
+				FROM mix_rounds WHERE height > $1
+				GROUP BY height, LEAST(round, 4)
+			) AS by_round
+			GROUP BY height
+		) AS r ON r.height = s.height
+		WHERE s.height > $1
+		ORDER BY s.height;`
+
+	// SelectMixingSummary sums the mixing statistics above height $1.
+	SelectMixingSummary = `SELECT COALESCE(SUM(mixes), 0), COALESCE(SUM(participants), 0),
+			COALESCE(SUM(mixed), 0), COALESCE(SUM(remixed), 0),
+			COALESCE(SUM(spent), 0), COALESCE(SUM(spend_secs), 0),
+			COALESCE(SUM(tickets), 0), COALESCE(SUM(mixed_tickets), 0)
+		FROM mixing_stats WHERE height > $1;`
+
+	SelectMixingDenomsSummary = `SELECT denom, SUM(mixes), SUM(mixed)::INT8
+		FROM mixing_denoms WHERE height > $1
+		GROUP BY denom ORDER BY denom;`
+
+	SelectMixRoundsSummary = `SELECT LEAST(round, 4), COUNT(*), SUM(mixed)::INT8
+		FROM mix_rounds WHERE height > $1
+		GROUP BY LEAST(round, 4) ORDER BY 1;`
+)
+
+// SelectTicketsInRange selects, by main chain block from height $1 to $2, the
+// number of tickets bought and the number of those funded by a mixed split
+// transaction, which is not a mix of the denominations in $3.
+var SelectTicketsInRange = fmt.Sprintf(`SELECT tx.block_height, COUNT(*),
+		COUNT(*) FILTER (WHERE EXISTS (
+			SELECT 1 FROM vins
+			JOIN transactions AS split ON split.tx_hash = vins.prev_tx_hash
+				AND split.is_mainchain
+			WHERE vins.tx_hash = tx.tx_hash AND vins.is_mainchain
+				AND split.mix_count > 0 AND split.mix_denom <> ALL($3)))
+	FROM transactions AS tx
+	WHERE tx.is_mainchain AND tx.block_height BETWEEN $1 AND $2
+		AND tx.tx_type = %d
+	GROUP BY tx.block_height;`, stake.TxTypeSStx)
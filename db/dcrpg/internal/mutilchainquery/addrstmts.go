@@ -161,6 +161,35 @@ LIMIT 1;`
 	SelectAddressUnspentCountAndValue = `SELECT COUNT(*), SUM(value) FROM %saddresses WHERE address=$1 and spending_tx_row_id IS NULL;`
 	SelectAddressSpentCountAndValue   = `SELECT COUNT(*), SUM(value) FROM %saddresses WHERE address=$1 and spending_tx_row_id IS NOT NULL;`
 
+	SelectAddressUnspentWithTxn = `SELECT %[1]saddresses.address, %[1]saddresses.funding_tx_hash,
+			%[1]saddresses.value, %[1]stransactions.block_height, %[1]stransactions.block_time,
+			%[1]saddresses.funding_tx_vout_index, %[1]svouts.pkscript
+		FROM %[1]saddresses
+		JOIN %[1]stransactions ON %[1]saddresses.funding_tx_row_id = %[1]stransactions.id
+		JOIN %[1]svouts ON %[1]saddresses.vout_row_id = %[1]svouts.id
+		WHERE %[1]saddresses.address=$1 AND %[1]saddresses.spending_tx_row_id IS NULL
+		ORDER BY %[1]stransactions.block_time DESC;`
+
+	// SelectUsedAddresses selects which of the addresses in $1 have received
+	// funds.
+	SelectUsedAddresses = `SELECT DISTINCT address FROM %saddresses WHERE address = ANY($1);`
+
+	// SelectAddressesTxs selects the latest $2 transactions funding or
+	// spending any of the addresses in $1, with the amounts received and sent
+	// by the addresses in each.
+	SelectAddressesTxs = `WITH io AS (
+			SELECT funding_tx_row_id AS tx_row_id, value AS received, 0 AS sent
+			FROM %[1]saddresses WHERE address = ANY($1)
+			UNION ALL
+			SELECT spending_tx_row_id, 0, value
+			FROM %[1]saddresses WHERE address = ANY($1) AND spending_tx_row_id IS NOT NULL)
+		SELECT %[1]stransactions.tx_hash, %[1]stransactions.block_time,
+			SUM(io.received), SUM(io.sent)
+		FROM io JOIN %[1]stransactions ON io.tx_row_id = %[1]stransactions.id
+		GROUP BY %[1]stransactions.id
+		ORDER BY %[1]stransactions.block_height DESC, %[1]stransactions.id DESC
+		LIMIT $2;`
+
 	addrsColumnNames                   = `id, address, funding_tx_row_id, funding_tx_hash, funding_tx_vout_index, vout_row_id, value, spending_tx_row_id, spending_tx_hash, spending_tx_vin_index, vin_row_id`
 	SelectAddressLimitNByAddress       = `SELECT ` + addrsColumnNames + ` FROM %saddresses WHERE address=$1 order by id desc limit $2 offset $3;`
 	SelectAddressLimitNByAddressSubQry = `WITH these as (SELECT * FROM %saddresses WHERE address=$1)
@@ -226,6 +255,18 @@ func MakeSelectCountTotalAddress(chainType string) string {
 	return fmt.Sprintf(SelectCountTotalAddress, chainType)
 }
 
+func MakeSelectAddressUnspentWithTxn(chainType string) string {
+	return fmt.Sprintf(SelectAddressUnspentWithTxn, chainType)
+}
+
+func MakeSelectUsedAddresses(chainType string) string {
+	return fmt.Sprintf(SelectUsedAddresses, chainType)
+}
+
+func MakeSelectAddressesTxs(chainType string) string {
+	return fmt.Sprintf(SelectAddressesTxs, chainType)
+}
+
 func MakeSelectAddressUnspentCountAndValue(chainType string) string {
 	return fmt.Sprintf(SelectAddressUnspentCountAndValue, chainType)
 }
@@ -0,0 +1,30 @@
+package mutilchainquery
+
+import "fmt"
+
+const (
+	// SelectBlockChartStats selects the per-block chart data above a height.
+	// The coinbase output total is NULL when the transactions of the block are
+	// not stored.
+	SelectBlockChartStats = `SELECT b.height, b.size, b.time, COALESCE(b.numtx, 0),
+		COALESCE(b.difficulty, 0), COALESCE(b.fees, 0), cb.sent
+	FROM %sblocks b
+	LEFT JOIN %stransactions cb
+		ON cb.block_height = b.height AND cb.block_hash = b.hash AND cb.block_index = 0
+	WHERE b.height > $1
+	ORDER BY b.height, b.id;`
+
+	// SelectMempoolHistoryAfter selects the mempool snapshots taken after a
+	// time. Day summary rows without a snapshot are skipped.
+	SelectMempoolHistoryAfter = `SELECT time, size, bytes FROM %smempool_history
+	WHERE time > $1 AND size IS NOT NULL
+	ORDER BY time;`
+)
+
+func MakeSelectBlockChartStats(chainType string) string {
+	return fmt.Sprintf(SelectBlockChartStats, chainType, chainType)
+}
+
+func MakeSelectMempoolHistoryAfter(chainType string) string {
+	return fmt.Sprintf(SelectMempoolHistoryAfter, chainType)
+}
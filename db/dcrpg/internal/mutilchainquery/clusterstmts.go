@@ -0,0 +1,89 @@
+package mutilchainquery
+
+import "fmt"
+
+// These queries compute the address clustering of the BTC and LTC tables. The
+// clusters are kept in the shared "address_clusters" and "cluster_txs" tables.
+const (
+	SelectClusterBlockTxs = `SELECT tx_hash FROM %stransactions
+		WHERE block_height = $1
+		ORDER BY block_index;`
+
+	// SelectClusterTxsInputs selects the input addresses of the transactions
+	// in $1 from the funding outpoints of their vins.
+	SelectClusterTxsInputs = `SELECT %[1]svins.tx_hash, %[1]saddresses.address
+		FROM %[1]svins
+		JOIN %[1]saddresses ON %[1]saddresses.funding_tx_hash = %[1]svins.prev_tx_hash
+			AND %[1]saddresses.funding_tx_vout_index = %[1]svins.prev_tx_index
+		WHERE %[1]svins.tx_hash = ANY($1);`
+
+	SelectClusterTxsOutputs = `SELECT funding_tx_hash, address, value FROM %saddresses
+		WHERE funding_tx_hash = ANY($1);`
+
+	// SelectFundedAddressesBefore selects which of the addresses in $1 were
+	// funded below the block height $2.
+	SelectFundedAddressesBefore = `SELECT DISTINCT %[1]saddresses.address
+		FROM %[1]saddresses
+		JOIN %[1]stransactions ON %[1]saddresses.funding_tx_row_id = %[1]stransactions.id
+		WHERE %[1]saddresses.address = ANY($1) AND %[1]stransactions.block_height < $2;`
+
+	// SelectClusterStats selects the balance and the first and last activity
+	// of cluster $2 of chain $1, or of the address $3 alone when it is not
+	// clustered.
+	SelectClusterStats = `SELECT
+			COALESCE(SUM(a.value) FILTER (WHERE a.spending_tx_row_id IS NULL), 0),
+			MIN(funding.block_time),
+			MAX(GREATEST(funding.block_time, spending.block_time))
+		FROM %[1]saddresses AS a
+		JOIN %[1]stransactions AS funding ON a.funding_tx_row_id = funding.id
+		LEFT JOIN %[1]stransactions AS spending ON a.spending_tx_row_id = spending.id
+		WHERE a.address IN (
+			SELECT address FROM address_clusters WHERE chain = $1 AND cluster_id = $2
+			UNION SELECT $3::TEXT);`
+
+	// SelectCoSpentAddresses selects the addresses spent together with $2 in
+	// the clustered transactions of chain $1, with the number of such
+	// transactions, most frequent first. The spends are found from the vins
+	// of the clustered transactions, as the spending transaction is not
+	// indexed.
+	SelectCoSpentAddresses = `WITH spends AS (
+			SELECT DISTINCT %[1]svins.tx_hash
+			FROM %[1]saddresses
+			JOIN %[1]svins ON %[1]svins.prev_tx_hash = %[1]saddresses.funding_tx_hash
+				AND %[1]svins.prev_tx_index = %[1]saddresses.funding_tx_vout_index
+			JOIN cluster_txs ON cluster_txs.chain = $1 AND cluster_txs.tx_hash = %[1]svins.tx_hash
+			WHERE %[1]saddresses.address = $2 AND %[1]saddresses.spending_tx_row_id IS NOT NULL)
+		SELECT %[1]saddresses.address, COUNT(DISTINCT spends.tx_hash) AS txs
+		FROM spends
+		JOIN %[1]svins ON %[1]svins.tx_hash = spends.tx_hash
+		JOIN %[1]saddresses ON %[1]saddresses.funding_tx_hash = %[1]svins.prev_tx_hash
+			AND %[1]saddresses.funding_tx_vout_index = %[1]svins.prev_tx_index
+		WHERE %[1]saddresses.address != $2
+		GROUP BY %[1]saddresses.address
+		ORDER BY txs DESC, %[1]saddresses.address
+		LIMIT $3;`
+)
+
+func MakeSelectClusterBlockTxs(chainType string) string {
+	return fmt.Sprintf(SelectClusterBlockTxs, chainType)
+}
+
+func MakeSelectClusterTxsInputs(chainType string) string {
+	return fmt.Sprintf(SelectClusterTxsInputs, chainType)
+}
+
+func MakeSelectClusterTxsOutputs(chainType string) string {
+	return fmt.Sprintf(SelectClusterTxsOutputs, chainType)
+}
+
+func MakeSelectFundedAddressesBefore(chainType string) string {
+	return fmt.Sprintf(SelectFundedAddressesBefore, chainType)
+}
+
+func MakeSelectClusterStats(chainType string) string {
+	return fmt.Sprintf(SelectClusterStats, chainType)
+}
+
+func MakeSelectCoSpentAddresses(chainType string) string {
+	return fmt.Sprintf(SelectCoSpentAddresses, chainType)
+}
@@ -0,0 +1,273 @@
+package mutilchainquery
+
+import "fmt"
+
+// The BTC and LTC coin age tables are updated one block at a time. The outputs
+// created and spent in a block are recorded in utxo_history, and the unspent
+// value is kept in utxo_age_buckets by creation time bucket, so that the age
+// bands and the mean coin age of a block are computed from the buckets rather
+// than the whole UTXO set.
+const (
+	CreateUtxoHistoryTable = `CREATE TABLE IF NOT EXISTS %sutxo_history (
+		tx_hash TEXT NOT NULL,
+		tx_index INT4 NOT NULL,
+		value INT8 NOT NULL,
+		create_time INT8 NOT NULL,
+		create_height INT8 NOT NULL,
+		spend_time INT8,
+		spend_height INT8,
+		PRIMARY KEY (tx_hash, tx_index)
+	);`
+
+	IndexUtxoHistoryOnCreateHeight = `CREATE INDEX IF NOT EXISTS uix_%sutxo_history_create_height
+		ON %sutxo_history(create_height);`
+	IndexUtxoHistoryOnSpendHeight = `CREATE INDEX IF NOT EXISTS uix_%sutxo_history_spend_height
+		ON %sutxo_history(spend_height);`
+
+	// CreateUtxoAgeBucketsTable stores the unspent value by creation time
+	// bucket, and the sum of the value times the creation time of each output.
+	CreateUtxoAgeBucketsTable = `CREATE TABLE IF NOT EXISTS %sutxo_age_buckets (
+		bucket INT8 PRIMARY KEY,
+		value INT8 NOT NULL,
+		value_time NUMERIC NOT NULL
+	);`
+
+	CreateCoinAgeTable = `CREATE TABLE IF NOT EXISTS %scoin_age (
+		height INT8 PRIMARY KEY,
+		time INT8 NOT NULL,
+		coin_days_destroyed FLOAT8,
+		avg_coin_days FLOAT8
+	);`
+
+	CreateCoinAgeBandsTable = `CREATE TABLE IF NOT EXISTS %scoin_age_bands (
+		height INT8 NOT NULL,
+		time INT8 NOT NULL,
+		age_band TEXT NOT NULL,
+		value INT8,
+		PRIMARY KEY (height, age_band)
+	);`
+
+	// CreateMcaSnapshotsTable stores the mean coin age and the realized cap
+	// of each block. The realized cap is zero when there is no price data.
+	CreateMcaSnapshotsTable = `CREATE TABLE IF NOT EXISTS %smca_snapshots (
+		block_height INT8 PRIMARY KEY,
+		block_time INT8 NOT NULL,
+		total_coin_days FLOAT8,
+		total_supply INT8,
+		mean_coin_age FLOAT8,
+		realized_cap FLOAT8
+	);`
+
+	// CreateDailyMarketTable stores the daily USD market data of a chain,
+	// like the Decred daily_market table.
+	CreateDailyMarketTable = `CREATE TABLE IF NOT EXISTS %sdaily_market (
+		date INT8 PRIMARY KEY,
+		volume FLOAT8,
+		open FLOAT8,
+		high FLOAT8,
+		low FLOAT8,
+		close FLOAT8
+	);`
+
+	UpsertDailyMarketRow = `INSERT INTO %sdaily_market (date, volume, open, high, low, close)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (date) DO UPDATE SET volume = $2, open = $3, high = $4, low = $5, close = $6;`
+
+	SelectLastDailyMarketDate = `SELECT COALESCE(MAX(date), 0) FROM %sdaily_market;`
+
+	SelectCoinAgeMaxHeight = `SELECT COALESCE(MAX(height), -1) FROM %scoin_age;`
+
+	// SelectCoinAgeBlock selects the hash and time of the mainchain block at
+	// a height, its number of transactions and the number of them stored.
+	SelectCoinAgeBlock = `SELECT b.hash, b.time, COALESCE(b.numtx, 0),
+		(SELECT COUNT(*) FROM %[1]stransactions t WHERE t.block_height = b.height AND t.block_hash = b.hash)
+	FROM %[1]sblocks b
+	WHERE b.height = $1
+	ORDER BY b.id DESC LIMIT 1;`
+
+	// InsertUtxoHistoryCreated records the outputs created in a block.
+	InsertUtxoHistoryCreated = `INSERT INTO %[1]sutxo_history (tx_hash, tx_index, value, create_time, create_height)
+	SELECT v.tx_hash, v.tx_index, v.value, $3::INT8, $1::INT8
+	FROM %[1]stransactions t
+	JOIN %[1]svouts v ON v.tx_hash = t.tx_hash
+	WHERE t.block_height = $1 AND t.block_hash = $2 AND v.value > 0
+	ON CONFLICT (tx_hash, tx_index) DO NOTHING;`
+
+	// UpdateUtxoHistorySpent records the outputs spent in a block.
+	UpdateUtxoHistorySpent = `UPDATE %[1]sutxo_history u SET spend_time = $3::INT8, spend_height = $1::INT8
+	FROM %[1]stransactions t
+	JOIN %[1]svins i ON i.tx_hash = t.tx_hash
+	WHERE t.block_height = $1 AND t.block_hash = $2
+		AND u.tx_hash = i.prev_tx_hash AND u.tx_index = i.prev_tx_index
+		AND u.spend_height IS NULL;`
+
+	// AddUtxoAgeBuckets adds the outputs created in a block to their bucket
+	// of $2 seconds.
+	AddUtxoAgeBuckets = `INSERT INTO %[1]sutxo_age_buckets AS b (bucket, value, value_time)
+	SELECT create_time - create_time %% $2::INT8, SUM(value), SUM(value::NUMERIC * create_time)
+	FROM %[1]sutxo_history
+	WHERE create_height = $1
+	GROUP BY 1
+	ON CONFLICT (bucket) DO UPDATE SET value = b.value + EXCLUDED.value,
+		value_time = b.value_time + EXCLUDED.value_time;`
+
+	// SubtractUtxoAgeBuckets removes the outputs spent in a block from their
+	// bucket of $2 seconds.
+	SubtractUtxoAgeBuckets = `UPDATE %[1]sutxo_age_buckets b
+	SET value = b.value - s.value, value_time = b.value_time - s.value_time
+	FROM (
+		SELECT create_time - create_time %% $2::INT8 AS bucket, SUM(value) AS value,
+			SUM(value::NUMERIC * create_time) AS value_time
+		FROM %[1]sutxo_history
+		WHERE spend_height = $1
+		GROUP BY 1
+	) s
+	WHERE b.bucket = s.bucket;`
+
+	// InsertCoinAge computes the coin days destroyed in a block and the
+	// average age in days of the spent outputs.
+	InsertCoinAge = `INSERT INTO %[1]scoin_age (height, time, coin_days_destroyed, avg_coin_days)
+	SELECT $1::INT8, $2::INT8,
+		COALESCE(SUM(value * FLOOR(GREATEST(spend_time - create_time, 0) / 86400.0)), 0),
+		COALESCE(SUM(value * FLOOR(GREATEST(spend_time - create_time, 0) / 86400.0)) / NULLIF(SUM(value), 0), 0)
+	FROM %[1]sutxo_history
+	WHERE spend_height = $1
+	ON CONFLICT (height) DO NOTHING;`
+
+	// InsertCoinAgeBands computes the unspent value by age band at a block.
+	// The age of a bucket is the time since the start of the bucket.
+	InsertCoinAgeBands = `INSERT INTO %[1]scoin_age_bands (height, time, age_band, value)
+	SELECT $1::INT8, $2::INT8,
+		CASE
+			WHEN $2::INT8 - bucket < 86400 THEN '<1d'
+			WHEN $2::INT8 - bucket < 7 * 86400 THEN '1d-1w'
+			WHEN $2::INT8 - bucket < 30 * 86400 THEN '1w-1m'
+			WHEN $2::INT8 - bucket < 180 * 86400 THEN '1m-6m'
+			WHEN $2::INT8 - bucket < 365 * 86400 THEN '6m-1y'
+			WHEN $2::INT8 - bucket < 2 * 365 * 86400 THEN '1y-2y'
+			WHEN $2::INT8 - bucket < 3 * 365 * 86400 THEN '2y-3y'
+			WHEN $2::INT8 - bucket < 5 * 365 * 86400 THEN '3y-5y'
+			WHEN $2::INT8 - bucket < 7 * 365 * 86400 THEN '5y-7y'
+			ELSE '>7y'
+		END AS age_band,
+		SUM(value)
+	FROM %[1]sutxo_age_buckets
+	WHERE value > 0
+	GROUP BY age_band
+	ON CONFLICT (height, age_band) DO NOTHING;`
+
+	// InsertMcaSnapshot computes the total and mean coin days of the unspent
+	// outputs at a block, and the realized cap, which values each output at
+	// the daily close price of its creation day. Outputs created after the
+	// last daily price are valued at that price, and outputs created before
+	// the first one at zero.
+	InsertMcaSnapshot = `INSERT INTO %[1]smca_snapshots (block_height, block_time, total_coin_days,
+		total_supply, mean_coin_age, realized_cap)
+	SELECT $1::INT8, $2::INT8, s.coin_days, s.supply, COALESCE(s.coin_days / NULLIF(s.supply, 0), 0), s.realized
+	FROM (
+		SELECT COALESCE(SUM(b.value::NUMERIC * $2::INT8 - b.value_time) / 86400, 0) AS coin_days,
+			COALESCE(SUM(b.value), 0) AS supply,
+			COALESCE(SUM(b.value * COALESCE(p.close, CASE WHEN b.bucket > l.date THEN l.close ELSE 0 END)) / 1e8, 0) AS realized
+		FROM %[1]sutxo_age_buckets b
+		LEFT JOIN %[1]sdaily_market p ON p.date = b.bucket - b.bucket %% 86400
+		LEFT JOIN (SELECT date, close FROM %[1]sdaily_market ORDER BY date DESC LIMIT 1) l ON TRUE
+		WHERE b.value > 0
+	) s
+	ON CONFLICT (block_height) DO NOTHING;`
+
+	// SelectCoinAgeChartRows selects the coin age chart data above a height.
+	SelectCoinAgeChartRows = `SELECT c.height, c.time, c.coin_days_destroyed, c.avg_coin_days,
+		m.total_coin_days, m.mean_coin_age, m.realized_cap
+	FROM %[1]scoin_age c
+	JOIN %[1]smca_snapshots m ON m.block_height = c.height
+	WHERE c.height > $1
+	ORDER BY c.height;`
+
+	SelectCoinAgeBandsChartRows = `SELECT height, age_band, value FROM %scoin_age_bands
+	WHERE height > $1
+	ORDER BY height;`
+)
+
+func CreateUtxoHistoryTableFunc(chainType string) string {
+	return fmt.Sprintf(CreateUtxoHistoryTable, chainType)
+}
+
+func CreateUtxoAgeBucketsTableFunc(chainType string) string {
+	return fmt.Sprintf(CreateUtxoAgeBucketsTable, chainType)
+}
+
+func CreateCoinAgeTableFunc(chainType string) string {
+	return fmt.Sprintf(CreateCoinAgeTable, chainType)
+}
+
+func CreateCoinAgeBandsTableFunc(chainType string) string {
+	return fmt.Sprintf(CreateCoinAgeBandsTable, chainType)
+}
+
+func CreateMcaSnapshotsTableFunc(chainType string) string {
+	return fmt.Sprintf(CreateMcaSnapshotsTable, chainType)
+}
+
+func CreateDailyMarketTableFunc(chainType string) string {
+	return fmt.Sprintf(CreateDailyMarketTable, chainType)
+}
+
+func MakeIndexUtxoHistoryOnCreateHeight(chainType string) string {
+	return fmt.Sprintf(IndexUtxoHistoryOnCreateHeight, chainType, chainType)
+}
+
+func MakeIndexUtxoHistoryOnSpendHeight(chainType string) string {
+	return fmt.Sprintf(IndexUtxoHistoryOnSpendHeight, chainType, chainType)
+}
+
+func MakeUpsertDailyMarketRow(chainType string) string {
+	return fmt.Sprintf(UpsertDailyMarketRow, chainType)
+}
+
+func MakeSelectLastDailyMarketDate(chainType string) string {
+	return fmt.Sprintf(SelectLastDailyMarketDate, chainType)
+}
+
+func MakeSelectCoinAgeMaxHeight(chainType string) string {
+	return fmt.Sprintf(SelectCoinAgeMaxHeight, chainType)
+}
+
+func MakeSelectCoinAgeBlock(chainType string) string {
+	return fmt.Sprintf(SelectCoinAgeBlock, chainType)
+}
+
+func MakeInsertUtxoHistoryCreated(chainType string) string {
+	return fmt.Sprintf(InsertUtxoHistoryCreated, chainType)
+}
+
+func MakeUpdateUtxoHistorySpent(chainType string) string {
+	return fmt.Sprintf(UpdateUtxoHistorySpent, chainType)
+}
+
+func MakeAddUtxoAgeBuckets(chainType string) string {
+	return fmt.Sprintf(AddUtxoAgeBuckets, chainType)
+}
+
+func MakeSubtractUtxoAgeBuckets(chainType string) string {
+	return fmt.Sprintf(SubtractUtxoAgeBuckets, chainType)
+}
+
+func MakeInsertCoinAge(chainType string) string {
+	return fmt.Sprintf(InsertCoinAge, chainType)
+}
+
+func MakeInsertCoinAgeBands(chainType string) string {
+	return fmt.Sprintf(InsertCoinAgeBands, chainType)
+}
+
+func MakeInsertMcaSnapshot(chainType string) string {
+	return fmt.Sprintf(InsertMcaSnapshot, chainType)
+}
+
+func MakeSelectCoinAgeChartRows(chainType string) string {
+	return fmt.Sprintf(SelectCoinAgeChartRows, chainType)
+}
+
+func MakeSelectCoinAgeBandsChartRows(chainType string) string {
+	return fmt.Sprintf(SelectCoinAgeBandsChartRows, chainType)
+}
@@ -0,0 +1,50 @@
+package mutilchainquery
+
+import "fmt"
+
+// These queries relate to the "coinjoins" tables of the BTC and LTC CoinJoin
+// transactions, detected by txhelpers.DetectCoinJoin when the transactions are
+// stored.
+const (
+	// CreateCoinJoinsTable creates the table of the CoinJoin transactions.
+	// participants is the number of outputs of the denomination, and mixed the
+	// total value of the equal outputs.
+	CreateCoinJoinsTable = `CREATE TABLE IF NOT EXISTS %[1]scoinjoins (
+		tx_hash TEXT PRIMARY KEY,
+		block_height INT8 NOT NULL,
+		block_time INT8 NOT NULL,
+		mix_type TEXT NOT NULL,
+		denom INT8 NOT NULL,
+		participants INT4 NOT NULL,
+		mixed INT8 NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_%[1]scoinjoins_block_height ON %[1]scoinjoins (block_height);`
+
+	// UpsertCoinJoin inserts a CoinJoin, moving it to the block of $2 when it
+	// was mined again after a reorganization.
+	UpsertCoinJoin = `INSERT INTO %scoinjoins (tx_hash, block_height, block_time, mix_type,
+			denom, participants, mixed)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tx_hash) DO UPDATE
+		SET block_height = EXCLUDED.block_height, block_time = EXCLUDED.block_time;`
+
+	// SelectCoinJoinsPerBlock sums the mixed value and the participants of the
+	// CoinJoins of each block above a height.
+	SelectCoinJoinsPerBlock = `SELECT block_height, SUM(mixed), SUM(participants)
+		FROM %scoinjoins
+		WHERE block_height > $1
+		GROUP BY block_height
+		ORDER BY block_height;`
+)
+
+func CreateCoinJoinsTableFunc(chainType string) string {
+	return fmt.Sprintf(CreateCoinJoinsTable, chainType)
+}
+
+func MakeUpsertCoinJoin(chainType string) string {
+	return fmt.Sprintf(UpsertCoinJoin, chainType)
+}
+
+func MakeSelectCoinJoinsPerBlock(chainType string) string {
+	return fmt.Sprintf(SelectCoinJoinsPerBlock, chainType)
+}
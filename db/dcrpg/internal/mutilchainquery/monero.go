@@ -81,6 +81,10 @@ const (
 
 	SelectTotalXmrInputs = `SELECT COUNT(*) FROM monero_key_images;`
 
+	SelectMoneroKeyImageTx = `SELECT COALESCE(spent_tx_hash, first_seen_tx_hash, ''),
+		COALESCE(spent_block_height, first_seen_block_height, 0)
+		FROM monero_key_images WHERE key_image = $1;`
+
 	CheckAndRemoveDuplicateMoneroKeyImageRows = `WITH duplicates AS (
   		SELECT id, row_number() OVER (PARTITION BY key_image ORDER BY id) AS rn
   		FROM public.monero_key_images
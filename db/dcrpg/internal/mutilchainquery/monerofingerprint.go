@@ -0,0 +1,64 @@
+package mutilchainquery
+
+const (
+	// CreateMoneroTxFingerprintsTable stores the wallet fingerprint of each
+	// non-coinbase transaction. The flags are those of xmrfingerprint.Flags.
+	CreateMoneroTxFingerprintsTable = `CREATE TABLE IF NOT EXISTS monero_tx_fingerprints (
+		id SERIAL8 PRIMARY KEY,
+		tx_hash TEXT NOT NULL UNIQUE,
+		block_height INT8 NOT NULL,
+		block_time INT8 NOT NULL,
+		version INT4 NOT NULL,
+		rct_type INT4 NOT NULL,
+		inputs INT4 NOT NULL,
+		outputs INT4 NOT NULL,
+		ring_size INT4 NOT NULL,
+		size INT4 NOT NULL,
+		fee INT8 NOT NULL,
+		fee_per_byte FLOAT8 NOT NULL,
+		unlock_time INT8 NOT NULL,
+		extra_size INT4 NOT NULL,
+		extra_tags TEXT NOT NULL,
+		payment_id TEXT NOT NULL,     -- '', encrypted or unencrypted
+		additional_pubkeys INT4 NOT NULL,
+		flags TEXT[] NOT NULL
+	);`
+
+	UpsertMoneroTxFingerprint = `INSERT INTO monero_tx_fingerprints (tx_hash, block_height, block_time,
+			version, rct_type, inputs, outputs, ring_size, size, fee, fee_per_byte, unlock_time, extra_size,
+			extra_tags, payment_id, additional_pubkeys, flags)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		ON CONFLICT (tx_hash) DO UPDATE SET
+			block_height = EXCLUDED.block_height,
+			block_time = EXCLUDED.block_time,
+			fee_per_byte = EXCLUDED.fee_per_byte,
+			flags = EXCLUDED.flags;`
+
+	IndexMoneroTxFingerprintsOnBlockHeight = `CREATE INDEX IF NOT EXISTS uix_monero_tx_fingerprints_block_height
+		ON monero_tx_fingerprints(block_height);`
+	DeindexMoneroTxFingerprintsOnBlockHeight = `DROP INDEX IF EXISTS uix_monero_tx_fingerprints_block_height;`
+
+	IndexMoneroTxFingerprintsOnFlags = `CREATE INDEX IF NOT EXISTS uix_monero_tx_fingerprints_flags
+		ON monero_tx_fingerprints USING GIN (flags);`
+	DeindexMoneroTxFingerprintsOnFlags = `DROP INDEX IF EXISTS uix_monero_tx_fingerprints_flags;`
+
+	selectMoneroTxFingerprintColumns = `SELECT tx_hash, block_height, block_time, version, rct_type, inputs,
+			outputs, ring_size, size, fee, fee_per_byte, unlock_time, extra_size, extra_tags, payment_id,
+			additional_pubkeys, flags
+		FROM monero_tx_fingerprints`
+
+	SelectMoneroTxFingerprint = selectMoneroTxFingerprintColumns + ` WHERE tx_hash = $1;`
+
+	SelectMoneroBlockTxFingerprints = selectMoneroTxFingerprintColumns + `
+		WHERE block_height = $1
+		ORDER BY id;`
+
+	// SelectMoneroTxFingerprintsFiltered selects the fingerprints with all the
+	// flags of $3 in the height range [$1, $2], most recent first.
+	SelectMoneroTxFingerprintsFiltered = selectMoneroTxFingerprintColumns + `
+		WHERE block_height BETWEEN $1 AND $2 AND flags @> $3::TEXT[]
+		ORDER BY block_height DESC, id DESC
+		LIMIT $4 OFFSET $5;`
+
+	DeleteMoneroTxFingerprintsWithTxhashArray = `DELETE FROM monero_tx_fingerprints WHERE tx_hash = ANY($1)`
+)
@@ -0,0 +1,109 @@
+package mutilchainquery
+
+const (
+	// CreateMoneroRingAnalysisTable stores the ring analysis of each input.
+	// The ring members are the indices among the outputs of the input amount,
+	// which is zero for RingCT inputs, and the heights of the blocks that
+	// created them, -1 when unknown.
+	CreateMoneroRingAnalysisTable = `CREATE TABLE IF NOT EXISTS monero_ring_analysis (
+		id SERIAL8 PRIMARY KEY,
+		tx_hash TEXT NOT NULL,
+		tx_input_index INT4 NOT NULL,
+		block_height INT8 NOT NULL,
+		amount INT8 NOT NULL,
+		members INT8[] NOT NULL,
+		member_heights INT8[] NOT NULL,
+		ring_size INT4 NOT NULL,
+		effective_ring_size INT4 NOT NULL,
+		before_creation INT4 NOT NULL,
+		spent_before INT4 NOT NULL,
+		deduced_index INT8,   -- index of the deduced real spend
+		deduction TEXT,       -- zero-mixin or chain-reaction
+		median_age INT8,      -- seconds
+		UNIQUE (tx_hash, tx_input_index)
+	);`
+
+	IndexMoneroRingAnalysisOnMembers = `CREATE INDEX IF NOT EXISTS idx_monero_ring_analysis_members
+		ON monero_ring_analysis USING GIN (members);`
+	IndexMoneroRingAnalysisOnBlockHeight = `CREATE INDEX IF NOT EXISTS idx_monero_ring_analysis_block_height
+		ON monero_ring_analysis(block_height);`
+
+	UpsertMoneroRingAnalysis = `INSERT INTO monero_ring_analysis (tx_hash, tx_input_index, block_height,
+			amount, members, member_heights, ring_size, effective_ring_size, before_creation, spent_before,
+			deduced_index, deduction, median_age)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (tx_hash, tx_input_index) DO UPDATE SET
+			effective_ring_size = EXCLUDED.effective_ring_size,
+			before_creation = EXCLUDED.before_creation,
+			spent_before = EXCLUDED.spent_before,
+			deduced_index = EXCLUDED.deduced_index,
+			deduction = EXCLUDED.deduction;`
+
+	// SelectMoneroRingsReferencing selects the inputs without a deduced real
+	// spend whose rings reference an output.
+	SelectMoneroRingsReferencing = `SELECT tx_hash, tx_input_index, block_height, members, member_heights
+		FROM monero_ring_analysis
+		WHERE amount = $1 AND members @> ARRAY[$2]::INT8[] AND deduced_index IS NULL;`
+
+	DeleteMoneroRingAnalysisAboveHeight = `DELETE FROM monero_ring_analysis WHERE block_height > $1;`
+
+	// CreateMoneroDeducedSpendsTable stores the deduced real spend of each
+	// output.
+	CreateMoneroDeducedSpendsTable = `CREATE TABLE IF NOT EXISTS monero_deduced_spends (
+		amount INT8 NOT NULL,
+		global_index INT8 NOT NULL,
+		tx_hash TEXT NOT NULL,
+		tx_input_index INT4 NOT NULL,
+		block_height INT8 NOT NULL,
+		PRIMARY KEY (amount, global_index)
+	);`
+
+	InsertMoneroDeducedSpend = `INSERT INTO monero_deduced_spends (amount, global_index, tx_hash,
+			tx_input_index, block_height)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (amount, global_index) DO NOTHING;`
+
+	SelectMoneroDeducedSpends = `SELECT global_index, tx_hash, tx_input_index, block_height
+		FROM monero_deduced_spends
+		WHERE amount = $1 AND global_index = ANY($2);`
+
+	DeleteMoneroDeducedSpendsAboveHeight = `DELETE FROM monero_deduced_spends WHERE block_height > $1;`
+
+	// CreateMoneroRingStatsTable stores the ring analysis totals of each
+	// block as of its analysis. The age bands are the number of ring members
+	// in each band of xmrring.AgeBandLabels.
+	CreateMoneroRingStatsTable = `CREATE TABLE IF NOT EXISTS monero_ring_stats (
+		block_height INT8 PRIMARY KEY,
+		block_time INT8 NOT NULL,
+		inputs INT4 NOT NULL,
+		ring_size_sum INT8 NOT NULL,
+		effective_ring_size_sum INT8 NOT NULL,
+		deduced INT4 NOT NULL,
+		before_creation INT4 NOT NULL,
+		spent_before INT4 NOT NULL,
+		age_bands INT8[] NOT NULL
+	);`
+
+	UpsertMoneroRingStats = `INSERT INTO monero_ring_stats (block_height, block_time, inputs,
+			ring_size_sum, effective_ring_size_sum, deduced, before_creation, spent_before, age_bands)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (block_height) DO UPDATE SET
+			block_time = EXCLUDED.block_time,
+			inputs = EXCLUDED.inputs,
+			ring_size_sum = EXCLUDED.ring_size_sum,
+			effective_ring_size_sum = EXCLUDED.effective_ring_size_sum,
+			deduced = EXCLUDED.deduced,
+			before_creation = EXCLUDED.before_creation,
+			spent_before = EXCLUDED.spent_before,
+			age_bands = EXCLUDED.age_bands;`
+
+	SelectMoneroRingStatsMaxHeight = `SELECT COALESCE(MAX(block_height), -1) FROM monero_ring_stats;`
+
+	SelectMoneroRingStatsChartRows = `SELECT block_height, block_time, inputs, ring_size_sum,
+			effective_ring_size_sum, deduced, before_creation, spent_before, age_bands
+		FROM monero_ring_stats
+		WHERE block_height > $1
+		ORDER BY block_height;`
+
+	DeleteMoneroRingStatsAboveHeight = `DELETE FROM monero_ring_stats WHERE block_height > $1;`
+)
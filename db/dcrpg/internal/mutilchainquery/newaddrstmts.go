@@ -0,0 +1,71 @@
+package mutilchainquery
+
+import "fmt"
+
+// The BTC and LTC new address tables are updated one block at a time. The
+// height at which each address is first funded is kept in address_first_seen,
+// and the number of addresses first funded in each block in new_addresses, so
+// that the chart does not scan the addresses table.
+const (
+	CreateAddressFirstSeenTable = `CREATE TABLE IF NOT EXISTS %saddress_first_seen (
+		address TEXT PRIMARY KEY,
+		block_height INT8 NOT NULL
+	);`
+
+	CreateNewAddressesTable = `CREATE TABLE IF NOT EXISTS %snew_addresses (
+		height INT8 PRIMARY KEY,
+		count INT8 NOT NULL
+	);`
+
+	SelectNewAddressesMaxHeight = `SELECT COALESCE(MAX(height), -1) FROM %snew_addresses;`
+
+	// SelectNextBlockHeight selects the lowest height from $1 of a stored
+	// block, or -1 if there is none.
+	SelectNextBlockHeight = `SELECT COALESCE(MIN(height), -1) FROM %sblocks WHERE height >= $1;`
+
+	// InsertAddressFirstSeen records the addresses funded in the block at
+	// height $1 with hash $2 that were not funded in an earlier block.
+	InsertAddressFirstSeen = `INSERT INTO %[1]saddress_first_seen (address, block_height)
+	SELECT DISTINCT a.address, $1::INT8
+	FROM %[1]stransactions t
+	JOIN %[1]saddresses a ON a.funding_tx_hash = t.tx_hash
+	WHERE t.block_height = $1 AND t.block_hash = $2 AND a.address <> ''
+	ON CONFLICT (address) DO NOTHING;`
+
+	InsertNewAddresses = `INSERT INTO %snew_addresses (height, count) VALUES ($1, $2)
+	ON CONFLICT (height) DO NOTHING;`
+
+	// SelectNewAddressesChartRows selects the number of new addresses of the
+	// blocks above a height.
+	SelectNewAddressesChartRows = `SELECT height, count FROM %snew_addresses
+	WHERE height > $1
+	ORDER BY height;`
+)
+
+func CreateAddressFirstSeenTableFunc(chainType string) string {
+	return fmt.Sprintf(CreateAddressFirstSeenTable, chainType)
+}
+
+func CreateNewAddressesTableFunc(chainType string) string {
+	return fmt.Sprintf(CreateNewAddressesTable, chainType)
+}
+
+func MakeSelectNewAddressesMaxHeight(chainType string) string {
+	return fmt.Sprintf(SelectNewAddressesMaxHeight, chainType)
+}
+
+func MakeSelectNextBlockHeight(chainType string) string {
+	return fmt.Sprintf(SelectNextBlockHeight, chainType)
+}
+
+func MakeInsertAddressFirstSeen(chainType string) string {
+	return fmt.Sprintf(InsertAddressFirstSeen, chainType)
+}
+
+func MakeInsertNewAddresses(chainType string) string {
+	return fmt.Sprintf(InsertNewAddresses, chainType)
+}
+
+func MakeSelectNewAddressesChartRows(chainType string) string {
+	return fmt.Sprintf(SelectNewAddressesChartRows, chainType)
+}
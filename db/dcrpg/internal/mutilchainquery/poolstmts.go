@@ -0,0 +1,94 @@
+package mutilchainquery
+
+import "fmt"
+
+const (
+	// CreateBlockPoolsTable stores the mining pool attribution of each BTC or
+	// LTC mainchain block. The coinbase script and payout addresses are kept
+	// so that blocks may be attributed again when the pool definitions change.
+	CreateBlockPoolsTable = `CREATE TABLE IF NOT EXISTS %sblock_pools (
+		height INT8 PRIMARY KEY,
+		hash TEXT NOT NULL,
+		time INT8,
+		pool_slug TEXT NOT NULL,
+		pool_name TEXT NOT NULL,
+		coinbase_script BYTEA,
+		payout_addresses TEXT[],
+		reward INT8,
+		numtx INT4,
+		defs_version INT4
+	);`
+
+	IndexBlockPoolsTableOnTime = `CREATE INDEX IF NOT EXISTS uix_%sblock_pools_time
+		ON %sblock_pools(time);`
+
+	UpsertBlockPool = `INSERT INTO %sblock_pools (height, hash, time, pool_slug, pool_name,
+		coinbase_script, payout_addresses, reward, numtx, defs_version)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	ON CONFLICT (height) DO UPDATE SET hash = $2, time = $3, pool_slug = $4, pool_name = $5,
+		coinbase_script = $6, payout_addresses = $7, reward = $8, numtx = $9, defs_version = $10;`
+
+	UpdateBlockPoolAttribution = `UPDATE %sblock_pools SET pool_slug = $2, pool_name = $3,
+		defs_version = $4 WHERE height = $1;`
+
+	// SelectBlocksWithoutPool selects the blocks that have not been attributed,
+	// newest first. Blocks connected after a reorg replace the attribution at
+	// their height when they are stored.
+	SelectBlocksWithoutPool = `SELECT b.height, b.hash FROM %sblocks b
+		LEFT JOIN %sblock_pools p ON p.height = b.height
+		WHERE p.height IS NULL
+		ORDER BY b.height DESC LIMIT $1;`
+
+	SelectBlockPoolsByDefsVersion = `SELECT height, coinbase_script, payout_addresses
+		FROM %sblock_pools WHERE defs_version < $1 ORDER BY height LIMIT $2;`
+
+	SelectLastBlockPools = `SELECT height, pool_slug, pool_name, reward
+		FROM %sblock_pools WHERE height <= $1 ORDER BY height DESC LIMIT $2;`
+
+	// SelectPoolBlockCountsSince counts the blocks of each pool since a time,
+	// and the blocks with only the coinbase transaction.
+	SelectPoolBlockCountsSince = `SELECT pool_slug, COUNT(*),
+		SUM(CASE WHEN numtx <= 1 THEN 1 ELSE 0 END)
+		FROM %sblock_pools WHERE time >= $1 GROUP BY pool_slug;`
+
+	// SelectPoolBlockCountsByBin counts the blocks of each pool in time bins
+	// of $1 seconds.
+	SelectPoolBlockCountsByBin = `SELECT (time / $1) * $1 AS bin, pool_slug, COUNT(*)
+		FROM %sblock_pools GROUP BY bin, pool_slug ORDER BY bin;`
+)
+
+func CreateBlockPoolsTableFunc(chainType string) string {
+	return fmt.Sprintf(CreateBlockPoolsTable, chainType)
+}
+
+func MakeIndexBlockPoolsTableOnTime(chainType string) string {
+	return fmt.Sprintf(IndexBlockPoolsTableOnTime, chainType, chainType)
+}
+
+func MakeUpsertBlockPool(chainType string) string {
+	return fmt.Sprintf(UpsertBlockPool, chainType)
+}
+
+func MakeUpdateBlockPoolAttribution(chainType string) string {
+	return fmt.Sprintf(UpdateBlockPoolAttribution, chainType)
+}
+
+func MakeSelectBlocksWithoutPool(chainType string) string {
+	return fmt.Sprintf(SelectBlocksWithoutPool, chainType, chainType)
+}
+
+func MakeSelectBlockPoolsByDefsVersion(chainType string) string {
+	return fmt.Sprintf(SelectBlockPoolsByDefsVersion, chainType)
+}
+
+func MakeSelectLastBlockPools(chainType string) string {
+	return fmt.Sprintf(SelectLastBlockPools, chainType)
+}
+
+func MakeSelectPoolBlockCountsSince(chainType string) string {
+	return fmt.Sprintf(SelectPoolBlockCountsSince, chainType)
+}
+
+func MakeSelectPoolBlockCountsByBin(chainType string) string {
+	return fmt.Sprintf(SelectPoolBlockCountsByBin, chainType)
+}
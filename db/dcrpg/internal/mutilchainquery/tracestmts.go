@@ -0,0 +1,41 @@
+package mutilchainquery
+
+import "fmt"
+
+// These queries relate to the transaction flow tracing of the BTC and LTC
+// "transactions", "vins" and "vouts" tables. The spends of the outputs are
+// found from the vins, as the vouts do not reference them.
+const (
+	SelectTraceTx = `SELECT block_height, block_time FROM %stransactions
+		WHERE tx_hash = $1
+		LIMIT 1;`
+
+	SelectTraceTxOutputs = `SELECT %[1]svouts.tx_index, COALESCE(%[1]svouts.script_addresses[1], ''),
+			%[1]svouts.value, COALESCE(%[1]svins.tx_hash, '')
+		FROM %[1]svouts
+		LEFT JOIN %[1]svins ON %[1]svins.prev_tx_hash = %[1]svouts.tx_hash
+			AND %[1]svins.prev_tx_index = %[1]svouts.tx_index
+		WHERE %[1]svouts.tx_hash = $1
+		ORDER BY %[1]svouts.tx_index;`
+
+	SelectTraceTxInputs = `SELECT %[1]svins.prev_tx_hash, %[1]svins.prev_tx_index,
+			COALESCE(%[1]svouts.value, %[1]svins.value_in, 0),
+			COALESCE(%[1]svouts.script_addresses[1], '')
+		FROM %[1]svins
+		LEFT JOIN %[1]svouts ON %[1]svouts.tx_hash = %[1]svins.prev_tx_hash
+			AND %[1]svouts.tx_index = %[1]svins.prev_tx_index
+		WHERE %[1]svins.tx_hash = $1
+		ORDER BY %[1]svins.tx_index;`
+)
+
+func MakeSelectTraceTx(chainType string) string {
+	return fmt.Sprintf(SelectTraceTx, chainType)
+}
+
+func MakeSelectTraceTxOutputs(chainType string) string {
+	return fmt.Sprintf(SelectTraceTxOutputs, chainType)
+}
+
+func MakeSelectTraceTxInputs(chainType string) string {
+	return fmt.Sprintf(SelectTraceTxInputs, chainType)
+}
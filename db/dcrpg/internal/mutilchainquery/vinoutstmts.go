@@ -103,8 +103,9 @@ const (
 	SelectVoutIDByOutpoint = `SELECT id FROM %svouts WHERE tx_hash=$1 and tx_index=$2;`
 	SelectVoutByID         = `SELECT * FROM %svouts WHERE id=$1;`
 
-	RetrieveVoutValue  = `SELECT value FROM %svouts WHERE tx_hash=$1 and tx_index=$2;`
-	RetrieveVoutValues = `SELECT value, tx_index, tx_tree FROM %svouts WHERE tx_hash=$1;`
+	RetrieveVoutValue            = `SELECT value FROM %svouts WHERE tx_hash=$1 and tx_index=$2;`
+	RetrieveVoutValueAndPkScript = `SELECT value, pkscript FROM %svouts WHERE tx_hash=$1 and tx_index=$2;`
+	RetrieveVoutValues           = `SELECT value, tx_index, tx_tree FROM %svouts WHERE tx_hash=$1;`
 
 	IndexVoutTableOnTxHashIdx = `CREATE INDEX uix_%svout_txhash_ind
 		ON %svouts(tx_hash, tx_index);`
@@ -147,6 +148,10 @@ func MakeDeleteVoutsOfOlderThan20Blocks(chainType string) string {
 	return fmt.Sprintf(DeleteVoutsOfOlderThan20Blocks, chainType, chainType)
 }
 
+func MakeRetrieveVoutValueAndPkScript(chainType string) string {
+	return fmt.Sprintf(RetrieveVoutValueAndPkScript, chainType)
+}
+
 func MakeCountTotalVouts(chainType string) string {
 	return fmt.Sprintf(CountTotalVouts, chainType)
 }
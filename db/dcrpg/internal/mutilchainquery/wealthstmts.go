@@ -0,0 +1,30 @@
+package mutilchainquery
+
+import "fmt"
+
+// These queries compute the address balances of the BTC and LTC tables. The
+// balances are kept in the shared "address_balances" table.
+const (
+	// SelectWealthBlockDeltas selects the balance change of each address in
+	// the block at height $1 with hash $2, from the outputs it funds and the
+	// funding outpoints of its vins.
+	SelectWealthBlockDeltas = `SELECT address, SUM(delta)::INT8
+		FROM (
+			SELECT a.address, a.value AS delta
+			FROM %[1]stransactions AS t
+			JOIN %[1]saddresses AS a ON a.funding_tx_hash = t.tx_hash
+			WHERE t.block_height = $1 AND t.block_hash = $2
+			UNION ALL
+			SELECT a.address, -a.value
+			FROM %[1]stransactions AS t
+			JOIN %[1]svins AS i ON i.tx_hash = t.tx_hash
+			JOIN %[1]saddresses AS a ON a.funding_tx_hash = i.prev_tx_hash
+				AND a.funding_tx_vout_index = i.prev_tx_index
+			WHERE t.block_height = $1 AND t.block_hash = $2
+		) AS deltas
+		GROUP BY address;`
+)
+
+func MakeSelectWealthBlockDeltas(chainType string) string {
+	return fmt.Sprintf(SelectWealthBlockDeltas, chainType)
+}
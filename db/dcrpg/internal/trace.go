@@ -0,0 +1,43 @@
+package internal
+
+import "fmt"
+
+// These queries relate to the transaction flow tracing, from the Decred
+// "transactions", "vins" and "vouts" tables, and the swap tables of all chains.
+const (
+	// SelectTraceTx selects the block and mix count of the main chain
+	// transaction $1, preferring a valid one.
+	SelectTraceTx = `SELECT block_height, block_time, COALESCE(mix_count, 0) FROM transactions
+		WHERE tx_hash = $1 AND is_mainchain
+		ORDER BY is_valid DESC
+		LIMIT 1;`
+
+	// SelectTraceTxOutputs selects the outputs of transaction $1 with their
+	// first address and spending transaction.
+	SelectTraceTxOutputs = `SELECT vouts.tx_index, COALESCE(vouts.script_addresses[1], ''), vouts.value,
+			COALESCE(spend.tx_hash, '')
+		FROM vouts
+		LEFT JOIN transactions AS spend ON spend.id = vouts.spend_tx_row_id AND spend.is_mainchain
+		WHERE vouts.tx_hash = $1
+		ORDER BY vouts.tx_index;`
+
+	// SelectTraceTxInputs selects the funding outpoints of the inputs of
+	// transaction $1, with the address of the funding output.
+	SelectTraceTxInputs = `SELECT vins.prev_tx_hash, vins.prev_tx_index, vins.value_in,
+			COALESCE(vouts.script_addresses[1], '')
+		FROM vins
+		LEFT JOIN vouts ON vouts.tx_hash = vins.prev_tx_hash
+			AND vouts.tx_index = vins.prev_tx_index AND vouts.tx_tree = vins.prev_tx_tree
+		WHERE vins.tx_hash = $1 AND vins.is_mainchain
+		ORDER BY vins.tx_index;`
+
+	// selectIsSwapTx checks if transaction $1 creates or redeems an atomic
+	// swap contract of a swaps table.
+	selectIsSwapTx = `SELECT EXISTS (SELECT 1 FROM %s WHERE contract_tx = $1 OR spend_tx = $1);`
+)
+
+// MakeSelectIsSwapTx returns the swap check of a swaps table, "swaps",
+// "btc_swaps" or "ltc_swaps".
+func MakeSelectIsSwapTx(table string) string {
+	return fmt.Sprintf(selectIsSwapTx, table)
+}
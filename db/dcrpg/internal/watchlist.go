@@ -0,0 +1,121 @@
+package internal
+
+// These queries relate to the "watches", "watch_deliveries" and
+// "watch_confirmations" tables, the watch-list, its webhook delivery log and
+// the confirmed transactions awaiting the confirmations of their watch.
+const (
+	CreateWatchesTable = `CREATE TABLE IF NOT EXISTS watches (
+		id BIGSERIAL PRIMARY KEY,
+		chain TEXT NOT NULL,
+		address TEXT NOT NULL,
+		view_key TEXT NOT NULL DEFAULT '',
+		callback_url TEXT NOT NULL,
+		client TEXT NOT NULL DEFAULT '',
+		secret TEXT NOT NULL,
+		confirmations INT4 NOT NULL,
+		created TIMESTAMPTZ NOT NULL
+	);`
+
+	InsertWatch = `INSERT INTO watches (chain, address, view_key, callback_url, client, secret, confirmations, created)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id;`
+
+	selectWatchColumns = `SELECT id, chain, address, view_key, callback_url, client, secret, confirmations, created
+		FROM watches`
+
+	SelectWatch = selectWatchColumns + ` WHERE id = $1;`
+
+	SelectWatches = selectWatchColumns + ` ORDER BY id;`
+
+	DeleteWatch = `DELETE FROM watches WHERE id = $1;`
+
+	// The deliveries of a watch are unique by event and transaction, so a
+	// transaction seen again, e.g. after a restart, is not delivered twice.
+	CreateWatchDeliveriesTable = `CREATE TABLE IF NOT EXISTS watch_deliveries (
+		id BIGSERIAL PRIMARY KEY,
+		watch_id INT8 NOT NULL REFERENCES watches (id) ON DELETE CASCADE,
+		event TEXT NOT NULL,
+		txid TEXT NOT NULL,
+		height INT8 NOT NULL,
+		block_hash TEXT NOT NULL DEFAULT '',
+		amount INT8 NOT NULL,
+		confirmations INT4 NOT NULL,
+		created TIMESTAMPTZ NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		attempts INT4 NOT NULL DEFAULT 0,
+		next_attempt TIMESTAMPTZ NOT NULL,
+		response_code INT4 NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '',
+		delivered TIMESTAMPTZ,
+		UNIQUE (watch_id, event, txid)
+	);
+	CREATE INDEX IF NOT EXISTS idx_watch_deliveries_pending
+		ON watch_deliveries (next_attempt) WHERE status = 'pending';`
+
+	InsertWatchDelivery = `INSERT INTO watch_deliveries (watch_id, event, txid, height, block_hash,
+			amount, confirmations, created, next_attempt)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+		ON CONFLICT (watch_id, event, txid) DO NOTHING;`
+
+	// The transactions of the "confirmed" deliveries of the watches of more
+	// than one confirmation wait in watch_confirmations until they reach the
+	// confirmations of their watch, or their block is reorganized out of the
+	// chain.
+	CreateWatchConfirmationsTable = `CREATE TABLE IF NOT EXISTS watch_confirmations (
+		watch_id INT8 NOT NULL REFERENCES watches (id) ON DELETE CASCADE,
+		txid TEXT NOT NULL,
+		height INT8 NOT NULL,
+		block_hash TEXT NOT NULL,
+		amount INT8 NOT NULL,
+		PRIMARY KEY (watch_id, txid)
+	);
+	CREATE INDEX IF NOT EXISTS idx_watch_confirmations_height
+		ON watch_confirmations (height);`
+
+	// UpsertWatchConfirmation records the block of a confirmed transaction of
+	// the watch $1, moving it to the block of a transaction mined again after
+	// a reorganization.
+	UpsertWatchConfirmation = `INSERT INTO watch_confirmations (watch_id, txid, height, block_hash, amount)
+		SELECT id, $2, $3, $4, $5 FROM watches WHERE id = $1 AND confirmations > 1
+		ON CONFLICT (watch_id, txid) DO UPDATE
+		SET height = EXCLUDED.height, block_hash = EXCLUDED.block_hash, amount = EXCLUDED.amount;`
+
+	// DeleteOrphanedWatchConfirmations removes the transactions of the blocks
+	// of a chain that the new block $3 at height $2 reorganized out of it.
+	DeleteOrphanedWatchConfirmations = `DELETE FROM watch_confirmations c
+		USING watches w
+		WHERE w.id = c.watch_id AND w.chain = $1 AND c.height >= $2 AND c.block_hash <> $3;`
+
+	// InsertWatchConfirmations queues the "confirmations" event of the
+	// confirmed transactions that reached the confirmations of their watch at
+	// the chain tip $2, and stops waiting for them.
+	InsertWatchConfirmations = `WITH due AS (
+			DELETE FROM watch_confirmations c
+			USING watches w
+			WHERE w.id = c.watch_id AND w.chain = $1 AND c.height <= $2 - w.confirmations + 1
+			RETURNING c.watch_id, c.txid, c.height, c.block_hash, c.amount, w.confirmations
+		)
+		INSERT INTO watch_deliveries (watch_id, event, txid, height, block_hash,
+			amount, confirmations, created, next_attempt)
+		SELECT watch_id, 'confirmations', txid, height, block_hash, amount, confirmations, $3, $3
+		FROM due
+		ON CONFLICT (watch_id, event, txid) DO NOTHING;`
+
+	selectWatchDeliveryColumns = `SELECT id, watch_id, event, txid, height, block_hash, amount,
+			confirmations, created, status, attempts, next_attempt, response_code, last_error, delivered
+		FROM watch_deliveries`
+
+	SelectDueWatchDeliveries = selectWatchDeliveryColumns + `
+		WHERE status = 'pending' AND next_attempt <= $1
+		ORDER BY next_attempt, id
+		LIMIT $2;`
+
+	SelectWatchDeliveries = selectWatchDeliveryColumns + `
+		WHERE watch_id = $1
+		ORDER BY id DESC
+		LIMIT $2;`
+
+	UpdateWatchDelivery = `UPDATE watch_deliveries
+		SET status = $2, attempts = $3, next_attempt = $4, response_code = $5, last_error = $6, delivered = $7
+		WHERE id = $1;`
+)
@@ -0,0 +1,142 @@
+package internal
+
+// These queries relate to the "address_balances", "wealth_progress",
+// "wealth_snapshots" and "wealth_buckets" tables of the rich lists and the
+// wealth distribution, and to the Decred "blocks", "transactions" and
+// "addresses" tables they are computed from. The wealth tables are shared by
+// all UTXO chains, with a chain column. Times are UNIX seconds.
+const (
+	// Only the addresses with a positive balance are kept.
+	CreateAddressBalancesTable = `CREATE TABLE IF NOT EXISTS address_balances (
+		chain TEXT NOT NULL,
+		address TEXT NOT NULL,
+		balance INT8 NOT NULL,
+		PRIMARY KEY (chain, address)
+	);
+	CREATE INDEX IF NOT EXISTS idx_address_balances_balance
+		ON address_balances (chain, balance DESC, address);`
+
+	// The last block of each chain included in the balances, with the number
+	// of addresses and their total balance.
+	CreateWealthProgressTable = `CREATE TABLE IF NOT EXISTS wealth_progress (
+		chain TEXT PRIMARY KEY,
+		height INT8 NOT NULL,
+		time INT8 NOT NULL,
+		addresses INT8 NOT NULL,
+		total INT8 NOT NULL
+	);`
+
+	// A snapshot of the balances is taken after the last block of each day.
+	CreateWealthSnapshotsTable = `CREATE TABLE IF NOT EXISTS wealth_snapshots (
+		chain TEXT NOT NULL,
+		height INT8 NOT NULL,
+		time INT8 NOT NULL,
+		addresses INT8 NOT NULL,
+		total INT8 NOT NULL,
+		gini FLOAT8 NOT NULL,
+		nakamoto INT8 NOT NULL,
+		PRIMARY KEY (chain, height)
+	);`
+
+	// The buckets are the indexes of dbtypes.WealthBucketMins.
+	CreateWealthBucketsTable = `CREATE TABLE IF NOT EXISTS wealth_buckets (
+		chain TEXT NOT NULL,
+		height INT8 NOT NULL,
+		bucket INT2 NOT NULL,
+		addresses INT8 NOT NULL,
+		value INT8 NOT NULL,
+		PRIMARY KEY (chain, height, bucket)
+	);`
+
+	SelectWealthProgress = `SELECT height, time, addresses, total FROM wealth_progress
+		WHERE chain = $1;`
+
+	UpsertWealthProgress = `INSERT INTO wealth_progress (chain, height, time, addresses, total)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (chain) DO UPDATE SET height = EXCLUDED.height, time = EXCLUDED.time,
+			addresses = EXCLUDED.addresses, total = EXCLUDED.total;`
+
+	// UpsertAddressBalances adds the balance changes $3 to the addresses $2,
+	// returning the new balances and whether each address is new.
+	UpsertAddressBalances = `INSERT INTO address_balances AS b (chain, address, balance)
+		SELECT $1, d.address, d.delta FROM unnest($2::TEXT[], $3::INT8[]) AS d (address, delta)
+		ON CONFLICT (chain, address) DO UPDATE SET balance = b.balance + EXCLUDED.balance
+		RETURNING b.address, b.balance, (b.xmax = 0);`
+
+	DeleteAddressBalances = `DELETE FROM address_balances
+		WHERE chain = $1 AND address = ANY($2);`
+
+	// InsertWealthSnapshot snapshots the balances of chain $1 at height $2 and
+	// time $3. The Gini coefficient is computed from the balances ranked in
+	// ascending order, and the Nakamoto coefficient counts the largest
+	// balances up to the first one past half of the total. Ranking all the
+	// balances is costly, so snapshots are only taken from the sync tip.
+	InsertWealthSnapshot = `INSERT INTO wealth_snapshots (chain, height, time, addresses,
+			total, gini, nakamoto)
+		SELECT $1, $2, $3, COUNT(*), COALESCE(SUM(balance), 0)::INT8,
+			COALESCE(2 * SUM(rank * balance::NUMERIC) / NULLIF(COUNT(*) * SUM(balance::NUMERIC), 0)
+				- (COUNT(*) + 1)::NUMERIC / NULLIF(COUNT(*), 0), 0)::FLOAT8,
+			COUNT(*) FILTER (WHERE above <= total / 2)
+		FROM (
+			SELECT balance,
+				ROW_NUMBER() OVER (ORDER BY balance) AS rank,
+				SUM(balance::NUMERIC) OVER (ORDER BY balance DESC ROWS UNBOUNDED PRECEDING) - balance AS above,
+				SUM(balance::NUMERIC) OVER () AS total
+			FROM address_balances
+			WHERE chain = $1 AND balance > 0
+		) AS ranked
+		ON CONFLICT (chain, height) DO NOTHING;`
+
+	// InsertWealthBuckets snapshots the balance buckets of chain $1 at height
+	// $2. The bucket of a balance of 10^n atoms is n - 4, within the
+	// dbtypes.WealthBucketMins.
+	InsertWealthBuckets = `INSERT INTO wealth_buckets (chain, height, bucket, addresses, value)
+		SELECT $1, $2, LEAST(GREATEST(FLOOR(LOG(balance::NUMERIC)) - 4, 0), 9)::INT2 AS bucket,
+			COUNT(*), SUM(balance)::INT8
+		FROM address_balances
+		WHERE chain = $1 AND balance > 0
+		GROUP BY bucket
+		ON CONFLICT (chain, height, bucket) DO NOTHING;`
+
+	// SelectRichList selects $2 addresses of chain $1 by decreasing balance
+	// from offset $3, with their address cluster, if any.
+	SelectRichList = `SELECT b.address, b.balance, COALESCE(c.cluster_id, 0)
+		FROM address_balances AS b
+		LEFT JOIN address_clusters AS c ON c.chain = b.chain AND c.address = b.address
+		WHERE b.chain = $1
+		ORDER BY b.balance DESC, b.address
+		LIMIT $2 OFFSET $3;`
+
+	SelectLastWealthSnapshot = `SELECT height, time, addresses, total, gini, nakamoto
+		FROM wealth_snapshots
+		WHERE chain = $1
+		ORDER BY height DESC
+		LIMIT 1;`
+
+	SelectWealthBuckets = `SELECT bucket, addresses, value FROM wealth_buckets
+		WHERE chain = $1 AND height = $2
+		ORDER BY bucket;`
+
+	// SelectWealthChartRows selects the wealth snapshots of chain $1 above
+	// height $2, with the addresses and value of their buckets.
+	SelectWealthChartRows = `SELECT s.height, s.time, s.addresses, s.gini, s.nakamoto,
+			COALESCE(array_agg(b.bucket ORDER BY b.bucket) FILTER (WHERE b.bucket IS NOT NULL), '{}'),
+			COALESCE(array_agg(b.addresses ORDER BY b.bucket) FILTER (WHERE b.bucket IS NOT NULL), '{}'),
+			COALESCE(array_agg(b.value ORDER BY b.bucket) FILTER (WHERE b.bucket IS NOT NULL), '{}')
+		FROM wealth_snapshots AS s
+		LEFT JOIN wealth_buckets AS b ON b.chain = s.chain AND b.height = s.height
+		WHERE s.chain = $1 AND s.height > $2
+		GROUP BY s.chain, s.height
+		ORDER BY s.height;`
+
+	SelectWealthBlock = `SELECT time FROM blocks WHERE height = $1 AND is_mainchain;`
+
+	// SelectWealthBlockDeltas selects the balance change of each address in
+	// the valid transactions of the main chain block at height $1.
+	SelectWealthBlockDeltas = `SELECT addresses.address,
+			SUM(CASE WHEN addresses.is_funding THEN addresses.value ELSE -addresses.value END)::INT8
+		FROM transactions
+		JOIN addresses ON addresses.tx_hash = transactions.tx_hash AND addresses.valid_mainchain
+		WHERE transactions.block_height = $1 AND transactions.is_mainchain AND transactions.is_valid
+		GROUP BY addresses.address;`
+)
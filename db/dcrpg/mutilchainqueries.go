@@ -11,6 +11,7 @@ import (
 	"github.com/decred/dcrdata/db/dcrpg/v8/internal/mutilchainquery"
 	"github.com/decred/dcrdata/v8/db/dbtypes"
 	"github.com/decred/dcrdata/v8/mutilchain"
+	"github.com/decred/dcrdata/v8/txhelpers"
 	"github.com/decred/dcrdata/v8/xmr/xmrhelper"
 	"github.com/lib/pq"
 )
@@ -211,6 +212,45 @@ func InsertMutilchainTxns(sqlTx *sql.Tx, dbTxns []*dbtypes.Tx, checked bool, cha
 	return ids, nil
 }
 
+// InsertMutilchainCoinJoins classifies the transactions of a BTC or LTC block
+// with detect and stores the CoinJoins found, returning how many were stored.
+func InsertMutilchainCoinJoins(sqlTx *sql.Tx, dbTxns []*dbtypes.Tx, dbTxVouts [][]*dbtypes.Vout,
+	detect func(numIns int, outs []int64) *txhelpers.CoinJoin, chainType string) (int, error) {
+	stmt, err := sqlTx.Prepare(mutilchainquery.MakeUpsertCoinJoin(chainType))
+	if err != nil {
+		log.Errorf("%s: CoinJoins INSERT prepare: %v", chainType, err)
+		_ = sqlTx.Rollback() // try, but we want the Prepare error back
+		return 0, err
+	}
+
+	var count int
+	for it, tx := range dbTxns {
+		outs := make([]int64, 0, len(dbTxVouts[it]))
+		for _, vout := range dbTxVouts[it] {
+			outs = append(outs, int64(vout.Value))
+		}
+		cj := detect(int(tx.NumVin), outs)
+		if cj == nil {
+			continue
+		}
+		_, err = stmt.Exec(tx.TxID, tx.BlockHeight, tx.BlockTime.UNIX(), cj.Type,
+			cj.Denom, cj.Participants, cj.Mixed)
+		if err != nil {
+			_ = stmt.Close() // try, but we want the Exec error back
+			if errRoll := sqlTx.Rollback(); errRoll != nil {
+				log.Errorf("Rollback failed: %v", errRoll)
+			}
+			return 0, err
+		}
+		count++
+	}
+
+	// Close prepared statement. Ignore errors as we'll Commit regardless.
+	_ = stmt.Close()
+
+	return count, nil
+}
+
 func ParseAndStoreTxJSON(dbtx *sql.Tx, txHash string, blockHeight uint64, txJSONStr string, checked, isCoinbase bool) (*xmrParseTxResult, error) {
 	// parse into map
 	var txMap map[string]interface{}
@@ -673,6 +673,12 @@ func (pgb *ChainDB) storeBTCWholeTxns(sqlTx *sql.Tx, client *btcClient.Client, b
 		txRes.err = err
 		return txRes
 	}
+	// Classify and store the CoinJoin transactions
+	if _, err = InsertMutilchainCoinJoins(sqlTx, dbTransactions, dbTxVouts, txhelpers.DetectCoinJoin, mutilchain.TYPEBTC); err != nil {
+		log.Error("BTC: InsertCoinJoins:", err)
+		txRes.err = err
+		return txRes
+	}
 	// Store tx Db IDs as funding tx in AddressRows and rearrange
 	dbAddressRowsFlat := make([]*dbtypes.MutilchainAddressRow, 0, totalAddressRows)
 	for it, txDbID := range TxDbIDs {
@@ -676,6 +676,12 @@ func (pgb *ChainDB) storeLTCWholeTxns(sqlTx *sql.Tx, client *ltcClient.Client, b
 		txRes.err = err
 		return txRes
 	}
+	// Classify and store the CoinJoin transactions
+	if _, err = InsertMutilchainCoinJoins(sqlTx, dbTransactions, dbTxVouts, txhelpers.DetectGenericCoinJoin, mutilchain.TYPELTC); err != nil {
+		log.Error("LTC: InsertCoinJoins:", err)
+		txRes.err = err
+		return txRes
+	}
 	// Store tx Db IDs as funding tx in AddressRows and rearrange
 	dbAddressRowsFlat := make([]*dbtypes.MutilchainAddressRow, 0, totalAddressRows)
 	for it, txDbID := range TxDbIDs {
@@ -19,6 +19,7 @@ import (
 	"github.com/decred/dcrdata/v8/db/dbtypes"
 	"github.com/decred/dcrdata/v8/mutilchain"
 	"github.com/decred/dcrdata/v8/xmr/xmrclient"
+	"github.com/decred/dcrdata/v8/xmr/xmrfingerprint"
 	"github.com/decred/dcrdata/v8/xmr/xmrhelper"
 )
 
@@ -199,6 +200,7 @@ func (pgb *ChainDB) storeXMRWholeTxns(dbtx *sql.Tx, client *xmrclient.XMRClient,
 		return txRes
 	}
 	var totalTxSize, totalRingSize, totalDecoy03, totalDecoy47, totalDecoy811, totalDecoy1214, totalDecoyGe15 int64
+	fingerprints := make([]*xmrfingerprint.Fingerprint, 0, len(block.Tx))
 	for i, txHash := range block.Tx {
 		isCoinbaseTx := txHash == block.MinnerTxhash
 		var txJSONStr string
@@ -238,9 +240,21 @@ func (pgb *ChainDB) storeXMRWholeTxns(dbtx *sql.Tx, client *xmrclient.XMRClient,
 				totalDecoy811 += int64(parseResult.decoy811Num)
 				totalDecoy1214 += int64(parseResult.decoy1214Num)
 				totalDecoyGe15 += int64(parseResult.decoyGe15Num)
+				fp, err := xmrfingerprint.FromTxJSON(txHash, int64(block.Height), len(txHex)/2, txJSONStr)
+				if err != nil {
+					log.Warnf("XMR: fingerprint of tx %s: %v", txHash, err)
+				} else {
+					fp.BlockTime = block.Time.T.Unix()
+					fingerprints = append(fingerprints, fp)
+				}
 			}
 		}
 	}
+	if err := insertXMRTxFingerprints(dbtx, fingerprints); err != nil {
+		log.Errorf("XMR: insertXMRTxFingerprints: %v", err)
+		txRes.err = err
+		return txRes
+	}
 	// calculate for final
 	txRes.ringSize = totalRingSize
 	if txRes.numVins > 0 {
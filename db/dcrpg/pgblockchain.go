@@ -47,6 +47,7 @@ import (
 	"github.com/decred/dcrdata/v8/mutilchain"
 	"github.com/decred/dcrdata/v8/mutilchain/btcrpcutils"
 	"github.com/decred/dcrdata/v8/mutilchain/externalapi"
+	"github.com/decred/dcrdata/v8/mutilchain/labels"
 	"github.com/decred/dcrdata/v8/mutilchain/ltcrpcutils"
 	"github.com/decred/dcrdata/v8/rpcutils"
 	"github.com/decred/dcrdata/v8/stakedb"
@@ -278,6 +279,7 @@ type ChainDB struct {
 	ltcChainParams     *ltc_chaincfg.Params
 	btcChainParams     *btc_chaincfg.Params
 	devAddress         string
+	labels             *labels.Set
 	dupChecks          bool
 	ltcDupChecks       bool
 	btcDupChecks       bool
@@ -363,13 +365,34 @@ type ChainDB struct {
 		// commonly retrieved when the explorer block is updated.
 		difficulties map[int64]float64
 	}
+	// retargetEpochs caches the current BTC and LTC difficulty retarget
+	// epochs by chain type.
+	retargetEpochs struct {
+		sync.Mutex
+		m map[string]*mutilchain.RetargetEpoch
+	}
+	// newAddressesTips are the heights of the BTC and LTC new address chart
+	// data read from the new_addresses tables, by chain type.
+	newAddressesTips struct {
+		sync.Mutex
+		m map[string]int64
+	}
 	coinAgeSync               sync.Mutex
+	mixingSync                sync.Mutex
 	utxoHistorySync           sync.Mutex
+	btcCoinAgeSync            sync.Mutex
+	ltcCoinAgeSync            sync.Mutex
+	btcNewAddressesSync       sync.Mutex
+	ltcNewAddressesSync       sync.Mutex
+	dcrWealthSync             sync.Mutex
+	btcWealthSync             sync.Mutex
+	ltcWealthSync             sync.Mutex
 	multichainBtcMetaInfoSync sync.Mutex
 	multichainLtcMetaInfoSync sync.Mutex
 	btcWholeSyncMtx           sync.Mutex
 	ltcWholeSyncMtx           sync.Mutex
 	xmrWholeSyncMtx           sync.Mutex
+	xmrRingSyncMtx            sync.Mutex
 	btc20BlocksSyncMtx        sync.Mutex
 	ltc20BlocksSyncMtx        sync.Mutex
 }
@@ -824,6 +847,7 @@ func NewChainDB(ctx context.Context, cfg *ChainDBCfg, stakeDB *stakedb.StakeData
 		ltcChainParams:     ltcParams,
 		btcChainParams:     btcParams,
 		devAddress:         projectFundAddress,
+		labels:             newLabelSet(projectFundAddress),
 		dupChecks:          true,
 		ltcDupChecks:       true,
 		btcDupChecks:       true,
@@ -992,6 +1016,17 @@ func (pgb *ChainDB) MutilchainCheckAndCreateTable(chainType string) error {
 	if err := CreateMutilchainTables(pgb.db, chainType); err != nil {
 		return fmt.Errorf("failed to create tables: %w", err)
 	}
+	if chainType == mutilchain.TYPEBTC || chainType == mutilchain.TYPELTC {
+		// The coin age sync selects the outputs created and spent in a block.
+		for _, stmt := range []string{
+			mutilchainquery.MakeIndexUtxoHistoryOnCreateHeight(chainType),
+			mutilchainquery.MakeIndexUtxoHistoryOnSpendHeight(chainType),
+		} {
+			if _, err := pgb.db.Exec(stmt); err != nil {
+				return fmt.Errorf("failed to index %sutxo_history table: %w", chainType, err)
+			}
+		}
+	}
 	return nil
 }
 
@@ -1241,6 +1276,18 @@ func (pgb *ChainDB) RegisterCharts(charts *cache.ChartData) {
 		Appender: appendMcaSnapshot,
 	})
 
+	charts.AddUpdater(cache.ChartUpdater{
+		Tag:      "mixing",
+		Fetcher:  pgb.chartMixing,
+		Appender: appendMixing,
+	})
+
+	charts.AddUpdater(cache.ChartUpdater{
+		Tag:      "wealth",
+		Fetcher:  pgb.chartWealth,
+		Appender: appendWealth,
+	})
+
 	charts.AddUpdater(cache.ChartUpdater{
 		Tag:      "market price",
 		Fetcher:  pgb.marketPrice,
@@ -1289,7 +1336,40 @@ func (pgb *ChainDB) RegisterMutilchainCharts(charts *cache.MutilchainChartData)
 		charts.AddUpdater(cache.ChartMutilchainUpdater{
 			Tag:      fmt.Sprintf("%s basic blocks", charts.ChainType),
 			Fetcher:  pgb.chartMutilchainBlocks,
-			Appender: appendMutilchainChartBlocks,
+			Appender: pgb.appendMutilchainChartBlocks, // ChainDB's method for the chain params.
+		})
+		if !charts.UseSyncDB {
+			return
+		}
+		charts.AddUpdater(cache.ChartMutilchainUpdater{
+			Tag:      fmt.Sprintf("%s new addresses", charts.ChainType),
+			Fetcher:  pgb.chartMutilchainNewAddresses,
+			Appender: pgb.appendMutilchainNewAddresses,
+		})
+		charts.AddUpdater(cache.ChartMutilchainUpdater{
+			Tag:      fmt.Sprintf("%s coinjoins", charts.ChainType),
+			Fetcher:  pgb.chartMutilchainCoinJoins,
+			Appender: appendMutilchainCoinJoins,
+		})
+		charts.AddUpdater(cache.ChartMutilchainUpdater{
+			Tag:      fmt.Sprintf("%s mempool", charts.ChainType),
+			Fetcher:  pgb.chartMutilchainMempool,
+			Appender: appendMutilchainMempool,
+		})
+		charts.AddUpdater(cache.ChartMutilchainUpdater{
+			Tag:      fmt.Sprintf("%s coin age", charts.ChainType),
+			Fetcher:  pgb.chartMutilchainCoinAge,
+			Appender: appendMutilchainCoinAge,
+		})
+		charts.AddUpdater(cache.ChartMutilchainUpdater{
+			Tag:      fmt.Sprintf("%s coin age bands", charts.ChainType),
+			Fetcher:  pgb.chartMutilchainCoinAgeBands,
+			Appender: appendMutilchainCoinAgeBands,
+		})
+		charts.AddUpdater(cache.ChartMutilchainUpdater{
+			Tag:      fmt.Sprintf("%s wealth", charts.ChainType),
+			Fetcher:  pgb.chartMutilchainWealth,
+			Appender: appendMutilchainWealth,
 		})
 		return
 	}
@@ -1300,6 +1380,11 @@ func (pgb *ChainDB) RegisterMutilchainCharts(charts *cache.MutilchainChartData)
 		Fetcher:  pgb.chartXmrMutilchainBlocks,
 		Appender: appendXmrChartBlocks,
 	})
+	charts.AddUpdater(cache.ChartMutilchainUpdater{
+		Tag:      "Monero ring analysis",
+		Fetcher:  pgb.chartXmrRingAnalysis,
+		Appender: appendXmrRingAnalysis,
+	})
 
 	// charts.AddUpdater(cache.ChartMutilchainUpdater{
 	// 	Tag:      "Monero ring members",
@@ -3046,8 +3131,14 @@ func (pgb *ChainDB) GetPeerCount() (peerCount int, err error) {
 }
 
 func (pgb *ChainDB) GetPriceAll() (*dbtypes.BitDegreeOhlcResponse, error) {
+	return getBitDegreeOhlc("decred-dcr")
+}
+
+// getBitDegreeOhlc fetches the whole daily OHLC history of a coin, named like
+// decred-dcr, from BitDegree.
+func getBitDegreeOhlc(coin string) (*dbtypes.BitDegreeOhlcResponse, error) {
 	var result dbtypes.BitDegreeOhlcResponse
-	fetchUrl := "https://www.bitdegree.org/api/cryptocurrencies/ohlc-chart/decred-dcr"
+	fetchUrl := "https://www.bitdegree.org/api/cryptocurrencies/ohlc-chart/" + coin
 	query := map[string]string{
 		"period": "all",
 	}
@@ -3138,9 +3229,6 @@ func (pgb *ChainDB) GetBitDegreeAllPriceMap() (map[string]float64, *dbtypes.Dail
 	if len(res.Ohlc) == 0 {
 		return result, nil
 	}
-	dailyResult := &dbtypes.DailyMarket{
-		Data: make([]*dbtypes.DailyItemData, 0),
-	}
 	for idx, dataArr := range res.Ohlc {
 		if len(dataArr) < 5 {
 			continue
@@ -3158,23 +3246,37 @@ func (pgb *ChainDB) GetBitDegreeAllPriceMap() (map[string]float64, *dbtypes.Dail
 			result[key] = dataArr[4]
 			countMap[key] = 1
 		}
-		// if valid daily index
-		if idx < len(res.Ohlc)-1 {
-			dailyItem := &dbtypes.DailyItemData{
-				Date:   int64(dataArr[0]) / 1000,
-				Volume: res.Volumns[idx][1],
-				Open:   dataArr[1],
-				High:   dataArr[2],
-				Low:    dataArr[3],
-				Close:  dataArr[4],
-			}
-			dailyResult.Data = append(dailyResult.Data, dailyItem)
-		}
 	}
 	for k, v := range result {
 		result[k] = v / float64(countMap[k])
 	}
-	return result, dailyResult
+	return result, bitDegreeDailyMarket(res)
+}
+
+// bitDegreeDailyMarket is the daily market data of a BitDegree OHLC history.
+// The last day is left out since it is not complete.
+func bitDegreeDailyMarket(res *dbtypes.BitDegreeOhlcResponse) *dbtypes.DailyMarket {
+	dailyResult := &dbtypes.DailyMarket{
+		Data: make([]*dbtypes.DailyItemData, 0),
+	}
+	for idx, dataArr := range res.Ohlc {
+		if len(dataArr) < 5 || idx == len(res.Ohlc)-1 {
+			continue
+		}
+		if len(res.Volumns)-1 < idx || len(res.Volumns[idx]) < 2 {
+			continue
+		}
+		dailyItem := &dbtypes.DailyItemData{
+			Date:   int64(dataArr[0]) / 1000,
+			Volume: res.Volumns[idx][1],
+			Open:   dataArr[1],
+			High:   dataArr[2],
+			Low:    dataArr[3],
+			Close:  dataArr[4],
+		}
+		dailyResult.Data = append(dailyResult.Data, dailyItem)
+	}
+	return dailyResult
 }
 
 // Get legacy summary data
@@ -3772,20 +3874,6 @@ func (pgb *ChainDB) GetLast5PoolDataList() (last5PoolData []*dbtypes.PoolDataIte
 	return
 }
 
-// GetLastMultichainPoolDataList return last 10 block pools info
-func (pgb *ChainDB) GetLastMultichainPoolDataList(chainType string, startHeight int64) ([]*dbtypes.MultichainPoolDataItem, error) {
-	switch chainType {
-	case mutilchain.TYPEBTC:
-		return externalapi.GetBitcoinLastBlocksPool(startHeight)
-	case mutilchain.TYPELTC:
-		return externalapi.GetLitecoinLastBlocksPool(startHeight)
-	case mutilchain.TYPEXMR:
-		return externalapi.GetXMRLastBlocksPool()
-	default:
-		return make([]*dbtypes.MultichainPoolDataItem, 0), nil
-	}
-}
-
 // AddressBalance attempts to retrieve balance information for a specific
 // address from cache, and if cache is stale or missing data for the address, a
 // DB query is used. A successful DB query will freshen the cache.
@@ -5295,6 +5383,8 @@ func (pgb *ChainDB) Store(blockData *blockdata.BlockData, msgBlock *wire.MsgBloc
 	pgb.SignalHeight(msgBlock.Header.Height)
 	log.Infof("Start syncing coin age bands/mean coin age data in the background. Height: %d.", msgBlock.Header.Height)
 	go pgb.SyncCoinAgeDataAllSet(int64(msgBlock.Header.Height))
+	go pgb.SyncMixingDataOnHeight(int64(msgBlock.Header.Height))
+	go pgb.SyncWealth(mutilchain.TYPEDCR)
 	return nil
 }
 
@@ -5543,20 +5633,6 @@ func (pgb *ChainDB) chartBlocks(charts *cache.ChartData) (*sql.Rows, func(), err
 	return rows, cancel, nil
 }
 
-func (pgb *ChainDB) chartMutilchainBlocks(charts *cache.MutilchainChartData) (*sql.Rows, func(), error) {
-	// TODO when handler sync all blockchain data to DB, uncomment
-	_, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
-	// rows, err := retrieveMutilchainChartBlocks(ctx, pgb.db, charts, charts.ChainType)
-	// if err != nil {
-	// 	return nil, cancel, fmt.Errorf("chartBlocks: %w", pgb.replaceCancelError(err))
-	// }
-	// lastBlockHeight, blockErr := pgb.GetMutilchainHeight(charts.ChainType)
-	// if blockErr == nil {
-	// 	charts.LastBlockHeight = lastBlockHeight
-	// }
-	return nil, cancel, nil
-}
-
 // coinSupply fetches the coin supply chart data from retrieveCoinSupply.
 // This is the Fetcher half of a pair that make up a cache.ChartUpdater. The
 // Appender half is appendCoinSupply.
@@ -8715,6 +8791,7 @@ func (pgb *ChainDB) GetExplorerBlock(hash string) *exptypes.BlockInfo {
 				exptx.Fee, exptx.FeeRate, exptx.Fees = 0.0, 0.0, 0.0
 			}
 		}
+		exptx.Labels = pgb.dcrTxLabels(tx.Vout)
 		// check swaps tx
 		exptx.SwapsType = pgb.GetSwapType(exptx.TxID)
 		if exptx.SwapsType != "" {
@@ -303,6 +303,12 @@ func (pgb *ChainDB) BTCStore(blockData *blockdatabtc.BlockData, msgBlock *btcwir
 		if err != nil {
 			return err
 		}
+		if err = pgb.StoreBTCBlockPool(msgBlock, int64(blockData.Header.Height)); err != nil {
+			log.Errorf("BTC: failed to store pool of block %d: %v", blockData.Header.Height, err)
+		}
+		go pgb.SyncMutilchainCoinAge(mutilchain.TYPEBTC)
+		go pgb.SyncMutilchainNewAddresses(mutilchain.TYPEBTC)
+		go pgb.SyncWealth(mutilchain.TYPEBTC)
 		if pgb.SyncChainDBFlag {
 			// TODO: go pgb.SyncOneBTCWholeBlock(pgb.BtcClient, msgBlock)
 		}
@@ -758,6 +764,7 @@ func (pgb *ChainDB) GetBTCExplorerBlock(hash string) *exptypes.BlockInfo {
 			return nil
 		}
 		exptx := trimmedBTCTxInfoFromMsgTx(pgb.BtcClient, tx, msgTx, pgb.btcChainParams, prevTxCache)
+		exptx.Labels = pgb.btcTxLabels(tx.Vout)
 		totalSent += exptx.Total
 		totalFees += exptx.FeeCoin
 		totalNumVins += int64(exptx.VinCount)
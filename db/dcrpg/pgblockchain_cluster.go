@@ -0,0 +1,360 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/mutilchain"
+	"github.com/lib/pq"
+
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal"
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal/mutilchainquery"
+)
+
+// ClusterProgress retrieves the last block height of a chain included in the
+// address clusters, or -1 before the first block.
+func (pgb *ChainDB) ClusterProgress(chain string) (int64, error) {
+	var height int64
+	err := pgb.db.QueryRow(internal.SelectClusterProgress, chain).Scan(&height)
+	if errors.Is(err, sql.ErrNoRows) {
+		return -1, nil
+	}
+	return height, err
+}
+
+// ClusterTipHeight retrieves the best block height stored for a chain.
+func (pgb *ChainDB) ClusterTipHeight(chain string) (int64, error) {
+	if chain == mutilchain.TYPEDCR {
+		return pgb.HeightDB()
+	}
+	return pgb.MutilchainHeightDB(chain)
+}
+
+// ClusterBlockTxs retrieves the transactions of the main chain block of a
+// chain at a height, with their input and output addresses, for the address
+// clustering. Outputs to addresses funded in earlier blocks are not fresh.
+func (pgb *ChainDB) ClusterBlockTxs(chain string, height int64) ([]*dbtypes.ClusterTx, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+
+	var txs []*dbtypes.ClusterTx
+	var before any // the block time (dcr) or height of the freshness check
+	var inputsQuery, outputsQuery, fundedQuery string
+	if chain == mutilchain.TYPEDCR {
+		rows, err := pgb.db.QueryContext(ctx, internal.SelectClusterBlockTxs, height)
+		if err != nil {
+			return nil, pgb.replaceCancelError(err)
+		}
+		defer closeRows(rows)
+		for rows.Next() {
+			var tx dbtypes.ClusterTx
+			var blockTime dbtypes.TimeDef
+			var mixCount sql.NullInt64
+			if err = rows.Scan(&tx.TxHash, &blockTime, &mixCount); err != nil {
+				return nil, err
+			}
+			tx.Mix = mixCount.Int64 > 0
+			before = blockTime
+			txs = append(txs, &tx)
+		}
+		if err = rows.Err(); err != nil {
+			return nil, err
+		}
+		inputsQuery = internal.SelectClusterTxsInputs
+		outputsQuery = internal.SelectClusterTxsOutputs
+		fundedQuery = internal.SelectFundedAddressesBefore
+	} else {
+		rows, err := pgb.db.QueryContext(ctx, mutilchainquery.MakeSelectClusterBlockTxs(chain), height)
+		if err != nil {
+			return nil, pgb.replaceCancelError(err)
+		}
+		defer closeRows(rows)
+		for rows.Next() {
+			var tx dbtypes.ClusterTx
+			if err = rows.Scan(&tx.TxHash); err != nil {
+				return nil, err
+			}
+			txs = append(txs, &tx)
+		}
+		if err = rows.Err(); err != nil {
+			return nil, err
+		}
+		before = height
+		inputsQuery = mutilchainquery.MakeSelectClusterTxsInputs(chain)
+		outputsQuery = mutilchainquery.MakeSelectClusterTxsOutputs(chain)
+		fundedQuery = mutilchainquery.MakeSelectFundedAddressesBefore(chain)
+	}
+	if len(txs) == 0 {
+		return txs, nil
+	}
+
+	byHash := make(map[string]*dbtypes.ClusterTx, len(txs))
+	hashes := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		byHash[tx.TxHash] = tx
+		hashes = append(hashes, tx.TxHash)
+	}
+
+	rows, err := pgb.db.QueryContext(ctx, inputsQuery, pq.Array(hashes))
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	defer closeRows(rows)
+	for rows.Next() {
+		var hash, addr string
+		if err = rows.Scan(&hash, &addr); err != nil {
+			return nil, err
+		}
+		if tx := byHash[hash]; tx != nil {
+			tx.Inputs = append(tx.Inputs, addr)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	outRows, err := pgb.db.QueryContext(ctx, outputsQuery, pq.Array(hashes))
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	defer closeRows(outRows)
+	var outAddrs []string
+	for outRows.Next() {
+		var hash string
+		out := new(dbtypes.ClusterOutput)
+		if err = outRows.Scan(&hash, &out.Address, &out.Value); err != nil {
+			return nil, err
+		}
+		if tx := byHash[hash]; tx != nil {
+			tx.Outputs = append(tx.Outputs, out)
+			outAddrs = append(outAddrs, out.Address)
+		}
+	}
+	if err = outRows.Err(); err != nil {
+		return nil, err
+	}
+	if len(outAddrs) == 0 {
+		return txs, nil
+	}
+
+	// An output address is fresh unless it was funded before the block.
+	fundedRows, err := pgb.db.QueryContext(ctx, fundedQuery, pq.Array(outAddrs), before)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	defer closeRows(fundedRows)
+	funded := make(map[string]bool)
+	for fundedRows.Next() {
+		var addr string
+		if err = fundedRows.Scan(&addr); err != nil {
+			return nil, err
+		}
+		funded[addr] = true
+	}
+	if err = fundedRows.Err(); err != nil {
+		return nil, err
+	}
+	for _, tx := range txs {
+		for _, out := range tx.Outputs {
+			out.Fresh = !funded[out.Address]
+		}
+	}
+	return txs, nil
+}
+
+// StoreClusterLinks merges the clusters of the addresses of each link into the
+// cluster with the smallest ID, or into a new cluster, records the linking
+// transactions, and sets the clustering progress of the chain to the height,
+// in one database transaction.
+func (pgb *ChainDB) StoreClusterLinks(chain string, height int64, links []*dbtypes.ClusterLink) error {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	dbTx, err := pgb.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin database transaction: %w", err)
+	}
+	if err = storeClusterLinks(ctx, dbTx, chain, height, links); err != nil {
+		_ = dbTx.Rollback()
+		return pgb.replaceCancelError(err)
+	}
+	return dbTx.Commit()
+}
+
+func storeClusterLinks(ctx context.Context, dbTx *sql.Tx, chain string, height int64, links []*dbtypes.ClusterLink) error {
+	for _, link := range links {
+		rows, err := dbTx.QueryContext(ctx, internal.SelectAddressesClusterIDs, chain, pq.Array(link.Addresses))
+		if err != nil {
+			return err
+		}
+		var ids []int64
+		for rows.Next() {
+			var id int64
+			if err = rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+
+		var target int64
+		if len(ids) == 0 {
+			if err = dbTx.QueryRowContext(ctx, internal.SelectNextClusterID).Scan(&target); err != nil {
+				return err
+			}
+		} else {
+			sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+			target = ids[0]
+			if len(ids) > 1 {
+				_, err = dbTx.ExecContext(ctx, internal.MergeClusters, chain, target, pq.Array(ids[1:]))
+				if err != nil {
+					return err
+				}
+			}
+		}
+		_, err = dbTx.ExecContext(ctx, internal.InsertAddressesCluster, chain, pq.Array(link.Addresses), target)
+		if err != nil {
+			return err
+		}
+		_, err = dbTx.ExecContext(ctx, internal.InsertClusterTx, chain, link.TxHash, height, link.Change)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := dbTx.ExecContext(ctx, internal.UpsertClusterProgress, chain, height)
+	return err
+}
+
+// AddressCluster retrieves the cluster of an address with its size, balance
+// and activity. An address that is not clustered is reported alone, with
+// cluster ID 0.
+func (pgb *ChainDB) AddressCluster(chain, address string) (*dbtypes.AddressCluster, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	var id int64
+	err := pgb.db.QueryRowContext(ctx, internal.SelectAddressClusterID, chain, address).Scan(&id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, pgb.replaceCancelError(err)
+	}
+	return pgb.clusterStats(ctx, chain, id, address)
+}
+
+// AddressClusterSize retrieves the cluster ID of an address and the number of
+// addresses in the cluster, without its balance and activity. Both are 0 for
+// an address that is not clustered.
+func (pgb *ChainDB) AddressClusterSize(chain, address string) (id, size int64, err error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	err = pgb.db.QueryRowContext(ctx, internal.SelectAddressClusterID, chain, address).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, pgb.replaceCancelError(err)
+	}
+	err = pgb.db.QueryRowContext(ctx, internal.SelectClusterSize, chain, id).Scan(&size)
+	return id, size, pgb.replaceCancelError(err)
+}
+
+// Cluster retrieves a cluster by ID with a page of its addresses, in address
+// order. The error is dbtypes.ErrNoResult for an unknown cluster.
+func (pgb *ChainDB) Cluster(chain string, id int64, limit, offset int) (*dbtypes.AddressCluster, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	rows, err := pgb.db.QueryContext(ctx, internal.SelectClusterAddresses, chain, id, limit, offset)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	defer closeRows(rows)
+	addrs := make([]string, 0, limit)
+	for rows.Next() {
+		var addr string
+		if err = rows.Scan(&addr); err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, addr)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 && offset == 0 {
+		return nil, dbtypes.ErrNoResult
+	}
+	cluster, err := pgb.clusterStats(ctx, chain, id, "")
+	if err != nil {
+		return nil, err
+	}
+	cluster.Addresses = addrs
+	return cluster, nil
+}
+
+// clusterStats computes the size, balance and activity of a cluster, or of
+// the address alone with cluster ID 0.
+func (pgb *ChainDB) clusterStats(ctx context.Context, chain string, id int64, address string) (*dbtypes.AddressCluster, error) {
+	cluster := &dbtypes.AddressCluster{Chain: chain, ID: id, Size: 1}
+	if id > 0 {
+		err := pgb.db.QueryRowContext(ctx, internal.SelectClusterSize, chain, id).Scan(&cluster.Size)
+		if err != nil {
+			return nil, pgb.replaceCancelError(err)
+		}
+	}
+	if chain == mutilchain.TYPEDCR {
+		var first, last sql.NullTime
+		err := pgb.db.QueryRowContext(ctx, internal.SelectClusterStats, id, address).
+			Scan(&cluster.Balance, &first, &last)
+		if err != nil {
+			return nil, pgb.replaceCancelError(err)
+		}
+		if first.Valid {
+			cluster.FirstActivity = first.Time.Unix()
+			cluster.LastActivity = last.Time.Unix()
+		}
+		return cluster, nil
+	}
+	var first, last sql.NullInt64
+	err := pgb.db.QueryRowContext(ctx, mutilchainquery.MakeSelectClusterStats(chain), chain, id, address).
+		Scan(&cluster.Balance, &first, &last)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	cluster.FirstActivity, cluster.LastActivity = first.Int64, last.Int64
+	return cluster, nil
+}
+
+// CoSpentAddresses retrieves the addresses spent together with an address in
+// the clustered transactions, most frequent first, up to limit.
+func (pgb *ChainDB) CoSpentAddresses(chain, address string, limit int) ([]*dbtypes.CoSpentAddress, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	var rows *sql.Rows
+	var err error
+	if chain == mutilchain.TYPEDCR {
+		rows, err = pgb.db.QueryContext(ctx, internal.SelectCoSpentAddresses, address, limit)
+	} else {
+		rows, err = pgb.db.QueryContext(ctx, mutilchainquery.MakeSelectCoSpentAddresses(chain),
+			chain, address, limit)
+	}
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	defer closeRows(rows)
+	addrs := make([]*dbtypes.CoSpentAddress, 0)
+	for rows.Next() {
+		var addr dbtypes.CoSpentAddress
+		if err = rows.Scan(&addr.Address, &addr.TxCount); err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, &addr)
+	}
+	return addrs, rows.Err()
+}
@@ -0,0 +1,278 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrdata/v8/db/cache"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/mutilchain"
+
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal/mutilchainquery"
+)
+
+const (
+	// utxoAgeBucketSeconds is the width of the creation time buckets of the
+	// unspent BTC and LTC outputs, and so the resolution of their age bands.
+	utxoAgeBucketSeconds = 3600
+
+	// coinAgeConfirmations is the number of blocks that the coin age data is
+	// kept behind the best stored block, since the coin age data of a block is
+	// not undone when the block is reorganized out of the chain.
+	coinAgeConfirmations = 6
+)
+
+// bitDegreeCoins are the BitDegree names of the BTC and LTC price histories.
+var bitDegreeCoins = map[string]string{
+	mutilchain.TYPEBTC: "bitcoin-btc",
+	mutilchain.TYPELTC: "litecoin-ltc",
+}
+
+// coinAgeSyncMtx is the mutex of the coin age sync of a BTC or LTC chain.
+func (pgb *ChainDB) coinAgeSyncMtx(chainType string) *sync.Mutex {
+	switch chainType {
+	case mutilchain.TYPEBTC:
+		return &pgb.btcCoinAgeSync
+	case mutilchain.TYPELTC:
+		return &pgb.ltcCoinAgeSync
+	}
+	return nil
+}
+
+// syncMutilchainDailyMarket stores the daily market data of a BTC or LTC
+// chain, used for the realized cap, unless the last complete day is stored.
+func (pgb *ChainDB) syncMutilchainDailyMarket(chainType string) error {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	var lastDate int64
+	err := pgb.db.QueryRowContext(ctx, mutilchainquery.MakeSelectLastDailyMarketDate(chainType)).Scan(&lastDate)
+	cancel()
+	if err != nil {
+		return pgb.replaceCancelError(err)
+	}
+	if time.Since(time.Unix(lastDate, 0)) < 48*time.Hour {
+		return nil
+	}
+	res, err := getBitDegreeOhlc(bitDegreeCoins[chainType])
+	if err != nil {
+		return err
+	}
+	ctx, cancel = context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	stmt := mutilchainquery.MakeUpsertDailyMarketRow(chainType)
+	for _, day := range bitDegreeDailyMarket(res).Data {
+		if day.Date <= lastDate {
+			continue
+		}
+		_, err = pgb.db.ExecContext(ctx, stmt, day.Date, day.Volume, day.Open, day.High, day.Low, day.Close)
+		if err != nil {
+			return pgb.replaceCancelError(err)
+		}
+	}
+	return nil
+}
+
+// storeMutilchainCoinAge updates the coin age tables of a BTC or LTC chain
+// with the block at height. It returns false if the block or some of its
+// transactions are not stored yet.
+func (pgb *ChainDB) storeMutilchainCoinAge(chainType string, height int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+
+	var hash string
+	var blockTime, numTx, storedTx int64
+	err := pgb.db.QueryRowContext(ctx, mutilchainquery.MakeSelectCoinAgeBlock(chainType), height).
+		Scan(&hash, &blockTime, &numTx, &storedTx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if numTx == 0 || storedTx < numTx {
+		return false, nil
+	}
+
+	dbtx, err := pgb.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("unable to begin database transaction: %w", err)
+	}
+	// The outputs created in the block are added to the buckets before the
+	// spent ones are removed, since an output may be spent in the block that
+	// created it.
+	stmts := []struct {
+		query string
+		args  []any
+	}{
+		{mutilchainquery.MakeInsertUtxoHistoryCreated(chainType), []any{height, hash, blockTime}},
+		{mutilchainquery.MakeUpdateUtxoHistorySpent(chainType), []any{height, hash, blockTime}},
+		{mutilchainquery.MakeAddUtxoAgeBuckets(chainType), []any{height, utxoAgeBucketSeconds}},
+		{mutilchainquery.MakeSubtractUtxoAgeBuckets(chainType), []any{height, utxoAgeBucketSeconds}},
+		{mutilchainquery.MakeInsertCoinAge(chainType), []any{height, blockTime}},
+		{mutilchainquery.MakeInsertCoinAgeBands(chainType), []any{height, blockTime}},
+		{mutilchainquery.MakeInsertMcaSnapshot(chainType), []any{height, blockTime}},
+	}
+	for _, stmt := range stmts {
+		if _, err = dbtx.ExecContext(ctx, stmt.query, stmt.args...); err != nil {
+			_ = dbtx.Rollback()
+			return false, err
+		}
+	}
+	return true, dbtx.Commit()
+}
+
+// SyncMutilchainCoinAge brings the coin age tables of a BTC or LTC chain up to
+// the best stored block, less coinAgeConfirmations. The blocks are processed
+// in height order from the lowest stored block, and the blocks that are
+// missing or whose transactions are not all stored are skipped, so the
+// outputs they create are left out of the coin age. It returns at once when
+// the chain is already being synced, so it may be called for every new block.
+func (pgb *ChainDB) SyncMutilchainCoinAge(chainType string) {
+	if pgb.ChainDBDisabled {
+		return
+	}
+	mtx := pgb.coinAgeSyncMtx(chainType)
+	if mtx == nil || !mtx.TryLock() {
+		return
+	}
+	defer mtx.Unlock()
+
+	if err := pgb.syncMutilchainDailyMarket(chainType); err != nil {
+		// The realized cap is valued at the last stored price.
+		log.Warnf("%s: failed to sync daily market data: %v", chainType, err)
+	}
+
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	var height, bestHeight int64
+	err := pgb.db.QueryRowContext(ctx, mutilchainquery.MakeSelectCoinAgeMaxHeight(chainType)).Scan(&height)
+	if err == nil {
+		bestHeight, _, err = RetrieveMutilchainBestBlock(ctx, pgb.db, chainType)
+	}
+	cancel()
+	if err != nil {
+		log.Errorf("%s: failed to get the coin age sync heights: %v", chainType, pgb.replaceCancelError(err))
+		return
+	}
+
+	start := time.Now()
+	for height++; height <= bestHeight-coinAgeConfirmations && pgb.ctx.Err() == nil; height++ {
+		stored, err := pgb.storeMutilchainCoinAge(chainType, height)
+		if err != nil {
+			log.Errorf("%s: failed to store coin age of block %d: %v", chainType, height,
+				pgb.replaceCancelError(err))
+			return
+		}
+		if !stored {
+			next, err := pgb.nextMutilchainBlockHeight(chainType, height+1)
+			if err != nil {
+				log.Errorf("%s: failed to get the next stored block after %d: %v", chainType, height, err)
+				return
+			}
+			if next < 0 || next > bestHeight-coinAgeConfirmations {
+				break
+			}
+			log.Debugf("%s: coin age sync skipped blocks %d to %d, which are not stored", chainType,
+				height, next-1)
+			height = next - 1
+			continue
+		}
+		if height%10000 == 0 {
+			log.Infof("%s: coin age synced to block %d", chainType, height)
+		}
+	}
+	log.Debugf("%s: coin age synced to block %d in %v", chainType, height-1, time.Since(start))
+}
+
+// chartMutilchainCoinAge fetches the per-block coin age data above the
+// CoinAge height. This is the Fetcher half of a pair that make up a
+// cache.ChartMutilchainUpdater. The Appender half is appendMutilchainCoinAge.
+func (pgb *ChainDB) chartMutilchainCoinAge(charts *cache.MutilchainChartData) (*sql.Rows, func(), error) {
+	ctx, cancel := context.WithCancel(pgb.ctx)
+	rows, err := pgb.db.QueryContext(ctx, mutilchainquery.MakeSelectCoinAgeChartRows(charts.ChainType),
+		charts.CoinAgeTip())
+	if err != nil {
+		return nil, cancel, fmt.Errorf("%s: chartCoinAge: %w", charts.ChainType, pgb.replaceCancelError(err))
+	}
+	return rows, cancel, nil
+}
+
+// appendMutilchainCoinAge appends the results of chartMutilchainCoinAge to the
+// CoinAge zoomSet. Coin days are in coins times days.
+func appendMutilchainCoinAge(charts *cache.MutilchainChartData, rows *sql.Rows) error {
+	defer closeRows(rows)
+	coinAge := charts.CoinAge
+	for rows.Next() {
+		var height, blockTime uint64
+		var cdd, avgAge, totalCoinDays, meanAge, realizedCap float64
+		if err := rows.Scan(&height, &blockTime, &cdd, &avgAge, &totalCoinDays, &meanAge, &realizedCap); err != nil {
+			return err
+		}
+		coinAge.Height = append(coinAge.Height, height)
+		coinAge.Time = append(coinAge.Time, blockTime)
+		coinAge.CoinDaysDestroyed = append(coinAge.CoinDaysDestroyed, cdd/satoshisPerCoin)
+		coinAge.AvgCoinAge = append(coinAge.AvgCoinAge, avgAge)
+		coinAge.TotalCoinDays = append(coinAge.TotalCoinDays, totalCoinDays/satoshisPerCoin)
+		coinAge.MeanCoinAge = append(coinAge.MeanCoinAge, meanAge)
+		coinAge.RealizedCap = append(coinAge.RealizedCap, realizedCap)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("appendMutilchainCoinAge: iteration error: %w", err)
+	}
+	return nil
+}
+
+// chartMutilchainCoinAgeBands fetches the unspent value by age band of the
+// blocks above the CoinAgeBands height. This is the Fetcher half of a pair
+// that make up a cache.ChartMutilchainUpdater. The Appender half is
+// appendMutilchainCoinAgeBands.
+func (pgb *ChainDB) chartMutilchainCoinAgeBands(charts *cache.MutilchainChartData) (*sql.Rows, func(), error) {
+	ctx, cancel := context.WithCancel(pgb.ctx)
+	rows, err := pgb.db.QueryContext(ctx, mutilchainquery.MakeSelectCoinAgeBandsChartRows(charts.ChainType),
+		charts.CoinAgeBandsTip())
+	if err != nil {
+		return nil, cancel, fmt.Errorf("%s: chartCoinAgeBands: %w", charts.ChainType, pgb.replaceCancelError(err))
+	}
+	return rows, cancel, nil
+}
+
+// appendMutilchainCoinAgeBands appends the results of
+// chartMutilchainCoinAgeBands to the CoinAge zoomSet, up to the CoinAge
+// height. Blocks without unspent outputs have empty bands.
+func appendMutilchainCoinAgeBands(charts *cache.MutilchainChartData, rows *sql.Rows) error {
+	defer closeRows(rows)
+	coinAge := charts.CoinAge
+	var band *dbtypes.AgeBandData
+	var bandHeight uint64
+	for rows.Next() {
+		var height uint64
+		var ageBand string
+		var value int64
+		if err := rows.Scan(&height, &ageBand, &value); err != nil {
+			return err
+		}
+		if band == nil || height != bandHeight {
+			n := len(coinAge.CoinAgeBands)
+			for n < len(coinAge.Height) && coinAge.Height[n] < height {
+				coinAge.CoinAgeBands = append(coinAge.CoinAgeBands, &dbtypes.AgeBandData{})
+				n++
+			}
+			if n == len(coinAge.Height) || coinAge.Height[n] != height {
+				// Past the CoinAge height.
+				break
+			}
+			band, bandHeight = &dbtypes.AgeBandData{}, height
+			coinAge.CoinAgeBands = append(coinAge.CoinAgeBands, band)
+		}
+		setAgeBandItemData(ageBand, band, float64(value)/satoshisPerCoin)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("appendMutilchainCoinAgeBands: iteration error: %w", err)
+	}
+	return nil
+}
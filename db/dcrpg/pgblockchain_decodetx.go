@@ -0,0 +1,306 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+	btc_chainhash "github.com/btcsuite/btcd/chaincfg/chainhash"
+	btcwire "github.com/btcsuite/btcd/wire"
+	"github.com/decred/dcrdata/v8/mutilchain"
+	"github.com/decred/dcrdata/v8/mutilchain/btcrpcutils"
+	"github.com/decred/dcrdata/v8/mutilchain/ltcrpcutils"
+	"github.com/decred/dcrdata/v8/txhelpers"
+	"github.com/decred/dcrdata/v8/txhelpers/btctxhelper"
+	"github.com/decred/dcrdata/v8/txhelpers/ltctxhelper"
+	ltcjson "github.com/ltcsuite/ltcd/btcjson"
+	ltc_chainhash "github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/ltcutil"
+	ltcwire "github.com/ltcsuite/ltcd/wire"
+)
+
+// decodeTxNode abstracts the BTC and LTC node calls needed to resolve the
+// inputs of a decoded transaction that are not in the database.
+type decodeTxNode struct {
+	// prevOut gets the value and pkScript of an outpoint.
+	prevOut func(txid string, vout uint32) (int64, []byte, error)
+	// unspent indicates if an outpoint is in the node's UTXO set, including
+	// the mempool.
+	unspent func(txid string, vout uint32) (bool, error)
+	// confirmations gets the number of confirmations of a transaction known
+	// to the node. Zero means the transaction is in the mempool.
+	confirmations func(txid string) (int64, error)
+	setPrevOut    func(vin *txhelpers.DecodedMultichainVin, value int64, pkScript []byte)
+	bestHeight    func() int64
+}
+
+func (pgb *ChainDB) btcDecodeTxNode() *decodeTxNode {
+	return &decodeTxNode{
+		prevOut: func(txid string, vout uint32) (int64, []byte, error) {
+			hash, err := btc_chainhash.NewHashFromStr(txid)
+			if err != nil {
+				return 0, nil, err
+			}
+			tx, err := btcrpcutils.WithTimeout(func() (*btcutil.Tx, error) {
+				return pgb.BtcClient.GetRawTransaction(hash)
+			})
+			if err != nil {
+				return 0, nil, err
+			}
+			txOuts := tx.MsgTx().TxOut
+			if int(vout) >= len(txOuts) {
+				return 0, nil, fmt.Errorf("output %d of %s does not exist", vout, txid)
+			}
+			return txOuts[vout].Value, txOuts[vout].PkScript, nil
+		},
+		unspent: func(txid string, vout uint32) (bool, error) {
+			hash, err := btc_chainhash.NewHashFromStr(txid)
+			if err != nil {
+				return false, err
+			}
+			txOut, err := btcrpcutils.WithTimeout(func() (*btcjson.GetTxOutResult, error) {
+				return pgb.BtcClient.GetTxOut(hash, vout, true)
+			})
+			return txOut != nil, err
+		},
+		confirmations: func(txid string) (int64, error) {
+			hash, err := btc_chainhash.NewHashFromStr(txid)
+			if err != nil {
+				return 0, err
+			}
+			tx, err := btcrpcutils.WithTimeout(func() (*btcjson.TxRawResult, error) {
+				return pgb.BtcClient.GetRawTransactionVerbose(hash)
+			})
+			if err != nil {
+				return 0, err
+			}
+			return int64(tx.Confirmations), nil
+		},
+		setPrevOut: func(vin *txhelpers.DecodedMultichainVin, value int64, pkScript []byte) {
+			btctxhelper.SetDecodedPrevOut(vin, value, pkScript, pgb.btcChainParams)
+		},
+		bestHeight: func() int64 {
+			_, height := pgb.BTCBestBlock()
+			return height
+		},
+	}
+}
+
+func (pgb *ChainDB) ltcDecodeTxNode() *decodeTxNode {
+	return &decodeTxNode{
+		prevOut: func(txid string, vout uint32) (int64, []byte, error) {
+			hash, err := ltc_chainhash.NewHashFromStr(txid)
+			if err != nil {
+				return 0, nil, err
+			}
+			tx, err := ltcrpcutils.WithTimeout(func() (*ltcutil.Tx, error) {
+				return pgb.LtcClient.GetRawTransaction(hash)
+			})
+			if err != nil {
+				return 0, nil, err
+			}
+			txOuts := tx.MsgTx().TxOut
+			if int(vout) >= len(txOuts) {
+				return 0, nil, fmt.Errorf("output %d of %s does not exist", vout, txid)
+			}
+			return txOuts[vout].Value, txOuts[vout].PkScript, nil
+		},
+		unspent: func(txid string, vout uint32) (bool, error) {
+			hash, err := ltc_chainhash.NewHashFromStr(txid)
+			if err != nil {
+				return false, err
+			}
+			txOut, err := ltcrpcutils.WithTimeout(func() (*ltcjson.GetTxOutResult, error) {
+				return pgb.LtcClient.GetTxOut(hash, vout, true)
+			})
+			return txOut != nil, err
+		},
+		confirmations: func(txid string) (int64, error) {
+			hash, err := ltc_chainhash.NewHashFromStr(txid)
+			if err != nil {
+				return 0, err
+			}
+			tx, err := ltcrpcutils.WithTimeout(func() (*ltcjson.TxRawResult, error) {
+				return pgb.LtcClient.GetRawTransactionVerbose(hash)
+			})
+			if err != nil {
+				return 0, err
+			}
+			return int64(tx.Confirmations), nil
+		},
+		setPrevOut: func(vin *txhelpers.DecodedMultichainVin, value int64, pkScript []byte) {
+			ltctxhelper.SetDecodedPrevOut(vin, value, pkScript, pgb.ltcChainParams)
+		},
+		bestHeight: func() int64 {
+			_, height := pgb.LTCBestBlock()
+			return height
+		},
+	}
+}
+
+// MutilchainDecodeRawTransaction decodes a hex encoded BTC or LTC transaction,
+// or a hex or base64 encoded PSBT. The previous outputs of the inputs are
+// looked up in the database first and then with the node, so the fee is known
+// for transactions spending unconfirmed outputs as well. Inputs that are
+// already spent by a different transaction are reported in the warnings.
+func (pgb *ChainDB) MutilchainDecodeRawTransaction(rawTx, chainType string) (*txhelpers.DecodedMultichainTx, error) {
+	var dtx *txhelpers.DecodedMultichainTx
+	var node *decodeTxNode
+	var haveNode bool
+	var err error
+	switch chainType {
+	case mutilchain.TYPEBTC:
+		dtx, err = btctxhelper.DecodeRawTxOrPSBT(rawTx, pgb.btcChainParams)
+		node, haveNode = pgb.btcDecodeTxNode(), pgb.BtcClient != nil
+	case mutilchain.TYPELTC:
+		dtx, err = ltctxhelper.DecodeRawTxOrPSBT(rawTx, pgb.ltcChainParams)
+		node, haveNode = pgb.ltcDecodeTxNode(), pgb.LtcClient != nil
+	default:
+		return nil, fmt.Errorf("unsupported chain type %s", chainType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Is the transaction itself already known?
+	if haveNode {
+		if confs, err := node.confirmations(dtx.TxID); err == nil {
+			if confs > 0 {
+				dtx.Confirmed = true
+				dtx.BlockHeight = node.bestHeight() - confs + 1
+			} else {
+				dtx.InMempool = true
+			}
+		}
+	}
+	known := dtx.Confirmed || dtx.InMempool
+
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+
+	for _, vin := range dtx.Vin {
+		if vin.Coinbase {
+			continue
+		}
+		if !vin.PrevFound {
+			value, pkScript, err := RetrieveMutilchainVoutValueAndPkScript(ctx, pgb.db,
+				vin.PrevTxID, vin.PrevVout, chainType)
+			switch {
+			case err == nil:
+				node.setPrevOut(vin, value, pkScript)
+			case errors.Is(err, sql.ErrNoRows):
+				// Not in the DB, possibly unconfirmed. Ask the node.
+				if !haveNode {
+					break
+				}
+				value, pkScript, err = node.prevOut(vin.PrevTxID, vin.PrevVout)
+				if err != nil {
+					log.Debugf("Previous output %s:%d not found: %v", vin.PrevTxID, vin.PrevVout, err)
+					break
+				}
+				node.setPrevOut(vin, value, pkScript)
+				vin.Unconfirmed = true
+			default:
+				return nil, pgb.replaceCancelError(err)
+			}
+		}
+		if !vin.PrevFound {
+			dtx.AddWarning(fmt.Sprintf("input %d spends unknown output %s:%d", vin.Index, vin.PrevTxID, vin.PrevVout))
+			continue
+		}
+
+		_, spendingTx, _, err := RetrieveMutilchainSpendingTxByTxOut(ctx, pgb.db,
+			vin.PrevTxID, vin.PrevVout, chainType)
+		switch {
+		case err == nil:
+			vin.Spent = true
+			vin.SpendingTxID = spendingTx
+		case errors.Is(err, sql.ErrNoRows):
+			// The DB only has confirmed spends. The node's UTXO set
+			// includes the mempool.
+			if !haveNode {
+				break
+			}
+			if unspent, err := node.unspent(vin.PrevTxID, vin.PrevVout); err == nil && !unspent {
+				vin.Spent = true
+			}
+		default:
+			return nil, pgb.replaceCancelError(err)
+		}
+		if !vin.Spent {
+			continue
+		}
+		if vin.SpendingTxID == dtx.TxID || (vin.SpendingTxID == "" && known) {
+			continue
+		}
+		if vin.SpendingTxID != "" {
+			dtx.AddWarning(fmt.Sprintf("input %d is already spent by %s", vin.Index, vin.SpendingTxID))
+		} else {
+			dtx.AddWarning(fmt.Sprintf("input %d is already spent by another transaction, possibly in the mempool", vin.Index))
+		}
+	}
+
+	dtx.ComputeFee()
+	return dtx, nil
+}
+
+// MutilchainSendRawTransaction broadcasts a hex encoded BTC or LTC transaction,
+// or a finalized PSBT, and returns the transaction ID.
+func (pgb *ChainDB) MutilchainSendRawTransaction(rawTx, chainType string) (string, error) {
+	var dtx *txhelpers.DecodedMultichainTx
+	var err error
+	switch chainType {
+	case mutilchain.TYPEBTC:
+		dtx, err = btctxhelper.DecodeRawTxOrPSBT(rawTx, pgb.btcChainParams)
+	case mutilchain.TYPELTC:
+		dtx, err = ltctxhelper.DecodeRawTxOrPSBT(rawTx, pgb.ltcChainParams)
+	default:
+		return "", fmt.Errorf("unsupported chain type %s", chainType)
+	}
+	if err != nil {
+		return "", err
+	}
+	if !dtx.Complete {
+		return "", fmt.Errorf("PSBT is not finalized")
+	}
+
+	switch chainType {
+	case mutilchain.TYPEBTC:
+		if pgb.BtcClient == nil {
+			return "", fmt.Errorf("BTC node is not available")
+		}
+		msgTx := btcwire.NewMsgTx(btcwire.TxVersion)
+		if err = msgTx.Deserialize(bytes.NewReader(dtx.SerializedTx)); err != nil {
+			return "", err
+		}
+		hash, err := btcrpcutils.WithTimeout(func() (*btc_chainhash.Hash, error) {
+			return pgb.BtcClient.SendRawTransaction(msgTx, false)
+		})
+		if err != nil {
+			return "", err
+		}
+		return hash.String(), nil
+	default:
+		if pgb.LtcClient == nil {
+			return "", fmt.Errorf("LTC node is not available")
+		}
+		msgTx := ltcwire.NewMsgTx(ltcwire.TxVersion)
+		if err = msgTx.Deserialize(bytes.NewReader(dtx.SerializedTx)); err != nil {
+			return "", err
+		}
+		hash, err := ltcrpcutils.WithTimeout(func() (*ltc_chainhash.Hash, error) {
+			return pgb.LtcClient.SendRawTransaction(msgTx, false)
+		})
+		if err != nil {
+			return "", err
+		}
+		return hash.String(), nil
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal"
+)
+
+// exchangePriceTickRetention is how long the price updates of the exchange
+// markets are kept. The candlesticks hold the longer history.
+const exchangePriceTickRetention = 30 * 24 * time.Hour
+
+// StoreExchangeCandles upserts the candlesticks of an exchange market, since
+// the last one is updated until its bin closes.
+func (pgb *ChainDB) StoreExchangeCandles(chain, token, bin string, candles []*dbtypes.ExchangeCandle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	dbtx, err := pgb.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin database transaction: %w", pgb.replaceCancelError(err))
+	}
+	stmt, err := dbtx.PrepareContext(ctx, internal.UpsertExchangeCandlestick)
+	if err != nil {
+		_ = dbtx.Rollback()
+		return pgb.replaceCancelError(err)
+	}
+	defer stmt.Close()
+	for _, c := range candles {
+		_, err = stmt.ExecContext(ctx, chain, token, bin, c.Start, c.Open, c.High, c.Low, c.Close, c.Volume)
+		if err != nil {
+			_ = dbtx.Rollback()
+			return fmt.Errorf("upsert %s %s %s candlestick: %w", chain, token, bin,
+				pgb.replaceCancelError(err))
+		}
+	}
+	return dbtx.Commit()
+}
+
+// StoreExchangeDepth stores a snapshot of the order book of an exchange
+// market, encoded as JSON.
+func (pgb *ChainDB) StoreExchangeDepth(chain, token string, stamp time.Time, depthJSON []byte) error {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	_, err := pgb.db.ExecContext(ctx, internal.InsertExchangeDepthSnapshot, chain, token, stamp, depthJSON)
+	return pgb.replaceCancelError(err)
+}
+
+// StoreExchangePrice stores a price update of an exchange market, and deletes
+// the updates of the market older than exchangePriceTickRetention.
+func (pgb *ChainDB) StoreExchangePrice(chain, token string, tick *dbtypes.ExchangePriceTick) error {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	dbtx, err := pgb.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin database transaction: %w", pgb.replaceCancelError(err))
+	}
+	_, err = dbtx.ExecContext(ctx, internal.InsertExchangePriceTick, chain, token, tick.Stamp,
+		tick.Price, tick.BaseVolume, tick.Volume, tick.Change)
+	if err != nil {
+		_ = dbtx.Rollback()
+		return pgb.replaceCancelError(err)
+	}
+	_, err = dbtx.ExecContext(ctx, internal.DeleteOldExchangePriceTicks, chain, token,
+		tick.Stamp.Add(-exchangePriceTickRetention))
+	if err != nil {
+		_ = dbtx.Rollback()
+		return fmt.Errorf("delete old %s %s price ticks: %w", chain, token, pgb.replaceCancelError(err))
+	}
+	return dbtx.Commit()
+}
+
+func (pgb *ChainDB) queryExchangeCandles(query string, args ...any) ([]*dbtypes.ExchangeCandle, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	rows, err := pgb.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	defer closeRows(rows)
+	candles := make([]*dbtypes.ExchangeCandle, 0)
+	for rows.Next() {
+		var c dbtypes.ExchangeCandle
+		if err = rows.Scan(&c.Start, &c.Open, &c.High, &c.Low, &c.Close, &c.Volume); err != nil {
+			return nil, err
+		}
+		candles = append(candles, &c)
+	}
+	return candles, rows.Err()
+}
+
+// ExchangeCandles are the stored candlesticks of an exchange market that
+// start in [from, to], in time order.
+func (pgb *ChainDB) ExchangeCandles(chain, token, bin string, from, to time.Time) ([]*dbtypes.ExchangeCandle, error) {
+	return pgb.queryExchangeCandles(internal.SelectExchangeCandlesticks, chain, token, bin, from, to)
+}
+
+// LastExchangeCandles are the n most recent stored candlesticks of an
+// exchange market, in time order.
+func (pgb *ChainDB) LastExchangeCandles(chain, token, bin string, n int) ([]*dbtypes.ExchangeCandle, error) {
+	return pgb.queryExchangeCandles(internal.SelectLastExchangeCandlesticks, chain, token, bin, n)
+}
@@ -0,0 +1,240 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/decred/dcrdata/v8/db/cache"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/hdwallet"
+	"github.com/decred/dcrdata/v8/mutilchain"
+	"github.com/lib/pq"
+
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal"
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal/mutilchainquery"
+)
+
+// hdWalletTxsLimit is the number of latest transactions of an HDWallet.
+const hdWalletTxsLimit = 100
+
+// MutilchainAddressUTXO returns the unspent transaction outputs (UTXOs) paying
+// to the specified BTC or LTC address in a []*dbtypes.MutilchainAddressTxnOutput.
+func (pgb *ChainDB) MutilchainAddressUTXO(address, chainType string) ([]*dbtypes.MutilchainAddressTxnOutput, bool, error) {
+	if !pgb.IsMutilchainValidAddress(chainType, address) {
+		return nil, false, fmt.Errorf("invalid %s address %q", chainType, address)
+	}
+
+	// Check the cache first.
+	bestHash, height := pgb.GetMutilchainHashHeight(chainType)
+	utxos, validBlock := pgb.AddressCache.MutilchainUTXOs(address, chainType)
+	if utxos != nil && validBlock != nil {
+		return utxos, false, nil
+	}
+
+	busy, wait, done := pgb.CacheLocks.utxo.TryLock(address)
+	if busy {
+		<-wait
+		// Try again, starting with the cache.
+		return pgb.MutilchainAddressUTXO(address, chainType)
+	}
+	defer done()
+
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	txnOutputs, err := RetrieveMutilchainAddressDbUTXOs(ctx, pgb.db, address, chainType)
+	if err != nil {
+		return nil, false, pgb.replaceCancelError(err)
+	}
+
+	// Update the address cache.
+	cacheUpdated := pgb.AddressCache.StoreMutilchainUTXOs(address, txnOutputs,
+		cache.NewMutilchainBlockID(bestHash, height), chainType)
+	return txnOutputs, cacheUpdated, nil
+}
+
+// usedAddresses reports which of the addresses have received funds.
+func (pgb *ChainDB) usedAddresses(chainType string, addrs []string) (map[string]bool, error) {
+	query := internal.SelectUsedAddresses
+	if chainType != mutilchain.TYPEDCR {
+		query = mutilchainquery.MakeSelectUsedAddresses(chainType)
+	}
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	rows, err := pgb.db.QueryContext(ctx, query, pq.Array(addrs))
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	defer closeRows(rows)
+	used := make(map[string]bool)
+	for rows.Next() {
+		var addr string
+		if err = rows.Scan(&addr); err != nil {
+			return nil, err
+		}
+		used[addr] = true
+	}
+	return used, rows.Err()
+}
+
+// addressesTxs retrieves the latest transactions funding or spending any of
+// the addresses, up to limit.
+func (pgb *ChainDB) addressesTxs(chainType string, addrs []string, limit int) ([]*dbtypes.HDWalletTx, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	txs := make([]*dbtypes.HDWalletTx, 0)
+	if chainType == mutilchain.TYPEDCR {
+		rows, err := pgb.db.QueryContext(ctx, internal.SelectAddressesTxs, pq.Array(addrs), limit)
+		if err != nil {
+			return nil, pgb.replaceCancelError(err)
+		}
+		defer closeRows(rows)
+		for rows.Next() {
+			var tx dbtypes.HDWalletTx
+			var blockTime dbtypes.TimeDef
+			if err = rows.Scan(&tx.TxHash, &blockTime, &tx.Received, &tx.Sent); err != nil {
+				return nil, err
+			}
+			tx.BlockTime = blockTime.UNIX()
+			txs = append(txs, &tx)
+		}
+		return txs, rows.Err()
+	}
+	rows, err := pgb.db.QueryContext(ctx, mutilchainquery.MakeSelectAddressesTxs(chainType),
+		pq.Array(addrs), limit)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	defer closeRows(rows)
+	for rows.Next() {
+		var tx dbtypes.HDWalletTx
+		if err = rows.Scan(&tx.TxHash, &tx.BlockTime, &tx.Received, &tx.Sent); err != nil {
+			return nil, err
+		}
+		txs = append(txs, &tx)
+	}
+	return txs, rows.Err()
+}
+
+// HDWallet scans the branches of an extended public key or output descriptor
+// for used addresses with a gap limit, and aggregates their balances, UTXOs
+// and latest transactions. The key is only used to derive the addresses and
+// is neither stored nor logged. Errors wrapping hdwallet.ErrInvalidKey are
+// the caller's, and those wrapping hdwallet.ErrScanLimit are returned before
+// querying the balances of a wallet with more used addresses than a request
+// may cost.
+func (pgb *ChainDB) HDWallet(chainType, key string, gapLimit int) (*dbtypes.HDWallet, error) {
+	w, err := hdwallet.Parse(chainType, key, &hdwallet.Params{
+		DCR: pgb.chainParams,
+		BTC: pgb.btcChainParams,
+		LTC: pgb.ltcChainParams,
+	})
+	if err != nil {
+		return nil, err
+	}
+	scans, err := w.Scan(gapLimit, func(addrs []string) (map[string]bool, error) {
+		return pgb.usedAddresses(chainType, addrs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	wallet := &dbtypes.HDWallet{
+		Chain:      chainType,
+		ScriptType: w.ScriptType,
+		GapLimit:   gapLimit,
+		UTXOs:      make([]*dbtypes.HDWalletUTXO, 0),
+		TxsLimit:   hdWalletTxsLimit,
+	}
+	var addrs []string
+	for _, scan := range scans {
+		branch := &dbtypes.HDWalletBranch{
+			Name:      scan.Name,
+			Path:      scan.Path,
+			Addresses: make([]*dbtypes.HDWalletAddress, 0, len(scan.Used)),
+		}
+		if scan.Next != nil {
+			branch.NextIndex = scan.Next.Index
+			branch.NextAddress = scan.Next.Address
+		}
+		for _, used := range scan.Used {
+			addr := &dbtypes.HDWalletAddress{Path: used.Path, Address: used.Address}
+			var bal *dbtypes.AddressBalance
+			if chainType == mutilchain.TYPEDCR {
+				bal, _, err = pgb.AddressBalance(used.Address)
+			} else {
+				bal, _, err = pgb.MutilchainAddressBalance(used.Address, chainType)
+			}
+			if err != nil {
+				return nil, err
+			}
+			addr.Received = bal.TotalReceived
+			addr.Balance = bal.TotalUnspent
+			wallet.TotalReceived += bal.TotalReceived
+			wallet.TotalSent += bal.TotalSpent
+			wallet.Balance += bal.TotalUnspent
+
+			if err = pgb.appendHDWalletUTXOs(wallet, chainType, used); err != nil {
+				return nil, err
+			}
+			branch.Addresses = append(branch.Addresses, addr)
+			addrs = append(addrs, used.Address)
+		}
+		wallet.Branches = append(wallet.Branches, branch)
+	}
+	wallet.NumUsed = len(addrs)
+	sort.Slice(wallet.UTXOs, func(i, j int) bool {
+		return wallet.UTXOs[i].Height > wallet.UTXOs[j].Height
+	})
+
+	if len(addrs) == 0 {
+		wallet.Txs = make([]*dbtypes.HDWalletTx, 0)
+		return wallet, nil
+	}
+	wallet.Txs, err = pgb.addressesTxs(chainType, addrs, hdWalletTxsLimit)
+	if err != nil {
+		return nil, err
+	}
+	return wallet, nil
+}
+
+// appendHDWalletUTXOs appends the cached or queried UTXOs of a used address.
+func (pgb *ChainDB) appendHDWalletUTXOs(wallet *dbtypes.HDWallet, chainType string, used *hdwallet.Address) error {
+	if chainType == mutilchain.TYPEDCR {
+		utxos, _, err := pgb.AddressUTXO(used.Address)
+		if err != nil {
+			return err
+		}
+		for _, utxo := range utxos {
+			wallet.UTXOs = append(wallet.UTXOs, &dbtypes.HDWalletUTXO{
+				Path:      used.Path,
+				Address:   used.Address,
+				TxHash:    utxo.TxHash.String(),
+				Vout:      utxo.Vout,
+				Height:    int64(utxo.Height),
+				BlockTime: utxo.BlockTime,
+				Value:     utxo.Atoms,
+			})
+		}
+		return nil
+	}
+	utxos, _, err := pgb.MutilchainAddressUTXO(used.Address, chainType)
+	if err != nil {
+		return err
+	}
+	for _, utxo := range utxos {
+		wallet.UTXOs = append(wallet.UTXOs, &dbtypes.HDWalletUTXO{
+			Path:      used.Path,
+			Address:   used.Address,
+			TxHash:    utxo.TxHash,
+			Vout:      utxo.Vout,
+			Height:    int64(utxo.Height),
+			BlockTime: utxo.BlockTime,
+			Value:     utxo.Atoms,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/btcjson"
+	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v4"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/mutilchain"
+	"github.com/decred/dcrdata/v8/mutilchain/labels"
+	ltcjson "github.com/ltcsuite/ltcd/btcjson"
+
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal"
+)
+
+// newLabelSet creates the label set of the labels embedded from labels.json.
+// The legacy project fund address of the network is labeled even if it is
+// not a mainnet address listed in labels.json.
+func newLabelSet(devAddress string) *labels.Set {
+	defs := *labels.Default()
+	if devAddress != "" {
+		listed := false
+		for _, label := range defs.DCR {
+			listed = listed || label.Address == devAddress
+		}
+		if !listed {
+			defs.DCR = append(defs.DCR[:len(defs.DCR):len(defs.DCR)], &labels.Label{
+				Address:  devAddress,
+				Name:     "Legacy project fund",
+				Category: labels.CategoryDevFund,
+				Source:   labels.SourceFile,
+			})
+		}
+	}
+	return labels.NewSet(&defs)
+}
+
+// CheckAndCreateLabelsTable creates the table of the address labels added by
+// the operators if it does not exist.
+func (pgb *ChainDB) CheckAndCreateLabelsTable() error {
+	if err := createTable(pgb.db, "address_labels", internal.CreateAddressLabelsTable); err != nil {
+		return fmt.Errorf("failed to create address_labels table: %w", err)
+	}
+	return nil
+}
+
+// LoadAddressLabels loads the address labels added by the operators over the
+// labels embedded from labels.json.
+func (pgb *ChainDB) LoadAddressLabels() error {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	rows, err := pgb.db.QueryContext(ctx, internal.SelectAddressLabels)
+	if err != nil {
+		return pgb.replaceCancelError(err)
+	}
+	defer closeRows(rows)
+	var count int
+	for rows.Next() {
+		var chain string
+		label := &labels.Label{Source: labels.SourceAdmin}
+		err = rows.Scan(&chain, &label.Address, &label.Name, &label.Category, &label.Link)
+		if err != nil {
+			return err
+		}
+		pgb.labels.Put(chain, label)
+		count++
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	log.Infof("Loaded %d address labels (labels.json version %d).", count,
+		labels.Default().Version)
+	return nil
+}
+
+// AddressLabel retrieves the label of an address of a chain, or nil.
+func (pgb *ChainDB) AddressLabel(chain, address string) *labels.Label {
+	return pgb.labels.Lookup(chain, address)
+}
+
+// AddressLabels retrieves the label names of the labeled addresses of a chain
+// among addrs.
+func (pgb *ChainDB) AddressLabels(chain string, addrs []string) map[string]string {
+	return pgb.labels.Names(chain, addrs)
+}
+
+// Labels retrieves the address labels of a chain by name.
+func (pgb *ChainDB) Labels(chain string) []*labels.Label {
+	return pgb.labels.List(chain)
+}
+
+// SearchLabels retrieves up to limit address labels of all the chains whose
+// name contains the query, or whose address is the query.
+func (pgb *ChainDB) SearchLabels(query string, limit int) []*labels.Match {
+	return pgb.labels.Search(query, limit)
+}
+
+// PutAddressLabel stores the label added by an operator for an address of a
+// chain, replacing any label of the address.
+func (pgb *ChainDB) PutAddressLabel(chain string, label *labels.Label) error {
+	if !labels.SupportedChain(chain) {
+		return fmt.Errorf("unsupported chain %q", chain)
+	}
+	if err := label.Validate(); err != nil {
+		return err
+	}
+	label.Source = labels.SourceAdmin
+	_, err := pgb.db.Exec(internal.UpsertAddressLabel, chain, label.Address, label.Name,
+		label.Category, label.Link, time.Now().Unix())
+	if err != nil {
+		return err
+	}
+	pgb.labels.Put(chain, label)
+	return nil
+}
+
+// DeleteAddressLabel removes the label added by an operator for an address of
+// a chain. The error is dbtypes.ErrNoResult if there is no such label.
+func (pgb *ChainDB) DeleteAddressLabel(chain, address string) error {
+	res, err := pgb.db.Exec(internal.DeleteAddressLabel, chain, address)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return dbtypes.ErrNoResult
+	}
+	pgb.labels.Remove(chain, address)
+	return nil
+}
+
+// txLabels returns the distinct label names of the output addresses of a
+// transaction, for the transaction rows of the block pages.
+func (pgb *ChainDB) txLabels(chain string, addrs []string) []string {
+	var names []string
+	seen := make(map[string]struct{})
+	for _, addr := range addrs {
+		name := pgb.labels.Name(chain, addr)
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; !ok {
+			seen[name] = struct{}{}
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// dcrTxLabels returns the distinct label names of the output addresses of the
+// Decred transaction of a block.
+func (pgb *ChainDB) dcrTxLabels(vouts []chainjson.Vout) []string {
+	var addrs []string
+	for i := range vouts {
+		addrs = append(addrs, vouts[i].ScriptPubKey.Addresses...)
+	}
+	return pgb.txLabels(mutilchain.TYPEDCR, addrs)
+}
+
+// btcTxLabels returns the distinct label names of the output addresses of the
+// Bitcoin transaction of a block.
+func (pgb *ChainDB) btcTxLabels(vouts []btcjson.Vout) []string {
+	var addrs []string
+	for i := range vouts {
+		if vouts[i].ScriptPubKey.Address != "" {
+			addrs = append(addrs, vouts[i].ScriptPubKey.Address)
+		}
+		addrs = append(addrs, vouts[i].ScriptPubKey.Addresses...)
+	}
+	return pgb.txLabels(mutilchain.TYPEBTC, addrs)
+}
+
+// ltcTxLabels returns the distinct label names of the output addresses of the
+// Litecoin transaction of a block.
+func (pgb *ChainDB) ltcTxLabels(vouts []ltcjson.Vout) []string {
+	var addrs []string
+	for i := range vouts {
+		if vouts[i].ScriptPubKey.Address != "" {
+			addrs = append(addrs, vouts[i].ScriptPubKey.Address)
+		}
+		addrs = append(addrs, vouts[i].ScriptPubKey.Addresses...)
+	}
+	return pgb.txLabels(mutilchain.TYPELTC, addrs)
+}
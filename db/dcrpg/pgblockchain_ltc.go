@@ -297,6 +297,12 @@ func (pgb *ChainDB) LTCStore(blockData *blockdataltc.BlockData, msgBlock *ltcwir
 		if err != nil {
 			return err
 		}
+		if err = pgb.StoreLTCBlockPool(msgBlock, int64(blockData.Header.Height)); err != nil {
+			log.Errorf("LTC: failed to store pool of block %d: %v", blockData.Header.Height, err)
+		}
+		go pgb.SyncMutilchainCoinAge(mutilchain.TYPELTC)
+		go pgb.SyncMutilchainNewAddresses(mutilchain.TYPELTC)
+		go pgb.SyncWealth(mutilchain.TYPELTC)
 		if pgb.SyncChainDBFlag {
 			// TODO: go pgb.SyncOneLTCWholeBlock(pgb.LtcClient, msgBlock)
 		}
@@ -776,6 +782,7 @@ func (pgb *ChainDB) GetLTCExplorerBlock(hash string) *exptypes.BlockInfo {
 			return nil
 		}
 		exptx := trimmedLTCTxInfoFromMsgTx(pgb.LtcClient, tx, msgTx, pgb.ltcChainParams, prevTxCache)
+		exptx.Labels = pgb.ltcTxLabels(tx.Vout)
 		totalSent += exptx.Total
 		totalFees += exptx.FeeCoin
 		totalNumVins += int64(exptx.VinCount)
@@ -0,0 +1,423 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/decred/dcrdata/v8/db/cache"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/txhelpers"
+	"github.com/lib/pq"
+
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal"
+)
+
+// mixingSyncBatch is the number of blocks of mixing statistics stored in one
+// database transaction.
+const mixingSyncBatch = 2000
+
+// mixRoundBands is the number of re-mix round bands. The last band includes
+// the later rounds.
+const mixRoundBands = 4
+
+// mixingBlock is the mixing statistics of a block as they are computed.
+type mixingBlock struct {
+	height int64
+	time   time.Time
+	stats  dbtypes.MixingStats
+	denoms map[int64]*dbtypes.MixingDenom
+}
+
+// mixTx is a mix of a block range.
+type mixTx struct {
+	hash   string
+	height int64
+	mixed  int64
+}
+
+// CheckAndCreateMixingTables creates the tables of the mixing statistics if
+// they do not exist.
+func (pgb *ChainDB) CheckAndCreateMixingTables() error {
+	tables := [][2]string{
+		{"mixing_stats", internal.CreateMixingStatsTable},
+		{"mixing_denoms", internal.CreateMixingDenomsTable},
+		{"mix_rounds", internal.CreateMixRoundsTable},
+	}
+	for _, table := range tables {
+		if err := createTable(pgb.db, table[0], table[1]); err != nil {
+			return fmt.Errorf("failed to create %s table: %w", table[0], err)
+		}
+	}
+	return nil
+}
+
+// SyncMixingData stores the mixing statistics of the blocks above the last
+// block with stored statistics, up to the best block.
+func (pgb *ChainDB) SyncMixingData() error {
+	pgb.mixingSync.Lock()
+	defer pgb.mixingSync.Unlock()
+	lastHeight, err := pgb.mixingStatsHeight()
+	if err != nil {
+		return err
+	}
+	log.Infof("Start sync mixing statistics from height: %d", lastHeight+1)
+	return pgb.syncMixingData(lastHeight + 1)
+}
+
+// SyncMixingDataOnHeight stores the mixing statistics after a block is
+// connected at the height, replacing any statistics from that height, which
+// are of orphaned blocks.
+func (pgb *ChainDB) SyncMixingDataOnHeight(height int64) {
+	pgb.mixingSync.Lock()
+	defer pgb.mixingSync.Unlock()
+	lastHeight, err := pgb.mixingStatsHeight()
+	if err != nil {
+		log.Errorf("Sync mixing statistics on height %d failed. %v", height, err)
+		return
+	}
+	if err = pgb.syncMixingData(min(lastHeight+1, height)); err != nil {
+		log.Errorf("Sync mixing statistics on height %d failed. %v", height, err)
+	}
+}
+
+func (pgb *ChainDB) mixingStatsHeight() (int64, error) {
+	var height int64
+	err := pgb.db.QueryRowContext(pgb.ctx, internal.SelectMixingStatsMaxHeight).Scan(&height)
+	return height, pgb.replaceCancelError(err)
+}
+
+// syncMixingData stores the mixing statistics from the height to the best
+// block, in batches.
+func (pgb *ChainDB) syncMixingData(from int64) error {
+	from = max(from, 0)
+	best := pgb.Height()
+	denoms := txhelpers.MixDenoms()
+	for start := from; start <= best; start += mixingSyncBatch {
+		end := min(start+mixingSyncBatch-1, best)
+		if err := pgb.storeMixingRange(start, end, denoms); err != nil {
+			return fmt.Errorf("store mixing statistics of blocks %d to %d: %w", start, end, err)
+		}
+		if best-from > mixingSyncBatch {
+			log.Infof("Stored mixing statistics up to height %d of %d", end, best)
+		}
+	}
+	return nil
+}
+
+// storeMixingRange computes the mixing statistics of the main chain blocks
+// from start to end and replaces the stored statistics from start.
+func (pgb *ChainDB) storeMixingRange(start, end int64, denoms []int64) error {
+	ctx := pgb.ctx
+	denomsArg := pq.Array(denoms)
+
+	rows, err := pgb.db.QueryContext(ctx, internal.SelectMixingBlocks, start, end)
+	if err != nil {
+		return pgb.replaceCancelError(err)
+	}
+	defer closeRows(rows)
+	var blocks []*mixingBlock
+	byHeight := make(map[int64]*mixingBlock)
+	for rows.Next() {
+		block := &mixingBlock{denoms: make(map[int64]*dbtypes.MixingDenom)}
+		if err = rows.Scan(&block.height, &block.time); err != nil {
+			return err
+		}
+		blocks = append(blocks, block)
+		byHeight[block.height] = block
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	// Mixes, by denomination. A peer is assumed to have one change output.
+	mixRows, err := pgb.db.QueryContext(ctx, internal.SelectMixesInRange, start, end, denomsArg)
+	if err != nil {
+		return pgb.replaceCancelError(err)
+	}
+	defer closeRows(mixRows)
+	var mixes []*mixTx
+	for mixRows.Next() {
+		var mix mixTx
+		var denom, count, numVout int64
+		if err = mixRows.Scan(&mix.hash, &mix.height, &denom, &count, &numVout); err != nil {
+			return err
+		}
+		mix.mixed = denom * count
+		mixes = append(mixes, &mix)
+		block := byHeight[mix.height]
+		if block == nil {
+			continue
+		}
+		block.stats.Mixes++
+		block.stats.Participants += max(numVout-count, 0)
+		block.stats.Mixed += mix.mixed
+		d := block.denoms[denom]
+		if d == nil {
+			d = &dbtypes.MixingDenom{Denom: denom}
+			block.denoms[denom] = d
+		}
+		d.Mixes++
+		d.Mixed += mix.mixed
+	}
+	if err = mixRows.Err(); err != nil {
+		return err
+	}
+
+	rounds, err := pgb.mixRounds(ctx, mixes)
+	if err != nil {
+		return err
+	}
+
+	spendRows, err := pgb.db.QueryContext(ctx, internal.SelectMixedSpendsInRange, start, end, denomsArg)
+	if err != nil {
+		return pgb.replaceCancelError(err)
+	}
+	defer closeRows(spendRows)
+	for spendRows.Next() {
+		var height, spent, spendSecs, remixed int64
+		if err = spendRows.Scan(&height, &spent, &spendSecs, &remixed); err != nil {
+			return err
+		}
+		if block := byHeight[height]; block != nil {
+			block.stats.Spent, block.stats.SpendSecs, block.stats.Remixed = spent, spendSecs, remixed
+		}
+	}
+	if err = spendRows.Err(); err != nil {
+		return err
+	}
+
+	ticketRows, err := pgb.db.QueryContext(ctx, internal.SelectTicketsInRange, start, end, denomsArg)
+	if err != nil {
+		return pgb.replaceCancelError(err)
+	}
+	defer closeRows(ticketRows)
+	for ticketRows.Next() {
+		var height, tickets, mixedTickets int64
+		if err = ticketRows.Scan(&height, &tickets, &mixedTickets); err != nil {
+			return err
+		}
+		if block := byHeight[height]; block != nil {
+			block.stats.Tickets, block.stats.MixedTickets = tickets, mixedTickets
+		}
+	}
+	if err = ticketRows.Err(); err != nil {
+		return err
+	}
+
+	dbTx, err := pgb.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin database transaction: %w", err)
+	}
+	if err = storeMixingBlocks(ctx, dbTx, start, blocks, mixes, rounds); err != nil {
+		_ = dbTx.Rollback()
+		return pgb.replaceCancelError(err)
+	}
+	return dbTx.Commit()
+}
+
+// mixRounds finds the re-mix round of each mix, in block order. A mix is one
+// round after the highest round of the mixes of its mixed inputs, which are
+// either earlier in the batch or already stored.
+func (pgb *ChainDB) mixRounds(ctx context.Context, mixes []*mixTx) (map[string]int64, error) {
+	rounds := make(map[string]int64, len(mixes))
+	if len(mixes) == 0 {
+		return rounds, nil
+	}
+	hashes := make([]string, 0, len(mixes))
+	inBatch := make(map[string]bool, len(mixes))
+	for _, mix := range mixes {
+		hashes = append(hashes, mix.hash)
+		inBatch[mix.hash] = true
+	}
+
+	rows, err := pgb.db.QueryContext(ctx, internal.SelectMixesMixedInputs, pq.Array(hashes))
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	defer closeRows(rows)
+	funding := make(map[string][]string)
+	var stored []string
+	for rows.Next() {
+		var hash, prevHash string
+		if err = rows.Scan(&hash, &prevHash); err != nil {
+			return nil, err
+		}
+		funding[hash] = append(funding[hash], prevHash)
+		if !inBatch[prevHash] {
+			stored = append(stored, prevHash)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(stored) > 0 {
+		roundRows, err := pgb.db.QueryContext(ctx, internal.SelectMixRounds, pq.Array(stored))
+		if err != nil {
+			return nil, pgb.replaceCancelError(err)
+		}
+		defer closeRows(roundRows)
+		for roundRows.Next() {
+			var hash string
+			var round int64
+			if err = roundRows.Scan(&hash, &round); err != nil {
+				return nil, err
+			}
+			rounds[hash] = round
+		}
+		if err = roundRows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, mix := range mixes {
+		var prevRound int64
+		for _, prevHash := range funding[mix.hash] {
+			prevRound = max(prevRound, rounds[prevHash])
+		}
+		rounds[mix.hash] = prevRound + 1
+	}
+	return rounds, nil
+}
+
+func storeMixingBlocks(ctx context.Context, dbTx *sql.Tx, start int64, blocks []*mixingBlock,
+	mixes []*mixTx, rounds map[string]int64) error {
+	for _, stmt := range []string{internal.DeleteMixingStatsFrom,
+		internal.DeleteMixingDenomsFrom, internal.DeleteMixRoundsFrom} {
+		if _, err := dbTx.ExecContext(ctx, stmt, start); err != nil {
+			return err
+		}
+	}
+	for _, block := range blocks {
+		s := block.stats
+		_, err := dbTx.ExecContext(ctx, internal.InsertMixingStatsRow, block.height, block.time,
+			s.Mixes, s.Participants, s.Mixed, s.Remixed, s.Spent, s.SpendSecs, s.Tickets, s.MixedTickets)
+		if err != nil {
+			return err
+		}
+		for _, d := range block.denoms {
+			_, err = dbTx.ExecContext(ctx, internal.InsertMixingDenomsRow, block.height, d.Denom, d.Mixes, d.Mixed)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, mix := range mixes {
+		_, err := dbTx.ExecContext(ctx, internal.UpsertMixRoundRow, mix.hash, mix.height, rounds[mix.hash], mix.mixed)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MixingSummary retrieves the mixing statistics of the blocks above the
+// height, with their breakdown by denomination and by re-mix round.
+func (pgb *ChainDB) MixingSummary(height int64) (*dbtypes.MixingSummary, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	summary := &dbtypes.MixingSummary{
+		Denoms: make([]*dbtypes.MixingDenom, 0),
+		Rounds: make([]*dbtypes.MixRound, 0),
+	}
+	s := &summary.MixingStats
+	err := pgb.db.QueryRowContext(ctx, internal.SelectMixingSummary, height).Scan(&s.Mixes,
+		&s.Participants, &s.Mixed, &s.Remixed, &s.Spent, &s.SpendSecs, &s.Tickets, &s.MixedTickets)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+
+	rows, err := pgb.db.QueryContext(ctx, internal.SelectMixingDenomsSummary, height)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	defer closeRows(rows)
+	for rows.Next() {
+		d := new(dbtypes.MixingDenom)
+		if err = rows.Scan(&d.Denom, &d.Mixes, &d.Mixed); err != nil {
+			return nil, err
+		}
+		summary.Denoms = append(summary.Denoms, d)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	roundRows, err := pgb.db.QueryContext(ctx, internal.SelectMixRoundsSummary, height)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	defer closeRows(roundRows)
+	for roundRows.Next() {
+		r := new(dbtypes.MixRound)
+		if err = roundRows.Scan(&r.Round, &r.Mixes, &r.Mixed); err != nil {
+			return nil, err
+		}
+		summary.Rounds = append(summary.Rounds, r)
+	}
+	if err = roundRows.Err(); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// chartMixing fetches the mixing statistics above the tip of the Mixing chart
+// data.
+func (pgb *ChainDB) chartMixing(charts *cache.ChartData) (*sql.Rows, func(), error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+
+	rows, err := pgb.db.QueryContext(ctx, internal.SelectMixingChartRows, charts.MixingTip())
+	if err != nil {
+		return nil, cancel, fmt.Errorf("chartMixing: %w", pgb.replaceCancelError(err))
+	}
+	return rows, cancel, nil
+}
+
+// appendMixing appends the results from chartMixing to the Mixing chart data.
+// The denomination bands follow txhelpers.MixDenoms.
+func appendMixing(charts *cache.ChartData, rows *sql.Rows) error {
+	defer closeRows(rows)
+	denomIndex := make(map[int64]int)
+	for i, denom := range txhelpers.MixDenoms() {
+		denomIndex[denom] = i
+	}
+	mixing := charts.Mixing
+	for rows.Next() {
+		var height, mixes, participants, spent, spendSecs, tickets, mixedTickets int64
+		var blockTime time.Time
+		var denoms, denomsMixed, rounds, roundsMixed pq.Int64Array
+		err := rows.Scan(&height, &blockTime, &mixes, &participants, &spent, &spendSecs,
+			&tickets, &mixedTickets, &denoms, &denomsMixed, &rounds, &roundsMixed)
+		if err != nil {
+			return err
+		}
+		denomBands := make([]int64, len(denomIndex))
+		for i, denom := range denoms {
+			if j, ok := denomIndex[denom]; ok && i < len(denomsMixed) {
+				denomBands[j] += denomsMixed[i]
+			}
+		}
+		roundBands := make([]int64, mixRoundBands)
+		for i, round := range rounds {
+			if round >= 1 && round <= mixRoundBands && i < len(roundsMixed) {
+				roundBands[round-1] += roundsMixed[i]
+			}
+		}
+		mixing.Height = append(mixing.Height, uint64(height))
+		mixing.Time = append(mixing.Time, uint64(blockTime.Unix()))
+		mixing.Mixes = append(mixing.Mixes, uint64(mixes))
+		mixing.MixParticipants = append(mixing.MixParticipants, uint64(participants))
+		mixing.MixSpent = append(mixing.MixSpent, uint64(spent))
+		mixing.MixSpendSecs = append(mixing.MixSpendSecs, uint64(max(spendSecs, 0)))
+		mixing.TicketsBought = append(mixing.TicketsBought, uint64(tickets))
+		mixing.MixedTickets = append(mixing.MixedTickets, uint64(mixedTickets))
+		mixing.MixDenoms = append(mixing.MixDenoms, denomBands)
+		mixing.MixRounds = append(mixing.MixRounds, roundBands)
+	}
+	return rows.Err()
+}
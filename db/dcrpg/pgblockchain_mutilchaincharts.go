@@ -0,0 +1,254 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	btcblockchain "github.com/btcsuite/btcd/blockchain"
+	"github.com/decred/dcrdata/v8/db/cache"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/mutilchain"
+	ltcblockchain "github.com/ltcsuite/ltcd/blockchain"
+
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal/mutilchainquery"
+)
+
+// satoshisPerCoin is the number of atomic units in a BTC or LTC.
+const satoshisPerCoin = 1e8
+
+// mutilchainBlockSubsidy is the consensus block subsidy of a BTC or LTC block,
+// used when the coinbase transaction of a block is not stored.
+func (pgb *ChainDB) mutilchainBlockSubsidy(chainType string, height int64) int64 {
+	switch chainType {
+	case mutilchain.TYPEBTC:
+		return btcblockchain.CalcBlockSubsidy(int32(height), pgb.btcChainParams)
+	case mutilchain.TYPELTC:
+		return ltcblockchain.CalcBlockSubsidy(int32(height), pgb.ltcChainParams)
+	}
+	return 0
+}
+
+// chartMutilchainBlocks fetches the per-block BTC or LTC chart data above the
+// charts height from the blocks and transactions tables. This is the Fetcher
+// half of a pair that make up a cache.ChartMutilchainUpdater. The Appender half
+// is appendMutilchainChartBlocks.
+func (pgb *ChainDB) chartMutilchainBlocks(charts *cache.MutilchainChartData) (*sql.Rows, func(), error) {
+	ctx, cancel := context.WithCancel(pgb.ctx)
+	if !charts.UseSyncDB {
+		return nil, cancel, nil
+	}
+	rows, err := pgb.db.QueryContext(ctx, mutilchainquery.MakeSelectBlockChartStats(charts.ChainType), charts.Height())
+	if err != nil {
+		return nil, cancel, fmt.Errorf("%s: chartBlocks: %w", charts.ChainType, pgb.replaceCancelError(err))
+	}
+	return rows, cancel, nil
+}
+
+// appendMutilchainChartBlocks appends the results of chartMutilchainBlocks to
+// the Blocks zoomSet. Blocks are appended in height order until the first one
+// that is not stored yet. Without a synced database, the charts data is
+// requested from the external APIs instead.
+func (pgb *ChainDB) appendMutilchainChartBlocks(charts *cache.MutilchainChartData, rows *sql.Rows) error {
+	if !charts.UseSyncDB {
+		charts.UseAPI = true
+		return HandlerMutilchainAPIDataForCharts(charts)
+	}
+	charts.UseAPI = false
+	defer closeRows(rows)
+
+	blocks := charts.Blocks
+	for rows.Next() {
+		var height, blockTime, count, fees int64
+		var size, coinbase sql.NullInt64
+		var difficulty float64
+		if err := rows.Scan(&height, &size, &blockTime, &count, &difficulty, &fees, &coinbase); err != nil {
+			return err
+		}
+		next := int64(len(blocks.Height))
+		if height < next {
+			// A side chain block at a height already appended.
+			continue
+		}
+		if height > next || !size.Valid {
+			break
+		}
+		if fees < 0 {
+			fees = 0
+		}
+		subsidy := pgb.mutilchainBlockSubsidy(charts.ChainType, height)
+		if coinbase.Valid {
+			subsidy = max(coinbase.Int64-fees, 0)
+		}
+		blocks.Height = append(blocks.Height, uint64(height))
+		blocks.Time = append(blocks.Time, uint64(blockTime))
+		blocks.BlockSize = append(blocks.BlockSize, uint64(size.Int64))
+		blocks.TxCount = append(blocks.TxCount, uint64(count))
+		blocks.Difficulty = append(blocks.Difficulty, difficulty)
+		// CalculateHashRate is in MH/s.
+		blocks.Hashrate = append(blocks.Hashrate, dbtypes.CalculateHashRate(difficulty, charts.TimePerBlocks)*1e6)
+		blocks.Fees = append(blocks.Fees, uint64(fees))
+		blocks.Reward = append(blocks.Reward, float64(subsidy)/satoshisPerCoin)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("appendMutilchainChartBlocks: iteration error: %w", err)
+	}
+	return nil
+}
+
+// chartMutilchainNewAddresses fetches the number of addresses funded for the
+// first time in each block above the height of the new address data read so
+// far. This is the Fetcher half of a pair that make up a
+// cache.ChartMutilchainUpdater. The Appender half is
+// appendMutilchainNewAddresses.
+func (pgb *ChainDB) chartMutilchainNewAddresses(charts *cache.MutilchainChartData) (*sql.Rows, func(), error) {
+	tip := int64(charts.NewAddressesTip())
+	pgb.newAddressesTips.Lock()
+	if synced, ok := pgb.newAddressesTips.m[charts.ChainType]; !ok {
+		tip = -1
+	} else if synced < tip {
+		tip = synced
+	}
+	pgb.newAddressesTips.Unlock()
+
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	rows, err := pgb.db.QueryContext(ctx, mutilchainquery.MakeSelectNewAddressesChartRows(charts.ChainType), tip)
+	if err != nil {
+		return nil, cancel, fmt.Errorf("%s: chartNewAddresses: %w", charts.ChainType, pgb.replaceCancelError(err))
+	}
+	return rows, cancel, nil
+}
+
+// appendMutilchainNewAddresses sets the results of
+// chartMutilchainNewAddresses in the Blocks zoomSet, with zeros for the blocks
+// that are not synced yet, up to the Blocks height. The zeros are replaced
+// when the blocks are synced.
+func (pgb *ChainDB) appendMutilchainNewAddresses(charts *cache.MutilchainChartData, rows *sql.Rows) error {
+	defer closeRows(rows)
+	blocks := charts.Blocks
+	tip := uint64(len(blocks.Height))
+	synced := int64(-1)
+	for rows.Next() {
+		var height, count uint64
+		if err := rows.Scan(&height, &count); err != nil {
+			return err
+		}
+		if height >= tip {
+			break
+		}
+		if height < uint64(len(blocks.NewAddresses)) {
+			blocks.NewAddresses[height] = count
+		} else {
+			for uint64(len(blocks.NewAddresses)) < height {
+				blocks.NewAddresses = append(blocks.NewAddresses, 0)
+			}
+			blocks.NewAddresses = append(blocks.NewAddresses, count)
+		}
+		synced = int64(height)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("appendMutilchainNewAddresses: iteration error: %w", err)
+	}
+	for uint64(len(blocks.NewAddresses)) < tip {
+		blocks.NewAddresses = append(blocks.NewAddresses, 0)
+	}
+	if synced >= 0 {
+		pgb.newAddressesTips.Lock()
+		if pgb.newAddressesTips.m == nil {
+			pgb.newAddressesTips.m = make(map[string]int64)
+		}
+		pgb.newAddressesTips.m[charts.ChainType] = synced
+		pgb.newAddressesTips.Unlock()
+	}
+	return nil
+}
+
+// chartMutilchainCoinJoins fetches the value mixed and the participants of the
+// CoinJoins in each block above the TotalMixed tip. This is the Fetcher half of
+// a pair that make up a cache.ChartMutilchainUpdater. The Appender half is
+// appendMutilchainCoinJoins.
+func (pgb *ChainDB) chartMutilchainCoinJoins(charts *cache.MutilchainChartData) (*sql.Rows, func(), error) {
+	ctx, cancel := context.WithCancel(pgb.ctx)
+	rows, err := pgb.db.QueryContext(ctx, mutilchainquery.MakeSelectCoinJoinsPerBlock(charts.ChainType),
+		charts.TotalMixedTip())
+	if err != nil {
+		return nil, cancel, fmt.Errorf("%s: chartCoinJoins: %w", charts.ChainType, pgb.replaceCancelError(err))
+	}
+	return rows, cancel, nil
+}
+
+// appendMutilchainCoinJoins appends the results of chartMutilchainCoinJoins to
+// the TotalMixed and AnonymitySet data of the Blocks zoomSet, with zeros for
+// blocks without a CoinJoin.
+func appendMutilchainCoinJoins(charts *cache.MutilchainChartData, rows *sql.Rows) error {
+	defer closeRows(rows)
+	blocks := charts.Blocks
+	tip := uint64(len(blocks.Height))
+	for rows.Next() {
+		var height, mixed, participants uint64
+		if err := rows.Scan(&height, &mixed, &participants); err != nil {
+			return err
+		}
+		if height >= tip {
+			break
+		}
+		if height < uint64(len(blocks.TotalMixed)) {
+			continue
+		}
+		for uint64(len(blocks.TotalMixed)) < height {
+			blocks.TotalMixed = append(blocks.TotalMixed, 0)
+			blocks.AnonymitySet = append(blocks.AnonymitySet, 0)
+		}
+		blocks.TotalMixed = append(blocks.TotalMixed, mixed)
+		blocks.AnonymitySet = append(blocks.AnonymitySet, participants)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("appendMutilchainCoinJoins: iteration error: %w", err)
+	}
+	for uint64(len(blocks.TotalMixed)) < tip {
+		blocks.TotalMixed = append(blocks.TotalMixed, 0)
+		blocks.AnonymitySet = append(blocks.AnonymitySet, 0)
+	}
+	return nil
+}
+
+// chartMutilchainMempool fetches the mempool snapshots taken after the last
+// one in the charts data. This is the Fetcher half of a pair that make up a
+// cache.ChartMutilchainUpdater. The Appender half is appendMutilchainMempool.
+func (pgb *ChainDB) chartMutilchainMempool(charts *cache.MutilchainChartData) (*sql.Rows, func(), error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	rows, err := pgb.db.QueryContext(ctx, mutilchainquery.MakeSelectMempoolHistoryAfter(charts.ChainType),
+		charts.MempoolTip())
+	if err != nil {
+		return nil, cancel, fmt.Errorf("%s: chartMempool: %w", charts.ChainType, pgb.replaceCancelError(err))
+	}
+	return rows, cancel, nil
+}
+
+// appendMutilchainMempool appends the results of chartMutilchainMempool to the
+// Mempool zoomSet. The height of a snapshot is the best block height in the
+// charts data at the time of the snapshot.
+func appendMutilchainMempool(charts *cache.MutilchainChartData, rows *sql.Rows) error {
+	defer closeRows(rows)
+	mempool := charts.Mempool
+	times := charts.Blocks.Time
+	for rows.Next() {
+		var snapTime, txCount, size uint64
+		if err := rows.Scan(&snapTime, &txCount, &size); err != nil {
+			return err
+		}
+		height := sort.Search(len(times), func(i int) bool { return times[i] > snapTime }) - 1
+		mempool.Time = append(mempool.Time, snapTime)
+		mempool.Height = append(mempool.Height, uint64(max(height, 0)))
+		mempool.MempoolTxCount = append(mempool.MempoolTxCount, txCount)
+		mempool.MempoolSize = append(mempool.MempoolSize, size)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("appendMutilchainMempool: iteration error: %w", err)
+	}
+	return nil
+}
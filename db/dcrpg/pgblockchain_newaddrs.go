@@ -0,0 +1,146 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrdata/v8/mutilchain"
+
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal/mutilchainquery"
+)
+
+// newAddressesConfirmations is the number of blocks that the new address data
+// is kept behind the best stored block, since the first-seen heights of the
+// addresses of a block are not undone when the block is reorganized out of the
+// chain.
+const newAddressesConfirmations = 6
+
+// newAddressesSyncMtx is the mutex of the new address sync of a BTC or LTC
+// chain.
+func (pgb *ChainDB) newAddressesSyncMtx(chainType string) *sync.Mutex {
+	switch chainType {
+	case mutilchain.TYPEBTC:
+		return &pgb.btcNewAddressesSync
+	case mutilchain.TYPELTC:
+		return &pgb.ltcNewAddressesSync
+	}
+	return nil
+}
+
+// storeMutilchainNewAddresses records the addresses first funded in the block
+// at height of a BTC or LTC chain. It returns false if the block or some of
+// its transactions are not stored yet.
+func (pgb *ChainDB) storeMutilchainNewAddresses(chainType string, height int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+
+	var hash string
+	var blockTime, numTx, storedTx int64
+	err := pgb.db.QueryRowContext(ctx, mutilchainquery.MakeSelectCoinAgeBlock(chainType), height).
+		Scan(&hash, &blockTime, &numTx, &storedTx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if numTx == 0 || storedTx < numTx {
+		return false, nil
+	}
+
+	dbtx, err := pgb.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("unable to begin database transaction: %w", err)
+	}
+	res, err := dbtx.ExecContext(ctx, mutilchainquery.MakeInsertAddressFirstSeen(chainType), height, hash)
+	if err != nil {
+		_ = dbtx.Rollback()
+		return false, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		_ = dbtx.Rollback()
+		return false, err
+	}
+	_, err = dbtx.ExecContext(ctx, mutilchainquery.MakeInsertNewAddresses(chainType), height, count)
+	if err != nil {
+		_ = dbtx.Rollback()
+		return false, err
+	}
+	return true, dbtx.Commit()
+}
+
+// nextMutilchainBlockHeight returns the lowest height from height of a stored
+// block of a BTC or LTC chain, or -1 if there is none.
+func (pgb *ChainDB) nextMutilchainBlockHeight(chainType string, height int64) (int64, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	var next int64
+	err := pgb.db.QueryRowContext(ctx, mutilchainquery.MakeSelectNextBlockHeight(chainType), height).Scan(&next)
+	return next, pgb.replaceCancelError(err)
+}
+
+// SyncMutilchainNewAddresses brings the new address tables of a BTC or LTC
+// chain up to the best stored block, less newAddressesConfirmations. The
+// blocks are processed in height order from the lowest stored block, and the
+// blocks that are missing or whose transactions are not all stored are
+// skipped, so the addresses first funded in a skipped block are counted as new
+// in the next block funding them. It returns at once when the chain is already
+// being synced, so it may be called for every new block.
+func (pgb *ChainDB) SyncMutilchainNewAddresses(chainType string) {
+	if pgb.ChainDBDisabled {
+		return
+	}
+	mtx := pgb.newAddressesSyncMtx(chainType)
+	if mtx == nil || !mtx.TryLock() {
+		return
+	}
+	defer mtx.Unlock()
+
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	var height, bestHeight int64
+	err := pgb.db.QueryRowContext(ctx, mutilchainquery.MakeSelectNewAddressesMaxHeight(chainType)).Scan(&height)
+	if err == nil {
+		bestHeight, _, err = RetrieveMutilchainBestBlock(ctx, pgb.db, chainType)
+	}
+	cancel()
+	if err != nil {
+		log.Errorf("%s: failed to get the new address sync heights: %v", chainType, pgb.replaceCancelError(err))
+		return
+	}
+
+	start := time.Now()
+	for height++; height <= bestHeight-newAddressesConfirmations && pgb.ctx.Err() == nil; height++ {
+		stored, err := pgb.storeMutilchainNewAddresses(chainType, height)
+		if err != nil {
+			log.Errorf("%s: failed to store new addresses of block %d: %v", chainType, height,
+				pgb.replaceCancelError(err))
+			return
+		}
+		if !stored {
+			next, err := pgb.nextMutilchainBlockHeight(chainType, height+1)
+			if err != nil {
+				log.Errorf("%s: failed to get the next stored block after %d: %v", chainType, height, err)
+				return
+			}
+			if next < 0 || next > bestHeight-newAddressesConfirmations {
+				break
+			}
+			log.Debugf("%s: new address sync skipped blocks %d to %d, which are not stored", chainType,
+				height, next-1)
+			height = next - 1
+			continue
+		}
+		if height%10000 == 0 {
+			log.Infof("%s: new addresses synced to block %d", chainType, height)
+		}
+	}
+	log.Debugf("%s: new addresses synced to block %d in %v", chainType, height-1, time.Since(start))
+}
@@ -0,0 +1,346 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	btc_chainhash "github.com/btcsuite/btcd/chaincfg/chainhash"
+	btctxscript "github.com/btcsuite/btcd/txscript"
+	btcwire "github.com/btcsuite/btcd/wire"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/mutilchain"
+	"github.com/decred/dcrdata/v8/mutilchain/externalapi"
+	"github.com/decred/dcrdata/v8/mutilchain/pools"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/ltcutil"
+	ltctxscript "github.com/ltcsuite/ltcd/txscript"
+	ltcwire "github.com/ltcsuite/ltcd/wire"
+
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal/mutilchainquery"
+)
+
+const (
+	// blockPoolsBatchSize is the number of blocks attributed per batch when
+	// syncing the block pools table.
+	blockPoolsBatchSize = 500
+
+	// lastBlockPoolsCount is the number of blocks listed by
+	// GetLastMultichainPoolDataList.
+	lastBlockPoolsCount = 10
+)
+
+// btcBlockPool attributes a BTC block to a mining pool.
+func (pgb *ChainDB) btcBlockPool(msgBlock *btcwire.MsgBlock, height int64) *dbtypes.BlockPool {
+	bp := &dbtypes.BlockPool{
+		Height:      height,
+		Hash:        msgBlock.BlockHash().String(),
+		Time:        msgBlock.Header.Timestamp.Unix(),
+		NumTx:       len(msgBlock.Transactions),
+		DefsVersion: pools.Default().Version,
+	}
+	if len(msgBlock.Transactions) > 0 && len(msgBlock.Transactions[0].TxIn) > 0 {
+		coinbase := msgBlock.Transactions[0]
+		bp.CoinbaseScript = coinbase.TxIn[0].SignatureScript
+		for _, txOut := range coinbase.TxOut {
+			bp.Reward += txOut.Value
+			_, addrs, _, err := btctxscript.ExtractPkScriptAddrs(txOut.PkScript, pgb.btcChainParams)
+			if err != nil {
+				continue
+			}
+			for _, addr := range addrs {
+				bp.PayoutAddresses = append(bp.PayoutAddresses, addr.String())
+			}
+		}
+	}
+	pool := pools.Default().Identify(mutilchain.TYPEBTC, bp.CoinbaseScript, bp.PayoutAddresses)
+	bp.PoolSlug, bp.PoolName = pool.Slug, pool.Name
+	return bp
+}
+
+// ltcBlockPool attributes a LTC block to a mining pool.
+func (pgb *ChainDB) ltcBlockPool(msgBlock *ltcwire.MsgBlock, height int64) *dbtypes.BlockPool {
+	bp := &dbtypes.BlockPool{
+		Height:      height,
+		Hash:        msgBlock.BlockHash().String(),
+		Time:        msgBlock.Header.Timestamp.Unix(),
+		NumTx:       len(msgBlock.Transactions),
+		DefsVersion: pools.Default().Version,
+	}
+	if len(msgBlock.Transactions) > 0 && len(msgBlock.Transactions[0].TxIn) > 0 {
+		coinbase := msgBlock.Transactions[0]
+		bp.CoinbaseScript = coinbase.TxIn[0].SignatureScript
+		for _, txOut := range coinbase.TxOut {
+			bp.Reward += txOut.Value
+			_, addrs, _, err := ltctxscript.ExtractPkScriptAddrs(txOut.PkScript, pgb.ltcChainParams)
+			if err != nil {
+				continue
+			}
+			for _, addr := range addrs {
+				bp.PayoutAddresses = append(bp.PayoutAddresses, addr.String())
+			}
+		}
+	}
+	pool := pools.Default().Identify(mutilchain.TYPELTC, bp.CoinbaseScript, bp.PayoutAddresses)
+	bp.PoolSlug, bp.PoolName = pool.Slug, pool.Name
+	return bp
+}
+
+// StoreBTCBlockPool stores the mining pool attribution of a BTC block.
+func (pgb *ChainDB) StoreBTCBlockPool(msgBlock *btcwire.MsgBlock, height int64) error {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	err := UpsertMutilchainBlockPool(ctx, pgb.db, pgb.btcBlockPool(msgBlock, height), mutilchain.TYPEBTC)
+	return pgb.replaceCancelError(err)
+}
+
+// StoreLTCBlockPool stores the mining pool attribution of a LTC block.
+func (pgb *ChainDB) StoreLTCBlockPool(msgBlock *ltcwire.MsgBlock, height int64) error {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	err := UpsertMutilchainBlockPool(ctx, pgb.db, pgb.ltcBlockPool(msgBlock, height), mutilchain.TYPELTC)
+	return pgb.replaceCancelError(err)
+}
+
+// storeMutilchainBlockPoolByHash fetches a block from the node and stores its
+// mining pool attribution.
+func (pgb *ChainDB) storeMutilchainBlockPoolByHash(hash string, height int64, chainType string) error {
+	switch chainType {
+	case mutilchain.TYPEBTC:
+		if pgb.BtcClient == nil {
+			return fmt.Errorf("no BTC node client")
+		}
+		blockHash, err := btc_chainhash.NewHashFromStr(hash)
+		if err != nil {
+			return err
+		}
+		msgBlock, err := pgb.BtcClient.GetBlock(blockHash)
+		if err != nil {
+			return fmt.Errorf("GetBlock(%s): %w", hash, err)
+		}
+		return pgb.StoreBTCBlockPool(msgBlock, height)
+	case mutilchain.TYPELTC:
+		if pgb.LtcClient == nil {
+			return fmt.Errorf("no LTC node client")
+		}
+		blockHash, err := chainhash.NewHashFromStr(hash)
+		if err != nil {
+			return err
+		}
+		msgBlock, err := pgb.LtcClient.GetBlock(blockHash)
+		if err != nil {
+			return fmt.Errorf("GetBlock(%s): %w", hash, err)
+		}
+		return pgb.StoreLTCBlockPool(msgBlock, height)
+	default:
+		return fmt.Errorf("mining pools are not supported for chain %s", chainType)
+	}
+}
+
+// SyncMutilchainBlockPools attributes the stored blocks of a BTC or LTC chain
+// that have no pool attribution, fetching them from the node, and attributes
+// again the blocks attributed with older pool definitions. The latter needs no
+// node since the coinbase script and payout addresses are stored.
+func (pgb *ChainDB) SyncMutilchainBlockPools(chainType string) {
+	if pgb.ChainDBDisabled {
+		return
+	}
+	if _, err := pgb.db.ExecContext(pgb.ctx, mutilchainquery.MakeIndexBlockPoolsTableOnTime(chainType)); err != nil {
+		log.Errorf("%s: failed to index block pools table: %v", chainType, err)
+		return
+	}
+
+	start := time.Now()
+	var attributed int
+	for pgb.ctx.Err() == nil {
+		ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+		heights, hashes, err := RetrieveMutilchainBlocksWithoutPool(ctx, pgb.db, blockPoolsBatchSize, chainType)
+		cancel()
+		if err != nil {
+			log.Errorf("%s: RetrieveMutilchainBlocksWithoutPool: %v", chainType, pgb.replaceCancelError(err))
+			return
+		}
+		if len(heights) == 0 {
+			break
+		}
+		for i, height := range heights {
+			if pgb.ctx.Err() != nil {
+				return
+			}
+			// Stop on the first failure, such as a pruned block, so that
+			// the same blocks are not requested forever.
+			if err = pgb.storeMutilchainBlockPoolByHash(hashes[i], height, chainType); err != nil {
+				log.Warnf("%s: stopped attributing blocks to pools at height %d: %v",
+					chainType, height, err)
+				return
+			}
+			attributed++
+		}
+		log.Debugf("%s: attributed %d blocks to mining pools", chainType, attributed)
+	}
+
+	defs := pools.Default()
+	var updated int
+	for pgb.ctx.Err() == nil {
+		ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+		bps, err := RetrieveMutilchainBlockPoolsByDefsVersion(ctx, pgb.db, defs.Version,
+			blockPoolsBatchSize, chainType)
+		cancel()
+		if err != nil {
+			log.Errorf("%s: RetrieveMutilchainBlockPoolsByDefsVersion: %v", chainType, pgb.replaceCancelError(err))
+			return
+		}
+		if len(bps) == 0 {
+			break
+		}
+		for _, bp := range bps {
+			pool := defs.Identify(chainType, bp.CoinbaseScript, bp.PayoutAddresses)
+			ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+			err = UpdateMutilchainBlockPoolAttribution(ctx, pgb.db, bp.Height, pool.Slug,
+				pool.Name, defs.Version, chainType)
+			cancel()
+			if err != nil {
+				log.Errorf("%s: UpdateMutilchainBlockPoolAttribution: %v", chainType, pgb.replaceCancelError(err))
+				return
+			}
+			updated++
+		}
+	}
+
+	if attributed > 0 || updated > 0 {
+		log.Infof("%s: attributed %d blocks and updated %d blocks to mining pools (definitions v%d) in %v",
+			chainType, attributed, updated, defs.Version, time.Since(start))
+	}
+}
+
+// GetLastMultichainPoolDataList return last 10 block pools info
+func (pgb *ChainDB) GetLastMultichainPoolDataList(chainType string, startHeight int64) ([]*dbtypes.MultichainPoolDataItem, error) {
+	switch chainType {
+	case mutilchain.TYPEBTC, mutilchain.TYPELTC:
+		items, err := pgb.lastMutilchainBlockPools(chainType, startHeight)
+		if err == nil && len(items) > 0 {
+			return items, nil
+		}
+		if err != nil {
+			log.Warnf("%s: local pool data unavailable, using external API: %v", chainType, err)
+		}
+		if chainType == mutilchain.TYPEBTC {
+			return externalapi.GetBitcoinLastBlocksPool(startHeight)
+		}
+		return externalapi.GetLitecoinLastBlocksPool(startHeight)
+	case mutilchain.TYPEXMR:
+		return externalapi.GetXMRLastBlocksPool()
+	default:
+		return make([]*dbtypes.MultichainPoolDataItem, 0), nil
+	}
+}
+
+// lastMutilchainBlockPools returns the pools of the last blocks at or below
+// startHeight from the block pools table, with their blocks in the last 24
+// hours.
+func (pgb *ChainDB) lastMutilchainBlockPools(chainType string, startHeight int64) ([]*dbtypes.MultichainPoolDataItem, error) {
+	if pgb.ChainDBDisabled {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	bps, err := RetrieveMutilchainLastBlockPools(ctx, pgb.db, startHeight, lastBlockPoolsCount, chainType)
+	if err != nil || len(bps) == 0 {
+		return nil, pgb.replaceCancelError(err)
+	}
+	counts, err := RetrieveMutilchainPoolBlockCountsSince(ctx, pgb.db,
+		time.Now().Add(-24*time.Hour).Unix(), chainType)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+
+	defs := pools.Default()
+	items := make([]*dbtypes.MultichainPoolDataItem, 0, len(bps))
+	for _, bp := range bps {
+		item := &dbtypes.MultichainPoolDataItem{
+			BlockHeight: bp.Height,
+			PoolName:    bp.PoolName,
+			PoolSlug:    bp.PoolSlug,
+			Link:        defs.Pool(chainType, bp.PoolSlug).Link,
+		}
+		if chainType == mutilchain.TYPEBTC {
+			item.Reward = btcutil.Amount(bp.Reward).ToBTC()
+		} else {
+			item.Reward = ltcutil.Amount(bp.Reward).ToBTC()
+		}
+		if c := counts[bp.PoolSlug]; c != nil && c.Blocks > 0 {
+			item.Pool24hBlocks = int(c.Blocks)
+			item.Health = float64(c.Blocks-c.EmptyBlocks) / float64(c.Blocks)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// MutilchainPoolShareChart returns the share of the blocks mined by each pool
+// in bins of a day or a week. Pools are sorted by their total blocks.
+func (pgb *ChainDB) MutilchainPoolShareChart(chainType, bin string) (*dbtypes.PoolShareChart, error) {
+	if chainType != mutilchain.TYPEBTC && chainType != mutilchain.TYPELTC {
+		return nil, fmt.Errorf("mining pools are not supported for chain %s", chainType)
+	}
+	binSize, ok := dbtypes.PoolShareBins[bin]
+	if !ok {
+		return nil, fmt.Errorf("invalid bin %q", bin)
+	}
+	if pgb.ChainDBDisabled {
+		return nil, fmt.Errorf("%s DB is disabled", chainType)
+	}
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	bins, counts, err := RetrieveMutilchainPoolBlockCountsByBin(ctx, pgb.db, binSize, chainType)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+
+	defs := pools.Default()
+	chart := &dbtypes.PoolShareChart{
+		ChainType: chainType,
+		Bin:       bin,
+		Time:      bins,
+		Pools:     []*dbtypes.PoolShareSeries{},
+	}
+	series := make(map[string]*dbtypes.PoolShareSeries)
+	totals := make(map[string]int64)
+	for i, binCounts := range counts {
+		var binTotal int64
+		for _, n := range binCounts {
+			binTotal += n
+		}
+		for slug, n := range binCounts {
+			s := series[slug]
+			if s == nil {
+				pool := defs.Pool(chainType, slug)
+				s = &dbtypes.PoolShareSeries{
+					Slug:   slug,
+					Name:   pool.Name,
+					Link:   pool.Link,
+					Blocks: make([]int64, len(bins)),
+					Share:  make([]float64, len(bins)),
+				}
+				series[slug] = s
+				chart.Pools = append(chart.Pools, s)
+			}
+			s.Blocks[i] = n
+			s.Share[i] = float64(n) / float64(binTotal)
+			totals[slug] += n
+		}
+	}
+	sort.Slice(chart.Pools, func(i, j int) bool {
+		ti, tj := totals[chart.Pools[i].Slug], totals[chart.Pools[j].Slug]
+		if ti != tj {
+			return ti > tj
+		}
+		return chart.Pools[i].Slug < chart.Pools[j].Slug
+	})
+	return chart, nil
+}
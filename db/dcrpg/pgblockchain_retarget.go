@@ -0,0 +1,114 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/decred/dcrdata/v8/mutilchain"
+	"github.com/decred/dcrdata/v8/mutilchain/btcrpcutils"
+	"github.com/decred/dcrdata/v8/mutilchain/ltcrpcutils"
+	ltcjson "github.com/ltcsuite/ltcd/btcjson"
+)
+
+// retargetParams returns the retarget parameters of a BTC or LTC network.
+func (pgb *ChainDB) retargetParams(chainType string) (mutilchain.RetargetParams, error) {
+	switch chainType {
+	case mutilchain.TYPEBTC:
+		return mutilchain.RetargetParams{
+			TargetTimespan:           pgb.btcChainParams.TargetTimespan,
+			TargetTimePerBlock:       pgb.btcChainParams.TargetTimePerBlock,
+			RetargetAdjustmentFactor: pgb.btcChainParams.RetargetAdjustmentFactor,
+		}, nil
+	case mutilchain.TYPELTC:
+		return mutilchain.RetargetParams{
+			TargetTimespan:           pgb.ltcChainParams.TargetTimespan,
+			TargetTimePerBlock:       pgb.ltcChainParams.TargetTimePerBlock,
+			RetargetAdjustmentFactor: pgb.ltcChainParams.RetargetAdjustmentFactor,
+		}, nil
+	}
+	return mutilchain.RetargetParams{}, fmt.Errorf("no difficulty retarget for chain %q", chainType)
+}
+
+// mutilchainHeaderAt returns the time and difficulty of the BTC or LTC block
+// at height from the node.
+func (pgb *ChainDB) mutilchainHeaderAt(chainType string, height int64) (int64, float64, error) {
+	switch chainType {
+	case mutilchain.TYPEBTC:
+		hdr, err := btcrpcutils.WithTimeout(func() (*btcjson.GetBlockHeaderVerboseResult, error) {
+			hash, err := pgb.BtcClient.GetBlockHash(height)
+			if err != nil {
+				return nil, err
+			}
+			return pgb.BtcClient.GetBlockHeaderVerbose(hash)
+		})
+		if err != nil {
+			return 0, 0, err
+		}
+		return hdr.Time, hdr.Difficulty, nil
+	case mutilchain.TYPELTC:
+		hdr, err := ltcrpcutils.WithTimeout(func() (*ltcjson.GetBlockHeaderVerboseResult, error) {
+			hash, err := pgb.LtcClient.GetBlockHash(height)
+			if err != nil {
+				return nil, err
+			}
+			return pgb.LtcClient.GetBlockHeaderVerbose(hash)
+		})
+		if err != nil {
+			return 0, 0, err
+		}
+		return hdr.Time, hdr.Difficulty, nil
+	}
+	return 0, 0, fmt.Errorf("unsupported chain type %q", chainType)
+}
+
+// mutilchainRetargetEpoch returns the first block data of the retarget epoch
+// starting at startHeight. The last epoch is cached, so the node is only
+// queried once per epoch.
+func (pgb *ChainDB) mutilchainRetargetEpoch(chainType string, startHeight int64) (*mutilchain.RetargetEpoch, error) {
+	pgb.retargetEpochs.Lock()
+	defer pgb.retargetEpochs.Unlock()
+	if epoch := pgb.retargetEpochs.m[chainType]; epoch != nil && epoch.StartHeight == startHeight {
+		return epoch, nil
+	}
+
+	startTime, startDiff, err := pgb.mutilchainHeaderAt(chainType, startHeight)
+	if err != nil {
+		return nil, fmt.Errorf("retarget epoch start %d: %w", startHeight, err)
+	}
+	epoch := &mutilchain.RetargetEpoch{
+		StartHeight:     startHeight,
+		StartTime:       startTime,
+		StartDifficulty: startDiff,
+	}
+	if startHeight > 0 {
+		_, epoch.PrevDifficulty, err = pgb.mutilchainHeaderAt(chainType, startHeight-1)
+		if err != nil {
+			return nil, fmt.Errorf("retarget epoch end %d: %w", startHeight-1, err)
+		}
+	}
+
+	if pgb.retargetEpochs.m == nil {
+		pgb.retargetEpochs.m = make(map[string]*mutilchain.RetargetEpoch)
+	}
+	pgb.retargetEpochs.m[chainType] = epoch
+	return epoch, nil
+}
+
+// MutilchainDifficultyAdjustment computes the progress of the current BTC or
+// LTC difficulty retarget epoch and estimates the next difficulty from the
+// best block at height, with the given block time and difficulty.
+func (pgb *ChainDB) MutilchainDifficultyAdjustment(chainType string, height, blockTime int64,
+	difficulty float64) (*mutilchain.DifficultyAdjustment, error) {
+	params, err := pgb.retargetParams(chainType)
+	if err != nil {
+		return nil, err
+	}
+	epoch, err := pgb.mutilchainRetargetEpoch(chainType, params.EpochStart(height))
+	if err != nil {
+		return nil, err
+	}
+	return mutilchain.CalcDifficultyAdjustment(params, *epoch, height, blockTime, difficulty), nil
+}
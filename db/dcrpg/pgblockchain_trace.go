@@ -0,0 +1,149 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/mutilchain"
+	"github.com/lib/pq"
+
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal"
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal/mutilchainquery"
+)
+
+// swapsTables are the atomic swap tables of the chains.
+var swapsTables = map[string]string{
+	mutilchain.TYPEDCR: "swaps",
+	mutilchain.TYPEBTC: "btc_swaps",
+	mutilchain.TYPELTC: "ltc_swaps",
+}
+
+// TraceTx retrieves a main chain transaction of a chain with its inputs,
+// outputs and spends, and the address clusters of their addresses, for the
+// transaction flow tracing. It returns nil for an unknown transaction.
+func (pgb *ChainDB) TraceTx(chain, txHash string) (*dbtypes.TraceTx, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+
+	tx := &dbtypes.TraceTx{TxHash: txHash}
+	var inputsQuery, outputsQuery string
+	var err error
+	if chain == mutilchain.TYPEDCR {
+		var blockTime dbtypes.TimeDef
+		var mixCount int64
+		err = pgb.db.QueryRowContext(ctx, internal.SelectTraceTx, txHash).
+			Scan(&tx.BlockHeight, &blockTime, &mixCount)
+		tx.BlockTime = blockTime.UNIX()
+		tx.Mix = mixCount > 0
+		inputsQuery, outputsQuery = internal.SelectTraceTxInputs, internal.SelectTraceTxOutputs
+	} else {
+		err = pgb.db.QueryRowContext(ctx, mutilchainquery.MakeSelectTraceTx(chain), txHash).Scan(&tx.BlockHeight, &tx.BlockTime)
+		inputsQuery = mutilchainquery.MakeSelectTraceTxInputs(chain)
+		outputsQuery = mutilchainquery.MakeSelectTraceTxOutputs(chain)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+
+	rows, err := pgb.db.QueryContext(ctx, inputsQuery, txHash)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	for rows.Next() {
+		var in dbtypes.TraceInput
+		if err = rows.Scan(&in.PrevTxHash, &in.PrevVout, &in.Value, &in.Address); err != nil {
+			closeRows(rows)
+			return nil, err
+		}
+		// Skip the coinbase and stakebase inputs.
+		if bytes.Equal(zeroHashStringBytes, []byte(in.PrevTxHash)) {
+			continue
+		}
+		tx.Inputs = append(tx.Inputs, &in)
+	}
+	closeRows(rows)
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = pgb.db.QueryContext(ctx, outputsQuery, txHash)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	for rows.Next() {
+		var out dbtypes.TraceOutput
+		if err = rows.Scan(&out.Vout, &out.Address, &out.Value, &out.SpendTxHash); err != nil {
+			closeRows(rows)
+			return nil, err
+		}
+		tx.Outputs = append(tx.Outputs, &out)
+	}
+	closeRows(rows)
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if table, ok := swapsTables[chain]; ok {
+		err = pgb.db.QueryRowContext(ctx, internal.MakeSelectIsSwapTx(table), txHash).Scan(&tx.Swap)
+		if err != nil {
+			return nil, pgb.replaceCancelError(err)
+		}
+	}
+
+	if err = pgb.traceClusters(ctx, chain, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// traceClusters sets the address clusters of the inputs and outputs of a
+// TraceTx.
+func (pgb *ChainDB) traceClusters(ctx context.Context, chain string, tx *dbtypes.TraceTx) error {
+	var addrs []string
+	for _, in := range tx.Inputs {
+		if in.Address != "" {
+			addrs = append(addrs, in.Address)
+		}
+	}
+	for _, out := range tx.Outputs {
+		if out.Address != "" {
+			addrs = append(addrs, out.Address)
+		}
+	}
+	if len(addrs) == 0 {
+		return nil
+	}
+	rows, err := pgb.db.QueryContext(ctx, internal.SelectAddressesClusters, chain, pq.Array(addrs))
+	if err != nil {
+		return pgb.replaceCancelError(err)
+	}
+	defer closeRows(rows)
+	clusters := make(map[string]int64)
+	for rows.Next() {
+		var addr string
+		var id int64
+		if err = rows.Scan(&addr, &id); err != nil {
+			return err
+		}
+		clusters[addr] = id
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	for _, in := range tx.Inputs {
+		in.ClusterID = clusters[in.Address]
+	}
+	for _, out := range tx.Outputs {
+		out.ClusterID = clusters[out.Address]
+	}
+	return nil
+}
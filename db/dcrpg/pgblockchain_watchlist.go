@@ -0,0 +1,172 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal"
+)
+
+// CreateWatch stores a new watch-list entry and returns its ID.
+func (pgb *ChainDB) CreateWatch(w *dbtypes.Watch) (int64, error) {
+	var id int64
+	err := pgb.db.QueryRow(internal.InsertWatch, w.Chain, w.Address, w.ViewKey,
+		w.CallbackURL, w.Client, w.Secret, w.Confirmations, w.Created).Scan(&id)
+	return id, err
+}
+
+func scanWatch(row interface{ Scan(...any) error }) (*dbtypes.Watch, error) {
+	var w dbtypes.Watch
+	err := row.Scan(&w.ID, &w.Chain, &w.Address, &w.ViewKey, &w.CallbackURL,
+		&w.Client, &w.Secret, &w.Confirmations, &w.Created)
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// Watch retrieves a watch-list entry. The error is sql.ErrNoRows for an
+// unknown ID.
+func (pgb *ChainDB) Watch(id int64) (*dbtypes.Watch, error) {
+	return scanWatch(pgb.db.QueryRow(internal.SelectWatch, id))
+}
+
+// Watches retrieves all watch-list entries.
+func (pgb *ChainDB) Watches() ([]*dbtypes.Watch, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	rows, err := pgb.db.QueryContext(ctx, internal.SelectWatches)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	defer closeRows(rows)
+	watches := make([]*dbtypes.Watch, 0)
+	for rows.Next() {
+		w, err := scanWatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		watches = append(watches, w)
+	}
+	return watches, rows.Err()
+}
+
+// DeleteWatch removes a watch-list entry with its delivery log.
+func (pgb *ChainDB) DeleteWatch(id int64) error {
+	_, err := pgb.db.Exec(internal.DeleteWatch, id)
+	return err
+}
+
+// InsertWatchDelivery queues the delivery of a watch event. It returns false
+// if the event of the transaction was already queued. The transaction of a
+// "confirmed" event waits for the confirmations of its watch in its block,
+// even if it was already queued in a block since reorganized out of the chain.
+func (pgb *ChainDB) InsertWatchDelivery(d *dbtypes.WatchDelivery) (bool, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	dbtx, err := pgb.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, pgb.replaceCancelError(err)
+	}
+	res, err := dbtx.ExecContext(ctx, internal.InsertWatchDelivery, d.WatchID, d.Event, d.TxID,
+		d.Height, d.BlockHash, d.Amount, d.Confirmations, d.Created)
+	if err != nil {
+		_ = dbtx.Rollback()
+		return false, pgb.replaceCancelError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		_ = dbtx.Rollback()
+		return false, err
+	}
+	if d.Event == "confirmed" {
+		_, err = dbtx.ExecContext(ctx, internal.UpsertWatchConfirmation, d.WatchID, d.TxID,
+			d.Height, d.BlockHash, d.Amount)
+		if err != nil {
+			_ = dbtx.Rollback()
+			return false, pgb.replaceCancelError(err)
+		}
+	}
+	return n > 0, dbtx.Commit()
+}
+
+// InsertWatchConfirmations queues the deliveries of the confirmed transactions
+// of the watches on a chain that reached their confirmations at the tip block
+// with the hash tipHash. The transactions of the blocks that the tip
+// reorganized out of the chain no longer wait for their confirmations. It
+// returns the number of deliveries queued.
+func (pgb *ChainDB) InsertWatchConfirmations(chain string, tip int64, tipHash string, now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	dbtx, err := pgb.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, pgb.replaceCancelError(err)
+	}
+	_, err = dbtx.ExecContext(ctx, internal.DeleteOrphanedWatchConfirmations, chain, tip, tipHash)
+	if err != nil {
+		_ = dbtx.Rollback()
+		return 0, pgb.replaceCancelError(err)
+	}
+	res, err := dbtx.ExecContext(ctx, internal.InsertWatchConfirmations, chain, tip, now)
+	if err != nil {
+		_ = dbtx.Rollback()
+		return 0, pgb.replaceCancelError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		_ = dbtx.Rollback()
+		return 0, err
+	}
+	return n, dbtx.Commit()
+}
+
+func (pgb *ChainDB) queryWatchDeliveries(query string, args ...any) ([]*dbtypes.WatchDelivery, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	rows, err := pgb.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	defer closeRows(rows)
+	deliveries := make([]*dbtypes.WatchDelivery, 0)
+	for rows.Next() {
+		var d dbtypes.WatchDelivery
+		var delivered sql.NullTime
+		err = rows.Scan(&d.ID, &d.WatchID, &d.Event, &d.TxID, &d.Height, &d.BlockHash,
+			&d.Amount, &d.Confirmations, &d.Created, &d.Status, &d.Attempts,
+			&d.NextAttempt, &d.ResponseCode, &d.LastError, &delivered)
+		if err != nil {
+			return nil, err
+		}
+		if delivered.Valid {
+			d.Delivered = &delivered.Time
+		}
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, rows.Err()
+}
+
+// DueWatchDeliveries retrieves up to limit pending deliveries whose next
+// attempt is due at now, the most overdue first.
+func (pgb *ChainDB) DueWatchDeliveries(now time.Time, limit int) ([]*dbtypes.WatchDelivery, error) {
+	return pgb.queryWatchDeliveries(internal.SelectDueWatchDeliveries, now, limit)
+}
+
+// WatchDeliveries retrieves the delivery log of a watch, up to limit
+// deliveries, the latest first.
+func (pgb *ChainDB) WatchDeliveries(watchID int64, limit int) ([]*dbtypes.WatchDelivery, error) {
+	return pgb.queryWatchDeliveries(internal.SelectWatchDeliveries, watchID, limit)
+}
+
+// UpdateWatchDelivery stores the outcome of a delivery attempt.
+func (pgb *ChainDB) UpdateWatchDelivery(d *dbtypes.WatchDelivery) error {
+	_, err := pgb.db.Exec(internal.UpdateWatchDelivery, d.ID, d.Status, d.Attempts,
+		d.NextAttempt, d.ResponseCode, d.LastError, d.Delivered)
+	return err
+}
@@ -0,0 +1,437 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrdata/v8/db/cache"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/mutilchain"
+	"github.com/lib/pq"
+
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal"
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal/mutilchainquery"
+)
+
+// wealthConfirmations is the number of blocks that the address balances are
+// kept behind the best stored block, since the balance changes of a block are
+// not undone when the block is reorganized out of the chain.
+const wealthConfirmations = 6
+
+// wealthSnapshotAge is the age of the first block of a day past which no
+// wealth snapshot is taken of the previous day. A snapshot ranks all the
+// balances of a chain, which is affordable once a day from the sync tip but
+// not for every day of history while catching up.
+const wealthSnapshotAge = 48 * time.Hour
+
+// wealthProgress is the last block of a chain included in the address
+// balances, with the number of addresses and their total balance.
+type wealthProgress struct {
+	height    int64
+	time      int64
+	addresses int64
+	total     int64
+}
+
+// CheckAndCreateWealthTables creates the tables of the address balances and
+// the wealth distribution if they do not exist.
+func (pgb *ChainDB) CheckAndCreateWealthTables() error {
+	tables := [][2]string{
+		{"address_balances", internal.CreateAddressBalancesTable},
+		{"wealth_progress", internal.CreateWealthProgressTable},
+		{"wealth_snapshots", internal.CreateWealthSnapshotsTable},
+		{"wealth_buckets", internal.CreateWealthBucketsTable},
+	}
+	for _, table := range tables {
+		if err := createTable(pgb.db, table[0], table[1]); err != nil {
+			return fmt.Errorf("failed to create %s table: %w", table[0], err)
+		}
+	}
+	return nil
+}
+
+// wealthSyncMtx is the mutex of the address balances sync of a chain.
+func (pgb *ChainDB) wealthSyncMtx(chain string) *sync.Mutex {
+	switch chain {
+	case mutilchain.TYPEDCR:
+		return &pgb.dcrWealthSync
+	case mutilchain.TYPEBTC:
+		return &pgb.btcWealthSync
+	case mutilchain.TYPELTC:
+		return &pgb.ltcWealthSync
+	}
+	return nil
+}
+
+// wealthProgress retrieves the last block of a chain included in the address
+// balances, with height -1 before the first block.
+func (pgb *ChainDB) wealthProgress(chain string) (*wealthProgress, error) {
+	progress := &wealthProgress{height: -1}
+	err := pgb.db.QueryRowContext(pgb.ctx, internal.SelectWealthProgress, chain).
+		Scan(&progress.height, &progress.time, &progress.addresses, &progress.total)
+	if errors.Is(err, sql.ErrNoRows) {
+		return progress, nil
+	}
+	return progress, pgb.replaceCancelError(err)
+}
+
+// SyncWealth brings the address balances of a chain up to the best stored
+// block, less wealthConfirmations, taking a wealth snapshot after the last
+// block of each day within wealthSnapshotAge. The sync stops at the first block that is not stored. It
+// returns at once when the chain is already being synced, so it may be called
+// for every new block.
+func (pgb *ChainDB) SyncWealth(chain string) {
+	if pgb.ChainDBDisabled {
+		return
+	}
+	mtx := pgb.wealthSyncMtx(chain)
+	if mtx == nil || !mtx.TryLock() {
+		return
+	}
+	defer mtx.Unlock()
+
+	progress, err := pgb.wealthProgress(chain)
+	if err != nil {
+		log.Errorf("%s: failed to get the address balances progress: %v", chain, err)
+		return
+	}
+	var bestHeight int64
+	if chain == mutilchain.TYPEDCR {
+		bestHeight, err = pgb.HeightDB()
+	} else {
+		bestHeight, err = pgb.MutilchainHeightDB(chain)
+	}
+	if err != nil {
+		log.Errorf("%s: failed to get the best block height: %v", chain, err)
+		return
+	}
+
+	start := time.Now()
+	for height := progress.height + 1; height <= bestHeight-wealthConfirmations && pgb.ctx.Err() == nil; height++ {
+		stored, err := pgb.storeWealthBlock(chain, height, progress)
+		if err != nil {
+			log.Errorf("%s: failed to store the address balances of block %d: %v", chain, height,
+				pgb.replaceCancelError(err))
+			return
+		}
+		if !stored {
+			log.Debugf("%s: address balances sync stopped at block %d, which is not stored", chain, height)
+			break
+		}
+		if height%10000 == 0 {
+			log.Infof("%s: address balances synced to block %d", chain, height)
+		}
+	}
+	log.Debugf("%s: address balances synced to block %d in %v", chain, progress.height, time.Since(start))
+}
+
+// storeWealthBlock adds the balance changes of the main chain block at height
+// to the address balances, after a wealth snapshot of the balances when the
+// block starts a recent new day, and advances the progress. It returns false if the
+// block or some of its transactions are not stored yet.
+func (pgb *ChainDB) storeWealthBlock(chain string, height int64, progress *wealthProgress) (bool, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+
+	var blockTime int64
+	var rows *sql.Rows
+	var err error
+	if chain == mutilchain.TYPEDCR {
+		var t dbtypes.TimeDef
+		err = pgb.db.QueryRowContext(ctx, internal.SelectWealthBlock, height).Scan(&t)
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		blockTime = t.UNIX()
+		rows, err = pgb.db.QueryContext(ctx, internal.SelectWealthBlockDeltas, height)
+	} else {
+		var hash string
+		var numTx, storedTx int64
+		err = pgb.db.QueryRowContext(ctx, mutilchainquery.MakeSelectCoinAgeBlock(chain), height).
+			Scan(&hash, &blockTime, &numTx, &storedTx)
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if numTx == 0 || storedTx < numTx {
+			return false, nil
+		}
+		rows, err = pgb.db.QueryContext(ctx, mutilchainquery.MakeSelectWealthBlockDeltas(chain), height, hash)
+	}
+	if err != nil {
+		return false, err
+	}
+	var addrs []string
+	var deltas []int64
+	for rows.Next() {
+		var addr string
+		var delta int64
+		if err = rows.Scan(&addr, &delta); err != nil {
+			closeRows(rows)
+			return false, err
+		}
+		if addr == "" || delta == 0 {
+			continue
+		}
+		addrs = append(addrs, addr)
+		deltas = append(deltas, delta)
+	}
+	closeRows(rows)
+	if err = rows.Err(); err != nil {
+		return false, err
+	}
+
+	dbtx, err := pgb.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("unable to begin database transaction: %w", err)
+	}
+	next, err := storeWealthBlockTx(ctx, dbtx, chain, height, blockTime, progress, addrs, deltas)
+	if err != nil {
+		_ = dbtx.Rollback()
+		return false, err
+	}
+	if err = dbtx.Commit(); err != nil {
+		return false, err
+	}
+	*progress = *next
+	return true, nil
+}
+
+// storeWealthBlockTx stores the balance changes of a block in a database
+// transaction, and returns the new progress.
+func storeWealthBlockTx(ctx context.Context, dbtx *sql.Tx, chain string, height, blockTime int64,
+	progress *wealthProgress, addrs []string, deltas []int64) (*wealthProgress, error) {
+	if progress.height >= 0 && blockTime/86400 > progress.time/86400 &&
+		time.Since(time.Unix(blockTime, 0)) < wealthSnapshotAge {
+		_, err := dbtx.ExecContext(ctx, internal.InsertWealthSnapshot, chain, progress.height, progress.time)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = dbtx.ExecContext(ctx, internal.InsertWealthBuckets, chain, progress.height); err != nil {
+			return nil, err
+		}
+	}
+
+	next := &wealthProgress{
+		height:    height,
+		time:      blockTime,
+		addresses: progress.addresses,
+		total:     progress.total,
+	}
+	if len(addrs) > 0 {
+		rows, err := dbtx.QueryContext(ctx, internal.UpsertAddressBalances, chain,
+			pq.Array(addrs), pq.Array(deltas))
+		if err != nil {
+			return nil, err
+		}
+		// Emptied addresses are deleted, so an address that is not new had a
+		// positive balance.
+		var emptied []string
+		for rows.Next() {
+			var addr string
+			var balance int64
+			var inserted bool
+			if err = rows.Scan(&addr, &balance, &inserted); err != nil {
+				closeRows(rows)
+				return nil, err
+			}
+			switch {
+			case balance <= 0:
+				emptied = append(emptied, addr)
+				if !inserted {
+					next.addresses--
+				}
+			case inserted:
+				next.addresses++
+			}
+		}
+		closeRows(rows)
+		if err = rows.Err(); err != nil {
+			return nil, err
+		}
+		if len(emptied) > 0 {
+			if _, err = dbtx.ExecContext(ctx, internal.DeleteAddressBalances, chain, pq.Array(emptied)); err != nil {
+				return nil, err
+			}
+		}
+		for _, delta := range deltas {
+			next.total += delta
+		}
+	}
+
+	_, err := dbtx.ExecContext(ctx, internal.UpsertWealthProgress, chain, next.height, next.time,
+		next.addresses, next.total)
+	if err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// RichList retrieves a page of the addresses of a chain with the largest
+// balances, with their share of the total balance, their labels and their
+// address clusters, and the last wealth snapshot.
+func (pgb *ChainDB) RichList(chain string, limit, offset int) (*dbtypes.RichList, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+
+	progress, err := pgb.wealthProgress(chain)
+	if err != nil {
+		return nil, err
+	}
+	richList := &dbtypes.RichList{
+		Chain:     chain,
+		Height:    progress.height,
+		Addresses: progress.addresses,
+		Total:     progress.total,
+	}
+
+	rows, err := pgb.db.QueryContext(ctx, internal.SelectRichList, chain, limit, offset)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	defer closeRows(rows)
+	for rows.Next() {
+		entry := &dbtypes.RichListEntry{Rank: int64(offset + len(richList.Entries) + 1)}
+		if err = rows.Scan(&entry.Address, &entry.Balance, &entry.ClusterID); err != nil {
+			return nil, err
+		}
+		if progress.total > 0 {
+			entry.Share = 100 * float64(entry.Balance) / float64(progress.total)
+		}
+		entry.Label = pgb.labels.Name(chain, entry.Address)
+		richList.Entries = append(richList.Entries, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	richList.Snapshot, err = pgb.lastWealthSnapshot(ctx, chain)
+	if err != nil {
+		return nil, err
+	}
+	return richList, nil
+}
+
+// lastWealthSnapshot retrieves the last wealth snapshot of a chain with its
+// buckets, or nil if there is none.
+func (pgb *ChainDB) lastWealthSnapshot(ctx context.Context, chain string) (*dbtypes.WealthSnapshot, error) {
+	snapshot := new(dbtypes.WealthSnapshot)
+	err := pgb.db.QueryRowContext(ctx, internal.SelectLastWealthSnapshot, chain).Scan(&snapshot.Height,
+		&snapshot.Time, &snapshot.Addresses, &snapshot.Total, &snapshot.Gini, &snapshot.Nakamoto)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+
+	rows, err := pgb.db.QueryContext(ctx, internal.SelectWealthBuckets, chain, snapshot.Height)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	defer closeRows(rows)
+	buckets := make([]*dbtypes.WealthBucket, len(dbtypes.WealthBucketMins))
+	for i, bucketMin := range dbtypes.WealthBucketMins {
+		buckets[i] = &dbtypes.WealthBucket{Min: bucketMin}
+	}
+	for rows.Next() {
+		var bucket int
+		var addresses, value int64
+		if err = rows.Scan(&bucket, &addresses, &value); err != nil {
+			return nil, err
+		}
+		if bucket >= 0 && bucket < len(buckets) {
+			buckets[bucket].Addresses = addresses
+			buckets[bucket].Value = value
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	snapshot.Buckets = buckets
+	return snapshot, nil
+}
+
+// wealthChartRows queries the wealth snapshots of a chain above a height.
+func (pgb *ChainDB) wealthChartRows(chain string, height int64) (*sql.Rows, func(), error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	rows, err := pgb.db.QueryContext(ctx, internal.SelectWealthChartRows, chain, height)
+	if err != nil {
+		return nil, cancel, fmt.Errorf("%s: chartWealth: %w", chain, pgb.replaceCancelError(err))
+	}
+	return rows, cancel, nil
+}
+
+// chartWealth fetches the Decred wealth snapshots above the tip of the Wealth
+// chart data. This is the Fetcher half of a pair that make up a
+// cache.ChartUpdater. The Appender half is appendWealth.
+func (pgb *ChainDB) chartWealth(charts *cache.ChartData) (*sql.Rows, func(), error) {
+	return pgb.wealthChartRows(mutilchain.TYPEDCR, charts.WealthTip())
+}
+
+// appendWealth appends the results of chartWealth to the Wealth chart data.
+func appendWealth(charts *cache.ChartData, rows *sql.Rows) error {
+	return appendWealthRows(charts.Wealth, rows)
+}
+
+// chartMutilchainWealth fetches the wealth snapshots of a BTC or LTC chain
+// above the tip of the Wealth chart data. This is the Fetcher half of a pair
+// that make up a cache.ChartMutilchainUpdater. The Appender half is
+// appendMutilchainWealth.
+func (pgb *ChainDB) chartMutilchainWealth(charts *cache.MutilchainChartData) (*sql.Rows, func(), error) {
+	return pgb.wealthChartRows(charts.ChainType, charts.WealthTip())
+}
+
+// appendMutilchainWealth appends the results of chartMutilchainWealth to the
+// Wealth chart data.
+func appendMutilchainWealth(charts *cache.MutilchainChartData, rows *sql.Rows) error {
+	return appendWealthRows(charts.Wealth, rows)
+}
+
+// appendWealthRows appends wealth snapshots to a Wealth zoomSet. The buckets
+// follow dbtypes.WealthBucketMins.
+func appendWealthRows(wealth *cache.ZoomSet, rows *sql.Rows) error {
+	defer closeRows(rows)
+	for rows.Next() {
+		var height, blockTime, addresses, nakamoto int64
+		var gini float64
+		var buckets, bucketAddresses, bucketValue pq.Int64Array
+		err := rows.Scan(&height, &blockTime, &addresses, &gini, &nakamoto,
+			&buckets, &bucketAddresses, &bucketValue)
+		if err != nil {
+			return err
+		}
+		addressBands := make([]int64, len(dbtypes.WealthBucketMins))
+		valueBands := make([]int64, len(dbtypes.WealthBucketMins))
+		for i, bucket := range buckets {
+			if bucket < 0 || bucket >= int64(len(addressBands)) || i >= len(bucketAddresses) || i >= len(bucketValue) {
+				continue
+			}
+			addressBands[bucket] = bucketAddresses[i]
+			valueBands[bucket] = bucketValue[i]
+		}
+		wealth.Height = append(wealth.Height, uint64(height))
+		wealth.Time = append(wealth.Time, uint64(blockTime))
+		wealth.WealthAddresses = append(wealth.WealthAddresses, uint64(addresses))
+		wealth.Gini = append(wealth.Gini, gini)
+		wealth.Nakamoto = append(wealth.Nakamoto, uint64(nakamoto))
+		wealth.BucketAddresses = append(wealth.BucketAddresses, addressBands)
+		wealth.BucketValue = append(wealth.BucketValue, valueBands)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("appendWealthRows: iteration error: %w", err)
+	}
+	return nil
+}
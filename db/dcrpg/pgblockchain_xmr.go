@@ -15,10 +15,12 @@ import (
 
 	"github.com/decred/dcrdata/db/dcrpg/v8/internal/mutilchainquery"
 	"github.com/decred/dcrdata/v8/db/cache"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
 	exptypes "github.com/decred/dcrdata/v8/explorer/types"
 	"github.com/decred/dcrdata/v8/mutilchain"
 	"github.com/decred/dcrdata/v8/utils"
 	"github.com/decred/dcrdata/v8/xmr/xmrhelper"
+	"github.com/decred/dcrdata/v8/xmr/xmrring"
 	"github.com/decred/dcrdata/v8/xmr/xmrutil"
 	humanize "github.com/dustin/go-humanize"
 	"github.com/lib/pq"
@@ -47,7 +49,11 @@ func (pgb *ChainDB) XMRStore(blockData *xmrutil.BlockData) error {
 	pgb.XmrBestBlock.Hash = blockData.Header.Hash
 	pgb.XmrBestBlock.Height = int64(blockData.Header.Height)
 	pgb.XmrBestBlock.Time = int64(blockData.Header.Timestamp)
-	go pgb.SyncXMROneBlock(blockData)
+	go func() {
+		if err := pgb.SyncXMROneBlock(blockData); err == nil {
+			pgb.SyncXMRRingAnalysis()
+		}
+	}()
 	return nil
 }
 
@@ -151,6 +157,10 @@ func (pgb *ChainDB) rollbackToHeight(keepHeight int64) error {
 		if _, err := tx.ExecContext(pgb.ctx, mutilchainquery.DeleteRingMembersWithTxhashArray, pq.Array(toDeleteTxs)); err != nil {
 			return fmt.Errorf("XMR: rollbackToHeight: delete monero_ring_members failed: %v", err)
 		}
+		// Delete tx fingerprints
+		if _, err := tx.ExecContext(pgb.ctx, mutilchainquery.DeleteMoneroTxFingerprintsWithTxhashArray, pq.Array(toDeleteTxs)); err != nil {
+			return fmt.Errorf("XMR: rollbackToHeight: delete monero_tx_fingerprints failed: %v", err)
+		}
 		// Delete rct data
 		if _, err := tx.ExecContext(pgb.ctx, mutilchainquery.DeleteRctDataWithTxhashArray, pq.Array(toDeleteTxs)); err != nil {
 			return fmt.Errorf("XMR: rollbackToHeight: delete monero_rct_data failed: %v", err)
@@ -174,7 +184,19 @@ func (pgb *ChainDB) rollbackToHeight(keepHeight int64) error {
 		return fmt.Errorf("XMR: rollbackToHeight: delete monero_key_images failed: %v", err)
 	}
 
-	// 4) delete blocks > keepHeight
+	// 4) delete the ring analysis of the deleted blocks. The deductions that
+	// they caused in earlier rings are kept.
+	for _, stmt := range []string{
+		mutilchainquery.DeleteMoneroRingAnalysisAboveHeight,
+		mutilchainquery.DeleteMoneroDeducedSpendsAboveHeight,
+		mutilchainquery.DeleteMoneroRingStatsAboveHeight,
+	} {
+		if _, err := tx.ExecContext(pgb.ctx, stmt, keepHeight); err != nil {
+			return fmt.Errorf("XMR: rollbackToHeight: delete ring analysis failed: %v", err)
+		}
+	}
+
+	// 5) delete blocks > keepHeight
 	if _, err := tx.ExecContext(pgb.ctx, mutilchainquery.CreateDeleteBlocksWithMinHeightQuery(mutilchain.TYPEXMR), keepHeight); err != nil {
 		return fmt.Errorf("XMR: rollbackToHeight: delete blocks_all failed: %v", err)
 	}
@@ -242,6 +264,21 @@ func (pgb *ChainDB) chartXmrMutilchainRingMembers(charts *cache.MutilchainChartD
 	return rows, cancel, nil
 }
 
+// XMRKeyImageTx returns the hash and height of the transaction that spent the
+// key image. dbtypes.ErrNoResult is returned if the key image is unknown.
+func (pgb *ChainDB) XMRKeyImageTx(keyImage string) (string, int64, error) {
+	if pgb.ChainDBDisabled {
+		return "", 0, dbtypes.ErrNoResult
+	}
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	txHash, height, err := retrieveXMRKeyImageTx(ctx, pgb.db, keyImage)
+	if err == nil && txHash == "" {
+		return "", 0, dbtypes.ErrNoResult
+	}
+	return txHash, height, pgb.replaceCancelError(err)
+}
+
 func (pgb *ChainDB) GetXMRBlockchainInfo() (*xmrutil.BlockchainInfo, error) {
 	return pgb.XmrClient.GetInfo()
 }
@@ -433,7 +470,14 @@ func (pgb *ChainDB) GetXMRExplorerTx(txhash string) (*exptypes.TxInfo, error) {
 
 							ringOuts := make([]xmrutil.OutputInfo, 0)
 							if len(globalIdxs) > 0 {
-								ringCtOuts, err := pgb.XmrClient.GetOuts(globalIdxs)
+								// Pre-RingCT rings index the outputs of the input amount.
+								var ringCtOuts *xmrutil.GetOutsResult
+								var err error
+								if amountin > 0 {
+									ringCtOuts, err = pgb.XmrClient.GetAmountOuts(uint64(amountin), globalIdxs)
+								} else {
+									ringCtOuts, err = pgb.XmrClient.GetOuts(globalIdxs)
+								}
 								if err != nil {
 									return nil, err
 								}
@@ -441,6 +485,28 @@ func (pgb *ChainDB) GetXMRExplorerTx(txhash string) (*exptypes.TxInfo, error) {
 									ringOuts = append(ringOuts, ctOut)
 								}
 							}
+							// analyze the ring, at the next block for mempool txs
+							var ringAnalysis *xmrring.InputAnalysis
+							if len(globalIdxs) > 0 && len(ringOuts) == len(globalIdxs) {
+								in := &xmrring.Input{
+									TxHash:  txhash,
+									Index:   vinIdx,
+									Height:  txData.BlockHeight,
+									Amount:  uint64(amountin),
+									Members: make([]xmrring.Member, len(globalIdxs)),
+								}
+								if txData.InPool {
+									in.Height = pgb.XmrBestBlock.Height + 1
+								}
+								for i, gi := range globalIdxs {
+									in.Members[i] = xmrring.Member{Index: gi, Height: ringOuts[i].Height}
+								}
+								if a, err := pgb.analyzeXMRRing(in); err != nil {
+									log.Warnf("XMR: ring analysis of %s:%d failed: %v", txhash, vinIdx, err)
+								} else {
+									ringAnalysis = a
+								}
+							}
 							// key image k_image (if present)
 							if ki, ok5 := keyObj["k_image"].(string); ok5 && ki != "" {
 								keyImages = append(keyImages, exptypes.XmrKeyImageInfo{
@@ -450,6 +516,7 @@ func (pgb *ChainDB) GetXMRExplorerTx(txhash string) (*exptypes.TxInfo, error) {
 									RingMembers: globalIdxs,
 									RingCtOuts:  ringOuts,
 									AmountIn:    amountin,
+									Analysis:    ringAnalysis,
 								})
 							}
 						}
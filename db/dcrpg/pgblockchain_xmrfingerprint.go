@@ -0,0 +1,170 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrdata/v8/xmr/xmrfingerprint"
+	"github.com/lib/pq"
+
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal/mutilchainquery"
+)
+
+// insertXMRTxFingerprints stores the fingerprints of the transactions of a
+// block after flagging their fee outliers.
+func insertXMRTxFingerprints(dbtx *sql.Tx, fps []*xmrfingerprint.Fingerprint) error {
+	if len(fps) == 0 {
+		return nil
+	}
+	xmrfingerprint.MarkFeeOutliers(fps)
+	stmt, err := dbtx.Prepare(mutilchainquery.UpsertMoneroTxFingerprint)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, fp := range fps {
+		_, err = stmt.Exec(fp.TxHash, fp.BlockHeight, fp.BlockTime, fp.Version, fp.RctType, fp.Inputs,
+			fp.Outputs, fp.RingSize, fp.Size, fp.Fee, fp.FeePerByte, int64(fp.UnlockTime), fp.ExtraSize,
+			fp.ExtraTags, fp.PaymentID, fp.AdditionalPubkeys, pq.Array(fp.Flags))
+		if err != nil {
+			return fmt.Errorf("insert fingerprint of tx %s: %w", fp.TxHash, err)
+		}
+	}
+	return nil
+}
+
+func scanXMRTxFingerprint(rows interface{ Scan(...any) error }) (*xmrfingerprint.Fingerprint, error) {
+	var fp xmrfingerprint.Fingerprint
+	var unlockTime int64
+	var flags pq.StringArray
+	err := rows.Scan(&fp.TxHash, &fp.BlockHeight, &fp.BlockTime, &fp.Version, &fp.RctType, &fp.Inputs,
+		&fp.Outputs, &fp.RingSize, &fp.Size, &fp.Fee, &fp.FeePerByte, &unlockTime, &fp.ExtraSize,
+		&fp.ExtraTags, &fp.PaymentID, &fp.AdditionalPubkeys, &flags)
+	if err != nil {
+		return nil, err
+	}
+	fp.UnlockTime = uint64(unlockTime)
+	fp.Flags = []string(flags)
+	if fp.Flags == nil {
+		fp.Flags = []string{}
+	}
+	return &fp, nil
+}
+
+func (pgb *ChainDB) queryXMRTxFingerprints(query string, args ...any) ([]*xmrfingerprint.Fingerprint, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	rows, err := pgb.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	defer closeRows(rows)
+	fps := make([]*xmrfingerprint.Fingerprint, 0)
+	for rows.Next() {
+		fp, err := scanXMRTxFingerprint(rows)
+		if err != nil {
+			return nil, err
+		}
+		fps = append(fps, fp)
+	}
+	return fps, rows.Err()
+}
+
+// daemonXMRTxFingerprints fingerprints transactions from the daemon. The fee
+// outliers are not flagged.
+func (pgb *ChainDB) daemonXMRTxFingerprints(txHashes []string) ([]*xmrfingerprint.Fingerprint, error) {
+	if pgb.XmrClient == nil {
+		return nil, fmt.Errorf("XMR: no daemon client")
+	}
+	txs, err := pgb.XmrClient.GetTransactions(txHashes, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(txs.TxsAsJSON) != len(txHashes) || len(txs.TxsAsHex) != len(txHashes) || len(txs.Txs) != len(txHashes) {
+		return nil, fmt.Errorf("XMR: got %d of %d transactions", len(txs.TxsAsJSON), len(txHashes))
+	}
+	fps := make([]*xmrfingerprint.Fingerprint, 0, len(txHashes))
+	for i, txHash := range txHashes {
+		height := txs.Txs[i].BlockHeight
+		if txs.Txs[i].InPool {
+			height = -1
+		}
+		fp, err := xmrfingerprint.FromTxJSON(txHash, height, len(txs.TxsAsHex[i])/2, txs.TxsAsJSON[i])
+		if err != nil {
+			return nil, err
+		}
+		fp.BlockTime = int64(txs.Txs[i].BlockTimestamp)
+		fps = append(fps, fp)
+	}
+	return fps, nil
+}
+
+// XMRTxFingerprint is the fingerprint of a Monero transaction. Transactions
+// that are not stored, such as those in mempool, are fingerprinted from the
+// daemon without the fee outlier flags.
+func (pgb *ChainDB) XMRTxFingerprint(txHash string) (*xmrfingerprint.Fingerprint, error) {
+	if !pgb.ChainDBDisabled {
+		ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+		defer cancel()
+		fp, err := scanXMRTxFingerprint(pgb.db.QueryRowContext(ctx, mutilchainquery.SelectMoneroTxFingerprint, txHash))
+		if err == nil {
+			return fp, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, pgb.replaceCancelError(err)
+		}
+	}
+	fps, err := pgb.daemonXMRTxFingerprints([]string{txHash})
+	if err != nil {
+		return nil, err
+	}
+	return fps[0], nil
+}
+
+// XMRBlockFingerprints summarizes the fingerprints of the transactions of a
+// Monero block. Blocks that are not stored are fingerprinted from the daemon.
+func (pgb *ChainDB) XMRBlockFingerprints(height int64) (*xmrfingerprint.BlockSummary, error) {
+	if !pgb.ChainDBDisabled {
+		fps, err := pgb.queryXMRTxFingerprints(mutilchainquery.SelectMoneroBlockTxFingerprints, height)
+		if err != nil {
+			return nil, err
+		}
+		if len(fps) > 0 {
+			return xmrfingerprint.Summarize(height, fps), nil
+		}
+	}
+	if pgb.XmrClient == nil {
+		return xmrfingerprint.Summarize(height, nil), nil
+	}
+	br, err := pgb.XmrClient.GetBlock(uint64(height))
+	if err != nil {
+		return nil, err
+	}
+	fps := make([]*xmrfingerprint.Fingerprint, 0)
+	if len(br.TxHashes) > 0 {
+		if fps, err = pgb.daemonXMRTxFingerprints(br.TxHashes); err != nil {
+			return nil, err
+		}
+		xmrfingerprint.MarkFeeOutliers(fps)
+	}
+	return xmrfingerprint.Summarize(height, fps), nil
+}
+
+// XMRTxFingerprints selects the stored fingerprints of the Monero transactions
+// that match a filter.
+func (pgb *ChainDB) XMRTxFingerprints(filter *xmrfingerprint.Filter) ([]*xmrfingerprint.Fingerprint, error) {
+	if pgb.ChainDBDisabled {
+		return nil, fmt.Errorf("XMR: the transaction fingerprints need the database")
+	}
+	flags := filter.Flags
+	if flags == nil {
+		flags = []string{}
+	}
+	return pgb.queryXMRTxFingerprints(mutilchainquery.SelectMoneroTxFingerprintsFiltered, filter.FromHeight,
+		filter.ToHeight, pq.Array(flags), filter.Limit, filter.Offset)
+}
@@ -0,0 +1,393 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/decred/dcrdata/v8/db/cache"
+	"github.com/decred/dcrdata/v8/xmr/xmrring"
+	"github.com/decred/dcrdata/v8/xmr/xmrutil"
+	"github.com/lib/pq"
+
+	"github.com/decred/dcrdata/db/dcrpg/v8/internal/mutilchainquery"
+)
+
+// maxOutsPerRequest is the number of outputs requested from the Monero daemon
+// at a time.
+const maxOutsPerRequest = 1000
+
+// sqlContextExecQueryer is implemented by both sql.DB and sql.Tx.
+type sqlContextExecQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// xmrRingStore is the xmrring.Store of the Monero ring analysis tables.
+type xmrRingStore struct {
+	ctx context.Context
+	db  sqlContextExecQueryer
+}
+
+// Spends returns the deduced real spends of the outputs.
+func (s *xmrRingStore) Spends(keys []xmrring.OutputKey) (map[xmrring.OutputKey]xmrring.Spend, error) {
+	byAmount := make(map[uint64][]int64)
+	for _, key := range keys {
+		byAmount[key.Amount] = append(byAmount[key.Amount], int64(key.Index))
+	}
+	spends := make(map[xmrring.OutputKey]xmrring.Spend)
+	for amount, indices := range byAmount {
+		rows, err := s.db.QueryContext(s.ctx, mutilchainquery.SelectMoneroDeducedSpends, int64(amount), pq.Array(indices))
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var index int64
+			var spend xmrring.Spend
+			if err = rows.Scan(&index, &spend.TxHash, &spend.InputIndex, &spend.Height); err != nil {
+				closeRows(rows)
+				return nil, err
+			}
+			spends[xmrring.OutputKey{Amount: amount, Index: uint64(index)}] = spend
+		}
+		err = rows.Err()
+		closeRows(rows)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return spends, nil
+}
+
+// AddSpend records the deduced real spend of an output.
+func (s *xmrRingStore) AddSpend(key xmrring.OutputKey, spend xmrring.Spend) error {
+	_, err := s.db.ExecContext(s.ctx, mutilchainquery.InsertMoneroDeducedSpend, int64(key.Amount),
+		int64(key.Index), spend.TxHash, spend.InputIndex, spend.Height)
+	return err
+}
+
+// Referencing returns the analyzed inputs without a deduced real spend whose
+// rings reference the output.
+func (s *xmrRingStore) Referencing(key xmrring.OutputKey) ([]*xmrring.Input, error) {
+	rows, err := s.db.QueryContext(s.ctx, mutilchainquery.SelectMoneroRingsReferencing, int64(key.Amount), int64(key.Index))
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+	var inputs []*xmrring.Input
+	for rows.Next() {
+		in := &xmrring.Input{Amount: key.Amount}
+		var members, heights pq.Int64Array
+		if err = rows.Scan(&in.TxHash, &in.Index, &in.Height, &members, &heights); err != nil {
+			return nil, err
+		}
+		if len(members) != len(heights) {
+			return nil, fmt.Errorf("ring of input %s:%d has %d members and %d heights", in.TxHash,
+				in.Index, len(members), len(heights))
+		}
+		in.Members = make([]xmrring.Member, len(members))
+		for i := range members {
+			in.Members[i] = xmrring.Member{Index: uint64(members[i]), Height: heights[i]}
+		}
+		inputs = append(inputs, in)
+	}
+	return inputs, rows.Err()
+}
+
+// Update records the analysis of an input.
+func (s *xmrRingStore) Update(in *xmrring.Input, a *xmrring.InputAnalysis) error {
+	members := make(pq.Int64Array, len(in.Members))
+	heights := make(pq.Int64Array, len(in.Members))
+	for i, m := range in.Members {
+		members[i], heights[i] = int64(m.Index), m.Height
+	}
+	var deduced sql.NullInt64
+	var deduction sql.NullString
+	if a.Deduced >= 0 {
+		deduced = sql.NullInt64{Int64: int64(in.Members[a.Deduced].Index), Valid: true}
+		deduction = sql.NullString{String: string(a.Deduction), Valid: true}
+	}
+	_, err := s.db.ExecContext(s.ctx, mutilchainquery.UpsertMoneroRingAnalysis, in.TxHash, in.Index, in.Height,
+		int64(in.Amount), members, heights, a.RingSize, a.EffectiveRingSize, a.BeforeCreation, a.SpentBefore,
+		deduced, deduction, int64(a.MedianAge.Seconds()))
+	return err
+}
+
+// xmrRingInputs parses the inputs of a Monero transaction from its JSON. The
+// member heights are not set.
+func xmrRingInputs(txHash string, height int64, txJSON string) ([]*xmrring.Input, error) {
+	var tx struct {
+		Vin []struct {
+			Key *struct {
+				Amount     uint64   `json:"amount"`
+				KeyOffsets []uint64 `json:"key_offsets"`
+			} `json:"key"`
+		} `json:"vin"`
+	}
+	if err := json.Unmarshal([]byte(txJSON), &tx); err != nil {
+		return nil, fmt.Errorf("unmarshal tx %s json: %w", txHash, err)
+	}
+	var inputs []*xmrring.Input
+	for i, vin := range tx.Vin {
+		if vin.Key == nil {
+			// A coinbase input.
+			continue
+		}
+		in := &xmrring.Input{
+			TxHash:  txHash,
+			Index:   i,
+			Height:  height,
+			Amount:  vin.Key.Amount,
+			Members: make([]xmrring.Member, len(vin.Key.KeyOffsets)),
+		}
+		var index uint64
+		for j, offset := range vin.Key.KeyOffsets {
+			index += offset
+			in.Members[j] = xmrring.Member{Index: index, Height: -1}
+		}
+		inputs = append(inputs, in)
+	}
+	return inputs, nil
+}
+
+// setXMRRingMemberHeights sets the heights of the ring members of the inputs
+// from the daemon.
+func (pgb *ChainDB) setXMRRingMemberHeights(inputs []*xmrring.Input) error {
+	heights := make(map[xmrring.OutputKey]int64)
+	byAmount := make(map[uint64][]uint64)
+	for _, in := range inputs {
+		for pos := range in.Members {
+			key := in.Key(pos)
+			if _, found := heights[key]; !found {
+				heights[key] = -1
+				byAmount[key.Amount] = append(byAmount[key.Amount], key.Index)
+			}
+		}
+	}
+	for amount, indices := range byAmount {
+		for start := 0; start < len(indices); start += maxOutsPerRequest {
+			chunk := indices[start:min(start+maxOutsPerRequest, len(indices))]
+			var res *xmrutil.GetOutsResult
+			var err error
+			if amount == 0 {
+				res, err = pgb.XmrClient.GetOuts(chunk)
+			} else {
+				res, err = pgb.XmrClient.GetAmountOuts(amount, chunk)
+			}
+			if err != nil {
+				return err
+			}
+			if len(res.Outs) != len(chunk) {
+				return fmt.Errorf("get_outs returned %d of %d outputs", len(res.Outs), len(chunk))
+			}
+			for i, out := range res.Outs {
+				heights[xmrring.OutputKey{Amount: amount, Index: chunk[i]}] = out.Height
+			}
+		}
+	}
+	for _, in := range inputs {
+		for pos := range in.Members {
+			in.Members[pos].Height = heights[in.Key(pos)]
+		}
+	}
+	return nil
+}
+
+// XMRRingAnalysis analyzes the rings of the inputs of a Monero transaction
+// with the stored deduced real spends.
+func (pgb *ChainDB) XMRRingAnalysis(txHash string, height int64, txJSON string) ([]*xmrring.InputAnalysis, error) {
+	inputs, err := xmrRingInputs(txHash, height, txJSON)
+	if err != nil {
+		return nil, err
+	}
+	if err = pgb.setXMRRingMemberHeights(inputs); err != nil {
+		return nil, err
+	}
+	analyses := make([]*xmrring.InputAnalysis, 0, len(inputs))
+	for _, in := range inputs {
+		a, err := pgb.analyzeXMRRing(in)
+		if err != nil {
+			return nil, err
+		}
+		analyses = append(analyses, a)
+	}
+	return analyses, nil
+}
+
+// analyzeXMRRing analyzes the ring of an input, with its member heights set,
+// given the stored deduced real spends. Without a database, only the members
+// referenced before their creation are eliminated.
+func (pgb *ChainDB) analyzeXMRRing(in *xmrring.Input) (*xmrring.InputAnalysis, error) {
+	spends := make(map[xmrring.OutputKey]xmrring.Spend)
+	if !pgb.ChainDBDisabled {
+		ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+		defer cancel()
+		keys := make([]xmrring.OutputKey, len(in.Members))
+		for pos := range in.Members {
+			keys[pos] = in.Key(pos)
+		}
+		var err error
+		store := &xmrRingStore{ctx: ctx, db: pgb.db}
+		if spends, err = store.Spends(keys); err != nil {
+			return nil, pgb.replaceCancelError(err)
+		}
+	}
+	return xmrring.Analyze(in, spends), nil
+}
+
+// storeXMRRingAnalysis analyzes the rings of the inputs of the Monero block at
+// height and stores the analyses and the block totals.
+func (pgb *ChainDB) storeXMRRingAnalysis(height int64) error {
+	br, err := pgb.XmrClient.GetBlock(uint64(height))
+	if err != nil {
+		return err
+	}
+	header, err := pgb.XmrClient.GetBlockHeaderByHeight(uint64(height))
+	if err != nil {
+		return err
+	}
+	var inputs []*xmrring.Input
+	if len(br.TxHashes) > 0 {
+		txs, err := pgb.XmrClient.GetTransactions(br.TxHashes, true)
+		if err != nil {
+			return err
+		}
+		if len(txs.TxsAsJSON) != len(br.TxHashes) {
+			return fmt.Errorf("got %d of %d transactions", len(txs.TxsAsJSON), len(br.TxHashes))
+		}
+		for i, txHash := range br.TxHashes {
+			txInputs, err := xmrRingInputs(txHash, height, txs.TxsAsJSON[i])
+			if err != nil {
+				return err
+			}
+			inputs = append(inputs, txInputs...)
+		}
+	}
+	if err = pgb.setXMRRingMemberHeights(inputs); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	dbtx, err := pgb.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin database transaction: %w", err)
+	}
+	store := &xmrRingStore{ctx: ctx, db: dbtx}
+	var ringSizeSum, effectiveSum int64
+	var deduced, beforeCreation, spentBefore int
+	ageBands := make(pq.Int64Array, len(xmrring.AgeBandLabels))
+	for _, in := range inputs {
+		a, err := xmrring.Deduce(store, in)
+		if err != nil {
+			_ = dbtx.Rollback()
+			return err
+		}
+		ringSizeSum += int64(a.RingSize)
+		effectiveSum += int64(a.EffectiveRingSize)
+		if a.Deduced >= 0 {
+			deduced++
+		}
+		beforeCreation += a.BeforeCreation
+		spentBefore += a.SpentBefore
+		for band, n := range a.AgeBands {
+			ageBands[band] += n
+		}
+	}
+	_, err = dbtx.ExecContext(ctx, mutilchainquery.UpsertMoneroRingStats, height, int64(header.Timestamp),
+		len(inputs), ringSizeSum, effectiveSum, deduced, beforeCreation, spentBefore, ageBands)
+	if err != nil {
+		_ = dbtx.Rollback()
+		return err
+	}
+	return dbtx.Commit()
+}
+
+// SyncXMRRingAnalysis analyzes the rings of the Monero blocks up to the best
+// block, less xmrring.SpendableAge, in height order. It returns at once when
+// the analysis is already being synced, so it may be called for every new
+// block.
+func (pgb *ChainDB) SyncXMRRingAnalysis() {
+	if pgb.ChainDBDisabled || !pgb.XmrSyncFlag || pgb.XmrClient == nil || pgb.XmrBestBlock == nil {
+		return
+	}
+	if !pgb.xmrRingSyncMtx.TryLock() {
+		return
+	}
+	defer pgb.xmrRingSyncMtx.Unlock()
+
+	for _, stmt := range []string{
+		mutilchainquery.IndexMoneroRingAnalysisOnMembers,
+		mutilchainquery.IndexMoneroRingAnalysisOnBlockHeight,
+	} {
+		if _, err := pgb.db.ExecContext(pgb.ctx, stmt); err != nil {
+			log.Errorf("XMR: failed to index ring analysis table: %v", err)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	var height int64
+	err := pgb.db.QueryRowContext(ctx, mutilchainquery.SelectMoneroRingStatsMaxHeight).Scan(&height)
+	cancel()
+	if err != nil {
+		log.Errorf("XMR: failed to get the ring analysis height: %v", pgb.replaceCancelError(err))
+		return
+	}
+
+	start := time.Now()
+	for height++; height <= pgb.XmrBestBlock.Height-xmrring.SpendableAge && pgb.ctx.Err() == nil; height++ {
+		if err = pgb.storeXMRRingAnalysis(height); err != nil {
+			log.Errorf("XMR: failed to store ring analysis of block %d: %v", height, pgb.replaceCancelError(err))
+			return
+		}
+		if height%10000 == 0 {
+			log.Infof("XMR: ring analysis synced to block %d", height)
+		}
+	}
+	log.Debugf("XMR: ring analysis synced to block %d in %v", height-1, time.Since(start))
+}
+
+// chartXmrRingAnalysis fetches the ring analysis totals of the blocks above
+// the RingAnalysis height. This is the Fetcher half of a pair that make up a
+// cache.ChartMutilchainUpdater. The Appender half is appendXmrRingAnalysis.
+func (pgb *ChainDB) chartXmrRingAnalysis(charts *cache.MutilchainChartData) (*sql.Rows, func(), error) {
+	ctx, cancel := context.WithCancel(pgb.ctx)
+	rows, err := pgb.db.QueryContext(ctx, mutilchainquery.SelectMoneroRingStatsChartRows, charts.RingAnalysisTip())
+	if err != nil {
+		return nil, cancel, fmt.Errorf("XMR: chartRingAnalysis: %w", pgb.replaceCancelError(err))
+	}
+	return rows, cancel, nil
+}
+
+// appendXmrRingAnalysis appends the results of chartXmrRingAnalysis to the
+// RingAnalysis zoomSet.
+func appendXmrRingAnalysis(charts *cache.MutilchainChartData, rows *sql.Rows) error {
+	defer closeRows(rows)
+	set := charts.RingAnalysis
+	for rows.Next() {
+		var height, blockTime, inputs, ringSizeSum, effectiveSum, deduced uint64
+		var beforeCreation, spentBefore uint64
+		var ageBands pq.Int64Array
+		if err := rows.Scan(&height, &blockTime, &inputs, &ringSizeSum, &effectiveSum, &deduced,
+			&beforeCreation, &spentBefore, &ageBands); err != nil {
+			return err
+		}
+		set.Height = append(set.Height, height)
+		set.Time = append(set.Time, blockTime)
+		set.RingInputs = append(set.RingInputs, inputs)
+		set.RingSizeSum = append(set.RingSizeSum, ringSizeSum)
+		set.EffectiveRingSizeSum = append(set.EffectiveRingSizeSum, effectiveSum)
+		set.DeducedInputs = append(set.DeducedInputs, deduced)
+		set.RingMemberAges = append(set.RingMemberAges, []int64(ageBands))
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("appendXmrRingAnalysis: iteration error: %w", err)
+	}
+	return nil
+}
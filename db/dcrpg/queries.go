@@ -2117,6 +2117,30 @@ func RetrieveAddressDbUTXOs(ctx context.Context, db *sql.DB, address string) ([]
 	return outputs, nil
 }
 
+// RetrieveMutilchainAddressDbUTXOs gets the unspent transaction outputs paying
+// to a BTC or LTC address.
+func RetrieveMutilchainAddressDbUTXOs(ctx context.Context, db *sql.DB, address, chainType string) ([]*dbtypes.MutilchainAddressTxnOutput, error) {
+	rows, err := db.QueryContext(ctx, mutilchainquery.MakeSelectAddressUnspentWithTxn(chainType), address)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var outputs []*dbtypes.MutilchainAddressTxnOutput
+	for rows.Next() {
+		pkScript := []byte{}
+		txnOutput := new(dbtypes.MutilchainAddressTxnOutput)
+		if err = rows.Scan(&txnOutput.Address, &txnOutput.TxHash,
+			&txnOutput.Atoms, &txnOutput.Height, &txnOutput.BlockTime,
+			&txnOutput.Vout, &pkScript); err != nil {
+			return nil, err
+		}
+		txnOutput.PkScript = hex.EncodeToString(pkScript)
+		outputs = append(outputs, txnOutput)
+	}
+	return outputs, rows.Err()
+}
+
 // RetrieveAddressTxnsOrdered will get all transactions for addresses provided
 // and return them sorted by time in descending order. It will also return a
 // short list of recently (defined as greater than recentBlockHeight) confirmed
@@ -2935,6 +2959,148 @@ func RetrieveMutilchainSpendingTxByTxOut(ctx context.Context, db *sql.DB, txHash
 	return
 }
 
+// RetrieveMutilchainVoutValueAndPkScript gets the value and pkScript of the
+// specified BTC or LTC outpoint.
+func RetrieveMutilchainVoutValueAndPkScript(ctx context.Context, db *sql.DB, txHash string,
+	voutIndex uint32, chainType string) (value int64, pkScript []byte, err error) {
+	err = db.QueryRowContext(ctx, mutilchainquery.MakeRetrieveVoutValueAndPkScript(chainType),
+		txHash, voutIndex).Scan(&value, &pkScript)
+	return
+}
+
+// UpsertMutilchainBlockPool inserts or updates the mining pool attribution of
+// a BTC or LTC block.
+func UpsertMutilchainBlockPool(ctx context.Context, db *sql.DB, bp *dbtypes.BlockPool, chainType string) error {
+	_, err := db.ExecContext(ctx, mutilchainquery.MakeUpsertBlockPool(chainType),
+		bp.Height, bp.Hash, bp.Time, bp.PoolSlug, bp.PoolName, bp.CoinbaseScript,
+		pq.Array(bp.PayoutAddresses), bp.Reward, bp.NumTx, bp.DefsVersion)
+	return err
+}
+
+// UpdateMutilchainBlockPoolAttribution sets the pool of an attributed block.
+func UpdateMutilchainBlockPoolAttribution(ctx context.Context, db *sql.DB, height int64,
+	poolSlug, poolName string, defsVersion int, chainType string) error {
+	_, err := db.ExecContext(ctx, mutilchainquery.MakeUpdateBlockPoolAttribution(chainType),
+		height, poolSlug, poolName, defsVersion)
+	return err
+}
+
+// RetrieveMutilchainBlocksWithoutPool retrieves the heights and hashes of up
+// to limit blocks that have no pool attribution, highest first.
+func RetrieveMutilchainBlocksWithoutPool(ctx context.Context, db *sql.DB, limit int64,
+	chainType string) (heights []int64, hashes []string, err error) {
+	var rows *sql.Rows
+	rows, err = db.QueryContext(ctx, mutilchainquery.MakeSelectBlocksWithoutPool(chainType), limit)
+	if err != nil {
+		return
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		var height int64
+		var hash string
+		if err = rows.Scan(&height, &hash); err != nil {
+			return
+		}
+		heights = append(heights, height)
+		hashes = append(hashes, hash)
+	}
+	err = rows.Err()
+	return
+}
+
+// RetrieveMutilchainBlockPoolsByDefsVersion retrieves up to limit block pool
+// attributions made with pool definitions older than defsVersion.
+func RetrieveMutilchainBlockPoolsByDefsVersion(ctx context.Context, db *sql.DB, defsVersion int,
+	limit int64, chainType string) ([]*dbtypes.BlockPool, error) {
+	rows, err := db.QueryContext(ctx, mutilchainquery.MakeSelectBlockPoolsByDefsVersion(chainType),
+		defsVersion, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var bps []*dbtypes.BlockPool
+	for rows.Next() {
+		bp := new(dbtypes.BlockPool)
+		if err = rows.Scan(&bp.Height, &bp.CoinbaseScript, pq.Array(&bp.PayoutAddresses)); err != nil {
+			return nil, err
+		}
+		bps = append(bps, bp)
+	}
+	return bps, rows.Err()
+}
+
+// RetrieveMutilchainLastBlockPools retrieves the pool attributions of the
+// count blocks at or below height, highest first.
+func RetrieveMutilchainLastBlockPools(ctx context.Context, db *sql.DB, height, count int64,
+	chainType string) ([]*dbtypes.BlockPool, error) {
+	rows, err := db.QueryContext(ctx, mutilchainquery.MakeSelectLastBlockPools(chainType), height, count)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var bps []*dbtypes.BlockPool
+	for rows.Next() {
+		bp := new(dbtypes.BlockPool)
+		if err = rows.Scan(&bp.Height, &bp.PoolSlug, &bp.PoolName, &bp.Reward); err != nil {
+			return nil, err
+		}
+		bps = append(bps, bp)
+	}
+	return bps, rows.Err()
+}
+
+// RetrieveMutilchainPoolBlockCountsSince retrieves the number of blocks mined
+// by each pool since the given time.
+func RetrieveMutilchainPoolBlockCountsSince(ctx context.Context, db *sql.DB, since int64,
+	chainType string) (map[string]*dbtypes.PoolBlockCount, error) {
+	rows, err := db.QueryContext(ctx, mutilchainquery.MakeSelectPoolBlockCountsSince(chainType), since)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	counts := make(map[string]*dbtypes.PoolBlockCount)
+	for rows.Next() {
+		c := new(dbtypes.PoolBlockCount)
+		if err = rows.Scan(&c.PoolSlug, &c.Blocks, &c.EmptyBlocks); err != nil {
+			return nil, err
+		}
+		counts[c.PoolSlug] = c
+	}
+	return counts, rows.Err()
+}
+
+// RetrieveMutilchainPoolBlockCountsByBin retrieves the number of blocks mined
+// by each pool in time bins of binSize seconds. The bins are in ascending
+// order.
+func RetrieveMutilchainPoolBlockCountsByBin(ctx context.Context, db *sql.DB, binSize int64,
+	chainType string) (bins []int64, counts []map[string]int64, err error) {
+	var rows *sql.Rows
+	rows, err = db.QueryContext(ctx, mutilchainquery.MakeSelectPoolBlockCountsByBin(chainType), binSize)
+	if err != nil {
+		return
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		var bin, count int64
+		var slug string
+		if err = rows.Scan(&bin, &slug, &count); err != nil {
+			return
+		}
+		if len(bins) == 0 || bins[len(bins)-1] != bin {
+			bins = append(bins, bin)
+			counts = append(counts, make(map[string]int64))
+		}
+		counts[len(counts)-1][slug] = count
+	}
+	err = rows.Err()
+	return
+}
+
 // RetrieveSpendingTxsByFundingTx gets info on all spending transaction inputs
 // for the given funding transaction specified by DB row ID. This function is
 // called by SpendingTransactions, an important part of the transaction page
@@ -4161,49 +4327,6 @@ func appendChartBlocks(charts *cache.ChartData, rows *sql.Rows) error {
 	return nil
 }
 
-func appendMutilchainChartBlocks(charts *cache.MutilchainChartData, rows *sql.Rows) error {
-	// TODO when handler sync all blockchain data to DB, uncomment
-	// defer closeRows(rows)
-
-	// var count, size, height uint64
-	// var difficulty float64
-	// var rowCount int32
-	// var time uint64
-	// blocks := charts.Blocks
-	// for rows.Next() {
-	// 	rowCount++
-	// 	// Get the chainwork.
-	// 	err := rows.Scan(&height, &size, &time, &count, &difficulty)
-	// 	if err != nil {
-	// 		return err
-	// 	}
-	// 	blocks.Height = append(blocks.Height, height)
-	// 	blocks.TxCount = append(blocks.TxCount, count)
-	// 	blocks.Time = append(blocks.Time, time)
-	// 	blocks.BlockSize = append(blocks.BlockSize, size)
-	// 	blocks.Difficulty = append(blocks.Difficulty, difficulty)
-	// 	hashrateEst := dbtypes.CalculateHashRate(difficulty, charts.TimePerBlocks)
-	// 	blocks.Hashrate = append(blocks.Hashrate, hashrateEst)
-	// }
-	// if err := rows.Err(); err != nil {
-	// 	return fmt.Errorf("appendChartBlocks: iteration error: %w", err)
-	// }
-	//if lastest db height less than lastblock height, use external api to display chart
-	if !charts.UseSyncDB {
-		charts.UseAPI = true
-		if charts.ChainType == mutilchain.TYPEXMR {
-			return nil
-		}
-		//handler api data for charts
-		apiErr := HandlerMutilchainAPIDataForCharts(charts)
-		if apiErr == nil {
-			return nil
-		}
-	}
-	charts.UseAPI = false
-	return nil
-}
-
 func HandlerMutilchainAPIDataForCharts(charts *cache.MutilchainChartData) error {
 	return externalapi.HandlerMutilchainChartsData(charts)
 }
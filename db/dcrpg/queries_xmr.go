@@ -32,6 +32,11 @@ func retrieveXMRInputsCount(ctx context.Context, db *sql.DB) (inputsCount int64,
 	return
 }
 
+func retrieveXMRKeyImageTx(ctx context.Context, db *sql.DB, keyImage string) (txHash string, height int64, err error) {
+	err = db.QueryRowContext(ctx, mutilchainquery.SelectMoneroKeyImageTx, keyImage).Scan(&txHash, &height)
+	return
+}
+
 func retrieveXMRRingMembersCount(ctx context.Context, db *sql.DB) (ringMemberCount int64, err error) {
 	err = db.QueryRowContext(ctx, mutilchainquery.SelectTotalXmrRingMembers).Scan(&ringMemberCount)
 	return
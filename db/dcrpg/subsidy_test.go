@@ -0,0 +1,40 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	btcchaincfg "github.com/btcsuite/btcd/chaincfg"
+	ltcchaincfg "github.com/ltcsuite/ltcd/chaincfg"
+)
+
+func TestRegtestSubsidy(t *testing.T) {
+	// Regtest halves the subsidy every 150 blocks.
+	btcParams := &btcchaincfg.RegressionNetParams
+	tests := []struct {
+		height int32
+		want   btcutil.Amount
+	}{
+		{1, 50 * btcutil.SatoshiPerBitcoin},
+		{149, 50 * btcutil.SatoshiPerBitcoin},
+		{150, 25 * btcutil.SatoshiPerBitcoin},
+		{450, 625 * btcutil.SatoshiPerBitcoin / 100},
+		{150 * 64, 0},
+	}
+	for _, tt := range tests {
+		if got := CalcBTCBlockSubsidy(tt.height, btcParams); got != tt.want {
+			t.Errorf("BTC regtest subsidy at %d: got %v, want %v", tt.height, got, tt.want)
+		}
+	}
+	if got, want := SumBTCSubsidy(0, 299, btcParams, true), btcutil.Amount(149*50+150*25)*btcutil.SatoshiPerBitcoin; got != want {
+		t.Errorf("BTC regtest subsidy sum: got %v, want %v", got, want)
+	}
+
+	ltcParams := &ltcchaincfg.RegressionNetParams
+	if got := CalcLTCBlockSubsidy(150, ltcParams); int64(got) != 25*btcutil.SatoshiPerBitcoin {
+		t.Errorf("LTC regtest subsidy at 150: got %v, want 25 LTC", got)
+	}
+}
@@ -45,6 +45,23 @@ var createTableStatements = [][2]string{
 	{"blocks24h", internal.Create24hBlocksTable},
 	{"tspend_votes", internal.CreateTSpendVotesTable},
 	{"black_list", internal.CreateBlackListTable},
+	{"exchange_candlesticks", internal.CreateExchangeCandlesticksTable},
+	{"exchange_depth_snapshots", internal.CreateExchangeDepthSnapshotsTable},
+	{"exchange_price_ticks", internal.CreateExchangePriceTicksTable},
+	{"watches", internal.CreateWatchesTable},
+	{"watch_deliveries", internal.CreateWatchDeliveriesTable},
+	{"watch_confirmations", internal.CreateWatchConfirmationsTable},
+	{"address_clusters", internal.CreateAddressClustersTable},
+	{"cluster_txs", internal.CreateClusterTxsTable},
+	{"cluster_progress", internal.CreateClusterProgressTable},
+	{"mixing_stats", internal.CreateMixingStatsTable},
+	{"mixing_denoms", internal.CreateMixingDenomsTable},
+	{"mix_rounds", internal.CreateMixRoundsTable},
+	{"address_balances", internal.CreateAddressBalancesTable},
+	{"wealth_progress", internal.CreateWealthProgressTable},
+	{"wealth_snapshots", internal.CreateWealthSnapshotsTable},
+	{"wealth_buckets", internal.CreateWealthBucketsTable},
+	{"address_labels", internal.CreateAddressLabelsTable},
 }
 
 func GetCreateDBTables() [][2]string {
@@ -63,11 +80,19 @@ func GetCreateDBTables() [][2]string {
 		result = append(result, [2]string{fmt.Sprintf("%svins_all", chainType), mutilchainquery.CreateVinAllTableFunc(chainType)})
 		result = append(result, [2]string{fmt.Sprintf("%svouts", chainType), mutilchainquery.CreateVoutTableFunc(chainType)})
 		result = append(result, [2]string{fmt.Sprintf("%svouts_all", chainType), mutilchainquery.CreateVoutAllTableFunc(chainType)})
+		if chainType == mutilchain.TYPEBTC || chainType == mutilchain.TYPELTC {
+			result = append(result, [2]string{fmt.Sprintf("%sblock_pools", chainType), mutilchainquery.CreateBlockPoolsTableFunc(chainType)})
+			result = append(result, [2]string{fmt.Sprintf("%scoinjoins", chainType), mutilchainquery.CreateCoinJoinsTableFunc(chainType)})
+			result = append(result, coinAgeTables(chainType)...)
+			result = append(result, newAddressesTables(chainType)...)
+		}
 		if chainType == mutilchain.TYPEXMR {
 			result = append(result, [2]string{"monero_outputs", mutilchainquery.CreateMoneroOutputsTable})
 			result = append(result, [2]string{"monero_key_images", mutilchainquery.CreateMoneroKeyImagesTable})
 			result = append(result, [2]string{"monero_ring_members", mutilchainquery.CreateMoneroRingMembers})
 			result = append(result, [2]string{"monero_rct_data", mutilchainquery.CreateMoneroRctData})
+			result = append(result, moneroRingTables()...)
+			result = append(result, [2]string{"monero_tx_fingerprints", mutilchainquery.CreateMoneroTxFingerprintsTable})
 		}
 	}
 	return result
@@ -87,15 +112,52 @@ func GetMutilchainTables(chainType string) [][2]string {
 	result = append(result, [2]string{fmt.Sprintf("%svouts", chainType), mutilchainquery.CreateVoutTableFunc(chainType)})
 	result = append(result, [2]string{fmt.Sprintf("%svins_all", chainType), mutilchainquery.CreateVinAllTableFunc(chainType)})
 	result = append(result, [2]string{fmt.Sprintf("%svouts_all", chainType), mutilchainquery.CreateVoutAllTableFunc(chainType)})
+	if chainType == mutilchain.TYPEBTC || chainType == mutilchain.TYPELTC {
+		result = append(result, [2]string{fmt.Sprintf("%sblock_pools", chainType), mutilchainquery.CreateBlockPoolsTableFunc(chainType)})
+		result = append(result, [2]string{fmt.Sprintf("%scoinjoins", chainType), mutilchainquery.CreateCoinJoinsTableFunc(chainType)})
+		result = append(result, coinAgeTables(chainType)...)
+		result = append(result, newAddressesTables(chainType)...)
+	}
 	if chainType == mutilchain.TYPEXMR {
 		result = append(result, [2]string{"monero_outputs", mutilchainquery.CreateMoneroOutputsTable})
 		result = append(result, [2]string{"monero_key_images", mutilchainquery.CreateMoneroKeyImagesTable})
 		result = append(result, [2]string{"monero_ring_members", mutilchainquery.CreateMoneroRingMembers})
 		result = append(result, [2]string{"monero_rct_data", mutilchainquery.CreateMoneroRctData})
+		result = append(result, moneroRingTables()...)
+		result = append(result, [2]string{"monero_tx_fingerprints", mutilchainquery.CreateMoneroTxFingerprintsTable})
 	}
 	return result
 }
 
+// moneroRingTables are the Monero ring analysis tables.
+func moneroRingTables() [][2]string {
+	return [][2]string{
+		{"monero_ring_analysis", mutilchainquery.CreateMoneroRingAnalysisTable},
+		{"monero_deduced_spends", mutilchainquery.CreateMoneroDeducedSpendsTable},
+		{"monero_ring_stats", mutilchainquery.CreateMoneroRingStatsTable},
+	}
+}
+
+// coinAgeTables are the BTC and LTC coin age and daily market tables.
+func coinAgeTables(chainType string) [][2]string {
+	return [][2]string{
+		{fmt.Sprintf("%sutxo_history", chainType), mutilchainquery.CreateUtxoHistoryTableFunc(chainType)},
+		{fmt.Sprintf("%sutxo_age_buckets", chainType), mutilchainquery.CreateUtxoAgeBucketsTableFunc(chainType)},
+		{fmt.Sprintf("%scoin_age", chainType), mutilchainquery.CreateCoinAgeTableFunc(chainType)},
+		{fmt.Sprintf("%scoin_age_bands", chainType), mutilchainquery.CreateCoinAgeBandsTableFunc(chainType)},
+		{fmt.Sprintf("%smca_snapshots", chainType), mutilchainquery.CreateMcaSnapshotsTableFunc(chainType)},
+		{fmt.Sprintf("%sdaily_market", chainType), mutilchainquery.CreateDailyMarketTableFunc(chainType)},
+	}
+}
+
+// newAddressesTables are the BTC and LTC new address tables.
+func newAddressesTables(chainType string) [][2]string {
+	return [][2]string{
+		{fmt.Sprintf("%saddress_first_seen", chainType), mutilchainquery.CreateAddressFirstSeenTableFunc(chainType)},
+		{fmt.Sprintf("%snew_addresses", chainType), mutilchainquery.CreateNewAddressesTableFunc(chainType)},
+	}
+}
+
 func GetCreateTypeStatements() map[string]string {
 	result := make(map[string]string)
 	for _, chainType := range dbtypes.MutilchainList {
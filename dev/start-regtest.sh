@@ -0,0 +1,67 @@
+#!/usr/bin/env bash
+
+# Run this script from the "dev" folder, after start-simnet.sh has started the
+# Decred simnet harness, to:
+#  1. Start regtest bitcoind, litecoind and monerod nodes.
+#  2. Mine enough blocks on each of them to have spendable coinbases.
+#  3. Start dcrdata with the simnet Decred node and the regtest nodes.
+#
+# Set BTC_SIGNET=1 to run bitcoind on signet instead of regtest. Signet blocks
+# can't be mined locally, so the node syncs the public signet.
+#
+# When done testing, stop dcrdata with CTRL+C or SIGINT, then use
+# stop-regtest.sh to stop the nodes.
+
+set -e
+
+REGTEST_ROOT=~/dcrdataregtest
+RPCUSER=USER
+RPCPASS=PASS
+BTC_NET=regtest
+BTC_PORT=18443
+if [ -n "${BTC_SIGNET}" ]; then
+    BTC_NET=signet
+    BTC_PORT=38332
+fi
+
+rm -rf ${REGTEST_ROOT}
+mkdir -p ${REGTEST_ROOT}/btc ${REGTEST_ROOT}/ltc ${REGTEST_ROOT}/xmr
+
+echo "Starting ${BTC_NET} bitcoind, regtest litecoind and regtest monerod..."
+bitcoind -${BTC_NET} -daemon -datadir=${REGTEST_ROOT}/btc -txindex \
+    -rpcuser=${RPCUSER} -rpcpassword=${RPCPASS} -fallbackfee=0.0001
+litecoind -regtest -daemon -datadir=${REGTEST_ROOT}/ltc -txindex \
+    -rpcuser=${RPCUSER} -rpcpassword=${RPCPASS} -fallbackfee=0.0001
+monerod --regtest --offline --fixed-difficulty 1 --detach \
+    --data-dir ${REGTEST_ROOT}/xmr --pidfile ${REGTEST_ROOT}/xmr/monerod.pid
+
+sleep 5
+
+BTC_CLI="bitcoin-cli -${BTC_NET} -datadir=${REGTEST_ROOT}/btc -rpcuser=${RPCUSER} -rpcpassword=${RPCPASS}"
+LTC_CLI="litecoin-cli -regtest -datadir=${REGTEST_ROOT}/ltc -rpcuser=${RPCUSER} -rpcpassword=${RPCPASS}"
+
+echo "Mining regtest blocks..."
+if [ "${BTC_NET}" = "regtest" ]; then
+    ${BTC_CLI} createwallet harness > /dev/null
+    ${BTC_CLI} generatetoaddress 101 $(${BTC_CLI} getnewaddress) > /dev/null
+fi
+${LTC_CLI} createwallet harness > /dev/null
+${LTC_CLI} generatetoaddress 101 $(${LTC_CLI} getnewaddress) > /dev/null
+# Regtest uses mainnet addresses, so the coinbases go to the Monero donation
+# address.
+XMR_ADDR=44AFFq5kSiGBoZ4NMDwYtN18obc8AemS33DBLWs3H7otXft3XjrpDtQGv7SqSsaBYBb98uNbr2VBBEt7f2wfn3RVGQBEP3A
+curl -s http://127.0.0.1:18081/json_rpc -d '{"jsonrpc":"2.0","id":"0","method":"generateblocks",
+    "params":{"amount_of_blocks":100,"wallet_address":"'${XMR_ADDR}'"}}' \
+    -H 'Content-Type: application/json' > /dev/null
+
+echo "Use stop-regtest.sh to stop the nodes."
+
+pushd .. > /dev/null
+dcrdata -C ./dev/dcrdata-regtest.conf -g --dcrdserv=127.0.0.1:19201 \
+--dcrdcert=${HOME}/dcrdsimnet/beta/rpc.cert \
+--btcnet=${BTC_NET} --btcdserv=127.0.0.1:${BTC_PORT}
+popd > /dev/null
+
+echo " ***
+Don't forget to run ./stop-regtest.sh!
+ ***"
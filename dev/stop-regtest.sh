@@ -0,0 +1,17 @@
+#!/usr/bin/env bash
+
+REGTEST_ROOT=~/dcrdataregtest
+RPCUSER=USER
+RPCPASS=PASS
+
+echo "Stopping regtest nodes..."
+for net in regtest signet; do
+    bitcoin-cli -${net} -datadir=${REGTEST_ROOT}/btc -rpcuser=${RPCUSER} \
+        -rpcpassword=${RPCPASS} stop &> /dev/null
+done
+litecoin-cli -regtest -datadir=${REGTEST_ROOT}/ltc -rpcuser=${RPCUSER} \
+    -rpcpassword=${RPCPASS} stop &> /dev/null
+if [ -f ${REGTEST_ROOT}/xmr/monerod.pid ]; then
+    kill $(cat ${REGTEST_ROOT}/xmr/monerod.pid)
+fi
+echo "Stopped. The regtest chains are in ${REGTEST_ROOT}."
@@ -33,6 +33,10 @@ const (
 	DefaultRequestExpiry = "60m"
 
 	defaultDCRRatesPort = "7778"
+	// DCRRatesVersion is the version of the dcrrates subscriptions, from
+	// which the exchanges of every chain are listed in
+	// ExchangeSubscription.Chains.
+	DCRRatesVersion = 1
 
 	aggregatedOrderbookKey    = "aggregated"
 	aggregatedBTCOrderbookKey = "btc_aggregated"
@@ -63,6 +67,9 @@ type ExchangeBotConfig struct {
 	MasterBot      string
 	MasterCertFile string
 	BinanceAPIURL  string
+	// History is an optional store of the candlesticks, depth snapshots and
+	// prices of the exchanges.
+	History HistoryStore
 }
 
 // ExchangeBot monitors exchanges and processes updates. When an update is
@@ -113,6 +120,9 @@ type ExchangeBot struct {
 	indexChan    chan *IndexUpdate
 	client       *http.Client
 	config       *ExchangeBotConfig
+	history      *marketHistory
+	// quality is the data quality of each market, as of its last update.
+	quality map[string]*MarketQuality
 	// The failed flag is set when there are either no up-to-date Bitcoin-fiat
 	// exchanges or no up-to-date Decred exchanges. IsFailed is a getter for failed.
 	failed bool
@@ -479,6 +489,7 @@ func NewExchangeBot(config *ExchangeBotConfig) (*ExchangeBot, error) {
 		chartVersions:   make(map[string]int),
 		BtcIndex:        config.BtcIndex,
 		indexMap:        make(map[string]FiatIndices),
+		quality:         make(map[string]*MarketQuality),
 		currentState: ExchangeBotState{
 			BtcIndex:    config.BtcIndex,
 			Price:       0,
@@ -509,6 +520,10 @@ func NewExchangeBot(config *ExchangeBotConfig) (*ExchangeBot, error) {
 		failed:            false,
 	}
 
+	if config.History != nil {
+		bot.history = newMarketHistory(config.History)
+	}
+
 	if config.MasterBot != "" {
 		if config.MasterCertFile == "" {
 			return nil, fmt.Errorf("No TLS certificate path provided")
@@ -642,6 +657,10 @@ func (bot *ExchangeBot) Start(ctx context.Context, wg *sync.WaitGroup) {
 	tick := time.NewTimer(time.Second)
 	config := bot.config
 	reconnectionAttempt := 0
+	if bot.history != nil {
+		bot.loadHistory()
+		go bot.history.run(ctx)
+	}
 	if config.MasterBot != "" {
 		stream, err := bot.connectMasterBot(ctx, 0)
 		if err != nil {
@@ -683,26 +702,7 @@ func (bot *ExchangeBot) Start(ctx context.Context, wg *sync.WaitGroup) {
 						reconnectionAttempt = 0
 						continue
 					}
-					// Send the update through the Exchange so that appropriate attributes
-					// are set.
-					if IsDcrExchange(update.Token, update.Symbol) {
-						state := exchangeStateFromProto(update)
-						bot.Exchanges[update.Token].Update(state)
-					} else if IsBtcIndex(update.Token) {
-						bot.Exchanges[update.Token].UpdateIndices(update.GetIndices())
-					}
-					if IsLTCExchange(update.Token, update.Symbol) {
-						state := exchangeStateFromProto(update)
-						bot.LTCExchanges[update.Token].Update(state)
-					}
-					if IsBTCExchange(update.Token, update.Symbol) {
-						state := exchangeStateFromProto(update)
-						bot.BTCExchanges[update.Token].Update(state)
-					}
-					if IsXMRExchange(update.Token, update.Symbol) {
-						state := exchangeStateFromProto(update)
-						bot.XMRExchanges[update.Token].Update(state)
-					}
+					bot.masterUpdate(update)
 				}
 			}()
 		}
@@ -817,12 +817,19 @@ func (bot *ExchangeBot) connectMasterBot(ctx context.Context, delay time.Duratio
 	}
 	bot.masterConnection = conn
 	grpcClient := dcrrates.NewDCRRatesClient(conn)
+	// The version 0 lists are still set for servers that predate the chains.
 	stream, err := grpcClient.SubscribeExchanges(ctx, &dcrrates.ExchangeSubscription{
 		BtcIndex:     bot.BtcIndex,
 		Exchanges:    bot.subscribedExchanges(),
 		LtcExchanges: bot.subscribedMutilchainExchanges(TYPELTC),
 		BtcExchanges: bot.subscribedMutilchainExchanges(TYPEBTC),
-		XmrExchanges: bot.subscribedMutilchainExchanges(TYPEXMR),
+		Version:      DCRRatesVersion,
+		Chains: []*dcrrates.ChainExchanges{
+			{Chain: TYPEDCR, Exchanges: bot.subscribedExchanges()},
+			{Chain: TYPELTC, Exchanges: bot.subscribedMutilchainExchanges(TYPELTC)},
+			{Chain: TYPEBTC, Exchanges: bot.subscribedMutilchainExchanges(TYPEBTC)},
+			{Chain: TYPEXMR, Exchanges: bot.subscribedMutilchainExchanges(TYPEXMR)},
+		},
 	})
 	if err != nil {
 		return nil, err
@@ -830,6 +837,31 @@ func (bot *ExchangeBot) connectMasterBot(ctx context.Context, delay time.Duratio
 	return stream, nil
 }
 
+// masterUpdate sends an update from the DCRRates server through the Exchange
+// so that appropriate attributes are set. Servers before version 1 do not set
+// the chain of the update, so it is found from the market symbol.
+func (bot *ExchangeBot) masterUpdate(update *dcrrates.ExchangeRateUpdate) {
+	chain := update.GetChain()
+	if chain == "" {
+		chain = GetChainTypeFromSymbol(update.Symbol)
+	}
+	if chain == TYPEDCR {
+		xc := bot.Exchanges[update.Token]
+		if xc == nil {
+			return
+		}
+		if IsBtcIndex(update.Token) {
+			xc.UpdateIndices(update.GetIndices())
+		} else if IsDcrExchange(update.Token, update.Symbol) {
+			xc.Update(exchangeStateFromProto(update))
+		}
+		return
+	}
+	if xc := bot.getMutilchainExchanges(chain)[update.Token]; xc != nil {
+		xc.Update(exchangeStateFromProto(update))
+	}
+}
+
 func (bot *ExchangeBot) getMutilchainExchanges(chainType string) map[string]Exchange {
 	switch chainType {
 	case TYPEBTC:
@@ -926,12 +958,12 @@ func (bot *ExchangeBot) ConvertedState(code string) (*ExchangeBotState, error) {
 		}
 	}
 
-	dcrPrice, dcrChange, volume, low, high := bot.processState(bot.currentState.DcrBtc, true)
-	dcrBtcPrice, dcrBtcChange, dcrBtcvolume := bot.processDCRBTCState(bot.currentState.DcrBtc, true)
-	ltcPrice, ltcChange, ltcVolumn, ltcLow, ltcHigh := bot.processMutilchainState(bot.currentState.LtcUsd, bot.LTCExchanges, true)
-	btcExchangePrice, btcUsdChange, btcVolumn, btcLow, btcHigh := bot.processMutilchainState(bot.currentState.BtcUsd, bot.BTCExchanges, true)
-	xmrPrice, xmrChange, xmrVolumn, xmrLow, xmrHigh := bot.processMutilchainState(bot.currentState.XmrUsd, bot.XMRExchanges, true)
-	btcPrice, _, _, _, _ := bot.processState(fiatIndices, false)
+	dcrPrice, dcrChange, volume, low, high := bot.processState(bot.currentState.DcrBtc, bot.outliers(TYPEDCR), true)
+	dcrBtcPrice, dcrBtcChange, dcrBtcvolume := bot.processDCRBTCState(bot.currentState.DcrBtc, bot.outliers(DCRBTCMarket), true)
+	ltcPrice, ltcChange, ltcVolumn, ltcLow, ltcHigh := bot.processMutilchainState(bot.currentState.LtcUsd, bot.LTCExchanges, bot.outliers(TYPELTC), true)
+	btcExchangePrice, btcUsdChange, btcVolumn, btcLow, btcHigh := bot.processMutilchainState(bot.currentState.BtcUsd, bot.BTCExchanges, bot.outliers(TYPEBTC), true)
+	xmrPrice, xmrChange, xmrVolumn, xmrLow, xmrHigh := bot.processMutilchainState(bot.currentState.XmrUsd, bot.XMRExchanges, bot.outliers(TYPEXMR), true)
+	btcPrice, _, _, _, _ := bot.processState(fiatIndices, nil, false)
 	if dcrPrice == 0 || btcPrice == 0 {
 		bot.failed = true
 		return nil, fmt.Errorf("Unable to process price for currency %s", code)
@@ -1013,8 +1045,8 @@ func (bot *ExchangeBot) ConvertedRates(code string) (*ExchangeRates, error) {
 		}
 	}
 
-	dcrPrice, _, _, _, _ := bot.processState(bot.currentState.DcrBtc, true)
-	btcPrice, _, _, _, _ := bot.processState(fiatIndices, false)
+	dcrPrice, _, _, _, _ := bot.processState(bot.currentState.DcrBtc, bot.outliers(TYPEDCR), true)
+	btcPrice, _, _, _, _ := bot.processState(fiatIndices, nil, false)
 	if dcrPrice == 0 || btcPrice == 0 {
 		bot.failed = true
 		return nil, fmt.Errorf("Unable to process price for currency %s", code)
@@ -1101,7 +1133,7 @@ func (bot *ExchangeBot) cachedChartVersion(chartId string) int {
 	return cid
 }
 
-func (bot *ExchangeBot) processMutilchainState(states map[string]*ExchangeState, exchanges map[string]Exchange, volumeAveraged bool) (float64, float64, float64, float64, float64) {
+func (bot *ExchangeBot) processMutilchainState(states map[string]*ExchangeState, exchanges map[string]Exchange, outliers map[string]bool, volumeAveraged bool) (float64, float64, float64, float64, float64) {
 	var priceAccumulator, volSum, changeSum float64
 	var deletions []string
 	oldestValid := time.Now().Add(-bot.RequestExpiry)
@@ -1112,6 +1144,9 @@ func (bot *ExchangeBot) processMutilchainState(states map[string]*ExchangeState,
 			deletions = append(deletions, token)
 			continue
 		}
+		if outliers[token] {
+			continue
+		}
 		volume := 1.0
 		if volumeAveraged {
 			volume = state.BaseVolume
@@ -1140,7 +1175,8 @@ func (bot *ExchangeBot) processMutilchainState(states map[string]*ExchangeState,
 // processState is a helper function to process a slice of ExchangeState into
 // a price, and optionally a volume sum, and perform some cleanup along the way.
 // If volumeAveraged is false, all exchanges are given equal weight in the avg.
-func (bot *ExchangeBot) processState(states map[string]*ExchangeState, volumeAveraged bool) (float64, float64, float64, float64, float64) {
+// The outliers found by assessQuality are left out.
+func (bot *ExchangeBot) processState(states map[string]*ExchangeState, outliers map[string]bool, volumeAveraged bool) (float64, float64, float64, float64, float64) {
 	var priceAccumulator, volSum, changeSum float64
 	var deletions []string
 	oldestValid := time.Now().Add(-bot.RequestExpiry)
@@ -1154,6 +1190,9 @@ func (bot *ExchangeBot) processState(states map[string]*ExchangeState, volumeAve
 			deletions = append(deletions, token)
 			continue
 		}
+		if outliers[token] {
+			continue
+		}
 		volume := 1.0
 		if volumeAveraged {
 			volume = state.BaseVolume
@@ -1182,7 +1221,7 @@ func (bot *ExchangeBot) processState(states map[string]*ExchangeState, volumeAve
 	return priceAccumulator / volSum, changeSum / volSum, volSum, lowPrice, highPrice
 }
 
-func (bot *ExchangeBot) processDCRBTCState(states map[string]*ExchangeState, volumeAveraged bool) (float64, float64, float64) {
+func (bot *ExchangeBot) processDCRBTCState(states map[string]*ExchangeState, outliers map[string]bool, volumeAveraged bool) (float64, float64, float64) {
 	var priceAccumulator, volSum, changeSum float64
 	var deletions []string
 	oldestValid := time.Now().Add(-bot.RequestExpiry)
@@ -1194,6 +1233,9 @@ func (bot *ExchangeBot) processDCRBTCState(states map[string]*ExchangeState, vol
 			deletions = append(deletions, token)
 			continue
 		}
+		if outliers[token] {
+			continue
+		}
 		volume := 1.0
 		if volumeAveraged {
 			volume = state.BaseVolume
@@ -1240,6 +1282,9 @@ func (bot *ExchangeBot) updateExchange(update *ExchangeUpdate) error {
 		bot.currentState.DcrBtc[update.Token] = update.State
 		chainType = TYPEDCR
 	}
+	if bot.history != nil {
+		bot.history.record(chainType, update.Token, update.State)
+	}
 	return bot.updateMutilchainState(chainType)
 }
 
@@ -1266,7 +1311,8 @@ func (bot *ExchangeBot) updateIndices(update *IndexUpdate) error {
 func (bot *ExchangeBot) updateMutilchainState(chainType string) error {
 	switch chainType {
 	case TYPELTC:
-		ltcPrice, ltcChange, ltcVolumn, ltcLow, ltcHigh := bot.processMutilchainState(bot.currentState.LtcUsd, bot.LTCExchanges, true)
+		outliers := bot.assessQuality(TYPELTC, bot.currentState.LtcUsd, bot.LTCExchanges, nil)
+		ltcPrice, ltcChange, ltcVolumn, ltcLow, ltcHigh := bot.processMutilchainState(bot.currentState.LtcUsd, bot.LTCExchanges, outliers, true)
 		if ltcPrice == 0 {
 			bot.failed = true
 		} else {
@@ -1278,7 +1324,8 @@ func (bot *ExchangeBot) updateMutilchainState(chainType string) error {
 			bot.currentState.LTCPriceChange = ltcChange
 		}
 	case TYPEBTC:
-		btcPrice, btcChange, btcVolumn, btcLow, btcHigh := bot.processMutilchainState(bot.currentState.BtcUsd, bot.BTCExchanges, true)
+		outliers := bot.assessQuality(TYPEBTC, bot.currentState.BtcUsd, bot.BTCExchanges, nil)
+		btcPrice, btcChange, btcVolumn, btcLow, btcHigh := bot.processMutilchainState(bot.currentState.BtcUsd, bot.BTCExchanges, outliers, true)
 		if btcPrice == 0 {
 			bot.failed = true
 		} else {
@@ -1290,7 +1337,8 @@ func (bot *ExchangeBot) updateMutilchainState(chainType string) error {
 			bot.currentState.BTCPriceChange = btcChange
 		}
 	case TYPEXMR:
-		xmrPrice, xmrChange, xmrVolumn, xmrLow, xmrHigh := bot.processMutilchainState(bot.currentState.XmrUsd, bot.XMRExchanges, true)
+		outliers := bot.assessQuality(TYPEXMR, bot.currentState.XmrUsd, bot.XMRExchanges, nil)
+		xmrPrice, xmrChange, xmrVolumn, xmrLow, xmrHigh := bot.processMutilchainState(bot.currentState.XmrUsd, bot.XMRExchanges, outliers, true)
 		if xmrPrice == 0 {
 			bot.failed = true
 		} else {
@@ -1302,9 +1350,10 @@ func (bot *ExchangeBot) updateMutilchainState(chainType string) error {
 			bot.currentState.XMRPriceChange = xmrChange
 		}
 	default:
-		dcrPrice, dcrChange, volume, lowPrice, highPrice := bot.processState(bot.currentState.DcrBtc, true)
-		dcrBtcPrice, dcrBtcChange, dcrBtcVolume := bot.processDCRBTCState(bot.currentState.DcrBtc, true)
-		btcPrice, _, _, _, _ := bot.processState(bot.currentState.FiatIndices, false)
+		usdtOutliers, btcOutliers := bot.assessDCRQuality()
+		dcrPrice, dcrChange, volume, lowPrice, highPrice := bot.processState(bot.currentState.DcrBtc, usdtOutliers, true)
+		dcrBtcPrice, dcrBtcChange, dcrBtcVolume := bot.processDCRBTCState(bot.currentState.DcrBtc, btcOutliers, true)
+		btcPrice, _, _, _, _ := bot.processState(bot.currentState.FiatIndices, nil, false)
 		if dcrPrice == 0 || btcPrice == 0 {
 			bot.failed = true
 		} else {
@@ -1339,9 +1388,10 @@ func (bot *ExchangeBot) updateMutilchainState(chainType string) error {
 
 // Called from both updateIndices and updateExchange (under mutex lock).
 func (bot *ExchangeBot) updateState() error {
-	dcrPrice, dcrChange, volume, lowPrice, highPrice := bot.processState(bot.currentState.DcrBtc, true)
-	dcrBtcPrice, dcrBtcChange, dcrBtcVolume := bot.processDCRBTCState(bot.currentState.DcrBtc, true)
-	btcPrice, _, _, _, _ := bot.processState(bot.currentState.FiatIndices, false)
+	usdtOutliers, btcOutliers := bot.assessDCRQuality()
+	dcrPrice, dcrChange, volume, lowPrice, highPrice := bot.processState(bot.currentState.DcrBtc, usdtOutliers, true)
+	dcrBtcPrice, dcrBtcChange, dcrBtcVolume := bot.processDCRBTCState(bot.currentState.DcrBtc, btcOutliers, true)
+	btcPrice, _, _, _, _ := bot.processState(bot.currentState.FiatIndices, nil, false)
 	if dcrPrice == 0 || btcPrice == 0 {
 		bot.failed = true
 	} else {
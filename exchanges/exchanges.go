@@ -407,22 +407,17 @@ var BtcIndices = map[string]func(*http.Client, *BotChannels, string) (Exchange,
 
 // DcrExchanges maps tokens to constructors for DCR-BTC exchanges.
 var DcrExchanges = map[string]func(*http.Client, *BotChannels, string) (Exchange, error){
-	Binance:   NewBinance,
-	Mexc:      NewMexc,
-	Xt:        NewXt,
-	Pionex:    NewPionex,
-	Coinex:    NewCoinex,
-	BTCCoinex: NewBTCCoinex,
-	DragonEx:  NewDragonEx,
-	Huobi:     NewHuobi,
-	Poloniex:  NewPoloniex,
-	KuCoin:    NewKucoin,
-	DexDotDecred: NewDecredDEXConstructor(&DEXConfig{
-		Token:    DexDotDecred,
-		Host:     "dex.decred.org:7232",
-		Cert:     core.CertStore[dex.Mainnet]["dex.decred.org:7232"],
-		CertHost: "dex.decred.org",
-	}),
+	Binance:      NewBinance,
+	Mexc:         NewMexc,
+	Xt:           NewXt,
+	Pionex:       NewPionex,
+	Coinex:       NewCoinex,
+	BTCCoinex:    NewBTCCoinex,
+	DragonEx:     NewDragonEx,
+	Huobi:        NewHuobi,
+	Poloniex:     NewPoloniex,
+	KuCoin:       NewKucoin,
+	DexDotDecred: NewDecredDEXConstructor(dexDotDecredConfig),
 }
 
 var LTCExchanges = map[string]func(*http.Client, *BotChannels, string, string) (Exchange, error){
@@ -4259,6 +4254,21 @@ func GetSymbolFromChainType(chainType string) string {
 	}
 }
 
+// GetChainTypeFromSymbol is the chain type of a market symbol. The Decred
+// markets and the Bitcoin-fiat indices, which have no symbol, are TYPEDCR.
+func GetChainTypeFromSymbol(symbol string) string {
+	switch symbol {
+	case BTCSYMBOL:
+		return TYPEBTC
+	case LTCSYMBOL:
+		return TYPELTC
+	case XMRSYMBOL:
+		return TYPEXMR
+	default:
+		return TYPEDCR
+	}
+}
+
 // HuobiResponse models the common response fields in all API BittrexResponseResult
 type HuobiResponse struct {
 	Status string `json:"status"`
@@ -4471,7 +4481,7 @@ func NewPoloniex(client *http.Client, channels *BotChannels, _ string) (poloniex
 
 func MutilchainNewPoloniex(client *http.Client, channels *BotChannels, chainType string, _ string) (poloniex Exchange, err error) {
 	reqs := newRequests()
-	reqs.price, err = http.NewRequest(http.MethodGet, fmt.Sprintf(PoloniexMutilchainURLs.Price, strings.ToUpper(chainType)), nil)
+	reqs.price, err = http.NewRequest(http.MethodGet, PoloniexMutilchainURLs.Price, nil)
 	if err != nil {
 		return
 	}
@@ -5072,6 +5082,14 @@ func (poloniex *PoloniexExchange) Refresh() {
 // dexDotDecredMsgID is used as an atomic counter for msgjson.Message IDs.
 var dexDotDecredMsgID uint64 = 1
 
+// dexDotDecredConfig is the configuration of the dex.decred.org server.
+var dexDotDecredConfig = &DEXConfig{
+	Token:    DexDotDecred,
+	Host:     "dex.decred.org:7232",
+	Cert:     core.CertStore[dex.Mainnet]["dex.decred.org:7232"],
+	CertHost: "dex.decred.org",
+}
+
 // dexSubscription is the DEX request for the order book feed.
 var dexSubscription = &msgjson.OrderBookSubscription{
 	Base:  42, // BIP44 coin ID for Decred
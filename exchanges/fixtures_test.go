@@ -0,0 +1,757 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package exchanges
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/dex/msgjson"
+	"github.com/gorilla/websocket"
+)
+
+// Re-record the fixtures from the live exchange APIs with
+//
+//	go test -run TestExchangeFixtures -record
+//
+// and review the diff of testdata/fixtures before committing it.
+var recordFixtures = flag.Bool("record", false, "record the exchange fixtures from the live APIs")
+
+const (
+	fixtureDir = "testdata/fixtures"
+	// fixtureIndexChain is the chain of the fixtures of the BTC-fiat indices.
+	fixtureIndexChain = "index"
+	fixtureTimeout    = 5 * time.Second
+)
+
+// fixtureSocketAddrs are the websocket addresses that are pointed at the
+// stand-in server for the fixtures that have websocket frames.
+var fixtureSocketAddrs = map[string]*string{
+	Poloniex: &PoloniexURLs.Websocket,
+}
+
+// exchangeFixture is a recorded exchange session. HTTP maps the request URLs
+// to the payloads the exchange served, WS are the frames it pushed after the
+// client subscribed, DEX maps the requests to a DEX server to its results, and
+// Expect is the resulting ExchangeState.
+type exchangeFixture struct {
+	Token    string                     `json:"token"`
+	Chain    string                     `json:"chain"`
+	APIURL   string                     `json:"api_url,omitempty"`
+	Recorded string                     `json:"recorded,omitempty"`
+	HTTP     map[string]json.RawMessage `json:"http"`
+	WS       []json.RawMessage          `json:"ws,omitempty"`
+	DEX      map[string]json.RawMessage `json:"dex,omitempty"`
+	Expect   fixtureExpectation         `json:"expect"`
+}
+
+type fixtureExpectation struct {
+	Price        float64                          `json:"price,omitempty"`
+	Low          float64                          `json:"low,omitempty"`
+	High         float64                          `json:"high,omitempty"`
+	BaseVolume   float64                          `json:"base_volume,omitempty"`
+	Volume       float64                          `json:"volume,omitempty"`
+	Change       float64                          `json:"change,omitempty"`
+	Indices      FiatIndices                      `json:"indices,omitempty"`
+	Depth        *fixtureDepth                    `json:"depth,omitempty"`
+	Candlesticks map[candlestickKey]fixtureSticks `json:"candlesticks,omitempty"`
+}
+
+type fixtureDepth struct {
+	Bids    int     `json:"bids"`
+	Asks    int     `json:"asks"`
+	BestBid float64 `json:"best_bid,omitempty"`
+	BestAsk float64 `json:"best_ask,omitempty"`
+}
+
+type fixtureSticks struct {
+	Count      int     `json:"count"`
+	FirstStart int64   `json:"first_start,omitempty"`
+	LastStart  int64   `json:"last_start,omitempty"`
+	LastClose  float64 `json:"last_close,omitempty"`
+}
+
+func loadFixture(path string) (*exchangeFixture, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fixture := new(exchangeFixture)
+	if err = json.Unmarshal(b, fixture); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return fixture, nil
+}
+
+func (fixture *exchangeFixture) save(path string) error {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(fixture); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b.Bytes(), 0644)
+}
+
+// newExchange constructs the fixture's exchange with the constructor that the
+// ExchangeBot would use.
+func (fixture *exchangeFixture) newExchange(client *http.Client, channels *BotChannels) (Exchange, error) {
+	var constructor func(*http.Client, *BotChannels, string) (Exchange, error)
+	var mutilchainConstructor func(*http.Client, *BotChannels, string, string) (Exchange, error)
+	switch fixture.Chain {
+	case fixtureIndexChain:
+		constructor = BtcIndices[fixture.Token]
+	case TYPEDCR:
+		constructor = DcrExchanges[fixture.Token]
+	case TYPELTC:
+		mutilchainConstructor = LTCExchanges[fixture.Token]
+	case TYPEBTC:
+		mutilchainConstructor = BTCExchanges[fixture.Token]
+	case TYPEXMR:
+		mutilchainConstructor = XMRExchanges[fixture.Token]
+	default:
+		return nil, fmt.Errorf("unknown chain %q", fixture.Chain)
+	}
+	switch {
+	case constructor != nil:
+		return constructor(client, channels, fixture.APIURL)
+	case mutilchainConstructor != nil:
+		return mutilchainConstructor(client, channels, fixture.Chain, fixture.APIURL)
+	}
+	return nil, fmt.Errorf("no %s constructor for %s", fixture.Chain, fixture.Token)
+}
+
+// fixtureTransport is an http.RoundTripper that serves the payloads of a
+// fixture. Requests for URLs that are not in the fixture get a 404. In record
+// mode, the payloads of the URLs in the fixture are fetched from the live API
+// and stored in the fixture.
+type fixtureTransport struct {
+	mtx     sync.Mutex
+	fixture *exchangeFixture
+	record  bool
+	missed  []string
+}
+
+func (ft *fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	url := req.URL.String()
+	ft.mtx.Lock()
+	defer ft.mtx.Unlock()
+	payload, found := ft.fixture.HTTP[url]
+	if !found {
+		ft.missed = append(ft.missed, url)
+		return fixtureResponse(req, http.StatusNotFound, nil), nil
+	}
+	if ft.record {
+		var err error
+		if payload, err = fetchLive(req); err != nil {
+			return nil, err
+		}
+		ft.fixture.HTTP[url] = payload
+	}
+	return fixtureResponse(req, http.StatusOK, payload), nil
+}
+
+func (ft *fixtureTransport) missedURLs() []string {
+	ft.mtx.Lock()
+	defer ft.mtx.Unlock()
+	return ft.missed
+}
+
+func fixtureResponse(req *http.Request, status int, payload []byte) *http.Response {
+	return &http.Response{
+		Status:     http.StatusText(status),
+		StatusCode: status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(string(payload))),
+		Request:    req,
+	}
+}
+
+func fetchLive(req *http.Request) (json.RawMessage, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", req.URL, resp.Status)
+	}
+	if !json.Valid(b) {
+		return nil, fmt.Errorf("%s: response is not JSON", req.URL)
+	}
+	return b, nil
+}
+
+// fixtureSocket is a websocket server that waits for the client's
+// subscription, then pushes the frames of a fixture once it is released. In
+// record mode, it relays the subscription to the live address and pushes and
+// records the frames it gets back instead.
+type fixtureSocket struct {
+	*httptest.Server
+	release  chan struct{}
+	once     sync.Once
+	mtx      sync.Mutex
+	recorded []json.RawMessage
+	err      error
+}
+
+func newFixtureSocket(frames []json.RawMessage, liveAddr string, record bool) *fixtureSocket {
+	fs := &fixtureSocket{release: make(chan struct{})}
+	var upgrader websocket.Upgrader
+	fs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			fs.setErr(err)
+			return
+		}
+		defer conn.Close()
+		_, subscription, err := conn.ReadMessage()
+		if err != nil {
+			fs.setErr(fmt.Errorf("no subscription: %w", err))
+			return
+		}
+		<-fs.release
+		if record {
+			if frames, err = recordFrames(liveAddr, subscription, len(frames)); err != nil {
+				fs.setErr(err)
+				return
+			}
+			fs.mtx.Lock()
+			fs.recorded = frames
+			fs.mtx.Unlock()
+		}
+		for _, frame := range frames {
+			if err = conn.WriteMessage(websocket.TextMessage, frame); err != nil {
+				return
+			}
+		}
+		// Hold the connection until the client closes it.
+		for {
+			if _, _, err = conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	return fs
+}
+
+func (fs *fixtureSocket) address() string {
+	return "ws" + strings.TrimPrefix(fs.URL, "http")
+}
+
+func (fs *fixtureSocket) releaseFrames() {
+	fs.once.Do(func() { close(fs.release) })
+}
+
+func (fs *fixtureSocket) setErr(err error) {
+	fs.mtx.Lock()
+	defer fs.mtx.Unlock()
+	fs.err = err
+}
+
+func (fs *fixtureSocket) result() ([]json.RawMessage, error) {
+	fs.mtx.Lock()
+	defer fs.mtx.Unlock()
+	return fs.recorded, fs.err
+}
+
+func (fs *fixtureSocket) Close() {
+	fs.releaseFrames()
+	fs.Server.Close()
+}
+
+// recordFrames subscribes at the live address and returns the first n frames,
+// or just the first frame if n is zero.
+func recordFrames(addr string, subscription []byte, n int) ([]json.RawMessage, error) {
+	if n == 0 {
+		n = 1
+	}
+	conn, _, err := websocket.DefaultDialer.Dial(addr, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err = conn.WriteMessage(websocket.TextMessage, subscription); err != nil {
+		return nil, err
+	}
+	if err = conn.SetReadDeadline(time.Now().Add(30 * time.Second)); err != nil {
+		return nil, err
+	}
+	frames := make([]json.RawMessage, 0, n)
+	for len(frames) < n {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+		if !json.Valid(msg) {
+			return nil, fmt.Errorf("%s: frame is not JSON", addr)
+		}
+		frames = append(frames, msg)
+	}
+	return frames, nil
+}
+
+// dexFixtureServer is a TLS websocket stand-in for a DEX server. It answers
+// the requests of the DEX adapter with the results of a fixture, holding the
+// order book subscription until the candles of the bin sizes in the config
+// result are requested, so that every request is made before the update. In record mode, it relays the
+// requests to the live server and records the results instead.
+type dexFixtureServer struct {
+	*httptest.Server
+	mtx     sync.Mutex
+	results map[string]json.RawMessage
+	missed  []string
+	err     error
+}
+
+// dexRequestKey is the key of the result of a DEX request in a fixture: the
+// route, followed by the bin size for candles.
+func dexRequestKey(msg *msgjson.Message) string {
+	if msg.Route != msgjson.CandlesRoute {
+		return msg.Route
+	}
+	req := new(msgjson.CandlesRequest)
+	if err := msg.Unmarshal(req); err != nil {
+		return msg.Route
+	}
+	return msg.Route + " " + req.BinSize
+}
+
+func newDexFixtureServer(results map[string]json.RawMessage, record bool) *dexFixtureServer {
+	ds := &dexFixtureServer{results: results}
+	var upgrader websocket.Upgrader
+	ds.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			ds.setErr(err)
+			return
+		}
+		defer conn.Close()
+		answer := ds.respond
+		if record {
+			live, err := dialDEX(dexDotDecredConfig)
+			if err != nil {
+				ds.setErr(err)
+				return
+			}
+			defer live.Close()
+			answer = func(req *msgjson.Message) *msgjson.Message {
+				resp, err := ds.relay(live, req)
+				if err != nil {
+					ds.setErr(err)
+				}
+				return resp
+			}
+		}
+		var candles int
+		var subscription *msgjson.Message
+		for {
+			_, b, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			req, err := msgjson.DecodeMessage(b)
+			if err != nil || req.Type != msgjson.Request {
+				ds.setErr(fmt.Errorf("unexpected message %s", b))
+				return
+			}
+			if req.Route == msgjson.OrderBookRoute && candles < ds.binSizes() {
+				subscription = req
+				continue
+			}
+			resps := []*msgjson.Message{answer(req)}
+			if req.Route == msgjson.CandlesRoute {
+				candles++
+			}
+			if subscription != nil && candles == ds.binSizes() {
+				resps = append(resps, answer(subscription))
+				subscription = nil
+			}
+			for _, resp := range resps {
+				if resp == nil {
+					return
+				}
+				if err = conn.WriteJSON(resp); err != nil {
+					return
+				}
+			}
+		}
+	}))
+	return ds
+}
+
+// binSizes counts the bin sizes of the config result that the adapter
+// requests candles for.
+func (ds *dexFixtureServer) binSizes() int {
+	ds.mtx.Lock()
+	defer ds.mtx.Unlock()
+	cfg := new(msgjson.ConfigResult)
+	if err := json.Unmarshal(ds.results[msgjson.ConfigRoute], cfg); err != nil {
+		return 0
+	}
+	var n int
+	for _, binSize := range cfg.BinSizes {
+		dur, _ := time.ParseDuration(binSize)
+		for _, d := range candlestickDurations {
+			if d == dur {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// dialDEX connects to the websocket of a DEX server.
+func dialDEX(cfg *DEXConfig) (*websocket.Conn, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(cfg.Cert) {
+		return nil, fmt.Errorf("invalid certificate for %s", cfg.Host)
+	}
+	dialer := &websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+		TLSClientConfig:  &tls.Config{RootCAs: pool, ServerName: cfg.CertHost},
+	}
+	conn, _, err := dialer.Dial("wss://"+cfg.Host+"/ws", nil)
+	return conn, err
+}
+
+// config is the DEX configuration of the stand-in. The test certificate of
+// httptest is issued to example.com.
+func (ds *dexFixtureServer) config() *DEXConfig {
+	return &DEXConfig{
+		Token:    DexDotDecred,
+		Host:     strings.TrimPrefix(ds.URL, "https://"),
+		Cert:     pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ds.Certificate().Raw}),
+		CertHost: "example.com",
+	}
+}
+
+// respond answers a request with its result in the fixture, or with an error
+// if it is not in the fixture.
+func (ds *dexFixtureServer) respond(req *msgjson.Message) *msgjson.Message {
+	key := dexRequestKey(req)
+	ds.mtx.Lock()
+	defer ds.mtx.Unlock()
+	result, found := ds.results[key]
+	if !found {
+		ds.missed = append(ds.missed, key)
+		resp, _ := msgjson.NewResponse(req.ID, nil, msgjson.NewError(msgjson.RPCUnknownRoute, "%s is not in the fixture", key))
+		return resp
+	}
+	resp, _ := msgjson.NewResponse(req.ID, result, nil)
+	return resp
+}
+
+// relay sends a request to the live server and records the result of its
+// response. The notifications of the live server are skipped.
+func (ds *dexFixtureServer) relay(live *websocket.Conn, req *msgjson.Message) (*msgjson.Message, error) {
+	if err := live.WriteJSON(req); err != nil {
+		return nil, err
+	}
+	if err := live.SetReadDeadline(time.Now().Add(30 * time.Second)); err != nil {
+		return nil, err
+	}
+	for {
+		_, b, err := live.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+		resp, err := msgjson.DecodeMessage(b)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Type != msgjson.Response || resp.ID != req.ID {
+			continue
+		}
+		payload, err := resp.Response()
+		if err != nil {
+			return nil, err
+		}
+		if payload.Error != nil {
+			return nil, fmt.Errorf("%s: %v", dexRequestKey(req), payload.Error)
+		}
+		ds.mtx.Lock()
+		ds.results[dexRequestKey(req)] = payload.Result
+		ds.mtx.Unlock()
+		return resp, nil
+	}
+}
+
+func (ds *dexFixtureServer) setErr(err error) {
+	ds.mtx.Lock()
+	defer ds.mtx.Unlock()
+	ds.err = err
+}
+
+// result returns the requests that are not in the fixture and the error of
+// the stand-in, if any.
+func (ds *dexFixtureServer) result() ([]string, error) {
+	ds.mtx.Lock()
+	defer ds.mtx.Unlock()
+	return ds.missed, ds.err
+}
+
+// fixtureResult summarizes an update in the form of a fixtureExpectation.
+func fixtureResult(state *ExchangeState, indices FiatIndices) *fixtureExpectation {
+	if indices != nil {
+		return &fixtureExpectation{Indices: indices}
+	}
+	result := &fixtureExpectation{
+		Price:      state.Price,
+		Low:        state.Low,
+		High:       state.High,
+		BaseVolume: state.BaseVolume,
+		Volume:     state.Volume,
+		Change:     state.Change,
+	}
+	if depth := state.Depth; depth != nil {
+		result.Depth = &fixtureDepth{
+			Bids: len(depth.Bids),
+			Asks: len(depth.Asks),
+		}
+		if len(depth.Bids) > 0 {
+			result.Depth.BestBid = depth.Bids[0].Price
+		}
+		if len(depth.Asks) > 0 {
+			result.Depth.BestAsk = depth.Asks[0].Price
+		}
+	}
+	if len(state.Candlesticks) > 0 {
+		result.Candlesticks = make(map[candlestickKey]fixtureSticks, len(state.Candlesticks))
+		for bin, sticks := range state.Candlesticks {
+			summary := fixtureSticks{Count: len(sticks)}
+			if len(sticks) > 0 {
+				last := sticks[len(sticks)-1]
+				summary.FirstStart = sticks[0].Start.Unix()
+				summary.LastStart = last.Start.Unix()
+				summary.LastClose = last.Close
+			}
+			result.Candlesticks[bin] = summary
+		}
+	}
+	return result
+}
+
+func fixtureFloatsMatch(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+// compare lists the differences between the expected and the actual result.
+func (want *fixtureExpectation) compare(got *fixtureExpectation) []string {
+	var diffs []string
+	checkFloat := func(field string, w, g float64) {
+		if !fixtureFloatsMatch(w, g) {
+			diffs = append(diffs, fmt.Sprintf("%s: want %v, got %v", field, w, g))
+		}
+	}
+	checkFloat("price", want.Price, got.Price)
+	checkFloat("low", want.Low, got.Low)
+	checkFloat("high", want.High, got.High)
+	checkFloat("base_volume", want.BaseVolume, got.BaseVolume)
+	checkFloat("volume", want.Volume, got.Volume)
+	checkFloat("change", want.Change, got.Change)
+
+	for code, w := range want.Indices {
+		g, found := got.Indices[code]
+		if !found {
+			diffs = append(diffs, fmt.Sprintf("indices: missing %s", code))
+			continue
+		}
+		checkFloat("indices."+code, w, g)
+	}
+	for code := range got.Indices {
+		if _, found := want.Indices[code]; !found {
+			diffs = append(diffs, fmt.Sprintf("indices: unexpected %s", code))
+		}
+	}
+
+	switch {
+	case want.Depth == nil && got.Depth != nil:
+		diffs = append(diffs, "depth: unexpected depth data")
+	case want.Depth != nil && got.Depth == nil:
+		diffs = append(diffs, "depth: missing")
+	case want.Depth != nil:
+		if want.Depth.Bids != got.Depth.Bids || want.Depth.Asks != got.Depth.Asks {
+			diffs = append(diffs, fmt.Sprintf("depth: want %d bids and %d asks, got %d and %d",
+				want.Depth.Bids, want.Depth.Asks, got.Depth.Bids, got.Depth.Asks))
+		}
+		checkFloat("depth.best_bid", want.Depth.BestBid, got.Depth.BestBid)
+		checkFloat("depth.best_ask", want.Depth.BestAsk, got.Depth.BestAsk)
+	}
+
+	for bin, w := range want.Candlesticks {
+		g, found := got.Candlesticks[bin]
+		if !found {
+			diffs = append(diffs, fmt.Sprintf("candlesticks: missing bin %s", bin))
+			continue
+		}
+		if w.Count != g.Count || w.FirstStart != g.FirstStart || w.LastStart != g.LastStart {
+			diffs = append(diffs, fmt.Sprintf("candlesticks %s: want %d from %d to %d, got %d from %d to %d",
+				bin, w.Count, w.FirstStart, w.LastStart, g.Count, g.FirstStart, g.LastStart))
+		}
+		checkFloat(fmt.Sprintf("candlesticks %s last close", bin), w.LastClose, g.LastClose)
+	}
+	for bin := range got.Candlesticks {
+		if _, found := want.Candlesticks[bin]; !found {
+			diffs = append(diffs, fmt.Sprintf("candlesticks: unexpected bin %s", bin))
+		}
+	}
+	sort.Strings(diffs)
+	return diffs
+}
+
+// TestExchangeFixtures replays the recorded sessions in testdata/fixtures
+// through the exchange adapters, so that a change in the format of an
+// exchange's API shows up as a failing test.
+func TestExchangeFixtures(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join(fixtureDir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("no fixtures in %s", fixtureDir)
+	}
+	for _, path := range paths {
+		path := path
+		t.Run(strings.TrimSuffix(filepath.Base(path), ".json"), func(t *testing.T) {
+			testExchangeFixture(t, path)
+		})
+	}
+}
+
+func testExchangeFixture(t *testing.T, path string) {
+	fixture, err := loadFixture(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := &fixtureTransport{fixture: fixture, record: *recordFixtures}
+	channels := &BotChannels{
+		index:    make(chan *IndexUpdate, 2),
+		exchange: make(chan *ExchangeUpdate, 2),
+		done:     make(chan struct{}),
+	}
+
+	var dexServer *dexFixtureServer
+	if fixture.DEX != nil {
+		dexServer = newDexFixtureServer(fixture.DEX, *recordFixtures)
+		defer dexServer.Close()
+	}
+	var socket *fixtureSocket
+	if len(fixture.WS) > 0 || *recordFixtures && fixtureSocketAddrs[fixture.Token] != nil {
+		addr := fixtureSocketAddrs[fixture.Token]
+		if addr == nil {
+			t.Fatalf("%s has no websocket", fixture.Token)
+		}
+		socket = newFixtureSocket(fixture.WS, *addr, *recordFixtures)
+		defer socket.Close()
+		defer func(liveAddr string) { *addr = liveAddr }(*addr)
+		*addr = socket.address()
+	}
+	// Closing done disconnects the exchange's websocket.
+	defer close(channels.done)
+
+	var xc Exchange
+	if dexServer != nil {
+		xc, err = NewDecredDEXConstructor(dexServer.config())(&http.Client{Transport: transport}, channels, "")
+	} else {
+		xc, err = fixture.newExchange(&http.Client{Transport: transport}, channels)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	xc.Refresh()
+	if xc.IsFailed() {
+		t.Fatalf("%s refresh failed. Requests not in the fixture: %v", fixture.Token, transport.missedURLs())
+	}
+	if socket != nil {
+		socket.releaseFrames()
+	}
+
+	var state *ExchangeState
+	var indices FiatIndices
+	select {
+	case update := <-channels.exchange:
+		state = update.State
+	case update := <-channels.index:
+		indices = update.Indices
+	case <-time.After(fixtureTimeout):
+		if socket != nil {
+			if _, err = socket.result(); err != nil {
+				t.Fatalf("websocket stand-in: %v", err)
+			}
+		}
+		if dexServer != nil {
+			missed, err := dexServer.result()
+			if err != nil {
+				t.Fatalf("DEX stand-in: %v", err)
+			}
+			if len(missed) > 0 {
+				t.Fatalf("no update from %s. Requests not in the fixture: %v", fixture.Token, missed)
+			}
+		}
+		t.Fatalf("no update from %s", fixture.Token)
+	}
+	// Every request must be in the fixture, so that a candlestick bin cannot
+	// go untested by failing quietly.
+	for _, url := range transport.missedURLs() {
+		t.Errorf("%s: not in the fixture: %s", fixture.Token, url)
+	}
+	if dexServer != nil {
+		missed, err := dexServer.result()
+		if err != nil {
+			t.Fatalf("DEX stand-in: %v", err)
+		}
+		for _, key := range missed {
+			t.Errorf("%s: not in the fixture: %s", fixture.Token, key)
+		}
+	}
+	got := fixtureResult(state, indices)
+
+	if *recordFixtures {
+		if got.Price == 0 && len(got.Indices) == 0 {
+			t.Fatalf("%s update has no price. Not recording it.", fixture.Token)
+		}
+		if socket != nil {
+			frames, err := socket.result()
+			if err != nil {
+				t.Fatalf("websocket stand-in: %v", err)
+			}
+			fixture.WS = frames
+		}
+		fixture.Expect = *got
+		fixture.Recorded = time.Now().UTC().Format("2006-01-02")
+		if err = fixture.save(path); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	for _, diff := range fixture.Expect.compare(got) {
+		t.Errorf("%s: %s", fixture.Token, diff)
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package exchanges
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// depthSnapshotInterval is the minimum time between the stored depth
+	// snapshots of an exchange.
+	depthSnapshotInterval = 30 * time.Minute
+	// seedStickCount is the number of stored candlesticks of each bin that
+	// are loaded when the ExchangeBot starts.
+	seedStickCount = 500
+	// maxHistorySticks is the maximum number of bins in the date range of a
+	// HistorySticks request.
+	maxHistorySticks = 5000
+	// historyQueueSize is the number of writes that can wait for the
+	// HistoryStore before new ones are dropped.
+	historyQueueSize = 256
+)
+
+// HistoryStore persists the candlesticks, depth snapshots and prices of the
+// exchanges, so the market charts survive restarts and can be served for any
+// date range. The chain is one of TYPEDCR, TYPELTC, TYPEBTC or TYPEXMR.
+type HistoryStore interface {
+	StoreCandlesticks(chain, token, bin string, sticks Candlesticks) error
+	StoreDepth(chain, token string, depth *DepthData) error
+	StorePrice(chain, token string, state *BaseState) error
+	// Candlesticks are the stored candlesticks starting in [from, to].
+	Candlesticks(chain, token, bin string, from, to time.Time) (Candlesticks, error)
+	// LastCandlesticks are the n most recent stored candlesticks.
+	LastCandlesticks(chain, token, bin string, n int) (Candlesticks, error)
+}
+
+// marketHistory queues the exchange updates for a HistoryStore, remembering
+// what was stored so the candlesticks that are carried over from previous
+// updates are not stored again.
+type marketHistory struct {
+	store     HistoryStore
+	queue     chan func() error
+	mtx       sync.Mutex
+	lastStick map[string]Candlestick // chain/token/bin -> last stored stick
+	lastDepth map[string]int64       // chain/token -> last stored depth time
+	lastPrice map[string]int64       // chain/token -> last stored price stamp
+}
+
+func newMarketHistory(store HistoryStore) *marketHistory {
+	return &marketHistory{
+		store:     store,
+		queue:     make(chan func() error, historyQueueSize),
+		lastStick: make(map[string]Candlestick),
+		lastDepth: make(map[string]int64),
+		lastPrice: make(map[string]int64),
+	}
+}
+
+func historyKey(parts ...string) string {
+	key := parts[0]
+	for _, part := range parts[1:] {
+		key += "/" + part
+	}
+	return key
+}
+
+// run writes the queued updates until the context is cancelled.
+func (h *marketHistory) run(ctx context.Context) {
+	for {
+		select {
+		case write := <-h.queue:
+			if err := write(); err != nil {
+				log.Errorf("Failed to store exchange history: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (h *marketHistory) enqueue(write func() error) {
+	select {
+	case h.queue <- write:
+	default:
+		log.Warnf("Exchange history queue is full. Dropping an update.")
+	}
+}
+
+// record queues the new candlesticks, depth snapshot and price of an update.
+func (h *marketHistory) record(chain, token string, state *ExchangeState) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	for bin, sticks := range state.Candlesticks {
+		key := historyKey(chain, token, string(bin))
+		// The last stored candlestick is stored again if it changed, since it
+		// is updated until its bin closes.
+		last, found := h.lastStick[key]
+		i := 0
+		if found {
+			for i < len(sticks) && sticks[i].Start.Before(last.Start) {
+				i++
+			}
+			if i < len(sticks) && sticks[i] == last {
+				i++
+			}
+		}
+		if i == len(sticks) {
+			continue
+		}
+		newSticks := sticks[i:]
+		h.lastStick[key] = newSticks[len(newSticks)-1]
+		bin := string(bin)
+		h.enqueue(func() error {
+			return h.store.StoreCandlesticks(chain, token, bin, newSticks)
+		})
+	}
+	key := historyKey(chain, token)
+	lastDepth, found := h.lastDepth[key]
+	if depth := state.Depth; depth != nil && (!found || depth.Time-lastDepth >= int64(depthSnapshotInterval.Seconds())) {
+		h.lastDepth[key] = depth.Time
+		h.enqueue(func() error {
+			return h.store.StoreDepth(chain, token, depth)
+		})
+	}
+	if state.Price > 0 && state.Stamp != h.lastPrice[key] {
+		h.lastPrice[key] = state.Stamp
+		base := state.BaseState
+		h.enqueue(func() error {
+			return h.store.StorePrice(chain, token, &base)
+		})
+	}
+}
+
+// load returns the most recent stored candlesticks of an exchange for every
+// bin, and remembers them as stored.
+func (h *marketHistory) load(chain, token string) map[candlestickKey]Candlesticks {
+	candlesticks := make(map[candlestickKey]Candlesticks)
+	for bin := range candlestickDurations {
+		sticks, err := h.store.LastCandlesticks(chain, token, string(bin), seedStickCount)
+		if err != nil {
+			log.Errorf("Failed to load %s candlesticks for %s %s: %v", bin, chain, token, err)
+			continue
+		}
+		if len(sticks) == 0 {
+			continue
+		}
+		candlesticks[bin] = sticks
+		h.mtx.Lock()
+		h.lastStick[historyKey(chain, token, string(bin))] = sticks[len(sticks)-1]
+		h.mtx.Unlock()
+	}
+	return candlesticks
+}
+
+// stickSeeder is satisfied by the exchanges that embed CommonExchange.
+type stickSeeder interface {
+	seedCandlesticks(map[candlestickKey]Candlesticks)
+}
+
+// seedCandlesticks adds the candlesticks of the bins that the exchange has
+// none of, before its first refresh. The exchange will only request the bins
+// whose stored candlesticks are out of date, and the candlesticks it gets
+// overlap the stored ones to fill the gap.
+func (xc *CommonExchange) seedCandlesticks(candlesticks map[candlestickKey]Candlesticks) {
+	xc.mtx.Lock()
+	defer xc.mtx.Unlock()
+	state := &ExchangeState{
+		BaseState: xc.currentState.BaseState,
+		Depth:     xc.currentState.Depth,
+	}
+	state.stealSticks(xc.currentState)
+	state.stealSticks(&ExchangeState{Candlesticks: candlesticks})
+	xc.currentState = state
+}
+
+// loadHistory seeds the exchanges with their stored candlesticks.
+func (bot *ExchangeBot) loadHistory() {
+	chains := map[string]map[string]Exchange{
+		TYPEDCR: bot.DcrBtcExchanges,
+		TYPELTC: bot.LTCExchanges,
+		TYPEBTC: bot.BTCExchanges,
+		TYPEXMR: bot.XMRExchanges,
+	}
+	var count int
+	for chain, xcs := range chains {
+		for token, xc := range xcs {
+			seeder, ok := xc.(stickSeeder)
+			if !ok {
+				continue
+			}
+			candlesticks := bot.history.load(chain, token)
+			if len(candlesticks) == 0 {
+				continue
+			}
+			seeder.seedCandlesticks(candlesticks)
+			count++
+		}
+	}
+	log.Infof("Loaded the stored candlesticks of %d exchanges", count)
+}
+
+// HistorySticks returns the stored candlesticks of an exchange and bin width
+// that start in [from, to], including the ones that are not stored yet. A zero
+// to is now, and a zero from is seedStickCount bins before to.
+func (bot *ExchangeBot) HistorySticks(chainType, token, rawBin string, from, to time.Time) ([]byte, error) {
+	if bot.history == nil {
+		return nil, fmt.Errorf("no exchange history store")
+	}
+	bin := candlestickKey(rawBin)
+	if _, found := candlestickDurations[bin]; !found {
+		return nil, fmt.Errorf("unknown bin %s", rawBin)
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-seedStickCount * bin.duration())
+	}
+	if to.Before(from) || to.Sub(from)/bin.duration() > maxHistorySticks {
+		return nil, fmt.Errorf("invalid range for bin %s: %s to %s", rawBin, from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+	sticks, err := bot.history.store.Candlesticks(chainType, token, rawBin, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	bot.mtx.RLock()
+	defer bot.mtx.RUnlock()
+	var price float64
+	var states map[string]*ExchangeState
+	if chainType == TYPEDCR {
+		price, states = bot.currentState.Price, bot.currentState.DcrBtc
+	} else {
+		price, states = bot.currentState.GetMutilchainPrice(chainType), bot.currentState.GetMutilchainExchangeState(chainType)
+	}
+	if state := states[token]; state != nil {
+		for _, stick := range state.Candlesticks[bin] {
+			if stick.Start.Before(from) || stick.Start.After(to) {
+				continue
+			}
+			switch last := sticks.time(); {
+			case len(sticks) > 0 && stick.Start.Equal(last):
+				sticks[len(sticks)-1] = stick
+			case stick.Start.After(last):
+				sticks = append(sticks, stick)
+			}
+		}
+	}
+	if len(sticks) == 0 {
+		return nil, fmt.Errorf("No candlesticks for %s and bin %s", token, rawBin)
+	}
+	return bot.encodeJSON(&candlestickResponse{
+		BtcIndex:   bot.BtcIndex,
+		Price:      price,
+		Sticks:     sticks,
+		Expiration: sticks.time().Add(2 * bin.duration()).Unix(),
+	})
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package exchanges
+
+import (
+	"testing"
+	"time"
+)
+
+type historyStoreStub struct {
+	sticks Candlesticks
+	depths int
+	prices int
+}
+
+func (s *historyStoreStub) StoreCandlesticks(chain, token, bin string, sticks Candlesticks) error {
+	s.sticks = append(s.sticks, sticks...)
+	return nil
+}
+
+func (s *historyStoreStub) StoreDepth(chain, token string, depth *DepthData) error {
+	s.depths++
+	return nil
+}
+
+func (s *historyStoreStub) StorePrice(chain, token string, state *BaseState) error {
+	s.prices++
+	return nil
+}
+
+func (s *historyStoreStub) Candlesticks(chain, token, bin string, from, to time.Time) (Candlesticks, error) {
+	return nil, nil
+}
+
+func (s *historyStoreStub) LastCandlesticks(chain, token, bin string, n int) (Candlesticks, error) {
+	return nil, nil
+}
+
+func TestMarketHistoryRecord(t *testing.T) {
+	store := new(historyStoreStub)
+	h := newMarketHistory(store)
+	flush := func() {
+		for {
+			select {
+			case write := <-h.queue:
+				if err := write(); err != nil {
+					t.Fatal(err)
+				}
+			default:
+				return
+			}
+		}
+	}
+
+	start := time.Unix(1700000000, 0)
+	sticks := Candlesticks{
+		{Start: start, Close: 1},
+		{Start: start.Add(time.Hour), Close: 2},
+	}
+	state := &ExchangeState{
+		BaseState:    BaseState{Price: 2, Stamp: 100},
+		Depth:        &DepthData{Time: 100},
+		Candlesticks: map[candlestickKey]Candlesticks{hourKey: sticks},
+	}
+	h.record(TYPEXMR, "binance", state)
+	flush()
+	if len(store.sticks) != 2 || store.depths != 1 || store.prices != 1 {
+		t.Fatalf("stored %d sticks, %d depths and %d prices, expecting 2, 1 and 1",
+			len(store.sticks), store.depths, store.prices)
+	}
+
+	// The candlesticks carried over from the previous update and a depth
+	// snapshot that is too recent are not stored again.
+	state.Stamp, state.Depth = 200, &DepthData{Time: 200}
+	h.record(TYPEXMR, "binance", state)
+	flush()
+	if len(store.sticks) != 2 || store.depths != 1 || store.prices != 2 {
+		t.Fatalf("stored %d sticks, %d depths and %d prices, expecting 2, 1 and 2",
+			len(store.sticks), store.depths, store.prices)
+	}
+
+	// The last candlestick is stored again when it changes.
+	state.Candlesticks[hourKey] = Candlesticks{
+		{Start: start.Add(time.Hour), Close: 3},
+		{Start: start.Add(2 * time.Hour), Close: 4},
+	}
+	h.record(TYPEXMR, "binance", state)
+	flush()
+	if len(store.sticks) != 4 || store.sticks[2].Close != 3 {
+		t.Fatalf("unexpected stored candlesticks %+v", store.sticks)
+	}
+}
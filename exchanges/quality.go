@@ -0,0 +1,371 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package exchanges
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// DCRBTCMarket is the quality report key of the DCR-BTC exchanges. The
+	// other markets use their chain type, e.g. TYPEDCR for DCR-USDT.
+	DCRBTCMarket = "dcr_btc"
+	// maxPriceDeviation is the relative difference from the VWAP of the other
+	// exchanges above which the price of an exchange is an outlier.
+	maxPriceDeviation = 0.05
+	// maxVolumeDepthRatio is how many times the median ratio of 24h volume to
+	// order book depth an exchange can report before its volume is suspicious.
+	maxVolumeDepthRatio = 10
+	// depthBand is the distance from the mid-gap, as a fraction of it, within
+	// which the order book depth is measured.
+	depthBand = 0.02
+	// minQualityExchanges is the fewest exchanges that are needed to tell
+	// which of them are outliers.
+	minQualityExchanges = 3
+	// staleRefreshes is the number of missed refreshes after which an
+	// exchange is stale.
+	staleRefreshes = 2
+)
+
+// ExchangeQuality is the data quality of the market of one exchange.
+type ExchangeQuality struct {
+	Token      string  `json:"token"`
+	Price      float64 `json:"price"`
+	BaseVolume float64 `json:"base_volume"`
+	// Deviation is the relative difference between the price and the VWAP of
+	// the exchanges that are not excluded.
+	Deviation float64 `json:"deviation"`
+	// VolumeDepth is the ratio of the 24h volume to the order book depth
+	// within depthBand of the mid-gap, or zero without depth data.
+	VolumeDepth float64 `json:"volume_depth,omitempty"`
+	LastUpdate  int64   `json:"last_update"`
+	// Stale is set when the exchange has missed staleRefreshes refreshes.
+	// Stale exchanges stay in the index until their data expires.
+	Stale            bool `json:"stale"`
+	Diverging        bool `json:"diverging"`
+	SuspiciousVolume bool `json:"suspicious_volume"`
+	// Excluded is whether the exchange is left out of the index price, which
+	// happens when it is diverging or its volume is suspicious.
+	Excluded bool `json:"excluded"`
+}
+
+// Flagged is whether the exchange has any data quality problem.
+func (q *ExchangeQuality) Flagged() bool {
+	return q.Stale || q.Diverging || q.SuspiciousVolume
+}
+
+// VenueQuote is the price of an order on one exchange.
+type VenueQuote struct {
+	Token string  `json:"token"`
+	Price float64 `json:"price"`
+}
+
+// MarketQuality is the data quality of the exchanges of a market, and the
+// spread between the best bid and the best ask across them.
+type MarketQuality struct {
+	Market string  `json:"market"`
+	VWAP   float64 `json:"vwap"`
+	// BestBid and BestAsk are the top of the aggregated order book.
+	BestBid *VenueQuote `json:"best_bid,omitempty"`
+	BestAsk *VenueQuote `json:"best_ask,omitempty"`
+	// Spread is the relative difference between the best ask and the best
+	// bid. It is negative when the books of two exchanges are crossed, which
+	// is an arbitrage opportunity.
+	Spread    float64            `json:"spread"`
+	Arbitrage bool               `json:"arbitrage"`
+	Exchanges []*ExchangeQuality `json:"exchanges"`
+	Stamp     int64              `json:"stamp"`
+}
+
+// Flagged is the number of exchanges with a data quality problem.
+func (q *MarketQuality) Flagged() int {
+	var n int
+	for _, xc := range q.Exchanges {
+		if xc.Flagged() {
+			n++
+		}
+	}
+	return n
+}
+
+// Excluded are the tokens of the exchanges that are left out of the index.
+func (q *MarketQuality) Excluded() []string {
+	var tokens []string
+	for _, xc := range q.Exchanges {
+		if xc.Excluded {
+			tokens = append(tokens, xc.Token)
+		}
+	}
+	return tokens
+}
+
+// QualityReport is the data quality of every market.
+type QualityReport struct {
+	Markets map[string]*MarketQuality `json:"markets"`
+}
+
+// midGapDepth is the quantity of the orders within depthBand of the mid-gap
+// of the order book.
+func midGapDepth(depth *DepthData) float64 {
+	if depth == nil || len(depth.Bids) == 0 || len(depth.Asks) == 0 {
+		return 0
+	}
+	bestBid, bestAsk := 0.0, math.MaxFloat64
+	for _, pt := range depth.Bids {
+		bestBid = math.Max(bestBid, pt.Price)
+	}
+	for _, pt := range depth.Asks {
+		bestAsk = math.Min(bestAsk, pt.Price)
+	}
+	midGap := (bestBid + bestAsk) / 2
+	var qty float64
+	for _, pt := range depth.Bids {
+		if pt.Price >= midGap*(1-depthBand) {
+			qty += pt.Quantity
+		}
+	}
+	for _, pt := range depth.Asks {
+		if pt.Price <= midGap*(1+depthBand) {
+			qty += pt.Quantity
+		}
+	}
+	return qty
+}
+
+// vwap is the volume-weighted average price of the exchanges that are not
+// excluded, other than skip. If none of them report volume, the prices are
+// given equal weight.
+func vwap(xcs []*ExchangeQuality, skip *ExchangeQuality) float64 {
+	var priceSum, volSum, plainSum float64
+	var count int
+	for _, xc := range xcs {
+		if xc.Excluded || xc == skip {
+			continue
+		}
+		priceSum += xc.Price * xc.BaseVolume
+		volSum += xc.BaseVolume
+		plainSum += xc.Price
+		count++
+	}
+	if volSum > 0 {
+		return priceSum / volSum
+	}
+	if count == 0 {
+		return 0
+	}
+	return plainSum / float64(count)
+}
+
+// flagOutliers flags and excludes the exchanges whose volume is suspicious
+// and then, one at a time, the exchange whose price is furthest from the VWAP
+// of the others, as long as that is more than maxPriceDeviation. It returns
+// the VWAP of the exchanges that remain.
+func flagOutliers(xcs []*ExchangeQuality) float64 {
+	// Wash trading inflates the volume without adding to the depth of the
+	// book, so an exchange reporting many times the volume per unit of depth
+	// of the typical exchange is suspicious.
+	ratios := make([]float64, 0, len(xcs))
+	for _, xc := range xcs {
+		if xc.VolumeDepth > 0 {
+			ratios = append(ratios, xc.VolumeDepth)
+		}
+	}
+	if len(ratios) >= minQualityExchanges {
+		sort.Float64s(ratios)
+		median := ratios[len(ratios)/2]
+		if len(ratios)%2 == 0 {
+			median = (median + ratios[len(ratios)/2-1]) / 2
+		}
+		for _, xc := range xcs {
+			if xc.VolumeDepth > maxVolumeDepthRatio*median {
+				xc.SuspiciousVolume = true
+				xc.Excluded = true
+			}
+		}
+	}
+
+	// Measuring each exchange against the others keeps a diverging exchange
+	// with most of the volume from pulling the VWAP to its own price.
+	for {
+		var included int
+		for _, xc := range xcs {
+			if !xc.Excluded {
+				included++
+			}
+		}
+		if included < minQualityExchanges {
+			break
+		}
+		var worst *ExchangeQuality
+		var worstDev float64
+		for _, xc := range xcs {
+			if xc.Excluded {
+				continue
+			}
+			ref := vwap(xcs, xc)
+			if ref == 0 {
+				continue
+			}
+			if dev := math.Abs(xc.Price/ref - 1); dev > worstDev {
+				worst, worstDev = xc, dev
+			}
+		}
+		if worst == nil || worstDev <= maxPriceDeviation {
+			break
+		}
+		worst.Diverging = true
+		worst.Excluded = true
+	}
+
+	price := vwap(xcs, nil)
+	if price > 0 {
+		for _, xc := range xcs {
+			xc.Deviation = xc.Price/price - 1
+		}
+	}
+	return price
+}
+
+// assessQuality flags the stale, diverging and suspicious exchanges of a
+// market, stores the MarketQuality and returns the tokens of the exchanges to
+// leave out of the index. Exchanges are skipped if include is non-nil and
+// false for their token, or if their data has expired. This must be called
+// with the mtx locked.
+func (bot *ExchangeBot) assessQuality(market string, states map[string]*ExchangeState, exchanges map[string]Exchange, include func(string) bool) map[string]bool {
+	now := time.Now()
+	oldestValid := now.Add(-bot.RequestExpiry)
+	xcs := make([]*ExchangeQuality, 0, len(states))
+	for token, state := range states {
+		if include != nil && !include(token) {
+			continue
+		}
+		xc := exchanges[token]
+		if xc == nil || xc.LastUpdate().Before(oldestValid) || state.Price <= 0 {
+			continue
+		}
+		q := &ExchangeQuality{
+			Token:      token,
+			Price:      state.Price,
+			BaseVolume: state.BaseVolume,
+			LastUpdate: xc.LastUpdate().Unix(),
+			Stale:      now.Sub(xc.LastUpdate()) > staleRefreshes*bot.DataExpiry,
+		}
+		if depth := midGapDepth(state.Depth); depth > 0 {
+			q.VolumeDepth = state.BaseVolume / depth
+		}
+		xcs = append(xcs, q)
+	}
+	sort.Slice(xcs, func(i, j int) bool { return xcs[i].Token < xcs[j].Token })
+
+	quality := &MarketQuality{
+		Market:    market,
+		VWAP:      flagOutliers(xcs),
+		Exchanges: xcs,
+		Stamp:     now.Unix(),
+	}
+	bot.quality[market] = quality
+	for _, token := range quality.Excluded() {
+		log.Debugf("Excluding %s from the %s index", token, market)
+	}
+	return bot.outliers(market)
+}
+
+// outliers are the tokens of the exchanges that were left out of the index of
+// a market at its last update. This must be called with the mtx locked.
+func (bot *ExchangeBot) outliers(market string) map[string]bool {
+	quality := bot.quality[market]
+	if quality == nil {
+		return nil
+	}
+	outliers := make(map[string]bool)
+	for _, token := range quality.Excluded() {
+		outliers[token] = true
+	}
+	return outliers
+}
+
+// assessDCRQuality assesses the DCR-USDT and DCR-BTC markets. This must be
+// called with the mtx locked.
+func (bot *ExchangeBot) assessDCRQuality() (usdtOutliers, btcOutliers map[string]bool) {
+	usdtOutliers = bot.assessQuality(TYPEDCR, bot.currentState.DcrBtc, bot.Exchanges, func(token string) bool {
+		return !IsDCRBTCExchange(token)
+	})
+	btcOutliers = bot.assessQuality(DCRBTCMarket, bot.currentState.DcrBtc, bot.Exchanges, IsDCRBTCExchange)
+	return
+}
+
+// topOfBook is the best bid and the best ask of an aggregated order book, and
+// the exchanges that offer them.
+func topOfBook(book *aggregateOrderbook) (bid, ask *VenueQuote) {
+	venue := func(pts []agBookPt) *VenueQuote {
+		if len(pts) == 0 {
+			return nil
+		}
+		for i, vol := range pts[0].Volumes {
+			if vol > 0 && i < len(book.Tokens) {
+				return &VenueQuote{Token: book.Tokens[i], Price: pts[0].Price}
+			}
+		}
+		return nil
+	}
+	if book == nil {
+		return nil, nil
+	}
+	return venue(book.Data.Bids), venue(book.Data.Asks)
+}
+
+// marketOrderbook is the aggregated order book of a market.
+func (bot *ExchangeBot) marketOrderbook(market string) *aggregateOrderbook {
+	switch market {
+	case TYPEDCR:
+		return bot.aggOrderbookHandler(true)
+	case DCRBTCMarket:
+		return bot.aggOrderbookHandler(false)
+	default:
+		return bot.aggMutilchainOrderbook(market)
+	}
+}
+
+// MarketQuality is the data quality of a market, with the cross-exchange
+// spread from its aggregated order book. The market is a chain type or
+// DCRBTCMarket. It is nil if the market has not been assessed.
+func (bot *ExchangeBot) MarketQuality(market string) *MarketQuality {
+	bot.mtx.RLock()
+	stored := bot.quality[market]
+	bot.mtx.RUnlock()
+	if stored == nil {
+		return nil
+	}
+	// The stored MarketQuality is shared, so the spread is set on a copy.
+	quality := *stored
+	quality.BestBid, quality.BestAsk = topOfBook(bot.marketOrderbook(market))
+	if quality.BestBid != nil && quality.BestAsk != nil {
+		midGap := (quality.BestBid.Price + quality.BestAsk.Price) / 2
+		quality.Spread = (quality.BestAsk.Price - quality.BestBid.Price) / midGap
+		quality.Arbitrage = quality.Spread < 0 && quality.BestBid.Token != quality.BestAsk.Token
+	}
+	return &quality
+}
+
+// Quality is the data quality of every market that has been assessed.
+func (bot *ExchangeBot) Quality() *QualityReport {
+	bot.mtx.RLock()
+	markets := make([]string, 0, len(bot.quality))
+	for market := range bot.quality {
+		markets = append(markets, market)
+	}
+	bot.mtx.RUnlock()
+	report := &QualityReport{Markets: make(map[string]*MarketQuality, len(markets))}
+	for _, market := range markets {
+		report.Markets[market] = bot.MarketQuality(market)
+	}
+	return report
+}
+
+// QualityBytes is the JSON-encoded QualityReport.
+func (bot *ExchangeBot) QualityBytes() ([]byte, error) {
+	return bot.encodeJSON(bot.Quality())
+}
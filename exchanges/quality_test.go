@@ -0,0 +1,128 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package exchanges
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFlagOutliers(t *testing.T) {
+	xcs := []*ExchangeQuality{
+		{Token: "a", Price: 100, BaseVolume: 10, VolumeDepth: 2},
+		{Token: "b", Price: 101, BaseVolume: 10, VolumeDepth: 3},
+		{Token: "c", Price: 99, BaseVolume: 10, VolumeDepth: 2.5},
+		// Most of the volume, but far from the others.
+		{Token: "diverging", Price: 120, BaseVolume: 100},
+		// Close to the others, but trading far more than its book supports.
+		{Token: "wash", Price: 100, BaseVolume: 1000, VolumeDepth: 500},
+	}
+	price := flagOutliers(xcs)
+	if math.Abs(price-100) > 1e-9 {
+		t.Fatalf("wrong VWAP %f, expected 100", price)
+	}
+	for _, xc := range xcs {
+		wantDiverging, wantWash := xc.Token == "diverging", xc.Token == "wash"
+		if xc.Diverging != wantDiverging || xc.SuspiciousVolume != wantWash || xc.Excluded != (wantDiverging || wantWash) {
+			t.Errorf("%s: diverging %t, suspicious volume %t, excluded %t", xc.Token, xc.Diverging, xc.SuspiciousVolume, xc.Excluded)
+		}
+	}
+	if math.Abs(xcs[3].Deviation-0.2) > 1e-9 {
+		t.Errorf("wrong deviation %f, expected 0.2", xcs[3].Deviation)
+	}
+
+	// Two exchanges are not enough to tell which one is wrong.
+	pair := []*ExchangeQuality{
+		{Token: "a", Price: 100, BaseVolume: 1},
+		{Token: "b", Price: 200, BaseVolume: 1},
+	}
+	flagOutliers(pair)
+	for _, xc := range pair {
+		if xc.Excluded {
+			t.Errorf("%s excluded from a market of two", xc.Token)
+		}
+	}
+}
+
+func TestMidGapDepth(t *testing.T) {
+	depth := &DepthData{
+		Bids: []DepthPoint{{Price: 99, Quantity: 1}, {Price: 97, Quantity: 2}, {Price: 90, Quantity: 4}},
+		Asks: []DepthPoint{{Price: 101, Quantity: 1}, {Price: 102, Quantity: 2}, {Price: 110, Quantity: 4}},
+	}
+	if qty := midGapDepth(depth); qty != 4 {
+		t.Errorf("wrong depth %f, expected 4", qty)
+	}
+	if qty := midGapDepth(&DepthData{Bids: depth.Bids}); qty != 0 {
+		t.Errorf("one-sided book has depth %f", qty)
+	}
+}
+
+func TestMarketQuality(t *testing.T) {
+	now := time.Now()
+	xc := func(token string, lastUpdate time.Time) Exchange {
+		return &BinanceExchange{CommonExchange: &CommonExchange{token: token, lastUpdate: lastUpdate}}
+	}
+	state := func(price, volume float64, bid, ask float64) *ExchangeState {
+		return &ExchangeState{
+			BaseState: BaseState{Price: price, BaseVolume: volume},
+			Depth: &DepthData{
+				Time: now.Unix(),
+				Bids: []DepthPoint{{Price: bid, Quantity: 1}},
+				Asks: []DepthPoint{{Price: ask, Quantity: 1}},
+			},
+		}
+	}
+	bot := &ExchangeBot{
+		XMRExchanges: map[string]Exchange{
+			"a":     xc("a", now),
+			"b":     xc("b", now),
+			"stale": xc("stale", now.Add(-20*time.Minute)),
+			"far":   xc("far", now),
+		},
+		DataExpiry:    5 * time.Minute,
+		RequestExpiry: time.Hour,
+		quality:       make(map[string]*MarketQuality),
+		config:        &ExchangeBotConfig{},
+		currentState: ExchangeBotState{
+			XmrUsd: map[string]*ExchangeState{
+				"a":     state(100, 10, 99.5, 100.5),
+				"b":     state(101, 10, 100.8, 101.2),
+				"stale": state(100, 10, 99, 101),
+				"far":   state(150, 1, 149, 151),
+			},
+		},
+	}
+	if err := bot.updateMutilchainState(TYPEXMR); err != nil {
+		t.Fatal(err)
+	}
+	if bot.currentState.XMRPrice > 101 {
+		t.Errorf("index price %f includes the diverging exchange", bot.currentState.XMRPrice)
+	}
+
+	quality := bot.MarketQuality(TYPEXMR)
+	if quality == nil {
+		t.Fatal("no quality report for xmr")
+	}
+	flags := make(map[string]*ExchangeQuality)
+	for _, xcq := range quality.Exchanges {
+		flags[xcq.Token] = xcq
+	}
+	if !flags["stale"].Stale || flags["stale"].Excluded {
+		t.Errorf("stale exchange should be flagged but not excluded: %+v", flags["stale"])
+	}
+	if !flags["far"].Diverging || !flags["far"].Excluded {
+		t.Errorf("diverging exchange should be excluded: %+v", flags["far"])
+	}
+	if quality.Flagged() != 2 {
+		t.Errorf("%d exchanges flagged, expected 2", quality.Flagged())
+	}
+	// The bid of far crosses the asks of every other exchange.
+	if quality.BestBid == nil || quality.BestBid.Token != "far" || quality.BestAsk == nil || quality.BestAsk.Token != "a" {
+		t.Fatalf("wrong top of book %+v %+v", quality.BestBid, quality.BestAsk)
+	}
+	if !quality.Arbitrage || quality.Spread >= 0 {
+		t.Errorf("crossed books should be an arbitrage, spread %f", quality.Spread)
+	}
+}
@@ -4,6 +4,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
 	"testing"
@@ -43,6 +44,74 @@ func TestSendStateList(t *testing.T) {
 	}
 }
 
+func TestSubscribedChains(t *testing.T) {
+	// Version 0 clients have no Monero exchanges.
+	chains := subscribedChains(&dcrrates.ExchangeSubscription{
+		Exchanges:    []string{"binance", "coinex"},
+		LtcExchanges: []string{"binance"},
+		BtcExchanges: []string{"kraken"},
+	})
+	if len(chains[exchanges.TYPEDCR]) != 2 || len(chains[exchanges.TYPELTC]) != 1 ||
+		len(chains[exchanges.TYPEBTC]) != 1 || len(chains[exchanges.TYPEXMR]) != 0 {
+		t.Fatalf("unexpected version 0 chains %v", chains)
+	}
+
+	// Version 1 clients list every chain, and the legacy fields are ignored.
+	chains = subscribedChains(&dcrrates.ExchangeSubscription{
+		Exchanges: []string{"binance"},
+		Version:   1,
+		Chains: []*dcrrates.ChainExchanges{
+			{Chain: exchanges.TYPEDCR, Exchanges: []string{"coinex"}},
+			{Chain: exchanges.TYPEXMR, Exchanges: []string{"kraken", "mexc"}},
+		},
+	})
+	if len(chains[exchanges.TYPEDCR]) != 1 || chains[exchanges.TYPEDCR][0] != "coinex" ||
+		len(chains[exchanges.TYPEXMR]) != 2 {
+		t.Fatalf("unexpected version 1 chains %v", chains)
+	}
+
+	if chains = subscribedChains(nil); len(chains[exchanges.TYPEDCR]) != 0 {
+		t.Fatalf("unexpected chains of a nil subscription %v", chains)
+	}
+}
+
+type streamStub struct {
+	sent []*dcrrates.ExchangeRateUpdate
+}
+
+func (s *streamStub) Send(update *dcrrates.ExchangeRateUpdate) error {
+	s.sent = append(s.sent, update)
+	return nil
+}
+
+func (s *streamStub) Context() context.Context {
+	return context.Background()
+}
+
+func TestSendExchangeUpdateChain(t *testing.T) {
+	stream := new(streamStub)
+	client := NewRateClient(stream, map[string][]string{
+		exchanges.TYPEDCR: {"binance"},
+		exchanges.TYPEXMR: {"kraken"},
+	})
+	updates := []*exchanges.ExchangeUpdate{
+		{Token: "binance", State: &exchanges.ExchangeState{BaseState: exchanges.BaseState{Symbol: exchanges.DCRBTCSYMBOL}}},
+		{Token: "binance", State: &exchanges.ExchangeState{BaseState: exchanges.BaseState{Symbol: exchanges.LTCSYMBOL}}},
+		{Token: "kraken", State: &exchanges.ExchangeState{BaseState: exchanges.BaseState{Symbol: exchanges.XMRSYMBOL}}},
+	}
+	for _, update := range updates {
+		if err := client.SendExchangeUpdate(makeExchangeRateUpdate(update)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(stream.sent) != 2 {
+		t.Fatalf("sent %d updates, expecting 2", len(stream.sent))
+	}
+	if stream.sent[0].Chain != exchanges.TYPEDCR || stream.sent[1].Chain != exchanges.TYPEXMR {
+		t.Fatalf("unexpected chains %s and %s", stream.sent[0].Chain, stream.sent[1].Chain)
+	}
+}
+
 type certWriterStub struct {
 	lengths map[string]int
 }
@@ -149,6 +149,7 @@ func main() {
 				sendUpdate(&dcrrates.ExchangeRateUpdate{
 					Token:   update.Token,
 					Indices: update.Indices,
+					Chain:   exchanges.TYPEDCR,
 				})
 			case <-xcSignals.Quit:
 				log.Infof("ExchangeBot Quit signal received.")
@@ -53,6 +53,9 @@ type GRPCStream interface {
 // subscription is received.
 func sendStateList(client RateClient, states map[string]*exchanges.ExchangeState) (err error) {
 	for token, state := range states {
+		if state == nil {
+			continue
+		}
 		err = client.SendExchangeUpdate(makeExchangeRateUpdate(&exchanges.ExchangeUpdate{
 			Token: token,
 			State: state,
@@ -93,17 +96,25 @@ func (server *RateServer) ReallySubscribeExchanges(hello *dcrrates.ExchangeSubsc
 		log.Infof("Client has connected from %s", clientAddr)
 	}
 
-	// Send Decred exchanges.
+	// Send the exchanges of every chain. The ExchangeBot has no state until its
+	// first update.
 	state := server.xcBot.State()
-	if err != nil {
-		err = sendStateList(client, state.DcrBtc)
-		return err
+	if state == nil {
+		state = new(exchanges.ExchangeBotState)
+	}
+	for _, states := range []map[string]*exchanges.ExchangeState{state.DcrBtc, state.LtcUsd, state.BtcUsd, state.XmrUsd} {
+		if err = sendStateList(client, states); err != nil {
+			log.Errorf("Error encountered while sending exchange states to client at %s: %v", clientAddr, err)
+			server.deleteClient(sid)
+			return err
+		}
 	}
 	// Send Bitcoin-fiat indices.
 	for token := range state.FiatIndices {
 		err = client.SendExchangeUpdate(&dcrrates.ExchangeRateUpdate{
 			Token:   token,
 			Indices: server.xcBot.Indices(token),
+			Chain:   exchanges.TYPEDCR,
 		})
 		if err != nil {
 			log.Errorf("Error encountered while sending fiat indices to client at %s: %v", clientAddr, err)
@@ -125,7 +136,7 @@ func (server *RateServer) ReallySubscribeExchanges(hello *dcrrates.ExchangeSubsc
 func (server *RateServer) addClient(stream GRPCStream, hello *dcrrates.ExchangeSubscription) (RateClient, StreamID) {
 	server.clientLock.Lock()
 	defer server.clientLock.Unlock()
-	client := NewRateClient(stream, hello.GetExchanges())
+	client := NewRateClient(stream, subscribedChains(hello))
 	streamCounter++
 	server.clients[streamCounter] = client
 	return client, streamCounter
@@ -138,16 +149,34 @@ func (server *RateServer) deleteClient(sid StreamID) {
 	delete(server.clients, sid)
 }
 
-// A rateClient stores a client's gRPC stream and a list of exchange tokens
-// to which they are subscribed. rateClient satisfies the RateClient interface.
+// subscribedChains are the exchange tokens of a subscription by chain. Version
+// 0 clients list the Decred, Litecoin and Bitcoin exchanges in their own
+// fields, and have no Monero exchanges.
+func subscribedChains(hello *dcrrates.ExchangeSubscription) map[string][]string {
+	chains := make(map[string][]string)
+	if hello.GetVersion() == 0 {
+		chains[exchanges.TYPEDCR] = hello.GetExchanges()
+		chains[exchanges.TYPELTC] = hello.GetLtcExchanges()
+		chains[exchanges.TYPEBTC] = hello.GetBtcExchanges()
+		return chains
+	}
+	for _, chain := range hello.GetChains() {
+		chains[chain.GetChain()] = append(chains[chain.GetChain()], chain.GetExchanges()...)
+	}
+	return chains
+}
+
+// A rateClient stores a client's gRPC stream and the exchange tokens to which
+// they are subscribed for each chain. rateClient satisfies the RateClient
+// interface.
 type rateClient struct {
 	stream    GRPCStream
-	exchanges []string
+	exchanges map[string][]string
 }
 
 // NewRateClient is a constructor for rate client. It returns the RateClient
 // interface rather than rateClient itself.
-func NewRateClient(stream GRPCStream, exchanges []string) RateClient {
+func NewRateClient(stream GRPCStream, exchanges map[string][]string) RateClient {
 	return &rateClient{
 		stream:    stream,
 		exchanges: exchanges,
@@ -165,6 +194,7 @@ func makeExchangeRateUpdate(update *exchanges.ExchangeUpdate) *dcrrates.Exchange
 		Volume:     state.Volume,
 		Change:     state.Change,
 		Stamp:      state.Stamp,
+		Chain:      exchanges.GetChainTypeFromSymbol(state.Symbol),
 	}
 	if state.Candlesticks != nil {
 		protoUpdate.Candlesticks = make([]*dcrrates.ExchangeRateUpdate_Candlesticks, 0, len(state.Candlesticks))
@@ -211,10 +241,12 @@ func makeExchangeRateUpdate(update *exchanges.ExchangeUpdate) *dcrrates.Exchange
 	return protoUpdate
 }
 
-// SendExchangeUpdate sends the update if the client is subscribed to the exchange.
+// SendExchangeUpdate sends the update if the client is subscribed to the
+// exchange on the chain of the update.
 func (client *rateClient) SendExchangeUpdate(update *dcrrates.ExchangeRateUpdate) (err error) {
-	for i := range client.exchanges {
-		if client.exchanges[i] == update.Token {
+	tokens := client.exchanges[update.Chain]
+	for i := range tokens {
+		if tokens[i] == update.Token {
 			err = client.stream.Send(update)
 			return
 		}
@@ -28,11 +28,15 @@ type ExchangeSubscription struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	BtcIndex     string   `protobuf:"bytes,1,opt,name=btcIndex,proto3" json:"btcIndex,omitempty"`
+	BtcIndex string `protobuf:"bytes,1,opt,name=btcIndex,proto3" json:"btcIndex,omitempty"`
+	// The Decred, Litecoin and Bitcoin exchanges of version 0 clients. They are
+	// ignored from version 1, which lists the exchanges of every chain in chains.
 	Exchanges    []string `protobuf:"bytes,2,rep,name=exchanges,proto3" json:"exchanges,omitempty"`
 	LtcExchanges []string `protobuf:"bytes,3,rep,name=ltcExchanges,proto3" json:"ltcExchanges,omitempty"`
 	BtcExchanges []string `protobuf:"bytes,4,rep,name=btcExchanges,proto3" json:"btcExchanges,omitempty"`
-	XmrExchanges []string `protobuf:"bytes,4,rep,name=xmrExchanges,proto3" json:"xmrExchanges,omitempty"`
+	// The version of the subscription. Version 0 clients predate it.
+	Version uint32            `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	Chains  []*ChainExchanges `protobuf:"bytes,6,rep,name=chains,proto3" json:"chains,omitempty"`
 }
 
 func (x *ExchangeSubscription) Reset() {
@@ -95,9 +99,73 @@ func (x *ExchangeSubscription) GetBtcExchanges() []string {
 	return nil
 }
 
-func (x *ExchangeSubscription) GetXmrExchanges() []string {
+func (x *ExchangeSubscription) GetVersion() uint32 {
 	if x != nil {
-		return x.XmrExchanges
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ExchangeSubscription) GetChains() []*ChainExchanges {
+	if x != nil {
+		return x.Chains
+	}
+	return nil
+}
+
+// ChainExchanges are the exchanges subscribed to for a chain, such as "dcr" or
+// "xmr". The Bitcoin-fiat indices are subscribed to with the "dcr" exchanges.
+type ChainExchanges struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Chain     string   `protobuf:"bytes,1,opt,name=chain,proto3" json:"chain,omitempty"`
+	Exchanges []string `protobuf:"bytes,2,rep,name=exchanges,proto3" json:"exchanges,omitempty"`
+}
+
+func (x *ChainExchanges) Reset() {
+	*x = ChainExchanges{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dcrrates_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChainExchanges) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChainExchanges) ProtoMessage() {}
+
+func (x *ChainExchanges) ProtoReflect() protoreflect.Message {
+	mi := &file_dcrrates_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChainExchanges.ProtoReflect.Descriptor instead.
+func (*ChainExchanges) Descriptor() ([]byte, []int) {
+	return file_dcrrates_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ChainExchanges) GetChain() string {
+	if x != nil {
+		return x.Chain
+	}
+	return ""
+}
+
+func (x *ChainExchanges) GetExchanges() []string {
+	if x != nil {
+		return x.Exchanges
 	}
 	return nil
 }
@@ -117,12 +185,14 @@ type ExchangeRateUpdate struct {
 	Indices      map[string]float64                 `protobuf:"bytes,8,rep,name=indices,proto3" json:"indices,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
 	Depth        *ExchangeRateUpdate_DepthData      `protobuf:"bytes,9,opt,name=depth,proto3" json:"depth,omitempty"`
 	Candlesticks []*ExchangeRateUpdate_Candlesticks `protobuf:"bytes,10,rep,name=candlesticks,proto3" json:"candlesticks,omitempty"`
+	// The chain of the exchange. Servers before version 1 leave it empty.
+	Chain string `protobuf:"bytes,11,opt,name=chain,proto3" json:"chain,omitempty"`
 }
 
 func (x *ExchangeRateUpdate) Reset() {
 	*x = ExchangeRateUpdate{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_dcrrates_proto_msgTypes[1]
+		mi := &file_dcrrates_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -135,7 +205,7 @@ func (x *ExchangeRateUpdate) String() string {
 func (*ExchangeRateUpdate) ProtoMessage() {}
 
 func (x *ExchangeRateUpdate) ProtoReflect() protoreflect.Message {
-	mi := &file_dcrrates_proto_msgTypes[1]
+	mi := &file_dcrrates_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -148,7 +218,7 @@ func (x *ExchangeRateUpdate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExchangeRateUpdate.ProtoReflect.Descriptor instead.
 func (*ExchangeRateUpdate) Descriptor() ([]byte, []int) {
-	return file_dcrrates_proto_rawDescGZIP(), []int{1}
+	return file_dcrrates_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *ExchangeRateUpdate) GetToken() string {
@@ -221,6 +291,13 @@ func (x *ExchangeRateUpdate) GetCandlesticks() []*ExchangeRateUpdate_Candlestick
 	return nil
 }
 
+func (x *ExchangeRateUpdate) GetChain() string {
+	if x != nil {
+		return x.Chain
+	}
+	return ""
+}
+
 type ExchangeRateUpdate_DepthPoint struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -233,7 +310,7 @@ type ExchangeRateUpdate_DepthPoint struct {
 func (x *ExchangeRateUpdate_DepthPoint) Reset() {
 	*x = ExchangeRateUpdate_DepthPoint{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_dcrrates_proto_msgTypes[3]
+		mi := &file_dcrrates_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -246,7 +323,7 @@ func (x *ExchangeRateUpdate_DepthPoint) String() string {
 func (*ExchangeRateUpdate_DepthPoint) ProtoMessage() {}
 
 func (x *ExchangeRateUpdate_DepthPoint) ProtoReflect() protoreflect.Message {
-	mi := &file_dcrrates_proto_msgTypes[3]
+	mi := &file_dcrrates_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -259,7 +336,7 @@ func (x *ExchangeRateUpdate_DepthPoint) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExchangeRateUpdate_DepthPoint.ProtoReflect.Descriptor instead.
 func (*ExchangeRateUpdate_DepthPoint) Descriptor() ([]byte, []int) {
-	return file_dcrrates_proto_rawDescGZIP(), []int{1, 1}
+	return file_dcrrates_proto_rawDescGZIP(), []int{2, 1}
 }
 
 func (x *ExchangeRateUpdate_DepthPoint) GetQuantity() float64 {
@@ -289,7 +366,7 @@ type ExchangeRateUpdate_DepthData struct {
 func (x *ExchangeRateUpdate_DepthData) Reset() {
 	*x = ExchangeRateUpdate_DepthData{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_dcrrates_proto_msgTypes[4]
+		mi := &file_dcrrates_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -302,7 +379,7 @@ func (x *ExchangeRateUpdate_DepthData) String() string {
 func (*ExchangeRateUpdate_DepthData) ProtoMessage() {}
 
 func (x *ExchangeRateUpdate_DepthData) ProtoReflect() protoreflect.Message {
-	mi := &file_dcrrates_proto_msgTypes[4]
+	mi := &file_dcrrates_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -315,7 +392,7 @@ func (x *ExchangeRateUpdate_DepthData) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExchangeRateUpdate_DepthData.ProtoReflect.Descriptor instead.
 func (*ExchangeRateUpdate_DepthData) Descriptor() ([]byte, []int) {
-	return file_dcrrates_proto_rawDescGZIP(), []int{1, 2}
+	return file_dcrrates_proto_rawDescGZIP(), []int{2, 2}
 }
 
 func (x *ExchangeRateUpdate_DepthData) GetTime() int64 {
@@ -355,7 +432,7 @@ type ExchangeRateUpdate_Candlestick struct {
 func (x *ExchangeRateUpdate_Candlestick) Reset() {
 	*x = ExchangeRateUpdate_Candlestick{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_dcrrates_proto_msgTypes[5]
+		mi := &file_dcrrates_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -368,7 +445,7 @@ func (x *ExchangeRateUpdate_Candlestick) String() string {
 func (*ExchangeRateUpdate_Candlestick) ProtoMessage() {}
 
 func (x *ExchangeRateUpdate_Candlestick) ProtoReflect() protoreflect.Message {
-	mi := &file_dcrrates_proto_msgTypes[5]
+	mi := &file_dcrrates_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -381,7 +458,7 @@ func (x *ExchangeRateUpdate_Candlestick) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExchangeRateUpdate_Candlestick.ProtoReflect.Descriptor instead.
 func (*ExchangeRateUpdate_Candlestick) Descriptor() ([]byte, []int) {
-	return file_dcrrates_proto_rawDescGZIP(), []int{1, 3}
+	return file_dcrrates_proto_rawDescGZIP(), []int{2, 3}
 }
 
 func (x *ExchangeRateUpdate_Candlestick) GetHigh() float64 {
@@ -438,7 +515,7 @@ type ExchangeRateUpdate_Candlesticks struct {
 func (x *ExchangeRateUpdate_Candlesticks) Reset() {
 	*x = ExchangeRateUpdate_Candlesticks{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_dcrrates_proto_msgTypes[6]
+		mi := &file_dcrrates_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -451,7 +528,7 @@ func (x *ExchangeRateUpdate_Candlesticks) String() string {
 func (*ExchangeRateUpdate_Candlesticks) ProtoMessage() {}
 
 func (x *ExchangeRateUpdate_Candlesticks) ProtoReflect() protoreflect.Message {
-	mi := &file_dcrrates_proto_msgTypes[6]
+	mi := &file_dcrrates_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -464,7 +541,7 @@ func (x *ExchangeRateUpdate_Candlesticks) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExchangeRateUpdate_Candlesticks.ProtoReflect.Descriptor instead.
 func (*ExchangeRateUpdate_Candlesticks) Descriptor() ([]byte, []int) {
-	return file_dcrrates_proto_rawDescGZIP(), []int{1, 4}
+	return file_dcrrates_proto_rawDescGZIP(), []int{2, 4}
 }
 
 func (x *ExchangeRateUpdate_Candlesticks) GetBin() string {
@@ -485,7 +562,7 @@ var File_dcrrates_proto protoreflect.FileDescriptor
 
 var file_dcrrates_proto_rawDesc = []byte{
 	0x0a, 0x0e, 0x64, 0x63, 0x72, 0x72, 0x61, 0x74, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x12, 0x08, 0x64, 0x63, 0x72, 0x72, 0x61, 0x74, 0x65, 0x73, 0x22, 0x98, 0x01, 0x0a, 0x14, 0x45,
+	0x12, 0x08, 0x64, 0x63, 0x72, 0x72, 0x61, 0x74, 0x65, 0x73, 0x22, 0xe4, 0x01, 0x0a, 0x14, 0x45,
 	0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74,
 	0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x62, 0x74, 0x63, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x18,
 	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x62, 0x74, 0x63, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12,
@@ -495,72 +572,83 @@ var file_dcrrates_proto_rawDesc = []byte{
 	0x03, 0x28, 0x09, 0x52, 0x0c, 0x6c, 0x74, 0x63, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65,
 	0x73, 0x12, 0x22, 0x0a, 0x0c, 0x62, 0x74, 0x63, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65,
 	0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x62, 0x74, 0x63, 0x45, 0x78, 0x63, 0x68,
-	0x61, 0x6e, 0x67, 0x65, 0x73, 0x22, 0x9a, 0x07, 0x0a, 0x12, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e,
-	0x67, 0x65, 0x52, 0x61, 0x74, 0x65, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x14, 0x0a, 0x05,
-	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b,
-	0x65, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72,
-	0x69, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65,
-	0x12, 0x1e, 0x0a, 0x0a, 0x62, 0x61, 0x73, 0x65, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x62, 0x61, 0x73, 0x65, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65,
-	0x12, 0x16, 0x0a, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01,
-	0x52, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x68, 0x61, 0x6e,
-	0x67, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65,
-	0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52,
-	0x05, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x43, 0x0a, 0x07, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x65,
-	0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x64, 0x63, 0x72, 0x72, 0x61, 0x74,
-	0x65, 0x73, 0x2e, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x61, 0x74, 0x65, 0x55,
-	0x70, 0x64, 0x61, 0x74, 0x65, 0x2e, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x45, 0x6e, 0x74,
-	0x72, 0x79, 0x52, 0x07, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x12, 0x3c, 0x0a, 0x05, 0x64,
-	0x65, 0x70, 0x74, 0x68, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x64, 0x63, 0x72,
-	0x72, 0x61, 0x74, 0x65, 0x73, 0x2e, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x61,
-	0x74, 0x65, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x2e, 0x44, 0x65, 0x70, 0x74, 0x68, 0x44, 0x61,
-	0x74, 0x61, 0x52, 0x05, 0x64, 0x65, 0x70, 0x74, 0x68, 0x12, 0x4d, 0x0a, 0x0c, 0x63, 0x61, 0x6e,
-	0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32,
-	0x29, 0x2e, 0x64, 0x63, 0x72, 0x72, 0x61, 0x74, 0x65, 0x73, 0x2e, 0x45, 0x78, 0x63, 0x68, 0x61,
-	0x6e, 0x67, 0x65, 0x52, 0x61, 0x74, 0x65, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x2e, 0x43, 0x61,
-	0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x52, 0x0c, 0x63, 0x61, 0x6e, 0x64,
-	0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x1a, 0x3a, 0x0a, 0x0c, 0x49, 0x6e, 0x64, 0x69,
-	0x63, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
-	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
-	0x3a, 0x02, 0x38, 0x01, 0x1a, 0x3e, 0x0a, 0x0a, 0x44, 0x65, 0x70, 0x74, 0x68, 0x50, 0x6f, 0x69,
-	0x6e, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x71, 0x75, 0x61, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x71, 0x75, 0x61, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x14,
-	0x0a, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x70,
-	0x72, 0x69, 0x63, 0x65, 0x1a, 0x99, 0x01, 0x0a, 0x09, 0x44, 0x65, 0x70, 0x74, 0x68, 0x44, 0x61,
-	0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03,
-	0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x3b, 0x0a, 0x04, 0x62, 0x69, 0x64, 0x73, 0x18, 0x02,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x64, 0x63, 0x72, 0x72, 0x61, 0x74, 0x65, 0x73, 0x2e,
-	0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x61, 0x74, 0x65, 0x55, 0x70, 0x64, 0x61,
-	0x74, 0x65, 0x2e, 0x44, 0x65, 0x70, 0x74, 0x68, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x04, 0x62,
-	0x69, 0x64, 0x73, 0x12, 0x3b, 0x0a, 0x04, 0x61, 0x73, 0x6b, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x27, 0x2e, 0x64, 0x63, 0x72, 0x72, 0x61, 0x74, 0x65, 0x73, 0x2e, 0x45, 0x78, 0x63,
+	0x61, 0x6e, 0x67, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x30, 0x0a, 0x06, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x18, 0x2e, 0x64, 0x63, 0x72, 0x72, 0x61, 0x74, 0x65, 0x73, 0x2e, 0x43, 0x68, 0x61, 0x69, 0x6e,
+	0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x52, 0x06, 0x63, 0x68, 0x61, 0x69, 0x6e,
+	0x73, 0x22, 0x44, 0x0a, 0x0e, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x65, 0x78, 0x63,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x65, 0x78,
+	0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x22, 0xb0, 0x07, 0x0a, 0x12, 0x45, 0x78, 0x63, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x52, 0x61, 0x74, 0x65, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x14,
+	0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74,
+	0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x14, 0x0a, 0x05,
+	0x70, 0x72, 0x69, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x70, 0x72, 0x69,
+	0x63, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x62, 0x61, 0x73, 0x65, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x62, 0x61, 0x73, 0x65, 0x56, 0x6f, 0x6c, 0x75,
+	0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x63, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x05, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x43, 0x0a, 0x07, 0x69, 0x6e, 0x64, 0x69,
+	0x63, 0x65, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x64, 0x63, 0x72, 0x72,
+	0x61, 0x74, 0x65, 0x73, 0x2e, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x61, 0x74,
+	0x65, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x2e, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x12, 0x3c, 0x0a,
+	0x05, 0x64, 0x65, 0x70, 0x74, 0x68, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x64,
+	0x63, 0x72, 0x72, 0x61, 0x74, 0x65, 0x73, 0x2e, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x52, 0x61, 0x74, 0x65, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x2e, 0x44, 0x65, 0x70, 0x74, 0x68,
+	0x44, 0x61, 0x74, 0x61, 0x52, 0x05, 0x64, 0x65, 0x70, 0x74, 0x68, 0x12, 0x4d, 0x0a, 0x0c, 0x63,
+	0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x18, 0x0a, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x29, 0x2e, 0x64, 0x63, 0x72, 0x72, 0x61, 0x74, 0x65, 0x73, 0x2e, 0x45, 0x78, 0x63,
 	0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x61, 0x74, 0x65, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x2e,
-	0x44, 0x65, 0x70, 0x74, 0x68, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x04, 0x61, 0x73, 0x6b, 0x73,
-	0x1a, 0x8b, 0x01, 0x0a, 0x0b, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b,
-	0x12, 0x12, 0x0a, 0x04, 0x68, 0x69, 0x67, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04,
-	0x68, 0x69, 0x67, 0x68, 0x12, 0x10, 0x0a, 0x03, 0x6c, 0x6f, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x01, 0x52, 0x03, 0x6c, 0x6f, 0x77, 0x12, 0x12, 0x0a, 0x04, 0x6f, 0x70, 0x65, 0x6e, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x6f, 0x70, 0x65, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6c,
-	0x6f, 0x73, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x63, 0x6c, 0x6f, 0x73, 0x65,
-	0x12, 0x16, 0x0a, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01,
-	0x52, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x72,
-	0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x1a, 0x62,
-	0x0a, 0x0c, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x12, 0x10,
-	0x0a, 0x03, 0x62, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x62, 0x69, 0x6e,
-	0x12, 0x40, 0x0a, 0x06, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
-	0x32, 0x28, 0x2e, 0x64, 0x63, 0x72, 0x72, 0x61, 0x74, 0x65, 0x73, 0x2e, 0x45, 0x78, 0x63, 0x68,
-	0x61, 0x6e, 0x67, 0x65, 0x52, 0x61, 0x74, 0x65, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x2e, 0x43,
-	0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x52, 0x06, 0x73, 0x74, 0x69, 0x63,
-	0x6b, 0x73, 0x32, 0x60, 0x0a, 0x08, 0x44, 0x43, 0x52, 0x52, 0x61, 0x74, 0x65, 0x73, 0x12, 0x54,
-	0x0a, 0x12, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x45, 0x78, 0x63, 0x68, 0x61,
-	0x6e, 0x67, 0x65, 0x73, 0x12, 0x1e, 0x2e, 0x64, 0x63, 0x72, 0x72, 0x61, 0x74, 0x65, 0x73, 0x2e,
-	0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70,
-	0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x1c, 0x2e, 0x64, 0x63, 0x72, 0x72, 0x61, 0x74, 0x65, 0x73, 0x2e,
-	0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x61, 0x74, 0x65, 0x55, 0x70, 0x64, 0x61,
-	0x74, 0x65, 0x30, 0x01, 0x42, 0x0b, 0x5a, 0x09, 0x2f, 0x64, 0x63, 0x72, 0x72, 0x61, 0x74, 0x65,
-	0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x52, 0x0c, 0x63, 0x61,
+	0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x68,
+	0x61, 0x69, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x63, 0x68, 0x61, 0x69, 0x6e,
+	0x1a, 0x3a, 0x0a, 0x0c, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x3e, 0x0a, 0x0a,
+	0x44, 0x65, 0x70, 0x74, 0x68, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x71, 0x75,
+	0x61, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x71, 0x75,
+	0x61, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x1a, 0x99, 0x01, 0x0a,
+	0x09, 0x44, 0x65, 0x70, 0x74, 0x68, 0x44, 0x61, 0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x69,
+	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x3b,
+	0x0a, 0x04, 0x62, 0x69, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x64,
+	0x63, 0x72, 0x72, 0x61, 0x74, 0x65, 0x73, 0x2e, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x52, 0x61, 0x74, 0x65, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x2e, 0x44, 0x65, 0x70, 0x74, 0x68,
+	0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x04, 0x62, 0x69, 0x64, 0x73, 0x12, 0x3b, 0x0a, 0x04, 0x61,
+	0x73, 0x6b, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x64, 0x63, 0x72, 0x72,
+	0x61, 0x74, 0x65, 0x73, 0x2e, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x61, 0x74,
+	0x65, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x2e, 0x44, 0x65, 0x70, 0x74, 0x68, 0x50, 0x6f, 0x69,
+	0x6e, 0x74, 0x52, 0x04, 0x61, 0x73, 0x6b, 0x73, 0x1a, 0x8b, 0x01, 0x0a, 0x0b, 0x43, 0x61, 0x6e,
+	0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x69, 0x67, 0x68,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x68, 0x69, 0x67, 0x68, 0x12, 0x10, 0x0a, 0x03,
+	0x6c, 0x6f, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6c, 0x6f, 0x77, 0x12, 0x12,
+	0x0a, 0x04, 0x6f, 0x70, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x6f, 0x70,
+	0x65, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x05, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x6f, 0x6c, 0x75,
+	0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65,
+	0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x1a, 0x62, 0x0a, 0x0c, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65,
+	0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x62, 0x69, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x62, 0x69, 0x6e, 0x12, 0x40, 0x0a, 0x06, 0x73, 0x74, 0x69, 0x63,
+	0x6b, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x64, 0x63, 0x72, 0x72, 0x61,
+	0x74, 0x65, 0x73, 0x2e, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x61, 0x74, 0x65,
+	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69,
+	0x63, 0x6b, 0x52, 0x06, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x32, 0x60, 0x0a, 0x08, 0x44, 0x43,
+	0x52, 0x52, 0x61, 0x74, 0x65, 0x73, 0x12, 0x54, 0x0a, 0x12, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72,
+	0x69, 0x62, 0x65, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x12, 0x1e, 0x2e, 0x64,
+	0x63, 0x72, 0x72, 0x61, 0x74, 0x65, 0x73, 0x2e, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x1c, 0x2e, 0x64,
+	0x63, 0x72, 0x72, 0x61, 0x74, 0x65, 0x73, 0x2e, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x52, 0x61, 0x74, 0x65, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x30, 0x01, 0x42, 0x0b, 0x5a, 0x09,
+	0x2f, 0x64, 0x63, 0x72, 0x72, 0x61, 0x74, 0x65, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
 }
 
 var (
@@ -575,30 +663,32 @@ func file_dcrrates_proto_rawDescGZIP() []byte {
 	return file_dcrrates_proto_rawDescData
 }
 
-var file_dcrrates_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_dcrrates_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_dcrrates_proto_goTypes = []interface{}{
 	(*ExchangeSubscription)(nil),            // 0: dcrrates.ExchangeSubscription
-	(*ExchangeRateUpdate)(nil),              // 1: dcrrates.ExchangeRateUpdate
-	nil,                                     // 2: dcrrates.ExchangeRateUpdate.IndicesEntry
-	(*ExchangeRateUpdate_DepthPoint)(nil),   // 3: dcrrates.ExchangeRateUpdate.DepthPoint
-	(*ExchangeRateUpdate_DepthData)(nil),    // 4: dcrrates.ExchangeRateUpdate.DepthData
-	(*ExchangeRateUpdate_Candlestick)(nil),  // 5: dcrrates.ExchangeRateUpdate.Candlestick
-	(*ExchangeRateUpdate_Candlesticks)(nil), // 6: dcrrates.ExchangeRateUpdate.Candlesticks
+	(*ChainExchanges)(nil),                  // 1: dcrrates.ChainExchanges
+	(*ExchangeRateUpdate)(nil),              // 2: dcrrates.ExchangeRateUpdate
+	nil,                                     // 3: dcrrates.ExchangeRateUpdate.IndicesEntry
+	(*ExchangeRateUpdate_DepthPoint)(nil),   // 4: dcrrates.ExchangeRateUpdate.DepthPoint
+	(*ExchangeRateUpdate_DepthData)(nil),    // 5: dcrrates.ExchangeRateUpdate.DepthData
+	(*ExchangeRateUpdate_Candlestick)(nil),  // 6: dcrrates.ExchangeRateUpdate.Candlestick
+	(*ExchangeRateUpdate_Candlesticks)(nil), // 7: dcrrates.ExchangeRateUpdate.Candlesticks
 }
 var file_dcrrates_proto_depIdxs = []int32{
-	2, // 0: dcrrates.ExchangeRateUpdate.indices:type_name -> dcrrates.ExchangeRateUpdate.IndicesEntry
-	4, // 1: dcrrates.ExchangeRateUpdate.depth:type_name -> dcrrates.ExchangeRateUpdate.DepthData
-	6, // 2: dcrrates.ExchangeRateUpdate.candlesticks:type_name -> dcrrates.ExchangeRateUpdate.Candlesticks
-	3, // 3: dcrrates.ExchangeRateUpdate.DepthData.bids:type_name -> dcrrates.ExchangeRateUpdate.DepthPoint
-	3, // 4: dcrrates.ExchangeRateUpdate.DepthData.asks:type_name -> dcrrates.ExchangeRateUpdate.DepthPoint
-	5, // 5: dcrrates.ExchangeRateUpdate.Candlesticks.sticks:type_name -> dcrrates.ExchangeRateUpdate.Candlestick
-	0, // 6: dcrrates.DCRRates.SubscribeExchanges:input_type -> dcrrates.ExchangeSubscription
-	1, // 7: dcrrates.DCRRates.SubscribeExchanges:output_type -> dcrrates.ExchangeRateUpdate
-	7, // [7:8] is the sub-list for method output_type
-	6, // [6:7] is the sub-list for method input_type
-	6, // [6:6] is the sub-list for extension type_name
-	6, // [6:6] is the sub-list for extension extendee
-	0, // [0:6] is the sub-list for field type_name
+	1, // 0: dcrrates.ExchangeSubscription.chains:type_name -> dcrrates.ChainExchanges
+	3, // 1: dcrrates.ExchangeRateUpdate.indices:type_name -> dcrrates.ExchangeRateUpdate.IndicesEntry
+	5, // 2: dcrrates.ExchangeRateUpdate.depth:type_name -> dcrrates.ExchangeRateUpdate.DepthData
+	7, // 3: dcrrates.ExchangeRateUpdate.candlesticks:type_name -> dcrrates.ExchangeRateUpdate.Candlesticks
+	4, // 4: dcrrates.ExchangeRateUpdate.DepthData.bids:type_name -> dcrrates.ExchangeRateUpdate.DepthPoint
+	4, // 5: dcrrates.ExchangeRateUpdate.DepthData.asks:type_name -> dcrrates.ExchangeRateUpdate.DepthPoint
+	6, // 6: dcrrates.ExchangeRateUpdate.Candlesticks.sticks:type_name -> dcrrates.ExchangeRateUpdate.Candlestick
+	0, // 7: dcrrates.DCRRates.SubscribeExchanges:input_type -> dcrrates.ExchangeSubscription
+	2, // 8: dcrrates.DCRRates.SubscribeExchanges:output_type -> dcrrates.ExchangeRateUpdate
+	8, // [8:9] is the sub-list for method output_type
+	7, // [7:8] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_dcrrates_proto_init() }
@@ -620,6 +710,18 @@ func file_dcrrates_proto_init() {
 			}
 		}
 		file_dcrrates_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChainExchanges); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dcrrates_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ExchangeRateUpdate); i {
 			case 0:
 				return &v.state
@@ -631,7 +733,7 @@ func file_dcrrates_proto_init() {
 				return nil
 			}
 		}
-		file_dcrrates_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+		file_dcrrates_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ExchangeRateUpdate_DepthPoint); i {
 			case 0:
 				return &v.state
@@ -643,7 +745,7 @@ func file_dcrrates_proto_init() {
 				return nil
 			}
 		}
-		file_dcrrates_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+		file_dcrrates_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ExchangeRateUpdate_DepthData); i {
 			case 0:
 				return &v.state
@@ -655,7 +757,7 @@ func file_dcrrates_proto_init() {
 				return nil
 			}
 		}
-		file_dcrrates_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+		file_dcrrates_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ExchangeRateUpdate_Candlestick); i {
 			case 0:
 				return &v.state
@@ -667,7 +769,7 @@ func file_dcrrates_proto_init() {
 				return nil
 			}
 		}
-		file_dcrrates_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+		file_dcrrates_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ExchangeRateUpdate_Candlesticks); i {
 			case 0:
 				return &v.state
@@ -686,7 +788,7 @@ func file_dcrrates_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_dcrrates_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
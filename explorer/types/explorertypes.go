@@ -24,8 +24,10 @@ import (
 	ltcwire "github.com/ltcsuite/ltcd/wire"
 
 	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/mutilchain"
 	"github.com/decred/dcrdata/v8/mutilchain/externalapi"
 	"github.com/decred/dcrdata/v8/txhelpers"
+	"github.com/decred/dcrdata/v8/xmr/xmrring"
 	"github.com/decred/dcrdata/v8/xmr/xmrutil"
 )
 
@@ -184,6 +186,8 @@ type TrimmedTxInfo struct {
 	VinCount         int
 	VoutCount        int
 	VoteValid        bool
+	// Labels are the known-entity labels of the output addresses.
+	Labels []string
 }
 
 type XmrTxFull struct {
@@ -264,15 +268,16 @@ type XmrRingMemberInfo struct {
 
 // KeyImageInfo: table key_images mapping
 type XmrKeyImageInfo struct {
-	KeyImage    string               `json:"key_image"`
-	SeenAtTx    string               `json:"seen_at_tx,omitempty"` // tx hash where first seen
-	SpentAtTx   string               `json:"spent_at_tx,omitempty"`
-	Spent       bool                 `json:"spent"`
-	AmountIn    int64                `json:"amount_in"`
-	TextIsHash  bool                 `json:"text_is_hash"`
-	DisplayText string               `json:"display_text"`
-	RingMembers []uint64             `json:"ring_members"`
-	RingCtOuts  []xmrutil.OutputInfo `json:"ring_ct_outs"`
+	KeyImage    string                 `json:"key_image"`
+	SeenAtTx    string                 `json:"seen_at_tx,omitempty"` // tx hash where first seen
+	SpentAtTx   string                 `json:"spent_at_tx,omitempty"`
+	Spent       bool                   `json:"spent"`
+	AmountIn    int64                  `json:"amount_in"`
+	TextIsHash  bool                   `json:"text_is_hash"`
+	DisplayText string                 `json:"display_text"`
+	RingMembers []uint64               `json:"ring_members"`
+	RingCtOuts  []xmrutil.OutputInfo   `json:"ring_ct_outs"`
+	Analysis    *xmrring.InputAnalysis `json:"analysis,omitempty"`
 }
 
 // RctData minimal wrapper for rct metadata (store raw or parsed)
@@ -780,6 +785,8 @@ type HomeInfo struct {
 	RemainingBlocks       int64                       `json:"remainingBlocks"`
 	TimeRemaining         int64                       `json:"timeRemaining"`
 	BlockTimeAvg          int64                       `json:"blockTimeAvg"`
+	NextDifficulty        float64                     `json:"nextDifficulty"`
+	RetargetProgress      float64                     `json:"retargetProgress"`
 	FormattedAvgBlockSize string                      `json:"formattedAvgBlockSize"`
 	BlockReward           int64                       `json:"blockReward"`
 	FeesPerBlock          int64                       `json:"feesPerBlock"`
@@ -803,6 +810,18 @@ type HomeInfo struct {
 	TargetTimePerBlock    float64                     `json:"targetTimePerBlock"`
 }
 
+// SetDifficultyAdjustment sets the BTC/LTC difficulty retarget fields.
+// TimeRemaining and BlockTimeAvg are in milliseconds.
+func (h *HomeInfo) SetDifficultyAdjustment(da *mutilchain.DifficultyAdjustment) {
+	h.DifficultyChange = da.DifficultyChange
+	h.PreviousRetarget = da.PreviousRetarget
+	h.RemainingBlocks = da.RemainingBlocks
+	h.TimeRemaining = da.TimeRemaining * 1000
+	h.BlockTimeAvg = da.TimeAvg * 1000
+	h.NextDifficulty = da.NextDifficulty
+	h.RetargetProgress = da.Progress
+}
+
 type MutilchainHomeInfo struct {
 	CoinSupply   int64       `json:"coin_supply"`
 	Difficulty   float64     `json:"difficulty"`
@@ -859,6 +878,7 @@ type MutilchainMempoolInfo struct {
 	TotalFee           float64             `json:"total_fee"`
 	MinFeeRatevB       float64             `json:"minFeeRatevB"`
 	MaxFeeRatevB       float64             `json:"maxFeeRatevB"`
+	VBytesPerSecond    float64             `json:"vbytesPerSecond"`
 	FormattedTotalSize string              `json:"formatted_size"`
 	Transactions       []MempoolTx         `json:"tx"`
 	XmrTxs             []xmrutil.MempoolTx `json:"xmr_tx"`
@@ -1345,6 +1365,13 @@ type MempoolTx struct {
 	Type     string    `json:"Type"`
 	TypeID   int       `json:"typeID"` // stake package types
 	VoteInfo *VoteInfo `json:"vote_info,omitempty"`
+	// BTC and LTC replace-by-fee and package data. EffectiveFeeRate is in
+	// sat/vB and accounts for CPFP by descendants.
+	SignalsRBF       bool     `json:"rbf,omitempty"`
+	AncestorCount    int      `json:"ancestor_count,omitempty"`
+	DescendantCount  int      `json:"descendant_count,omitempty"`
+	EffectiveFeeRate float64  `json:"effective_fee_rate,omitempty"`
+	Replaces         []string `json:"replaces,omitempty"`
 }
 
 type MoneroSimpleSummaryInfo struct {
@@ -1363,9 +1390,115 @@ func (mpt *MempoolTx) DeepCopy() *MempoolTx {
 	out.Vin = make([]MempoolInput, len(mpt.Vin))
 	copy(out.Vin, mpt.Vin)
 	out.VoteInfo = mpt.VoteInfo.DeepCopy()
+	if mpt.Replaces != nil {
+		out.Replaces = make([]string, len(mpt.Replaces))
+		copy(out.Replaces, mpt.Replaces)
+	}
 	return &out
 }
 
+// MempoolReplacement records a BTC or LTC mempool transaction that was
+// replaced by a conflicting transaction spending one or more of the same
+// outpoints. Fees are in atoms of the chain's coin (satoshi or litoshi).
+type MempoolReplacement struct {
+	TxID       string `json:"txid"`
+	ReplacedBy string `json:"replaced_by"`
+	Fee        int64  `json:"fee"`
+	NewFee     int64  `json:"new_fee"`
+	FeeDelta   int64  `json:"fee_delta"`
+	// FullRBF is set when the replaced transaction did not signal BIP-125
+	// replaceability.
+	FullRBF bool  `json:"full_rbf"`
+	Time    int64 `json:"time"`
+}
+
+// Reasons a transaction left the mempool without being mined.
+const (
+	EvictionReplaced      = "replaced"
+	EvictionConflict      = "conflict"
+	EvictionBlockConflict = "block conflict"
+	EvictionExpired       = "expired or evicted"
+)
+
+// MempoolEviction records a BTC or LTC transaction that left the mempool
+// without being mined.
+type MempoolEviction struct {
+	TxID   string `json:"txid"`
+	Reason string `json:"reason"`
+	Time   int64  `json:"time"`
+}
+
+// MempoolTxPackage describes the in-mempool ancestors and descendants of a BTC
+// or LTC transaction. Fees are in atoms and fee rates in atoms/vB.
+type MempoolTxPackage struct {
+	TxID             string   `json:"txid"`
+	Fee              int64    `json:"fee"`
+	VSize            int64    `json:"vsize"`
+	FeeRate          float64  `json:"fee_rate"`
+	SignalsRBF       bool     `json:"rbf"`
+	Ancestors        []string `json:"ancestors,omitempty"`
+	AncestorFee      int64    `json:"ancestor_fee"`
+	AncestorVSize    int64    `json:"ancestor_vsize"`
+	Descendants      []string `json:"descendants,omitempty"`
+	DescendantFee    int64    `json:"descendant_fee"`
+	DescendantVSize  int64    `json:"descendant_vsize"`
+	EffectiveFeeRate float64  `json:"effective_fee_rate"`
+	Replaces         []string `json:"replaces,omitempty"`
+}
+
+// MutilchainMempoolEvent is sent on the BTC and LTC mempool event
+// subscriptions when transactions are replaced, evicted or accelerated by a
+// child (CPFP).
+type MutilchainMempoolEvent struct {
+	ChainType    string               `json:"chain"`
+	Replacements []MempoolReplacement `json:"replacements,omitempty"`
+	Evictions    []MempoolEviction    `json:"evictions,omitempty"`
+	// Accelerated lists the packages whose effective fee rate was raised by
+	// a new child transaction.
+	Accelerated []*MempoolTxPackage `json:"accelerated,omitempty"`
+}
+
+// MempoolProjectedBlock summarizes one of the blocks projected from the BTC or
+// LTC mempool. Fees are in atoms and fee rates in atoms/vB.
+type MempoolProjectedBlock struct {
+	Index         int     `json:"index"`
+	TxCount       int     `json:"tx_count"`
+	VSize         int64   `json:"vsize"`
+	TotalFee      int64   `json:"total_fee"`
+	MinFeeRate    float64 `json:"min_fee_rate"`
+	MedianFeeRate float64 `json:"median_fee_rate"`
+	MaxFeeRate    float64 `json:"max_fee_rate"`
+}
+
+// MempoolFeeHistogramBin is the number and virtual size of the mempool
+// transactions with an effective fee rate of at least FeeRate and less than
+// the FeeRate of the next bin.
+type MempoolFeeHistogramBin struct {
+	FeeRate float64 `json:"fee_rate"`
+	TxCount int     `json:"tx_count"`
+	VSize   int64   `json:"vsize"`
+}
+
+// MempoolFeeEstimate is the fee rate needed to be included within Blocks
+// blocks according to the projected blocks, and the expected wait.
+type MempoolFeeEstimate struct {
+	Blocks  int     `json:"blocks"`
+	FeeRate float64 `json:"fee_rate"`
+	Minutes float64 `json:"minutes"`
+}
+
+// MutilchainProjectedMempool is the next blocks projected from the BTC or LTC
+// mempool by packing transaction packages greedily by ancestor fee rate.
+type MutilchainProjectedMempool struct {
+	ChainType string                   `json:"chain"`
+	Time      int64                    `json:"time"`
+	TxCount   int                      `json:"tx_count"`
+	VSize     int64                    `json:"vsize"`
+	Blocks    []MempoolProjectedBlock  `json:"blocks"`
+	Histogram []MempoolFeeHistogramBin `json:"histogram"`
+	Estimates []MempoolFeeEstimate     `json:"estimates"`
+}
+
 func CopyMempoolTxSlice(s []MempoolTx) []MempoolTx {
 	if s == nil { // []types.MempoolTx(nil) != []types.MempoolTx{}
 		return nil
@@ -1530,6 +1663,10 @@ func LTCAddressPrefixes(params *ltcchaincfg.Params) []AddrPrefix {
 	}
 
 	netPrefixes := []string{"L", "M", "6 or T", "p2", "7Xh", "xprv", "xpub"}
+	if params.Name != ltcchaincfg.MainNetParams.Name {
+		hrp := params.Bech32HRPSegwit + "1q"
+		netPrefixes = []string{"m or n", "Q", "9 or c", hrp, hrp, "tprv", "tpub"}
+	}
 	addrPrefix := make([]AddrPrefix, 0, len(Descriptions))
 	for i, desc := range Descriptions {
 		addrPrefix = append(addrPrefix, AddrPrefix{
@@ -1560,6 +1697,12 @@ func BTCAddressPrefixes(params *btcchaincfg.Params) []AddrPrefix {
 	}
 
 	netPrefixes := []string{"1", "3", "5", "p2", "7Xh", "xprv", "xpub"}
+	if params.Name != btcchaincfg.MainNetParams.Name {
+		// testnet3, signet and regtest share the test network prefixes,
+		// except for the segwit HRP (tb or bcrt).
+		hrp := params.Bech32HRPSegwit + "1q"
+		netPrefixes = []string{"m or n", "2", "9 or c", hrp, hrp, "tprv", "tpub"}
+	}
 	addrPrefix := make([]AddrPrefix, 0, len(Descriptions))
 	for i, desc := range Descriptions {
 		addrPrefix = append(addrPrefix, AddrPrefix{
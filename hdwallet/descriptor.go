@@ -0,0 +1,208 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package hdwallet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// descriptor is a parsed bare extended key or single-key output descriptor.
+type descriptor struct {
+	// script is empty for a bare key.
+	script string
+	key    string
+	paths  []*derivationPath
+}
+
+// descriptorScripts are the supported descriptor functions, outermost first.
+var descriptorScripts = []struct {
+	prefix, suffix, script string
+}{
+	{"sh(wpkh(", "))", ScriptP2SHP2WPKH},
+	{"pkh(", ")", ScriptP2PKH},
+	{"wpkh(", ")", ScriptP2WPKH},
+	{"tr(", ")", ScriptP2TR},
+}
+
+// parseDescriptor parses a bare extended key, for which the receive (0) and
+// change (1) branches are scanned, or a descriptor such as
+// wpkh([d34db33f/84h/0h/0h]xpub.../<0;1>/*)#checksum. A descriptor key must
+// end in a wildcard, and may have one BIP 389 multipath step of up to
+// MaxMultipath alternatives. Hardened steps
+// after the key cannot be derived from a public key.
+func parseDescriptor(s string) (*descriptor, error) {
+	s = strings.TrimSpace(s)
+	if !strings.ContainsAny(s, "()") {
+		if s == "" {
+			return nil, fmt.Errorf("%w: empty key", ErrInvalidKey)
+		}
+		return &descriptor{
+			key: s,
+			paths: []*derivationPath{
+				{name: "receive", steps: []uint32{0}},
+				{name: "change", steps: []uint32{1}},
+			},
+		}, nil
+	}
+
+	if i := strings.LastIndexByte(s, '#'); i >= 0 {
+		if checksum := descriptorChecksum(s[:i]); checksum == "" || checksum != s[i+1:] {
+			return nil, fmt.Errorf("%w: bad descriptor checksum", ErrInvalidKey)
+		}
+		s = s[:i]
+	}
+	d := new(descriptor)
+	for _, ds := range descriptorScripts {
+		if strings.HasPrefix(s, ds.prefix) && strings.HasSuffix(s, ds.suffix) {
+			d.script = ds.script
+			s = s[len(ds.prefix) : len(s)-len(ds.suffix)]
+			break
+		}
+	}
+	if d.script == "" {
+		return nil, fmt.Errorf("%w: only pkh, wpkh, sh(wpkh) and tr descriptors are supported", ErrInvalidKey)
+	}
+
+	// The key origin is informational.
+	if strings.HasPrefix(s, "[") {
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			return nil, fmt.Errorf("%w: unterminated key origin", ErrInvalidKey)
+		}
+		s = s[end+1:]
+	}
+
+	parts := strings.Split(s, "/")
+	d.key = parts[0]
+	if len(parts) < 2 || parts[len(parts)-1] != "*" {
+		if last := parts[len(parts)-1]; last == "*'" || last == "*h" {
+			return nil, fmt.Errorf("%w: hardened derivation requires the private key", ErrInvalidKey)
+		}
+		return nil, fmt.Errorf("%w: descriptor key must end with /*", ErrInvalidKey)
+	}
+
+	// The steps before the wildcard, with the alternatives of a multipath
+	// step expanded into one path each.
+	d.paths = []*derivationPath{{}}
+	var multipath bool
+	for _, part := range parts[1 : len(parts)-1] {
+		if strings.HasPrefix(part, "<") && strings.HasSuffix(part, ">") {
+			if multipath {
+				return nil, fmt.Errorf("%w: only one multipath step is allowed", ErrInvalidKey)
+			}
+			multipath = true
+			alts := strings.Split(part[1:len(part)-1], ";")
+			if len(alts) < 2 {
+				return nil, fmt.Errorf("%w: multipath step needs two or more indexes", ErrInvalidKey)
+			}
+			if len(alts) > MaxMultipath {
+				return nil, fmt.Errorf("%w: multipath step has more than %d indexes", ErrInvalidKey, MaxMultipath)
+			}
+			paths := make([]*derivationPath, 0, len(alts))
+			for _, alt := range alts {
+				step, err := parseStep(alt)
+				if err != nil {
+					return nil, err
+				}
+				p := d.paths[0]
+				paths = append(paths, &derivationPath{
+					steps: append(append([]uint32(nil), p.steps...), step),
+				})
+			}
+			d.paths = paths
+			continue
+		}
+		step, err := parseStep(part)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range d.paths {
+			p.steps = append(p.steps, step)
+		}
+	}
+	for i, p := range d.paths {
+		switch {
+		case len(d.paths) == 2 && i == 0:
+			p.name = "receive"
+		case len(d.paths) == 2 && i == 1:
+			p.name = "change"
+		default:
+			p.name = p.String()
+		}
+	}
+	return d, nil
+}
+
+// parseStep parses an unhardened derivation step.
+func parseStep(s string) (uint32, error) {
+	if strings.HasSuffix(s, "'") || strings.HasSuffix(s, "h") || strings.HasSuffix(s, "H") {
+		return 0, fmt.Errorf("%w: hardened derivation requires the private key", ErrInvalidKey)
+	}
+	step, err := strconv.ParseUint(s, 10, 31)
+	if err != nil {
+		return 0, fmt.Errorf("%w: bad derivation step %q", ErrInvalidKey, s)
+	}
+	return uint32(step), nil
+}
+
+const (
+	checksumInputCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+		"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+		"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+	checksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+func checksumPolymod(c uint64, val int) uint64 {
+	c0 := c >> 35
+	c = ((c & 0x7ffffffff) << 5) ^ uint64(val)
+	if c0&1 != 0 {
+		c ^= 0xf5dee51989
+	}
+	if c0&2 != 0 {
+		c ^= 0xa9fdca3312
+	}
+	if c0&4 != 0 {
+		c ^= 0x1bab10e32d
+	}
+	if c0&8 != 0 {
+		c ^= 0x3706b1677a
+	}
+	if c0&16 != 0 {
+		c ^= 0x644d626ffd
+	}
+	return c
+}
+
+// descriptorChecksum computes the BIP 380 checksum of a descriptor. It is
+// empty for a descriptor with characters outside the input charset.
+func descriptorChecksum(desc string) string {
+	c := uint64(1)
+	cls, clsCount := 0, 0
+	for _, ch := range desc {
+		pos := strings.IndexRune(checksumInputCharset, ch)
+		if pos < 0 {
+			return ""
+		}
+		c = checksumPolymod(c, pos&31)
+		cls = cls*3 + pos>>5
+		if clsCount++; clsCount == 3 {
+			c = checksumPolymod(c, cls)
+			cls, clsCount = 0, 0
+		}
+	}
+	if clsCount > 0 {
+		c = checksumPolymod(c, cls)
+	}
+	for i := 0; i < 8; i++ {
+		c = checksumPolymod(c, 0)
+	}
+	c ^= 1
+	checksum := make([]byte, 8)
+	for i := range checksum {
+		checksum[i] = checksumCharset[(c>>(5*(7-i)))&31]
+	}
+	return string(checksum)
+}
@@ -0,0 +1,432 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+// Package hdwallet derives the addresses of an extended public key (xpub,
+// ypub, zpub, dpub, ...) or of a single-key output descriptor (pkh, wpkh,
+// sh(wpkh), tr) and scans them for use with a gap limit. Keys are only held
+// for the lifetime of a Wallet and are never stored or logged.
+package hdwallet
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	btcschnorr "github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	btchd "github.com/btcsuite/btcd/btcutil/hdkeychain"
+	btcchaincfg "github.com/btcsuite/btcd/chaincfg"
+	btctxscript "github.com/btcsuite/btcd/txscript"
+	"github.com/decred/dcrd/chaincfg/v3"
+	dcrhd "github.com/decred/dcrd/hdkeychain/v3"
+	"github.com/decred/dcrd/txscript/v4/stdaddr"
+	"github.com/decred/dcrdata/v8/mutilchain"
+	ltcschnorr "github.com/ltcsuite/ltcd/btcec/v2/schnorr"
+	ltcchaincfg "github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/ltcutil"
+	ltchd "github.com/ltcsuite/ltcd/ltcutil/hdkeychain"
+	ltctxscript "github.com/ltcsuite/ltcd/txscript"
+)
+
+// The script types of the derived addresses.
+const (
+	ScriptP2PKH      = "p2pkh"
+	ScriptP2SHP2WPKH = "p2sh-p2wpkh"
+	ScriptP2WPKH     = "p2wpkh"
+	ScriptP2TR       = "p2tr"
+)
+
+const (
+	// DefaultGapLimit is the number of consecutive unused addresses after
+	// which a branch scan stops, as in BIP 44.
+	DefaultGapLimit = 20
+	// MaxGapLimit is the largest accepted gap limit.
+	MaxGapLimit = 100
+	// MaxIndex bounds the addresses derived on each branch.
+	MaxIndex = 10000
+	// MaxDerived bounds the addresses derived by a scan, over all branches.
+	MaxDerived = 2500
+	// MaxUsed bounds the used addresses found by a scan, each of which costs
+	// balance and UTXO queries.
+	MaxUsed = 500
+	// MaxMultipath bounds the alternatives of a descriptor multipath step.
+	MaxMultipath = 4
+)
+
+var (
+	// ErrInvalidKey is wrapped by the errors of keys and descriptors that
+	// cannot be used.
+	ErrInvalidKey = errors.New("invalid extended public key or descriptor")
+	// ErrPrivateKey is returned for extended private keys, which are refused
+	// rather than neutered.
+	ErrPrivateKey = fmt.Errorf("%w: extended private keys are not accepted", ErrInvalidKey)
+	// ErrScanLimit is wrapped by the errors of scans that exceed MaxDerived
+	// or MaxUsed.
+	ErrScanLimit = errors.New("wallet exceeds the scan limits")
+)
+
+// keyVersion is the script type and network implied by the version bytes of
+// a serialized extended public key.
+type keyVersion struct {
+	script  string
+	mainnet bool
+}
+
+var btcVersions = map[[4]byte]keyVersion{
+	{0x04, 0x88, 0xb2, 0x1e}: {ScriptP2PKH, true},       // xpub
+	{0x04, 0x9d, 0x7c, 0xb2}: {ScriptP2SHP2WPKH, true},  // ypub
+	{0x04, 0xb2, 0x47, 0x46}: {ScriptP2WPKH, true},      // zpub
+	{0x04, 0x35, 0x87, 0xcf}: {ScriptP2PKH, false},      // tpub
+	{0x04, 0x4a, 0x52, 0x62}: {ScriptP2SHP2WPKH, false}, // upub
+	{0x04, 0x5f, 0x1c, 0xf6}: {ScriptP2WPKH, false},     // vpub
+}
+
+var ltcVersions = map[[4]byte]keyVersion{
+	{0x01, 0x9d, 0xa4, 0x62}: {ScriptP2PKH, true},      // Ltub
+	{0x01, 0xb2, 0x6e, 0xf6}: {ScriptP2SHP2WPKH, true}, // Mtub
+	{0x04, 0x36, 0xf6, 0xe1}: {ScriptP2PKH, false},     // ttub
+}
+
+func init() {
+	// Litecoin wallets also export the Bitcoin versions.
+	for version, kv := range btcVersions {
+		ltcVersions[version] = kv
+	}
+}
+
+// Branch is a chain of addresses derived from the key at consecutive
+// indexes, such as the receive or change addresses of an account.
+type Branch struct {
+	// Name is "receive" or "change" for the branches of a bare key or of a
+	// <0;1> multipath descriptor, and the path otherwise.
+	Name string
+	// Path is the derivation path of the branch relative to the key.
+	Path    string
+	address func(index uint32) (string, error)
+}
+
+// Address derives the address at an index of the branch.
+func (b *Branch) Address(index uint32) (string, error) {
+	return b.address(index)
+}
+
+// Wallet is the address derivation of an extended public key or descriptor.
+type Wallet struct {
+	Chain      string
+	ScriptType string
+	Branches   []*Branch
+}
+
+// Params are the networks of the supported chains.
+type Params struct {
+	DCR *chaincfg.Params
+	BTC *btcchaincfg.Params
+	LTC *ltcchaincfg.Params
+}
+
+// Parse parses an extended public key or output descriptor of a chain.
+func Parse(chain, key string, params *Params) (*Wallet, error) {
+	switch chain {
+	case mutilchain.TYPEDCR:
+		return ParseDCR(key, params.DCR)
+	case mutilchain.TYPEBTC:
+		return ParseBTC(key, params.BTC)
+	case mutilchain.TYPELTC:
+		return ParseLTC(key, params.LTC)
+	}
+	return nil, fmt.Errorf("%w: unsupported chain %q", ErrInvalidKey, chain)
+}
+
+// ParseDCR parses a Decred extended public key (dpub, tpub, spub), or a pkh
+// descriptor of one.
+func ParseDCR(key string, params *chaincfg.Params) (*Wallet, error) {
+	d, err := parseDescriptor(key)
+	if err != nil {
+		return nil, err
+	}
+	if d.script != "" && d.script != ScriptP2PKH {
+		return nil, fmt.Errorf("%w: Decred only supports pkh descriptors", ErrInvalidKey)
+	}
+	k, err := dcrhd.NewKeyFromString(d.key, params)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	if k.IsPrivate() {
+		return nil, ErrPrivateKey
+	}
+	w := &Wallet{Chain: mutilchain.TYPEDCR, ScriptType: ScriptP2PKH}
+	for _, p := range d.paths {
+		parent := k
+		for _, step := range p.steps {
+			if parent, err = parent.Child(step); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+			}
+		}
+		w.Branches = append(w.Branches, &Branch{
+			Name: p.name,
+			Path: p.String(),
+			address: func(index uint32) (string, error) {
+				child, err := parent.Child(index)
+				if err != nil {
+					return "", err
+				}
+				pkHash := stdaddr.Hash160(child.SerializedPubKey())
+				addr, err := stdaddr.NewAddressPubKeyHashEcdsaSecp256k1V0(pkHash, params)
+				if err != nil {
+					return "", err
+				}
+				return addr.String(), nil
+			},
+		})
+	}
+	return w, nil
+}
+
+// ParseBTC parses a Bitcoin extended public key (xpub, ypub, zpub and their
+// test network versions), or a descriptor of one.
+func ParseBTC(key string, params *btcchaincfg.Params) (*Wallet, error) {
+	d, err := parseDescriptor(key)
+	if err != nil {
+		return nil, err
+	}
+	k, err := btchd.NewKeyFromString(d.key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	if k.IsPrivate() {
+		return nil, ErrPrivateKey
+	}
+	script, err := scriptType(d, k.Version(), btcVersions,
+		params.Net == btcchaincfg.MainNetParams.Net)
+	if err != nil {
+		return nil, err
+	}
+	w := &Wallet{Chain: mutilchain.TYPEBTC, ScriptType: script}
+	for _, p := range d.paths {
+		parent := k
+		for _, step := range p.steps {
+			if parent, err = parent.Derive(step); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+			}
+		}
+		w.Branches = append(w.Branches, &Branch{
+			Name: p.name,
+			Path: p.String(),
+			address: func(index uint32) (string, error) {
+				child, err := parent.Derive(index)
+				if err != nil {
+					return "", err
+				}
+				pub, err := child.ECPubKey()
+				if err != nil {
+					return "", err
+				}
+				var addr btcutil.Address
+				pkHash := btcutil.Hash160(pub.SerializeCompressed())
+				switch script {
+				case ScriptP2PKH:
+					addr, err = btcutil.NewAddressPubKeyHash(pkHash, params)
+				case ScriptP2SHP2WPKH:
+					addr, err = btcutil.NewAddressScriptHash(witnessV0Script(pkHash), params)
+				case ScriptP2WPKH:
+					addr, err = btcutil.NewAddressWitnessPubKeyHash(pkHash, params)
+				case ScriptP2TR:
+					outputKey := btctxscript.ComputeTaprootKeyNoScript(pub)
+					addr, err = btcutil.NewAddressTaproot(btcschnorr.SerializePubKey(outputKey), params)
+				}
+				if err != nil {
+					return "", err
+				}
+				return addr.EncodeAddress(), nil
+			},
+		})
+	}
+	return w, nil
+}
+
+// ParseLTC parses a Litecoin extended public key (Ltub, Mtub, ttub or the
+// Bitcoin versions), or a descriptor of one.
+func ParseLTC(key string, params *ltcchaincfg.Params) (*Wallet, error) {
+	d, err := parseDescriptor(key)
+	if err != nil {
+		return nil, err
+	}
+	k, err := ltchd.NewKeyFromString(d.key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	if k.IsPrivate() {
+		return nil, ErrPrivateKey
+	}
+	script, err := scriptType(d, k.Version(), ltcVersions,
+		params.Net == ltcchaincfg.MainNetParams.Net)
+	if err != nil {
+		return nil, err
+	}
+	w := &Wallet{Chain: mutilchain.TYPELTC, ScriptType: script}
+	for _, p := range d.paths {
+		parent := k
+		for _, step := range p.steps {
+			if parent, err = parent.Derive(step); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+			}
+		}
+		w.Branches = append(w.Branches, &Branch{
+			Name: p.name,
+			Path: p.String(),
+			address: func(index uint32) (string, error) {
+				child, err := parent.Derive(index)
+				if err != nil {
+					return "", err
+				}
+				pub, err := child.ECPubKey()
+				if err != nil {
+					return "", err
+				}
+				var addr ltcutil.Address
+				pkHash := ltcutil.Hash160(pub.SerializeCompressed())
+				switch script {
+				case ScriptP2PKH:
+					addr, err = ltcutil.NewAddressPubKeyHash(pkHash, params)
+				case ScriptP2SHP2WPKH:
+					addr, err = ltcutil.NewAddressScriptHash(witnessV0Script(pkHash), params)
+				case ScriptP2WPKH:
+					addr, err = ltcutil.NewAddressWitnessPubKeyHash(pkHash, params)
+				case ScriptP2TR:
+					outputKey := ltctxscript.ComputeTaprootKeyNoScript(pub)
+					addr, err = ltcutil.NewAddressTaproot(ltcschnorr.SerializePubKey(outputKey), params)
+				}
+				if err != nil {
+					return "", err
+				}
+				return addr.EncodeAddress(), nil
+			},
+		})
+	}
+	return w, nil
+}
+
+// scriptType checks the version of a key against the network and returns the
+// script type of the descriptor, or the one implied by the version for a bare
+// key.
+func scriptType(d *descriptor, version []byte, versions map[[4]byte]keyVersion, mainnet bool) (string, error) {
+	var v [4]byte
+	copy(v[:], version)
+	kv, found := versions[v]
+	if !found {
+		return "", fmt.Errorf("%w: unknown extended key version %x", ErrInvalidKey, version)
+	}
+	if kv.mainnet != mainnet {
+		return "", fmt.Errorf("%w: extended key is for another network", ErrInvalidKey)
+	}
+	if d.script != "" {
+		return d.script, nil
+	}
+	return kv.script, nil
+}
+
+// witnessV0Script returns the version 0 witness program of a public key hash,
+// the redeem script of a nested segwit address.
+func witnessV0Script(pkHash []byte) []byte {
+	return append([]byte{0x00, 0x14}, pkHash...)
+}
+
+// Address is a used address found by a scan.
+type Address struct {
+	Index   uint32
+	Path    string
+	Address string
+}
+
+// BranchScan is the outcome of scanning a branch.
+type BranchScan struct {
+	Name string
+	Path string
+	// Used are the addresses that have received funds, by index.
+	Used []*Address
+	// Next is the first address after the last used one.
+	Next *Address
+}
+
+// UsedFunc reports which of the addresses have received funds.
+type UsedFunc func(addrs []string) (map[string]bool, error)
+
+// Scan derives the addresses of each branch, in batches of the gap limit,
+// until gapLimit consecutive addresses are unused or MaxIndex is reached. A
+// scan deriving more than MaxDerived addresses or finding more than MaxUsed
+// used ones is abandoned with an error wrapping ErrScanLimit.
+func (w *Wallet) Scan(gapLimit int, used UsedFunc) ([]*BranchScan, error) {
+	if gapLimit < 1 || gapLimit > MaxGapLimit {
+		return nil, fmt.Errorf("gap limit must be between 1 and %d", MaxGapLimit)
+	}
+	scans := make([]*BranchScan, 0, len(w.Branches))
+	var derived, numUsed int
+	for _, b := range w.Branches {
+		scan := &BranchScan{Name: b.Name, Path: b.Path}
+		next := uint32(0) // one past the last used index
+		for start := uint32(0); start < next+uint32(gapLimit) && start < MaxIndex; {
+			end := next + uint32(gapLimit)
+			if end > MaxIndex {
+				end = MaxIndex
+			}
+			if derived += int(end - start); derived > MaxDerived {
+				return nil, fmt.Errorf("%w: more than %d addresses derived", ErrScanLimit, MaxDerived)
+			}
+			batch := make([]*Address, 0, end-start)
+			addrs := make([]string, 0, end-start)
+			for i := start; i < end; i++ {
+				addr, err := b.address(i)
+				if err != nil {
+					// Invalid children are skipped by wallets too.
+					continue
+				}
+				batch = append(batch, &Address{i, joinPath(b.Path, i), addr})
+				addrs = append(addrs, addr)
+			}
+			isUsed, err := used(addrs)
+			if err != nil {
+				return nil, err
+			}
+			for _, a := range batch {
+				if isUsed[a.Address] {
+					if numUsed++; numUsed > MaxUsed {
+						return nil, fmt.Errorf("%w: more than %d used addresses", ErrScanLimit, MaxUsed)
+					}
+					scan.Used = append(scan.Used, a)
+					next = a.Index + 1
+				}
+			}
+			start = end
+		}
+		if next < MaxIndex {
+			addr, err := b.address(next)
+			if err == nil {
+				scan.Next = &Address{next, joinPath(b.Path, next), addr}
+			}
+		}
+		scans = append(scans, scan)
+	}
+	return scans, nil
+}
+
+func joinPath(path string, index uint32) string {
+	if path == "" {
+		return strconv.FormatUint(uint64(index), 10)
+	}
+	return path + "/" + strconv.FormatUint(uint64(index), 10)
+}
+
+// derivationPath is the path of a branch relative to the key.
+type derivationPath struct {
+	name  string
+	steps []uint32
+}
+
+func (p *derivationPath) String() string {
+	steps := make([]string, 0, len(p.steps))
+	for _, step := range p.steps {
+		steps = append(steps, strconv.FormatUint(uint64(step), 10))
+	}
+	return strings.Join(steps, "/")
+}
@@ -0,0 +1,264 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package hdwallet
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	btcchaincfg "github.com/btcsuite/btcd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/v3"
+	dcrhd "github.com/decred/dcrd/hdkeychain/v3"
+	"github.com/decred/dcrd/txscript/v4/stdaddr"
+	ltcchaincfg "github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/ltcutil"
+)
+
+const (
+	// BIP 84 test vector, account 0.
+	bip84ZPub = "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs"
+	// BIP 86 test vector, account 0.
+	bip86XPub = "xpub6BgBgsespWvERF3LHQu6CnqdvfEvtMcQjYrcRzx53QJjSxarj2afYWcLteoGVky7D3UKDP9QyrLprQ3VCECoY49yfdDEHGCtMMj92pReUsQ"
+)
+
+func TestDescriptorChecksum(t *testing.T) {
+	// BIP 380 test vector.
+	if got := descriptorChecksum("raw(deadbeef)"); got != "89f8spxm" {
+		t.Errorf("checksum %q, want 89f8spxm", got)
+	}
+	if got := descriptorChecksum("raw(déadbeef)"); got != "" {
+		t.Errorf("checksum %q of invalid charset, want empty", got)
+	}
+}
+
+func TestParseDescriptor(t *testing.T) {
+	tests := []struct {
+		desc   string
+		script string
+		paths  []string
+		err    bool
+	}{
+		{desc: "xpub", paths: []string{"receive 0", "change 1"}},
+		{desc: "wpkh([d34db33f/84h/0h/0h]xpub/<0;1>/*)", script: ScriptP2WPKH,
+			paths: []string{"receive 0", "change 1"}},
+		{desc: "sh(wpkh(xpub/0/*))", script: ScriptP2SHP2WPKH, paths: []string{"0 0"}},
+		{desc: "tr(xpub/5/<2;3;4>/*)", script: ScriptP2TR, paths: []string{"5/2 5/2", "5/3 5/3", "5/4 5/4"}},
+		{desc: "pkh(xpub/*)", script: ScriptP2PKH, paths: []string{" "}},
+		{desc: "wpkh(xpub/0'/*)", err: true},
+		{desc: "wpkh(xpub/0/*h)", err: true},
+		{desc: "wpkh(xpub/0)", err: true},
+		{desc: "wpkh(xpub/<0;1>/<0;1>/*)", err: true},
+		{desc: "wpkh(xpub/<0;1;2;3;4>/*)", err: true},
+		{desc: "wsh(multi(1,xpub/0/*))", err: true},
+		{desc: "wpkh([d34db33f/84h/0h/0h]xpub/0/*", err: true},
+		{desc: "", err: true},
+	}
+	for _, test := range tests {
+		d, err := parseDescriptor(test.desc)
+		if test.err {
+			if !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("%q: error %v, want ErrInvalidKey", test.desc, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", test.desc, err)
+			continue
+		}
+		if d.key != "xpub" || d.script != test.script || len(d.paths) != len(test.paths) {
+			t.Errorf("%q: got key %q, script %q, %d paths", test.desc, d.key, d.script, len(d.paths))
+			continue
+		}
+		for i, p := range d.paths {
+			if got := p.name + " " + p.String(); got != test.paths[i] {
+				t.Errorf("%q: path %d is %q, want %q", test.desc, i, got, test.paths[i])
+			}
+		}
+	}
+}
+
+func TestChecksummedDescriptor(t *testing.T) {
+	desc := "wpkh(" + bip84ZPub + "/<0;1>/*)"
+	if _, err := ParseBTC(desc+"#"+descriptorChecksum(desc), &btcchaincfg.MainNetParams); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseBTC(desc+"#qqqqqqqq", &btcchaincfg.MainNetParams); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("bad checksum accepted: %v", err)
+	}
+}
+
+func TestParseBTC(t *testing.T) {
+	tests := []struct {
+		key     string
+		script  string
+		receive []string
+		change  string
+	}{{
+		key:     bip84ZPub,
+		script:  ScriptP2WPKH,
+		receive: []string{"bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu", "bc1qnjg0jd8228aq7egyzacy8cys3knf9xvrerkf9g"},
+		change:  "bc1q8c6fshw2dlwun7ekn9qwf37cu2rn755upcp6el",
+	}, {
+		key:     "tr(" + bip86XPub + "/<0;1>/*)",
+		script:  ScriptP2TR,
+		receive: []string{"bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr", "bc1p4qhjn9zdvkux4e44uhx8tc55attvtyu358kutcqkudyccelu0was9fqzwh"},
+		change:  "bc1p3qkhfews2uk44qtvauqyr2ttdsw7svhkl9nkm9s9c3x4ax5h60wqwruhk7",
+	}}
+	for _, test := range tests {
+		w, err := ParseBTC(test.key, &btcchaincfg.MainNetParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w.ScriptType != test.script || len(w.Branches) != 2 {
+			t.Fatalf("got script %q and %d branches", w.ScriptType, len(w.Branches))
+		}
+		for i, want := range test.receive {
+			if got, _ := w.Branches[0].Address(uint32(i)); got != want {
+				t.Errorf("%s receive %d: got %s, want %s", test.script, i, got, want)
+			}
+		}
+		if got, _ := w.Branches[1].Address(0); got != test.change {
+			t.Errorf("%s change 0: got %s, want %s", test.script, got, test.change)
+		}
+	}
+
+	if _, err := ParseBTC(bip84ZPub, &btcchaincfg.TestNet3Params); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("mainnet key accepted on testnet: %v", err)
+	}
+	xprv := "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi"
+	if _, err := ParseBTC(xprv, &btcchaincfg.MainNetParams); !errors.Is(err, ErrPrivateKey) {
+		t.Errorf("private key accepted: %v", err)
+	}
+}
+
+func TestParseLTC(t *testing.T) {
+	w, err := ParseLTC(bip84ZPub, &ltcchaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := w.Branches[0].Address(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The witness program of the BIP 84 receive address.
+	decoded, err := ltcutil.DecodeAddress(addr, &ltcchaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hex.EncodeToString(decoded.ScriptAddress()), "c0cebcd6c3d3ca8c75dc5ec62ebe55330ef910e2"; got != want {
+		t.Errorf("got witness program %s, want %s", got, want)
+	}
+	if _, err := ParseLTC(bip84ZPub, &ltcchaincfg.TestNet4Params); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("mainnet key accepted on testnet: %v", err)
+	}
+}
+
+func TestParseDCR(t *testing.T) {
+	params := chaincfg.MainNetParams()
+	seed := make([]byte, 32)
+	master, err := dcrhd.NewMaster(seed, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := master.Neuter()
+	w, err := ParseDCR(account.String(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	change, _ := account.Child(1)
+	child, _ := change.Child(3)
+	want, _ := stdaddr.NewAddressPubKeyHashEcdsaSecp256k1V0(
+		stdaddr.Hash160(child.SerializedPubKey()), params)
+	if got, _ := w.Branches[1].Address(3); got != want.String() {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	if _, err = ParseDCR(master.String(), params); !errors.Is(err, ErrPrivateKey) {
+		t.Errorf("private key accepted: %v", err)
+	}
+	if _, err = ParseDCR(account.String(), chaincfg.TestNet3Params()); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("mainnet key accepted on testnet: %v", err)
+	}
+	if _, err = ParseDCR("wpkh("+account.String()+"/0/*)", params); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("wpkh descriptor accepted: %v", err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	w, err := ParseBTC(bip84ZPub, &btcchaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Receive addresses 0, 5 and 12 and change address 0 are used. 12 is
+	// beyond the gap after 5.
+	used := make(map[string]bool)
+	for _, u := range []struct {
+		branch int
+		index  uint32
+	}{{0, 0}, {0, 5}, {0, 12}, {1, 0}} {
+		addr, _ := w.Branches[u.branch].Address(u.index)
+		used[addr] = true
+	}
+	var queried int
+	scans, err := w.Scan(5, func(addrs []string) (map[string]bool, error) {
+		queried += len(addrs)
+		found := make(map[string]bool)
+		for _, addr := range addrs {
+			if used[addr] {
+				found[addr] = true
+			}
+		}
+		return found, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Receive: 0..4, 5..5, 6..10, change: 0..4, 5..5.
+	if queried != 11+6 {
+		t.Errorf("queried %d addresses, want 17", queried)
+	}
+	receive, change := scans[0], scans[1]
+	if len(receive.Used) != 2 || receive.Used[1].Path != "0/5" || receive.Next.Index != 6 {
+		t.Errorf("unexpected receive scan %+v", receive)
+	}
+	if len(change.Used) != 1 || change.Next.Path != "1/1" {
+		t.Errorf("unexpected change scan %+v", change)
+	}
+
+	if _, err = w.Scan(MaxGapLimit+1, nil); err == nil {
+		t.Error("gap limit above the maximum accepted")
+	}
+
+	// Every address used.
+	_, err = w.Scan(MaxGapLimit, func(addrs []string) (map[string]bool, error) {
+		found := make(map[string]bool, len(addrs))
+		for _, addr := range addrs {
+			found[addr] = true
+		}
+		return found, nil
+	})
+	if !errors.Is(err, ErrScanLimit) {
+		t.Errorf("expected a scan limit error, got %v", err)
+	}
+
+	// Used addresses at almost the gap limit from each other.
+	used = make(map[string]bool)
+	for i := uint32(0); i < MaxDerived+MaxGapLimit; i += MaxGapLimit - 1 {
+		addr, _ := w.Branches[0].Address(i)
+		used[addr] = true
+	}
+	_, err = w.Scan(MaxGapLimit, func(addrs []string) (map[string]bool, error) {
+		found := make(map[string]bool)
+		for _, addr := range addrs {
+			if used[addr] {
+				found[addr] = true
+			}
+		}
+		return found, nil
+	})
+	if !errors.Is(err, ErrScanLimit) {
+		t.Errorf("expected a scan limit error, got %v", err)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
 	exptypes "github.com/decred/dcrdata/v8/explorer/types"
 	"github.com/decred/dcrdata/v8/txhelpers"
 )
@@ -26,6 +27,8 @@ type NodeClient interface {
 	txhelpers.BTCRawTransactionGetter
 	txhelpers.BTCVerboseTransactionGetter
 	GetBlockHeaderVerbose(hash *chainhash.Hash) (*btcjson.GetBlockHeaderVerboseResult, error)
+	GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error)
+	GetMempoolEntry(txHash string) (*btcjson.GetMempoolEntryResult, error)
 }
 
 // DataCollector is used for retrieving and processing data from a chain
@@ -0,0 +1,333 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package mempoolbtc
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	exptypes "github.com/decred/dcrdata/v8/explorer/types"
+	"github.com/decred/dcrdata/v8/txhelpers"
+)
+
+const (
+	seqRBF   = txhelpers.MaxBIP125Sequence
+	seqFinal = wire.MaxTxInSequenceNum
+)
+
+// confirmed returns an outpoint of a transaction that is not in the mempool.
+func confirmed(n byte) wire.OutPoint {
+	return wire.OutPoint{Hash: chainhash.Hash{n}, Index: 0}
+}
+
+// spend returns an outpoint of a mempool transaction.
+func spend(tx *wire.MsgTx, index uint32) wire.OutPoint {
+	return wire.OutPoint{Hash: tx.TxHash(), Index: index}
+}
+
+// testTx creates a transaction spending prevs with nOut outputs. The tag makes
+// the hash of otherwise identical transactions distinct.
+func testTx(tag int64, seq uint32, nOut int, prevs ...wire.OutPoint) *wire.MsgTx {
+	tx := wire.NewMsgTx(2)
+	for _, op := range prevs {
+		tx.AddTxIn(&wire.TxIn{PreviousOutPoint: op, Sequence: seq})
+	}
+	for i := 0; i < nOut; i++ {
+		tx.AddTxOut(wire.NewTxOut(tag*1000+int64(i), []byte{0x51}))
+	}
+	return tx
+}
+
+func hashes(nodes []*txNode) []string {
+	out := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		out = append(out, n.hash.String())
+	}
+	sort.Strings(out)
+	return out
+}
+
+func txids(txs ...*wire.MsgTx) []string {
+	out := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		out = append(out, tx.TxHash().String())
+	}
+	sort.Strings(out)
+	return out
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTxGraphConflicts(t *testing.T) {
+	a := testTx(1, seqRBF, 2, confirmed(1), confirmed(2))
+	b := testTx(2, seqRBF, 1, confirmed(3))
+	tests := []struct {
+		name string
+		tx   *wire.MsgTx
+		want []string
+	}{
+		{"no conflict", testTx(3, seqRBF, 1, confirmed(4)), nil},
+		{"one input", testTx(4, seqRBF, 1, confirmed(1)), txids(a)},
+		{"same spender twice", testTx(5, seqRBF, 1, confirmed(1), confirmed(2)), txids(a)},
+		{"two spenders", testTx(6, seqRBF, 1, confirmed(2), confirmed(3)), txids(a, b)},
+		{"spends a mempool output", testTx(7, seqRBF, 1, spend(a, 0)), nil},
+		{"already in the graph", a, nil},
+	}
+	g := newTxGraph()
+	g.add(a, 100, 0)
+	g.add(b, 100, 0)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hashes(g.conflicts(tt.tx)); !equalStrings(got, tt.want) {
+				t.Errorf("conflicts %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddToGraphReplacement(t *testing.T) {
+	tests := []struct {
+		name        string
+		seq         uint32
+		withChild   bool
+		newFee      int64
+		wantFullRBF bool
+	}{
+		{"opt-in", seqRBF, false, 500, false},
+		{"full RBF", seqFinal, false, 500, true},
+		{"with descendant", seqRBF, true, 800, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &MempoolMonitor{}
+			orig := testTx(1, tt.seq, 1, confirmed(1))
+			other := testTx(2, seqRBF, 1, confirmed(2))
+			p.addToGraph(orig, 200, 10)
+			p.addToGraph(other, 100, 10)
+			child := testTx(3, seqRBF, 1, spend(orig, 0))
+			if tt.withChild {
+				p.addToGraph(child, 300, 11)
+			}
+
+			repl := testTx(4, seqRBF, 1, confirmed(1))
+			event, removed, affected := p.addToGraph(repl, tt.newFee, 12)
+			if event == nil {
+				t.Fatal("no event for the replacement")
+			}
+			if len(event.Replacements) != 1 {
+				t.Fatalf("%d replacements, want 1", len(event.Replacements))
+			}
+			r := event.Replacements[0]
+			if r.TxID != orig.TxHash().String() || r.ReplacedBy != repl.TxHash().String() {
+				t.Errorf("replacement %s by %s", r.TxID, r.ReplacedBy)
+			}
+			if r.Fee != 200 || r.NewFee != tt.newFee || r.FeeDelta != tt.newFee-200 {
+				t.Errorf("fees %d -> %d (delta %d)", r.Fee, r.NewFee, r.FeeDelta)
+			}
+			if r.FullRBF != tt.wantFullRBF {
+				t.Errorf("full RBF %v, want %v", r.FullRBF, tt.wantFullRBF)
+			}
+
+			wantEvictions := map[string]string{orig.TxHash().String(): exptypes.EvictionReplaced}
+			if tt.withChild {
+				wantEvictions[child.TxHash().String()] = exptypes.EvictionConflict
+			}
+			if len(event.Evictions) != len(wantEvictions) {
+				t.Fatalf("%d evictions, want %d", len(event.Evictions), len(wantEvictions))
+			}
+			for _, ev := range event.Evictions {
+				if wantEvictions[ev.TxID] != ev.Reason {
+					t.Errorf("eviction of %s for %q, want %q", ev.TxID, ev.Reason, wantEvictions[ev.TxID])
+				}
+				if _, ok := removed[ev.TxID]; !ok {
+					t.Errorf("evicted %s not removed", ev.TxID)
+				}
+				h, _ := chainhash.NewHashFromStr(ev.TxID)
+				if p.graph.nodes[*h] != nil {
+					t.Errorf("evicted %s still in the graph", ev.TxID)
+				}
+			}
+			if len(removed) != len(wantEvictions) {
+				t.Errorf("%d removed, want %d", len(removed), len(wantEvictions))
+			}
+			if _, ok := affected[repl.TxHash().String()]; !ok {
+				t.Error("replacement not affected")
+			}
+
+			node := p.graph.nodes[repl.TxHash()]
+			if node == nil {
+				t.Fatal("replacement not in the graph")
+			}
+			if !equalStrings(node.replaces, txids(orig)) {
+				t.Errorf("replaces %v", node.replaces)
+			}
+			if p.graph.spends[confirmed(1)] != repl.TxHash() {
+				t.Error("outpoint not spent by the replacement")
+			}
+			if p.graph.nodes[other.TxHash()] == nil {
+				t.Error("unrelated transaction removed")
+			}
+			if len(p.replacements) != 1 || len(p.evictions) != len(wantEvictions) {
+				t.Errorf("history has %d replacements, %d evictions", len(p.replacements), len(p.evictions))
+			}
+		})
+	}
+}
+
+func TestTxGraphAncestorFeeRate(t *testing.T) {
+	parent := testTx(1, seqRBF, 2, confirmed(1))
+	child := testTx(2, seqRBF, 1, spend(parent, 0))
+	sibling := testTx(3, seqRBF, 1, spend(parent, 1))
+	grandchild := testTx(4, seqRBF, 1, spend(child, 0))
+	fees := map[chainhash.Hash]int64{
+		parent.TxHash():     100,
+		child.TxHash():      2000,
+		sibling.TxHash():    50,
+		grandchild.TxHash(): 10,
+	}
+	vsize := txVSize(parent)
+	rate := func(txs ...*wire.MsgTx) float64 {
+		var fee, vs int64
+		for _, tx := range txs {
+			fee += fees[tx.TxHash()]
+			vs += txVSize(tx)
+		}
+		return float64(fee) / float64(vs)
+	}
+
+	tests := []struct {
+		name          string
+		order         []*wire.MsgTx
+		tx            *wire.MsgTx
+		wantAncestors []string
+		wantRate      float64
+		wantEffective float64
+	}{
+		{"lone parent", []*wire.MsgTx{parent}, parent, nil,
+			100 / float64(vsize), 100 / float64(vsize)},
+		{"parent paid by child", []*wire.MsgTx{parent, child}, parent, nil,
+			rate(parent), rate(parent, child)},
+		{"child", []*wire.MsgTx{parent, child}, child, txids(parent),
+			rate(parent, child), rate(parent, child)},
+		{"child before parent", []*wire.MsgTx{child, parent}, parent, nil,
+			rate(parent), rate(parent, child)},
+		{"low fee sibling", []*wire.MsgTx{parent, sibling}, sibling, txids(parent),
+			rate(parent, sibling), rate(parent, sibling)},
+		{"grandchild", []*wire.MsgTx{parent, child, grandchild}, grandchild, txids(parent, child),
+			rate(parent, child, grandchild), rate(parent, child, grandchild)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTxGraph()
+			for _, tx := range tt.order {
+				g.add(tx, fees[tx.TxHash()], 0)
+			}
+			node := g.nodes[tt.tx.TxHash()]
+			if got := hashes(g.ancestors(node)); !equalStrings(got, tt.wantAncestors) {
+				t.Errorf("ancestors %v, want %v", got, tt.wantAncestors)
+			}
+			if got := g.ancestorFeeRate(node); math.Abs(got-tt.wantRate) > 1e-9 {
+				t.Errorf("ancestor fee rate %f, want %f", got, tt.wantRate)
+			}
+			if got := g.txPackage(node).EffectiveFeeRate; math.Abs(got-tt.wantEffective) > 1e-9 {
+				t.Errorf("effective fee rate %f, want %f", got, tt.wantEffective)
+			}
+		})
+	}
+}
+
+func TestTxGraphRemoveWithDescendants(t *testing.T) {
+	a := testTx(1, seqRBF, 2, confirmed(1))
+	b := testTx(2, seqRBF, 1, spend(a, 0))
+	c := testTx(3, seqRBF, 1, spend(b, 0))
+	d := testTx(4, seqRBF, 1, spend(a, 1))
+	e := testTx(5, seqRBF, 1, spend(c, 0), spend(d, 0))
+	all := []*wire.MsgTx{a, b, c, d, e}
+
+	tests := []struct {
+		name        string
+		tx          *wire.MsgTx
+		wantRemoved []string
+		wantLeft    []string
+	}{
+		{"leaf", e, nil, txids(a, b, c, d)},
+		{"chain", c, txids(e), txids(a, b, d)},
+		{"shared descendant", d, txids(e), txids(a, b, c)},
+		{"root", a, txids(b, c, d, e), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTxGraph()
+			for _, tx := range all {
+				g.add(tx, 100, 0)
+			}
+			removed := g.removeWithDescendants(g.nodes[tt.tx.TxHash()])
+			if got := hashes(removed); !equalStrings(got, tt.wantRemoved) {
+				t.Errorf("removed %v, want %v", got, tt.wantRemoved)
+			}
+			left := make([]*txNode, 0, len(g.nodes))
+			for _, n := range g.nodes {
+				left = append(left, n)
+				for h := range n.children {
+					if g.nodes[h] == nil {
+						t.Errorf("%s has removed child %s", n.hash, h)
+					}
+				}
+				for h := range n.parents {
+					if g.nodes[h] == nil {
+						t.Errorf("%s has removed parent %s", n.hash, h)
+					}
+				}
+			}
+			if got := hashes(left); !equalStrings(got, tt.wantLeft) {
+				t.Errorf("left %v, want %v", got, tt.wantLeft)
+			}
+			for op, spender := range g.spends {
+				if g.nodes[spender] == nil {
+					t.Errorf("outpoint %v spent by removed %s", op, spender)
+				}
+			}
+		})
+	}
+}
+
+func TestRemoveFromAddrStore(t *testing.T) {
+	replaced, replacement := chainhash.Hash{1}, chainhash.Hash{2}
+	store := txhelpers.BTCMempoolAddressStore{
+		// Paid only by the replaced transaction.
+		"a": {
+			Outpoints: []*wire.OutPoint{{Hash: replaced, Index: 0}},
+			TxnsStore: map[chainhash.Hash]*txhelpers.BTCTxWithBlockData{replaced: {}},
+		},
+		// Spent from by both.
+		"b": {
+			PrevOuts: []txhelpers.BTCPrevOut{{TxSpending: replaced}, {TxSpending: replacement}},
+			TxnsStore: map[chainhash.Hash]*txhelpers.BTCTxWithBlockData{
+				replaced: {}, replacement: {},
+			},
+		},
+	}
+	removeFromAddrStore(store, map[chainhash.Hash]struct{}{replaced: {}})
+	if _, ok := store["a"]; ok {
+		t.Error("address of the replaced transaction only is kept")
+	}
+	b := store["b"]
+	if b == nil || len(b.PrevOuts) != 1 || b.PrevOuts[0].TxSpending != replacement ||
+		len(b.TxnsStore) != 1 || b.TxnsStore[replacement] == nil {
+		t.Errorf("unexpected address outpoints %+v", b)
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package mempoolbtc
+
+import (
+	"bytes"
+	"container/heap"
+	"sort"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	exptypes "github.com/decred/dcrdata/v8/explorer/types"
+)
+
+const (
+	// DefaultProjectedBlocks is the number of blocks projected from the
+	// mempool. The last projected block holds all of the remaining
+	// transactions.
+	DefaultProjectedBlocks = 8
+
+	// maxProjectedBlockVSize is the virtual size of a full block, less room
+	// for the coinbase transaction.
+	maxProjectedBlockVSize = blockchain.MaxBlockWeight/blockchain.WitnessScaleFactor - 1000
+
+	// fullBlockMargin is the unused virtual size below which a projected
+	// block is considered full.
+	fullBlockMargin = 4000
+
+	// maxPackageFailures is the number of packages that may fail to fit in a
+	// nearly full block before the block is closed.
+	maxPackageFailures = 1000
+
+	// minRelayFeeRate is the default minimum relay fee rate, in atoms/vB.
+	minRelayFeeRate = 1.0
+)
+
+// feeHistogramRates are the lower bounds of the fee histogram bins, in
+// atoms/vB.
+var feeHistogramRates = []float64{0, 1, 2, 3, 4, 5, 6, 8, 10, 12, 15, 20, 30,
+	40, 50, 60, 70, 80, 90, 100, 125, 150, 175, 200, 250, 300, 350, 400, 500,
+	600, 700, 800, 900, 1000, 1200, 1400, 1600, 1800, 2000}
+
+// feeEstimateTargets are the confirmation targets of the fee estimates, in
+// blocks.
+var feeEstimateTargets = []int{1, 2, 3, 6}
+
+// packageEntry is a transaction and its ancestors that are not yet in a
+// projected block.
+type packageEntry struct {
+	node    *txNode
+	fee     int64
+	vsize   int64
+	version int
+}
+
+func (e *packageEntry) rate() float64 {
+	if e.vsize == 0 {
+		return 0
+	}
+	return float64(e.fee) / float64(e.vsize)
+}
+
+// packageHeap is a max heap of packages by ancestor fee rate.
+type packageHeap []*packageEntry
+
+func (h packageHeap) Len() int { return len(h) }
+func (h packageHeap) Less(i, j int) bool {
+	ri, rj := h[i].rate(), h[j].rate()
+	if ri != rj {
+		return ri > rj
+	}
+	return bytes.Compare(h[i].node.hash[:], h[j].node.hash[:]) < 0
+}
+func (h packageHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *packageHeap) Push(x any)   { *h = append(*h, x.(*packageEntry)) }
+func (h *packageHeap) Pop() any {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return e
+}
+
+// projectedTx is a transaction in a projected block. The fee rate is the rate
+// of the package it was included with.
+type projectedTx struct {
+	fee     int64
+	vsize   int64
+	feeRate float64
+}
+
+// blockAssembler packs the transactions of a txGraph into blocks by ancestor
+// fee rate, similar to the node's block template construction. Packages are
+// updated as their ancestors are included.
+type blockAssembler struct {
+	graph    *txGraph
+	included map[chainhash.Hash]struct{}
+	versions map[chainhash.Hash]int
+	heap     packageHeap
+}
+
+// pending returns the ancestors of the transaction that are not yet included.
+func (b *blockAssembler) pending(node *txNode) []*txNode {
+	var out []*txNode
+	for _, a := range b.graph.ancestors(node) {
+		if _, ok := b.included[a.hash]; !ok {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+func (b *blockAssembler) push(node *txNode) {
+	e := &packageEntry{
+		node:    node,
+		fee:     node.fee,
+		vsize:   node.vsize,
+		version: b.versions[node.hash],
+	}
+	for _, a := range b.pending(node) {
+		e.fee += a.fee
+		e.vsize += a.vsize
+	}
+	heap.Push(&b.heap, e)
+}
+
+// include adds the package to the block and updates the packages of the
+// descendants.
+func (b *blockAssembler) include(e *packageEntry) []projectedTx {
+	nodes := append(b.pending(e.node), e.node)
+	// A transaction has more ancestors than any of its ancestors, so this is
+	// a valid order within a block.
+	sort.Slice(nodes, func(i, j int) bool {
+		return len(b.graph.ancestors(nodes[i])) < len(b.graph.ancestors(nodes[j]))
+	})
+	rate := e.rate()
+	txs := make([]projectedTx, 0, len(nodes))
+	for _, n := range nodes {
+		b.included[n.hash] = struct{}{}
+		txs = append(txs, projectedTx{n.fee, n.vsize, rate})
+	}
+	updated := make(map[chainhash.Hash]*txNode)
+	for _, n := range nodes {
+		for _, d := range b.graph.descendants(n) {
+			if _, ok := b.included[d.hash]; !ok {
+				updated[d.hash] = d
+			}
+		}
+	}
+	for h, d := range updated {
+		b.versions[h]++
+		b.push(d)
+	}
+	return txs
+}
+
+// projectBlocks packs the mempool into at most n blocks. The last block has
+// no size limit.
+func (g *txGraph) projectBlocks(n int) [][]projectedTx {
+	b := &blockAssembler{
+		graph:    g,
+		included: make(map[chainhash.Hash]struct{}, len(g.nodes)),
+		versions: make(map[chainhash.Hash]int, len(g.nodes)),
+		heap:     make(packageHeap, 0, len(g.nodes)),
+	}
+	for _, node := range g.nodes {
+		b.push(node)
+	}
+
+	var blocks [][]projectedTx
+	for len(blocks) < n && b.heap.Len() > 0 {
+		last := len(blocks) == n-1
+		var block []projectedTx
+		var size int64
+		var deferred []*packageEntry
+		for b.heap.Len() > 0 {
+			e := heap.Pop(&b.heap).(*packageEntry)
+			if _, done := b.included[e.node.hash]; done || e.version != b.versions[e.node.hash] {
+				continue // stale
+			}
+			if !last && size+e.vsize > maxProjectedBlockVSize {
+				deferred = append(deferred, e)
+				if len(deferred) > maxPackageFailures && size > maxProjectedBlockVSize-fullBlockMargin {
+					break
+				}
+				continue
+			}
+			block = append(block, b.include(e)...)
+			size += e.vsize
+		}
+		for _, e := range deferred {
+			heap.Push(&b.heap, e)
+		}
+		if len(block) == 0 {
+			break
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// feeHistogramBin returns the index of the fee histogram bin of the fee rate.
+// Rates below the first bin, such as those of transactions with an unknown
+// fee, are counted in the first bin.
+func feeHistogramBin(rate float64) int {
+	bin := sort.SearchFloat64s(feeHistogramRates, rate)
+	if bin == len(feeHistogramRates) || feeHistogramRates[bin] > rate {
+		bin--
+	}
+	if bin < 0 {
+		return 0
+	}
+	return bin
+}
+
+// projectedMempool projects the next n blocks and computes the fee rate
+// histogram and fee estimates. targetTimePerBlock is used for the expected
+// wait of the estimates.
+func (g *txGraph) projectedMempool(n int, targetTimePerBlock time.Duration) *exptypes.MutilchainProjectedMempool {
+	proj := &exptypes.MutilchainProjectedMempool{
+		Time:      time.Now().Unix(),
+		Blocks:    []exptypes.MempoolProjectedBlock{},
+		Histogram: make([]exptypes.MempoolFeeHistogramBin, len(feeHistogramRates)),
+	}
+	for i, rate := range feeHistogramRates {
+		proj.Histogram[i].FeeRate = rate
+	}
+
+	for i, txs := range g.projectBlocks(n) {
+		block := exptypes.MempoolProjectedBlock{
+			Index:   i,
+			TxCount: len(txs),
+		}
+		rates := make([]float64, 0, len(txs))
+		for _, tx := range txs {
+			block.VSize += tx.vsize
+			block.TotalFee += tx.fee
+			rates = append(rates, tx.feeRate)
+
+			bin := feeHistogramBin(tx.feeRate)
+			proj.Histogram[bin].TxCount++
+			proj.Histogram[bin].VSize += tx.vsize
+		}
+		sort.Float64s(rates)
+		block.MinFeeRate = rates[0]
+		block.MaxFeeRate = rates[len(rates)-1]
+		block.MedianFeeRate = rates[len(rates)/2]
+		proj.TxCount += block.TxCount
+		proj.VSize += block.VSize
+		proj.Blocks = append(proj.Blocks, block)
+	}
+
+	for _, target := range feeEstimateTargets {
+		if target > n {
+			break
+		}
+		est := exptypes.MempoolFeeEstimate{
+			Blocks:  target,
+			FeeRate: minRelayFeeRate,
+			Minutes: float64(target) * targetTimePerBlock.Minutes(),
+		}
+		// Outbid the lowest rate in the target block if it is full.
+		if target <= len(proj.Blocks) {
+			block := proj.Blocks[target-1]
+			if block.VSize > maxProjectedBlockVSize-fullBlockMargin && block.MinFeeRate > est.FeeRate {
+				est.FeeRate = block.MinFeeRate
+			}
+		}
+		proj.Estimates = append(proj.Estimates, est)
+	}
+	return proj
+}
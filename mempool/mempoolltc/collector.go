@@ -16,6 +16,7 @@ import (
 	"github.com/ltcsuite/ltcd/chaincfg"
 	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
 	"github.com/ltcsuite/ltcd/ltcutil"
+	"github.com/ltcsuite/ltcd/wire"
 )
 
 // txhelpers.VerboseTransactionPromiseGetter.
@@ -26,6 +27,8 @@ type NodeClient interface {
 	txhelpers.LTCRawTransactionGetter
 	txhelpers.LTCVerboseTransactionGetter
 	GetBlockHeaderVerbose(hash *chainhash.Hash) (*btcjson.GetBlockHeaderVerboseResult, error)
+	GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error)
+	GetMempoolEntry(txHash string) (*btcjson.GetMempoolEntryResult, error)
 }
 
 // DataCollector is used for retrieving and processing data from a chain
@@ -0,0 +1,63 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package mempoolltc
+
+import (
+	"sync"
+	"time"
+)
+
+// inflowWindow is the period over which the mempool inflow rate is averaged.
+const inflowWindow = 2 * time.Minute
+
+type txArrival struct {
+	t     time.Time
+	vsize int64
+}
+
+// inflowMeter measures the rate at which transactions enter mempool, in
+// virtual bytes per second, over the trailing inflowWindow.
+type inflowMeter struct {
+	mtx      sync.Mutex
+	start    time.Time
+	arrivals []txArrival
+	total    int64
+}
+
+func newInflowMeter() *inflowMeter {
+	return &inflowMeter{start: time.Now()}
+}
+
+// prune removes the arrivals before the window ending at now.
+func (m *inflowMeter) prune(now time.Time) {
+	cutoff := now.Add(-inflowWindow)
+	var i int
+	for i < len(m.arrivals) && m.arrivals[i].t.Before(cutoff) {
+		m.total -= m.arrivals[i].vsize
+		i++
+	}
+	m.arrivals = m.arrivals[i:]
+}
+
+// add records a transaction of virtual size vsize entering mempool at t.
+func (m *inflowMeter) add(t time.Time, vsize int64) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.arrivals = append(m.arrivals, txArrival{t, vsize})
+	m.total += vsize
+	m.prune(t)
+}
+
+// rate is the inflow in virtual bytes per second at now. The rate is averaged
+// over the time since the meter started until the window is full.
+func (m *inflowMeter) rate(now time.Time) float64 {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.prune(now)
+	period := min(now.Sub(m.start), inflowWindow)
+	if period < time.Second {
+		return 0
+	}
+	return float64(m.total) / period.Seconds()
+}
@@ -12,10 +12,14 @@ import (
 	"time"
 
 	exptypes "github.com/decred/dcrdata/v8/explorer/types"
+	"github.com/decred/dcrdata/v8/mutilchain"
+	pstypes "github.com/decred/dcrdata/v8/pubsub/types"
 	"github.com/decred/dcrdata/v8/txhelpers"
 	"github.com/ltcsuite/ltcd/btcjson"
 	"github.com/ltcsuite/ltcd/chaincfg"
 	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/ltcutil"
+	"github.com/ltcsuite/ltcd/wire"
 )
 
 // MempoolDataSaver is an interface for storing mempool data.
@@ -38,7 +42,8 @@ type MempoolAddressStore struct {
 // perform the collection and parsing, and an optional []MempoolDataSaver is
 // used to to forward the data to arbitrary destinations. The last block's
 // height, hash, and time are kept in memory in order to properly process votes
-// in mempool.
+// in mempool. Replacements (RBF), evictions and the ancestor/descendant
+// packages of the mempool transactions are tracked in a txGraph.
 type MempoolMonitor struct {
 	mtx        sync.RWMutex
 	ctx        context.Context
@@ -50,15 +55,35 @@ type MempoolMonitor struct {
 	params     *chaincfg.Params
 	collector  *DataCollector
 	dataSavers []MempoolDataSaver
+	signalOuts []chan<- pstypes.HubMessage
+
+	// graphMtx protects graph and the replacement and eviction histories.
+	// graphVersion is incremented when the graph changes.
+	graphMtx     sync.RWMutex
+	graph        *txGraph
+	graphVersion uint64
+	replacements []exptypes.MempoolReplacement
+	evictions    []exptypes.MempoolEviction
+
+	// projectedMtx protects the projected blocks computed from the graph
+	// version projectedVersion.
+	projectedMtx     sync.Mutex
+	projected        *exptypes.MutilchainProjectedMempool
+	projectedVersion uint64
+
+	// inflow measures the rate of new transactions entering mempool.
+	inflow *inflowMeter
 }
 
 // NewMempoolMonitor creates a new MempoolMonitor. The MempoolMonitor receives
 // notifications of new transactions on newTxInChan, and of new blocks on the
 // same channel using a nil transaction message. Once TxHandler is started, the
 // MempoolMonitor will process incoming transactions, and forward new ones on
-// via the newTxOutChan following an appropriate signal on hubRelay.
+// via the newTxOutChan following an appropriate signal on hubRelay. Mempool
+// events (replacements, evictions and CPFP) are sent on signalOuts.
 func NewMempoolMonitor(ctx context.Context, collector *DataCollector,
-	savers []MempoolDataSaver, params *chaincfg.Params, initialStore bool) (*MempoolMonitor, error) {
+	savers []MempoolDataSaver, params *chaincfg.Params,
+	signalOuts []chan<- pstypes.HubMessage, initialStore bool) (*MempoolMonitor, error) {
 
 	// Make the skeleton MempoolMonitor.
 	p := &MempoolMonitor{
@@ -66,6 +91,8 @@ func NewMempoolMonitor(ctx context.Context, collector *DataCollector,
 		params:     params,
 		collector:  collector,
 		dataSavers: savers,
+		signalOuts: signalOuts,
+		inflow:     newInflowMeter(),
 	}
 
 	if initialStore {
@@ -96,6 +123,14 @@ func (p *MempoolMonitor) LastBlockTime() int64 {
 	return p.lastBlock.Time
 }
 
+// BlockHandler satisfies notification.LtcBlockHandler. The mempool is collected
+// fresh after each new block, and transactions that left the mempool without
+// being mined are recorded as evicted.
+func (p *MempoolMonitor) BlockHandler(bh *mutilchain.LtcBlockHeader) error {
+	log.Debugf("New LTC block at height %d - starting CollectAndStore...", bh.Height)
+	return p.CollectAndStore()
+}
+
 // TxHandler receives signals from OnTxAccepted via the newTxIn, indicating that
 // a new transaction has entered mempool. This function should be launched as a
 // goroutine, and stopped by closing the quit channel, the broadcasting
@@ -163,7 +198,33 @@ func (p *MempoolMonitor) TxHandler(rawTx *btcjson.TxRawResult) error {
 		"%d out addrs (%d new), %d prev out addrs (%d new).", hash, newOuts, newPrevOuts,
 		len(addressesOut), newOutAddrs, len(addressesIn), newInAddrs)
 
-	fee, feeRate := txhelpers.LTCTxFeeRate(msgTx, p.collector.ltcdChainSvr)
+	now := time.Now()
+	p.inflow.add(now, txVSize(msgTx))
+
+	// Remove conflicting transactions, which this one replaces, and add it to
+	// the package graph. A transaction with an unknown fee is left out of the
+	// graph until it is rebuilt with the fees reported by the node.
+	var events *exptypes.MutilchainMempoolEvent
+	var removed map[string]struct{}
+	affected := map[string]struct{}{}
+	fee, feeRate, err := p.txFee(msgTx)
+	if err != nil {
+		log.Warnf("Unable to determine the fee of transaction %s: %v", hash, err)
+	} else {
+		events, removed, affected = p.addToGraph(msgTx, int64(fee), rawTx.Time)
+	}
+	if len(removed) > 0 {
+		gone := make(map[chainhash.Hash]struct{}, len(removed))
+		for txid := range removed {
+			if h, err := chainhash.NewHashFromStr(txid); err == nil {
+				delete(p.txnsStore, *h)
+				gone[*h] = struct{}{}
+			}
+		}
+		p.addrMap.mtx.Lock()
+		removeFromAddrStore(p.addrMap.store, gone)
+		p.addrMap.mtx.Unlock()
+	}
 
 	tx := exptypes.MempoolTx{
 		TxID:      hash,
@@ -180,16 +241,367 @@ func (p *MempoolMonitor) TxHandler(rawTx *btcjson.TxRawResult) error {
 		TotalOut: txhelpers.LTCTotalOutFromMsgTx(msgTx).ToBTC(),
 	}
 
+	if len(removed) > 0 {
+		kept := p.inventory.Transactions[:0]
+		for _, mtx := range p.inventory.Transactions {
+			if _, gone := removed[mtx.TxID]; gone {
+				p.inventory.TotalSize -= mtx.Size
+				p.inventory.TotalFee -= mtx.Fees
+				p.inventory.TotalOut -= mtx.TotalOut
+				p.inventory.OutputsCount -= int64(mtx.VoutCount)
+				continue
+			}
+			kept = append(kept, mtx)
+		}
+		p.inventory.Transactions = kept
+	}
+
 	p.inventory.Transactions = append([]exptypes.MempoolTx{tx}, p.inventory.Transactions...)
 	p.inventory.TotalTransactions = int64(len(p.inventory.Transactions))
-	p.inventory.OutputsCount += 1
+	p.inventory.OutputsCount += int64(tx.VoutCount)
+	p.inventory.TotalSize += tx.Size
+	p.inventory.TotalFee += tx.Fees
+	p.inventory.TotalOut += tx.TotalOut
 	// Update latest transactions, popping the oldest transaction off
 	p.inventory.FormattedTotalSize = exptypes.BytesString(uint64(p.inventory.TotalSize))
+	p.inventory.VBytesPerSecond = p.inflow.rate(now)
+	p.graphMtx.RLock()
+	p.graph.annotate(p.inventory.Transactions, affected)
+	p.graphMtx.RUnlock()
 	p.inventory.Unlock()
 	p.mtx.RUnlock()
+
+	if events != nil {
+		p.hubSend(pstypes.SigLTCMempoolEvents, events, time.Second*10)
+	}
+	return nil
+}
+
+// removeFromAddrStore removes the outpoints and previous outpoints of the
+// removed transactions from the address store, and the addresses left without
+// any.
+func removeFromAddrStore(store txhelpers.LTCMempoolAddressStore, removed map[chainhash.Hash]struct{}) {
+	for addr, outs := range store {
+		keptOuts := outs.Outpoints[:0]
+		for _, op := range outs.Outpoints {
+			if _, gone := removed[op.Hash]; !gone {
+				keptOuts = append(keptOuts, op)
+			}
+		}
+		outs.Outpoints = keptOuts
+		keptPrevOuts := outs.PrevOuts[:0]
+		for _, prevOut := range outs.PrevOuts {
+			if _, gone := removed[prevOut.TxSpending]; !gone {
+				keptPrevOuts = append(keptPrevOuts, prevOut)
+			}
+		}
+		outs.PrevOuts = keptPrevOuts
+		for hash := range removed {
+			delete(outs.TxnsStore, hash)
+		}
+		if len(outs.Outpoints) == 0 && len(outs.PrevOuts) == 0 {
+			delete(store, addr)
+		}
+	}
+}
+
+// txFee computes the fee and fee rate of msgTx from the values of the
+// previous outputs it spends, or from the fee of its mempool entry if they
+// cannot be retrieved.
+func (p *MempoolMonitor) txFee(msgTx *wire.MsgTx) (ltcutil.Amount, ltcutil.Amount, error) {
+	fee, feeRate, err := txhelpers.LTCTxFee(msgTx, p.collector.ltcdChainSvr)
+	if err == nil {
+		return fee, feeRate, nil
+	}
+	entry, errEntry := p.collector.ltcdChainSvr.GetMempoolEntry(msgTx.TxHash().String())
+	if errEntry != nil {
+		return 0, 0, fmt.Errorf("%v; GetMempoolEntry: %w", err, errEntry)
+	}
+	fee, err = ltcutil.NewAmount(entry.Fees.Base)
+	if err != nil {
+		return 0, 0, err
+	}
+	if fee < 0 {
+		return 0, 0, fmt.Errorf("negative mempool entry fee %v", fee)
+	}
+	feeRate = ltcutil.Amount(txhelpers.FeeRate(int64(fee), 0, int64(msgTx.SerializeSize())))
+	return fee, feeRate, nil
+}
+
+// addToGraph records the replacements of any transactions conflicting with
+// msgTx, removes them and their descendants, and adds msgTx to the package
+// graph. The removed txids and the txids whose package data changed are
+// returned, along with the mempool event to send, if any.
+func (p *MempoolMonitor) addToGraph(msgTx *wire.MsgTx, fee, t int64) (*exptypes.MutilchainMempoolEvent,
+	map[string]struct{}, map[string]struct{}) {
+	p.graphMtx.Lock()
+	defer p.graphMtx.Unlock()
+	if p.graph == nil {
+		p.graph = newTxGraph()
+	}
+	p.graphVersion++
+
+	hash := msgTx.TxHash().String()
+	now := time.Now().Unix()
+	event := &exptypes.MutilchainMempoolEvent{ChainType: mutilchain.TYPELTC}
+	removed := make(map[string]struct{})
+	var replaced []string
+	for _, c := range p.graph.conflicts(msgTx) {
+		txid := c.hash.String()
+		replaced = append(replaced, txid)
+		event.Replacements = append(event.Replacements, exptypes.MempoolReplacement{
+			TxID:       txid,
+			ReplacedBy: hash,
+			Fee:        c.fee,
+			NewFee:     fee,
+			FeeDelta:   fee - c.fee,
+			FullRBF:    !c.rbf,
+			Time:       now,
+		})
+		event.Evictions = append(event.Evictions, exptypes.MempoolEviction{
+			TxID:   txid,
+			Reason: exptypes.EvictionReplaced,
+			Time:   now,
+		})
+		removed[txid] = struct{}{}
+		for _, d := range p.graph.removeWithDescendants(c) {
+			dtxid := d.hash.String()
+			event.Evictions = append(event.Evictions, exptypes.MempoolEviction{
+				TxID:   dtxid,
+				Reason: exptypes.EvictionConflict,
+				Time:   now,
+			})
+			removed[dtxid] = struct{}{}
+		}
+	}
+
+	// Effective fee rates of the unconfirmed ancestors before the new child.
+	before := make(map[chainhash.Hash]float64)
+	for _, txIn := range msgTx.TxIn {
+		parent := p.graph.nodes[txIn.PreviousOutPoint.Hash]
+		if parent == nil {
+			continue
+		}
+		before[parent.hash] = p.graph.txPackage(parent).EffectiveFeeRate
+		for _, a := range p.graph.ancestors(parent) {
+			before[a.hash] = p.graph.txPackage(a).EffectiveFeeRate
+		}
+	}
+
+	node := p.graph.add(msgTx, fee, t)
+	node.replaces = replaced
+	affected := map[string]struct{}{hash: {}}
+	for h, rate := range before {
+		anc := p.graph.nodes[h]
+		if anc == nil {
+			continue
+		}
+		affected[h.String()] = struct{}{}
+		if pkg := p.graph.txPackage(anc); pkg.EffectiveFeeRate > rate {
+			event.Accelerated = append(event.Accelerated, pkg)
+		}
+	}
+
+	p.replacements = prependHistory(p.replacements, event.Replacements...)
+	p.evictions = prependHistory(p.evictions, event.Evictions...)
+
+	if len(event.Replacements) == 0 && len(event.Evictions) == 0 && len(event.Accelerated) == 0 {
+		event = nil
+	}
+	return event, removed, affected
+}
+
+// rebuildGraph replaces the package graph with one built from the collected
+// mempool, and records the transactions that left the mempool without being
+// mined since the previous collection.
+func (p *MempoolMonitor) rebuildGraph(txs []exptypes.MempoolTx, txnsStore txhelpers.LTCTxnsStore,
+	prevBlock, blockId *BlockID) *exptypes.MutilchainMempoolEvent {
+	graph := newTxGraph()
+	for i := range txs {
+		hash, err := chainhash.NewHashFromStr(txs[i].TxID)
+		if err != nil {
+			continue
+		}
+		txData := txnsStore[*hash]
+		if txData == nil || txData.Tx == nil {
+			continue
+		}
+		fee, _ := ltcutil.NewAmount(txs[i].Fees)
+		graph.add(txData.Tx, int64(fee), txs[i].Time)
+	}
+	graph.annotate(txs, nil)
+
+	p.graphMtx.Lock()
+	defer p.graphMtx.Unlock()
+	oldGraph := p.graph
+	p.graph = graph
+	p.graphVersion++
+	if oldGraph == nil || prevBlock == nil {
+		return nil
+	}
+
+	// Keep the replacements known to the previous graph.
+	for h, node := range oldGraph.nodes {
+		if n := graph.nodes[h]; n != nil {
+			n.replaces = node.replaces
+		}
+	}
+
+	var gone []*txNode
+	for h, node := range oldGraph.nodes {
+		if _, ok := graph.nodes[h]; !ok {
+			gone = append(gone, node)
+		}
+	}
+	if len(gone) == 0 {
+		return nil
+	}
+
+	mined, blockSpends, ok := p.blockTxns(prevBlock.Height, blockId.Height)
+	if !ok {
+		return nil
+	}
+	now := time.Now().Unix()
+	event := &exptypes.MutilchainMempoolEvent{ChainType: mutilchain.TYPELTC}
+	for _, node := range gone {
+		if _, ok := mined[node.hash]; ok {
+			continue
+		}
+		reason := exptypes.EvictionExpired
+		for _, op := range node.inputs {
+			if _, spent := blockSpends[op]; spent {
+				reason = exptypes.EvictionBlockConflict
+				break
+			}
+		}
+		event.Evictions = append(event.Evictions, exptypes.MempoolEviction{
+			TxID:   node.hash.String(),
+			Reason: reason,
+			Time:   now,
+		})
+	}
+	if len(event.Evictions) == 0 {
+		return nil
+	}
+	p.evictions = prependHistory(p.evictions, event.Evictions...)
+	return event
+}
+
+// maxEvictionCheckBlocks limits the number of blocks fetched to tell mined
+// transactions from evicted ones.
+const maxEvictionCheckBlocks = 6
+
+// blockTxns gets the transactions mined in the blocks after fromHeight up to
+// toHeight, and the outpoints they spend.
+func (p *MempoolMonitor) blockTxns(fromHeight, toHeight int64) (map[chainhash.Hash]struct{}, map[wire.OutPoint]struct{}, bool) {
+	if toHeight < fromHeight || toHeight-fromHeight > maxEvictionCheckBlocks {
+		return nil, nil, false
+	}
+	mined := make(map[chainhash.Hash]struct{})
+	spends := make(map[wire.OutPoint]struct{})
+	for height := fromHeight + 1; height <= toHeight; height++ {
+		hash, err := p.collector.ltcdChainSvr.GetBlockHash(height)
+		if err != nil {
+			log.Debugf("GetBlockHash(%d) failed: %v", height, err)
+			return nil, nil, false
+		}
+		block, err := p.collector.ltcdChainSvr.GetBlock(hash)
+		if err != nil {
+			log.Debugf("GetBlock(%v) failed: %v", hash, err)
+			return nil, nil, false
+		}
+		for _, tx := range block.Transactions {
+			mined[tx.TxHash()] = struct{}{}
+			for _, txIn := range tx.TxIn {
+				spends[txIn.PreviousOutPoint] = struct{}{}
+			}
+		}
+	}
+	return mined, spends, true
+}
+
+func (p *MempoolMonitor) hubSend(sig pstypes.HubSignal, msg interface{}, timeout time.Duration) {
+	for _, sigout := range p.signalOuts {
+		select {
+		case sigout <- pstypes.HubMessage{Signal: sig, Msg: msg}:
+		case <-time.After(timeout):
+			log.Errorf("send to signalOuts (%v) failed: Timeout waiting for WebsocketHub.", sig)
+		}
+	}
+}
+
+// Replacements returns the most recent mempool replacements, newest first.
+func (p *MempoolMonitor) Replacements() []exptypes.MempoolReplacement {
+	p.graphMtx.RLock()
+	defer p.graphMtx.RUnlock()
+	out := make([]exptypes.MempoolReplacement, len(p.replacements))
+	copy(out, p.replacements)
+	return out
+}
+
+// Evictions returns the most recent mempool evictions, newest first.
+func (p *MempoolMonitor) Evictions() []exptypes.MempoolEviction {
+	p.graphMtx.RLock()
+	defer p.graphMtx.RUnlock()
+	out := make([]exptypes.MempoolEviction, len(p.evictions))
+	copy(out, p.evictions)
+	return out
+}
+
+// ReplacedBy returns the replacement record for the transaction if it was
+// replaced recently, or nil.
+func (p *MempoolMonitor) ReplacedBy(txid string) *exptypes.MempoolReplacement {
+	p.graphMtx.RLock()
+	defer p.graphMtx.RUnlock()
+	for i := range p.replacements {
+		if p.replacements[i].TxID == txid {
+			r := p.replacements[i]
+			return &r
+		}
+	}
 	return nil
 }
 
+// TxPackage returns the ancestor and descendant package of a transaction in
+// mempool, or nil if the transaction is not in mempool.
+func (p *MempoolMonitor) TxPackage(txid string) *exptypes.MempoolTxPackage {
+	hash, err := chainhash.NewHashFromStr(txid)
+	if err != nil {
+		return nil
+	}
+	p.graphMtx.RLock()
+	defer p.graphMtx.RUnlock()
+	if p.graph == nil {
+		return nil
+	}
+	node := p.graph.nodes[*hash]
+	if node == nil {
+		return nil
+	}
+	return p.graph.txPackage(node)
+}
+
+// ProjectedMempool returns the next blocks projected from the mempool, along
+// with the fee rate histogram and fee estimates. The result is cached until the
+// mempool changes, and must not be modified.
+func (p *MempoolMonitor) ProjectedMempool() *exptypes.MutilchainProjectedMempool {
+	p.projectedMtx.Lock()
+	defer p.projectedMtx.Unlock()
+
+	p.graphMtx.RLock()
+	defer p.graphMtx.RUnlock()
+	if p.graph == nil {
+		return nil
+	}
+	if p.projected != nil && p.projectedVersion == p.graphVersion {
+		return p.projected
+	}
+	proj := p.graph.projectedMempool(DefaultProjectedBlocks, p.params.TargetTimePerBlock)
+	proj.ChainType = mutilchain.TYPELTC
+	p.projected, p.projectedVersion = proj, p.graphVersion
+	return proj
+}
+
 // Refresh collects mempool data, resets counters ticket counters and the timer,
 // but does not dispatch the MempoolDataSavers.
 func (p *MempoolMonitor) Refresh() ([]exptypes.MempoolTx, *exptypes.MutilchainMempoolInfo, error) {
@@ -206,7 +618,18 @@ func (p *MempoolMonitor) Refresh() ([]exptypes.MempoolTx, *exptypes.MutilchainMe
 		len(addrOuts), len(txnsStore))
 	// Pre-sort the txs so other consumers will not have to do it.
 	sort.Sort(exptypes.MPTxsByTime(txs))
+
+	// Rebuild the package graph, which also sets the package fields of txs.
+	var prevBlock *BlockID
+	p.mtx.RLock()
+	if p.inventory != nil {
+		prev := p.lastBlock
+		prevBlock = &prev
+	}
+	p.mtx.RUnlock()
+	event := p.rebuildGraph(txs, txnsStore, prevBlock, blockId)
 	inventory := ParseTxns(txs, p.params, blockId)
+	inventory.VBytesPerSecond = p.inflow.rate(time.Now())
 
 	// Reset the counter for tickets since last report.
 	p.mtx.Lock()
@@ -224,9 +647,19 @@ func (p *MempoolMonitor) Refresh() ([]exptypes.MempoolTx, *exptypes.MutilchainMe
 	p.addrMap.store = addrOuts
 	p.addrMap.mtx.Unlock()
 
+	if event != nil {
+		p.hubSend(pstypes.SigLTCMempoolEvents, event, time.Second*10)
+	}
+
 	return txs, inventory, err
 }
 
+// VBytesPerSecond is the rate at which transactions have recently entered
+// mempool, in virtual bytes per second.
+func (p *MempoolMonitor) VBytesPerSecond() float64 {
+	return p.inflow.rate(time.Now())
+}
+
 // CollectAndStore collects mempool data, resets counters ticket counters and
 // the timer, and dispatches the storers.
 func (p *MempoolMonitor) CollectAndStore() error {
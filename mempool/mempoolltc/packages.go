@@ -0,0 +1,274 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package mempoolltc
+
+import (
+	"sort"
+
+	exptypes "github.com/decred/dcrdata/v8/explorer/types"
+	"github.com/decred/dcrdata/v8/txhelpers"
+	"github.com/ltcsuite/ltcd/blockchain"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/ltcutil"
+	"github.com/ltcsuite/ltcd/wire"
+)
+
+// maxMempoolHistory is the number of replacements and evictions remembered by
+// the MempoolMonitor.
+const maxMempoolHistory = 1000
+
+// txNode is a transaction in the mempool package graph.
+type txNode struct {
+	hash     chainhash.Hash
+	fee      int64
+	vsize    int64
+	time     int64
+	rbf      bool
+	inputs   []wire.OutPoint
+	replaces []string
+	parents  map[chainhash.Hash]*txNode
+	children map[chainhash.Hash]*txNode
+}
+
+// txGraph tracks the outpoints spent by mempool transactions, and the parent
+// and child relationships between them.
+type txGraph struct {
+	nodes  map[chainhash.Hash]*txNode
+	spends map[wire.OutPoint]chainhash.Hash
+}
+
+func newTxGraph() *txGraph {
+	return &txGraph{
+		nodes:  make(map[chainhash.Hash]*txNode),
+		spends: make(map[wire.OutPoint]chainhash.Hash),
+	}
+}
+
+// txVSize computes the virtual size of the transaction.
+func txVSize(msgTx *wire.MsgTx) int64 {
+	weight := blockchain.GetTransactionWeight(ltcutil.NewTx(msgTx))
+	return (weight + blockchain.WitnessScaleFactor - 1) / blockchain.WitnessScaleFactor
+}
+
+// signalsRBF checks for BIP-125 opt-in replaceability.
+func signalsRBF(msgTx *wire.MsgTx) bool {
+	for _, txIn := range msgTx.TxIn {
+		if txIn.Sequence <= txhelpers.MaxBIP125Sequence {
+			return true
+		}
+	}
+	return false
+}
+
+// conflicts returns the mempool transactions that spend any of the outpoints
+// spent by msgTx.
+func (g *txGraph) conflicts(msgTx *wire.MsgTx) []*txNode {
+	hash := msgTx.TxHash()
+	seen := make(map[chainhash.Hash]struct{})
+	var nodes []*txNode
+	for _, txIn := range msgTx.TxIn {
+		spender, found := g.spends[txIn.PreviousOutPoint]
+		if !found || spender == hash {
+			continue
+		}
+		if _, dup := seen[spender]; dup {
+			continue
+		}
+		seen[spender] = struct{}{}
+		if node := g.nodes[spender]; node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// add inserts the transaction into the graph. Conflicting transactions must be
+// removed first.
+func (g *txGraph) add(msgTx *wire.MsgTx, fee, t int64) *txNode {
+	node := &txNode{
+		hash:     msgTx.TxHash(),
+		fee:      fee,
+		vsize:    txVSize(msgTx),
+		time:     t,
+		rbf:      signalsRBF(msgTx),
+		parents:  make(map[chainhash.Hash]*txNode),
+		children: make(map[chainhash.Hash]*txNode),
+	}
+	if old := g.nodes[node.hash]; old != nil {
+		return old
+	}
+	for _, txIn := range msgTx.TxIn {
+		op := txIn.PreviousOutPoint
+		node.inputs = append(node.inputs, op)
+		g.spends[op] = node.hash
+		if parent := g.nodes[op.Hash]; parent != nil {
+			node.parents[parent.hash] = parent
+			parent.children[node.hash] = node
+		}
+	}
+	// Children that arrived before this transaction.
+	for i := range msgTx.TxOut {
+		spender, found := g.spends[wire.OutPoint{Hash: node.hash, Index: uint32(i)}]
+		if !found {
+			continue
+		}
+		if child := g.nodes[spender]; child != nil {
+			node.children[child.hash] = child
+			child.parents[node.hash] = node
+		}
+	}
+	g.nodes[node.hash] = node
+	return node
+}
+
+// remove deletes a single transaction from the graph.
+func (g *txGraph) remove(node *txNode) {
+	for _, op := range node.inputs {
+		if g.spends[op] == node.hash {
+			delete(g.spends, op)
+		}
+	}
+	for _, parent := range node.parents {
+		delete(parent.children, node.hash)
+	}
+	for _, child := range node.children {
+		delete(child.parents, node.hash)
+	}
+	delete(g.nodes, node.hash)
+}
+
+// removeWithDescendants deletes the transaction and every transaction that
+// depends on it. The removed descendants are returned.
+func (g *txGraph) removeWithDescendants(node *txNode) []*txNode {
+	desc := g.descendants(node)
+	g.remove(node)
+	removed := make([]*txNode, 0, len(desc))
+	for _, d := range desc {
+		g.remove(d)
+		removed = append(removed, d)
+	}
+	return removed
+}
+
+func (g *txGraph) walk(node *txNode, next func(*txNode) map[chainhash.Hash]*txNode) []*txNode {
+	seen := map[chainhash.Hash]struct{}{node.hash: {}}
+	var out []*txNode
+	stack := []*txNode{node}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for h, m := range next(n) {
+			if _, ok := seen[h]; ok {
+				continue
+			}
+			seen[h] = struct{}{}
+			out = append(out, m)
+			stack = append(stack, m)
+		}
+	}
+	return out
+}
+
+// ancestors returns the in-mempool ancestors of the transaction.
+func (g *txGraph) ancestors(node *txNode) []*txNode {
+	return g.walk(node, func(n *txNode) map[chainhash.Hash]*txNode { return n.parents })
+}
+
+// descendants returns the in-mempool descendants of the transaction.
+func (g *txGraph) descendants(node *txNode) []*txNode {
+	return g.walk(node, func(n *txNode) map[chainhash.Hash]*txNode { return n.children })
+}
+
+// ancestorFeeRate is the fee rate of the transaction together with all of its
+// unconfirmed ancestors, in atoms/vB. This is the rate at which a miner would
+// include the package.
+func (g *txGraph) ancestorFeeRate(node *txNode) float64 {
+	fee, vsize := node.fee, node.vsize
+	for _, a := range g.ancestors(node) {
+		fee += a.fee
+		vsize += a.vsize
+	}
+	if vsize == 0 {
+		return 0
+	}
+	return float64(fee) / float64(vsize)
+}
+
+// txPackage describes the transaction's ancestors and descendants. The
+// effective fee rate is the best ancestor package rate of the transaction or
+// any of its descendants, so a parent paid for by a child (CPFP) is rated at
+// the package rate.
+func (g *txGraph) txPackage(node *txNode) *exptypes.MempoolTxPackage {
+	pkg := &exptypes.MempoolTxPackage{
+		TxID:       node.hash.String(),
+		Fee:        node.fee,
+		VSize:      node.vsize,
+		SignalsRBF: node.rbf,
+		Replaces:   node.replaces,
+	}
+	if node.vsize > 0 {
+		pkg.FeeRate = float64(node.fee) / float64(node.vsize)
+	}
+	for _, a := range g.ancestors(node) {
+		pkg.Ancestors = append(pkg.Ancestors, a.hash.String())
+		pkg.AncestorFee += a.fee
+		pkg.AncestorVSize += a.vsize
+	}
+	pkg.EffectiveFeeRate = g.ancestorFeeRate(node)
+	for _, d := range g.descendants(node) {
+		pkg.Descendants = append(pkg.Descendants, d.hash.String())
+		pkg.DescendantFee += d.fee
+		pkg.DescendantVSize += d.vsize
+		if rate := g.ancestorFeeRate(d); rate > pkg.EffectiveFeeRate {
+			pkg.EffectiveFeeRate = rate
+		}
+	}
+	sort.Strings(pkg.Ancestors)
+	sort.Strings(pkg.Descendants)
+	return pkg
+}
+
+// annotate sets the RBF and package fields of the mempool transactions that
+// are in the graph. If only is non-nil, only those transactions are updated.
+func (g *txGraph) annotate(txs []exptypes.MempoolTx, only map[string]struct{}) {
+	for i := range txs {
+		tx := &txs[i]
+		if only != nil {
+			if _, ok := only[tx.TxID]; !ok {
+				continue
+			}
+		}
+		hash, err := chainhash.NewHashFromStr(tx.TxID)
+		if err != nil {
+			continue
+		}
+		node := g.nodes[*hash]
+		if node == nil {
+			continue
+		}
+		pkg := g.txPackage(node)
+		tx.SignalsRBF = pkg.SignalsRBF
+		tx.AncestorCount = len(pkg.Ancestors)
+		tx.DescendantCount = len(pkg.Descendants)
+		tx.EffectiveFeeRate = pkg.EffectiveFeeRate
+		tx.Replaces = pkg.Replaces
+	}
+}
+
+// prependHistory adds items to the front of a history slice, keeping at most
+// maxMempoolHistory entries.
+func prependHistory[T any](history []T, items ...T) []T {
+	if len(items) == 0 {
+		return history
+	}
+	out := make([]T, 0, len(items)+len(history))
+	for i := len(items) - 1; i >= 0; i-- {
+		out = append(out, items[i])
+	}
+	out = append(out, history...)
+	if len(out) > maxMempoolHistory {
+		out = out[:maxMempoolHistory]
+	}
+	return out
+}
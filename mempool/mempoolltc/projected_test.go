@@ -0,0 +1,82 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package mempoolltc
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFeeHistogramBin(t *testing.T) {
+	last := len(feeHistogramRates) - 1
+	tests := []struct {
+		rate float64
+		want int
+	}{
+		{-5, 0},
+		{0, 0},
+		{0.5, 0},
+		{1, 1},
+		{7, 6},
+		{8, 7},
+		{2000, last},
+		{5000, last},
+		{math.NaN(), last},
+	}
+	for _, tt := range tests {
+		if got := feeHistogramBin(tt.rate); got != tt.want {
+			t.Errorf("feeHistogramBin(%v) = %d, want %d", tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestProjectedMempool(t *testing.T) {
+	paying := testTx(1, seqRBF, 1, confirmed(1))
+	free := testTx(2, seqRBF, 1, confirmed(2))
+	bad := testTx(3, seqRBF, 1, confirmed(3))
+	vsize := txVSize(paying)
+
+	g := newTxGraph()
+	g.add(paying, 10*vsize, 0)
+	g.add(free, 0, 0)
+	g.add(bad, -500, 0)
+
+	blocks := g.projectBlocks(DefaultProjectedBlocks)
+	if len(blocks) != 1 {
+		t.Fatalf("%d projected blocks, want 1", len(blocks))
+	}
+	if len(blocks[0]) != 3 {
+		t.Fatalf("%d projected transactions, want 3", len(blocks[0]))
+	}
+	if blocks[0][0].fee != 10*vsize || blocks[0][0].feeRate != 10 {
+		t.Errorf("first transaction fee %d at %f, want the paying transaction",
+			blocks[0][0].fee, blocks[0][0].feeRate)
+	}
+	if rate := blocks[0][2].feeRate; rate >= 0 {
+		t.Errorf("last transaction rate %f, want the negative rate", rate)
+	}
+
+	proj := g.projectedMempool(DefaultProjectedBlocks, 10*time.Minute)
+	if proj.TxCount != 3 || proj.VSize != 3*vsize {
+		t.Errorf("projected %d transactions of %d vB", proj.TxCount, proj.VSize)
+	}
+	counts := make(map[float64]int)
+	for _, bin := range proj.Histogram {
+		if bin.TxCount > 0 {
+			counts[bin.FeeRate] = bin.TxCount
+		}
+	}
+	if len(counts) != 2 || counts[0] != 2 || counts[10] != 1 {
+		t.Errorf("histogram counts %v, want 2 at 0 and 1 at 10", counts)
+	}
+	if len(proj.Estimates) != len(feeEstimateTargets) {
+		t.Fatalf("%d estimates", len(proj.Estimates))
+	}
+	for _, est := range proj.Estimates {
+		if est.FeeRate != minRelayFeeRate {
+			t.Errorf("%d block estimate %f for a block that is not full", est.Blocks, est.FeeRate)
+		}
+	}
+}
@@ -26,6 +26,7 @@ type APIAddressInfo struct {
 	Sent            int64
 	Unspent         int64
 	NumUnconfirmed  int64
+	Label           string `json:"label,omitempty"`
 }
 
 const (
@@ -0,0 +1,341 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+// Package labels names the addresses of known entities, such as exchanges,
+// mining pools, VSPs and project funds, on the Decred, Bitcoin and Litecoin
+// chains. The labels in labels.json are embedded in the binary. Bump the
+// version in labels.json whenever it changes. The payout addresses of the
+// mining pools are labeled from the pool definitions of the pools package
+// instead of labels.json. The labels added by the
+// operators of a dcrdata instance are merged over the embedded labels by a
+// Set.
+package labels
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/decred/dcrdata/v8/mutilchain"
+	"github.com/decred/dcrdata/v8/mutilchain/pools"
+)
+
+// The categories of the labels.
+const (
+	CategoryExchange = "exchange"
+	CategoryPool     = "pool"
+	CategoryVSP      = "vsp"
+	CategoryDevFund  = "devfund"
+	CategoryTreasury = "treasury"
+	CategoryBurn     = "burn"
+	CategoryOther    = "other"
+)
+
+// The sources of the labels.
+const (
+	SourceFile  = "file"
+	SourceAdmin = "admin"
+)
+
+// MaxNameLength is the maximum length of a label name, in characters.
+const MaxNameLength = 64
+
+// maxAddressLength is the maximum length of a labeled address.
+const maxAddressLength = 128
+
+var categories = map[string]struct{}{
+	CategoryExchange: {},
+	CategoryPool:     {},
+	CategoryVSP:      {},
+	CategoryDevFund:  {},
+	CategoryTreasury: {},
+	CategoryBurn:     {},
+	CategoryOther:    {},
+}
+
+// Chains are the chains with labels, in search order.
+var Chains = []string{mutilchain.TYPEDCR, mutilchain.TYPEBTC, mutilchain.TYPELTC}
+
+// SupportedChain reports whether the chain has labels.
+func SupportedChain(chain string) bool {
+	for _, c := range Chains {
+		if c == chain {
+			return true
+		}
+	}
+	return false
+}
+
+//go:embed labels.json
+var defaultDefinitions []byte
+
+// Label is the name of the entity controlling an address.
+type Label struct {
+	Address  string `json:"address"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Link     string `json:"link,omitempty"`
+	Source   string `json:"source,omitempty"`
+}
+
+// Validate checks the address, name, category and link of a label. A link must
+// be an http or https URL.
+func (l *Label) Validate() error {
+	if l.Address == "" || len(l.Address) > maxAddressLength ||
+		strings.IndexFunc(l.Address, unicode.IsSpace) != -1 {
+		return fmt.Errorf("invalid address %q", l.Address)
+	}
+	if strings.TrimSpace(l.Name) != l.Name || l.Name == "" ||
+		len([]rune(l.Name)) > MaxNameLength {
+		return fmt.Errorf("invalid name %q for address %s", l.Name, l.Address)
+	}
+	if _, ok := categories[l.Category]; !ok {
+		return fmt.Errorf("invalid category %q for address %s", l.Category, l.Address)
+	}
+	if l.Link != "" {
+		u, err := url.Parse(l.Link)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid link %q for address %s", l.Link, l.Address)
+		}
+	}
+	return nil
+}
+
+// Definitions is a versioned set of labels for each chain.
+type Definitions struct {
+	Version int      `json:"version"`
+	Updated string   `json:"updated"`
+	DCR     []*Label `json:"dcr"`
+	BTC     []*Label `json:"btc"`
+	LTC     []*Label `json:"ltc"`
+}
+
+// ParseDefinitions parses labels in the format of labels.json.
+func ParseDefinitions(r io.Reader) (*Definitions, error) {
+	defs := new(Definitions)
+	if err := json.NewDecoder(r).Decode(defs); err != nil {
+		return nil, fmt.Errorf("invalid label definitions: %w", err)
+	}
+	if defs.Version < 1 {
+		return nil, fmt.Errorf("invalid label definitions version %d", defs.Version)
+	}
+	for _, chain := range Chains {
+		addrs := make(map[string]struct{})
+		for _, label := range defs.Labels(chain) {
+			if err := label.Validate(); err != nil {
+				return nil, fmt.Errorf("%s: %w", chain, err)
+			}
+			if _, dup := addrs[label.Address]; dup {
+				return nil, fmt.Errorf("duplicate %s address %s", chain, label.Address)
+			}
+			addrs[label.Address] = struct{}{}
+			label.Source = SourceFile
+		}
+	}
+	return defs, nil
+}
+
+var (
+	defaultOnce sync.Once
+	defaultDefs *Definitions
+)
+
+// Default returns the labels embedded from labels.json, with the payout
+// addresses of the default pool definitions labeled with their pool.
+func Default() *Definitions {
+	defaultOnce.Do(func() {
+		defs, err := ParseDefinitions(bytes.NewReader(defaultDefinitions))
+		if err != nil {
+			panic(err)
+		}
+		defs.addPools(pools.Default())
+		defaultDefs = defs
+	})
+	return defaultDefs
+}
+
+// addPools labels the payout addresses of the pool definitions. The labels of
+// the definitions take precedence.
+func (d *Definitions) addPools(poolDefs *pools.Definitions) {
+	for _, chain := range []string{mutilchain.TYPEBTC, mutilchain.TYPELTC} {
+		list := d.Labels(chain)
+		addrs := make(map[string]struct{}, len(list))
+		for _, label := range list {
+			addrs[label.Address] = struct{}{}
+		}
+		for _, pool := range poolDefs.Pools(chain) {
+			for _, addr := range pool.Addresses {
+				if _, ok := addrs[addr]; ok {
+					continue
+				}
+				addrs[addr] = struct{}{}
+				list = append(list, &Label{
+					Address:  addr,
+					Name:     pool.Name,
+					Category: CategoryPool,
+					Link:     pool.Link,
+					Source:   SourceFile,
+				})
+			}
+		}
+		switch chain {
+		case mutilchain.TYPEBTC:
+			d.BTC = list
+		case mutilchain.TYPELTC:
+			d.LTC = list
+		}
+	}
+}
+
+// Labels returns the labels for the chain.
+func (d *Definitions) Labels(chain string) []*Label {
+	switch chain {
+	case mutilchain.TYPEDCR:
+		return d.DCR
+	case mutilchain.TYPEBTC:
+		return d.BTC
+	case mutilchain.TYPELTC:
+		return d.LTC
+	default:
+		return nil
+	}
+}
+
+// Match is a label found by Search.
+type Match struct {
+	Chain string `json:"chain"`
+	*Label
+}
+
+// Set is the labels of each chain, the labels added by the operators taking
+// precedence over the definitions. It is safe for concurrent use.
+type Set struct {
+	mtx   sync.RWMutex
+	file  map[string]map[string]*Label
+	admin map[string]map[string]*Label
+}
+
+// NewSet creates a Set with the labels of the definitions.
+func NewSet(defs *Definitions) *Set {
+	s := &Set{
+		file:  make(map[string]map[string]*Label, len(Chains)),
+		admin: make(map[string]map[string]*Label, len(Chains)),
+	}
+	for _, chain := range Chains {
+		file := make(map[string]*Label)
+		for _, label := range defs.Labels(chain) {
+			file[label.Address] = label
+		}
+		s.file[chain] = file
+		s.admin[chain] = make(map[string]*Label)
+	}
+	return s
+}
+
+// Put sets the label added by an operator for an address of the chain.
+func (s *Set) Put(chain string, label *Label) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if admin := s.admin[chain]; admin != nil {
+		admin[label.Address] = label
+	}
+}
+
+// Remove removes the label added by an operator for an address of the chain.
+// The label of the definitions, if any, applies again.
+func (s *Set) Remove(chain, address string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.admin[chain], address)
+}
+
+// Lookup returns the label of an address of the chain, or nil.
+func (s *Set) Lookup(chain, address string) *Label {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.lookup(chain, address)
+}
+
+func (s *Set) lookup(chain, address string) *Label {
+	if label := s.admin[chain][address]; label != nil {
+		return label
+	}
+	return s.file[chain][address]
+}
+
+// Name returns the label name of an address of the chain, or an empty string.
+func (s *Set) Name(chain, address string) string {
+	if label := s.Lookup(chain, address); label != nil {
+		return label.Name
+	}
+	return ""
+}
+
+// Names returns the label names of the labeled addresses among addrs.
+func (s *Set) Names(chain string, addrs []string) map[string]string {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	names := make(map[string]string)
+	for _, addr := range addrs {
+		if label := s.lookup(chain, addr); label != nil {
+			names[addr] = label.Name
+		}
+	}
+	return names
+}
+
+// List returns the labels of the chain by name.
+func (s *Set) List(chain string) []*Label {
+	s.mtx.RLock()
+	list := make([]*Label, 0, len(s.file[chain])+len(s.admin[chain]))
+	for _, label := range s.admin[chain] {
+		list = append(list, label)
+	}
+	for addr, label := range s.file[chain] {
+		if _, ok := s.admin[chain][addr]; !ok {
+			list = append(list, label)
+		}
+	}
+	s.mtx.RUnlock()
+	sortLabels(list)
+	return list
+}
+
+// Search returns up to limit labels of all the chains whose name contains the
+// query, ignoring case, or whose address is the query.
+func (s *Set) Search(query string, limit int) []*Match {
+	query = strings.TrimSpace(query)
+	if query == "" || limit < 1 {
+		return nil
+	}
+	lower := strings.ToLower(query)
+	var matches []*Match
+	for _, chain := range Chains {
+		for _, label := range s.List(chain) {
+			if label.Address != query && !strings.Contains(strings.ToLower(label.Name), lower) {
+				continue
+			}
+			if len(matches) == limit {
+				return matches
+			}
+			matches = append(matches, &Match{Chain: chain, Label: label})
+		}
+	}
+	return matches
+}
+
+func sortLabels(list []*Label) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Name != list[j].Name {
+			return list[i].Name < list[j].Name
+		}
+		return list[i].Address < list[j].Address
+	})
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package labels
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	btcchaincfg "github.com/btcsuite/btcd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/txscript/v4/stdaddr"
+	"github.com/decred/dcrdata/v8/mutilchain"
+	"github.com/decred/dcrdata/v8/mutilchain/pools"
+	ltcchaincfg "github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/ltcutil"
+)
+
+func TestDefault(t *testing.T) {
+	defs := Default()
+	if defs.Version < 1 {
+		t.Fatalf("invalid version %d", defs.Version)
+	}
+	if len(defs.DCR) == 0 || len(defs.BTC) == 0 {
+		t.Fatalf("missing labels")
+	}
+	for _, label := range defs.DCR {
+		if label.Source != SourceFile {
+			t.Errorf("label %s has source %q", label.Address, label.Source)
+		}
+	}
+}
+
+func TestDefaultAddresses(t *testing.T) {
+	defs := Default()
+	for _, label := range defs.DCR {
+		if _, err := stdaddr.DecodeAddress(label.Address, chaincfg.MainNetParams()); err != nil {
+			t.Errorf("invalid DCR address %s of %s: %v", label.Address, label.Name, err)
+		}
+	}
+	for _, label := range defs.BTC {
+		if _, err := btcutil.DecodeAddress(label.Address, &btcchaincfg.MainNetParams); err != nil {
+			t.Errorf("invalid BTC address %s of %s: %v", label.Address, label.Name, err)
+		}
+	}
+	for _, label := range defs.LTC {
+		if _, err := ltcutil.DecodeAddress(label.Address, &ltcchaincfg.MainNetParams); err != nil {
+			t.Errorf("invalid LTC address %s of %s: %v", label.Address, label.Name, err)
+		}
+	}
+}
+
+// TestDefaultPoolAddresses checks that the payout addresses of the mining
+// pools are labeled with their pool.
+func TestDefaultPoolAddresses(t *testing.T) {
+	set := NewSet(Default())
+	check := func(chain string, defs []*pools.Pool) {
+		for _, pool := range defs {
+			for _, addr := range pool.Addresses {
+				label := set.Lookup(chain, addr)
+				if label == nil || label.Name != pool.Name || label.Category != CategoryPool {
+					t.Errorf("%s payout address %s of %s has label %+v", chain, addr, pool.Name, label)
+				}
+			}
+		}
+	}
+	poolDefs := pools.Default()
+	check(mutilchain.TYPEBTC, poolDefs.BTC)
+	check(mutilchain.TYPELTC, poolDefs.LTC)
+}
+
+func TestParseDefinitionsInvalid(t *testing.T) {
+	for _, defs := range []string{
+		`{"version": 0, "dcr": []}`,
+		`{"version": 1, "dcr": [{"address": "", "name": "A", "category": "other"}]}`,
+		`{"version": 1, "dcr": [{"address": "D s", "name": "A", "category": "other"}]}`,
+		`{"version": 1, "btc": [{"address": "1a", "name": " A", "category": "other"}]}`,
+		`{"version": 1, "btc": [{"address": "1a", "name": "A", "category": "bank"}]}`,
+		`{"version": 1, "btc": [{"address": "1a", "name": "A", "category": "burn", "link": "javascript:alert(1)"}]}`,
+		`{"version": 1, "ltc": [{"address": "La", "name": "A", "category": "other"}, {"address": "La", "name": "B", "category": "pool"}]}`,
+	} {
+		if _, err := ParseDefinitions(strings.NewReader(defs)); err == nil {
+			t.Errorf("expected error for %s", defs)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	defs, err := ParseDefinitions(strings.NewReader(`{
+		"version": 2,
+		"dcr": [
+			{"address": "Dsfund", "name": "Project fund", "category": "devfund"},
+			{"address": "Dsvsp", "name": "Some VSP", "category": "vsp"}
+		],
+		"btc": [
+			{"address": "1exchange", "name": "Exchange hot wallet", "category": "exchange"}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := NewSet(defs)
+
+	if name := set.Name(mutilchain.TYPEDCR, "Dsfund"); name != "Project fund" {
+		t.Errorf("got name %q", name)
+	}
+	if label := set.Lookup(mutilchain.TYPEBTC, "Dsfund"); label != nil {
+		t.Errorf("got label %v for another chain", label)
+	}
+
+	set.Put(mutilchain.TYPEDCR, &Label{Address: "Dsvsp", Name: "Renamed VSP", Category: CategoryVSP, Source: SourceAdmin})
+	set.Put(mutilchain.TYPELTC, &Label{Address: "Lpool", Name: "LTC pool payout", Category: CategoryPool, Source: SourceAdmin})
+	names := set.Names(mutilchain.TYPEDCR, []string{"Dsfund", "Dsvsp", "Dsother"})
+	if len(names) != 2 || names["Dsvsp"] != "Renamed VSP" {
+		t.Errorf("got names %v", names)
+	}
+	if list := set.List(mutilchain.TYPEDCR); len(list) != 2 || list[0].Name != "Project fund" {
+		t.Errorf("got list %v", list)
+	}
+
+	matches := set.Search("pool", 10)
+	if len(matches) != 1 || matches[0].Chain != mutilchain.TYPELTC {
+		t.Errorf("got matches %v", matches)
+	}
+	if matches := set.Search("1exchange", 10); len(matches) != 1 || matches[0].Chain != mutilchain.TYPEBTC {
+		t.Errorf("got address matches %v", matches)
+	}
+	if matches := set.Search("e", 2); len(matches) != 2 {
+		t.Errorf("got %d matches over the limit", len(matches))
+	}
+
+	set.Remove(mutilchain.TYPEDCR, "Dsvsp")
+	if name := set.Name(mutilchain.TYPEDCR, "Dsvsp"); name != "Some VSP" {
+		t.Errorf("got name %q after removal", name)
+	}
+}
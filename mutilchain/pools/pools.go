@@ -0,0 +1,183 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+// Package pools attributes BTC and LTC blocks to mining pools using the
+// coinbase scriptSig tags and payout addresses in a versioned pool-definitions
+// file. The definitions in pools.json are embedded in the binary, so the
+// attribution works without any external service. Bump the version in
+// pools.json whenever it changes so that stored attributions are refreshed.
+package pools
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/decred/dcrdata/v8/mutilchain"
+)
+
+// UnknownSlug is the slug of blocks that match no pool definition.
+const UnknownSlug = "unknown"
+
+// maxTagLength is the maximum length of a coinbase tag returned by
+// CoinbaseTag.
+const maxTagLength = 100
+
+//go:embed pools.json
+var defaultDefinitions []byte
+
+// Pool is a mining pool definition.
+type Pool struct {
+	Name      string   `json:"name"`
+	Slug      string   `json:"slug"`
+	Link      string   `json:"link"`
+	Tags      []string `json:"tags,omitempty"`
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+// Unknown is the pool of blocks that match no definition.
+var Unknown = &Pool{
+	Name: "Unknown",
+	Slug: UnknownSlug,
+}
+
+// Definitions is a versioned set of pool definitions for each chain.
+type Definitions struct {
+	Version int     `json:"version"`
+	Updated string  `json:"updated"`
+	BTC     []*Pool `json:"btc"`
+	LTC     []*Pool `json:"ltc"`
+
+	// addrs and tags are indexes built by ParseDefinitions.
+	addrs map[string]map[string]*Pool
+	tags  map[string][]poolTag
+}
+
+type poolTag struct {
+	tag  []byte
+	pool *Pool
+}
+
+// ParseDefinitions parses pool definitions in the format of pools.json.
+func ParseDefinitions(r io.Reader) (*Definitions, error) {
+	defs := new(Definitions)
+	if err := json.NewDecoder(r).Decode(defs); err != nil {
+		return nil, fmt.Errorf("invalid pool definitions: %w", err)
+	}
+	if defs.Version < 1 {
+		return nil, fmt.Errorf("invalid pool definitions version %d", defs.Version)
+	}
+	defs.addrs = make(map[string]map[string]*Pool)
+	defs.tags = make(map[string][]poolTag)
+	for chainType, list := range map[string][]*Pool{
+		mutilchain.TYPEBTC: defs.BTC,
+		mutilchain.TYPELTC: defs.LTC,
+	} {
+		addrs := make(map[string]*Pool)
+		slugs := make(map[string]struct{}, len(list))
+		for _, pool := range list {
+			if pool.Slug == "" || pool.Slug == UnknownSlug {
+				return nil, fmt.Errorf("invalid %s pool slug %q", chainType, pool.Slug)
+			}
+			if _, dup := slugs[pool.Slug]; dup {
+				return nil, fmt.Errorf("duplicate %s pool slug %q", chainType, pool.Slug)
+			}
+			slugs[pool.Slug] = struct{}{}
+			for _, addr := range pool.Addresses {
+				if other, dup := addrs[addr]; dup {
+					return nil, fmt.Errorf("%s address %s is used by pools %s and %s",
+						chainType, addr, other.Slug, pool.Slug)
+				}
+				addrs[addr] = pool
+			}
+			for _, tag := range pool.Tags {
+				if tag == "" {
+					return nil, fmt.Errorf("empty tag for %s pool %s", chainType, pool.Slug)
+				}
+				defs.tags[chainType] = append(defs.tags[chainType],
+					poolTag{bytes.ToLower([]byte(tag)), pool})
+			}
+		}
+		defs.addrs[chainType] = addrs
+	}
+	return defs, nil
+}
+
+var (
+	defaultOnce sync.Once
+	defaultDefs *Definitions
+)
+
+// Default returns the pool definitions embedded from pools.json.
+func Default() *Definitions {
+	defaultOnce.Do(func() {
+		defs, err := ParseDefinitions(bytes.NewReader(defaultDefinitions))
+		if err != nil {
+			panic(err)
+		}
+		defaultDefs = defs
+	})
+	return defaultDefs
+}
+
+// Pools returns the pool definitions for the chain.
+func (d *Definitions) Pools(chainType string) []*Pool {
+	switch chainType {
+	case mutilchain.TYPEBTC:
+		return d.BTC
+	case mutilchain.TYPELTC:
+		return d.LTC
+	default:
+		return nil
+	}
+}
+
+// Pool returns the pool with the given slug, or Unknown.
+func (d *Definitions) Pool(chainType, slug string) *Pool {
+	for _, pool := range d.Pools(chainType) {
+		if pool.Slug == slug {
+			return pool
+		}
+	}
+	return Unknown
+}
+
+// Identify attributes a block to a pool using the scriptSig of its coinbase
+// transaction and the addresses paid by the coinbase. Payout addresses take
+// precedence over coinbase tags, since tags can be set by anyone. Unknown is
+// returned if there is no match.
+func (d *Definitions) Identify(chainType string, coinbaseScript []byte, payoutAddrs []string) *Pool {
+	for _, addr := range payoutAddrs {
+		if pool := d.addrs[chainType][addr]; pool != nil {
+			return pool
+		}
+	}
+	script := bytes.ToLower(coinbaseScript)
+	for _, t := range d.tags[chainType] {
+		if bytes.Contains(script, t.tag) {
+			return t.pool
+		}
+	}
+	return Unknown
+}
+
+// CoinbaseTag extracts the printable text of a coinbase scriptSig for display.
+func CoinbaseTag(coinbaseScript []byte) string {
+	var sb strings.Builder
+	for _, r := range strings.ToValidUTF8(string(coinbaseScript), " ") {
+		if !unicode.IsPrint(r) {
+			r = ' '
+		}
+		sb.WriteRune(r)
+	}
+	tag := strings.Join(strings.Fields(sb.String()), " ")
+	if runes := []rune(tag); len(runes) > maxTagLength {
+		tag = string(runes[:maxTagLength])
+	}
+	return tag
+}
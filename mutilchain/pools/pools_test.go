@@ -0,0 +1,132 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package pools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/decred/dcrdata/v8/mutilchain"
+	ltccfg "github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/ltcutil"
+)
+
+func TestDefault(t *testing.T) {
+	defs := Default()
+	if defs.Version < 1 {
+		t.Fatalf("invalid version %d", defs.Version)
+	}
+	if len(defs.BTC) == 0 || len(defs.LTC) == 0 {
+		t.Fatalf("missing pool definitions")
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	defs, err := ParseDefinitions(strings.NewReader(`{
+		"version": 2,
+		"btc": [
+			{"name": "Solo", "slug": "solo", "tags": ["solo.ckpool"]},
+			{"name": "CK", "slug": "ck", "tags": ["ckpool"], "addresses": ["bc1qpayout"]}
+		],
+		"ltc": [
+			{"name": "LTC Pool", "slug": "ltcpool", "tags": ["/LTCPool/"]}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		chain  string
+		script string
+		addrs  []string
+		want   string
+	}{
+		{"tag", mutilchain.TYPEBTC, "\x03\x01\x02\x03/solo.ckpool.org/\x00", nil, "solo"},
+		{"case insensitive", mutilchain.TYPEBTC, "\x03\x01\x02\x03CKPOOL", nil, "ck"},
+		{"address first", mutilchain.TYPEBTC, "solo.ckpool", []string{"bc1qother", "bc1qpayout"}, "ck"},
+		{"other chain", mutilchain.TYPELTC, "ckpool", nil, UnknownSlug},
+		{"ltc", mutilchain.TYPELTC, "\x03\x01\x02\x03/ltcpool/", nil, "ltcpool"},
+		{"none", mutilchain.TYPEBTC, "\x03\x01\x02\x03", nil, UnknownSlug},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := defs.Identify(tt.chain, []byte(tt.script), tt.addrs)
+			if pool.Slug != tt.want {
+				t.Errorf("got pool %s, want %s", pool.Slug, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultAddresses(t *testing.T) {
+	defs := Default()
+	var count int
+	for _, pool := range defs.BTC {
+		for _, addr := range pool.Addresses {
+			if _, err := btcutil.DecodeAddress(addr, &chaincfg.MainNetParams); err != nil {
+				t.Errorf("invalid address %s of BTC pool %s: %v", addr, pool.Slug, err)
+			}
+			count++
+		}
+	}
+	for _, pool := range defs.LTC {
+		for _, addr := range pool.Addresses {
+			if _, err := ltcutil.DecodeAddress(addr, &ltccfg.MainNetParams); err != nil {
+				t.Errorf("invalid address %s of LTC pool %s: %v", addr, pool.Slug, err)
+			}
+			count++
+		}
+	}
+	if count == 0 {
+		t.Fatal("no pool payout addresses")
+	}
+}
+
+func TestIdentifyDefaultAddress(t *testing.T) {
+	defs := Default()
+	tests := []struct {
+		name   string
+		script string
+		addrs  []string
+		want   string
+	}{
+		{"no tag", "\x03\xa0\xbb\x0c", []string{"1KFHE7w8BhaENAswwryaoccDb6qcT6DbYY"}, "f2pool"},
+		{"segwit", "\x03\xa0\xbb\x0c", []string{"bc1qxhmdufsvnuaaaer4ynz88fspdsxq2h9e9cetdj"}, "foundryusa"},
+		{"second output", "\x03\xa0\xbb\x0c", []string{"1BitcoinEaterAddressDontSendf59kuE", "18cBEMRxXHqzWWCxZNtU91F5sbUNKhL5PX"}, "viabtc"},
+		{"over other tag", "/AntPool/", []string{"1MkCDCzHpBsYQivp8MxjY5AkTGG1f2baoe"}, "luxor"},
+		{"unknown address", "\x03\xa0\xbb\x0c", []string{"1BitcoinEaterAddressDontSendf59kuE"}, UnknownSlug},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := defs.Identify(mutilchain.TYPEBTC, []byte(tt.script), tt.addrs)
+			if pool.Slug != tt.want {
+				t.Errorf("got pool %s, want %s", pool.Slug, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDefinitionsInvalid(t *testing.T) {
+	for _, defs := range []string{
+		`{"version": 0, "btc": []}`,
+		`{"version": 1, "btc": [{"name": "A", "slug": "a"}, {"name": "B", "slug": "a"}]}`,
+		`{"version": 1, "btc": [{"name": "A", "slug": "unknown"}]}`,
+		`{"version": 1, "ltc": [{"name": "A", "slug": "a", "addresses": ["x"]}, {"name": "B", "slug": "b", "addresses": ["x"]}]}`,
+	} {
+		if _, err := ParseDefinitions(strings.NewReader(defs)); err == nil {
+			t.Errorf("expected error for %s", defs)
+		}
+	}
+}
+
+func TestCoinbaseTag(t *testing.T) {
+	tag := CoinbaseTag([]byte("\x03\xa0\xbb\x0c\x1b/Foundry USA Pool #dropgold/\x00\x01"))
+	if !strings.Contains(tag, "/Foundry USA Pool #dropgold/") {
+		t.Errorf("unexpected tag %q", tag)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package mutilchain
+
+import "time"
+
+// RetargetParams are the proof-of-work retarget parameters of a Bitcoin-style
+// chain, as found in the btcd and ltcd chaincfg.Params.
+type RetargetParams struct {
+	TargetTimespan           time.Duration
+	TargetTimePerBlock       time.Duration
+	RetargetAdjustmentFactor int64
+}
+
+// RetargetInterval is the number of blocks in a difficulty retarget epoch.
+func (p RetargetParams) RetargetInterval() int64 {
+	return int64(p.TargetTimespan / p.TargetTimePerBlock)
+}
+
+// EpochStart is the height of the first block of the retarget epoch of the
+// block at height.
+func (p RetargetParams) EpochStart(height int64) int64 {
+	return height - height%p.RetargetInterval()
+}
+
+// RetargetEpoch is the data of the first block of a retarget epoch, and of the
+// last block of the preceding epoch, needed to estimate the next difficulty
+// adjustment.
+type RetargetEpoch struct {
+	StartHeight     int64
+	StartTime       int64
+	StartDifficulty float64
+	// PrevDifficulty is the difficulty of the last block of the preceding
+	// epoch, or zero for the first epoch.
+	PrevDifficulty float64
+}
+
+// DifficultyAdjustment is the progress of the current retarget epoch and the
+// estimated outcome of the next difficulty adjustment. Percentages are in the
+// range [0, 100], and times are in seconds.
+type DifficultyAdjustment struct {
+	Height           int64   `json:"height"`
+	RetargetHeight   int64   `json:"retargetHeight"`
+	Progress         float64 `json:"progress"`
+	RemainingBlocks  int64   `json:"remainingBlocks"`
+	TimeRemaining    int64   `json:"timeRemaining"`
+	TimeAvg          int64   `json:"timeAvg"`
+	DifficultyChange float64 `json:"difficultyChange"`
+	NextDifficulty   float64 `json:"nextDifficulty"`
+	PreviousRetarget float64 `json:"previousRetarget"`
+}
+
+// CalcDifficultyAdjustment estimates the next difficulty adjustment from the
+// average block time of the current epoch, given the height, time and
+// difficulty of the best block. The estimated change is bounded by the
+// retarget adjustment factor as it is by consensus.
+func CalcDifficultyAdjustment(p RetargetParams, epoch RetargetEpoch, height, blockTime int64,
+	difficulty float64) *DifficultyAdjustment {
+	interval := p.RetargetInterval()
+	target := p.TargetTimePerBlock.Seconds()
+	mined := height - epoch.StartHeight
+
+	timeAvg := target
+	if mined > 0 && blockTime > epoch.StartTime {
+		timeAvg = float64(blockTime-epoch.StartTime) / float64(mined)
+	}
+
+	ratio := target / timeAvg
+	if factor := float64(p.RetargetAdjustmentFactor); factor > 0 {
+		ratio = min(max(ratio, 1/factor), factor)
+	}
+
+	var previous float64
+	if epoch.PrevDifficulty > 0 {
+		previous = (epoch.StartDifficulty/epoch.PrevDifficulty - 1) * 100
+	}
+
+	remaining := interval - mined
+	return &DifficultyAdjustment{
+		Height:           height,
+		RetargetHeight:   epoch.StartHeight + interval,
+		Progress:         float64(mined) / float64(interval) * 100,
+		RemainingBlocks:  remaining,
+		TimeRemaining:    int64(float64(remaining) * timeAvg),
+		TimeAvg:          int64(timeAvg),
+		DifficultyChange: (ratio - 1) * 100,
+		NextDifficulty:   difficulty * ratio,
+		PreviousRetarget: previous,
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package mutilchain
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var btcRetargetParams = RetargetParams{
+	TargetTimespan:           14 * 24 * time.Hour,
+	TargetTimePerBlock:       10 * time.Minute,
+	RetargetAdjustmentFactor: 4,
+}
+
+func TestRetargetInterval(t *testing.T) {
+	if n := btcRetargetParams.RetargetInterval(); n != 2016 {
+		t.Fatalf("interval %d, want 2016", n)
+	}
+	ltc := RetargetParams{
+		TargetTimespan:     84 * time.Hour,
+		TargetTimePerBlock: 150 * time.Second,
+	}
+	if n := ltc.RetargetInterval(); n != 2016 {
+		t.Fatalf("LTC interval %d, want 2016", n)
+	}
+	if h := btcRetargetParams.EpochStart(840000); h != 838656 {
+		t.Fatalf("epoch start %d, want 838656", h)
+	}
+	if h := btcRetargetParams.EpochStart(838656); h != 838656 {
+		t.Fatalf("epoch start %d, want 838656", h)
+	}
+}
+
+func TestCalcDifficultyAdjustment(t *testing.T) {
+	const start = 1_700_000_000
+	epoch := RetargetEpoch{
+		StartHeight:     838656,
+		StartTime:       start,
+		StartDifficulty: 110,
+		PrevDifficulty:  100,
+	}
+	tests := []struct {
+		name          string
+		height        int64
+		blockTime     int64
+		wantAvg       int64
+		wantChange    float64
+		wantRemaining int64
+	}{
+		{"on target", 838656 + 1008, start + 1008*600, 600, 0, 1008},
+		{"fast blocks", 838656 + 1008, start + 1008*500, 500, 20, 1008},
+		{"slow blocks", 838656 + 504, start + 504*750, 750, -20, 1512},
+		{"bounded", 838656 + 100, start + 100*60, 60, 300, 1916},
+		{"epoch start", 838656, start, 600, 0, 2016},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			da := CalcDifficultyAdjustment(btcRetargetParams, epoch, tt.height, tt.blockTime, 110)
+			if da.TimeAvg != tt.wantAvg {
+				t.Errorf("time avg %d, want %d", da.TimeAvg, tt.wantAvg)
+			}
+			if math.Abs(da.DifficultyChange-tt.wantChange) > 1e-9 {
+				t.Errorf("difficulty change %f, want %f", da.DifficultyChange, tt.wantChange)
+			}
+			if math.Abs(da.NextDifficulty-110*(1+tt.wantChange/100)) > 1e-9 {
+				t.Errorf("next difficulty %f", da.NextDifficulty)
+			}
+			if da.RemainingBlocks != tt.wantRemaining {
+				t.Errorf("remaining blocks %d, want %d", da.RemainingBlocks, tt.wantRemaining)
+			}
+			if da.TimeRemaining != tt.wantRemaining*tt.wantAvg {
+				t.Errorf("time remaining %d, want %d", da.TimeRemaining, tt.wantRemaining*tt.wantAvg)
+			}
+			if da.RetargetHeight != 840672 {
+				t.Errorf("retarget height %d, want 840672", da.RetargetHeight)
+			}
+			if math.Abs(da.PreviousRetarget-10) > 1e-9 {
+				t.Errorf("previous retarget %f, want 10", da.PreviousRetarget)
+			}
+		})
+	}
+}
@@ -42,3 +42,21 @@ var SimNetParams = Params{
 	JSONRPCServerPort: "18557",
 	GRPCServerPort:    "18558",
 }
+
+// RegressionNetParams contains parameters specific to the regression test
+// network (wire.TestNet).
+var RegressionNetParams = Params{
+	Params:            &chaincfg.RegressionNetParams,
+	JSONRPCClientPort: "18443",
+	JSONRPCServerPort: "18445",
+	GRPCServerPort:    "18446",
+}
+
+// SigNetParams contains parameters specific to the default signet test
+// network (wire.SigNet).
+var SigNetParams = Params{
+	Params:            &chaincfg.SigNetParams,
+	JSONRPCClientPort: "38332",
+	JSONRPCServerPort: "38335",
+	GRPCServerPort:    "38336",
+}
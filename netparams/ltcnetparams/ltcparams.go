@@ -42,3 +42,12 @@ var SimNetParams = Params{
 	JSONRPCServerPort: "18557",
 	GRPCServerPort:    "18558",
 }
+
+// RegressionNetParams contains parameters specific to the regression test
+// network (wire.TestNet).
+var RegressionNetParams = Params{
+	Params:            &chaincfg.RegressionNetParams,
+	JSONRPCClientPort: "19443",
+	JSONRPCServerPort: "19445",
+	GRPCServerPort:    "19446",
+}
@@ -292,9 +292,20 @@ func (c *Client) receiver() {
 				resp.EventId, m.NumAll, t)
 		case *pstypes.TxList:
 			log.Debugf("Message (%s): TxList(len=%d)", resp.EventId, len(*m))
+		case *exptypes.MutilchainMempoolEvent:
+			log.Debugf("Message (%s): MutilchainMempoolEvent(chain=%s, replacements=%d, evictions=%d, accelerated=%d)",
+				resp.EventId, m.ChainType, len(m.Replacements), len(m.Evictions), len(m.Accelerated))
 		case *pstypes.AddressMessage:
 			log.Debugf("Message (%s): AddressMessage(address=%s, txHash=%s)",
 				resp.EventId, m.Address, m.TxHash)
+		case *pstypes.XMRTxList:
+			log.Debugf("Message (%s): XMRTxList(len=%d)", resp.EventId, len(*m))
+		case *pstypes.KeyImageMessage:
+			log.Debugf("Message (%s): KeyImageMessage(keyImage=%s, txHash=%s, height=%d)",
+				resp.EventId, m.KeyImage, m.TxHash, m.BlockHeight)
+		case *pstypes.XMRViewKeyMessage:
+			log.Debugf("Message (%s): XMRViewKeyMessage(address=%s, txHash=%s, outputs=%d, inPool=%v)",
+				resp.EventId, m.Address, m.TxHash, len(m.Outputs), m.InPool)
 		default:
 			log.Debugf("Message of type %v unhandled.", resp.EventId)
 			continue
@@ -503,6 +514,22 @@ func DecodeMsg(msg *pstypes.WebSocketMessage) (interface{}, error) {
 		var mpshort exptypes.MempoolShort
 		err := json.Unmarshal(msg.Message, &mpshort)
 		return &mpshort, err
+	case "btcmempoolevents", "ltcmempoolevents":
+		var event exptypes.MutilchainMempoolEvent
+		err := json.Unmarshal(msg.Message, &event)
+		return &event, err
+	case "newxmrtxs":
+		var newtxs pstypes.XMRTxList
+		err := json.Unmarshal(msg.Message, &newtxs)
+		return &newtxs, err
+	case "keyimage":
+		var km pstypes.KeyImageMessage
+		err := json.Unmarshal(msg.Message, &km)
+		return &km, err
+	case "xmrviewkey":
+		var vm pstypes.XMRViewKeyMessage
+		err := json.Unmarshal(msg.Message, &vm)
+		return &vm, err
 	default:
 		return nil, fmt.Errorf("unrecognized event type")
 	}
@@ -611,3 +638,60 @@ func DecodeMsgNewAddressTx(msg *pstypes.WebSocketMessage) (*pstypes.AddressMessa
 	}
 	return am, nil
 }
+
+// DecodeMsgMutilchainMempoolEvent attempts to decode the Message content of the
+// given WebSocketMessage as a btcmempoolevents or ltcmempoolevents message
+// (*exptypes.MutilchainMempoolEvent).
+func DecodeMsgMutilchainMempoolEvent(msg *pstypes.WebSocketMessage) (*exptypes.MutilchainMempoolEvent, error) {
+	ev, err := DecodeMsg(msg)
+	if err != nil {
+		return nil, err
+	}
+	event, ok := ev.(*exptypes.MutilchainMempoolEvent)
+	if !ok {
+		return nil, fmt.Errorf("content of Message was not of type *exptypes.MutilchainMempoolEvent")
+	}
+	return event, nil
+}
+
+// DecodeMsgXMRTxList attempts to decode the Message content of the given
+// WebSocketMessage as a newxmrtxs message (*pstypes.XMRTxList).
+func DecodeMsgXMRTxList(msg *pstypes.WebSocketMessage) (*pstypes.XMRTxList, error) {
+	txl, err := DecodeMsg(msg)
+	if err != nil {
+		return nil, err
+	}
+	txlist, ok := txl.(*pstypes.XMRTxList)
+	if !ok {
+		return nil, fmt.Errorf("content of Message was not of type *pstypes.XMRTxList")
+	}
+	return txlist, nil
+}
+
+// DecodeMsgKeyImage attempts to decode the Message content of the given
+// WebSocketMessage as a keyimage message (*pstypes.KeyImageMessage).
+func DecodeMsgKeyImage(msg *pstypes.WebSocketMessage) (*pstypes.KeyImageMessage, error) {
+	m, err := DecodeMsg(msg)
+	if err != nil {
+		return nil, err
+	}
+	km, ok := m.(*pstypes.KeyImageMessage)
+	if !ok {
+		return nil, fmt.Errorf("content of Message was not of type *pstypes.KeyImageMessage")
+	}
+	return km, nil
+}
+
+// DecodeMsgXMRViewKey attempts to decode the Message content of the given
+// WebSocketMessage as an xmrviewkey message (*pstypes.XMRViewKeyMessage).
+func DecodeMsgXMRViewKey(msg *pstypes.WebSocketMessage) (*pstypes.XMRViewKeyMessage, error) {
+	m, err := DecodeMsg(msg)
+	if err != nil {
+		return nil, err
+	}
+	vm, ok := m.(*pstypes.XMRViewKeyMessage)
+	if !ok {
+		return nil, fmt.Errorf("content of Message was not of type *pstypes.XMRViewKeyMessage")
+	}
+	return vm, nil
+}
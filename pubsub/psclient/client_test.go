@@ -767,6 +767,92 @@ func TestDecodeMsgMempool(t *testing.T) {
 	}
 }
 
+var msgBTCMempoolEvents = &pstypes.WebSocketMessage{
+	EventId: "btcmempoolevents",
+	Message: json.RawMessage(`{
+		"chain": "btc",
+		"replacements": [
+			{
+				"txid": "3a5ec5e7de5ce5df46d0a3eaac519c51a1aaf5092b71ad743295a698915e5833",
+				"replaced_by": "91be1871e359575af3b4d740afe4c90eee2a6410c67dc99975d940190f864fbc",
+				"fee": 1000,
+				"new_fee": 2500,
+				"fee_delta": 1500,
+				"full_rbf": false,
+				"time": 1548362229
+			}
+		],
+		"evictions": [
+			{
+				"txid": "3a5ec5e7de5ce5df46d0a3eaac519c51a1aaf5092b71ad743295a698915e5833",
+				"reason": "replaced",
+				"time": 1548362229
+			}
+		]
+	}`),
+}
+
+func TestDecodeMsgMutilchainMempoolEvent(t *testing.T) {
+	event, err := DecodeMsgMutilchainMempoolEvent(msgBTCMempoolEvents)
+	if err != nil {
+		t.Fatalf("failed to decode message: %v", err)
+	}
+
+	if event.ChainType != "btc" {
+		t.Errorf("expecting chain btc, got %s", event.ChainType)
+	}
+	if len(event.Replacements) != 1 {
+		t.Fatalf("expecting 1 replacement, got %d", len(event.Replacements))
+	}
+	if event.Replacements[0].FeeDelta != 1500 {
+		t.Errorf("expecting fee delta 1500, got %d", event.Replacements[0].FeeDelta)
+	}
+	if len(event.Evictions) != 1 || event.Evictions[0].Reason != "replaced" {
+		t.Errorf("unexpected evictions: %v", event.Evictions)
+	}
+}
+
+func TestDecodeMsgXMR(t *testing.T) {
+	txs, err := DecodeMsgXMRTxList(&pstypes.WebSocketMessage{
+		EventId: "newxmrtxs",
+		Message: json.RawMessage(`[{"txid": "ab", "size": 1500, "fee": 30720000, "inputs": 1,
+			"outputs": 2, "key_images": ["cd"], "receive_time": 1700000000}]`),
+	})
+	if err != nil {
+		t.Fatalf("failed to decode newxmrtxs: %v", err)
+	}
+	if len(*txs) != 1 || (*txs)[0].Fee != 30720000 || (*txs)[0].KeyImages[0] != "cd" {
+		t.Errorf("unexpected txs %v", *txs)
+	}
+
+	km, err := DecodeMsgKeyImage(&pstypes.WebSocketMessage{
+		EventId: "keyimage",
+		Message: json.RawMessage(`{"key_image": "cd", "transaction": "ab", "input_index": 0, "block_height": 3000000}`),
+	})
+	if err != nil {
+		t.Fatalf("failed to decode keyimage: %v", err)
+	}
+	if km.TxHash != "ab" || km.BlockHeight != 3000000 {
+		t.Errorf("unexpected key image message %v", km)
+	}
+
+	vm, err := DecodeMsgXMRViewKey(&pstypes.WebSocketMessage{
+		EventId: "xmrviewkey",
+		Message: json.RawMessage(`{"address": "44AFF", "transaction": "ab", "block_height": -1,
+			"in_pool": true, "outputs": [{"index": 1, "key": "ef", "amount": 1000000000000}]}`),
+	})
+	if err != nil {
+		t.Fatalf("failed to decode xmrviewkey: %v", err)
+	}
+	if !vm.InPool || len(vm.Outputs) != 1 || vm.Outputs[0].Amount != 1000000000000 {
+		t.Errorf("unexpected view key message %v", vm)
+	}
+
+	if _, err = DecodeMsgKeyImage(msgBTCMempoolEvents); err == nil {
+		t.Error("expected an error decoding another event as a key image message")
+	}
+}
+
 func TestDecodeMsgNewBlock(t *testing.T) {
 	newBlock, err := DecodeMsgNewBlock(msgNewBlock312592)
 	if err != nil {
@@ -35,6 +35,7 @@ import (
 	pstypes "github.com/decred/dcrdata/v8/pubsub/types"
 	"github.com/decred/dcrdata/v8/semver"
 	"github.com/decred/dcrdata/v8/txhelpers"
+	"github.com/decred/dcrdata/v8/xmr/xmrclient"
 	"github.com/decred/dcrdata/v8/xmr/xmrutil"
 	ltcjson "github.com/ltcsuite/ltcd/btcjson"
 	ltcchaincfg "github.com/ltcsuite/ltcd/chaincfg"
@@ -43,7 +44,7 @@ import (
 	"golang.org/x/net/websocket"
 )
 
-var version = semver.NewSemver(3, 2, 0)
+var version = semver.NewSemver(3, 3, 0)
 
 // Version indicates the semantic version of the pubsub module.
 func Version() semver.Semver {
@@ -121,6 +122,16 @@ type PubSubHub struct {
 	LtcCharts  *cache.MutilchainChartData
 	BtcCharts  *cache.MutilchainChartData
 	XmrCharts  *cache.MutilchainChartData
+	// XmrClient fetches the transactions of new Monero blocks for the key
+	// image and view key watches.
+	XmrClient *xmrclient.XMRClient
+	// XMRViewKeyWatch allows clients to watch Monero addresses with their
+	// private view keys.
+	XMRViewKeyWatch bool
+
+	xmrWatches    *xmrWatches
+	xmrMempoolMtx sync.Mutex
+	xmrMempoolTxs map[string]struct{}
 }
 
 // NewPubSubHub constructs a PubSubHub given a data source. The WebSocketHub is
@@ -131,6 +142,7 @@ func NewPubSubHub(dataSource DataSource) (*PubSubHub, error) {
 
 	// Allocate Mempool fields.
 	psh.invs = new(exptypes.MempoolInfo)
+	psh.xmrWatches = newXMRWatches()
 
 	// Retrieve chain parameters.
 	params := psh.sourceBase.GetChainParams()
@@ -281,20 +293,31 @@ func (psh *PubSubHub) receiveLoop(conn *connection) {
 		// Determine response based on EventId and Message content.
 		switch msg.EventId {
 		case "subscribe":
+			logEvent := pstypes.SubscriptionLogString(reqEvent)
 			sig, sigMsg, valid := pstypes.ValidateSubscription(reqEvent)
 			if !valid {
-				log.Debugf("Invalid subscribe signal: %.40s...", reqEvent)
+				log.Debugf("Invalid subscribe signal: %.40s...", logEvent)
 				respMsg.Data = "error: invalid subscription"
 				break
 			}
+			if sig == sigXMRViewKey && !psh.XMRViewKeyWatch {
+				respMsg.Data = "error: view key watches are disabled"
+				break
+			}
 
 			var ok bool
 			ok, err = conn.client.cl.subscribe(pstypes.HubMessage{Signal: sig, Msg: sigMsg})
 			if err != nil {
-				log.Debugf("Failed to subscribe: %.40s...", reqEvent)
+				log.Debugf("Failed to subscribe: %.40s...", logEvent)
 				respMsg.Data = "error: " + err.Error()
 				break
 			}
+			if sig == sigXMRKeyImage || sig == sigXMRViewKey {
+				if err = psh.xmrWatches.watch(conn.client.cl.id, sigMsg); err != nil {
+					respMsg.Data = "error: " + err.Error()
+					break
+				}
+			}
 			if !ok && sig != sigPingAndUserCount { // don't error on users over the legacy ping subscription request
 				log.Debugf("Client CANNOT subscribe to: %v.", reqEvent)
 				respMsg.Data = "cannot subscribed to " + reqEvent
@@ -302,7 +325,7 @@ func (psh *PubSubHub) receiveLoop(conn *connection) {
 			}
 
 			if sig != sigPingAndUserCount {
-				log.Debugf("Client subscribed for: %v.", reqEvent)
+				log.Debugf("Client subscribed for: %v.", logEvent)
 				// Do not error on old clients that try to subscribe to ping
 				// since they will get pings automatically.
 			}
@@ -310,21 +333,25 @@ func (psh *PubSubHub) receiveLoop(conn *connection) {
 			respMsg.Success = true
 
 		case "unsubscribe":
+			logEvent := pstypes.SubscriptionLogString(reqEvent)
 			sig, sigMsg, valid := pstypes.ValidateSubscription(reqEvent)
 			if !valid {
-				log.Debugf("Invalid unsubscribe signal: %.40s...", reqEvent)
+				log.Debugf("Invalid unsubscribe signal: %.40s...", logEvent)
 				respMsg.Data = "error: invalid subscription"
 				break
 			}
 
 			err = conn.client.cl.unsubscribe(pstypes.HubMessage{Signal: sig, Msg: sigMsg})
 			if err != nil {
-				log.Debugf("Failed to unsubscribe from: %.40s...", reqEvent)
+				log.Debugf("Failed to unsubscribe from: %.40s...", logEvent)
 				respMsg.Data = "error: " + err.Error()
 				break
 			}
+			if sig == sigXMRKeyImage || sig == sigXMRViewKey {
+				psh.xmrWatches.unwatch(conn.client.cl.id, sigMsg)
+			}
 
-			log.Debugf("Client unsubscribed from: %v.", reqEvent)
+			log.Debugf("Client unsubscribed from: %v.", logEvent)
 			respMsg.Data = "unsubscribed from " + reqEvent
 			respMsg.Success = true
 
@@ -589,6 +616,23 @@ loop:
 
 			pushMsg.Message = buff.Bytes()
 
+		case sigBTCMempoolEvents, sigLTCMempoolEvents:
+			// Replacements, evictions and CPFP accelerations.
+			if err := enc.Encode(sig.Msg); err != nil {
+				log.Warnf("Encode(*MutilchainMempoolEvent) failed: %v", err)
+			}
+
+			pushMsg.Message = buff.Bytes()
+
+		case sigNewXMRTxs, sigXMRKeyImage, sigXMRViewKey:
+			// The client was checked to be subscribed to the key image or
+			// address of the message.
+			if err := enc.Encode(sig.Msg); err != nil {
+				log.Warnf("Encode(%s) failed: %v", sig.Signal, err)
+			}
+
+			pushMsg.Message = buff.Bytes()
+
 		case sigPingAndUserCount:
 			// ping and send user count
 			pushMsg.Message = json.RawMessage(strconv.Itoa(psh.WsHub.NumClients())) // No quotes as this is a JSON integer
@@ -687,6 +731,7 @@ func (psh *PubSubHub) WebSocketHandler(w http.ResponseWriter, r *http.Request) {
 
 		// Clean up the client's subscriptions.
 		ch.cl.unsubscribeAll()
+		psh.xmrWatches.unwatchAll(ch.cl.id)
 	})
 
 	// Use a websocket.Server to avoid checking Origin.
@@ -948,8 +993,9 @@ func (psh *PubSubHub) BTCStore(blockData *blockdatabtc.BlockData, msgBlock *btcw
 }
 
 func (psh *PubSubHub) XMRStore(blockData *xmrutil.BlockData) error {
-	// handler store xmr block data in here
-	log.Infof("handler xmr store in pubsubhub")
+	// Signal spent key images and received outputs of the watches, without
+	// blocking the other savers.
+	go psh.checkXMRBlockTxs(int64(blockData.Header.Height), blockData.TxHashes)
 	return nil
 }
 
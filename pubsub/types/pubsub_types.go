@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/decred/base58"
 	exptypes "github.com/decred/dcrdata/v8/explorer/types"
+	"github.com/decred/dcrdata/v8/xmr/xmrutil"
 )
 
 // Ver is a json tagged version type.
@@ -85,6 +87,54 @@ func (am AddressMessage) String() string {
 
 type TxList []*exptypes.MempoolTx
 
+// XMRTx is a new Monero mempool transaction.
+type XMRTx struct {
+	TxHash      string   `json:"txid"`
+	Size        int64    `json:"size"`
+	Fee         int64    `json:"fee"`
+	Inputs      int      `json:"inputs"`
+	Outputs     int      `json:"outputs"`
+	KeyImages   []string `json:"key_images"`
+	ReceiveTime int64    `json:"receive_time"`
+}
+
+type XMRTxList []*XMRTx
+
+// KeyImageMessage is sent when a watched key image appears on chain, which
+// means the output it belongs to was spent. A subscription only sets
+// KeyImage.
+type KeyImageMessage struct {
+	KeyImage    string `json:"key_image"`
+	TxHash      string `json:"transaction"`
+	InputIndex  int    `json:"input_index"`
+	BlockHeight int64  `json:"block_height"`
+}
+
+func (km KeyImageMessage) String() string {
+	return km.KeyImage + ":" + km.TxHash
+}
+
+// XMRViewKeyWatch is a subscription to the outputs received by a Monero
+// address, which the server detects with the private view key.
+type XMRViewKeyWatch struct {
+	Address string
+	ViewKey string
+}
+
+// XMRViewKeyMessage is sent when a transaction in mempool or in a new block
+// pays a watched Monero address. BlockHeight is -1 for mempool transactions.
+type XMRViewKeyMessage struct {
+	Address     string                   `json:"address"`
+	TxHash      string                   `json:"transaction"`
+	BlockHeight int64                    `json:"block_height"`
+	InPool      bool                     `json:"in_pool"`
+	Outputs     []xmrutil.ReceivedOutput `json:"outputs"`
+}
+
+func (vm XMRViewKeyMessage) String() string {
+	return vm.Address + ":" + vm.TxHash
+}
+
 type HangUp struct{}
 
 type HubSignal int
@@ -113,6 +163,11 @@ const (
 	SigSummary24h
 	SigNewXMRBlock
 	SigXmrMempoolStatus
+	SigBTCMempoolEvents
+	SigLTCMempoolEvents
+	SigNewXMRTxs
+	SigXMRKeyImage
+	SigXMRViewKey
 )
 
 var Subscriptions = map[string]HubSignal{
@@ -128,6 +183,11 @@ var Subscriptions = map[string]HubSignal{
 	"summary24h":       SigSummary24h,
 	"xmrMempoolStatus": SigXmrMempoolStatus,
 	"newxmrblock":      SigNewXMRBlock,
+	"btcmempoolevents": SigBTCMempoolEvents,
+	"ltcmempoolevents": SigLTCMempoolEvents,
+	"newxmrtxs":        SigNewXMRTxs,
+	"keyimage":         SigXMRKeyImage,
+	"xmrviewkey":       SigXMRViewKey,
 }
 
 // Event type field for an event.
@@ -153,6 +213,21 @@ var eventIDs = map[HubSignal]string{
 	SigSummary24h:       "summary24h",
 	SigNewXMRBlock:      "newxmrblock",
 	SigXmrMempoolStatus: "xmrMempoolStatus",
+	SigBTCMempoolEvents: "btcmempoolevents",
+	SigLTCMempoolEvents: "ltcmempoolevents",
+	SigNewXMRTxs:        "newxmrtxs",
+	SigXMRKeyImage:      "keyimage",
+	SigXMRViewKey:       "xmrviewkey",
+}
+
+// SubscriptionLogString is the subscription event with any private view key
+// elided, for logging.
+func SubscriptionLogString(event string) string {
+	if rest, found := strings.CutPrefix(event, "xmrviewkey:"); found {
+		address, _, _ := strings.Cut(rest, ":")
+		return "xmrviewkey:" + address + ":<viewkey>"
+	}
+	return event
 }
 
 func ValidateSubscription(event string) (sub HubSignal, msg interface{}, valid bool) {
@@ -179,6 +254,27 @@ func ValidateSubscription(event string) (sub HubSignal, msg interface{}, valid b
 		msg = &AddressMessage{
 			Address: msgStr,
 		}
+	case SigXMRKeyImage:
+		keyImage, err := hex.DecodeString(msgStr)
+		if err != nil || len(keyImage) != 32 {
+			return SigUnknown, nil, false
+		}
+		msg = &KeyImageMessage{
+			KeyImage: hex.EncodeToString(keyImage),
+		}
+	case SigXMRViewKey:
+		// The address and the private view key, checked to match.
+		address, viewKey, found := strings.Cut(msgStr, ":")
+		if !found {
+			return SigUnknown, nil, false
+		}
+		if _, err := xmrutil.NewViewKeyScanner(address, viewKey); err != nil {
+			return SigUnknown, nil, false
+		}
+		msg = &XMRViewKeyWatch{
+			Address: address,
+			ViewKey: strings.ToLower(viewKey),
+		}
 	default:
 		// Other signals do not have a message.
 		if msgStr != "" {
@@ -221,6 +317,14 @@ func (m HubMessage) IsValid() bool {
 		_, ok = m.Msg.(*exptypes.MempoolTx)
 	case SigNewTxs:
 		_, ok = m.Msg.([]*exptypes.MempoolTx)
+	case SigBTCMempoolEvents, SigLTCMempoolEvents:
+		_, ok = m.Msg.(*exptypes.MutilchainMempoolEvent)
+	case SigNewXMRTxs:
+		_, ok = m.Msg.(XMRTxList)
+	case SigXMRKeyImage:
+		_, ok = m.Msg.(*KeyImageMessage)
+	case SigXMRViewKey:
+		_, ok = m.Msg.(*XMRViewKeyMessage)
 	}
 
 	return ok
@@ -243,6 +347,15 @@ func (m HubMessage) String() string {
 	case SigNewTxs:
 		txs := m.Msg.([]*exptypes.MempoolTx)
 		sigStr += ":len=" + strconv.Itoa(len(txs))
+	case SigNewXMRTxs:
+		txs := m.Msg.(XMRTxList)
+		sigStr += ":len=" + strconv.Itoa(len(txs))
+	case SigXMRKeyImage:
+		km := m.Msg.(*KeyImageMessage)
+		sigStr += ":" + km.String()
+	case SigXMRViewKey:
+		vm := m.Msg.(*XMRViewKeyMessage)
+		sigStr += ":" + vm.String()
 	}
 
 	return sigStr
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"strings"
 	"testing"
 
 	exptypes "github.com/decred/dcrdata/v8/explorer/types"
@@ -76,3 +77,32 @@ func TestHubMessage_String(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateSubscriptionXMR(t *testing.T) {
+	const (
+		address  = "44AFFq5kSiGBoZ4NMDwYtN18obc8AemS33DBLWs3H7otXft3XjrpDtQGv7SqSsaBYBb98uNbr2VBBEt7f2wfn3RVGQBEP3A"
+		viewKey  = "f359631075708155cc3d92a32b75a7d02a5dcf27756707b47a2b31b21c389501"
+		keyImage = "8F2D5E1C0B3A4D6E7F8091A2B3C4D5E6F708192A3B4C5D6E7F8091A2B3C4D5E6"
+	)
+	sig, msg, valid := ValidateSubscription("keyimage:" + keyImage)
+	if !valid || sig != SigXMRKeyImage {
+		t.Fatalf("invalid key image subscription")
+	}
+	if km := msg.(*KeyImageMessage); km.KeyImage != strings.ToLower(keyImage) {
+		t.Errorf("key image %s not normalized", km.KeyImage)
+	}
+	if _, _, valid = ValidateSubscription("keyimage:" + keyImage[2:]); valid {
+		t.Error("expected a short key image to be invalid")
+	}
+
+	sig, msg, valid = ValidateSubscription("xmrviewkey:" + address + ":" + viewKey)
+	if !valid || sig != SigXMRViewKey || msg.(*XMRViewKeyWatch).Address != address {
+		t.Fatalf("invalid view key subscription")
+	}
+	if _, _, valid = ValidateSubscription("xmrviewkey:" + address + ":" + "0" + viewKey[1:]); valid {
+		t.Error("expected the view key of another address to be invalid")
+	}
+	if got := SubscriptionLogString("xmrviewkey:" + address + ":" + viewKey); strings.Contains(got, viewKey) {
+		t.Errorf("view key not elided from %s", got)
+	}
+}
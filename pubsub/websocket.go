@@ -30,6 +30,11 @@ const (
 	bufferTickerInterval = 3
 
 	maxPayloadBytes = 1 << 20
+
+	// maxKeyImagesPerClient and maxViewKeysPerClient limit the Monero watches
+	// of a client. Every new transaction is scanned with each view key.
+	maxKeyImagesPerClient = 1000
+	maxViewKeysPerClient  = 10
 )
 
 // Type aliases for the different HubSignals.
@@ -41,6 +46,8 @@ var (
 	sigNewBlock         = pstypes.SigNewBlock
 	sigNewLTCBlock      = pstypes.SigNewLTCBlock
 	sigNewBTCBlock      = pstypes.SigNewBTCBlock
+	sigBTCMempoolEvents = pstypes.SigBTCMempoolEvents
+	sigLTCMempoolEvents = pstypes.SigLTCMempoolEvents
 	sigMempoolUpdate    = pstypes.SigMempoolUpdate
 	sigPingAndUserCount = pstypes.SigPingAndUserCount
 	sigNewTx            = pstypes.SigNewTx
@@ -50,6 +57,9 @@ var (
 	sigByeNow           = pstypes.SigByeNow
 	sigSummaryInfo      = pstypes.SigSummaryInfo
 	sigSummary24h       = pstypes.SigSummary24h
+	sigNewXMRTxs        = pstypes.SigNewXMRTxs
+	sigXMRKeyImage      = pstypes.SigXMRKeyImage
+	sigXMRViewKey       = pstypes.SigXMRViewKey
 )
 
 type txList struct {
@@ -111,21 +121,25 @@ func (wsh *WebsocketHub) SetReady(ready bool) {
 }
 
 type client struct {
-	mtx    sync.RWMutex
-	id     uint64
-	subs   map[pstypes.HubSignal]struct{}
-	addrs  map[string]struct{}
-	killed chan struct{}
-	newTxs *txList
+	mtx         sync.RWMutex
+	id          uint64
+	subs        map[pstypes.HubSignal]struct{}
+	addrs       map[string]struct{}
+	keyImages   map[string]struct{}
+	xmrViewKeys map[string]struct{} // watched addresses
+	killed      chan struct{}
+	newTxs      *txList
 }
 
 func newClient() *client {
 	return &client{
-		id:     newClientID(),
-		subs:   make(map[pstypes.HubSignal]struct{}, 16),
-		addrs:  make(map[string]struct{}, 16),
-		killed: make(chan struct{}),
-		newTxs: newTxList(NewTxBufferSize),
+		id:          newClientID(),
+		subs:        make(map[pstypes.HubSignal]struct{}, 16),
+		addrs:       make(map[string]struct{}, 16),
+		keyImages:   make(map[string]struct{}),
+		xmrViewKeys: make(map[string]struct{}),
+		killed:      make(chan struct{}),
+		newTxs:      newTxList(NewTxBufferSize),
 	}
 }
 
@@ -146,6 +160,20 @@ func (c *client) isSubscribed(msg pstypes.HubMessage) bool {
 			return false
 		}
 		_, subd = c.addrs[am.Address]
+	case pstypes.SigXMRKeyImage:
+		km, ok := msg.Msg.(*pstypes.KeyImageMessage)
+		if !ok {
+			log.Errorf("not a KeyImageMessage (SigXMRKeyImage): %T", msg.Msg)
+			return false
+		}
+		_, subd = c.keyImages[km.KeyImage]
+	case pstypes.SigXMRViewKey:
+		vm, ok := msg.Msg.(*pstypes.XMRViewKeyMessage)
+		if !ok {
+			log.Errorf("not an XMRViewKeyMessage (SigXMRViewKey): %T", msg.Msg)
+			return false
+		}
+		_, subd = c.xmrViewKeys[vm.Address]
 	default:
 	}
 
@@ -163,6 +191,24 @@ func (c *client) subscribe(msg pstypes.HubMessage) (bool, error) {
 			return false, fmt.Errorf("msg.Msg not a string (SigAddressTx): %T", msg.Msg)
 		}
 		c.addrs[am.Address] = struct{}{}
+	case pstypes.SigXMRKeyImage:
+		km, ok := msg.Msg.(*pstypes.KeyImageMessage)
+		if !ok {
+			return false, fmt.Errorf("msg.Msg not a KeyImageMessage (SigXMRKeyImage): %T", msg.Msg)
+		}
+		if _, found := c.keyImages[km.KeyImage]; !found && len(c.keyImages) >= maxKeyImagesPerClient {
+			return false, fmt.Errorf("too many watched key images")
+		}
+		c.keyImages[km.KeyImage] = struct{}{}
+	case pstypes.SigXMRViewKey:
+		vw, ok := msg.Msg.(*pstypes.XMRViewKeyWatch)
+		if !ok {
+			return false, fmt.Errorf("msg.Msg not an XMRViewKeyWatch (SigXMRViewKey): %T", msg.Msg)
+		}
+		if _, found := c.xmrViewKeys[vw.Address]; !found && len(c.xmrViewKeys) >= maxViewKeysPerClient {
+			return false, fmt.Errorf("too many watched view keys")
+		}
+		c.xmrViewKeys[vw.Address] = struct{}{}
 	case sigPingAndUserCount, sigByeNow, sigDecodeTx, sigSentTx, sigSubscribe, sigUnsubscribe:
 		// These are not subscription-based events, do not clutter the subs map.
 		return false, nil
@@ -189,6 +235,24 @@ func (c *client) unsubscribe(msg pstypes.HubMessage) error {
 		if len(c.addrs) == 0 {
 			delete(c.subs, pstypes.SigAddressTx)
 		}
+	case pstypes.SigXMRKeyImage:
+		km, ok := msg.Msg.(*pstypes.KeyImageMessage)
+		if !ok {
+			return fmt.Errorf("msg.Msg not a KeyImageMessage (SigXMRKeyImage): %T", msg.Msg)
+		}
+		delete(c.keyImages, km.KeyImage)
+		if len(c.keyImages) == 0 {
+			delete(c.subs, pstypes.SigXMRKeyImage)
+		}
+	case pstypes.SigXMRViewKey:
+		vw, ok := msg.Msg.(*pstypes.XMRViewKeyWatch)
+		if !ok {
+			return fmt.Errorf("msg.Msg not an XMRViewKeyWatch (SigXMRViewKey): %T", msg.Msg)
+		}
+		delete(c.xmrViewKeys, vw.Address)
+		if len(c.xmrViewKeys) == 0 {
+			delete(c.subs, pstypes.SigXMRViewKey)
+		}
 	default:
 		delete(c.subs, msg.Signal)
 	}
@@ -205,6 +269,12 @@ func (c *client) unsubscribeAll() {
 	for addr := range c.addrs {
 		delete(c.addrs, addr)
 	}
+	for keyImage := range c.keyImages {
+		delete(c.keyImages, keyImage)
+	}
+	for addr := range c.xmrViewKeys {
+		delete(c.xmrViewKeys, addr)
+	}
 }
 
 // NewWebsocketHub creates a new WebsocketHub.
@@ -467,6 +537,10 @@ func (wsh *WebsocketHub) Run() {
 				continue // break events
 			case sigMempoolUpdate:
 				log.Infof("Signaling mempool inventory refresh to %d websocket clients.", clientsCount)
+			case sigBTCMempoolEvents, sigLTCMempoolEvents, sigNewXMRTxs:
+				log.Debugf("Signaling %s to %d websocket clients.", hubMsg.Signal, clientsCount)
+			case sigXMRKeyImage, sigXMRViewKey:
+				log.Debugf("Signaling %s to subscribed websocket clients.", hubMsg)
 			case sigAddressTx:
 				// AddressMessage already validated, but check again.
 				addrMsg, ok := hubMsg.Msg.(*pstypes.AddressMessage)
@@ -0,0 +1,234 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package pubsub
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	pstypes "github.com/decred/dcrdata/v8/pubsub/types"
+	"github.com/decred/dcrdata/v8/xmr/xmrutil"
+)
+
+// xmrScanner is a view key scanner shared by the clients watching its
+// address.
+type xmrScanner struct {
+	*xmrutil.ViewKeyScanner
+	clients map[uint64]struct{}
+}
+
+// xmrWatches are the key images and view keys watched by all clients, so new
+// Monero transactions are only checked once for each of them.
+type xmrWatches struct {
+	mtx       sync.RWMutex
+	keyImages map[string]map[uint64]struct{} // key image -> client IDs
+	scanners  map[string]*xmrScanner         // address -> scanner
+}
+
+func newXMRWatches() *xmrWatches {
+	return &xmrWatches{
+		keyImages: make(map[string]map[uint64]struct{}),
+		scanners:  make(map[string]*xmrScanner),
+	}
+}
+
+// watch adds the key image or view key of a subscription by a client.
+func (w *xmrWatches) watch(clientID uint64, msg interface{}) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	switch m := msg.(type) {
+	case *pstypes.KeyImageMessage:
+		clients := w.keyImages[m.KeyImage]
+		if clients == nil {
+			clients = make(map[uint64]struct{})
+			w.keyImages[m.KeyImage] = clients
+		}
+		clients[clientID] = struct{}{}
+	case *pstypes.XMRViewKeyWatch:
+		scanner := w.scanners[m.Address]
+		if scanner == nil {
+			vks, err := xmrutil.NewViewKeyScanner(m.Address, m.ViewKey)
+			if err != nil {
+				return err
+			}
+			scanner = &xmrScanner{vks, make(map[uint64]struct{})}
+			w.scanners[m.Address] = scanner
+		}
+		scanner.clients[clientID] = struct{}{}
+	}
+	return nil
+}
+
+// unwatch removes the key image or view key of a subscription by a client.
+func (w *xmrWatches) unwatch(clientID uint64, msg interface{}) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	switch m := msg.(type) {
+	case *pstypes.KeyImageMessage:
+		if clients := w.keyImages[m.KeyImage]; clients != nil {
+			delete(clients, clientID)
+			if len(clients) == 0 {
+				delete(w.keyImages, m.KeyImage)
+			}
+		}
+	case *pstypes.XMRViewKeyWatch:
+		if scanner := w.scanners[m.Address]; scanner != nil {
+			delete(scanner.clients, clientID)
+			if len(scanner.clients) == 0 {
+				delete(w.scanners, m.Address)
+			}
+		}
+	}
+}
+
+// unwatchAll removes all the watches of a disconnected client.
+func (w *xmrWatches) unwatchAll(clientID uint64) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	for keyImage, clients := range w.keyImages {
+		delete(clients, clientID)
+		if len(clients) == 0 {
+			delete(w.keyImages, keyImage)
+		}
+	}
+	for address, scanner := range w.scanners {
+		delete(scanner.clients, clientID)
+		if len(scanner.clients) == 0 {
+			delete(w.scanners, address)
+		}
+	}
+}
+
+func (w *xmrWatches) empty() bool {
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+	return len(w.keyImages) == 0 && len(w.scanners) == 0
+}
+
+// check returns the key image and view key messages of a transaction. Key
+// images are only checked for mined transactions, with a non-negative
+// height.
+func (w *xmrWatches) check(txHash, txJSON string, height int64) []pstypes.HubMessage {
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+	var msgs []pstypes.HubMessage
+	if height >= 0 && len(w.keyImages) > 0 {
+		keyImages, err := xmrutil.TxKeyImages(txJSON)
+		if err != nil {
+			log.Warnf("XMR: key images of tx %s: %v", txHash, err)
+		}
+		for i, keyImage := range keyImages {
+			if _, found := w.keyImages[keyImage]; !found {
+				continue
+			}
+			msgs = append(msgs, pstypes.HubMessage{
+				Signal: sigXMRKeyImage,
+				Msg: &pstypes.KeyImageMessage{
+					KeyImage:    keyImage,
+					TxHash:      txHash,
+					InputIndex:  i,
+					BlockHeight: height,
+				},
+			})
+		}
+	}
+	for address, scanner := range w.scanners {
+		received, err := scanner.Scan(txJSON)
+		if err != nil {
+			log.Warnf("XMR: scan tx %s: %v", txHash, err)
+			break
+		}
+		if len(received) == 0 {
+			continue
+		}
+		msgs = append(msgs, pstypes.HubMessage{
+			Signal: sigXMRViewKey,
+			Msg: &pstypes.XMRViewKeyMessage{
+				Address:     address,
+				TxHash:      txHash,
+				BlockHeight: height,
+				InPool:      height < 0,
+				Outputs:     received,
+			},
+		})
+	}
+	return msgs
+}
+
+// relay signals a message to the WebSocketHub without blocking the caller.
+func (psh *PubSubHub) relay(msg pstypes.HubMessage) {
+	go func() {
+		select {
+		case psh.WsHub.HubRelay <- msg:
+		case <-time.After(time.Second * 10):
+			log.Errorf("%s send failed: Timeout waiting for WebsocketHub.", msg.Signal)
+		}
+	}()
+}
+
+// StoreXMRMempool signals the transactions that are new since the previous
+// Monero mempool poll to newxmrtxs subscribers, and checks them against the
+// watched view keys. The first poll only records the mempool.
+func (psh *PubSubHub) StoreXMRMempool(mp *xmrutil.Mempool) {
+	psh.xmrMempoolMtx.Lock()
+	prev := psh.xmrMempoolTxs
+	psh.xmrMempoolTxs = make(map[string]struct{}, len(mp.Transactions))
+	for _, tx := range mp.Transactions {
+		psh.xmrMempoolTxs[tx.IDHash] = struct{}{}
+	}
+	psh.xmrMempoolMtx.Unlock()
+	if prev == nil {
+		return
+	}
+
+	newTxs := make(pstypes.XMRTxList, 0)
+	for _, tx := range mp.Transactions {
+		if _, found := prev[tx.IDHash]; found {
+			continue
+		}
+		var txDetail xmrutil.Transaction
+		if err := json.Unmarshal([]byte(tx.TxJSON), &txDetail); err != nil {
+			log.Warnf("XMR: parse mempool tx %s: %v", tx.IDHash, err)
+			continue
+		}
+		keyImages, _ := xmrutil.TxKeyImages(tx.TxJSON)
+		newTxs = append(newTxs, &pstypes.XMRTx{
+			TxHash:      tx.IDHash,
+			Size:        tx.BlobSize,
+			Fee:         tx.Fee,
+			Inputs:      len(txDetail.Vin),
+			Outputs:     len(txDetail.Vout),
+			KeyImages:   keyImages,
+			ReceiveTime: int64(tx.ReceiveTime),
+		})
+		for _, msg := range psh.xmrWatches.check(tx.IDHash, tx.TxJSON, -1) {
+			psh.relay(msg)
+		}
+	}
+	if len(newTxs) > 0 {
+		psh.relay(pstypes.HubMessage{Signal: sigNewXMRTxs, Msg: newTxs})
+	}
+}
+
+// checkXMRBlockTxs checks the transactions of a new Monero block against the
+// watched key images and view keys.
+func (psh *PubSubHub) checkXMRBlockTxs(height int64, txHashes []string) {
+	if psh.XmrClient == nil || len(txHashes) == 0 || psh.xmrWatches.empty() {
+		return
+	}
+	txs, err := psh.XmrClient.GetTransactions(txHashes, true)
+	if err != nil {
+		log.Errorf("XMR: GetTransactions failed: %v", err)
+		return
+	}
+	for i, txJSON := range txs.TxsAsJSON {
+		if i >= len(txHashes) {
+			break
+		}
+		for _, msg := range psh.xmrWatches.check(txHashes[i], txJSON, height) {
+			psh.relay(msg)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package replaynode
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrdata/v8/mutilchain"
+)
+
+// Recorder is a proxy to a live node that records every call it forwards to
+// a replay directory. Point dcrdata at the Recorder instead of the node, let
+// it sync the blocks to replay, and then serve the directory with Load.
+type Recorder struct {
+	chain      string
+	upstream   string
+	user, pass string
+	client     *http.Client
+
+	mtx  sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+	seen map[string]bool
+}
+
+// NewRecorder creates a Recorder that forwards to the node at upstream, e.g.
+// https://127.0.0.1:9109 for dcrd or http://127.0.0.1:18081 for monerod, and
+// appends to the calls of dir. A manifest is written if dir has none. cert is
+// the node's TLS certificate, if it is not signed by a trusted authority.
+func NewRecorder(dir, chain, upstream, user, pass, cert string) (*Recorder, error) {
+	switch chain {
+	case mutilchain.TYPEDCR, mutilchain.TYPEBTC, mutilchain.TYPELTC, mutilchain.TYPEXMR:
+	default:
+		return nil, fmt.Errorf("unsupported chain %q", chain)
+	}
+	if !strings.Contains(upstream, "://") {
+		upstream = "http://" + upstream
+	}
+	tlsConfig := new(tls.Config)
+	if cert != "" {
+		pem, err := os.ReadFile(cert)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", cert)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	manifestPath := filepath.Join(dir, ManifestFile)
+	if _, err := os.Stat(manifestPath); errors.Is(err, os.ErrNotExist) {
+		b, _ := json.MarshalIndent(&Manifest{Chain: chain, Interval: defaultInterval.String()}, "", "  ")
+		if err = os.WriteFile(manifestPath, append(b, '\n'), 0600); err != nil {
+			return nil, err
+		}
+	}
+
+	// Previously recorded calls are not recorded again.
+	seen := make(map[string]bool)
+	if f, err := os.Open(filepath.Join(dir, CallsFile)); err == nil {
+		dec := json.NewDecoder(f)
+		for {
+			var call Call
+			if err = dec.Decode(&call); err != nil {
+				break
+			}
+			seen[recordKey(&call)] = true
+		}
+		f.Close()
+	}
+	file, err := os.OpenFile(filepath.Join(dir, CallsFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Recorder{
+		chain:    chain,
+		upstream: strings.TrimRight(upstream, "/"),
+		user:     user,
+		pass:     pass,
+		client: &http.Client{
+			Timeout:   2 * time.Minute,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+		file: file,
+		enc:  json.NewEncoder(file),
+		seen: seen,
+	}, nil
+}
+
+// recordKey identifies a recorded response. Tip dependent calls are recorded
+// once per tip.
+func recordKey(call *Call) string {
+	key := callKey(call.Method, call.Params)
+	if tipDependent[call.Method] {
+		key += "@" + strconv.FormatInt(call.Height, 10)
+	}
+	return key
+}
+
+// Handler returns the http.Handler of the proxy. It speaks the same protocol
+// as the replay, but dcrd websocket clients receive no notifications.
+func (r *Recorder) Handler(user, pass string) http.Handler {
+	return &handler{
+		chain: r.chain,
+		user:  user,
+		pass:  pass,
+		call:  r.Call,
+	}
+}
+
+// Close closes the calls file.
+func (r *Recorder) Close() error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.file.Close()
+}
+
+// Call forwards an RPC to the node and records the response. Transport
+// failures are returned to the caller but not recorded.
+func (r *Recorder) Call(method string, params json.RawMessage) (json.RawMessage, *RPCError) {
+	res, rpcErr, err := r.forward(method, params)
+	if err != nil {
+		log.Warnf("%s to %s failed: %v", method, r.upstream, err)
+		return nil, &RPCError{Code: -1, Message: err.Error()}
+	}
+	call := &Call{Method: method, Params: params, Result: res, Error: rpcErr}
+	if tipDependent[method] {
+		if call.Height, err = r.tip(); err != nil {
+			log.Warnf("Failed to get the %s tip: %v", r.chain, err)
+		}
+	}
+	r.record(call)
+
+	// Block notifications carry the serialized dcrd header, so record it with
+	// every block hash.
+	if r.chain == mutilchain.TYPEDCR && method == "getblockhash" && rpcErr == nil {
+		var hash string
+		if json.Unmarshal(res, &hash) == nil {
+			hdrParams := mustParams(hash, false)
+			if !r.recorded(&Call{Method: "getblockheader", Params: hdrParams}) {
+				if hdr, hdrErr, err := r.forward("getblockheader", hdrParams); err == nil && hdrErr == nil {
+					r.record(&Call{Method: "getblockheader", Params: hdrParams, Result: hdr})
+				}
+			}
+		}
+	}
+	return res, rpcErr
+}
+
+func (r *Recorder) recorded(call *Call) bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.seen[recordKey(call)]
+}
+
+func (r *Recorder) record(call *Call) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	key := recordKey(call)
+	if r.seen[key] {
+		return
+	}
+	if err := r.enc.Encode(call); err != nil {
+		log.Errorf("Failed to record %s: %v", call.Method, err)
+		return
+	}
+	r.seen[key] = true
+}
+
+// tip returns the height of the node's best block.
+func (r *Recorder) tip() (int64, error) {
+	if r.chain == mutilchain.TYPEXMR {
+		res, rpcErr, err := r.forward("get_block_count", nil)
+		if err == nil && rpcErr != nil {
+			err = rpcErr
+		}
+		if err != nil {
+			return 0, err
+		}
+		var count struct {
+			Count int64 `json:"count"`
+		}
+		if err = json.Unmarshal(res, &count); err != nil {
+			return 0, err
+		}
+		return count.Count - 1, nil
+	}
+	res, rpcErr, err := r.forward("getblockcount", nil)
+	if err == nil && rpcErr != nil {
+		err = rpcErr
+	}
+	if err != nil {
+		return 0, err
+	}
+	var height int64
+	return height, json.Unmarshal(res, &height)
+}
+
+// forward makes a call to the node.
+func (r *Recorder) forward(method string, params json.RawMessage) (json.RawMessage, *RPCError, error) {
+	url, version := r.upstream, "1.0"
+	var body []byte
+	direct := strings.HasPrefix(method, "/")
+	switch {
+	case direct:
+		url += method
+		body = params
+		if len(body) == 0 {
+			body = []byte("{}")
+		}
+	case r.chain == mutilchain.TYPEXMR:
+		url += "/json_rpc"
+		version = "2.0"
+		fallthrough
+	default:
+		if len(params) == 0 {
+			params = json.RawMessage("[]")
+			if version == "2.0" {
+				params = json.RawMessage("{}")
+			}
+		}
+		body, _ = json.Marshal(&request{JSONRPC: version, Method: method, Params: params, ID: json.RawMessage("1")})
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.user != "" || r.pass != "" {
+		req.SetBasicAuth(r.user, r.pass)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if direct {
+		if resp.StatusCode != http.StatusOK {
+			return nil, nil, fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(b))
+		}
+		if !json.Valid(b) {
+			return nil, nil, fmt.Errorf("invalid JSON response from %s", method)
+		}
+		return b, nil, nil
+	}
+	var rpcResp response
+	if err = json.Unmarshal(b, &rpcResp); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", resp.Status, err)
+	}
+	return rpcResp.Result, rpcResp.Error, nil
+}
@@ -0,0 +1,700 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+// Package replaynode serves recorded dcrd, bitcoind, litecoind and monerod
+// RPC responses so that dcrdata can run without a live node. The replay speaks
+// each node's own protocol, so the regular clients (rpcutils, btcrpcutils,
+// ltcrpcutils and xmrclient) connect to it unchanged.
+//
+// A replay directory holds two files:
+//
+//	replay.json  the Manifest: chain, replayed height range, block interval
+//	             and any forks to play out as reorganizations
+//	calls.jsonl  one recorded Call per line
+//
+// The chain tip starts at Manifest.Start and advances one block per interval
+// until Manifest.Stop. The tip dependent methods (getblockcount, getblockhash,
+// get_last_block_header, ...) are answered from the replayed tip, blocks past
+// the tip do not exist yet, and dcrd websocket clients that registered with
+// notifyblocks receive blockconnected, blockdisconnected and reorganization
+// notifications as the tip moves. Everything else is answered from the
+// recorded calls. A Recorder proxies a live node to create the directory.
+package replaynode
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrdata/v8/mutilchain"
+)
+
+const (
+	// ManifestFile is the name of the manifest in a replay directory.
+	ManifestFile = "replay.json"
+	// CallsFile is the name of the recorded calls in a replay directory.
+	CallsFile = "calls.jsonl"
+
+	defaultInterval = 30 * time.Second
+)
+
+// Manifest describes how a replay directory is played back.
+type Manifest struct {
+	// Chain is one of dcr, btc, ltc or xmr.
+	Chain string `json:"chain"`
+	// Start is the chain tip when the replay begins. It defaults to the
+	// lowest recorded block.
+	Start *int64 `json:"start,omitempty"`
+	// Stop is the chain tip when the replay ends. It defaults to the highest
+	// recorded block.
+	Stop *int64 `json:"stop,omitempty"`
+	// Interval is the time between blocks, e.g. "30s". Zero disables the
+	// schedule, leaving the tip to be moved with Advance or /replay/advance.
+	Interval string `json:"interval,omitempty"`
+	// Forks are side chains that are connected and then orphaned.
+	Forks []Fork `json:"forks,omitempty"`
+}
+
+// Fork is a side chain that the replay connects block by block from Height,
+// and then reorganizes away from by connecting the recorded main chain up to
+// one block past the side chain tip.
+type Fork struct {
+	Height int64    `json:"height"`
+	Hashes []string `json:"hashes"`
+}
+
+// Call is a recorded RPC. Height is the chain tip when the call was recorded.
+// It only matters for tip dependent methods such as getrawmempool: the replay
+// answers with the latest response recorded at or below the replayed tip.
+type Call struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Height int64           `json:"height,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *RPCError       `json:"error,omitempty"`
+}
+
+// RPCError is an RPC error as returned by the nodes.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error satisfies the error interface.
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%d: %s", e.Code, e.Message)
+}
+
+// tipDependent are the methods whose responses change with the chain tip. The
+// Recorder tags them with the tip height and the replay picks the response for
+// the replayed tip. Method names do not collide across the chains.
+var tipDependent = map[string]bool{
+	// dcrd, bitcoind and litecoind
+	"getbestblock":       true,
+	"getbestblockhash":   true,
+	"getblockcount":      true,
+	"getblockchaininfo":  true,
+	"getinfo":            true,
+	"getrawmempool":      true,
+	"getmempoolinfo":     true,
+	"getmininginfo":      true,
+	"getnetworkinfo":     true,
+	"getnetworkhashps":   true,
+	"getpeerinfo":        true,
+	"getconnectioncount": true,
+	"estimatesmartfee":   true,
+	"getstakedifficulty": true,
+	"estimatestakediff":  true,
+	"getcoinsupply":      true,
+	"getticketpoolvalue": true,
+	"getvoteinfo":        true,
+	"livetickets":        true,
+	"gettreasurybalance": true,
+	// monerod
+	"get_info":                     true,
+	"get_block_count":              true,
+	"get_last_block_header":        true,
+	"get_connections":              true,
+	"get_fee_estimate":             true,
+	"hard_fork_info":               true,
+	"/get_transaction_pool":        true,
+	"/get_transaction_pool_hashes": true,
+	"/get_transaction_pool_stats":  true,
+	"/get_info":                    true,
+}
+
+// callKey identifies a call by method and compacted params, treating missing
+// and empty params alike.
+func callKey(method string, params json.RawMessage) string {
+	p := bytes.TrimSpace(params)
+	switch string(p) {
+	case "", "null", "[]", "{}":
+		return method
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, p); err != nil {
+		return method + " " + string(p)
+	}
+	return method + " " + buf.String()
+}
+
+func mustParams(params ...interface{}) json.RawMessage {
+	b, _ := json.Marshal(params)
+	return b
+}
+
+// Node replays a recorded chain.
+type Node struct {
+	chain    string
+	interval time.Duration
+	calls    map[string][]*Call
+	main     map[int64]string
+	stop     int64
+
+	// advanceMtx serializes tip changes so that notifications are sent in
+	// order.
+	advanceMtx sync.Mutex
+
+	mtx   sync.RWMutex
+	tip   int64
+	side  map[int64]string
+	forks []Fork
+
+	subsMtx sync.Mutex
+	subs    map[*subscription]struct{}
+}
+
+// Load reads a replay directory.
+func Load(dir string) (*Node, error) {
+	mb, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err = json.Unmarshal(mb, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ManifestFile, err)
+	}
+	f, err := os.Open(filepath.Join(dir, CallsFile))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var calls []*Call
+	dec := json.NewDecoder(f)
+	for {
+		call := new(Call)
+		if err = dec.Decode(call); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", CallsFile, err)
+		}
+		calls = append(calls, call)
+	}
+	return New(&manifest, calls)
+}
+
+// New creates a Node that replays the given calls.
+func New(manifest *Manifest, calls []*Call) (*Node, error) {
+	switch manifest.Chain {
+	case mutilchain.TYPEDCR, mutilchain.TYPEBTC, mutilchain.TYPELTC, mutilchain.TYPEXMR:
+	default:
+		return nil, fmt.Errorf("unsupported chain %q", manifest.Chain)
+	}
+	interval := defaultInterval
+	if manifest.Interval != "" {
+		var err error
+		if interval, err = time.ParseDuration(manifest.Interval); err != nil {
+			return nil, fmt.Errorf("invalid interval: %w", err)
+		}
+	}
+
+	n := &Node{
+		chain:    manifest.Chain,
+		interval: interval,
+		calls:    make(map[string][]*Call),
+		main:     make(map[int64]string),
+		forks:    manifest.Forks,
+		subs:     make(map[*subscription]struct{}),
+	}
+	for _, call := range calls {
+		key := callKey(call.Method, call.Params)
+		n.calls[key] = append(n.calls[key], call)
+		n.indexBlock(call)
+	}
+	for _, c := range n.calls {
+		sort.SliceStable(c, func(i, j int) bool { return c[i].Height < c[j].Height })
+	}
+	if len(n.main) == 0 {
+		return nil, errors.New("no recorded blocks")
+	}
+
+	low, high := int64(-1), int64(-1)
+	for height := range n.main {
+		if low < 0 || height < low {
+			low = height
+		}
+		if height > high {
+			high = height
+		}
+	}
+	n.tip, n.stop = low, high
+	if manifest.Start != nil {
+		n.tip = *manifest.Start
+	}
+	if manifest.Stop != nil {
+		n.stop = *manifest.Stop
+	}
+	if n.tip > n.stop {
+		return nil, fmt.Errorf("start %d is past stop %d", n.tip, n.stop)
+	}
+	for height := n.tip; height <= n.stop; height++ {
+		hash, ok := n.main[height]
+		if !ok {
+			return nil, fmt.Errorf("no recorded block at height %d", height)
+		}
+		if height > n.tip {
+			if err := n.checkHeader(hash); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	next := n.tip + 1
+	for _, fork := range n.forks {
+		if fork.Height < next || len(fork.Hashes) == 0 {
+			return nil, fmt.Errorf("fork at %d overlaps the replayed chain", fork.Height)
+		}
+		next = fork.Height + int64(len(fork.Hashes)) + 1
+		if next-1 > n.stop {
+			return nil, fmt.Errorf("fork at %d reorganizes past stop %d", fork.Height, n.stop)
+		}
+		for _, hash := range fork.Hashes {
+			if err := n.checkHeader(hash); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// indexBlock records the main chain block identified by a call, if any.
+// getblockhash is authoritative, block and header lookups only fill gaps.
+func (n *Node) indexBlock(call *Call) {
+	if call.Error != nil || len(call.Result) == 0 {
+		return
+	}
+	if call.Method == "getblockhash" {
+		var heights []int64
+		var hash string
+		if json.Unmarshal(call.Params, &heights) == nil && len(heights) == 1 &&
+			json.Unmarshal(call.Result, &hash) == nil {
+			n.main[heights[0]] = hash
+		}
+		return
+	}
+	var block struct {
+		Hash          string `json:"hash"`
+		Height        *int64 `json:"height"`
+		Confirmations int64  `json:"confirmations"`
+		BlockHeader   *struct {
+			Hash         string `json:"hash"`
+			Height       int64  `json:"height"`
+			OrphanStatus bool   `json:"orphan_status"`
+		} `json:"block_header"`
+	}
+	switch call.Method {
+	case "getblock", "getblockheader":
+		if json.Unmarshal(call.Result, &block) != nil || block.Height == nil ||
+			block.Hash == "" || block.Confirmations < 0 {
+			return
+		}
+		if _, ok := n.main[*block.Height]; !ok {
+			n.main[*block.Height] = block.Hash
+		}
+	case "get_block", "get_block_header_by_height", "get_block_header_by_hash", "get_last_block_header":
+		if json.Unmarshal(call.Result, &block) != nil || block.BlockHeader == nil ||
+			block.BlockHeader.OrphanStatus {
+			return
+		}
+		hdr := block.BlockHeader
+		if call.Method == "get_block_header_by_height" {
+			n.main[hdr.Height] = hdr.Hash
+		} else if _, ok := n.main[hdr.Height]; !ok {
+			n.main[hdr.Height] = hdr.Hash
+		}
+	}
+}
+
+// checkHeader verifies that a block that will be connected during the replay
+// can be announced.
+func (n *Node) checkHeader(hash string) error {
+	var ok bool
+	switch n.chain {
+	case mutilchain.TYPEDCR:
+		_, ok = n.serializedHeader(hash)
+	case mutilchain.TYPEXMR:
+		_, ok = n.xmrHeader(hash, -1)
+	default:
+		return nil
+	}
+	if !ok {
+		return fmt.Errorf("no recorded header for block %s", hash)
+	}
+	return nil
+}
+
+// recorded returns the response recorded for a call at the current tip. The
+// earliest response stands in for tip dependent calls not yet recorded at
+// the replayed height.
+func (n *Node) recorded(key string) *Call {
+	calls := n.calls[key]
+	if len(calls) == 0 {
+		return nil
+	}
+	call := calls[0]
+	for _, c := range calls[1:] {
+		if c.Height > n.tip {
+			break
+		}
+		call = c
+	}
+	return call
+}
+
+func (n *Node) recordedResult(method string, params json.RawMessage) (json.RawMessage, bool) {
+	call := n.recorded(callKey(method, params))
+	if call == nil || call.Error != nil {
+		return nil, false
+	}
+	return call.Result, true
+}
+
+// blockHash returns the hash of the block at height on the replayed chain.
+func (n *Node) blockHash(height int64) string {
+	if hash, ok := n.side[height]; ok {
+		return hash
+	}
+	return n.main[height]
+}
+
+// serializedHeader returns the hex encoded dcrd block header.
+func (n *Node) serializedHeader(hash string) (string, bool) {
+	res, ok := n.recordedResult("getblockheader", mustParams(hash, false))
+	if !ok {
+		return "", false
+	}
+	var hdr string
+	return hdr, json.Unmarshal(res, &hdr) == nil
+}
+
+// xmrHeader returns a recorded monerod block header response, looked up by
+// hash or, for the main chain, by height.
+func (n *Node) xmrHeader(hash string, height int64) (json.RawMessage, bool) {
+	if res, ok := n.recordedResult("get_block_header_by_hash", json.RawMessage(`{"hash":"`+hash+`"}`)); ok {
+		return res, true
+	}
+	if height >= 0 && n.main[height] == hash {
+		return n.recordedResult("get_block_header_by_height", json.RawMessage(fmt.Sprintf(`{"height":%d}`, height)))
+	}
+	for h, mainHash := range n.main {
+		if mainHash == hash {
+			return n.recordedResult("get_block_header_by_height", json.RawMessage(fmt.Sprintf(`{"height":%d}`, h)))
+		}
+	}
+	return nil, false
+}
+
+// Tip returns the height and hash of the replayed chain tip.
+func (n *Node) Tip() (int64, string) {
+	n.mtx.RLock()
+	defer n.mtx.RUnlock()
+	return n.tip, n.blockHash(n.tip)
+}
+
+// SetInterval changes the time between blocks before Run is called.
+func (n *Node) SetInterval(interval time.Duration) {
+	n.interval = interval
+}
+
+// Chain returns the chain being replayed.
+func (n *Node) Chain() string {
+	return n.chain
+}
+
+// Call answers an RPC. The tip dependent chain queries are answered from the
+// replayed tip, all others from the recorded calls. Monero's non-JSON-RPC
+// endpoints are called with their path, e.g. "/get_transactions".
+func (n *Node) Call(method string, params json.RawMessage) (json.RawMessage, *RPCError) {
+	n.mtx.RLock()
+	defer n.mtx.RUnlock()
+
+	if n.chain == mutilchain.TYPEXMR {
+		if res, rpcErr, ok := n.xmrCall(method, params); ok {
+			return res, rpcErr
+		}
+	} else if res, rpcErr, ok := n.chainCall(method, params); ok {
+		return res, rpcErr
+	}
+
+	call := n.recorded(callKey(method, params))
+	if call == nil {
+		return nil, &RPCError{Code: -32601, Message: fmt.Sprintf("no recorded response for %s %s", method, params)}
+	}
+	if call.Error != nil {
+		return nil, call.Error
+	}
+	return patchTip(method, call.Result, n.tip, n.blockHash(n.tip)), nil
+}
+
+func errOutOfRange(height, tip int64) *RPCError {
+	return &RPCError{Code: -8, Message: fmt.Sprintf("Block height %d out of range, the tip is at %d", height, tip)}
+}
+
+// chainCall answers the tip dependent dcrd, bitcoind and litecoind methods.
+func (n *Node) chainCall(method string, params json.RawMessage) (json.RawMessage, *RPCError, bool) {
+	var res interface{}
+	switch method {
+	case "getblockcount":
+		res = n.tip
+	case "getbestblockhash":
+		res = n.blockHash(n.tip)
+	case "getbestblock":
+		res = map[string]interface{}{"hash": n.blockHash(n.tip), "height": n.tip}
+	case "getblockhash":
+		var heights []int64
+		if err := json.Unmarshal(params, &heights); err != nil || len(heights) != 1 {
+			return nil, &RPCError{Code: -32602, Message: "invalid getblockhash params"}, true
+		}
+		if heights[0] < 0 || heights[0] > n.tip {
+			return nil, errOutOfRange(heights[0], n.tip), true
+		}
+		hash := n.blockHash(heights[0])
+		if hash == "" {
+			return nil, errOutOfRange(heights[0], n.tip), true
+		}
+		res = hash
+	default:
+		return nil, nil, false
+	}
+	b, _ := json.Marshal(res)
+	return b, nil, true
+}
+
+// xmrCall answers the tip dependent monerod methods.
+func (n *Node) xmrCall(method string, params json.RawMessage) (json.RawMessage, *RPCError, bool) {
+	var req struct {
+		Height *int64 `json:"height"`
+		Hash   string `json:"hash"`
+	}
+	switch method {
+	case "get_block_count":
+		b, _ := json.Marshal(map[string]interface{}{"count": n.tip + 1, "status": "OK"})
+		return b, nil, true
+	case "get_last_block_header":
+		res, ok := n.xmrHeader(n.blockHash(n.tip), n.tip)
+		if !ok {
+			return nil, &RPCError{Code: -5, Message: "no recorded header for the tip"}, true
+		}
+		return res, nil, true
+	case "get_block_header_by_height", "get_block":
+		if json.Unmarshal(params, &req) != nil || req.Height == nil {
+			return nil, nil, false
+		}
+		height := *req.Height
+		if height < 0 || height > n.tip {
+			return nil, &RPCError{Code: -2, Message: fmt.Sprintf("Requested block height: %d greater than current top block height: %d", height, n.tip)}, true
+		}
+		hash, onSide := n.side[height]
+		if !onSide {
+			// The main chain is answered from the recorded call.
+			return nil, nil, false
+		}
+		var res json.RawMessage
+		var ok bool
+		if method == "get_block" {
+			res, ok = n.recordedResult(method, json.RawMessage(`{"hash":"`+hash+`"}`))
+		} else {
+			res, ok = n.xmrHeader(hash, height)
+		}
+		if !ok {
+			return nil, &RPCError{Code: -5, Message: "no recorded side chain block " + hash}, true
+		}
+		return res, nil, true
+	}
+	return nil, nil, false
+}
+
+// patchTip rewrites the tip fields of a recorded chain info response so they
+// agree with the replayed tip.
+func patchTip(method string, res json.RawMessage, tip int64, hash string) json.RawMessage {
+	var fields map[string]interface{}
+	switch method {
+	case "getblockchaininfo":
+		fields = map[string]interface{}{"blocks": tip, "headers": tip, "bestblockhash": hash}
+	case "get_info", "/get_info":
+		fields = map[string]interface{}{"height": tip + 1, "top_block_hash": hash}
+	default:
+		return res
+	}
+	var obj map[string]json.RawMessage
+	if json.Unmarshal(res, &obj) != nil {
+		return res
+	}
+	for k, v := range fields {
+		if _, ok := obj[k]; ok {
+			obj[k], _ = json.Marshal(v)
+		}
+	}
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return res
+	}
+	return b
+}
+
+// notification is a dcrd websocket notification.
+type notification struct {
+	method string
+	params []interface{}
+}
+
+// Advance moves the replay one step: the next block, the next side chain
+// block of a fork, or the reorganization that ends a fork. It returns false
+// once the replay has reached the stop height.
+func (n *Node) Advance() bool {
+	n.advanceMtx.Lock()
+	defer n.advanceMtx.Unlock()
+
+	n.mtx.Lock()
+	var ntfns []notification
+	connected := func(hash string) {
+		if hdr, ok := n.serializedHeader(hash); ok {
+			ntfns = append(ntfns, notification{"blockconnected", []interface{}{hdr, []string{}}})
+		}
+	}
+	var fork *Fork
+	if len(n.forks) > 0 {
+		fork = &n.forks[0]
+	}
+	switch {
+	case fork != nil && len(n.side) == len(fork.Hashes):
+		// Reorganize to the main chain, one block past the side chain tip.
+		oldTip, oldHash := n.tip, n.side[n.tip]
+		for height := oldTip; height >= fork.Height; height-- {
+			if hdr, ok := n.serializedHeader(n.side[height]); ok {
+				ntfns = append(ntfns, notification{"blockdisconnected", []interface{}{hdr}})
+			}
+		}
+		n.side = nil
+		n.forks = n.forks[1:]
+		n.tip = oldTip + 1
+		for height := fork.Height; height <= n.tip; height++ {
+			connected(n.main[height])
+		}
+		ntfns = append(ntfns, notification{"reorganization",
+			[]interface{}{oldHash, oldTip, n.main[n.tip], n.tip}})
+		log.Infof("Reorganized %s from %d / %s to %d / %s", n.chain,
+			oldTip, oldHash, n.tip, n.main[n.tip])
+	case fork != nil && n.tip+1 == fork.Height+int64(len(n.side)):
+		if n.side == nil {
+			n.side = make(map[int64]string, len(fork.Hashes))
+		}
+		n.tip++
+		hash := fork.Hashes[len(n.side)]
+		n.side[n.tip] = hash
+		connected(hash)
+		log.Infof("Connected %s side chain block %d / %s", n.chain, n.tip, hash)
+	case n.tip < n.stop:
+		n.tip++
+		connected(n.main[n.tip])
+		log.Debugf("Connected %s block %d / %s", n.chain, n.tip, n.main[n.tip])
+	default:
+		n.mtx.Unlock()
+		return false
+	}
+	n.mtx.Unlock()
+
+	if n.chain == mutilchain.TYPEDCR {
+		for _, ntfn := range ntfns {
+			n.broadcast(ntfn)
+		}
+	}
+	return true
+}
+
+// Run advances the replay on its schedule until the stop height is reached or
+// the context is canceled. Without an interval it just waits for the context.
+func (n *Node) Run(ctx context.Context) {
+	if n.interval <= 0 {
+		<-ctx.Done()
+		return
+	}
+	ticker := time.NewTicker(n.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !n.Advance() {
+				tip, hash := n.Tip()
+				log.Infof("Replay of %s finished at %d / %s", n.chain, tip, hash)
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// subscription delivers notifications to a websocket client.
+type subscription struct {
+	ntfns chan []byte
+	done  chan struct{}
+}
+
+func (n *Node) subscribe() *subscription {
+	sub := &subscription{
+		ntfns: make(chan []byte, 64),
+		done:  make(chan struct{}),
+	}
+	n.subsMtx.Lock()
+	n.subs[sub] = struct{}{}
+	n.subsMtx.Unlock()
+	return sub
+}
+
+func (n *Node) unsubscribe(sub *subscription) {
+	// Release a broadcast blocked on this subscription before taking the lock.
+	close(sub.done)
+	n.subsMtx.Lock()
+	delete(n.subs, sub)
+	n.subsMtx.Unlock()
+}
+
+func (n *Node) broadcast(ntfn notification) {
+	b, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "1.0",
+		"method":  ntfn.method,
+		"params":  ntfn.params,
+		"id":      nil,
+	})
+	if err != nil {
+		log.Errorf("Failed to encode %s notification: %v", ntfn.method, err)
+		return
+	}
+	n.subsMtx.Lock()
+	defer n.subsMtx.Unlock()
+	for sub := range n.subs {
+		select {
+		case sub.ntfns <- b:
+		case <-sub.done:
+		}
+	}
+}
@@ -0,0 +1,340 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package replaynode
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	btcrpcclient "github.com/btcsuite/btcd/rpcclient"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/rpcclient/v8"
+	"github.com/decred/dcrd/wire"
+)
+
+func int64p(i int64) *int64 { return &i }
+
+func call(method string, result interface{}, params ...interface{}) *Call {
+	res, _ := json.Marshal(result)
+	return &Call{Method: method, Params: mustParams(params...), Result: res}
+}
+
+// dcrChain records a chain of headers up to height tip, plus a side chain
+// block at height fork.
+func dcrChain(tip, fork uint32) (calls []*Call, main []chainhash.Hash, side chainhash.Hash) {
+	calls = append(calls, call("version", map[string]interface{}{
+		"dcrdjsonrpcapi": map[string]interface{}{"versionstring": "8.0.0", "major": 8},
+	}))
+	header := func(height uint32, prev chainhash.Hash, nonce uint32) chainhash.Hash {
+		hdr := &wire.BlockHeader{Version: 10, PrevBlock: prev, Height: height, Nonce: nonce}
+		b, _ := hdr.Bytes()
+		hash := hdr.BlockHash()
+		calls = append(calls, call("getblockheader", hex.EncodeToString(b), hash.String(), false))
+		return hash
+	}
+	var prev chainhash.Hash
+	for height := uint32(0); height <= tip; height++ {
+		prev = header(height, prev, 0)
+		main = append(main, prev)
+		calls = append(calls, call("getblockhash", prev.String(), height))
+	}
+	side = header(fork, main[fork-1], 1)
+	return
+}
+
+type event struct {
+	kind   string
+	height int32
+	hash   chainhash.Hash
+}
+
+func TestDcrdNotifications(t *testing.T) {
+	calls, main, side := dcrChain(5, 3)
+	node, err := New(&Manifest{
+		Chain:    "dcr",
+		Start:    int64p(2),
+		Interval: "0s",
+		Forks:    []Fork{{Height: 3, Hashes: []string{side.String()}}},
+	}, calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(node.Handler("user", "pass"))
+	defer srv.Close()
+
+	events := make(chan event, 16)
+	onHeader := func(kind string) func([]byte) {
+		return func(b []byte) {
+			var hdr wire.BlockHeader
+			if err := hdr.FromBytes(b); err != nil {
+				t.Error(err)
+			}
+			events <- event{kind, int32(hdr.Height), hdr.BlockHash()}
+		}
+	}
+	client, err := rpcclient.New(&rpcclient.ConnConfig{
+		Host:                 strings.TrimPrefix(srv.URL, "http://"),
+		Endpoint:             "ws",
+		User:                 "user",
+		Pass:                 "pass",
+		DisableTLS:           true,
+		DisableAutoReconnect: true,
+	}, &rpcclient.NotificationHandlers{
+		OnBlockConnected:    func(b []byte, _ [][]byte) { onHeader("connected")(b) },
+		OnBlockDisconnected: onHeader("disconnected"),
+		OnReorganization: func(_ *chainhash.Hash, _ int32, newHash *chainhash.Hash, newHeight int32) {
+			events <- event{"reorganization", newHeight, *newHash}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Shutdown()
+
+	ctx := context.Background()
+	if err = client.NotifyBlocks(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.Version(ctx); err != nil {
+		t.Fatalf("recorded call failed: %v", err)
+	}
+	checkTip := func(height int64, hash chainhash.Hash) {
+		t.Helper()
+		count, err := client.GetBlockCount(ctx)
+		if err != nil || count != height {
+			t.Fatalf("block count %d (%v), expected %d", count, err, height)
+		}
+		tipHash, err := client.GetBlockHash(ctx, height)
+		if err != nil || *tipHash != hash {
+			t.Fatalf("tip hash %v (%v), expected %v", tipHash, err, hash)
+		}
+		if _, err = client.GetBlockHash(ctx, height+1); err == nil {
+			t.Fatalf("block %d is past the tip", height+1)
+		}
+	}
+	expect := func(want ...event) {
+		t.Helper()
+		for _, w := range want {
+			select {
+			case got := <-events:
+				if got != w {
+					t.Fatalf("got %s %d %v, expected %s %d %v", got.kind, got.height, got.hash, w.kind, w.height, w.hash)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("no %s notification", w.kind)
+			}
+		}
+	}
+
+	checkTip(2, main[2])
+
+	// The side chain block.
+	node.Advance()
+	expect(event{"connected", 3, side})
+	checkTip(3, side)
+
+	// The reorganization back to the main chain.
+	node.Advance()
+	expect(event{"disconnected", 3, side},
+		event{"connected", 3, main[3]},
+		event{"connected", 4, main[4]},
+		event{"reorganization", 4, main[4]})
+	checkTip(4, main[4])
+
+	node.Advance()
+	expect(event{"connected", 5, main[5]})
+	checkTip(5, main[5])
+
+	if node.Advance() {
+		t.Error("advanced past the stop height")
+	}
+}
+
+func TestBitcoindPolling(t *testing.T) {
+	var calls []*Call
+	for height := 0; height <= 3; height++ {
+		calls = append(calls, call("getblockhash", fmt.Sprintf("%064x", height), height))
+	}
+	calls = append(calls, call("getblockchaininfo", map[string]interface{}{
+		"chain": "regtest", "blocks": 3, "headers": 3, "bestblockhash": fmt.Sprintf("%064x", 3),
+	}), call("getnetworkinfo", map[string]interface{}{"version": 250000, "subversion": "/Satoshi:25.0.0/"}))
+	mempool := func(height int64, txids ...string) *Call {
+		c := call("getrawmempool", txids, false)
+		c.Height = height
+		return c
+	}
+	calls = append(calls, mempool(1, "aa"), mempool(3, "bb", "cc"))
+
+	node, err := New(&Manifest{Chain: "btc", Start: int64p(1), Interval: "0s"}, calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(node.Handler("user", "pass"))
+	defer srv.Close()
+
+	client, err := btcrpcclient.New(&btcrpcclient.ConnConfig{
+		Host:         strings.TrimPrefix(srv.URL, "http://"),
+		User:         "user",
+		Pass:         "pass",
+		HTTPPostMode: true,
+		DisableTLS:   true,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Shutdown()
+
+	for height := int64(1); height <= 3; height++ {
+		count, err := client.GetBlockCount()
+		if err != nil || count != height {
+			t.Fatalf("block count %d (%v), expected %d", count, err, height)
+		}
+		info, err := client.GetBlockChainInfo()
+		if err != nil || int64(info.Blocks) != height || info.BestBlockHash != fmt.Sprintf("%064x", height) {
+			t.Fatalf("chain info %+v (%v) not at the tip %d", info, err, height)
+		}
+		txids, err := client.GetRawMempool()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wantTxs := map[int64]int{1: 1, 2: 1, 3: 2}[height]; len(txids) != wantTxs {
+			t.Errorf("%d mempool txs at %d, expected %d", len(txids), height, wantTxs)
+		}
+		node.Advance()
+	}
+}
+
+func TestMonerod(t *testing.T) {
+	header := func(height int64) map[string]interface{} {
+		return map[string]interface{}{
+			"block_header": map[string]interface{}{"height": height, "hash": fmt.Sprintf("%064x", height)},
+			"status":       "OK",
+		}
+	}
+	var calls []*Call
+	for height := int64(0); height <= 2; height++ {
+		c := call("get_block_header_by_height", header(height))
+		c.Params = json.RawMessage(fmt.Sprintf(`{"height": %d}`, height))
+		calls = append(calls, c)
+	}
+	pool := call("/get_transaction_pool_hashes", map[string]interface{}{"tx_hashes": []string{"aa"}, "status": "OK"})
+	pool.Params = nil
+	calls = append(calls, pool)
+
+	node, err := New(&Manifest{Chain: "xmr", Start: int64p(1), Interval: "0s"}, calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(node.Handler("", ""))
+	defer srv.Close()
+
+	post := func(path string, body, out interface{}) error {
+		b, _ := json.Marshal(body)
+		resp, err := http.Post(srv.URL+path, "application/json", bytes.NewReader(b))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	type blockHeader struct {
+		Height int64  `json:"height"`
+		Hash   string `json:"hash"`
+	}
+	var res struct {
+		Result struct {
+			BlockHeader blockHeader `json:"block_header"`
+			Count       int64       `json:"count"`
+		} `json:"result"`
+		Error *RPCError `json:"error"`
+	}
+	rpc := func(method string, params interface{}) error {
+		res.Result.BlockHeader, res.Error = blockHeader{}, nil
+		err := post("/json_rpc", map[string]interface{}{"jsonrpc": "2.0", "id": "0", "method": method, "params": params}, &res)
+		if err == nil && res.Error != nil {
+			return res.Error
+		}
+		return err
+	}
+
+	if err = rpc("get_last_block_header", nil); err != nil || res.Result.BlockHeader.Height != 1 {
+		t.Fatalf("last block header %+v (%v), expected height 1", res.Result.BlockHeader, err)
+	}
+	if err = rpc("get_block_header_by_height", map[string]int64{"height": 2}); err == nil {
+		t.Error("block 2 is past the tip")
+	}
+	node.Advance()
+	if err = rpc("get_block_count", nil); err != nil || res.Result.Count != 3 {
+		t.Errorf("block count %d (%v), expected 3", res.Result.Count, err)
+	}
+	if err = rpc("get_block_header_by_height", map[string]int64{"height": 2}); err != nil ||
+		res.Result.BlockHeader.Hash != fmt.Sprintf("%064x", 2) {
+		t.Errorf("block header %+v (%v)", res.Result.BlockHeader, err)
+	}
+	var txs struct {
+		TxHashes []string `json:"tx_hashes"`
+	}
+	if err = post("/get_transaction_pool_hashes", nil, &txs); err != nil || len(txs.TxHashes) != 1 {
+		t.Errorf("pool hashes %v (%v)", txs.TxHashes, err)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	calls, main, _ := dcrChain(3, 1)
+	live, err := New(&Manifest{Chain: "dcr", Start: int64p(3)}, calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	liveSrv := httptest.NewServer(live.Handler("", ""))
+	defer liveSrv.Close()
+
+	dir := t.TempDir()
+	rec, err := NewRecorder(dir, "dcr", liveSrv.URL, "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	recSrv := httptest.NewServer(rec.Handler("", ""))
+	defer recSrv.Close()
+
+	client, err := rpcclient.New(&rpcclient.ConnConfig{
+		Host:         strings.TrimPrefix(recSrv.URL, "http://"),
+		HTTPPostMode: true,
+		DisableTLS:   true,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for height := int64(0); height <= 3; height++ {
+		if _, err = client.GetBlockHash(ctx, height); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client.Shutdown()
+	if err = rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The recorded getblockheader calls make the blocks replayable.
+	replay, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tip, hash := replay.Tip(); tip != 0 || hash != main[0].String() {
+		t.Errorf("replay starts at %d %s, expected the first recorded block", tip, hash)
+	}
+	for replay.Advance() {
+	}
+	if tip, hash := replay.Tip(); tip != 3 || hash != main[3].String() {
+		t.Errorf("replay stops at %d %s, expected %d %s", tip, hash, 3, main[3])
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package replaynode
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/decred/dcrdata/v8/mutilchain"
+)
+
+// maxRequestSize limits the size of a request body.
+const maxRequestSize = 1 << 24
+
+// caller answers an RPC, from a replay or from a live node.
+type caller func(method string, params json.RawMessage) (json.RawMessage, *RPCError)
+
+// handler speaks the RPC protocols of the nodes:
+//
+//	dcrd      JSON-RPC 1.0 over HTTP POST and over the /ws websocket
+//	bitcoind  JSON-RPC 1.0 over HTTP POST, also used for litecoind
+//	monerod   JSON-RPC 2.0 on /json_rpc and JSON bodies on the other paths
+//
+// A Node additionally serves /replay/status and /replay/advance.
+type handler struct {
+	chain      string
+	user, pass string
+	call       caller
+	node       *Node
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type response struct {
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+	ID     json.RawMessage `json:"id"`
+}
+
+type responseV2 struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+var upgrader = websocket.Upgrader{
+	// The node clients do not send an Origin header.
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// Handler returns the http.Handler of the replay. Requests must carry the
+// given basic auth credentials unless both are empty.
+func (n *Node) Handler(user, pass string) http.Handler {
+	return &handler{
+		chain: n.chain,
+		user:  user,
+		pass:  pass,
+		call:  n.Call,
+		node:  n,
+	}
+}
+
+// ServeHTTP satisfies http.Handler.
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.user != "" || h.pass != "" {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != h.user || pass != h.pass {
+			w.Header().Set("WWW-Authenticate", `Basic realm="replaynode"`)
+			http.Error(w, "401 Unauthorized.", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	switch path := strings.TrimRight(r.URL.Path, "/"); {
+	case h.node != nil && path == "/replay/status":
+		h.status(w)
+	case h.node != nil && path == "/replay/advance":
+		if r.Method != http.MethodPost {
+			http.Error(w, "POST to advance the replay", http.StatusMethodNotAllowed)
+			return
+		}
+		h.node.Advance()
+		h.status(w)
+	case h.chain == mutilchain.TYPEXMR && path == "/json_rpc":
+		h.serveJSONRPC2(w, r)
+	case h.chain == mutilchain.TYPEXMR:
+		h.serveDirect(w, r, path)
+	case path == "/ws":
+		h.serveWebsocket(w, r)
+	default:
+		h.serveJSONRPC(w, r)
+	}
+}
+
+func (h *handler) status(w http.ResponseWriter) {
+	tip, hash := h.node.Tip()
+	writeJSON(w, map[string]interface{}{
+		"chain":  h.chain,
+		"height": tip,
+		"hash":   hash,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Warnf("Failed to write response: %v", err)
+	}
+}
+
+func (h *handler) respond(req *request) *response {
+	log.Tracef("%s %s", req.Method, req.Params)
+	res, rpcErr := h.call(req.Method, req.Params)
+	if rpcErr == nil && res == nil {
+		res = json.RawMessage("null")
+	}
+	return &response{Result: res, Error: rpcErr, ID: req.ID}
+}
+
+// serveJSONRPC answers single and batched JSON-RPC 1.0 requests.
+func (h *handler) serveJSONRPC(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body = bytes.TrimSpace(body); len(body) > 0 && body[0] == '[' {
+		var reqs []*request
+		if err = json.Unmarshal(body, &reqs); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resps := make([]*response, 0, len(reqs))
+		for _, req := range reqs {
+			resps = append(resps, h.respond(req))
+		}
+		writeJSON(w, resps)
+		return
+	}
+	req := new(request)
+	if err = json.Unmarshal(body, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, h.respond(req))
+}
+
+// serveJSONRPC2 answers monerod's /json_rpc.
+func (h *handler) serveJSONRPC2(w http.ResponseWriter, r *http.Request) {
+	req := new(request)
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxRequestSize)).Decode(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp := h.respond(req)
+	writeJSON(w, &responseV2{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result:  resp.Result,
+		Error:   resp.Error,
+	})
+}
+
+// serveDirect answers monerod's other endpoints, whose response is the bare
+// result. They are recorded with their path as the method.
+func (h *handler) serveDirect(w http.ResponseWriter, r *http.Request, path string) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	res, rpcErr := h.call(path, body)
+	if rpcErr != nil {
+		http.Error(w, rpcErr.Message, http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(res)
+}
+
+// serveWebsocket answers dcrd websocket clients. A notifyblocks registration
+// subscribes the client to the replay's block notifications, the other
+// notification registrations are accepted but never fire.
+func (h *handler) serveWebsocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Warnf("Websocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	out := make(chan []byte, 16)
+	quit := make(chan struct{})
+	defer close(quit)
+	go func() {
+		for {
+			select {
+			case msg := <-out:
+				if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+					log.Debugf("Websocket write failed: %v", err)
+					conn.Close()
+					return
+				}
+			case <-quit:
+				return
+			}
+		}
+	}()
+	send := func(msg []byte) {
+		select {
+		case out <- msg:
+		case <-quit:
+		}
+	}
+
+	var sub *subscription
+	defer func() {
+		if sub != nil {
+			h.node.unsubscribe(sub)
+		}
+	}()
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		req := new(request)
+		if err = json.Unmarshal(msg, req); err != nil {
+			log.Debugf("Invalid websocket request: %v", err)
+			continue
+		}
+
+		var resp *response
+		switch {
+		case req.Method == "notifyblocks" && h.node != nil && sub == nil:
+			sub = h.node.subscribe()
+			go func(sub *subscription) {
+				for {
+					select {
+					case ntfn := <-sub.ntfns:
+						send(ntfn)
+					case <-sub.done:
+						return
+					}
+				}
+			}(sub)
+			fallthrough
+		case strings.HasPrefix(req.Method, "notify") || strings.HasPrefix(req.Method, "stopnotify"):
+			resp = &response{Result: json.RawMessage("null"), ID: req.ID}
+		default:
+			resp = h.respond(req)
+		}
+		b, err := json.Marshal(resp)
+		if err != nil {
+			log.Errorf("Failed to encode %s response: %v", req.Method, err)
+			continue
+		}
+		send(b)
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package btctxhelper
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/decred/dcrdata/v8/mutilchain"
+	"github.com/decred/dcrdata/v8/txhelpers"
+	"github.com/decred/dcrdata/v8/utils"
+)
+
+// DecodeRawTxOrPSBT decodes a hex encoded transaction, or a hex or base64
+// encoded BIP-174 PSBT. Previous outputs are only resolved when the PSBT
+// carries them. The caller is expected to look up the remaining inputs and
+// then call ComputeFee on the result.
+func DecodeRawTxOrPSBT(input string, params *chaincfg.Params) (*txhelpers.DecodedMultichainTx, error) {
+	b, isPSBT, err := txhelpers.DecodeRawTxOrPSBTString(input)
+	if err != nil {
+		return nil, err
+	}
+	if isPSBT {
+		return decodePSBT(b, params)
+	}
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	if err = msgTx.Deserialize(bytes.NewReader(b)); err != nil {
+		return nil, fmt.Errorf("failed to deserialize transaction: %w", err)
+	}
+	dtx := DecodeMsgTx(msgTx, params)
+	dtx.Complete = true
+	dtx.SerializedTx = b
+	return dtx, nil
+}
+
+func decodePSBT(b []byte, params *chaincfg.Params) (*txhelpers.DecodedMultichainTx, error) {
+	var msgTx *wire.MsgTx
+	packet, err := txhelpers.ParsePSBT(b, func(unsignedTx []byte) (int, int, error) {
+		msgTx = wire.NewMsgTx(wire.TxVersion)
+		if err := msgTx.DeserializeNoWitness(bytes.NewReader(unsignedTx)); err != nil {
+			return 0, 0, err
+		}
+		for _, txIn := range msgTx.TxIn {
+			if len(txIn.SignatureScript) > 0 || len(txIn.Witness) > 0 {
+				return 0, 0, fmt.Errorf("unsigned transaction has signature data")
+			}
+		}
+		return len(msgTx.TxIn), len(msgTx.TxOut), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	complete := packet.IsFinalized()
+	if complete {
+		for i := range packet.Inputs {
+			msgTx.TxIn[i].SignatureScript = packet.Inputs[i].FinalScriptSig
+			msgTx.TxIn[i].Witness = packet.Inputs[i].FinalScriptWitness
+		}
+	}
+
+	dtx := DecodeMsgTx(msgTx, params)
+	dtx.IsPSBT = true
+	dtx.Complete = complete
+	if complete {
+		var buf bytes.Buffer
+		if err = msgTx.Serialize(&buf); err != nil {
+			return nil, err
+		}
+		dtx.SerializedTx = buf.Bytes()
+	} else {
+		dtx.AddWarning("PSBT is not finalized; size and fee rate are estimated from the unsigned transaction")
+	}
+
+	for i := range packet.Inputs {
+		pin := &packet.Inputs[i]
+		vin := dtx.Vin[i]
+		vin.Finalized = pin.IsFinalized()
+		vin.PartialSigs = pin.PartialSigs
+
+		prevOut := msgTx.TxIn[i].PreviousOutPoint
+		switch {
+		case pin.WitnessUtxo != nil:
+			SetDecodedPrevOut(vin, pin.WitnessUtxo.Value, pin.WitnessUtxo.PkScript, params)
+		case pin.NonWitnessUtxo != nil:
+			prevTx := wire.NewMsgTx(wire.TxVersion)
+			if err = prevTx.Deserialize(bytes.NewReader(pin.NonWitnessUtxo)); err != nil {
+				return nil, fmt.Errorf("input %d: invalid non-witness utxo: %w", i, err)
+			}
+			if prevTx.TxHash() != prevOut.Hash {
+				return nil, fmt.Errorf("input %d: non-witness utxo does not match outpoint", i)
+			}
+			if int(prevOut.Index) >= len(prevTx.TxOut) {
+				return nil, fmt.Errorf("input %d: outpoint index out of range", i)
+			}
+			txOut := prevTx.TxOut[prevOut.Index]
+			SetDecodedPrevOut(vin, txOut.Value, txOut.PkScript, params)
+		}
+
+		// An unfinalized input spending a swap contract reveals the contract
+		// in its witness or redeem script.
+		if vin.SwapRole == "" {
+			script := pin.WitnessScript
+			if len(script) == 0 {
+				script = pin.RedeemScript
+			}
+			if contract, _ := ParseAtomicSwapContract(script, params); contract != nil {
+				vin.SwapRole = "contract spend"
+				vin.SwapContract = contract.ContractAddress.String()
+				dtx.Swaps = append(dtx.Swaps, decodedSwap(contract, "contract spend", "vin", uint32(i), nil))
+			}
+		}
+	}
+
+	for i := range packet.Outputs {
+		pout := &packet.Outputs[i]
+		script := pout.WitnessScript
+		if len(script) == 0 {
+			script = pout.RedeemScript
+		}
+		contract, _ := ParseAtomicSwapContract(script, params)
+		if contract == nil {
+			continue
+		}
+		dtx.Vout[i].SwapContract = contract.ContractAddress.String()
+		dtx.Swaps = append(dtx.Swaps, decodedSwap(contract, utils.CONTRACT_TYPE, "vout", uint32(i), nil))
+	}
+
+	return dtx, nil
+}
+
+// DecodeMsgTx describes the inputs and outputs of the transaction, including
+// the RBF signal and any atomic swap redemptions or refunds visible in the
+// input witnesses.
+func DecodeMsgTx(msgTx *wire.MsgTx, params *chaincfg.Params) *txhelpers.DecodedMultichainTx {
+	tx := btcutil.NewTx(msgTx)
+	weight := blockchain.GetTransactionWeight(tx)
+	dtx := &txhelpers.DecodedMultichainTx{
+		ChainType: mutilchain.TYPEBTC,
+		TxID:      msgTx.TxHash().String(),
+		Version:   msgTx.Version,
+		LockTime:  msgTx.LockTime,
+		Size:      msgTx.SerializeSize(),
+		Weight:    int(weight),
+		VSize:     int((weight + blockchain.WitnessScaleFactor - 1) / blockchain.WitnessScaleFactor),
+		SegWit:    msgTx.HasWitness(),
+	}
+	if dtx.SegWit {
+		dtx.WTxID = msgTx.WitnessHash().String()
+	}
+
+	isCoinbase := blockchain.IsCoinBaseTx(msgTx)
+	for i, txIn := range msgTx.TxIn {
+		vin := &txhelpers.DecodedMultichainVin{
+			Index:      uint32(i),
+			Sequence:   txIn.Sequence,
+			SignalsRBF: txIn.Sequence <= txhelpers.MaxBIP125Sequence,
+			Coinbase:   isCoinbase,
+			Finalized:  len(txIn.SignatureScript) > 0 || len(txIn.Witness) > 0,
+		}
+		if !isCoinbase {
+			vin.PrevTxID = txIn.PreviousOutPoint.Hash.String()
+			vin.PrevVout = txIn.PreviousOutPoint.Index
+		}
+		if len(txIn.SignatureScript) > 0 {
+			vin.ScriptSig = hex.EncodeToString(txIn.SignatureScript)
+		}
+		for _, item := range txIn.Witness {
+			vin.Witness = append(vin.Witness, hex.EncodeToString(item))
+		}
+		dtx.SignalsRBF = dtx.SignalsRBF || vin.SignalsRBF
+
+		if !isCoinbase {
+			contract, _, secret, isRefund, err := ExtractSwapDataFromWitness(txIn.Witness, params)
+			if err == nil && contract != nil {
+				role := utils.REDEMPTION_TYPE
+				if isRefund {
+					role = utils.REFUND_TYPE
+				}
+				vin.SwapRole = role
+				vin.SwapContract = contract.ContractAddress.String()
+				dtx.Swaps = append(dtx.Swaps, decodedSwap(contract, role, "vin", uint32(i), secret))
+			}
+		}
+		dtx.Vin = append(dtx.Vin, vin)
+	}
+
+	for i, txOut := range msgTx.TxOut {
+		class, addrs, _, _ := txscript.ExtractPkScriptAddrs(txOut.PkScript, params)
+		vout := &txhelpers.DecodedMultichainVout{
+			Index:      uint32(i),
+			Value:      txOut.Value,
+			Amount:     btcutil.Amount(txOut.Value).ToBTC(),
+			ScriptType: class.String(),
+			PkScript:   hex.EncodeToString(txOut.PkScript),
+		}
+		for _, addr := range addrs {
+			vout.Addresses = append(vout.Addresses, addr.String())
+		}
+		if class == txscript.NullDataTy {
+			if pushes, err := txscript.PushedData(txOut.PkScript); err == nil {
+				for _, push := range pushes {
+					vout.NullData += hex.EncodeToString(push)
+				}
+			}
+		}
+		dtx.Vout = append(dtx.Vout, vout)
+	}
+
+	return dtx
+}
+
+// SetDecodedPrevOut fills in the previous output details of a decoded input.
+func SetDecodedPrevOut(vin *txhelpers.DecodedMultichainVin, value int64, pkScript []byte, params *chaincfg.Params) {
+	class, addrs, _, _ := txscript.ExtractPkScriptAddrs(pkScript, params)
+	vin.PrevFound = true
+	vin.Value = value
+	vin.Amount = btcutil.Amount(value).ToBTC()
+	vin.ScriptType = class.String()
+	vin.PrevPkScript = pkScript
+	vin.Addresses = vin.Addresses[:0]
+	for _, addr := range addrs {
+		vin.Addresses = append(vin.Addresses, addr.String())
+	}
+}
+
+func decodedSwap(contract *AtomicSwapContractPushes, role, io string, index uint32, secret []byte) *txhelpers.DecodedAtomicSwap {
+	return &txhelpers.DecodedAtomicSwap{
+		Role:              role,
+		IO:                io,
+		Index:             index,
+		ContractAddress:   contract.ContractAddress.String(),
+		RecipientAddress:  contract.RecipientAddress.String(),
+		RefundAddress:     contract.RefundAddress.String(),
+		Locktime:          contract.Locktime,
+		FormattedLocktime: contract.FormattedLocktime,
+		SecretHash:        hex.EncodeToString(contract.SecretHash[:]),
+		Secret:            hex.EncodeToString(secret),
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package txhelpers
+
+// CoinJoin types detected by DetectCoinJoin.
+const (
+	CoinJoinWhirlpool  = "whirlpool"
+	CoinJoinWabiSabi   = "wabisabi"
+	CoinJoinWasabi     = "wasabi"
+	CoinJoinJoinMarket = "joinmarket"
+	CoinJoinGeneric    = "generic"
+)
+
+// whirlpoolPools are the denominations of the Whirlpool pools, in satoshis.
+var whirlpoolPools = map[int64]struct{}{
+	100000:   {}, // 0.001 BTC
+	1000000:  {}, // 0.01 BTC
+	5000000:  {}, // 0.05 BTC
+	50000000: {}, // 0.5 BTC
+}
+
+// wabiSabiDenoms are the standard output denominations of the WabiSabi
+// coordinator: the powers of 2 and 3, twice the powers of 3, and 1, 2 and 5
+// times the powers of 10, from 5000 satoshis to 1374.38953472 BTC.
+var wabiSabiDenoms = func() map[int64]struct{} {
+	const minDenom, maxDenom = 5000, 137438953472
+	denoms := make(map[int64]struct{})
+	add := func(v int64) {
+		if v >= minDenom && v <= maxDenom {
+			denoms[v] = struct{}{}
+		}
+	}
+	for v := int64(1); v <= maxDenom; v *= 2 {
+		add(v)
+	}
+	for v := int64(1); v <= maxDenom; v *= 3 {
+		add(v)
+		add(2 * v)
+	}
+	for v := int64(1); v <= maxDenom; v *= 10 {
+		add(v)
+		add(2 * v)
+		add(5 * v)
+	}
+	return denoms
+}()
+
+const (
+	// minCoinJoinDenom excludes dust and zero value outputs from the equal
+	// outputs of a CoinJoin.
+	minCoinJoinDenom = 10000
+	// wabiSabiMinInputs is the fewest inputs of a WabiSabi round.
+	wabiSabiMinInputs = 50
+	// wasabiMinDenom and wasabiMaxDenom bound the base denomination of the
+	// Wasabi 1 (ZeroLink) rounds, about 0.1 BTC.
+	wasabiMinDenom, wasabiMaxDenom = 8000000, 12000000
+	wasabiMinOutputs               = 10
+)
+
+// CoinJoin describes a CoinJoin transaction. Participants is the number of
+// outputs of the denomination, the anonymity set of the mix, and Mixed is the
+// total value of the equal outputs.
+type CoinJoin struct {
+	Type         string
+	Denom        int64
+	Participants uint32
+	Mixed        int64
+}
+
+// equalOutputs returns the value shared by the most outputs of at least
+// minCoinJoinDenom, the larger one of a tie, and the number of such outputs.
+func equalOutputs(outs []int64) (denom int64, count uint32) {
+	counts := make(map[int64]uint32, len(outs))
+	for _, v := range outs {
+		if v < minCoinJoinDenom {
+			continue
+		}
+		counts[v]++
+		if c := counts[v]; c > count || (c == count && v > denom) {
+			denom, count = v, c
+		}
+	}
+	return
+}
+
+// DetectGenericCoinJoin tests if a transaction with numIns inputs and the
+// output values outs is a CoinJoin: 3 or more outputs of the same value, and
+// at least as many inputs.
+func DetectGenericCoinJoin(numIns int, outs []int64) *CoinJoin {
+	denom, count := equalOutputs(outs)
+	if count < 3 || numIns < int(count) {
+		return nil
+	}
+	return &CoinJoin{
+		Type:         CoinJoinGeneric,
+		Denom:        denom,
+		Participants: count,
+		Mixed:        denom * int64(count),
+	}
+}
+
+// DetectCoinJoin tests if a Bitcoin transaction with numIns inputs and the
+// output values outs is a CoinJoin, identifying the Whirlpool, WabiSabi
+// (Wasabi 2), Wasabi 1 and JoinMarket transactions by their structure, and
+// others as generic equal-output CoinJoins. It returns nil for a transaction
+// that is not a CoinJoin.
+func DetectCoinJoin(numIns int, outs []int64) *CoinJoin {
+	numOuts := len(outs)
+	if numIns < 2 || numOuts < 2 {
+		return nil
+	}
+
+	// Whirlpool: 5 to 8 inputs and as many outputs of a pool denomination.
+	if numOuts == numIns && numOuts >= 5 && numOuts <= 8 {
+		if _, ok := whirlpoolPools[outs[0]]; ok {
+			whirlpool := true
+			for _, v := range outs {
+				if v != outs[0] {
+					whirlpool = false
+					break
+				}
+			}
+			if whirlpool {
+				return &CoinJoin{
+					Type:         CoinJoinWhirlpool,
+					Denom:        outs[0],
+					Participants: uint32(numOuts),
+					Mixed:        outs[0] * int64(numOuts),
+				}
+			}
+		}
+	}
+
+	// WabiSabi: many inputs, and most outputs of a standard denomination.
+	if numIns >= wabiSabiMinInputs {
+		counts := make(map[int64]uint32)
+		var standard int
+		for _, v := range outs {
+			if _, ok := wabiSabiDenoms[v]; ok {
+				counts[v]++
+				standard++
+			}
+		}
+		if 2*standard >= numOuts {
+			cj := &CoinJoin{Type: CoinJoinWabiSabi}
+			for v, c := range counts {
+				if c > cj.Participants || (c == cj.Participants && v > cj.Denom) {
+					cj.Denom, cj.Participants = v, c
+				}
+				// Only the denominations of several outputs are mixed.
+				if c > 1 {
+					cj.Mixed += v * int64(c)
+				}
+			}
+			if cj.Participants > 1 {
+				return cj
+			}
+		}
+	}
+
+	cj := DetectGenericCoinJoin(numIns, outs)
+	if cj == nil {
+		return nil
+	}
+	switch {
+	case cj.Participants >= wasabiMinOutputs && cj.Denom >= wasabiMinDenom && cj.Denom <= wasabiMaxDenom:
+		// Wasabi 1: many outputs of about 0.1 BTC.
+		cj.Type = CoinJoinWasabi
+	case numOuts == 2*int(cj.Participants) || numOuts == 2*int(cj.Participants)-1:
+		// JoinMarket: a change output for each participant, except a taker
+		// sweeping its wallet.
+		cj.Type = CoinJoinJoinMarket
+	}
+	return cj
+}
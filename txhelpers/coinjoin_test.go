@@ -0,0 +1,79 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package txhelpers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func repeat(v int64, n int) []int64 {
+	outs := make([]int64, n)
+	for i := range outs {
+		outs[i] = v
+	}
+	return outs
+}
+
+func TestDetectCoinJoin(t *testing.T) {
+	wabiSabiOuts := append(repeat(1048576, 20), repeat(5000000, 30)...)
+	wabiSabiOuts = append(wabiSabiOuts, 123456789, 2000000)
+	tests := []struct {
+		name   string
+		numIns int
+		outs   []int64
+		want   *CoinJoin
+	}{{
+		name:   "whirlpool",
+		numIns: 5,
+		outs:   repeat(5000000, 5),
+		want:   &CoinJoin{CoinJoinWhirlpool, 5000000, 5, 25000000},
+	}, {
+		name:   "wabisabi",
+		numIns: 60,
+		outs:   wabiSabiOuts,
+		want:   &CoinJoin{CoinJoinWabiSabi, 5000000, 30, 30*5000000 + 20*1048576},
+	}, {
+		name:   "wasabi",
+		numIns: 40,
+		outs:   append(repeat(9876543, 30), 123456, 5432100),
+		want:   &CoinJoin{CoinJoinWasabi, 9876543, 30, 30 * 9876543},
+	}, {
+		name:   "joinmarket",
+		numIns: 6,
+		outs:   []int64{31234567, 31234567, 31234567, 31234567, 1111111, 2222222, 3333333},
+		want:   &CoinJoin{CoinJoinJoinMarket, 31234567, 4, 4 * 31234567},
+	}, {
+		name:   "generic",
+		numIns: 3,
+		outs:   []int64{7000000, 7000000, 7000000, 100000},
+		want:   &CoinJoin{CoinJoinGeneric, 7000000, 3, 21000000},
+	}, {
+		name:   "batch payout of one input",
+		numIns: 1,
+		outs:   repeat(7000000, 4),
+	}, {
+		name:   "payment with change",
+		numIns: 2,
+		outs:   []int64{50000000, 1234567},
+	}, {
+		name:   "dust outputs",
+		numIns: 5,
+		outs:   repeat(546, 5),
+	}}
+	for _, test := range tests {
+		if got := DetectCoinJoin(test.numIns, test.outs); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDetectGenericCoinJoin(t *testing.T) {
+	// Whirlpool pools are not detected on other chains.
+	got := DetectGenericCoinJoin(5, repeat(5000000, 5))
+	want := &CoinJoin{CoinJoinGeneric, 5000000, 5, 25000000}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
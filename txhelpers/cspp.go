@@ -27,6 +27,15 @@ func init() {
 	}
 }
 
+// MixDenoms returns the CSPP mix denominations in atoms, sorted small to large.
+func MixDenoms() []int64 {
+	denoms := make([]int64, 0, len(splitPoints))
+	for i := len(splitPoints) - 1; i >= 0; i-- {
+		denoms = append(denoms, int64(splitPoints[i]))
+	}
+	return denoms
+}
+
 // IsMixTx tests if a transaction is a CSPP-mixed transaction, which must have 3
 // or more outputs of the same amount, which is one of the pre-defined mix
 // denominations. mixDenom is the largest of such denominations. mixCount is the
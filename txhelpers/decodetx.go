@@ -0,0 +1,129 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package txhelpers
+
+// MaxBIP125Sequence is the highest input sequence number that signals opt-in
+// replace-by-fee according to BIP-125.
+const MaxBIP125Sequence = 0xfffffffd
+
+// DecodedMultichainVin describes an input of a decoded BTC or LTC transaction.
+// The previous output fields are filled in by the caller from the database or
+// node when the funding transaction is known.
+type DecodedMultichainVin struct {
+	Index        uint32   `json:"n"`
+	PrevTxID     string   `json:"prev_txid,omitempty"`
+	PrevVout     uint32   `json:"prev_vout"`
+	Coinbase     bool     `json:"coinbase,omitempty"`
+	Sequence     uint32   `json:"sequence"`
+	SignalsRBF   bool     `json:"signals_rbf"`
+	ScriptSig    string   `json:"script_sig,omitempty"`
+	Witness      []string `json:"witness,omitempty"`
+	PrevFound    bool     `json:"prev_found"`
+	Value        int64    `json:"value,omitempty"`
+	Amount       float64  `json:"amount,omitempty"`
+	Addresses    []string `json:"addresses,omitempty"`
+	ScriptType   string   `json:"script_type,omitempty"`
+	Spent        bool     `json:"spent,omitempty"`
+	SpendingTxID string   `json:"spending_txid,omitempty"`
+	Unconfirmed  bool     `json:"unconfirmed,omitempty"`
+	Finalized    bool     `json:"finalized"`
+	PartialSigs  int      `json:"partial_sigs,omitempty"`
+	SwapContract string   `json:"swap_contract,omitempty"`
+	SwapRole     string   `json:"swap_role,omitempty"`
+	PrevPkScript []byte   `json:"-"`
+}
+
+// DecodedMultichainVout describes an output of a decoded BTC or LTC
+// transaction.
+type DecodedMultichainVout struct {
+	Index        uint32   `json:"n"`
+	Value        int64    `json:"value"`
+	Amount       float64  `json:"amount"`
+	ScriptType   string   `json:"script_type"`
+	Addresses    []string `json:"addresses,omitempty"`
+	PkScript     string   `json:"script_pubkey"`
+	NullData     string   `json:"null_data,omitempty"`
+	SwapContract string   `json:"swap_contract,omitempty"`
+}
+
+// DecodedAtomicSwap summarizes an atomic swap contract detected in a decoded
+// transaction, either in a spending input or in a PSBT output script.
+type DecodedAtomicSwap struct {
+	Role              string `json:"role"` // contract, redemption or refund
+	IO                string `json:"io"`   // vin or vout
+	Index             uint32 `json:"index"`
+	ContractAddress   string `json:"contract_address"`
+	RecipientAddress  string `json:"recipient_address"`
+	RefundAddress     string `json:"refund_address"`
+	Locktime          int64  `json:"locktime"`
+	FormattedLocktime string `json:"formatted_locktime"`
+	SecretHash        string `json:"secret_hash"`
+	Secret            string `json:"secret,omitempty"`
+}
+
+// DecodedMultichainTx is the result of decoding a raw BTC or LTC transaction
+// or a BIP-174 PSBT.
+type DecodedMultichainTx struct {
+	ChainType   string                   `json:"chain"`
+	IsPSBT      bool                     `json:"is_psbt"`
+	Complete    bool                     `json:"complete"`
+	TxID        string                   `json:"txid"`
+	WTxID       string                   `json:"wtxid,omitempty"`
+	Version     int32                    `json:"version"`
+	LockTime    uint32                   `json:"locktime"`
+	Size        int                      `json:"size"`
+	VSize       int                      `json:"vsize"`
+	Weight      int                      `json:"weight"`
+	SegWit      bool                     `json:"segwit"`
+	SignalsRBF  bool                     `json:"signals_rbf"`
+	Vin         []*DecodedMultichainVin  `json:"vin"`
+	Vout        []*DecodedMultichainVout `json:"vout"`
+	TotalIn     int64                    `json:"total_in"`
+	TotalOut    int64                    `json:"total_out"`
+	Fee         int64                    `json:"fee"`
+	FeeRate     float64                  `json:"fee_rate"` // per vbyte
+	FeeKnown    bool                     `json:"fee_known"`
+	Swaps       []*DecodedAtomicSwap     `json:"swaps,omitempty"`
+	Confirmed   bool                     `json:"confirmed"`
+	BlockHeight int64                    `json:"block_height,omitempty"`
+	InMempool   bool                     `json:"in_mempool,omitempty"`
+	Warnings    []string                 `json:"warnings,omitempty"`
+	// SerializedTx is the network serialization of the transaction. For a
+	// PSBT, it is only set when all inputs are finalized.
+	SerializedTx []byte `json:"-"`
+}
+
+// AddWarning appends a warning message to the decoded transaction.
+func (tx *DecodedMultichainTx) AddWarning(msg string) {
+	tx.Warnings = append(tx.Warnings, msg)
+}
+
+// ComputeFee sums the known input values and sets the fee and fee rate. The
+// fee is only considered known when every input's previous output was found.
+func (tx *DecodedMultichainTx) ComputeFee() {
+	tx.TotalIn, tx.TotalOut = 0, 0
+	known := len(tx.Vin) > 0
+	for _, vin := range tx.Vin {
+		if vin.Coinbase || !vin.PrevFound {
+			known = false
+			continue
+		}
+		tx.TotalIn += vin.Value
+	}
+	for _, vout := range tx.Vout {
+		tx.TotalOut += vout.Value
+	}
+	tx.FeeKnown = known
+	if !known {
+		tx.Fee, tx.FeeRate = 0, 0
+		return
+	}
+	tx.Fee = tx.TotalIn - tx.TotalOut
+	if tx.Fee < 0 {
+		tx.AddWarning("inputs are worth less than outputs")
+	}
+	if tx.VSize > 0 {
+		tx.FeeRate = float64(tx.Fee) / float64(tx.VSize)
+	}
+}
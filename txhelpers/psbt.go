@@ -0,0 +1,340 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package txhelpers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// psbtMagic is the BIP-174 magic prefix, "psbt" followed by the 0xff
+// separator.
+var psbtMagic = []byte{0x70, 0x73, 0x62, 0x74, 0xff}
+
+// maxPSBTSize is the upper limit on the size of a serialized PSBT that will be
+// parsed. It matches the websocket payload limit.
+const maxPSBTSize = 1 << 20
+
+// BIP-174 global, input and output key types that are interpreted by
+// ParsePSBT. Unknown and proprietary keys are skipped.
+const (
+	psbtGlobalUnsignedTx = 0x00
+	psbtGlobalVersion    = 0xfb
+
+	psbtInNonWitnessUtxo     = 0x00
+	psbtInWitnessUtxo        = 0x01
+	psbtInPartialSig         = 0x02
+	psbtInSighashType        = 0x03
+	psbtInRedeemScript       = 0x04
+	psbtInWitnessScript      = 0x05
+	psbtInBip32Derivation    = 0x06
+	psbtInFinalScriptSig     = 0x07
+	psbtInFinalScriptWitness = 0x08
+
+	psbtOutRedeemScript    = 0x00
+	psbtOutWitnessScript   = 0x01
+	psbtOutBip32Derivation = 0x02
+)
+
+// ErrNotPSBT is returned by ParsePSBT when the input does not begin with the
+// BIP-174 magic bytes.
+var ErrNotPSBT = errors.New("not a PSBT")
+
+// PSBTWitnessUtxo is the output spent by a PSBT input, as carried in the
+// PSBT_IN_WITNESS_UTXO field.
+type PSBTWitnessUtxo struct {
+	Value    int64
+	PkScript []byte
+}
+
+// PSBTInput holds the per-input fields of a PSBT that are relevant to decoding
+// and finalization checks. Transactions are kept in serialized form so that
+// the container parsing does not depend on any one chain's wire package.
+type PSBTInput struct {
+	NonWitnessUtxo     []byte
+	WitnessUtxo        *PSBTWitnessUtxo
+	PartialSigs        int
+	SighashType        uint32
+	RedeemScript       []byte
+	WitnessScript      []byte
+	Bip32Derivations   int
+	FinalScriptSig     []byte
+	FinalScriptWitness [][]byte
+}
+
+// IsFinalized indicates if the input carries a final scriptSig or a final
+// script witness.
+func (in *PSBTInput) IsFinalized() bool {
+	return len(in.FinalScriptSig) > 0 || len(in.FinalScriptWitness) > 0
+}
+
+// PSBTOutput holds the per-output fields of a PSBT.
+type PSBTOutput struct {
+	RedeemScript     []byte
+	WitnessScript    []byte
+	Bip32Derivations int
+}
+
+// PSBTPacket is a parsed BIP-174 partially signed transaction.
+type PSBTPacket struct {
+	Version    uint32
+	UnsignedTx []byte
+	Inputs     []PSBTInput
+	Outputs    []PSBTOutput
+}
+
+// IsFinalized indicates if every input of the packet is finalized, meaning the
+// network transaction may be extracted and broadcast.
+func (p *PSBTPacket) IsFinalized() bool {
+	for i := range p.Inputs {
+		if !p.Inputs[i].IsFinalized() {
+			return false
+		}
+	}
+	return len(p.Inputs) > 0
+}
+
+// IsPSBT checks for the BIP-174 magic prefix.
+func IsPSBT(b []byte) bool {
+	return bytes.HasPrefix(b, psbtMagic)
+}
+
+// DecodeRawTxOrPSBTString decodes user input that may be a hex encoded
+// transaction, a hex encoded PSBT or a base64 encoded PSBT. The raw bytes are
+// returned along with a flag indicating if they are a PSBT.
+func DecodeRawTxOrPSBTString(s string) ([]byte, bool, error) {
+	s = strings.Join(strings.Fields(s), "")
+	if s == "" {
+		return nil, false, fmt.Errorf("empty input")
+	}
+	if b, err := hex.DecodeString(s); err == nil {
+		return b, IsPSBT(b), nil
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, false, fmt.Errorf("input is neither hex nor base64 encoded")
+	}
+	if !IsPSBT(b) {
+		return nil, false, fmt.Errorf("base64 input is not a PSBT")
+	}
+	return b, true, nil
+}
+
+// ParsePSBT parses a serialized BIP-174 PSBT. The numbers of inputs and
+// outputs are taken from the caller-supplied counter, which must deserialize
+// the unsigned transaction with the appropriate chain's wire package.
+func ParsePSBT(b []byte, countIO func(unsignedTx []byte) (numIn, numOut int, err error)) (*PSBTPacket, error) {
+	if !IsPSBT(b) {
+		return nil, ErrNotPSBT
+	}
+	if len(b) > maxPSBTSize {
+		return nil, fmt.Errorf("PSBT too large (%d bytes)", len(b))
+	}
+	r := bytes.NewReader(b[len(psbtMagic):])
+
+	p := new(PSBTPacket)
+	err := readPSBTMap(r, func(keyType byte, keyData, value []byte) error {
+		switch keyType {
+		case psbtGlobalUnsignedTx:
+			if len(keyData) != 0 {
+				return fmt.Errorf("invalid unsigned tx key")
+			}
+			if p.UnsignedTx != nil {
+				return fmt.Errorf("duplicate unsigned tx")
+			}
+			p.UnsignedTx = value
+		case psbtGlobalVersion:
+			if len(value) != 4 {
+				return fmt.Errorf("invalid PSBT version")
+			}
+			p.Version = binary.LittleEndian.Uint32(value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("global map: %w", err)
+	}
+	if p.UnsignedTx == nil {
+		return nil, fmt.Errorf("PSBT has no unsigned transaction")
+	}
+	if p.Version != 0 {
+		return nil, fmt.Errorf("unsupported PSBT version %d", p.Version)
+	}
+
+	numIn, numOut, err := countIO(p.UnsignedTx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid unsigned transaction: %w", err)
+	}
+
+	p.Inputs = make([]PSBTInput, numIn)
+	for i := range p.Inputs {
+		in := &p.Inputs[i]
+		err = readPSBTMap(r, func(keyType byte, keyData, value []byte) error {
+			switch keyType {
+			case psbtInNonWitnessUtxo:
+				in.NonWitnessUtxo = value
+			case psbtInWitnessUtxo:
+				utxo, err := parsePSBTTxOut(value)
+				if err != nil {
+					return err
+				}
+				in.WitnessUtxo = utxo
+			case psbtInPartialSig:
+				in.PartialSigs++
+			case psbtInSighashType:
+				if len(value) != 4 {
+					return fmt.Errorf("invalid sighash type")
+				}
+				in.SighashType = binary.LittleEndian.Uint32(value)
+			case psbtInRedeemScript:
+				in.RedeemScript = value
+			case psbtInWitnessScript:
+				in.WitnessScript = value
+			case psbtInBip32Derivation:
+				in.Bip32Derivations++
+			case psbtInFinalScriptSig:
+				in.FinalScriptSig = value
+			case psbtInFinalScriptWitness:
+				wit, err := parsePSBTWitness(value)
+				if err != nil {
+					return err
+				}
+				in.FinalScriptWitness = wit
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("input %d: %w", i, err)
+		}
+	}
+
+	p.Outputs = make([]PSBTOutput, numOut)
+	for i := range p.Outputs {
+		out := &p.Outputs[i]
+		err = readPSBTMap(r, func(keyType byte, keyData, value []byte) error {
+			switch keyType {
+			case psbtOutRedeemScript:
+				out.RedeemScript = value
+			case psbtOutWitnessScript:
+				out.WitnessScript = value
+			case psbtOutBip32Derivation:
+				out.Bip32Derivations++
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("output %d: %w", i, err)
+		}
+	}
+
+	return p, nil
+}
+
+// readPSBTMap reads key-value pairs until the 0x00 map separator, calling fn
+// for each pair.
+func readPSBTMap(r *bytes.Reader, fn func(keyType byte, keyData, value []byte) error) error {
+	seen := make(map[string]struct{})
+	for {
+		key, err := readPSBTVarBytes(r)
+		if err != nil {
+			return err
+		}
+		if len(key) == 0 {
+			return nil // separator
+		}
+		if _, dup := seen[string(key)]; dup {
+			return fmt.Errorf("duplicate key %x", key)
+		}
+		seen[string(key)] = struct{}{}
+		value, err := readPSBTVarBytes(r)
+		if err != nil {
+			return err
+		}
+		if err = fn(key[0], key[1:], value); err != nil {
+			return err
+		}
+	}
+}
+
+func readPSBTCompactSize(r *bytes.Reader) (uint64, error) {
+	d, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	switch d {
+	case 0xfd:
+		n = 2
+	case 0xfe:
+		n = 4
+	case 0xff:
+		n = 8
+	default:
+		return uint64(d), nil
+	}
+	buf := make([]byte, 8)
+	if _, err = io.ReadFull(r, buf[:n]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(buf), nil
+}
+
+func readPSBTVarBytes(r *bytes.Reader) ([]byte, error) {
+	n, err := readPSBTCompactSize(r)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if n > uint64(r.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := make([]byte, n)
+	if _, err = io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func parsePSBTTxOut(b []byte) (*PSBTWitnessUtxo, error) {
+	if len(b) < 9 {
+		return nil, fmt.Errorf("invalid witness utxo")
+	}
+	r := bytes.NewReader(b[8:])
+	pkScript, err := readPSBTVarBytes(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid witness utxo: %w", err)
+	}
+	return &PSBTWitnessUtxo{
+		Value:    int64(binary.LittleEndian.Uint64(b[:8])),
+		PkScript: pkScript,
+	}, nil
+}
+
+func parsePSBTWitness(b []byte) ([][]byte, error) {
+	r := bytes.NewReader(b)
+	n, err := readPSBTCompactSize(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script witness: %w", err)
+	}
+	if n > uint64(len(b)) {
+		return nil, fmt.Errorf("invalid script witness item count")
+	}
+	wit := make([][]byte, 0, n)
+	for i := uint64(0); i < n; i++ {
+		item, err := readPSBTVarBytes(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid script witness: %w", err)
+		}
+		wit = append(wit, item)
+	}
+	return wit, nil
+}
@@ -0,0 +1,188 @@
+package txhelpers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	btcwire "github.com/btcsuite/btcd/wire"
+)
+
+func psbtKV(buf *bytes.Buffer, key, value []byte) {
+	btcwire.WriteVarBytes(buf, 0, key)
+	btcwire.WriteVarBytes(buf, 0, value)
+}
+
+func testUnsignedTx(t *testing.T) []byte {
+	t.Helper()
+	tx := btcwire.NewMsgTx(2)
+	prevHash, _ := chainhash.NewHashFromStr("f61b1742ca13176464adb3cb66050c00787bb3a4eead37e985f2df1e37718126")
+	tx.AddTxIn(btcwire.NewTxIn(btcwire.NewOutPoint(prevHash, 0), nil, nil))
+	tx.TxIn[0].Sequence = 0xfffffffd
+	tx.AddTxOut(btcwire.NewTxOut(99999699, []byte{0x00, 0x14, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}))
+	tx.AddTxOut(btcwire.NewTxOut(100000000, []byte{0x6a, 0x01, 0x2a}))
+	var b bytes.Buffer
+	if err := tx.SerializeNoWitness(&b); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func countTestIO(unsignedTx []byte) (int, int, error) {
+	tx := btcwire.NewMsgTx(2)
+	if err := tx.DeserializeNoWitness(bytes.NewReader(unsignedTx)); err != nil {
+		return 0, 0, err
+	}
+	return len(tx.TxIn), len(tx.TxOut), nil
+}
+
+func testPSBT(t *testing.T, finalized bool) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	b.Write(psbtMagic)
+	psbtKV(&b, []byte{psbtGlobalUnsignedTx}, testUnsignedTx(t))
+	b.WriteByte(0)
+
+	// input 0
+	utxo := make([]byte, 8)
+	binary.LittleEndian.PutUint64(utxo, 200000000)
+	pkScript := []byte{0x00, 0x14, 20, 19, 18, 17, 16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+	utxo = append(utxo, byte(len(pkScript)))
+	utxo = append(utxo, pkScript...)
+	psbtKV(&b, []byte{psbtInWitnessUtxo}, utxo)
+	psbtKV(&b, append([]byte{psbtInPartialSig}, bytes.Repeat([]byte{2}, 33)...), []byte{0x30, 0x01})
+	sighash := make([]byte, 4)
+	binary.LittleEndian.PutUint32(sighash, 1)
+	psbtKV(&b, []byte{psbtInSighashType}, sighash)
+	if finalized {
+		psbtKV(&b, []byte{psbtInFinalScriptWitness}, []byte{0x02, 0x01, 0xaa, 0x02, 0xbb, 0xcc})
+	}
+	b.WriteByte(0)
+
+	// output 0 with a witness script, output 1 empty
+	psbtKV(&b, []byte{psbtOutWitnessScript}, []byte{0x51})
+	b.WriteByte(0)
+	b.WriteByte(0)
+	return b.Bytes()
+}
+
+func TestParsePSBT(t *testing.T) {
+	raw := testPSBT(t, false)
+	p, err := ParsePSBT(raw, countTestIO)
+	if err != nil {
+		t.Fatalf("ParsePSBT: %v", err)
+	}
+	if len(p.Inputs) != 1 || len(p.Outputs) != 2 {
+		t.Fatalf("wrong io counts: %d in, %d out", len(p.Inputs), len(p.Outputs))
+	}
+	in := p.Inputs[0]
+	if in.WitnessUtxo == nil || in.WitnessUtxo.Value != 200000000 || len(in.WitnessUtxo.PkScript) != 22 {
+		t.Errorf("bad witness utxo: %+v", in.WitnessUtxo)
+	}
+	if in.PartialSigs != 1 {
+		t.Errorf("expected 1 partial sig, got %d", in.PartialSigs)
+	}
+	if in.SighashType != 1 {
+		t.Errorf("expected sighash type 1, got %d", in.SighashType)
+	}
+	if p.IsFinalized() {
+		t.Errorf("packet should not be finalized")
+	}
+	if !bytes.Equal(p.Outputs[0].WitnessScript, []byte{0x51}) {
+		t.Errorf("bad output witness script %x", p.Outputs[0].WitnessScript)
+	}
+
+	p, err = ParsePSBT(testPSBT(t, true), countTestIO)
+	if err != nil {
+		t.Fatalf("ParsePSBT: %v", err)
+	}
+	if !p.IsFinalized() {
+		t.Errorf("packet should be finalized")
+	}
+	wit := p.Inputs[0].FinalScriptWitness
+	if len(wit) != 2 || !bytes.Equal(wit[0], []byte{0xaa}) || !bytes.Equal(wit[1], []byte{0xbb, 0xcc}) {
+		t.Errorf("bad final witness %x", wit)
+	}
+}
+
+func TestParsePSBTErrors(t *testing.T) {
+	raw := testPSBT(t, false)
+
+	if _, err := ParsePSBT(raw[1:], countTestIO); err != ErrNotPSBT {
+		t.Errorf("expected ErrNotPSBT, got %v", err)
+	}
+	if _, err := ParsePSBT(raw[:len(raw)-3], countTestIO); err == nil {
+		t.Errorf("expected error for truncated PSBT")
+	}
+
+	// Duplicate unsigned tx key in the global map.
+	var b bytes.Buffer
+	b.Write(psbtMagic)
+	unsigned := testUnsignedTx(t)
+	psbtKV(&b, []byte{psbtGlobalUnsignedTx}, unsigned)
+	psbtKV(&b, []byte{psbtGlobalUnsignedTx}, unsigned)
+	b.WriteByte(0)
+	if _, err := ParsePSBT(b.Bytes(), countTestIO); err == nil {
+		t.Errorf("expected error for duplicate key")
+	}
+
+	// No unsigned tx.
+	b.Reset()
+	b.Write(psbtMagic)
+	b.WriteByte(0)
+	if _, err := ParsePSBT(b.Bytes(), countTestIO); err == nil {
+		t.Errorf("expected error for missing unsigned tx")
+	}
+}
+
+func TestDecodeRawTxOrPSBTString(t *testing.T) {
+	raw := testPSBT(t, false)
+	tests := []struct {
+		name    string
+		in      string
+		isPSBT  bool
+		wantErr bool
+	}{
+		{"hex psbt", hex.EncodeToString(raw), true, false},
+		{"base64 psbt", base64.StdEncoding.EncodeToString(raw), true, false},
+		{"hex tx with whitespace", " 0200 0000\n00", false, false},
+		{"base64 non-psbt", base64.StdEncoding.EncodeToString([]byte("hello")), false, true},
+		{"garbage", "not*valid", false, true},
+		{"empty", "  ", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, isPSBT, err := DecodeRawTxOrPSBTString(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error state: %v", err)
+			}
+			if isPSBT != tt.isPSBT {
+				t.Errorf("isPSBT = %v, want %v", isPSBT, tt.isPSBT)
+			}
+		})
+	}
+}
+
+func TestDecodedMultichainTxComputeFee(t *testing.T) {
+	tx := &DecodedMultichainTx{
+		VSize: 100,
+		Vin: []*DecodedMultichainVin{
+			{PrevFound: true, Value: 5000},
+			{PrevFound: true, Value: 3000},
+		},
+		Vout: []*DecodedMultichainVout{{Value: 6000}},
+	}
+	tx.ComputeFee()
+	if !tx.FeeKnown || tx.Fee != 2000 || tx.FeeRate != 20 {
+		t.Errorf("wrong fee: known %v, fee %d, rate %f", tx.FeeKnown, tx.Fee, tx.FeeRate)
+	}
+
+	tx.Vin[1].PrevFound = false
+	tx.ComputeFee()
+	if tx.FeeKnown || tx.Fee != 0 {
+		t.Errorf("fee should be unknown with unresolved input")
+	}
+}
@@ -370,26 +370,46 @@ func CalculateBTCTxFee(client *rpcclient.Client, msgTx *wire.MsgTx) (btcutil.Amo
 	return fee, nil
 }
 
-func BTCTxFeeRate(msgTx *btcwire.MsgTx, client BTCVerboseTransactionGetter) (btcutil.Amount, btcutil.Amount) {
-	var amtIn int64
+// BTCTxFee computes the fee and fee rate of a transaction from the values of
+// the previous outputs it spends. An error is returned if a previous output
+// cannot be retrieved, or if the fee is negative.
+func BTCTxFee(msgTx *btcwire.MsgTx, client BTCVerboseTransactionGetter) (btcutil.Amount, btcutil.Amount, error) {
+	var amtIn btcutil.Amount
 	for _, txin := range msgTx.TxIn {
-		//Get transaction
-		blockhash, err := chainhash.NewHashFromStr(txin.PreviousOutPoint.Hash.String())
-		if err != nil {
-			continue
-		}
+		prevOut := txin.PreviousOutPoint
 		txResult, err := WithTimeout(func() (*btcjson.TxRawResult, error) {
-			return client.GetRawTransactionVerbose(blockhash)
+			return client.GetRawTransactionVerbose(&prevOut.Hash)
 		})
 		if err != nil {
-			continue
+			return 0, 0, fmt.Errorf("previous output %v: %w", prevOut, err)
+		}
+		if int(prevOut.Index) >= len(txResult.Vout) {
+			return 0, 0, fmt.Errorf("previous output %v does not exist", prevOut)
+		}
+		// The verbose result has the value in coins.
+		value, err := btcutil.NewAmount(txResult.Vout[prevOut.Index].Value)
+		if err != nil {
+			return 0, 0, fmt.Errorf("previous output %v: %w", prevOut, err)
 		}
-		amtIn += int64(txResult.Vout[txin.PreviousOutPoint.Index].Value)
+		amtIn += value
 	}
-	var amtOut int64
+	var amtOut btcutil.Amount
 	for iv := range msgTx.TxOut {
-		amtOut += msgTx.TxOut[iv].Value
+		amtOut += btcutil.Amount(msgTx.TxOut[iv].Value)
+	}
+	if amtIn < amtOut {
+		return 0, 0, fmt.Errorf("inputs %v are less than outputs %v", amtIn, amtOut)
 	}
 	txSize := int64(msgTx.SerializeSize())
-	return btcutil.Amount(amtIn - amtOut), btcutil.Amount(FeeRate(amtIn, amtOut, txSize))
+	return amtIn - amtOut, btcutil.Amount(FeeRate(int64(amtIn), int64(amtOut), txSize)), nil
+}
+
+// BTCTxFeeRate computes the fee and fee rate of a transaction like BTCTxFee,
+// but returns zero amounts if the fee cannot be computed.
+func BTCTxFeeRate(msgTx *btcwire.MsgTx, client BTCVerboseTransactionGetter) (btcutil.Amount, btcutil.Amount) {
+	fee, feeRate, err := BTCTxFee(msgTx, client)
+	if err != nil {
+		return 0, 0
+	}
+	return fee, feeRate
 }
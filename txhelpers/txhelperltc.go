@@ -307,28 +307,48 @@ func LTCTxPrevOutsByAddr(txAddrOuts LTCMempoolAddressStore, txnsStore LTCTxnsSto
 	return
 }
 
-func LTCTxFeeRate(msgTx *ltcwire.MsgTx, client LTCVerboseTransactionGetter) (ltcutil.Amount, ltcutil.Amount) {
-	var amtIn int64
+// LTCTxFee computes the fee and fee rate of a transaction from the values of
+// the previous outputs it spends. An error is returned if a previous output
+// cannot be retrieved, or if the fee is negative.
+func LTCTxFee(msgTx *ltcwire.MsgTx, client LTCVerboseTransactionGetter) (ltcutil.Amount, ltcutil.Amount, error) {
+	var amtIn ltcutil.Amount
 	for _, txin := range msgTx.TxIn {
-		//Get transaction
-		blockhash, err := chainhash.NewHashFromStr(txin.PreviousOutPoint.Hash.String())
-		if err != nil {
-			continue
-		}
+		prevOut := txin.PreviousOutPoint
 		txResult, err := WithTimeout(func() (*ltcjson.TxRawResult, error) {
-			return client.GetRawTransactionVerbose(blockhash)
+			return client.GetRawTransactionVerbose(&prevOut.Hash)
 		})
 		if err != nil {
-			continue
+			return 0, 0, fmt.Errorf("previous output %v: %w", prevOut, err)
+		}
+		if int(prevOut.Index) >= len(txResult.Vout) {
+			return 0, 0, fmt.Errorf("previous output %v does not exist", prevOut)
+		}
+		// The verbose result has the value in coins.
+		value, err := ltcutil.NewAmount(txResult.Vout[prevOut.Index].Value)
+		if err != nil {
+			return 0, 0, fmt.Errorf("previous output %v: %w", prevOut, err)
 		}
-		amtIn += int64(txResult.Vout[txin.PreviousOutPoint.Index].Value)
+		amtIn += value
 	}
-	var amtOut int64
+	var amtOut ltcutil.Amount
 	for iv := range msgTx.TxOut {
-		amtOut += msgTx.TxOut[iv].Value
+		amtOut += ltcutil.Amount(msgTx.TxOut[iv].Value)
+	}
+	if amtIn < amtOut {
+		return 0, 0, fmt.Errorf("inputs %v are less than outputs %v", amtIn, amtOut)
 	}
 	txSize := int64(msgTx.SerializeSize())
-	return ltcutil.Amount(amtIn - amtOut), ltcutil.Amount(FeeRate(amtIn, amtOut, txSize))
+	return amtIn - amtOut, ltcutil.Amount(FeeRate(int64(amtIn), int64(amtOut), txSize)), nil
+}
+
+// LTCTxFeeRate computes the fee and fee rate of a transaction like LTCTxFee,
+// but returns zero amounts if the fee cannot be computed.
+func LTCTxFeeRate(msgTx *ltcwire.MsgTx, client LTCVerboseTransactionGetter) (ltcutil.Amount, ltcutil.Amount) {
+	fee, feeRate, err := LTCTxFee(msgTx, client)
+	if err != nil {
+		return 0, 0
+	}
+	return fee, feeRate
 }
 
 // Get total of BTC for tx inputs
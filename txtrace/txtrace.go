@@ -0,0 +1,348 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+// Package txtrace follows the flow of funds from a transaction output, forward
+// through the transactions spending it or backward through the transactions
+// funding it, for a number of hops. Outputs below a minimum value are pruned,
+// and the trace stops at mixes and CoinJoins, atomic swaps, and where the
+// funds reach another address cluster.
+package txtrace
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrdata/v8/cluster"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/mutilchain"
+)
+
+// Trace directions.
+const (
+	Forward  = "forward"
+	Backward = "backward"
+)
+
+// Reasons a node is not followed, as TxGraphNode.Stop.
+const (
+	StopMix      = "mix"
+	StopSwap     = "swap"
+	StopCluster  = "cluster"
+	StopCoinbase = "coinbase"
+	StopHops     = "hops"
+)
+
+const (
+	// DefaultHops is the hops of a trace requested without them.
+	DefaultHops = 3
+	MaxHops     = 10
+	// MaxNodes bounds the transactions of a graph, as each takes a few
+	// queries.
+	MaxNodes = 250
+)
+
+var (
+	// ErrInvalidTrace is wrapped by the errors of Trace for a bad request.
+	ErrInvalidTrace = errors.New("invalid trace")
+	// ErrNotFound is returned by Trace for an unknown transaction.
+	ErrNotFound = errors.New("transaction not found")
+)
+
+// Store retrieves the transactions of a trace, implemented by dcrpg.ChainDB.
+type Store interface {
+	// TraceTx returns the transaction with its inputs and outputs, or nil
+	// for an unknown transaction.
+	TraceTx(chain, txHash string) (*dbtypes.TraceTx, error)
+}
+
+// Request is a trace from an output of a transaction.
+type Request struct {
+	Chain     string
+	TxHash    string
+	Vout      uint32
+	Direction string
+	Hops      int
+	MinValue  int64
+}
+
+// Chain reports whether the transactions of a chain can be traced.
+func Chain(chain string) bool {
+	switch chain {
+	case mutilchain.TYPEDCR, mutilchain.TYPEBTC, mutilchain.TYPELTC:
+		return true
+	}
+	return false
+}
+
+// ParseRequest parses a trace of a chain from the txid, vout, direction, hops
+// and min_value (atoms) query parameters. The direction defaults to Forward
+// and the hops to DefaultHops.
+func ParseRequest(chain string, query url.Values) (*Request, error) {
+	if !Chain(chain) {
+		return nil, fmt.Errorf("%w: unsupported chain %q", ErrInvalidTrace, chain)
+	}
+	req := &Request{
+		Chain:     chain,
+		TxHash:    query.Get("txid"),
+		Direction: Forward,
+		Hops:      DefaultHops,
+	}
+	if _, err := chainhash.NewHashFromStr(req.TxHash); err != nil || len(req.TxHash) != 2*chainhash.HashSize {
+		return nil, fmt.Errorf("%w: invalid txid", ErrInvalidTrace)
+	}
+	if v := query.Get("vout"); v != "" {
+		vout, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid vout", ErrInvalidTrace)
+		}
+		req.Vout = uint32(vout)
+	}
+	if d := query.Get("direction"); d != "" {
+		req.Direction = d
+	}
+	if h := query.Get("hops"); h != "" {
+		hops, err := strconv.Atoi(h)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid hops", ErrInvalidTrace)
+		}
+		req.Hops = hops
+	}
+	if v := query.Get("min_value"); v != "" {
+		minValue, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid min_value", ErrInvalidTrace)
+		}
+		req.MinValue = minValue
+	}
+	return req, nil
+}
+
+type tracer struct {
+	store Store
+	req   *Request
+	graph *dbtypes.TxGraph
+	nodes map[string]*dbtypes.TxGraphNode
+	// cluster is the address cluster of the traced funds, or 0.
+	cluster int64
+}
+
+// Trace walks the spends (Forward) or the funding (Backward) of an output
+// breadth first, up to the hops of the request or MaxNodes transactions.
+func Trace(store Store, req *Request) (*dbtypes.TxGraph, error) {
+	if req.Direction != Forward && req.Direction != Backward {
+		return nil, fmt.Errorf("%w: direction must be %s or %s", ErrInvalidTrace, Forward, Backward)
+	}
+	if req.Hops < 1 || req.Hops > MaxHops {
+		return nil, fmt.Errorf("%w: hops must be between 1 and %d", ErrInvalidTrace, MaxHops)
+	}
+	if req.MinValue < 0 {
+		return nil, fmt.Errorf("%w: negative minimum value", ErrInvalidTrace)
+	}
+	root, err := store.TraceTx(req.Chain, req.TxHash)
+	if err != nil {
+		return nil, err
+	}
+	if root == nil {
+		return nil, ErrNotFound
+	}
+	var rootOut *dbtypes.TraceOutput
+	for _, out := range root.Outputs {
+		if out.Vout == req.Vout {
+			rootOut = out
+			break
+		}
+	}
+	if rootOut == nil {
+		return nil, fmt.Errorf("%w: no output %d", ErrInvalidTrace, req.Vout)
+	}
+
+	t := &tracer{
+		store: store,
+		req:   req,
+		graph: &dbtypes.TxGraph{
+			Chain:     req.Chain,
+			TxHash:    req.TxHash,
+			Vout:      req.Vout,
+			Direction: req.Direction,
+			Hops:      req.Hops,
+			MinValue:  req.MinValue,
+			Nodes:     make([]*dbtypes.TxGraphNode, 0),
+			Edges:     make([]*dbtypes.TxGraphEdge, 0),
+		},
+		nodes: make(map[string]*dbtypes.TxGraphNode),
+	}
+	if req.Direction == Forward {
+		t.cluster = rootOut.ClusterID
+	} else {
+		for _, in := range root.Inputs {
+			if in.ClusterID != 0 {
+				t.cluster = in.ClusterID
+				break
+			}
+		}
+	}
+	t.add(root, 0)
+
+	queue := []*dbtypes.TraceTx{root}
+	for len(queue) > 0 {
+		tx := queue[0]
+		queue = queue[1:]
+		var next []*dbtypes.TraceTx
+		if req.Direction == Forward {
+			outs := tx.Outputs
+			if tx == root {
+				outs = []*dbtypes.TraceOutput{rootOut}
+			}
+			next, err = t.forward(tx, outs)
+		} else {
+			next, err = t.backward(tx)
+		}
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, next...)
+	}
+	return t.graph, nil
+}
+
+// forward adds the spends of the outputs of a transaction, and returns the
+// new spending transactions to follow.
+func (t *tracer) forward(tx *dbtypes.TraceTx, outs []*dbtypes.TraceOutput) ([]*dbtypes.TraceTx, error) {
+	hop := t.nodes[tx.TxHash].Hop + 1
+	var next []*dbtypes.TraceTx
+	for _, out := range outs {
+		if out.Value < t.req.MinValue {
+			t.graph.Pruned++
+			continue
+		}
+		edge := &dbtypes.TxGraphEdge{
+			From:    tx.TxHash,
+			To:      out.SpendTxHash,
+			Vout:    out.Vout,
+			Address: out.Address,
+			Value:   out.Value,
+		}
+		if out.SpendTxHash == "" {
+			t.graph.Edges = append(t.graph.Edges, edge)
+			continue
+		}
+		spend, ok, err := t.visit(out.SpendTxHash, hop)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		t.graph.Edges = append(t.graph.Edges, edge)
+		if spend != nil {
+			next = append(next, spend)
+		}
+	}
+	return next, nil
+}
+
+// backward adds the funding of the inputs of a transaction, and returns the
+// new funding transactions to follow.
+func (t *tracer) backward(tx *dbtypes.TraceTx) ([]*dbtypes.TraceTx, error) {
+	hop := t.nodes[tx.TxHash].Hop + 1
+	var next []*dbtypes.TraceTx
+	for _, in := range tx.Inputs {
+		if in.Value < t.req.MinValue {
+			t.graph.Pruned++
+			continue
+		}
+		funding, ok, err := t.visit(in.PrevTxHash, hop)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		t.graph.Edges = append(t.graph.Edges, &dbtypes.TxGraphEdge{
+			From:    in.PrevTxHash,
+			To:      tx.TxHash,
+			Vout:    in.PrevVout,
+			Address: in.Address,
+			Value:   in.Value,
+		})
+		if funding != nil {
+			next = append(next, funding)
+		}
+	}
+	return next, nil
+}
+
+// visit adds the node of a transaction reached at a hop, unless it is known.
+// It returns the transaction if it is to be followed, and false when the
+// graph is full.
+func (t *tracer) visit(txHash string, hop int) (*dbtypes.TraceTx, bool, error) {
+	if _, ok := t.nodes[txHash]; ok {
+		return nil, true, nil
+	}
+	if len(t.nodes) >= MaxNodes {
+		t.graph.Truncated = true
+		return nil, false, nil
+	}
+	tx, err := t.store.TraceTx(t.req.Chain, txHash)
+	if err != nil {
+		return nil, false, err
+	}
+	if tx == nil {
+		// Not stored yet, e.g. a mempool spend.
+		t.add(&dbtypes.TraceTx{TxHash: txHash}, hop)
+		return nil, true, nil
+	}
+	node := t.add(tx, hop)
+	node.Stop = t.stop(tx)
+	if node.Stop == "" && hop >= t.req.Hops {
+		node.Stop = StopHops
+	}
+	if node.Stop != "" {
+		return nil, true, nil
+	}
+	return tx, true, nil
+}
+
+func (t *tracer) add(tx *dbtypes.TraceTx, hop int) *dbtypes.TxGraphNode {
+	node := &dbtypes.TxGraphNode{
+		TxHash:      tx.TxHash,
+		Hop:         hop,
+		BlockHeight: tx.BlockHeight,
+		BlockTime:   tx.BlockTime,
+	}
+	t.nodes[tx.TxHash] = node
+	t.graph.Nodes = append(t.graph.Nodes, node)
+	return node
+}
+
+// stop returns the reason a transaction is not followed, or an empty string.
+func (t *tracer) stop(tx *dbtypes.TraceTx) string {
+	if tx.Mix || cluster.IsCoinJoin(t.req.Chain, len(tx.Inputs), outputValues(tx)) {
+		return StopMix
+	}
+	if tx.Swap {
+		return StopSwap
+	}
+	// The inputs of a transaction are controlled by its sender.
+	for _, in := range tx.Inputs {
+		if in.ClusterID != 0 && in.ClusterID != t.cluster {
+			return StopCluster
+		}
+	}
+	if t.req.Direction == Backward && len(tx.Inputs) == 0 {
+		return StopCoinbase
+	}
+	return ""
+}
+
+// outputValues returns the output values of a transaction.
+func outputValues(tx *dbtypes.TraceTx) []int64 {
+	outs := make([]int64, 0, len(tx.Outputs))
+	for _, out := range tx.Outputs {
+		outs = append(outs, out.Value)
+	}
+	return outs
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package txtrace
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+)
+
+type testStore map[string]*dbtypes.TraceTx
+
+func (s testStore) TraceTx(_, txHash string) (*dbtypes.TraceTx, error) {
+	return s[txHash], nil
+}
+
+// add adds a transaction to a test store, setting the spends of the outputs
+// it spends and the funding of its inputs.
+func (s testStore) add(tx *dbtypes.TraceTx) {
+	s[tx.TxHash] = tx
+	for _, in := range tx.Inputs {
+		prev := s[in.PrevTxHash]
+		if prev == nil {
+			continue
+		}
+		for _, out := range prev.Outputs {
+			if out.Vout == in.PrevVout {
+				out.SpendTxHash = tx.TxHash
+				in.Address, in.Value, in.ClusterID = out.Address, out.Value, out.ClusterID
+			}
+		}
+	}
+}
+
+func in(prev string, vout uint32) *dbtypes.TraceInput {
+	return &dbtypes.TraceInput{PrevTxHash: prev, PrevVout: vout}
+}
+
+func out(vout uint32, addr string, value, clusterID int64) *dbtypes.TraceOutput {
+	return &dbtypes.TraceOutput{Vout: vout, Address: addr, Value: value, ClusterID: clusterID}
+}
+
+// testTxs returns a store of the transactions:
+//
+//	a -> b -> c -> d -> e
+//	       \-> f (mix)
+//	       \-> g (other cluster)
+//
+// with a dust output of b spent by h.
+func testTxs() testStore {
+	s := make(testStore)
+	s.add(&dbtypes.TraceTx{TxHash: "a", BlockHeight: 1,
+		Outputs: []*dbtypes.TraceOutput{out(0, "a0", 100e8, 1), out(1, "a1", 50e8, 0)}})
+	s.add(&dbtypes.TraceTx{TxHash: "b", BlockHeight: 2, Inputs: []*dbtypes.TraceInput{in("a", 0)},
+		Outputs: []*dbtypes.TraceOutput{out(0, "b0", 40e8, 1), out(1, "b1", 30e8, 0),
+			out(2, "b2", 20e8, 0), out(3, "b3", 1000, 0)}})
+	s.add(&dbtypes.TraceTx{TxHash: "c", BlockHeight: 3, Inputs: []*dbtypes.TraceInput{in("b", 0)},
+		Outputs: []*dbtypes.TraceOutput{out(0, "c0", 39e8, 1)}})
+	s.add(&dbtypes.TraceTx{TxHash: "d", BlockHeight: 4, Inputs: []*dbtypes.TraceInput{in("c", 0)},
+		Outputs: []*dbtypes.TraceOutput{out(0, "d0", 38e8, 1)}})
+	s.add(&dbtypes.TraceTx{TxHash: "e", BlockHeight: 5, Inputs: []*dbtypes.TraceInput{in("d", 0)},
+		Outputs: []*dbtypes.TraceOutput{out(0, "e0", 37e8, 1)}})
+	s.add(&dbtypes.TraceTx{TxHash: "f", BlockHeight: 3, Mix: true, Inputs: []*dbtypes.TraceInput{in("b", 1)},
+		Outputs: []*dbtypes.TraceOutput{out(0, "f0", 29e8, 0)}})
+	s["b"].Outputs[2].ClusterID = 2
+	s.add(&dbtypes.TraceTx{TxHash: "g", BlockHeight: 3, Inputs: []*dbtypes.TraceInput{in("b", 2)},
+		Outputs: []*dbtypes.TraceOutput{out(0, "g0", 19e8, 2)}})
+	s.add(&dbtypes.TraceTx{TxHash: "h", BlockHeight: 3, Inputs: []*dbtypes.TraceInput{in("b", 3)},
+		Outputs: []*dbtypes.TraceOutput{out(0, "h0", 900, 0)}})
+	return s
+}
+
+func nodeStops(g *dbtypes.TxGraph) map[string]string {
+	stops := make(map[string]string, len(g.Nodes))
+	for _, node := range g.Nodes {
+		stops[node.TxHash] = node.Stop
+	}
+	return stops
+}
+
+func TestTraceForward(t *testing.T) {
+	g, err := Trace(testTxs(), &Request{TxHash: "a", Vout: 0, Direction: Forward, Hops: 3, MinValue: 1e8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"a": "", "b": "", "c": "", "d": StopHops, "f": StopMix, "g": StopCluster}
+	if got := nodeStops(g); !reflect.DeepEqual(got, want) {
+		t.Errorf("nodes: got %v, want %v", got, want)
+	}
+	// a:0, b:0-2 and c:0. The dust output of b and the unfollowed a:1 are not
+	// edges.
+	if len(g.Edges) != 5 {
+		t.Errorf("got %d edges, want 5", len(g.Edges))
+	}
+	if g.Pruned != 1 {
+		t.Errorf("got %d pruned, want 1", g.Pruned)
+	}
+	if g.Truncated {
+		t.Error("unexpected truncation")
+	}
+}
+
+func TestTraceBackward(t *testing.T) {
+	g, err := Trace(testTxs(), &Request{TxHash: "e", Direction: Backward, Hops: DefaultHops})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"e": "", "d": "", "c": "", "b": StopHops}
+	if got := nodeStops(g); !reflect.DeepEqual(got, want) {
+		t.Errorf("nodes: got %v, want %v", got, want)
+	}
+	g, err = Trace(testTxs(), &Request{TxHash: "c", Direction: Backward, Hops: DefaultHops})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = map[string]string{"c": "", "b": "", "a": StopCoinbase}
+	if got := nodeStops(g); !reflect.DeepEqual(got, want) {
+		t.Errorf("nodes: got %v, want %v", got, want)
+	}
+	if len(g.Edges) != 2 || g.Edges[1].From != "a" || g.Edges[1].To != "b" || g.Edges[1].Value != 100e8 {
+		t.Errorf("unexpected edges %+v", g.Edges)
+	}
+}
+
+func TestTraceCoinJoin(t *testing.T) {
+	s := make(testStore)
+	s.add(&dbtypes.TraceTx{TxHash: "a", Outputs: []*dbtypes.TraceOutput{out(0, "a0", 1e6, 0)}})
+	cj := &dbtypes.TraceTx{TxHash: "w", Inputs: []*dbtypes.TraceInput{in("a", 0),
+		in("p", 0), in("q", 0), in("r", 0), in("s", 0)}}
+	for i := uint32(0); i < 5; i++ {
+		cj.Outputs = append(cj.Outputs, out(i, "w", 1e6, 0))
+	}
+	s.add(cj)
+	for _, chain := range []string{"btc", "ltc"} {
+		g, err := Trace(s, &Request{Chain: chain, TxHash: "a", Direction: Forward, Hops: 3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]string{"a": "", "w": StopMix}
+		if got := nodeStops(g); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s nodes: got %v, want %v", chain, got, want)
+		}
+	}
+}
+
+func TestTraceUnspent(t *testing.T) {
+	g, err := Trace(testTxs(), &Request{TxHash: "a", Vout: 1, Direction: Forward, Hops: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Nodes) != 1 || len(g.Edges) != 1 || g.Edges[0].To != "" {
+		t.Errorf("unexpected graph %+v", g)
+	}
+}
+
+func TestTraceTruncated(t *testing.T) {
+	s := make(testStore)
+	root := &dbtypes.TraceTx{TxHash: "root", Outputs: []*dbtypes.TraceOutput{out(0, "r", 1e8, 0)}}
+	s.add(root)
+	s.add(&dbtypes.TraceTx{TxHash: "fan", Inputs: []*dbtypes.TraceInput{in("root", 0)}})
+	fan := s["fan"]
+	for i := 0; i < MaxNodes+10; i++ {
+		fan.Outputs = append(fan.Outputs, out(uint32(i), "x", 1e8+int64(i), 0))
+		s.add(&dbtypes.TraceTx{TxHash: string(rune('A' + i)), Inputs: []*dbtypes.TraceInput{in("fan", uint32(i))}})
+	}
+	g, err := Trace(s, &Request{TxHash: "root", Direction: Forward, Hops: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.Truncated || len(g.Nodes) != MaxNodes {
+		t.Errorf("got %d nodes, truncated %v", len(g.Nodes), g.Truncated)
+	}
+}
+
+func TestTraceErrors(t *testing.T) {
+	s := testTxs()
+	tests := []struct {
+		req  *Request
+		want error
+	}{
+		{&Request{TxHash: "a", Direction: "sideways", Hops: 1}, ErrInvalidTrace},
+		{&Request{TxHash: "a", Direction: Forward, Hops: MaxHops + 1}, ErrInvalidTrace},
+		{&Request{TxHash: "a", Vout: 9, Direction: Forward, Hops: 1}, ErrInvalidTrace},
+		{&Request{TxHash: "z", Direction: Forward, Hops: 1}, ErrNotFound},
+	}
+	for _, test := range tests {
+		if _, err := Trace(s, test.req); !errors.Is(err, test.want) {
+			t.Errorf("%+v: got %v, want %v", test.req, err, test.want)
+		}
+	}
+}
+
+func TestParseRequest(t *testing.T) {
+	const txid = "4d6d22cb3ec4f5b1d9b4b2e5bb3a0b4f35b2a7ef1a3b0f3c1e5d7a9b8c6e4f20"
+	req, err := ParseRequest("btc", url.Values{"txid": {txid}, "vout": {"2"}, "min_value": {"1000"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Request{Chain: "btc", TxHash: txid, Vout: 2, Direction: Forward, Hops: DefaultHops, MinValue: 1000}
+	if !reflect.DeepEqual(req, want) {
+		t.Errorf("got %+v, want %+v", req, want)
+	}
+	for _, query := range []url.Values{
+		{"txid": {"abc"}},
+		{"txid": {txid}, "vout": {"-1"}},
+		{"txid": {txid}, "hops": {"x"}},
+	} {
+		if _, err := ParseRequest("dcr", query); !errors.Is(err, ErrInvalidTrace) {
+			t.Errorf("%v: got %v, want ErrInvalidTrace", query, err)
+		}
+	}
+	if _, err := ParseRequest("xmr", url.Values{"txid": {txid}}); !errors.Is(err, ErrInvalidTrace) {
+		t.Errorf("xmr: got %v, want ErrInvalidTrace", err)
+	}
+}
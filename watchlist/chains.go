@@ -0,0 +1,231 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package watchlist
+
+import (
+	"github.com/btcsuite/btcd/btcjson"
+	btctxscript "github.com/btcsuite/btcd/txscript"
+	btcwire "github.com/btcsuite/btcd/wire"
+	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v4"
+	"github.com/decred/dcrd/txscript/v4/stdscript"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrdata/v8/blockdata"
+	"github.com/decred/dcrdata/v8/blockdata/blockdatabtc"
+	"github.com/decred/dcrdata/v8/blockdata/blockdataltc"
+	"github.com/decred/dcrdata/v8/mutilchain"
+	"github.com/decred/dcrdata/v8/xmr/xmrutil"
+	ltcjson "github.com/ltcsuite/ltcd/btcjson"
+	ltctxscript "github.com/ltcsuite/ltcd/txscript"
+	ltcwire "github.com/ltcsuite/ltcd/wire"
+)
+
+// Store queues the confirmed events of a new Decred block and the
+// confirmations events it completes. It satisfies blockdata.BlockDataSaver.
+func (wl *Watchlist) Store(bd *blockdata.BlockData, msgBlock *wire.MsgBlock) error {
+	if !wl.watching(mutilchain.TYPEDCR) {
+		return nil
+	}
+	height, hash := int64(bd.Header.Height), bd.Header.Hash
+	for _, txs := range [][]*wire.MsgTx{msgBlock.Transactions, msgBlock.STransactions} {
+		for _, tx := range txs {
+			outs := make([]output, 0, len(tx.TxOut))
+			for _, txOut := range tx.TxOut {
+				_, addrs := stdscript.ExtractAddrs(txOut.Version, txOut.PkScript, wl.dcrParams)
+				for _, addr := range addrs {
+					outs = append(outs, output{addr.String(), txOut.Value})
+				}
+			}
+			if amounts := wl.match(mutilchain.TYPEDCR, outs); len(amounts) > 0 {
+				wl.queue(EventConfirmed, tx.TxHash().String(), height, hash, amounts)
+			}
+		}
+	}
+	wl.confirm(mutilchain.TYPEDCR, height, hash)
+	return nil
+}
+
+// TxHandler queues the mempool events of a new Decred transaction. It is a
+// notification.TxHandler.
+func (wl *Watchlist) TxHandler(rawTx *chainjson.TxRawResult) error {
+	outs := make([]output, 0, len(rawTx.Vout))
+	for _, vout := range rawTx.Vout {
+		for _, addr := range vout.ScriptPubKey.Addresses {
+			outs = append(outs, output{addr, toAtoms(vout.Value)})
+		}
+	}
+	if amounts := wl.match(mutilchain.TYPEDCR, outs); len(amounts) > 0 {
+		wl.queue(EventMempool, rawTx.Txid, -1, "", amounts)
+	}
+	return nil
+}
+
+// BTCStore queues the confirmed events of a new Bitcoin block and the
+// confirmations events it completes. It satisfies
+// blockdatabtc.BlockDataSaver.
+func (wl *Watchlist) BTCStore(bd *blockdatabtc.BlockData, msgBlock *btcwire.MsgBlock) error {
+	if !wl.watching(mutilchain.TYPEBTC) {
+		return nil
+	}
+	height, hash := int64(bd.Header.Height), bd.Header.Hash
+	for _, tx := range msgBlock.Transactions {
+		outs := make([]output, 0, len(tx.TxOut))
+		for _, txOut := range tx.TxOut {
+			_, addrs, _, err := btctxscript.ExtractPkScriptAddrs(txOut.PkScript, wl.btcParams)
+			if err != nil {
+				continue
+			}
+			for _, addr := range addrs {
+				outs = append(outs, output{addr.EncodeAddress(), txOut.Value})
+			}
+		}
+		if amounts := wl.match(mutilchain.TYPEBTC, outs); len(amounts) > 0 {
+			wl.queue(EventConfirmed, tx.TxHash().String(), height, hash, amounts)
+		}
+	}
+	wl.confirm(mutilchain.TYPEBTC, height, hash)
+	return nil
+}
+
+// BTCTxHandler queues the mempool events of a new Bitcoin transaction. It is
+// a notification.BtcTxHandler.
+func (wl *Watchlist) BTCTxHandler(rawTx *btcjson.TxRawResult) error {
+	outs := make([]output, 0, len(rawTx.Vout))
+	for _, vout := range rawTx.Vout {
+		for _, addr := range scriptAddresses(vout.ScriptPubKey.Address, vout.ScriptPubKey.Addresses) {
+			outs = append(outs, output{addr, toAtoms(vout.Value)})
+		}
+	}
+	if amounts := wl.match(mutilchain.TYPEBTC, outs); len(amounts) > 0 {
+		wl.queue(EventMempool, rawTx.Txid, -1, "", amounts)
+	}
+	return nil
+}
+
+// LTCStore queues the confirmed events of a new Litecoin block and the
+// confirmations events it completes. It satisfies
+// blockdataltc.BlockDataSaver.
+func (wl *Watchlist) LTCStore(bd *blockdataltc.BlockData, msgBlock *ltcwire.MsgBlock) error {
+	if !wl.watching(mutilchain.TYPELTC) {
+		return nil
+	}
+	height, hash := int64(bd.Header.Height), bd.Header.Hash
+	for _, tx := range msgBlock.Transactions {
+		outs := make([]output, 0, len(tx.TxOut))
+		for _, txOut := range tx.TxOut {
+			_, addrs, _, err := ltctxscript.ExtractPkScriptAddrs(txOut.PkScript, wl.ltcParams)
+			if err != nil {
+				continue
+			}
+			for _, addr := range addrs {
+				outs = append(outs, output{addr.EncodeAddress(), txOut.Value})
+			}
+		}
+		if amounts := wl.match(mutilchain.TYPELTC, outs); len(amounts) > 0 {
+			wl.queue(EventConfirmed, tx.TxHash().String(), height, hash, amounts)
+		}
+	}
+	wl.confirm(mutilchain.TYPELTC, height, hash)
+	return nil
+}
+
+// LTCTxHandler queues the mempool events of a new Litecoin transaction. It
+// is a notification.LtcTxHandler.
+func (wl *Watchlist) LTCTxHandler(rawTx *ltcjson.TxRawResult) error {
+	outs := make([]output, 0, len(rawTx.Vout))
+	for _, vout := range rawTx.Vout {
+		for _, addr := range scriptAddresses(vout.ScriptPubKey.Address, vout.ScriptPubKey.Addresses) {
+			outs = append(outs, output{addr, toAtoms(vout.Value)})
+		}
+	}
+	if amounts := wl.match(mutilchain.TYPELTC, outs); len(amounts) > 0 {
+		wl.queue(EventMempool, rawTx.Txid, -1, "", amounts)
+	}
+	return nil
+}
+
+// scriptAddresses returns the addresses of a decoded output script, reported
+// in the address field by current nodes and in the addresses field by older
+// ones.
+func scriptAddresses(address string, addresses []string) []string {
+	if address != "" {
+		return []string{address}
+	}
+	return addresses
+}
+
+// scanXMR returns the piconero received by each view key watch in a Monero
+// transaction.
+func (wl *Watchlist) scanXMR(txHash, txJSON string) map[int64]int64 {
+	wl.mtx.RLock()
+	defer wl.mtx.RUnlock()
+	var amounts map[int64]int64
+	for watchID, scanner := range wl.scanners {
+		received, err := scanner.Scan(txJSON)
+		if err != nil {
+			log.Warnf("XMR: scan tx %s for watch %d: %v", txHash, watchID, err)
+			continue
+		}
+		for _, out := range received {
+			if amounts == nil {
+				amounts = make(map[int64]int64)
+			}
+			amounts[watchID] += int64(out.Amount)
+		}
+	}
+	return amounts
+}
+
+// XMRStore queues the confirmed events of a new Monero block and the
+// confirmations events it completes. The block transactions are fetched and
+// scanned in the background. It satisfies blockdataxmr.BlockDataSaver.
+func (wl *Watchlist) XMRStore(bd *xmrutil.BlockData) error {
+	if !wl.watching(mutilchain.TYPEXMR) {
+		return nil
+	}
+	height, hash := int64(bd.Header.Height), bd.Header.Hash
+	txHashes := bd.TxHashes
+	go func() {
+		if len(txHashes) > 0 {
+			txsJSON, err := wl.xmrTxsJSON(txHashes)
+			if err != nil {
+				log.Errorf("XMR: failed to get the transactions of block %d: %v", height, err)
+				return
+			}
+			for i, txJSON := range txsJSON {
+				if i >= len(txHashes) {
+					break
+				}
+				if amounts := wl.scanXMR(txHashes[i], txJSON); len(amounts) > 0 {
+					wl.queue(EventConfirmed, txHashes[i], height, hash, amounts)
+				}
+			}
+		}
+		wl.confirm(mutilchain.TYPEXMR, height, hash)
+	}()
+	return nil
+}
+
+// StoreXMRMempool queues the mempool events of the Monero transactions that
+// are new since the previous mempool poll. The first poll only records the
+// mempool. It satisfies explorer.XMRMempoolSaver.
+func (wl *Watchlist) StoreXMRMempool(mp *xmrutil.Mempool) {
+	wl.xmrMempoolMtx.Lock()
+	prev := wl.xmrMempoolTxs
+	wl.xmrMempoolTxs = make(map[string]struct{}, len(mp.Transactions))
+	for _, tx := range mp.Transactions {
+		wl.xmrMempoolTxs[tx.IDHash] = struct{}{}
+	}
+	wl.xmrMempoolMtx.Unlock()
+	if prev == nil || !wl.watching(mutilchain.TYPEXMR) {
+		return
+	}
+	for _, tx := range mp.Transactions {
+		if _, found := prev[tx.IDHash]; found {
+			continue
+		}
+		if amounts := wl.scanXMR(tx.IDHash, tx.TxJSON); len(amounts) > 0 {
+			wl.queue(EventMempool, tx.IDHash, -1, "", amounts)
+		}
+	}
+}
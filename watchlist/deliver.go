@@ -0,0 +1,257 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package watchlist
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+)
+
+// The headers of a webhook delivery.
+const (
+	// SignatureHeader is "sha256=" followed by the hex-encoded HMAC-SHA256,
+	// keyed by the watch secret, of the timestamp header, a period and the
+	// request body. See Verify.
+	SignatureHeader = "X-Dcrdata-Signature"
+	// TimestampHeader is the UNIX time of the delivery attempt. Receivers
+	// should reject stale timestamps to prevent replays.
+	TimestampHeader = "X-Dcrdata-Timestamp"
+	EventHeader     = "X-Dcrdata-Event"
+	DeliveryHeader  = "X-Dcrdata-Delivery"
+)
+
+const (
+	pollInterval = 5 * time.Second
+	batchSize    = 100
+	maxAttempts  = 10
+	retryBase    = 30 * time.Second
+	retryMax     = time.Hour
+	// maxConcurrentClients bounds the clients whose deliveries are attempted
+	// at once.
+	maxConcurrentClients = 8
+)
+
+// Payload is the JSON body of a webhook delivery. Amounts are in atoms, or
+// piconero for Monero.
+type Payload struct {
+	DeliveryID    int64  `json:"delivery_id"`
+	WatchID       int64  `json:"watch_id"`
+	Event         string `json:"event"`
+	Chain         string `json:"chain"`
+	Address       string `json:"address"`
+	TxID          string `json:"txid"`
+	Amount        int64  `json:"amount"`
+	Height        int64  `json:"height"`
+	BlockHash     string `json:"block_hash,omitempty"`
+	Confirmations int    `json:"confirmations"`
+	Created       int64  `json:"created"`
+}
+
+// Sign returns the signature header of a delivery body sent at a UNIX
+// timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature header of a delivery body sent at a UNIX
+// timestamp, for use by webhook receivers written in Go.
+func Verify(secret, signature string, timestamp int64, body []byte) bool {
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body)))
+}
+
+// retryDelay is the delay after a failed attempt, doubling from retryBase up
+// to retryMax.
+func retryDelay(attempts int) time.Duration {
+	delay := retryBase
+	for i := 1; i < attempts && delay < retryMax; i++ {
+		delay *= 2
+	}
+	if delay > retryMax {
+		delay = retryMax
+	}
+	return delay
+}
+
+// Run delivers the queued events until the context is canceled. Deliveries
+// are attempted when queued and retried when due.
+func (wl *Watchlist) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		wl.deliverDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-wl.wake:
+		}
+	}
+}
+
+// deliverDue attempts the due deliveries, in batches until none is left.
+func (wl *Watchlist) deliverDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		due, err := wl.store.DueWatchDeliveries(time.Now().UTC(), batchSize)
+		if err != nil {
+			log.Errorf("Failed to retrieve due deliveries: %v", err)
+			return
+		}
+		wl.deliverBatch(ctx, due)
+		if len(due) < batchSize {
+			return
+		}
+	}
+}
+
+// deliverBatch attempts a batch of deliveries. The deliveries of a client are
+// attempted in order, and those of up to maxConcurrentClients clients at
+// once, so that the slow or unreachable callbacks of one client do not delay
+// the deliveries of the others.
+func (wl *Watchlist) deliverBatch(ctx context.Context, due []*dbtypes.WatchDelivery) {
+	var clients []string
+	byClient := make(map[string][]*dbtypes.WatchDelivery)
+	for _, d := range due {
+		w := wl.watch(d.WatchID)
+		if w == nil {
+			// Removed since the query. Its deliveries are gone too.
+			continue
+		}
+		client := w.Client
+		if client == "" {
+			client = "watch " + strconv.FormatInt(w.ID, 10)
+		}
+		if byClient[client] == nil {
+			clients = append(clients, client)
+		}
+		byClient[client] = append(byClient[client], d)
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentClients)
+	for _, client := range clients {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(deliveries []*dbtypes.WatchDelivery) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			// After a failure, the other deliveries of the watch are retried
+			// with it instead of waiting for its callback again.
+			retries := make(map[int64]time.Time)
+			for _, d := range deliveries {
+				if ctx.Err() != nil {
+					return
+				}
+				if next, ok := retries[d.WatchID]; ok {
+					wl.postpone(d, next)
+					continue
+				}
+				if d = wl.deliver(ctx, d); d != nil && d.Status == StatusPending {
+					retries[d.WatchID] = d.NextAttempt
+				}
+			}
+		}(byClient[client])
+	}
+	wg.Wait()
+}
+
+// postpone reschedules a delivery without attempting it.
+func (wl *Watchlist) postpone(d *dbtypes.WatchDelivery, next time.Time) {
+	d.NextAttempt = next
+	if err := wl.store.UpdateWatchDelivery(d); err != nil {
+		log.Errorf("Failed to update delivery %d: %v", d.ID, err)
+	}
+}
+
+// deliver makes a delivery attempt and stores its outcome, which it returns.
+// It returns nil for the delivery of a removed watch.
+func (wl *Watchlist) deliver(ctx context.Context, d *dbtypes.WatchDelivery) *dbtypes.WatchDelivery {
+	w := wl.watch(d.WatchID)
+	if w == nil {
+		// Removed since the query. Its deliveries are gone too.
+		return nil
+	}
+	now := time.Now().UTC()
+	code, err := wl.post(ctx, w, d, now)
+	d.Attempts++
+	d.ResponseCode = code
+	switch {
+	case err == nil:
+		d.Status = StatusDelivered
+		d.Delivered = &now
+		d.LastError = ""
+	case d.Attempts >= maxAttempts:
+		d.Status = StatusFailed
+		d.LastError = err.Error()
+		log.Warnf("Giving up %s delivery %d to watch %d after %d attempts: %v",
+			d.Event, d.ID, w.ID, d.Attempts, err)
+	default:
+		d.NextAttempt = now.Add(retryDelay(d.Attempts))
+		d.LastError = err.Error()
+		log.Debugf("Delivery %d to watch %d failed, retrying at %v: %v",
+			d.ID, w.ID, d.NextAttempt, err)
+	}
+	if err = wl.store.UpdateWatchDelivery(d); err != nil {
+		log.Errorf("Failed to update delivery %d: %v", d.ID, err)
+	}
+	return d
+}
+
+// post sends a signed delivery to the callback URL of its watch. Any 2xx
+// response is a success.
+func (wl *Watchlist) post(ctx context.Context, w *dbtypes.Watch, d *dbtypes.WatchDelivery, now time.Time) (int, error) {
+	body, err := json.Marshal(&Payload{
+		DeliveryID:    d.ID,
+		WatchID:       w.ID,
+		Event:         d.Event,
+		Chain:         w.Chain,
+		Address:       w.Address,
+		TxID:          d.TxID,
+		Amount:        d.Amount,
+		Height:        d.Height,
+		BlockHash:     d.BlockHash,
+		Confirmations: d.Confirmations,
+		Created:       d.Created.Unix(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(w.Secret, timestamp, body))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(EventHeader, d.Event)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(d.ID, 10))
+	resp, err := wl.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("callback responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,500 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+// Package watchlist notifies registered callback URLs of the funds received
+// by watched addresses, or by a Monero view key, on every chain. A watch is
+// evaluated when a transaction enters the mempool, when it is first mined and
+// when it reaches the confirmations of the watch. Events are queued in the
+// database and delivered as HMAC-signed webhooks, retried with backoff.
+package watchlist
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	btcchaincfg "github.com/btcsuite/btcd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/txscript/v4/stdaddr"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/mutilchain"
+	"github.com/decred/dcrdata/v8/xmr/xmrutil"
+	ltcchaincfg "github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/ltcutil"
+)
+
+// Watch events, in the order they occur for a transaction.
+const (
+	EventMempool       = "mempool"
+	EventConfirmed     = "confirmed"
+	EventConfirmations = "confirmations"
+)
+
+// Delivery statuses.
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+const (
+	// DefaultConfirmations is the confirmations of a watch registered
+	// without them.
+	DefaultConfirmations = 6
+	maxConfirmations     = 1000
+	// maxWatches bounds the watches of a dcrdata instance, as every watch is
+	// checked against every new transaction of its chain.
+	maxWatches = 10000
+	// maxClientWatches bounds the watches registered by one API client, so
+	// that a client cannot fill the watch-list.
+	maxClientWatches = 100
+)
+
+var (
+	// ErrInvalidWatch is wrapped by the errors of Add for a watch that cannot
+	// be registered.
+	ErrInvalidWatch = errors.New("invalid watch")
+	// ErrNotFound is returned for an unknown watch ID.
+	ErrNotFound = errors.New("watch not found")
+	// ErrUnauthorized is returned by Authorize for a wrong secret.
+	ErrUnauthorized = errors.New("invalid watch secret")
+)
+
+// Store is the persistent storage of the watches and their deliveries,
+// implemented by dcrpg.ChainDB.
+type Store interface {
+	CreateWatch(w *dbtypes.Watch) (int64, error)
+	Watches() ([]*dbtypes.Watch, error)
+	DeleteWatch(id int64) error
+	InsertWatchDelivery(d *dbtypes.WatchDelivery) (bool, error)
+	InsertWatchConfirmations(chain string, tip int64, tipHash string, now time.Time) (int64, error)
+	DueWatchDeliveries(now time.Time, limit int) ([]*dbtypes.WatchDelivery, error)
+	WatchDeliveries(watchID int64, limit int) ([]*dbtypes.WatchDelivery, error)
+	UpdateWatchDelivery(d *dbtypes.WatchDelivery) error
+}
+
+// XMRTxsJSON returns the JSON of Monero transactions, in the order of their
+// hashes, to scan the transactions of new blocks with the watched view keys.
+type XMRTxsJSON func(txHashes []string) ([]string, error)
+
+// Config is the configuration of a Watchlist. Only the chains with their
+// network parameters set, or XMRTxsJSON for Monero, accept watches.
+type Config struct {
+	Store      Store
+	DCRParams  *chaincfg.Params
+	BTCParams  *btcchaincfg.Params
+	LTCParams  *ltcchaincfg.Params
+	XMRTxsJSON XMRTxsJSON
+	// HTTPClient delivers the webhooks. The default client times out after
+	// 10 seconds and refuses to connect to private addresses, unless they are
+	// allowed.
+	HTTPClient *http.Client
+	// AllowPrivateCallbacks allows callback URLs on private, loopback and
+	// link-local addresses, for a webhook receiver on the local network.
+	AllowPrivateCallbacks bool
+}
+
+// Watchlist matches new transactions against the registered watches and
+// delivers the resulting events.
+type Watchlist struct {
+	store      Store
+	dcrParams  *chaincfg.Params
+	btcParams  *btcchaincfg.Params
+	ltcParams  *ltcchaincfg.Params
+	xmrTxsJSON XMRTxsJSON
+	httpClient *http.Client
+	allowLocal bool
+	wake       chan struct{}
+
+	mtx      sync.RWMutex
+	watches  map[int64]*dbtypes.Watch
+	byAddr   map[string]map[string][]*dbtypes.Watch // chain -> address -> watches
+	scanners map[int64]*xmrutil.ViewKeyScanner      // watch ID -> scanner
+
+	xmrMempoolMtx sync.Mutex
+	xmrMempoolTxs map[string]struct{}
+}
+
+// New creates a Watchlist with the watches in the store.
+func New(cfg *Config) (*Watchlist, error) {
+	wl := &Watchlist{
+		store:      cfg.Store,
+		dcrParams:  cfg.DCRParams,
+		btcParams:  cfg.BTCParams,
+		ltcParams:  cfg.LTCParams,
+		xmrTxsJSON: cfg.XMRTxsJSON,
+		httpClient: cfg.HTTPClient,
+		allowLocal: cfg.AllowPrivateCallbacks,
+		wake:       make(chan struct{}, 1),
+		watches:    make(map[int64]*dbtypes.Watch),
+		byAddr:     make(map[string]map[string][]*dbtypes.Watch),
+		scanners:   make(map[int64]*xmrutil.ViewKeyScanner),
+	}
+	if wl.httpClient == nil {
+		dialer := &net.Dialer{Timeout: 10 * time.Second, Control: wl.dialControl}
+		wl.httpClient = &http.Client{
+			Timeout: 10 * time.Second,
+			// No proxy, so that the dialed address is the one of the callback.
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				ForceAttemptHTTP2:   true,
+				TLSHandshakeTimeout: 10 * time.Second,
+			},
+		}
+	}
+	watches, err := wl.store.Watches()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load watches: %w", err)
+	}
+	for _, w := range watches {
+		if err = wl.index(w); err != nil {
+			log.Warnf("Watch %d not loaded: %v", w.ID, err)
+		}
+	}
+	log.Infof("Loaded %d watches", len(wl.watches))
+	return wl, nil
+}
+
+// index adds a watch to the in-memory lookups. The lock must be held.
+func (wl *Watchlist) index(w *dbtypes.Watch) error {
+	if w.Chain == mutilchain.TYPEXMR {
+		scanner, err := xmrutil.NewViewKeyScanner(w.Address, w.ViewKey)
+		if err != nil {
+			return err
+		}
+		wl.scanners[w.ID] = scanner
+	} else {
+		addrs := wl.byAddr[w.Chain]
+		if addrs == nil {
+			addrs = make(map[string][]*dbtypes.Watch)
+			wl.byAddr[w.Chain] = addrs
+		}
+		addrs[w.Address] = append(addrs[w.Address], w)
+	}
+	wl.watches[w.ID] = w
+	return nil
+}
+
+// unindex removes a watch from the in-memory lookups. The lock must be held.
+func (wl *Watchlist) unindex(w *dbtypes.Watch) {
+	delete(wl.watches, w.ID)
+	delete(wl.scanners, w.ID)
+	addrs := wl.byAddr[w.Chain]
+	watches := addrs[w.Address]
+	for i := range watches {
+		if watches[i].ID == w.ID {
+			watches = append(watches[:i:i], watches[i+1:]...)
+			break
+		}
+	}
+	if len(watches) == 0 {
+		delete(addrs, w.Address)
+	} else {
+		addrs[w.Address] = watches
+	}
+}
+
+// validateAddress checks that an address is valid on an enabled chain.
+func (wl *Watchlist) validateAddress(chain, address, viewKey string) error {
+	var err error
+	switch chain {
+	case mutilchain.TYPEDCR:
+		if wl.dcrParams == nil {
+			return fmt.Errorf("%w: chain %s is disabled", ErrInvalidWatch, chain)
+		}
+		_, err = stdaddr.DecodeAddress(address, wl.dcrParams)
+	case mutilchain.TYPEBTC:
+		if wl.btcParams == nil {
+			return fmt.Errorf("%w: chain %s is disabled", ErrInvalidWatch, chain)
+		}
+		_, err = btcutil.DecodeAddress(address, wl.btcParams)
+	case mutilchain.TYPELTC:
+		if wl.ltcParams == nil {
+			return fmt.Errorf("%w: chain %s is disabled", ErrInvalidWatch, chain)
+		}
+		_, err = ltcutil.DecodeAddress(address, wl.ltcParams)
+	case mutilchain.TYPEXMR:
+		if wl.xmrTxsJSON == nil {
+			return fmt.Errorf("%w: chain %s is disabled", ErrInvalidWatch, chain)
+		}
+		if viewKey == "" {
+			return fmt.Errorf("%w: a Monero watch requires the view key of the address", ErrInvalidWatch)
+		}
+		_, err = xmrutil.NewViewKeyScanner(address, viewKey)
+	default:
+		return fmt.Errorf("%w: unknown chain %q", ErrInvalidWatch, chain)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWatch, err)
+	}
+	if chain != mutilchain.TYPEXMR && viewKey != "" {
+		return fmt.Errorf("%w: view keys are only used for Monero", ErrInvalidWatch)
+	}
+	return nil
+}
+
+// privateIP reports whether an IP address is private, loopback, link-local or
+// otherwise not on the public internet.
+func privateIP(ip net.IP) bool {
+	return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || ip.IsUnspecified()
+}
+
+// validateCallback checks that a callback URL is an absolute http or https URL
+// whose host is not a private address, unless they are allowed. Host names are
+// resolved when a delivery connects, by dialControl.
+func (wl *Watchlist) validateCallback(callbackURL string) error {
+	u, err := url.Parse(callbackURL)
+	if err != nil {
+		return fmt.Errorf("%w: callback URL: %v", ErrInvalidWatch, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: callback URL must be an absolute http or https URL", ErrInvalidWatch)
+	}
+	if wl.allowLocal {
+		return nil
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: callback URL must not be a local address", ErrInvalidWatch)
+	}
+	if ip := net.ParseIP(host); ip != nil && privateIP(ip) {
+		return fmt.Errorf("%w: callback URL must not be a private address", ErrInvalidWatch)
+	}
+	return nil
+}
+
+// dialControl refuses the connections of the deliveries to private addresses,
+// unless they are allowed. It checks the resolved address of every dial, so a
+// host name or a redirect cannot reach the local network.
+func (wl *Watchlist) dialControl(_, address string, _ syscall.RawConn) error {
+	if wl.allowLocal {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || privateIP(ip) {
+		return fmt.Errorf("callback address %s is not public", host)
+	}
+	return nil
+}
+
+// Add registers a watch of the chain, address, view key, callback URL and
+// confirmations of w, for the API client of w. The returned watch has its ID
+// and the generated secret that signs its deliveries and authorizes its
+// management, which is not retrievable later.
+func (wl *Watchlist) Add(w *dbtypes.Watch) (*dbtypes.Watch, error) {
+	if err := wl.validateAddress(w.Chain, w.Address, w.ViewKey); err != nil {
+		return nil, err
+	}
+	if err := wl.validateCallback(w.CallbackURL); err != nil {
+		return nil, err
+	}
+	confirmations := w.Confirmations
+	if confirmations == 0 {
+		confirmations = DefaultConfirmations
+	}
+	if confirmations < 1 || confirmations > maxConfirmations {
+		return nil, fmt.Errorf("%w: confirmations must be between 1 and %d",
+			ErrInvalidWatch, maxConfirmations)
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	watch := &dbtypes.Watch{
+		Chain:         w.Chain,
+		Address:       w.Address,
+		ViewKey:       w.ViewKey,
+		CallbackURL:   w.CallbackURL,
+		Client:        w.Client,
+		Secret:        hex.EncodeToString(secret),
+		Confirmations: confirmations,
+		Created:       time.Now().UTC(),
+	}
+
+	wl.mtx.Lock()
+	defer wl.mtx.Unlock()
+	if len(wl.watches) >= maxWatches {
+		return nil, fmt.Errorf("%w: the watch-list is full", ErrInvalidWatch)
+	}
+	if wl.clientWatches(w.Client) >= maxClientWatches {
+		return nil, fmt.Errorf("%w: at most %d watches per client", ErrInvalidWatch, maxClientWatches)
+	}
+	id, err := wl.store.CreateWatch(watch)
+	if err != nil {
+		return nil, err
+	}
+	watch.ID = id
+	if err = wl.index(watch); err != nil {
+		return nil, err
+	}
+	log.Debugf("Added watch %d of %s address %s", id, watch.Chain, watch.Address)
+	return watch, nil
+}
+
+// Authorize returns the watch with the ID if the secret is the one generated
+// for it.
+func (wl *Watchlist) Authorize(id int64, secret string) (*dbtypes.Watch, error) {
+	wl.mtx.RLock()
+	w := wl.watches[id]
+	wl.mtx.RUnlock()
+	if w == nil {
+		return nil, ErrNotFound
+	}
+	if subtle.ConstantTimeCompare([]byte(w.Secret), []byte(secret)) != 1 {
+		return nil, ErrUnauthorized
+	}
+	return w, nil
+}
+
+// Remove deletes a watch and its delivery log.
+func (wl *Watchlist) Remove(id int64) error {
+	wl.mtx.Lock()
+	defer wl.mtx.Unlock()
+	w := wl.watches[id]
+	if w == nil {
+		return ErrNotFound
+	}
+	if err := wl.store.DeleteWatch(id); err != nil {
+		return err
+	}
+	wl.unindex(w)
+	log.Debugf("Removed watch %d", id)
+	return nil
+}
+
+// Deliveries returns up to limit deliveries of a watch, the latest first.
+func (wl *Watchlist) Deliveries(id int64, limit int) ([]*dbtypes.WatchDelivery, error) {
+	if wl.watch(id) == nil {
+		return nil, ErrNotFound
+	}
+	return wl.store.WatchDeliveries(id, limit)
+}
+
+// clientWatches counts the watches of an API client. The lock must be held.
+func (wl *Watchlist) clientWatches(client string) int {
+	var n int
+	for _, w := range wl.watches {
+		if w.Client == client {
+			n++
+		}
+	}
+	return n
+}
+
+func (wl *Watchlist) watch(id int64) *dbtypes.Watch {
+	wl.mtx.RLock()
+	defer wl.mtx.RUnlock()
+	return wl.watches[id]
+}
+
+// watching reports whether any watch of the chain exists.
+func (wl *Watchlist) watching(chain string) bool {
+	wl.mtx.RLock()
+	defer wl.mtx.RUnlock()
+	if chain == mutilchain.TYPEXMR {
+		return len(wl.scanners) > 0
+	}
+	return len(wl.byAddr[chain]) > 0
+}
+
+// output is an amount received by an address in a transaction.
+type output struct {
+	address string
+	amount  int64
+}
+
+// match sums the amounts received by the watches of a chain in the outputs
+// of a transaction.
+func (wl *Watchlist) match(chain string, outs []output) map[int64]int64 {
+	wl.mtx.RLock()
+	defer wl.mtx.RUnlock()
+	addrs := wl.byAddr[chain]
+	if len(addrs) == 0 {
+		return nil
+	}
+	var amounts map[int64]int64
+	for _, out := range outs {
+		for _, w := range addrs[out.address] {
+			if amounts == nil {
+				amounts = make(map[int64]int64)
+			}
+			amounts[w.ID] += out.amount
+		}
+	}
+	return amounts
+}
+
+// queue stores the deliveries of an event of a transaction to the watches it
+// paid, and wakes the delivery worker if any is new.
+func (wl *Watchlist) queue(event, txid string, height int64, blockHash string, amounts map[int64]int64) {
+	now := time.Now().UTC()
+	confirmations := 0
+	if event == EventConfirmed {
+		confirmations = 1
+	}
+	var queued bool
+	for watchID, amount := range amounts {
+		inserted, err := wl.store.InsertWatchDelivery(&dbtypes.WatchDelivery{
+			WatchID:       watchID,
+			Event:         event,
+			TxID:          txid,
+			Height:        height,
+			BlockHash:     blockHash,
+			Amount:        amount,
+			Confirmations: confirmations,
+			Created:       now,
+		})
+		if err != nil {
+			log.Errorf("Failed to queue %s delivery of tx %s to watch %d: %v",
+				event, txid, watchID, err)
+			continue
+		}
+		queued = queued || inserted
+	}
+	if queued {
+		wl.notify()
+	}
+}
+
+// confirm queues the confirmations events of a chain at a new tip block.
+func (wl *Watchlist) confirm(chain string, tip int64, tipHash string) {
+	n, err := wl.store.InsertWatchConfirmations(chain, tip, tipHash, time.Now().UTC())
+	if err != nil {
+		log.Errorf("Failed to queue %s confirmations at height %d: %v", chain, tip, err)
+		return
+	}
+	if n > 0 {
+		wl.notify()
+	}
+}
+
+// notify wakes the delivery worker without blocking.
+func (wl *Watchlist) notify() {
+	select {
+	case wl.wake <- struct{}{}:
+	default:
+	}
+}
+
+// toAtoms converts a coin amount of a chain with 1e8 atoms per coin, as
+// reported by the node RPC, to atoms.
+func toAtoms(amount float64) int64 {
+	return int64(math.Round(amount * 1e8))
+}
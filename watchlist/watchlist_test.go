@@ -0,0 +1,632 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package watchlist
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+	btcchaincfg "github.com/btcsuite/btcd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/v3"
+	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v4"
+	"github.com/decred/dcrd/txscript/v4/stdaddr"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrdata/v8/blockdata"
+	"github.com/decred/dcrdata/v8/db/dbtypes"
+	"github.com/decred/dcrdata/v8/mutilchain"
+)
+
+// memStore is a Store with the semantics of the dcrpg queries.
+type memStore struct {
+	mtx        sync.Mutex
+	watches    map[int64]*dbtypes.Watch
+	deliveries []*dbtypes.WatchDelivery
+	waiting    map[waitKey]*dbtypes.WatchDelivery // the watch_confirmations table
+	lastID     int64
+}
+
+type waitKey struct {
+	watchID int64
+	txid    string
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		watches: make(map[int64]*dbtypes.Watch),
+		waiting: make(map[waitKey]*dbtypes.WatchDelivery),
+	}
+}
+
+func (s *memStore) CreateWatch(w *dbtypes.Watch) (int64, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.lastID++
+	c := *w
+	c.ID = s.lastID
+	s.watches[c.ID] = &c
+	return c.ID, nil
+}
+
+func (s *memStore) Watches() ([]*dbtypes.Watch, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	watches := make([]*dbtypes.Watch, 0, len(s.watches))
+	for _, w := range s.watches {
+		c := *w
+		watches = append(watches, &c)
+	}
+	return watches, nil
+}
+
+func (s *memStore) DeleteWatch(id int64) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.watches, id)
+	kept := s.deliveries[:0]
+	for _, d := range s.deliveries {
+		if d.WatchID != id {
+			kept = append(kept, d)
+		}
+	}
+	s.deliveries = kept
+	for k := range s.waiting {
+		if k.watchID == id {
+			delete(s.waiting, k)
+		}
+	}
+	return nil
+}
+
+func (s *memStore) insert(d *dbtypes.WatchDelivery) bool {
+	for _, e := range s.deliveries {
+		if e.WatchID == d.WatchID && e.Event == d.Event && e.TxID == d.TxID {
+			return false
+		}
+	}
+	s.lastID++
+	c := *d
+	c.ID = s.lastID
+	c.Status = StatusPending
+	c.NextAttempt = c.Created
+	s.deliveries = append(s.deliveries, &c)
+	return true
+}
+
+func (s *memStore) InsertWatchDelivery(d *dbtypes.WatchDelivery) (bool, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if w := s.watches[d.WatchID]; d.Event == EventConfirmed && w != nil && w.Confirmations > 1 {
+		c := *d
+		s.waiting[waitKey{d.WatchID, d.TxID}] = &c
+	}
+	return s.insert(d), nil
+}
+
+func (s *memStore) InsertWatchConfirmations(chain string, tip int64, tipHash string, now time.Time) (int64, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	var n int64
+	for k, d := range s.waiting {
+		w := s.watches[d.WatchID]
+		if w.Chain != chain {
+			continue
+		}
+		if d.Height >= tip && d.BlockHash != tipHash {
+			delete(s.waiting, k) // orphaned
+			continue
+		}
+		if d.Height > tip-int64(w.Confirmations)+1 {
+			continue
+		}
+		delete(s.waiting, k)
+		if s.insert(&dbtypes.WatchDelivery{
+			WatchID:       d.WatchID,
+			Event:         EventConfirmations,
+			TxID:          d.TxID,
+			Height:        d.Height,
+			BlockHash:     d.BlockHash,
+			Amount:        d.Amount,
+			Confirmations: w.Confirmations,
+			Created:       now,
+		}) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *memStore) DueWatchDeliveries(now time.Time, limit int) ([]*dbtypes.WatchDelivery, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	var due []*dbtypes.WatchDelivery
+	for _, d := range s.deliveries {
+		if d.Status == StatusPending && !d.NextAttempt.After(now) && len(due) < limit {
+			c := *d
+			due = append(due, &c)
+		}
+	}
+	return due, nil
+}
+
+func (s *memStore) WatchDeliveries(watchID int64, limit int) ([]*dbtypes.WatchDelivery, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	var deliveries []*dbtypes.WatchDelivery
+	for _, d := range s.deliveries {
+		if d.WatchID == watchID {
+			c := *d
+			deliveries = append(deliveries, &c)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID > deliveries[j].ID })
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+func (s *memStore) UpdateWatchDelivery(d *dbtypes.WatchDelivery) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for i, e := range s.deliveries {
+		if e.ID == d.ID {
+			c := *d
+			s.deliveries[i] = &c
+		}
+	}
+	return nil
+}
+
+// setDue makes all the pending deliveries due.
+func (s *memStore) setDue() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, d := range s.deliveries {
+		d.NextAttempt = time.Time{}
+	}
+}
+
+func newTestWatchlist(t *testing.T, store Store, allowLocal bool) *Watchlist {
+	t.Helper()
+	wl, err := New(&Config{
+		Store:                 store,
+		DCRParams:             chaincfg.SimNetParams(),
+		BTCParams:             &btcchaincfg.RegressionNetParams,
+		AllowPrivateCallbacks: allowLocal,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wl
+}
+
+func dcrAddress(t *testing.T, b byte) stdaddr.Address {
+	t.Helper()
+	hash := make([]byte, 20)
+	hash[0] = b
+	addr, err := stdaddr.NewAddressPubKeyHashEcdsaSecp256k1V0(hash, chaincfg.SimNetParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
+
+func TestAddValidation(t *testing.T) {
+	wl := newTestWatchlist(t, newMemStore(), false)
+	addr := dcrAddress(t, 1).String()
+	tests := []struct {
+		name  string
+		watch dbtypes.Watch
+	}{
+		{"unknown chain", dbtypes.Watch{Chain: "doge", Address: addr, CallbackURL: "https://example.com/hook"}},
+		{"disabled chain", dbtypes.Watch{Chain: mutilchain.TYPELTC, Address: addr, CallbackURL: "https://example.com/hook"}},
+		{"monero without client", dbtypes.Watch{Chain: mutilchain.TYPEXMR, Address: addr, ViewKey: "00", CallbackURL: "https://example.com/hook"}},
+		{"wrong network", dbtypes.Watch{Chain: mutilchain.TYPEBTC, Address: addr, CallbackURL: "https://example.com/hook"}},
+		{"view key", dbtypes.Watch{Chain: mutilchain.TYPEDCR, Address: addr, ViewKey: "00", CallbackURL: "https://example.com/hook"}},
+		{"callback scheme", dbtypes.Watch{Chain: mutilchain.TYPEDCR, Address: addr, CallbackURL: "file:///etc/passwd"}},
+		{"relative callback", dbtypes.Watch{Chain: mutilchain.TYPEDCR, Address: addr, CallbackURL: "/hook"}},
+		{"confirmations", dbtypes.Watch{Chain: mutilchain.TYPEDCR, Address: addr, CallbackURL: "https://example.com/hook", Confirmations: -1}},
+	}
+	for _, tt := range tests {
+		if _, err := wl.Add(&tt.watch); !errors.Is(err, ErrInvalidWatch) {
+			t.Errorf("%s: expected ErrInvalidWatch, got %v", tt.name, err)
+		}
+	}
+
+	w, err := wl.Add(&dbtypes.Watch{Chain: mutilchain.TYPEDCR, Address: addr, CallbackURL: "https://example.com/hook"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Confirmations != DefaultConfirmations || len(w.Secret) != 64 {
+		t.Errorf("unexpected watch %+v", w)
+	}
+	if _, err = wl.Authorize(w.ID, "wrong"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized, got %v", err)
+	}
+	if _, err = wl.Authorize(w.ID, w.Secret); err != nil {
+		t.Errorf("Authorize: %v", err)
+	}
+
+	// A new Watchlist loads the stored watches.
+	wl2 := newTestWatchlist(t, wl.store, false)
+	if _, err = wl2.Authorize(w.ID, w.Secret); err != nil {
+		t.Errorf("Authorize after reload: %v", err)
+	}
+	if err = wl2.Remove(w.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = wl2.Authorize(w.ID, w.Secret); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCallbackDestinations(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	addr := dcrAddress(t, 1).String()
+
+	wl := newTestWatchlist(t, newMemStore(), false)
+	for _, callback := range []string{
+		"http://localhost/",
+		"http://api.localhost./hook",
+		"http://127.0.0.1:8080/",
+		"http://10.1.2.3/",
+		"http://192.168.1.1/",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]/",
+		"http://[fe80::1]/",
+		"http://0.0.0.0/",
+		srv.URL,
+	} {
+		_, err := wl.Add(&dbtypes.Watch{Chain: mutilchain.TYPEDCR, Address: addr, CallbackURL: callback})
+		if !errors.Is(err, ErrInvalidWatch) {
+			t.Errorf("%s: expected ErrInvalidWatch, got %v", callback, err)
+		}
+	}
+	if _, err := wl.Add(&dbtypes.Watch{Chain: mutilchain.TYPEDCR, Address: addr, CallbackURL: "https://203.0.113.7/hook"}); err != nil {
+		t.Errorf("public address: %v", err)
+	}
+	// A host name that resolves to a private address is refused on delivery.
+	if resp, err := wl.httpClient.Get(srv.URL); err == nil {
+		resp.Body.Close()
+		t.Error("delivery connected to a loopback address")
+	}
+
+	wl = newTestWatchlist(t, newMemStore(), true)
+	if _, err := wl.Add(&dbtypes.Watch{Chain: mutilchain.TYPEDCR, Address: addr, CallbackURL: srv.URL}); err != nil {
+		t.Errorf("allowed local callback: %v", err)
+	}
+	resp, err := wl.httpClient.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("allowed local delivery: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestClientWatchLimit(t *testing.T) {
+	wl := newTestWatchlist(t, newMemStore(), false)
+	add := func(client string) error {
+		_, err := wl.Add(&dbtypes.Watch{Chain: mutilchain.TYPEDCR, Address: dcrAddress(t, 1).String(),
+			CallbackURL: "https://example.com/hook", Client: client})
+		return err
+	}
+	for i := 0; i < maxClientWatches; i++ {
+		if err := add("192.0.2.1"); err != nil {
+			t.Fatalf("watch %d: %v", i, err)
+		}
+	}
+	if err := add("192.0.2.1"); !errors.Is(err, ErrInvalidWatch) {
+		t.Errorf("expected ErrInvalidWatch over the limit, got %v", err)
+	}
+	if err := add("192.0.2.2"); err != nil {
+		t.Errorf("another client: %v", err)
+	}
+}
+
+func dcrBlock(height uint32, txs ...*wire.MsgTx) (*blockdata.BlockData, *wire.MsgBlock) {
+	bd := new(blockdata.BlockData)
+	bd.Header.Height = height
+	bd.Header.Hash = "block" + strconv.Itoa(int(height))
+	return bd, &wire.MsgBlock{Transactions: txs}
+}
+
+func TestDCREvents(t *testing.T) {
+	store := newMemStore()
+	wl := newTestWatchlist(t, store, false)
+	addr := dcrAddress(t, 1)
+	w, err := wl.Add(&dbtypes.Watch{Chain: mutilchain.TYPEDCR, Address: addr.String(),
+		CallbackURL: "https://example.com/hook", Confirmations: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, script := addr.PaymentScript()
+	tx := wire.NewMsgTx()
+	tx.AddTxOut(wire.NewTxOut(150000000, script))
+	_, other := dcrAddress(t, 2).PaymentScript()
+	tx.AddTxOut(wire.NewTxOut(5, other))
+	tx.AddTxOut(wire.NewTxOut(50000000, script))
+	txid := tx.TxHash().String()
+
+	err = wl.TxHandler(&chainjson.TxRawResult{
+		Txid: txid,
+		Vout: []chainjson.Vout{
+			{Value: 1.5, ScriptPubKey: chainjson.ScriptPubKeyResult{Addresses: []string{addr.String()}}},
+			{Value: 0.5, ScriptPubKey: chainjson.ScriptPubKeyResult{Addresses: []string{addr.String()}}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for height := uint32(100); height <= 103; height++ {
+		bd, block := dcrBlock(height)
+		if height == 100 {
+			bd, block = dcrBlock(height, tx)
+		}
+		if err = wl.Store(bd, block); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deliveries, _ := store.WatchDeliveries(w.ID, 10)
+	if len(deliveries) != 3 {
+		t.Fatalf("expected 3 deliveries, got %d", len(deliveries))
+	}
+	expected := []struct {
+		event         string
+		height        int64
+		confirmations int
+	}{
+		{EventConfirmations, 100, 3},
+		{EventConfirmed, 100, 1},
+		{EventMempool, -1, 0},
+	}
+	for i, d := range deliveries {
+		e := expected[i]
+		if d.Event != e.event || d.Height != e.height || d.Confirmations != e.confirmations ||
+			d.TxID != txid || d.Amount != 200000000 {
+			t.Errorf("delivery %d: unexpected %+v", i, d)
+		}
+	}
+}
+
+func TestDCRReorg(t *testing.T) {
+	store := newMemStore()
+	wl := newTestWatchlist(t, store, false)
+	addr := dcrAddress(t, 1)
+	w, err := wl.Add(&dbtypes.Watch{Chain: mutilchain.TYPEDCR, Address: addr.String(),
+		CallbackURL: "https://example.com/hook", Confirmations: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, script := addr.PaymentScript()
+	tx := wire.NewMsgTx()
+	tx.AddTxOut(wire.NewTxOut(100000000, script))
+
+	storeBlock := func(height uint32, fork string, txs ...*wire.MsgTx) {
+		t.Helper()
+		bd, block := dcrBlock(height, txs...)
+		bd.Header.Hash += fork
+		if err := wl.Store(bd, block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	events := func() []string {
+		deliveries, _ := store.WatchDeliveries(w.ID, 10)
+		var events []string
+		for _, d := range deliveries {
+			events = append(events, d.Event+"@"+d.BlockHash)
+		}
+		return events
+	}
+
+	storeBlock(100, "", tx)
+	storeBlock(101, "")
+	// The block of the transaction is reorganized out of the chain before it
+	// reaches 3 confirmations.
+	storeBlock(100, "b")
+	storeBlock(101, "b")
+	storeBlock(102, "b")
+	if got := events(); !reflect.DeepEqual(got, []string{"confirmed@block100"}) {
+		t.Fatalf("deliveries after the reorganization %v", got)
+	}
+	// Mined again, it is confirmed in its new block.
+	storeBlock(103, "b", tx)
+	storeBlock(104, "b")
+	storeBlock(105, "b")
+	want := []string{"confirmations@block103b", "confirmed@block100"}
+	if got := events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("deliveries %v, want %v", got, want)
+	}
+}
+
+func TestBTCMempool(t *testing.T) {
+	store := newMemStore()
+	wl := newTestWatchlist(t, store, false)
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(make([]byte, 20), &btcchaincfg.RegressionNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := wl.Add(&dbtypes.Watch{Chain: mutilchain.TYPEBTC, Address: addr.EncodeAddress(),
+		CallbackURL: "https://example.com/hook"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = wl.BTCTxHandler(&btcjson.TxRawResult{
+		Txid: "btctx",
+		Vout: []btcjson.Vout{{Value: 0.00012345, ScriptPubKey: btcjson.ScriptPubKeyResult{Address: addr.EncodeAddress()}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliveries, _ := store.WatchDeliveries(w.ID, 10)
+	if len(deliveries) != 1 || deliveries[0].Amount != 12345 || deliveries[0].Event != EventMempool {
+		t.Fatalf("unexpected deliveries %+v", deliveries)
+	}
+}
+
+func TestDelivery(t *testing.T) {
+	store := newMemStore()
+	wl := newTestWatchlist(t, store, true)
+
+	var mtx sync.Mutex
+	var requests int
+	var secret string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		requests++
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+		if !Verify(secret, r.Header.Get(SignatureHeader), timestamp, body) {
+			t.Errorf("invalid signature of %s", body)
+		}
+		if r.Header.Get(EventHeader) != EventMempool {
+			t.Errorf("unexpected event header %q", r.Header.Get(EventHeader))
+		}
+		if requests == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	addr := dcrAddress(t, 1).String()
+	w, err := wl.Add(&dbtypes.Watch{Chain: mutilchain.TYPEDCR, Address: addr, CallbackURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret = w.Secret
+	wl.queue(EventMempool, "tx", -1, "", map[int64]int64{w.ID: 1})
+
+	ctx := context.Background()
+	wl.deliverDue(ctx)
+	deliveries, _ := wl.Deliveries(w.ID, 10)
+	d := deliveries[0]
+	if d.Status != StatusPending || d.Attempts != 1 || d.ResponseCode != http.StatusInternalServerError ||
+		d.LastError == "" || time.Until(d.NextAttempt) < retryBase-time.Second {
+		t.Fatalf("unexpected delivery after a failure %+v", d)
+	}
+
+	// Not due yet.
+	wl.deliverDue(ctx)
+	mtx.Lock()
+	if requests != 1 {
+		t.Fatalf("expected 1 request, got %d", requests)
+	}
+	mtx.Unlock()
+
+	store.setDue()
+	wl.deliverDue(ctx)
+	deliveries, _ = wl.Deliveries(w.ID, 10)
+	d = deliveries[0]
+	if d.Status != StatusDelivered || d.Attempts != 2 || d.ResponseCode != http.StatusNoContent ||
+		d.LastError != "" || d.Delivered == nil {
+		t.Fatalf("unexpected delivery after a success %+v", d)
+	}
+}
+
+func TestDeliveryClients(t *testing.T) {
+	store := newMemStore()
+	wl := newTestWatchlist(t, store, true)
+
+	// The callback of client a hangs until released, then fails.
+	release := make(chan struct{})
+	var slowRequests int
+	var mtx sync.Mutex
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		slowRequests++
+		mtx.Unlock()
+		<-release
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer slow.Close()
+	delivered := make(chan struct{}, 1)
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+		delivered <- struct{}{}
+	}))
+	defer fast.Close()
+
+	wa, err := wl.Add(&dbtypes.Watch{Chain: mutilchain.TYPEDCR, Address: dcrAddress(t, 1).String(),
+		CallbackURL: slow.URL, Client: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wb, err := wl.Add(&dbtypes.Watch{Chain: mutilchain.TYPEDCR, Address: dcrAddress(t, 2).String(),
+		CallbackURL: fast.URL, Client: "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wl.queue(EventMempool, "tx1", -1, "", map[int64]int64{wa.ID: 1})
+	wl.queue(EventMempool, "tx2", -1, "", map[int64]int64{wa.ID: 1})
+	wl.queue(EventMempool, "tx1", -1, "", map[int64]int64{wb.ID: 1})
+
+	done := make(chan struct{})
+	go func() {
+		wl.deliverDue(context.Background())
+		close(done)
+	}()
+	select {
+	case <-delivered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("delivery to client b waited for client a")
+	}
+	close(release)
+	<-done
+
+	// The second delivery of watch a is retried with the first without
+	// another request.
+	mtx.Lock()
+	if slowRequests != 1 {
+		t.Fatalf("expected 1 request to client a, got %d", slowRequests)
+	}
+	mtx.Unlock()
+	deliveries, _ := wl.Deliveries(wa.ID, 10)
+	if len(deliveries) != 2 {
+		t.Fatalf("expected 2 deliveries, got %d", len(deliveries))
+	}
+	second, first := deliveries[0], deliveries[1]
+	if first.Attempts != 1 || second.Attempts != 0 || second.Status != StatusPending ||
+		!second.NextAttempt.Equal(first.NextAttempt) {
+		t.Fatalf("unexpected deliveries %+v, %+v", first, second)
+	}
+	deliveries, _ = wl.Deliveries(wb.ID, 10)
+	if deliveries[0].Status != StatusDelivered {
+		t.Fatalf("unexpected delivery %+v", deliveries[0])
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		delay    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{4, 4 * time.Minute},
+		{8, time.Hour},
+		{20, time.Hour},
+	}
+	for _, tt := range tests {
+		if delay := retryDelay(tt.attempts); delay != tt.delay {
+			t.Errorf("retryDelay(%d) = %v, expected %v", tt.attempts, delay, tt.delay)
+		}
+	}
+}
@@ -579,3 +579,27 @@ func (c *XMRClient) GetOuts(globalIndices []uint64) (*xmrutil.GetOutsResult, err
 
 	return &res, nil
 }
+
+// GetAmountOuts is GetOuts for the outputs of a pre-RingCT amount, whose
+// indices count only the outputs of that amount.
+func (c *XMRClient) GetAmountOuts(amount uint64, indices []uint64) (*xmrutil.GetOutsResult, error) {
+	outputs := make([]map[string]interface{}, len(indices))
+	for i, index := range indices {
+		outputs[i] = map[string]interface{}{
+			"amount": amount,
+			"index":  index,
+		}
+	}
+	params := map[string]interface{}{
+		"get_txid": true,
+		"outputs":  outputs,
+	}
+	var res xmrutil.GetOutsResult
+	if err := c.postCore("get_outs", params, &res); err != nil {
+		return nil, fmt.Errorf("failed to call get_outs: %w", err)
+	}
+	if res.Status != "OK" {
+		return nil, fmt.Errorf("get_outs returned non-OK status: %s", res.Status)
+	}
+	return &res, nil
+}
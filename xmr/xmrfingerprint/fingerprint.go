@@ -0,0 +1,434 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+// Package xmrfingerprint computes the traits of Monero transactions that hint
+// at the wallet software that built them: the layout of the extra field and
+// payment ID usage, the unlock time, non-standard ring sizes, output counts and
+// fee-per-byte outliers.
+package xmrfingerprint
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Flags of the unusual traits of a transaction.
+const (
+	FlagEncryptedPaymentID   = "encrypted-payment-id"
+	FlagUnencryptedPaymentID = "unencrypted-payment-id"
+	FlagExtraNonce           = "extra-nonce"        // a nonce that is not a payment ID
+	FlagAdditionalPubkeys    = "additional-pubkeys" // subaddress destinations
+	FlagUnsortedExtra        = "unsorted-extra"     // extra fields not in wallet2 order
+	FlagUnknownExtra         = "unknown-extra"
+	FlagUnlockTime           = "unlock-time"
+	FlagNonStandardRing      = "non-standard-ring"
+	FlagSingleOutput         = "single-output"
+	FlagManyOutputs          = "many-outputs" // more than the usual two
+	FlagFeeHigh              = "fee-outlier-high"
+	FlagFeeLow               = "fee-outlier-low"
+)
+
+// Flags are all the flags, in the order they are set.
+var Flags = []string{
+	FlagEncryptedPaymentID, FlagUnencryptedPaymentID, FlagExtraNonce, FlagAdditionalPubkeys,
+	FlagUnsortedExtra, FlagUnknownExtra, FlagUnlockTime, FlagNonStandardRing, FlagSingleOutput,
+	FlagManyOutputs, FlagFeeHigh, FlagFeeLow,
+}
+
+// IsFlag tells whether s is one of the Flags.
+func IsFlag(s string) bool {
+	for _, f := range Flags {
+		if f == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Fee outliers pay more than feeHighFactor times, or less than feeLowFactor
+// times, the median fee per byte of a block with at least minFeeSample
+// transactions.
+const (
+	feeHighFactor = 10
+	feeLowFactor  = 0.5
+	minFeeSample  = 3
+)
+
+// standardRingSizes are the ring sizes that mainnet enforces, or that the
+// wallet uses by default, from the height of each hard fork.
+var standardRingSizes = []struct {
+	height int64
+	size   int
+}{
+	{2688888, 16}, // v15
+	{1546000, 11}, // v8
+	{1400000, 7},  // v7
+	{1220516, 5},  // v6
+}
+
+// StandardRingSize is the standard mainnet ring size at a height, or zero
+// before the ring size was fixed.
+func StandardRingSize(height int64) int {
+	for _, s := range standardRingSizes {
+		if height >= s.height {
+			return s.size
+		}
+	}
+	return 0
+}
+
+// Extra field tags.
+const (
+	tagPadding           = 0x00
+	tagPubKey            = 0x01
+	tagNonce             = 0x02
+	tagMergeMining       = 0x03
+	tagAdditionalPubkeys = 0x04
+	tagMinergate         = 0xde
+)
+
+// extraOrder is the position of each tag in the order that wallet2 sorts the
+// extra fields.
+var extraOrder = map[byte]int{
+	tagPadding:           0, // trails the other fields
+	tagPubKey:            1,
+	tagAdditionalPubkeys: 2,
+	tagNonce:             3,
+	tagMergeMining:       4,
+	tagMinergate:         5,
+}
+
+// Fingerprint is the fingerprint of a transaction.
+type Fingerprint struct {
+	TxHash            string   `json:"tx_hash"`
+	BlockHeight       int64    `json:"block_height"`
+	BlockTime         int64    `json:"block_time,omitempty"`
+	Version           int      `json:"version"`
+	RctType           int      `json:"rct_type"` // -1 without RingCT
+	Inputs            int      `json:"inputs"`
+	Outputs           int      `json:"outputs"`
+	RingSize          int      `json:"ring_size"` // of the first input
+	Size              int      `json:"size"`
+	Fee               int64    `json:"fee"`
+	FeePerByte        float64  `json:"fee_per_byte"`
+	UnlockTime        uint64   `json:"unlock_time"`
+	ExtraSize         int      `json:"extra_size"`
+	ExtraTags         string   `json:"extra_tags"` // hex tags in order, e.g. "01,04,02"
+	PaymentID         string   `json:"payment_id,omitempty"`
+	AdditionalPubkeys int      `json:"additional_pubkeys"`
+	Flags             []string `json:"flags"`
+}
+
+// HasFlag tells whether the fingerprint has a flag.
+func (fp *Fingerprint) HasFlag(flag string) bool {
+	for _, f := range fp.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+func (fp *Fingerprint) addFlag(flag string) {
+	if !fp.HasFlag(flag) {
+		fp.Flags = append(fp.Flags, flag)
+	}
+}
+
+// txJSON is the part of the daemon's JSON of a transaction that is
+// fingerprinted.
+type txJSON struct {
+	Version    int             `json:"version"`
+	UnlockTime uint64          `json:"unlock_time"`
+	Extra      json.RawMessage `json:"extra"`
+	Vin        []struct {
+		Key *struct {
+			Amount     uint64   `json:"amount"`
+			KeyOffsets []uint64 `json:"key_offsets"`
+		} `json:"key"`
+	} `json:"vin"`
+	Vout []struct {
+		Amount uint64 `json:"amount"`
+	} `json:"vout"`
+	RctSignatures *struct {
+		Type   int   `json:"type"`
+		TxnFee int64 `json:"txnFee"`
+	} `json:"rct_signatures"`
+}
+
+// FromTxJSON fingerprints a transaction from the daemon's JSON of it and its
+// size in bytes. The fee outlier flags are set by MarkFeeOutliers.
+func FromTxJSON(txHash string, height int64, size int, txJSONStr string) (*Fingerprint, error) {
+	var tx txJSON
+	if err := json.Unmarshal([]byte(txJSONStr), &tx); err != nil {
+		return nil, fmt.Errorf("unmarshal tx %s json: %w", txHash, err)
+	}
+	fp := &Fingerprint{
+		TxHash:      txHash,
+		BlockHeight: height,
+		Version:     tx.Version,
+		RctType:     -1,
+		Inputs:      len(tx.Vin),
+		Outputs:     len(tx.Vout),
+		Size:        size,
+		UnlockTime:  tx.UnlockTime,
+		Flags:       []string{},
+	}
+
+	var sumIn, sumOut uint64
+	for _, vin := range tx.Vin {
+		if vin.Key == nil {
+			continue
+		}
+		sumIn += vin.Key.Amount
+		if fp.RingSize == 0 {
+			fp.RingSize = len(vin.Key.KeyOffsets)
+		}
+		if std := StandardRingSize(height); std > 0 && len(vin.Key.KeyOffsets) != std {
+			fp.addFlag(FlagNonStandardRing)
+		}
+	}
+	for _, vout := range tx.Vout {
+		sumOut += vout.Amount
+	}
+	if tx.RctSignatures != nil {
+		fp.RctType = tx.RctSignatures.Type
+		fp.Fee = tx.RctSignatures.TxnFee
+	} else if sumIn > sumOut {
+		fp.Fee = int64(sumIn - sumOut)
+	}
+	if size > 0 {
+		fp.FeePerByte = float64(fp.Fee) / float64(size)
+	}
+
+	extra, err := decodeExtra(tx.Extra)
+	if err != nil {
+		return nil, fmt.Errorf("tx %s extra: %w", txHash, err)
+	}
+	fp.ExtraSize = len(extra)
+	fp.fingerprintExtra(extra)
+
+	if fp.UnlockTime != 0 {
+		fp.addFlag(FlagUnlockTime)
+	}
+	switch {
+	case fp.Outputs == 1:
+		fp.addFlag(FlagSingleOutput)
+	case fp.Outputs > 2:
+		fp.addFlag(FlagManyOutputs)
+	}
+	return fp, nil
+}
+
+// decodeExtra decodes the extra field, which the daemon encodes as an array of
+// bytes, or as hex.
+func decodeExtra(raw json.RawMessage) ([]byte, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var ints []int
+	if err := json.Unmarshal(raw, &ints); err == nil {
+		b := make([]byte, len(ints))
+		for i, v := range ints {
+			if v < 0 || v > 255 {
+				return nil, fmt.Errorf("byte %d out of range: %d", i, v)
+			}
+			b[i] = byte(v)
+		}
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(s)
+}
+
+// readVarint reads a little-endian base-128 varint.
+func readVarint(b []byte, i int) (uint64, int, bool) {
+	var v uint64
+	for shift := uint(0); i < len(b) && shift < 64; shift += 7 {
+		c := b[i]
+		i++
+		v |= uint64(c&0x7f) << shift
+		if c&0x80 == 0 {
+			return v, i, true
+		}
+	}
+	return 0, i, false
+}
+
+// fingerprintExtra records the tags of the extra fields and flags payment IDs,
+// nonces, additional public keys, and unknown or unsorted fields. Parsing stops
+// at an unknown or truncated field.
+func (fp *Fingerprint) fingerprintExtra(b []byte) {
+	var tags []string
+	lastOrder := -1
+	unsorted := false
+	for i := 0; i < len(b); {
+		tag := b[i]
+		i++
+		order, known := extraOrder[tag]
+		if !known {
+			tags = append(tags, fmt.Sprintf("%02x", tag))
+			fp.addFlag(FlagUnknownExtra)
+			break
+		}
+		if tag != tagPadding {
+			tags = append(tags, fmt.Sprintf("%02x", tag))
+			if order < lastOrder {
+				unsorted = true
+			}
+			lastOrder = order
+		}
+
+		var ok bool
+		switch tag {
+		case tagPadding:
+			// Padding runs to the end of the field.
+			i, ok = len(b), true
+		case tagPubKey:
+			i += 32
+			ok = i <= len(b)
+		case tagNonce:
+			if i >= len(b) {
+				break
+			}
+			n := int(b[i])
+			i++
+			if i+n > len(b) {
+				break
+			}
+			fp.fingerprintNonce(b[i : i+n])
+			i += n
+			ok = true
+		case tagAdditionalPubkeys:
+			var n uint64
+			n, i, ok = readVarint(b, i)
+			if !ok || n > uint64(len(b)) {
+				ok = false
+				break
+			}
+			fp.AdditionalPubkeys = int(n)
+			fp.addFlag(FlagAdditionalPubkeys)
+			i += 32 * int(n)
+			ok = i <= len(b)
+		case tagMergeMining, tagMinergate:
+			var n uint64
+			n, i, ok = readVarint(b, i)
+			if !ok || n > uint64(len(b)) {
+				ok = false
+				break
+			}
+			i += int(n)
+			ok = i <= len(b)
+		}
+		if !ok {
+			fp.addFlag(FlagUnknownExtra)
+			break
+		}
+	}
+	fp.ExtraTags = strings.Join(tags, ",")
+	if unsorted {
+		fp.addFlag(FlagUnsortedExtra)
+	}
+}
+
+// fingerprintNonce flags the payment ID or other data in an extra nonce.
+func (fp *Fingerprint) fingerprintNonce(nonce []byte) {
+	switch {
+	case len(nonce) == 33 && nonce[0] == 0x00:
+		fp.PaymentID = "unencrypted"
+		fp.addFlag(FlagUnencryptedPaymentID)
+	case len(nonce) == 9 && nonce[0] == 0x01:
+		fp.PaymentID = "encrypted"
+		fp.addFlag(FlagEncryptedPaymentID)
+	default:
+		fp.addFlag(FlagExtraNonce)
+	}
+}
+
+// medianFeePerByte is the median fee per byte of the fingerprints.
+func medianFeePerByte(fps []*Fingerprint) float64 {
+	if len(fps) == 0 {
+		return 0
+	}
+	fees := make([]float64, len(fps))
+	for i, fp := range fps {
+		fees[i] = fp.FeePerByte
+	}
+	sort.Float64s(fees)
+	mid := len(fees) / 2
+	if len(fees)%2 == 0 {
+		return (fees[mid-1] + fees[mid]) / 2
+	}
+	return fees[mid]
+}
+
+// MarkFeeOutliers flags the transactions of a block whose fee per byte is far
+// from the block median. Blocks with too few transactions are not marked.
+func MarkFeeOutliers(fps []*Fingerprint) {
+	if len(fps) < minFeeSample {
+		return
+	}
+	median := medianFeePerByte(fps)
+	if median <= 0 {
+		return
+	}
+	for _, fp := range fps {
+		switch {
+		case fp.FeePerByte > feeHighFactor*median:
+			fp.addFlag(FlagFeeHigh)
+		case fp.FeePerByte < feeLowFactor*median:
+			fp.addFlag(FlagFeeLow)
+		}
+	}
+}
+
+// BlockSummary summarizes the fingerprints of the transactions of a block.
+type BlockSummary struct {
+	Height           int64          `json:"height"`
+	Txs              int            `json:"txs"`
+	MedianFeePerByte float64        `json:"median_fee_per_byte"`
+	FlagCounts       map[string]int `json:"flag_counts"`
+	RingSizes        map[int]int    `json:"ring_sizes"`
+	OutputCounts     map[int]int    `json:"output_counts"`
+	ExtraTags        map[string]int `json:"extra_tags"`
+	Transactions     []*Fingerprint `json:"transactions"`
+}
+
+// Summarize summarizes the fingerprints of the transactions of a block.
+func Summarize(height int64, fps []*Fingerprint) *BlockSummary {
+	s := &BlockSummary{
+		Height:           height,
+		Txs:              len(fps),
+		MedianFeePerByte: medianFeePerByte(fps),
+		FlagCounts:       make(map[string]int),
+		RingSizes:        make(map[int]int),
+		OutputCounts:     make(map[int]int),
+		ExtraTags:        make(map[string]int),
+		Transactions:     fps,
+	}
+	for _, fp := range fps {
+		for _, f := range fp.Flags {
+			s.FlagCounts[f]++
+		}
+		s.RingSizes[fp.RingSize]++
+		s.OutputCounts[fp.Outputs]++
+		s.ExtraTags[fp.ExtraTags]++
+	}
+	return s
+}
+
+// Filter selects stored fingerprints. Only the fingerprints with all the
+// flags, in the inclusive height range, are selected, most recent first.
+type Filter struct {
+	Flags      []string
+	FromHeight int64
+	ToHeight   int64
+	Limit      int
+	Offset     int
+}
@@ -0,0 +1,124 @@
+package xmrfingerprint
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// extraJSON encodes extra bytes as the daemon does.
+func extraJSON(parts ...[]byte) string {
+	var b []int
+	for _, p := range parts {
+		for _, c := range p {
+			b = append(b, int(c))
+		}
+	}
+	js, _ := json.Marshal(b)
+	return string(js)
+}
+
+func repeat(c byte, n int) []byte {
+	return []byte(strings.Repeat(string([]byte{c}), n))
+}
+
+func txWithExtra(extra string, outputs, ring int, unlock uint64) string {
+	offsets := make([]uint64, ring)
+	vout := make([]map[string]any, outputs)
+	for i := range vout {
+		vout[i] = map[string]any{"amount": 0}
+	}
+	tx := map[string]any{
+		"version":     2,
+		"unlock_time": unlock,
+		"vin":         []any{map[string]any{"key": map[string]any{"amount": 0, "key_offsets": offsets}}},
+		"vout":        vout,
+		"extra":       json.RawMessage(extra),
+		"rct_signatures": map[string]any{
+			"type":   6,
+			"txnFee": 30000000,
+		},
+	}
+	js, _ := json.Marshal(tx)
+	return string(js)
+}
+
+func TestFromTxJSON(t *testing.T) {
+	const height = 3000000
+	pubKey := append([]byte{tagPubKey}, repeat(1, 32)...)
+	encPID := append([]byte{tagNonce, 9, 0x01}, repeat(2, 8)...)
+	unencPID := append([]byte{tagNonce, 33, 0x00}, repeat(3, 32)...)
+	additional := append([]byte{tagAdditionalPubkeys, 2}, repeat(4, 64)...)
+
+	tests := []struct {
+		name    string
+		extra   string
+		outputs int
+		ring    int
+		unlock  uint64
+		tags    string
+		flags   []string
+	}{
+		{"standard", extraJSON(pubKey), 2, 16, 0, "01", []string{}},
+		{"encrypted payment id", extraJSON(pubKey, encPID), 2, 16, 0, "01,02",
+			[]string{FlagEncryptedPaymentID}},
+		{"unsorted unencrypted payment id", extraJSON(unencPID, pubKey), 2, 16, 0, "02,01",
+			[]string{FlagUnencryptedPaymentID, FlagUnsortedExtra}},
+		{"subaddresses", extraJSON(pubKey, additional), 3, 16, 0, "01,04",
+			[]string{FlagAdditionalPubkeys, FlagManyOutputs}},
+		{"odd ring and unlock", extraJSON(pubKey), 1, 11, 10, "01",
+			[]string{FlagNonStandardRing, FlagUnlockTime, FlagSingleOutput}},
+		{"unknown tag", extraJSON(pubKey, []byte{0x7f, 1}), 2, 16, 0, "01,7f",
+			[]string{FlagUnknownExtra}},
+		{"truncated", extraJSON(pubKey[:10]), 2, 16, 0, "01", []string{FlagUnknownExtra}},
+		{"hex extra", `"` + "01" + strings.Repeat("aa", 32) + "0000" + `"`, 2, 16, 0, "01", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp, err := FromTxJSON("h", height, 1500, txWithExtra(tt.extra, tt.outputs, tt.ring, tt.unlock))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fp.ExtraTags != tt.tags {
+				t.Errorf("tags %q, want %q", fp.ExtraTags, tt.tags)
+			}
+			if !reflect.DeepEqual(fp.Flags, tt.flags) {
+				t.Errorf("flags %v, want %v", fp.Flags, tt.flags)
+			}
+			if fp.RctType != 6 || fp.Fee != 30000000 || fp.FeePerByte != 20000 || fp.RingSize != tt.ring {
+				t.Errorf("unexpected fingerprint %+v", fp)
+			}
+		})
+	}
+}
+
+func TestMarkFeeOutliers(t *testing.T) {
+	fps := []*Fingerprint{{FeePerByte: 20}, {FeePerByte: 22}, {FeePerByte: 400}, {FeePerByte: 5}}
+	MarkFeeOutliers(fps)
+	want := [][]string{nil, nil, {FlagFeeHigh}, {FlagFeeLow}}
+	for i, fp := range fps {
+		if !reflect.DeepEqual(fp.Flags, want[i]) {
+			t.Errorf("tx %d: flags %v, want %v", i, fp.Flags, want[i])
+		}
+	}
+	s := Summarize(1, fps)
+	if s.MedianFeePerByte != 21 || s.FlagCounts[FlagFeeHigh] != 1 || s.OutputCounts[0] != 4 {
+		t.Errorf("unexpected summary %+v", s)
+	}
+
+	// Too few transactions to tell.
+	few := []*Fingerprint{{FeePerByte: 1}, {FeePerByte: 1000}}
+	MarkFeeOutliers(few)
+	if len(few[0].Flags)+len(few[1].Flags) != 0 {
+		t.Errorf("unexpected flags in a small block")
+	}
+}
+
+func TestStandardRingSize(t *testing.T) {
+	for height, want := range map[int64]int{1000000: 0, 1220516: 5, 1500000: 7, 2000000: 11, 2688888: 16} {
+		if got := StandardRingSize(height); got != want {
+			t.Errorf("height %d: ring size %d, want %d", height, got, want)
+		}
+	}
+}
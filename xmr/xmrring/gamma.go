@@ -0,0 +1,86 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+package xmrring
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// GammaShape and GammaRate are the parameters of the gamma distribution
+	// of the log of the output age, in seconds, that the Monero wallet draws
+	// its decoys from.
+	GammaShape = 19.28
+	GammaRate  = 1.61
+
+	// BlockTime is the target time between Monero blocks.
+	BlockTime = 120 * time.Second
+
+	// SpendableAge is the number of blocks before a Monero output can be
+	// referenced in a ring.
+	SpendableAge = 10
+)
+
+// DecoySelectionCDF is the probability that the wallet picks a decoy no older
+// than age. The wallet measures the age of its picks from the most recent
+// spendable output, so the ages start at SpendableAge blocks.
+func DecoySelectionCDF(age time.Duration) float64 {
+	age -= SpendableAge * BlockTime
+	if age < time.Second {
+		return 0
+	}
+	return regularizedGammaP(GammaShape, GammaRate*math.Log(age.Seconds()))
+}
+
+// regularizedGammaP is the regularized lower incomplete gamma function P(a, x),
+// evaluated by its series below a+1 and by the continued fraction of Q(a, x)
+// above.
+func regularizedGammaP(a, x float64) float64 {
+	const (
+		maxIter = 500
+		eps     = 1e-14
+		tiny    = 1e-300
+	)
+	if x <= 0 {
+		return 0
+	}
+	lgammaA, _ := math.Lgamma(a)
+	prefix := math.Exp(a*math.Log(x) - x - lgammaA)
+	if x < a+1 {
+		sum, term := 1/a, 1/a
+		for n := 1; n < maxIter; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*eps {
+				break
+			}
+		}
+		return sum * prefix
+	}
+	// Lentz's method.
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for n := 1; n < maxIter; n++ {
+		an := -float64(n) * (float64(n) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < eps {
+			break
+		}
+	}
+	return 1 - prefix*h
+}
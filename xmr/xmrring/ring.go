@@ -0,0 +1,340 @@
+// Copyright (c) 2024, The Decred developers
+// See LICENSE for details.
+
+// Package xmrring analyzes the rings of Monero transaction inputs. It ages the
+// ring members against the wallet's decoy selection, eliminates the members
+// that cannot be the real spend, and deduces the real spends of the rings left
+// with a single member, both for zero-mixin rings and by chain reaction.
+package xmrring
+
+import (
+	"sort"
+	"time"
+)
+
+// AgeBandLabels are the labels of the ring member age bands.
+var AgeBandLabels = []string{"<2h", "2h-1d", "1d-1w", "1w-1m", "1m-6m", "6m-1y", ">1y"}
+
+// ageBandLimits are the upper limits of all but the last age band.
+var ageBandLimits = []time.Duration{
+	2 * time.Hour,
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+	30 * 24 * time.Hour,
+	182 * 24 * time.Hour,
+	365 * 24 * time.Hour,
+}
+
+// AgeBand is the index in AgeBandLabels of the band of a ring member age.
+func AgeBand(age time.Duration) int {
+	return sort.Search(len(ageBandLimits), func(i int) bool { return age < ageBandLimits[i] })
+}
+
+// ExpectedAgeBands is the share, in percent, of the decoys that the wallet
+// picks in each age band.
+func ExpectedAgeBands() []float64 {
+	shares := make([]float64, len(AgeBandLabels))
+	var prev float64
+	for i := range shares {
+		cdf := 1.0
+		if i < len(ageBandLimits) {
+			cdf = DecoySelectionCDF(ageBandLimits[i])
+		}
+		shares[i] = 100 * (cdf - prev)
+		prev = cdf
+	}
+	return shares
+}
+
+// OutputKey identifies a Monero output by its amount, which is zero for RingCT
+// outputs, and its index among the outputs of that amount.
+type OutputKey struct {
+	Amount uint64
+	Index  uint64
+}
+
+// Member is a ring member.
+type Member struct {
+	Index uint64
+	// Height is the height of the block that created the output, or -1 if it
+	// is unknown.
+	Height int64
+}
+
+// Input is a transaction input and its ring.
+type Input struct {
+	TxHash  string
+	Index   int
+	Height  int64
+	Amount  uint64
+	Members []Member
+}
+
+// Key is the OutputKey of the ring member at position pos.
+func (in *Input) Key(pos int) OutputKey {
+	return OutputKey{Amount: in.Amount, Index: in.Members[pos].Index}
+}
+
+// Spend is the input deduced to be the real spend of an output.
+type Spend struct {
+	TxHash     string
+	InputIndex int
+	Height     int64
+}
+
+// MemberStatus tells whether a ring member may be the real spend.
+type MemberStatus int
+
+const (
+	// Plausible members are not eliminated.
+	Plausible MemberStatus = iota
+	// BeforeCreation members are referenced before the block that created
+	// them.
+	BeforeCreation
+	// SpentBefore members are referenced after their deduced spend.
+	SpentBefore
+	// SpentElsewhere members are deduced to be spent by a later input.
+	SpentElsewhere
+	// RealSpend members are deduced to be the real spend.
+	RealSpend
+)
+
+func (s MemberStatus) String() string {
+	switch s {
+	case BeforeCreation:
+		return "referenced before creation"
+	case SpentBefore:
+		return "spent before"
+	case SpentElsewhere:
+		return "spent elsewhere"
+	case RealSpend:
+		return "real spend"
+	}
+	return "plausible"
+}
+
+// Deduction is how the real spend of a ring was deduced.
+type Deduction string
+
+const (
+	NotDeduced    Deduction = ""
+	ZeroMixin     Deduction = "zero-mixin"
+	ChainReaction Deduction = "chain-reaction"
+)
+
+// MemberAnalysis is the analysis of a ring member.
+type MemberAnalysis struct {
+	Status MemberStatus `json:"status"`
+	// Age is the age of the member at the height of the input, or -1 if the
+	// member height is unknown or not below the input height.
+	Age time.Duration `json:"age"`
+	// SelectionCDF is the probability that the wallet picks a decoy no older
+	// than the member.
+	SelectionCDF float64 `json:"selection_cdf"`
+}
+
+// InputAnalysis is the analysis of an input ring.
+type InputAnalysis struct {
+	RingSize          int              `json:"ring_size"`
+	EffectiveRingSize int              `json:"effective_ring_size"`
+	BeforeCreation    int              `json:"before_creation"`
+	SpentBefore       int              `json:"spent_before"`
+	Deduction         Deduction        `json:"deduction,omitempty"`
+	Deduced           int              `json:"deduced"` // position of the real spend, or -1
+	MedianAge         time.Duration    `json:"median_age"`
+	Members           []MemberAnalysis `json:"members"`
+	AgeBands          []int64          `json:"age_bands"` // members in each age band
+}
+
+// Analyze analyzes the ring of an input given the deduced real spends of its
+// members. The effective ring size is the number of members not eliminated,
+// and the real spend is deduced when a single member is left.
+func Analyze(in *Input, spends map[OutputKey]Spend) *InputAnalysis {
+	a := &InputAnalysis{
+		RingSize: len(in.Members),
+		Deduced:  -1,
+		Members:  make([]MemberAnalysis, len(in.Members)),
+		AgeBands: make([]int64, len(AgeBandLabels)),
+	}
+	ages := make([]time.Duration, 0, len(in.Members))
+	lastPlausible := -1
+	for pos, m := range in.Members {
+		ma := &a.Members[pos]
+		ma.Age = -1
+		if m.Height >= 0 && m.Height < in.Height {
+			ma.Age = time.Duration(in.Height-m.Height) * BlockTime
+			ma.SelectionCDF = DecoySelectionCDF(ma.Age)
+			ages = append(ages, ma.Age)
+			a.AgeBands[AgeBand(ma.Age)]++
+		}
+		spend, spent := spends[in.Key(pos)]
+		switch {
+		case m.Height >= in.Height:
+			ma.Status = BeforeCreation
+			a.BeforeCreation++
+		case spent && (spend.TxHash != in.TxHash || spend.InputIndex != in.Index):
+			if spend.Height < in.Height {
+				ma.Status = SpentBefore
+				a.SpentBefore++
+			} else {
+				ma.Status = SpentElsewhere
+			}
+		default:
+			a.EffectiveRingSize++
+			lastPlausible = pos
+		}
+	}
+	if len(ages) > 0 {
+		sort.Slice(ages, func(i, j int) bool { return ages[i] < ages[j] })
+		a.MedianAge = ages[len(ages)/2]
+	}
+	if a.EffectiveRingSize == 1 {
+		a.Deduced = lastPlausible
+		a.Deduction = ChainReaction
+		if a.RingSize == 1 {
+			a.Deduction = ZeroMixin
+		}
+		a.Members[lastPlausible].Status = RealSpend
+	}
+	return a
+}
+
+// Store is the state of a chain reaction analysis.
+type Store interface {
+	// Spends returns the deduced real spends of the outputs.
+	Spends(keys []OutputKey) (map[OutputKey]Spend, error)
+	// AddSpend records the deduced real spend of an output.
+	AddSpend(key OutputKey, spend Spend) error
+	// Referencing returns the analyzed inputs without a deduced real spend
+	// whose rings reference the output.
+	Referencing(key OutputKey) ([]*Input, error)
+	// Update records the analysis of an input.
+	Update(in *Input, a *InputAnalysis) error
+}
+
+// Deduce analyzes the ring of an input and records the analysis in the store.
+// When the real spend of the input is deduced, the other inputs referencing
+// the spent output are analyzed again, which may in turn deduce their real
+// spends. The analysis of the input is returned.
+func Deduce(store Store, in *Input) (*InputAnalysis, error) {
+	a, deduced, err := analyzeInput(store, in)
+	if err != nil {
+		return nil, err
+	}
+	queue := deduced
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		refs, err := store.Referencing(key)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range refs {
+			_, more, err := analyzeInput(store, ref)
+			if err != nil {
+				return nil, err
+			}
+			queue = append(queue, more...)
+		}
+	}
+	return a, nil
+}
+
+// analyzeInput analyzes and updates an input, recording its real spend if it
+// is deduced. The key of the deduced output is returned.
+func analyzeInput(store Store, in *Input) (*InputAnalysis, []OutputKey, error) {
+	keys := make([]OutputKey, len(in.Members))
+	for pos := range in.Members {
+		keys[pos] = in.Key(pos)
+	}
+	spends, err := store.Spends(keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	a := Analyze(in, spends)
+	if err = store.Update(in, a); err != nil {
+		return nil, nil, err
+	}
+	if a.Deduced < 0 {
+		return a, nil, nil
+	}
+	key := in.Key(a.Deduced)
+	if _, found := spends[key]; found {
+		return a, nil, nil
+	}
+	err = store.AddSpend(key, Spend{TxHash: in.TxHash, InputIndex: in.Index, Height: in.Height})
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, []OutputKey{key}, nil
+}
+
+// MemoryStore is a Store kept in memory.
+type MemoryStore struct {
+	spends   map[OutputKey]Spend
+	inputs   map[Spend]*Input
+	analyses map[Spend]*InputAnalysis
+	refs     map[OutputKey][]Spend
+}
+
+// NewMemoryStore is the constructor for an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		spends:   make(map[OutputKey]Spend),
+		inputs:   make(map[Spend]*Input),
+		analyses: make(map[Spend]*InputAnalysis),
+		refs:     make(map[OutputKey][]Spend),
+	}
+}
+
+func inputID(in *Input) Spend {
+	return Spend{TxHash: in.TxHash, InputIndex: in.Index, Height: in.Height}
+}
+
+// Spends returns the deduced real spends of the outputs.
+func (s *MemoryStore) Spends(keys []OutputKey) (map[OutputKey]Spend, error) {
+	spends := make(map[OutputKey]Spend)
+	for _, key := range keys {
+		if spend, found := s.spends[key]; found {
+			spends[key] = spend
+		}
+	}
+	return spends, nil
+}
+
+// AddSpend records the deduced real spend of an output.
+func (s *MemoryStore) AddSpend(key OutputKey, spend Spend) error {
+	s.spends[key] = spend
+	return nil
+}
+
+// Referencing returns the inputs without a deduced real spend whose rings
+// reference the output.
+func (s *MemoryStore) Referencing(key OutputKey) ([]*Input, error) {
+	var inputs []*Input
+	for _, id := range s.refs[key] {
+		if s.analyses[id].Deduced < 0 {
+			inputs = append(inputs, s.inputs[id])
+		}
+	}
+	return inputs, nil
+}
+
+// Update records the analysis of an input.
+func (s *MemoryStore) Update(in *Input, a *InputAnalysis) error {
+	id := inputID(in)
+	if _, found := s.inputs[id]; !found {
+		s.inputs[id] = in
+		for pos := range in.Members {
+			key := in.Key(pos)
+			s.refs[key] = append(s.refs[key], id)
+		}
+	}
+	s.analyses[id] = a
+	return nil
+}
+
+// Analysis is the last recorded analysis of an input.
+func (s *MemoryStore) Analysis(txHash string, index int, height int64) *InputAnalysis {
+	return s.analyses[Spend{TxHash: txHash, InputIndex: index, Height: height}]
+}
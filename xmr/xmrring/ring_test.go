@@ -0,0 +1,139 @@
+package xmrring
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRegularizedGammaP(t *testing.T) {
+	tests := []struct {
+		a, x, want float64
+	}{
+		{1, 1, 1 - math.Exp(-1)},
+		{1, 5, 1 - math.Exp(-5)},
+		{2, 3, 1 - 4*math.Exp(-3)},
+		{19.28, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := regularizedGammaP(tt.a, tt.x); math.Abs(got-tt.want) > 1e-10 {
+			t.Errorf("P(%v, %v) = %v, want %v", tt.a, tt.x, got, tt.want)
+		}
+	}
+}
+
+func TestExpectedAgeBands(t *testing.T) {
+	shares := ExpectedAgeBands()
+	var total float64
+	for i, share := range shares {
+		if share < 0 {
+			t.Errorf("band %s has a negative share %v", AgeBandLabels[i], share)
+		}
+		total += share
+	}
+	if math.Abs(total-100) > 1e-9 {
+		t.Errorf("shares sum to %v", total)
+	}
+	// The median of the log age is about 12 (2 days), so the wallet picks
+	// most decoys within a week.
+	if cdf := DecoySelectionCDF(7 * 24 * time.Hour); cdf < 0.5 || cdf > 0.9 {
+		t.Errorf("unexpected CDF at one week %v", cdf)
+	}
+	if cdf := DecoySelectionCDF(SpendableAge * BlockTime); cdf != 0 {
+		t.Errorf("expected zero CDF at the spendable age, got %v", cdf)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	in := &Input{
+		TxHash: "a",
+		Height: 1000,
+		Members: []Member{
+			{Index: 1, Height: 10},
+			{Index: 2, Height: 900},
+			{Index: 3, Height: 1000},
+			{Index: 4, Height: -1},
+		},
+	}
+	spends := map[OutputKey]Spend{
+		{Index: 1}: {TxHash: "b", Height: 500},
+	}
+	a := Analyze(in, spends)
+	if a.RingSize != 4 || a.EffectiveRingSize != 2 || a.Deduced != -1 {
+		t.Fatalf("unexpected analysis %+v", a)
+	}
+	if a.SpentBefore != 1 || a.BeforeCreation != 1 {
+		t.Errorf("expected one spent before and one before creation, got %d and %d",
+			a.SpentBefore, a.BeforeCreation)
+	}
+	wantStatus := []MemberStatus{SpentBefore, Plausible, BeforeCreation, Plausible}
+	for pos, ma := range a.Members {
+		if ma.Status != wantStatus[pos] {
+			t.Errorf("member %d: status %v, want %v", pos, ma.Status, wantStatus[pos])
+		}
+	}
+	if age := a.Members[1].Age; age != 100*BlockTime {
+		t.Errorf("member 1: age %v", age)
+	}
+	if a.Members[3].Age != -1 {
+		t.Errorf("member 3: expected unknown age, got %v", a.Members[3].Age)
+	}
+	if a.MedianAge != 990*BlockTime {
+		t.Errorf("median age %v", a.MedianAge)
+	}
+
+	// Another spent member leaves a single one.
+	spends[OutputKey{Index: 4}] = Spend{TxHash: "c", Height: 2000}
+	a = Analyze(in, spends)
+	if a.Deduced != 1 || a.Deduction != ChainReaction || a.Members[3].Status != SpentElsewhere {
+		t.Fatalf("unexpected analysis %+v", a)
+	}
+}
+
+func TestDeduceChainReaction(t *testing.T) {
+	store := NewMemoryStore()
+	deduce := func(txHash string, height int64, amount uint64, indices ...uint64) *InputAnalysis {
+		in := &Input{TxHash: txHash, Height: height, Amount: amount}
+		for _, idx := range indices {
+			in.Members = append(in.Members, Member{Index: idx, Height: 1})
+		}
+		a, err := Deduce(store, in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+
+	// Neither ring can be resolved alone.
+	if a := deduce("a", 10, 0, 1, 2, 3); a.EffectiveRingSize != 3 {
+		t.Fatalf("a: effective ring size %d", a.EffectiveRingSize)
+	}
+	if a := deduce("b", 11, 0, 2, 3); a.EffectiveRingSize != 2 {
+		t.Fatalf("b: effective ring size %d", a.EffectiveRingSize)
+	}
+	// Outputs of another amount do not interfere.
+	if a := deduce("x", 11, 5, 3); a.Deduction != ZeroMixin {
+		t.Fatalf("x: deduction %q", a.Deduction)
+	}
+	// A zero-mixin spend of output 3 resolves b to output 2, and then a to
+	// output 1.
+	if a := deduce("c", 12, 0, 3); a.Deduction != ZeroMixin {
+		t.Fatalf("c: deduction %q", a.Deduction)
+	}
+	a := store.Analysis("b", 0, 11)
+	if a.Deduced != 0 || a.Deduction != ChainReaction || a.Members[1].Status != SpentElsewhere {
+		t.Fatalf("b: unexpected analysis %+v", a)
+	}
+	a = store.Analysis("a", 0, 10)
+	if a.Deduced != 0 || a.EffectiveRingSize != 1 {
+		t.Fatalf("a: unexpected analysis %+v", a)
+	}
+	spends, _ := store.Spends([]OutputKey{{Index: 1}, {Index: 2}, {Index: 3}, {Amount: 5, Index: 3}})
+	if len(spends) != 4 || spends[OutputKey{Index: 1}].TxHash != "a" {
+		t.Fatalf("unexpected spends %v", spends)
+	}
+	// A later ring referencing the spent outputs is resolved at once.
+	if a := deduce("d", 20, 0, 1, 2, 4); a.Deduced != 2 || a.SpentBefore != 2 {
+		t.Fatalf("d: unexpected analysis %+v", a)
+	}
+}
@@ -0,0 +1,206 @@
+package xmrutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Monero address networks.
+const (
+	NetMainnet  = "mainnet"
+	NetTestnet  = "testnet"
+	NetStagenet = "stagenet"
+	// NetRegtest is a local monerod started with --regtest. It uses the
+	// mainnet address prefixes.
+	NetRegtest = "regtest"
+)
+
+// AddressNetwork is the network of the addresses of a monerod network.
+func AddressNetwork(net string) string {
+	if net == NetRegtest {
+		return NetMainnet
+	}
+	return net
+}
+
+// Monero address types.
+const (
+	AddrStandard   = "standard"
+	AddrIntegrated = "integrated"
+	AddrSubaddress = "subaddress"
+)
+
+const (
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+	// fullBlockSize is the size of the blocks Monero base58 encodes into
+	// fullEncodedBlockSize characters.
+	fullBlockSize        = 8
+	fullEncodedBlockSize = 11
+
+	keySize       = 32
+	paymentIDSize = 8
+	checksumSize  = 4
+)
+
+// encodedBlockSizes are the encoded sizes of blocks of 0 to 8 bytes.
+var encodedBlockSizes = []int{0, 2, 3, 5, 6, 7, 9, 10, 11}
+
+// ErrAddressChecksum is returned by DecodeAddress for a well-formed address
+// with a checksum mismatch, such as an address with a typo.
+var ErrAddressChecksum = errors.New("invalid address checksum")
+
+type addressPrefix struct {
+	net, typ string
+}
+
+// addressPrefixes are the varint address prefixes of each network and type.
+var addressPrefixes = map[uint64]addressPrefix{
+	18: {NetMainnet, AddrStandard},
+	19: {NetMainnet, AddrIntegrated},
+	42: {NetMainnet, AddrSubaddress},
+	53: {NetTestnet, AddrStandard},
+	54: {NetTestnet, AddrIntegrated},
+	63: {NetTestnet, AddrSubaddress},
+	24: {NetStagenet, AddrStandard},
+	25: {NetStagenet, AddrIntegrated},
+	36: {NetStagenet, AddrSubaddress},
+}
+
+// Address is a decoded Monero address.
+type Address struct {
+	Network        string
+	Type           string
+	PublicSpendKey []byte
+	PublicViewKey  []byte
+	PaymentID      []byte // integrated addresses only
+}
+
+// DecodeAddress decodes a Monero address and verifies its checksum.
+func DecodeAddress(addr string) (*Address, error) {
+	data, err := decodeBase58(addr)
+	if err != nil {
+		return nil, err
+	}
+	prefix, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid address prefix")
+	}
+	p, ok := addressPrefixes[prefix]
+	if !ok {
+		return nil, fmt.Errorf("unknown address prefix %d", prefix)
+	}
+	size := n + 2*keySize + checksumSize
+	if p.typ == AddrIntegrated {
+		size += paymentIDSize
+	}
+	if len(data) != size {
+		return nil, fmt.Errorf("invalid %s address length", p.typ)
+	}
+	payload, checksum := data[:size-checksumSize], data[size-checksumSize:]
+	if !bytes.Equal(keccak256(payload)[:checksumSize], checksum) {
+		return nil, ErrAddressChecksum
+	}
+	a := &Address{
+		Network:        p.net,
+		Type:           p.typ,
+		PublicSpendKey: payload[n : n+keySize],
+		PublicViewKey:  payload[n+keySize : n+2*keySize],
+	}
+	if p.typ == AddrIntegrated {
+		a.PaymentID = payload[n+2*keySize:]
+	}
+	return a, nil
+}
+
+// String encodes the address.
+func (a *Address) String() string {
+	var prefix uint64
+	for v, p := range addressPrefixes {
+		if p.net == a.Network && p.typ == a.Type {
+			prefix = v
+		}
+	}
+	data := binary.AppendUvarint(nil, prefix)
+	data = append(data, a.PublicSpendKey...)
+	data = append(data, a.PublicViewKey...)
+	if a.Type == AddrIntegrated {
+		data = append(data, a.PaymentID...)
+	}
+	data = append(data, keccak256(data)[:checksumSize]...)
+	return encodeBase58(data)
+}
+
+func keccak256(b []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(b)
+	return h.Sum(nil)
+}
+
+// encodeBase58 encodes data with Monero's base58, which encodes blocks of 8
+// bytes into 11 characters.
+func encodeBase58(data []byte) string {
+	var sb strings.Builder
+	for len(data) > 0 {
+		n := min(fullBlockSize, len(data))
+		num := new(big.Int).SetBytes(data[:n])
+		block := make([]byte, encodedBlockSizes[n])
+		for i := range block {
+			block[i] = base58Alphabet[0]
+		}
+		mod := new(big.Int)
+		base := big.NewInt(58)
+		for i := len(block) - 1; i >= 0 && num.Sign() > 0; i-- {
+			num.DivMod(num, base, mod)
+			block[i] = base58Alphabet[mod.Int64()]
+		}
+		sb.Write(block)
+		data = data[n:]
+	}
+	return sb.String()
+}
+
+// decodeBase58 decodes Monero's base58.
+func decodeBase58(s string) ([]byte, error) {
+	var out []byte
+	for len(s) > 0 {
+		n := min(fullEncodedBlockSize, len(s))
+		size := -1
+		for i, encSize := range encodedBlockSizes {
+			if encSize == n {
+				size = i
+			}
+		}
+		if size < 0 {
+			return nil, fmt.Errorf("invalid base58 length")
+		}
+		var num uint64
+		for _, c := range []byte(s[:n]) {
+			d := strings.IndexByte(base58Alphabet, c)
+			if d < 0 {
+				return nil, fmt.Errorf("invalid base58 character %q", c)
+			}
+			hi, lo := bits.Mul64(num, 58)
+			lo, carry := bits.Add64(lo, uint64(d), 0)
+			if hi != 0 || carry != 0 {
+				return nil, fmt.Errorf("base58 block overflow")
+			}
+			num = lo
+		}
+		if size < fullBlockSize && num>>(8*size) != 0 {
+			return nil, fmt.Errorf("base58 block overflow")
+		}
+		block := make([]byte, fullBlockSize)
+		binary.BigEndian.PutUint64(block, num)
+		out = append(out, block[fullBlockSize-size:]...)
+		s = s[n:]
+	}
+	return out, nil
+}
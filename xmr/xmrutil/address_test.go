@@ -0,0 +1,57 @@
+package xmrutil
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestDecodeAddress(t *testing.T) {
+	// The Monero General Fund donation address.
+	const donation = "44AFFq5kSiGBoZ4NMDwYtN18obc8AemS33DBLWs3H7otXft3XjrpDtQGv7SqSsaBYBb98uNbr2VBBEt7f2wfn3RVGQBEP3A"
+	a, err := DecodeAddress(donation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Network != NetMainnet || a.Type != AddrStandard {
+		t.Errorf("got %s %s address", a.Network, a.Type)
+	}
+	if a.String() != donation {
+		t.Errorf("encoded address %s does not match", a.String())
+	}
+
+	// A typo is detected by the checksum.
+	typo := donation[:20] + "x" + donation[21:]
+	if _, err = DecodeAddress(typo); !errors.Is(err, ErrAddressChecksum) {
+		t.Errorf("expected checksum error, got %v", err)
+	}
+}
+
+func TestAddressRoundTrip(t *testing.T) {
+	for _, typ := range []string{AddrStandard, AddrIntegrated, AddrSubaddress} {
+		for _, net := range []string{NetMainnet, NetTestnet, NetStagenet} {
+			a := &Address{
+				Network:        net,
+				Type:           typ,
+				PublicSpendKey: bytes.Repeat([]byte{0xab}, keySize),
+				PublicViewKey:  bytes.Repeat([]byte{0x01}, keySize),
+			}
+			if typ == AddrIntegrated {
+				a.PaymentID = bytes.Repeat([]byte{0xff}, paymentIDSize)
+			}
+			decoded, err := DecodeAddress(a.String())
+			if err != nil {
+				t.Fatalf("%s %s: %v", net, typ, err)
+			}
+			if decoded.Network != net || decoded.Type != typ ||
+				!bytes.Equal(decoded.PublicSpendKey, a.PublicSpendKey) ||
+				!bytes.Equal(decoded.PublicViewKey, a.PublicViewKey) ||
+				!bytes.Equal(decoded.PaymentID, a.PaymentID) {
+				t.Errorf("%s %s: round trip mismatch", net, typ)
+			}
+		}
+	}
+	if _, err := DecodeAddress("4"); err == nil {
+		t.Error("expected error for short address")
+	}
+}
@@ -0,0 +1,300 @@
+package xmrutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"filippo.io/edwards25519"
+)
+
+// Extra field tags of the transaction public keys.
+const (
+	extraTagPubKey            = 0x01
+	extraTagAdditionalPubKeys = 0x04
+)
+
+// rctTypeBulletproof2 is the first RingCT type with 8 byte encrypted amounts.
+const rctTypeBulletproof2 = 4
+
+// ReceivedOutput is a transaction output received by a watched address. The
+// amount is zero when it cannot be decoded, as for RingCT types before
+// Bulletproof2.
+type ReceivedOutput struct {
+	Index  int    `json:"index"`
+	Key    string `json:"key"`
+	Amount uint64 `json:"amount"`
+}
+
+// scanTx is the part of the daemon's transaction JSON used to scan outputs
+// and find key images.
+type scanTx struct {
+	Vin []struct {
+		Key *struct {
+			KeyImage string `json:"k_image"`
+		} `json:"key"`
+	} `json:"vin"`
+	Vout []struct {
+		Amount uint64 `json:"amount"`
+		Target struct {
+			Key       string `json:"key"`
+			TaggedKey *struct {
+				Key     string `json:"key"`
+				ViewTag string `json:"view_tag"`
+			} `json:"tagged_key"`
+		} `json:"target"`
+	} `json:"vout"`
+	Extra         json.RawMessage `json:"extra"`
+	RctSignatures struct {
+		Type     int `json:"type"`
+		EcdhInfo []struct {
+			Amount string `json:"amount"`
+		} `json:"ecdhInfo"`
+	} `json:"rct_signatures"`
+}
+
+// extraBytes decodes the extra field, which the daemon encodes as an array
+// of bytes.
+func (tx *scanTx) extraBytes() ([]byte, error) {
+	if len(tx.Extra) == 0 {
+		return nil, nil
+	}
+	var ints []int
+	if err := json.Unmarshal(tx.Extra, &ints); err == nil {
+		b := make([]byte, len(ints))
+		for i, v := range ints {
+			if v < 0 || v > 255 {
+				return nil, fmt.Errorf("invalid extra byte %d", v)
+			}
+			b[i] = byte(v)
+		}
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(tx.Extra, &s); err != nil {
+		return nil, fmt.Errorf("invalid extra: %w", err)
+	}
+	return hex.DecodeString(s)
+}
+
+// txPubKeys returns the transaction public key and the additional public keys
+// of the subaddress outputs from the extra field. Parsing stops at the first
+// unknown tag, like the wallet does.
+func txPubKeys(extra []byte) (pub *[32]byte, additional [][32]byte) {
+	for len(extra) > 0 {
+		tag := extra[0]
+		extra = extra[1:]
+		switch tag {
+		case 0x00: // padding runs to the end
+			return
+		case extraTagPubKey:
+			if len(extra) < 32 {
+				return
+			}
+			if pub == nil {
+				pub = new([32]byte)
+				copy(pub[:], extra[:32])
+			}
+			extra = extra[32:]
+		case extraTagAdditionalPubKeys:
+			n, w := binary.Uvarint(extra)
+			if w <= 0 || uint64(len(extra)-w) < 32*n {
+				return
+			}
+			extra = extra[w:]
+			additional = make([][32]byte, n)
+			for i := range additional {
+				copy(additional[i][:], extra[:32])
+				extra = extra[32:]
+			}
+		case 0x02, 0xde: // nonce and mysterious minergate fields
+			n, w := binary.Uvarint(extra)
+			if w <= 0 || uint64(len(extra)-w) < n {
+				return
+			}
+			extra = extra[w+int(n):]
+		default:
+			return
+		}
+	}
+	return
+}
+
+// TxKeyImages returns the key images of the inputs of a transaction from the
+// daemon's JSON. Coinbase transactions have none.
+func TxKeyImages(txJSON string) ([]string, error) {
+	var tx scanTx
+	if err := json.Unmarshal([]byte(txJSON), &tx); err != nil {
+		return nil, err
+	}
+	keyImages := make([]string, 0, len(tx.Vin))
+	for _, in := range tx.Vin {
+		if in.Key != nil {
+			keyImages = append(keyImages, in.Key.KeyImage)
+		}
+	}
+	return keyImages, nil
+}
+
+// ViewKeyScanner detects the outputs received by an address with its private
+// view key, like a view-only wallet.
+type ViewKeyScanner struct {
+	Address  string
+	viewKey  *edwards25519.Scalar
+	spendKey *edwards25519.Point
+}
+
+// NewViewKeyScanner creates a scanner for the address after checking that
+// the private view key belongs to it.
+func NewViewKeyScanner(address, viewKey string) (*ViewKeyScanner, error) {
+	a, err := DecodeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	s := &ViewKeyScanner{Address: address}
+	var key [32]byte
+	if err = decodeKey(viewKey, &key); err != nil {
+		return nil, fmt.Errorf("invalid view key: %w", err)
+	}
+	if s.viewKey, err = edwards25519.NewScalar().SetCanonicalBytes(key[:]); err != nil {
+		return nil, errors.New("invalid view key: not a reduced scalar")
+	}
+	if s.spendKey, err = new(edwards25519.Point).SetBytes(a.PublicSpendKey); err != nil {
+		return nil, errors.New("invalid public spend key")
+	}
+	// The view public key of a subaddress is the view key times its spend
+	// public key, rather than times the base point.
+	pub := new(edwards25519.Point)
+	if a.Type == AddrSubaddress {
+		pub.ScalarMult(s.viewKey, s.spendKey)
+	} else {
+		pub.ScalarBaseMult(s.viewKey)
+	}
+	if !bytes.Equal(pub.Bytes(), a.PublicViewKey) {
+		return nil, errors.New("the view key does not belong to the address")
+	}
+	return s, nil
+}
+
+// Scan returns the outputs of a transaction from the daemon's JSON that were
+// received by the address.
+func (s *ViewKeyScanner) Scan(txJSON string) ([]ReceivedOutput, error) {
+	var tx scanTx
+	if err := json.Unmarshal([]byte(txJSON), &tx); err != nil {
+		return nil, err
+	}
+	extra, err := tx.extraBytes()
+	if err != nil {
+		return nil, err
+	}
+	txPub, additional := txPubKeys(extra)
+	var derivation *[32]byte
+	if txPub != nil {
+		derivation = keyDerivation(txPub, s.viewKey)
+	}
+
+	var received []ReceivedOutput
+	for i, out := range tx.Vout {
+		key, viewTag := out.Target.Key, ""
+		if out.Target.TaggedKey != nil {
+			key, viewTag = out.Target.TaggedKey.Key, out.Target.TaggedKey.ViewTag
+		}
+		var outKey [32]byte
+		if decodeKey(key, &outKey) != nil {
+			continue
+		}
+		derivations := []*[32]byte{derivation}
+		if i < len(additional) {
+			derivations = append(derivations, keyDerivation(&additional[i], s.viewKey))
+		}
+		for _, d := range derivations {
+			if d == nil {
+				continue
+			}
+			if viewTag != "" && viewTag != hex.EncodeToString([]byte{outputViewTag(d, i)}) {
+				continue
+			}
+			scalar := derivationToScalar(d, i)
+			if derivePublicKey(scalar, s.spendKey) != outKey {
+				continue
+			}
+			ro := ReceivedOutput{Index: i, Key: key, Amount: out.Amount}
+			if ro.Amount == 0 && tx.RctSignatures.Type >= rctTypeBulletproof2 && i < len(tx.RctSignatures.EcdhInfo) {
+				ro.Amount = decodeAmount(tx.RctSignatures.EcdhInfo[i].Amount, scalar)
+			}
+			received = append(received, ro)
+			break
+		}
+	}
+	return received, nil
+}
+
+func decodeKey(s string, key *[32]byte) error {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	if len(b) != 32 {
+		return fmt.Errorf("key length %d", len(b))
+	}
+	copy(key[:], b)
+	return nil
+}
+
+// keyDerivation is the shared secret 8*sec*pub, or nil for an invalid point.
+func keyDerivation(pub *[32]byte, sec *edwards25519.Scalar) *[32]byte {
+	p, err := new(edwards25519.Point).SetBytes(pub[:])
+	if err != nil {
+		return nil
+	}
+	p.ScalarMult(sec, p).MultByCofactor(p)
+	d := new([32]byte)
+	copy(d[:], p.Bytes())
+	return d
+}
+
+// derivationToScalar is Hs(derivation || varint(index)).
+func derivationToScalar(d *[32]byte, index int) *edwards25519.Scalar {
+	buf := binary.AppendUvarint(append([]byte{}, d[:]...), uint64(index))
+	var wide [64]byte
+	copy(wide[:], keccak256(buf))
+	s, err := edwards25519.NewScalar().SetUniformBytes(wide[:])
+	if err != nil {
+		panic(err) // wide is always 64 bytes
+	}
+	return s
+}
+
+// derivePublicKey is the one-time output key scalar*G + spendKey.
+func derivePublicKey(scalar *edwards25519.Scalar, spendKey *edwards25519.Point) [32]byte {
+	p := new(edwards25519.Point).ScalarBaseMult(scalar)
+	p.Add(p, spendKey)
+	var key [32]byte
+	copy(key[:], p.Bytes())
+	return key
+}
+
+// outputViewTag is the first byte of H("view_tag" || derivation ||
+// varint(index)).
+func outputViewTag(d *[32]byte, index int) byte {
+	buf := append([]byte("view_tag"), d[:]...)
+	buf = binary.AppendUvarint(buf, uint64(index))
+	return keccak256(buf)[0]
+}
+
+// decodeAmount decrypts an 8 byte encrypted amount, which is XORed with
+// H("amount" || scalar).
+func decodeAmount(encrypted string, scalar *edwards25519.Scalar) uint64 {
+	b, err := hex.DecodeString(encrypted)
+	if err != nil || len(b) != 8 {
+		return 0
+	}
+	mask := keccak256(append([]byte("amount"), scalar.Bytes()...))
+	for i := range b {
+		b[i] ^= mask[i]
+	}
+	return binary.LittleEndian.Uint64(b)
+}
@@ -0,0 +1,126 @@
+package xmrutil
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"filippo.io/edwards25519"
+)
+
+const (
+	// The Monero General Fund donation address and its published view key.
+	donationAddress = "44AFFq5kSiGBoZ4NMDwYtN18obc8AemS33DBLWs3H7otXft3XjrpDtQGv7SqSsaBYBb98uNbr2VBBEt7f2wfn3RVGQBEP3A"
+	donationViewKey = "f359631075708155cc3d92a32b75a7d02a5dcf27756707b47a2b31b21c389501"
+)
+
+func TestNewViewKeyScanner(t *testing.T) {
+	if _, err := NewViewKeyScanner(donationAddress, donationViewKey); err != nil {
+		t.Fatal(err)
+	}
+	other := "0" + donationViewKey[1:]
+	if _, err := NewViewKeyScanner(donationAddress, other); err == nil {
+		t.Error("expected an error for the view key of another address")
+	}
+	if _, err := NewViewKeyScanner(donationAddress, "ff"); err == nil {
+		t.Error("expected an error for a short view key")
+	}
+}
+
+// sendTo builds the JSON of a transaction paying amount to the second output
+// of the donation address, as a wallet would with the tx secret key r.
+func sendTo(t *testing.T, amount uint64) string {
+	t.Helper()
+	a, err := DecodeAddress(donationAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := edwards25519.NewScalar().SetCanonicalBytes([]byte{7, 1, 2, 3, 31: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var txPub [32]byte
+	copy(txPub[:], new(edwards25519.Point).ScalarBaseMult(r).Bytes())
+
+	// The sender derives 8*r*A, equal to the receiver's 8*a*R.
+	var viewPub [32]byte
+	copy(viewPub[:], a.PublicViewKey)
+	d := keyDerivation(&viewPub, r)
+	scalar := derivationToScalar(d, 1)
+	B, err := new(edwards25519.Point).SetBytes(a.PublicSpendKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outKey := derivePublicKey(scalar, B)
+
+	enc := make([]byte, 8)
+	binary.LittleEndian.PutUint64(enc, amount)
+	mask := keccak256(append([]byte("amount"), scalar.Bytes()...))
+	for i := range enc {
+		enc[i] ^= mask[i]
+	}
+
+	extra := []int{extraTagPubKey}
+	for _, b := range txPub {
+		extra = append(extra, int(b))
+	}
+	type taggedKey struct {
+		Key     string `json:"key"`
+		ViewTag string `json:"view_tag"`
+	}
+	type target struct {
+		TaggedKey taggedKey `json:"tagged_key"`
+	}
+	type vout struct {
+		Amount uint64 `json:"amount"`
+		Target target `json:"target"`
+	}
+	tx := map[string]any{
+		"version": 2,
+		"vin": []any{
+			map[string]any{"key": map[string]any{"amount": 0, "k_image": "aa"}},
+			map[string]any{"key": map[string]any{"amount": 0, "k_image": "bb"}},
+		},
+		"vout": []vout{
+			{Target: target{taggedKey{Key: hex.EncodeToString(txPub[:]), ViewTag: "00"}}},
+			{Target: target{taggedKey{
+				Key:     hex.EncodeToString(outKey[:]),
+				ViewTag: hex.EncodeToString([]byte{outputViewTag(d, 1)}),
+			}}},
+		},
+		"extra": extra,
+		"rct_signatures": map[string]any{
+			"type":     6,
+			"ecdhInfo": []any{map[string]string{"amount": "0000000000000000"}, map[string]string{"amount": hex.EncodeToString(enc)}},
+		},
+	}
+	b, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestViewKeyScannerScan(t *testing.T) {
+	s, err := NewViewKeyScanner(donationAddress, donationViewKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txJSON := sendTo(t, 1234567890)
+	received, err := s.Scan(txJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 1 || received[0].Index != 1 || received[0].Amount != 1234567890 {
+		t.Fatalf("unexpected received outputs %+v", received)
+	}
+
+	keyImages, err := TxKeyImages(txJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keyImages) != 2 || keyImages[0] != "aa" || keyImages[1] != "bb" {
+		t.Errorf("unexpected key images %v", keyImages)
+	}
+}